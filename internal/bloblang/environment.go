@@ -77,6 +77,38 @@ func (e *Environment) NewMapping(blobl string) (*mapping.Executor, error) {
 	return exec, nil
 }
 
+// FormatMapping parses a Bloblang mapping and returns it reprinted in a
+// canonical form.
+//
+// When a parsing error occurs the error will be the type *parser.Error.
+func (e *Environment) FormatMapping(blobl string) (string, error) {
+	return parser.FormatMapping(e.pCtx, blobl)
+}
+
+// CheckMappingTypes parses a Bloblang mapping with type analysis enabled and
+// returns any definite type errors found within it. The input schema describes
+// the documents the mapping will be executed against and may be nil.
+//
+// When a parsing error occurs the error will be the type *parser.Error.
+func (e *Environment) CheckMappingTypes(blobl string, input *query.TypeSchema) ([]*query.TypeError, error) {
+	exec, err := parser.ParseMapping(e.pCtx.WithTypeChecking(), blobl)
+	if err != nil {
+		return nil, err
+	}
+
+	mappingRunes := []rune(blobl)
+	var tErrs []*query.TypeError
+	for _, tErr := range exec.CheckTypes(input) {
+		// Errors found within imported mappings can't be located within this
+		// mapping and are therefore omitted.
+		if len(tErr.Input) > len(mappingRunes) || string(mappingRunes[len(mappingRunes)-len(tErr.Input):]) != string(tErr.Input) {
+			continue
+		}
+		tErrs = append(tErrs, tErr)
+	}
+	return tErrs, nil
+}
+
 // Deactivated returns a version of the environment where constructors are
 // disabled for all functions and methods, allowing mappings to be parsed and
 // validated but not executed.
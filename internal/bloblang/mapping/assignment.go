@@ -26,6 +26,10 @@ type AssignmentContext struct {
 	Vars  map[string]any
 	Meta  metaMsg
 	Value *any
+
+	// Tracks the objects of Value that can be modified in place, only set for
+	// compiled executors.
+	owned *ownedValues
 }
 
 // Assignment represents a way of assigning a queried value to something within
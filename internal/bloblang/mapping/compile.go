@@ -0,0 +1,291 @@
+package mapping
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang/query"
+	"github.com/warpstreamlabs/bento/internal/value"
+)
+
+// Compile returns a copy of the executor where the statements have been
+// reduced to an execution plan that is optimised for mapping messages:
+//
+//   - Statements assigning constant values skip query execution, and branches
+//     of root level if statements with constant conditions are resolved ahead
+//     of time.
+//   - Assignments to static paths of the new document are made by walking the
+//     document directly rather than through generic path helpers.
+//   - Assigned values are no longer copied in full. Instead, objects of the
+//     new document are copied on write only when an assignment modifies them,
+//     leaving all unchanged branches shared with the values they came from.
+//   - Allocations required for each execution are pooled and reused.
+//
+// The resulting executor produces the same results as the original. However,
+// as the structured contents of mapped messages can share values with the
+// input message they are marked as read only, and are therefore copied by any
+// subsequent processor that mutates them.
+func (e *Executor) Compile() *Executor {
+	newE := *e
+	newE.statements = compileStatements(e.statements)
+	newE.compiled = true
+	return &newE
+}
+
+func compileStatements(stmts []Statement) []Statement {
+	compiled := make([]Statement, 0, len(stmts))
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *SingleStatement:
+			if c := compileSingleStatement(s); c != nil {
+				compiled = append(compiled, c)
+			}
+		case *RootLevelIfStatement:
+			compiled = append(compiled, compileRootLevelIf(s)...)
+		default:
+			compiled = append(compiled, stmt)
+		}
+	}
+	return compiled
+}
+
+func compileSingleStatement(s *SingleStatement) Statement {
+	lit, isLit := s.query.(*query.Literal)
+	if isLit {
+		if _, isNothing := lit.Value.(value.Nothing); isNothing {
+			// The assignment is always skipped.
+			return nil
+		}
+	}
+
+	jAssign, isJSON := s.assignment.(*JSONAssignment)
+	if !isJSON {
+		if isLit {
+			return &constantStatement{SingleStatement: s, value: lit.Value}
+		}
+		return s
+	}
+
+	cAssign := &cowAssignment{JSONAssignment: jAssign}
+
+	// Values obtained from the new document or variables might be objects that
+	// we own and mutate in place, and therefore must still be copied.
+	_, targets := s.query.QueryTargets(query.TargetsContext{})
+	for _, t := range targets {
+		if t.Type == query.TargetRoot || t.Type == query.TargetVariable {
+			cAssign.clone = true
+			break
+		}
+	}
+
+	compiled := NewSingleStatement(s.input, cAssign, s.query)
+	if isLit {
+		return &constantStatement{SingleStatement: compiled, value: lit.Value}
+	}
+	return compiled
+}
+
+func compileRootLevelIf(s *RootLevelIfStatement) []Statement {
+	compiled := NewRootLevelIfStatement(s.input)
+	for _, p := range s.pairs {
+		if p.query == nil {
+			if len(compiled.pairs) == 0 {
+				return compileStatements(p.statements)
+			}
+			compiled.Add(nil, compileStatements(p.statements)...)
+			break
+		}
+		if lit, isLit := p.query.(*query.Literal); isLit {
+			if b, isBool := lit.Value.(bool); isBool {
+				if !b {
+					// This branch can never be executed.
+					continue
+				}
+				if len(compiled.pairs) == 0 {
+					return compileStatements(p.statements)
+				}
+				// Subsequent branches can never be executed.
+				compiled.Add(nil, compileStatements(p.statements)...)
+				break
+			}
+		}
+		compiled.Add(p.query, compileStatements(p.statements)...)
+	}
+	if len(compiled.pairs) == 0 {
+		return nil
+	}
+	return []Statement{compiled}
+}
+
+//------------------------------------------------------------------------------
+
+// constantStatement is a statement where the query is known to always result
+// in the same value.
+type constantStatement struct {
+	*SingleStatement
+	value any
+}
+
+func (c *constantStatement) Execute(fnContext query.FunctionContext, asContext AssignmentContext) error {
+	return c.assignment.Apply(c.value, asContext)
+}
+
+//------------------------------------------------------------------------------
+
+var varsPool = sync.Pool{
+	New: func() any {
+		return map[string]any{}
+	},
+}
+
+// ownedValues tracks the objects of a new document that were created during
+// the current execution of a mapping, and can therefore be modified in place.
+type ownedValues struct {
+	// Owned maps are referenced by their address, and are held until the end
+	// of the execution so that addresses cannot be reused by new allocations.
+	maps map[unsafe.Pointer]map[string]any
+
+	// Set when a value that is not owned has been assigned to the document.
+	shared bool
+}
+
+var ownedValuesPool = sync.Pool{
+	New: func() any {
+		return &ownedValues{maps: map[unsafe.Pointer]map[string]any{}}
+	},
+}
+
+func getOwnedValues() *ownedValues {
+	return ownedValuesPool.Get().(*ownedValues)
+}
+
+func putOwnedValues(o *ownedValues) {
+	clear(o.maps)
+	o.shared = false
+	ownedValuesPool.Put(o)
+}
+
+func (o *ownedValues) newMap() map[string]any {
+	m := map[string]any{}
+	o.maps[reflect.ValueOf(m).UnsafePointer()] = m
+	return m
+}
+
+// own returns the provided map if it is owned, or a shallow copy of the map
+// that is now owned.
+func (o *ownedValues) own(m map[string]any) map[string]any {
+	if _, exists := o.maps[reflect.ValueOf(m).UnsafePointer()]; exists {
+		return m
+	}
+	newM := make(map[string]any, len(m)+1)
+	for k, v := range m {
+		newM[k] = v
+	}
+	o.maps[reflect.ValueOf(newM).UnsafePointer()] = newM
+	return newM
+}
+
+//------------------------------------------------------------------------------
+
+// cowAssignment is a JSONAssignment that avoids copying assigned values by
+// copying objects of the document on write instead. When executed without
+// ownership tracking it behaves exactly as a JSONAssignment.
+type cowAssignment struct {
+	*JSONAssignment
+	clone bool
+}
+
+func (c *cowAssignment) Apply(val any, ctx AssignmentContext) error {
+	owned := ctx.owned
+	if owned == nil {
+		return c.JSONAssignment.Apply(val, ctx)
+	}
+
+	_, deleted := val.(value.Delete)
+	if !deleted {
+		if c.clone {
+			val = value.IClone(val)
+		} else {
+			switch val.(type) {
+			case map[string]any, []any:
+				owned.shared = true
+			}
+		}
+	}
+
+	path := c.path
+	if len(path) == 0 {
+		*ctx.Value = val
+		return nil
+	}
+
+	var root map[string]any
+	switch t := (*ctx.Value).(type) {
+	case map[string]any:
+		root = t
+	case value.Nothing, nil:
+		if deleted {
+			*ctx.Value = owned.newMap()
+			return nil
+		}
+		root = owned.newMap()
+	default:
+		return c.applySlow(val, ctx)
+	}
+
+	if deleted && !c.pathExists(root) {
+		return nil
+	}
+
+	root = owned.own(root)
+	*ctx.Value = root
+
+	target := root
+	for _, key := range path[:len(path)-1] {
+		switch t := target[key].(type) {
+		case map[string]any:
+			next := owned.own(t)
+			target[key] = next
+			target = next
+		case nil:
+			next := owned.newMap()
+			target[key] = next
+			target = next
+		default:
+			return c.applySlow(val, ctx)
+		}
+	}
+
+	if deleted {
+		delete(target, path[len(path)-1])
+	} else {
+		target[path[len(path)-1]] = val
+	}
+	return nil
+}
+
+// pathExists returns true if the full path of the assignment exists within a
+// document, which is used in order to avoid copying objects for deletions that
+// have no effect.
+func (c *cowAssignment) pathExists(root map[string]any) bool {
+	target := root
+	for _, key := range c.path[:len(c.path)-1] {
+		next, isMap := target[key].(map[string]any)
+		if !isMap {
+			// Arrays and non-object values are left to the assignment.
+			return target[key] != nil
+		}
+		target = next
+	}
+	_, exists := target[c.path[len(c.path)-1]]
+	return exists
+}
+
+// applySlow is used for paths that cannot be walked as objects, such as array
+// indexes and path collisions. The document is copied in full so that it can
+// be safely modified.
+func (c *cowAssignment) applySlow(val any, ctx AssignmentContext) error {
+	*ctx.Value = value.IClone(*ctx.Value)
+	return c.JSONAssignment.Apply(val, ctx)
+}
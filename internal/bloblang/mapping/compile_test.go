@@ -0,0 +1,214 @@
+package mapping_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang/parser"
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+func TestCompiledExecutor(t *testing.T) {
+	tests := map[string]struct {
+		mapping string
+		input   string
+		output  string
+		err     string
+	}{
+		"constant values": {
+			mapping: `root.a = "foo"
+root.b = 5 + 5
+root.c = deleted()
+root.d = {"e":[1,2,3]}`,
+			input:  `{}`,
+			output: `{"a":"foo","b":10,"d":{"e":[1,2,3]}}`,
+		},
+		"constant nothing is skipped": {
+			mapping: `root = this
+root.a = nothing()`,
+			input:  `{"a":"foo"}`,
+			output: `{"a":"foo"}`,
+		},
+		"constant if conditions": {
+			mapping: `if false {
+  root.a = "never"
+} else if true {
+  root.b = "always"
+} else {
+  root.c = "never"
+}
+if this.x > 0 {
+  root.d = "positive"
+} else if false {
+  root.e = "never"
+} else {
+  root.f = "other"
+}`,
+			input:  `{"x":1}`,
+			output: `{"b":"always","d":"positive"}`,
+		},
+		"deep writes after root copy": {
+			mapping: `root = this
+root.a.b.c = "new"
+root.a.d = this.a.b`,
+			input:  `{"a":{"b":{"c":"old","x":"y"}},"z":[1,2]}`,
+			output: `{"a":{"b":{"c":"new","x":"y"},"d":{"c":"old","x":"y"}},"z":[1,2]}`,
+		},
+		"deletions": {
+			mapping: `root = this
+root.a.b = deleted()
+root.nope.nah = deleted()
+root.c = deleted()`,
+			input:  `{"a":{"b":"foo","c":"bar"},"c":"baz"}`,
+			output: `{"a":{"c":"bar"}}`,
+		},
+		"array indexes": {
+			mapping: `root = this
+root.a.1 = "new"
+root.a.0.b = "also new"`,
+			input:  `{"a":[{"b":"old"},"old"]}`,
+			output: `{"a":[{"b":"also new"},"new"]}`,
+		},
+		"root and variable references": {
+			mapping: `let tmp = {"b":this.a.b}
+root.a = $tmp
+root.a.c = "changed"
+root.b = root.a
+root.b.d = "also changed"
+root.c = $tmp
+root.d = @`,
+			input:  `{"a":{"b":"old"}}`,
+			output: `{"a":{"b":"old","c":"changed"},"b":{"b":"old","c":"changed","d":"also changed"},"c":{"b":"old"},"d":{}}`,
+		},
+		"path collisions": {
+			mapping: `root.a = "foo"
+root.a.b = "bar"`,
+			input: `{}`,
+			err:   "failed assignment (line 2): unable to set target path a.b as the value of a was a non-object type (string)",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			exec, err := parser.ParseMapping(parser.GlobalContext(), test.mapping)
+			require.Nil(t, err)
+
+			for _, e := range [](func() (*message.Part, error)){
+				func() (*message.Part, error) {
+					return exec.MapPart(0, message.QuickBatch([][]byte{[]byte(test.input)}))
+				},
+				func() (*message.Part, error) {
+					return exec.Compile().MapPart(0, message.QuickBatch([][]byte{[]byte(test.input)}))
+				},
+			} {
+				res, err := e()
+				if test.err != "" {
+					require.EqualError(t, err, test.err)
+					continue
+				}
+				require.NoError(t, err)
+				assert.JSONEq(t, test.output, string(res.AsBytes()))
+			}
+		})
+	}
+}
+
+func TestCompiledExecutorInputUnchanged(t *testing.T) {
+	exec, perr := parser.ParseMapping(parser.GlobalContext(), `root = this
+root.a.b = "new"
+root.a.c = deleted()
+root.d = this.a`)
+	require.Nil(t, perr)
+	exec = exec.Compile()
+
+	input := message.QuickBatch(nil)
+	part := message.NewPart(nil)
+	part.SetStructured(map[string]any{
+		"a": map[string]any{"b": "old", "c": "old"},
+	})
+	input = append(input, part)
+
+	for i := 0; i < 2; i++ {
+		res, err := exec.MapPart(0, input)
+		require.NoError(t, err)
+
+		resV, err := res.AsStructured()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"a": map[string]any{"b": "new"},
+			"d": map[string]any{"b": "old", "c": "old"},
+		}, resV)
+
+		// Mutations of the result must not modify the input.
+		mutV, err := res.AsStructuredMut()
+		require.NoError(t, err)
+		mutV.(map[string]any)["d"].(map[string]any)["b"] = "mutated"
+	}
+
+	inV, err := part.AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"a": map[string]any{"b": "old", "c": "old"},
+	}, inV)
+}
+
+//------------------------------------------------------------------------------
+
+var benchMappings = map[string]string{
+	"constants": `root.id = "foo"
+root.kind = "bar"
+root.version = 1 + 1
+root.tags = ["a","b","c"]`,
+	"reshape": `root.user.id = this.id
+root.user.name = this.name.uppercase()
+root.user.contact.email = this.email
+root.meta.source = "bench"
+root.items = this.items`,
+	"modify copy": `root = this
+root.name = this.name.uppercase()
+root.nested.deep.value = "changed"
+root.email = deleted()`,
+	"conditional": `if true {
+  root = this
+} else {
+  root = {}
+}
+root.flag = this.id == "123"`,
+}
+
+const benchInput = `{"id":"123","name":"bench user","email":"user@example.com","items":[{"a":1},{"b":2},{"c":3}],"nested":{"deep":{"value":"original","other":[1,2,3]},"sibling":{"x":"y"}}}`
+
+func benchmarkExecutor(b *testing.B, compile bool) {
+	for name, m := range benchMappings {
+		b.Run(name, func(b *testing.B) {
+			exec, err := parser.ParseMapping(parser.GlobalContext(), m)
+			require.Nil(b, err)
+			if compile {
+				exec = exec.Compile()
+			}
+
+			part := message.NewPart([]byte(benchInput))
+			_, jErr := part.AsStructured()
+			require.NoError(b, jErr)
+			batch := message.Batch{part}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := exec.MapPart(0, batch); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkExecutor(b *testing.B) {
+	benchmarkExecutor(b, false)
+}
+
+func BenchmarkCompiledExecutor(b *testing.B) {
+	benchmarkExecutor(b, true)
+}
@@ -44,6 +44,7 @@ type Executor struct {
 	input      []rune
 	maps       map[string]query.Function
 	statements []Statement
+	compiled   bool
 
 	maxMapStacks int
 }
@@ -153,7 +154,20 @@ func (e *Executor) mapPart(appendTo *message.Part, index int, reference Message)
 		}
 	}
 
-	vars := map[string]any{}
+	var owned *ownedValues
+	var vars map[string]any
+	if e.compiled {
+		owned = getOwnedValues()
+		defer putOwnedValues(owned)
+
+		vars = varsPool.Get().(map[string]any)
+		defer func() {
+			clear(vars)
+			varsPool.Put(vars)
+		}()
+	} else {
+		vars = map[string]any{}
+	}
 
 	for _, stmt := range e.statements {
 		err := stmt.Execute(query.FunctionContext{
@@ -168,6 +182,7 @@ func (e *Executor) mapPart(appendTo *message.Part, index int, reference Message)
 				Vars:  vars,
 				Meta:  newPart,
 				Value: &newValue,
+				owned: owned,
 			},
 		)
 		if err != nil {
@@ -200,7 +215,11 @@ func (e *Executor) mapPart(appendTo *message.Part, index int, reference Message)
 		case []byte:
 			newPart.SetBytes(t)
 		default:
-			newPart.SetStructuredMut(newValue)
+			if owned != nil && owned.shared {
+				newPart.SetStructured(newValue)
+			} else {
+				newPart.SetStructuredMut(newValue)
+			}
 		}
 	}
 	return newPart, nil
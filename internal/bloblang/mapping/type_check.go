@@ -0,0 +1,80 @@
+package mapping
+
+import (
+	"sort"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang/query"
+	"github.com/warpstreamlabs/bento/internal/value"
+)
+
+// CheckTypes statically checks the types of each statement of the mapping and
+// returns any definite type errors found. The input schema describes the
+// documents that the mapping is executed upon and can be nil, in which case
+// only errors that do not depend on the input are found.
+//
+// Errors where the precise location is unknown are given the location of the
+// statement in which they were found.
+func (e *Executor) CheckTypes(input *query.TypeSchema) []*query.TypeError {
+	var errs []*query.TypeError
+	checkStatementTypes(e.statements, input, map[string]*query.TypeSchema{}, &errs)
+
+	mapNames := make([]string, 0, len(e.maps))
+	for k := range e.maps {
+		mapNames = append(mapNames, k)
+	}
+	sort.Strings(mapNames)
+	for _, k := range mapNames {
+		if m, ok := e.maps[k].(*Executor); ok {
+			// Maps can be applied to any value and so their context is unknown.
+			checkStatementTypes(m.statements, nil, map[string]*query.TypeSchema{}, &errs)
+		}
+	}
+	return errs
+}
+
+func withStatementInput(input []rune, errs []*query.TypeError) []*query.TypeError {
+	for _, err := range errs {
+		if err.Input == nil {
+			err.Input = input
+		}
+	}
+	return errs
+}
+
+func checkStatementTypes(stmts []Statement, input *query.TypeSchema, vars map[string]*query.TypeSchema, errs *[]*query.TypeError) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *SingleStatement:
+			t, sErrs := query.CheckTypes(s.query, query.TypeContext{Value: input, Vars: vars})
+			*errs = append(*errs, withStatementInput(s.input, sErrs)...)
+
+			if v, isVar := s.assignment.(*VarAssignment); isVar {
+				if t != nil && len(t.Types) == 1 && t.Types[0] == value.TDelete {
+					delete(vars, v.name)
+				} else {
+					vars[v.name] = t
+				}
+			}
+		case *RootLevelIfStatement:
+			for _, p := range s.pairs {
+				if p.query != nil {
+					*errs = append(*errs, withStatementInput(s.input, query.CheckConditionTypes(p.query, query.TypeContext{Value: input, Vars: vars}))...)
+				}
+
+				branchVars := make(map[string]*query.TypeSchema, len(vars))
+				for k, v := range vars {
+					branchVars[k] = v
+				}
+				checkStatementTypes(p.statements, input, branchVars, errs)
+
+				// Variables modified within a branch are only modified
+				// conditionally, and are therefore unknown after the block.
+				for k, v := range branchVars {
+					if existing, exists := vars[k]; !exists || existing != v {
+						vars[k] = nil
+					}
+				}
+			}
+		}
+	}
+}
@@ -16,6 +16,10 @@ type Context struct {
 	Methods      *query.MethodSet
 	namedContext *namedContext
 	importer     Importer
+	typeChecking bool
+
+	// Functions declared within the mapping currently being parsed.
+	userFunctions map[string]*query.UserFunction
 }
 
 // EmptyContext returns a parser context with no functions, methods or import
@@ -74,6 +78,14 @@ func (pCtx Context) InitMethod(name string, target query.Function, args *query.P
 	return pCtx.Methods.Init(name, target, args)
 }
 
+// WithTypeChecking returns a Context where method and function calls retain
+// the information required in order to statically check the types of parsed
+// mappings, at the cost of a small execution overhead.
+func (pCtx Context) WithTypeChecking() Context {
+	pCtx.typeChecking = true
+	return pCtx
+}
+
 // WithImporter returns a Context where imports are made from the provided
 // Importer implementation.
 func (pCtx Context) WithImporter(importer Importer) Context {
@@ -150,6 +150,19 @@ func (e *Error) Add(from *Error) {
 	}
 }
 
+// Message returns a human readable error string without any positional
+// information, which is useful when the position of the error is reported
+// separately, e.g. by editor tooling.
+func (e *Error) Message() string {
+	if importErr, isImport := e.Err.(*ImportError); isImport {
+		return fmt.Sprintf(
+			"failed to parse import '%v': %v", importErr.filepath,
+			importErr.perr.ErrorAtPosition(importErr.content),
+		)
+	}
+	return e.errorMsg(false)
+}
+
 // ErrorAtPosition returns a human readable error string including the line and
 // character position of the error.
 func (e *Error) ErrorAtPosition(input []rune) string {
@@ -175,3 +175,10 @@ func TestErrorPositionalStringsStructured(t *testing.T) {
 		assert.Equal(t, test.exp, test.err.ErrorAtPositionStructured("", []rune(test.input)))
 	}
 }
+
+func TestErrorMessage(t *testing.T) {
+	assert.Equal(t, "expected foo, bar, or baz", NewError([]rune("input data"), "foo", "bar", "baz").Message())
+
+	importErr := NewFatalError([]rune("import \"./foo.blobl\""), NewImportError("./foo.blobl", []rune("foo bar"), NewError([]rune("bar"), "baz")))
+	assert.Equal(t, "failed to parse import './foo.blobl': line 1 char 5: expected baz", importErr.Message())
+}
@@ -0,0 +1,440 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+)
+
+// FormatMapping parses a Bloblang mapping and returns it reprinted in a
+// canonical form, with normalised indentation, spacing around operators and
+// delimiters, and consistent layout of if and match blocks. Comments and line
+// breaks placed by the author are preserved.
+//
+// When the mapping fails to parse the error will be the type *Error.
+func FormatMapping(pCtx Context, mapping string) (string, error) {
+	if _, err := ParseMapping(pCtx, mapping); err != nil {
+		return "", err
+	}
+
+	tokens, err := lexFormatTokens([]rune(strings.ReplaceAll(mapping, "\r\n", "\n")))
+	if err != nil {
+		return "", err
+	}
+	formatted := printFormatLines(expandFormatBlocks(splitFormatLines(tokens)))
+
+	// Formatting only ever modifies whitespace, but as a precaution we ensure
+	// that the result remains a valid mapping.
+	if _, err := ParseMapping(pCtx, formatted); err != nil {
+		return "", errors.New("formatting resulted in an invalid mapping: " + err.Error())
+	}
+	return formatted, nil
+}
+
+//------------------------------------------------------------------------------
+
+type fmtTokenKind int
+
+const (
+	fmtTokenWord fmtTokenKind = iota
+	fmtTokenNumber
+	fmtTokenString
+	fmtTokenComment
+	fmtTokenNewline
+	fmtTokenPunct
+)
+
+type fmtToken struct {
+	kind  fmtTokenKind
+	value string
+
+	// Set for opening and closing braces that delimit a block of statements
+	// or cases rather than an object literal.
+	block bool
+
+	// Set for operators that apply to the following value only, such as a
+	// minus sign that negates a number.
+	prefix bool
+
+	// Set for square brackets that index the preceding value, and for colons
+	// within them that denote a slice.
+	index bool
+
+	// Set when the token was preceded by whitespace in the original input.
+	spaced bool
+}
+
+func (t fmtToken) is(kind fmtTokenKind, value string) bool {
+	return t.kind == kind && t.value == value
+}
+
+func (t fmtToken) isOpener() bool {
+	return t.kind == fmtTokenPunct && (t.value == "{" || t.value == "[" || t.value == "(")
+}
+
+func (t fmtToken) isCloser() bool {
+	return t.kind == fmtTokenPunct && (t.value == "}" || t.value == "]" || t.value == ")")
+}
+
+var fmtKeywords = map[string]struct{}{
+	"if": {}, "else": {}, "match": {}, "let": {}, "map": {}, "import": {}, "from": {}, "func": {},
+}
+
+func (t fmtToken) isKeyword() bool {
+	if t.kind != fmtTokenWord {
+		return false
+	}
+	_, exists := fmtKeywords[t.value]
+	return exists
+}
+
+// endsValue returns true if the token can be the final token of a value, which
+// determines whether a following operator is binary or a prefix.
+func (t fmtToken) endsValue() bool {
+	switch t.kind {
+	case fmtTokenWord:
+		return !t.isKeyword()
+	case fmtTokenNumber, fmtTokenString:
+		return true
+	case fmtTokenPunct:
+		return t.isCloser()
+	}
+	return false
+}
+
+// continuesLine returns true if the token is an operator that expects a
+// following operand, and therefore cannot be the final token of a statement.
+func (t fmtToken) continuesLine() bool {
+	if t.kind != fmtTokenPunct || t.prefix {
+		return false
+	}
+	switch t.value {
+	case ".", "=", "==", "!=", ">=", "<=", "&&", "||", "->", "=>", "<", ">", "+", "-", "*", "/", "%", "|":
+		return true
+	}
+	return false
+}
+
+var fmtOperators = []string{
+	"==", "!=", ">=", "<=", "&&", "||", "->", "=>", "::",
+	"=", "<", ">", "+", "-", "*", "/", "%", "|", "!",
+	".", ",", ":", "(", ")", "[", "]", "{", "}", "@", "$",
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+func isDigitRune(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func lexFormatTokens(input []rune) ([]fmtToken, error) {
+	var tokens []fmtToken
+	prev := func() fmtToken {
+		if len(tokens) == 0 {
+			return fmtToken{kind: fmtTokenNewline}
+		}
+		return tokens[len(tokens)-1]
+	}
+
+	spaced := false
+	for i := 0; i < len(input); {
+		r, n := input[i], len(tokens)
+		switch {
+		case r == ' ' || r == '\t' || r == '\r':
+			i++
+		case r == '\n':
+			tokens = append(tokens, fmtToken{kind: fmtTokenNewline, value: "\n"})
+			i++
+		case r == '#':
+			j := i
+			for j < len(input) && input[j] != '\n' {
+				j++
+			}
+			tokens = append(tokens, fmtToken{kind: fmtTokenComment, value: strings.TrimRight(string(input[i:j]), " \t\r")})
+			i = j
+		case r == '"':
+			res := TripleQuoteString(input[i:])
+			if res.Err != nil {
+				res = QuotedString(input[i:])
+			}
+			if res.Err != nil {
+				return nil, res.Err
+			}
+			j := len(input) - len(res.Remaining)
+			tokens = append(tokens, fmtToken{kind: fmtTokenString, value: string(input[i:j])})
+			i = j
+		case isDigitRune(r) && !prev().is(fmtTokenPunct, "."):
+			j := i
+			for j < len(input) && isDigitRune(input[j]) {
+				j++
+			}
+			if j+1 < len(input) && input[j] == '.' && isDigitRune(input[j+1]) {
+				for j++; j < len(input) && isDigitRune(input[j]); j++ {
+				}
+			}
+			tokens = append(tokens, fmtToken{kind: fmtTokenNumber, value: string(input[i:j])})
+			i = j
+		case isWordRune(r):
+			j := i
+			for j < len(input) && isWordRune(input[j]) {
+				j++
+			}
+			tokens = append(tokens, fmtToken{kind: fmtTokenWord, value: string(input[i:j])})
+			i = j
+		default:
+			op := string(r)
+			for _, o := range fmtOperators {
+				if strings.HasPrefix(string(input[i:min(i+len(o), len(input))]), o) {
+					op = o
+					break
+				}
+			}
+			tokens = append(tokens, fmtToken{kind: fmtTokenPunct, value: op})
+			i += len([]rune(op))
+		}
+		if len(tokens) > n {
+			tokens[n].spaced = spaced
+		}
+		spaced = r == ' ' || r == '\t' || r == '\r' || r == '\n'
+	}
+
+	annotateFormatTokens(tokens)
+	return tokens, nil
+}
+
+// annotateFormatTokens marks braces that delimit blocks rather than object
+// literals, brackets that index a value, and operators that act as a prefix to
+// a value.
+func annotateFormatTokens(tokens []fmtToken) {
+	var prevSignificant, prevOnLine *fmtToken
+	var openers []*fmtToken
+
+	for i := range tokens {
+		t := &tokens[i]
+		switch t.kind {
+		case fmtTokenComment:
+			continue
+		case fmtTokenNewline:
+			prevOnLine = nil
+			continue
+		case fmtTokenPunct:
+			switch t.value {
+			case "{":
+				t.block = prevSignificant != nil &&
+					(prevSignificant.endsValue() || prevSignificant.is(fmtTokenWord, "else") || prevSignificant.is(fmtTokenWord, "match"))
+				openers = append(openers, t)
+			case "[":
+				t.index = !t.spaced && prevOnLine != nil && prevOnLine.endsValue()
+				openers = append(openers, t)
+			case "(":
+				openers = append(openers, t)
+			case "}", "]", ")":
+				if len(openers) > 0 {
+					t.block = openers[len(openers)-1].block
+					t.index = openers[len(openers)-1].index
+					openers = openers[:len(openers)-1]
+				}
+			case ":":
+				t.index = len(openers) > 0 && openers[len(openers)-1].index
+			case "-":
+				t.prefix = prevOnLine == nil || !prevOnLine.endsValue()
+			case "!", "$":
+				t.prefix = true
+			case "@":
+				t.prefix = i+1 < len(tokens) && (tokens[i+1].kind == fmtTokenWord || tokens[i+1].kind == fmtTokenString)
+			}
+		}
+		prevSignificant, prevOnLine = t, t
+	}
+}
+
+//------------------------------------------------------------------------------
+
+func lastCodeToken(line []fmtToken) (fmtToken, bool) {
+	for i := len(line) - 1; i >= 0; i-- {
+		if line[i].kind != fmtTokenComment {
+			return line[i], true
+		}
+	}
+	return fmtToken{}, false
+}
+
+// splitFormatLines breaks a token stream into lines, where an empty line is
+// represented by an empty slice. Runs of empty lines are collapsed, empty lines
+// at the beginning or end of a block are removed, and an else placed on the
+// line following the closing brace of an if is moved onto the same line.
+func splitFormatLines(tokens []fmtToken) [][]fmtToken {
+	var lines [][]fmtToken
+	var current []fmtToken
+
+	pushLine := func() {
+		defer func() { current = nil }()
+
+		if len(current) == 0 {
+			if len(lines) == 0 || len(lines[len(lines)-1]) == 0 {
+				return
+			}
+			if last, ok := lastCodeToken(lines[len(lines)-1]); ok && last.isOpener() {
+				return
+			}
+			lines = append(lines, nil)
+			return
+		}
+
+		if current[0].isCloser() || current[0].is(fmtTokenWord, "else") {
+			for len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+				lines = lines[:len(lines)-1]
+			}
+		}
+		if current[0].is(fmtTokenWord, "else") && len(lines) > 0 {
+			prevLine := lines[len(lines)-1]
+			if last := prevLine[len(prevLine)-1]; last.is(fmtTokenPunct, "}") {
+				lines[len(lines)-1] = append(prevLine, current...)
+				return
+			}
+		}
+		lines = append(lines, current)
+	}
+
+	for _, t := range tokens {
+		if t.kind == fmtTokenNewline {
+			pushLine()
+			continue
+		}
+		current = append(current, t)
+	}
+	pushLine()
+
+	for len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// expandFormatBlocks breaks lines so that the contents of if, match and map
+// blocks always begin on a new line, each match case separated by a comma is
+// placed on its own line, and the closing brace of a block is always the first
+// token of its line.
+func expandFormatBlocks(lines [][]fmtToken) [][]fmtToken {
+	var openers []fmtToken
+	inBlock := func() bool {
+		return len(openers) > 0 && openers[len(openers)-1].block
+	}
+
+	expanded := make([][]fmtToken, 0, len(lines))
+	for _, line := range lines {
+		var current []fmtToken
+		for i, t := range line {
+			if t.block && t.value == "}" && len(current) > 0 {
+				expanded = append(expanded, current)
+				current = nil
+			}
+			current = append(current, t)
+
+			breakAfter := t.is(fmtTokenPunct, ",") && inBlock()
+			if t.isOpener() {
+				openers = append(openers, t)
+				breakAfter = t.block
+			} else if t.isCloser() && len(openers) > 0 {
+				openers = openers[:len(openers)-1]
+			}
+			if breakAfter && i+1 < len(line) && line[i+1].kind != fmtTokenComment {
+				expanded = append(expanded, current)
+				current = nil
+			}
+		}
+		expanded = append(expanded, current)
+	}
+	return expanded
+}
+
+func fmtNeedsSpace(a, b fmtToken) bool {
+	if b.kind == fmtTokenComment {
+		return true
+	}
+	if a.prefix {
+		return false
+	}
+	if a.kind == fmtTokenPunct {
+		switch a.value {
+		case ".", "(", "[", "::":
+			return false
+		case "{":
+			return a.block && !b.is(fmtTokenPunct, "}")
+		}
+	}
+	if a.index && a.value == ":" {
+		return false
+	}
+	if b.kind == fmtTokenPunct {
+		switch b.value {
+		case ")", "]", ",", ":", ".", "::":
+			return false
+		case "[":
+			return !b.index
+		case "}":
+			return b.block
+		case "(":
+			return a.kind != fmtTokenWord || a.isKeyword()
+		}
+	}
+	return true
+}
+
+const fmtIndent = "  "
+
+func printFormatLines(lines [][]fmtToken) string {
+	type opener struct {
+		lineIndent int
+	}
+	var openers []opener
+
+	// When a line ends with an operator the expression continues onto the
+	// following lines, which are indented one level further.
+	contIndent, contDepth := -1, 0
+
+	var b strings.Builder
+	for _, line := range lines {
+		if len(line) == 0 {
+			b.WriteString("\n")
+			continue
+		}
+
+		indent := 0
+		if len(openers) > 0 {
+			indent = openers[len(openers)-1].lineIndent + 1
+		}
+		if line[0].isCloser() && len(openers) > 0 {
+			indent = openers[len(openers)-1].lineIndent
+		} else if contIndent >= 0 && len(openers) == contDepth {
+			indent = contIndent
+		}
+
+		b.WriteString(strings.Repeat(fmtIndent, indent))
+		for i, t := range line {
+			if i > 0 && fmtNeedsSpace(line[i-1], t) {
+				b.WriteByte(' ')
+			}
+			b.WriteString(t.value)
+
+			if t.isOpener() {
+				openers = append(openers, opener{lineIndent: indent})
+			} else if t.isCloser() && len(openers) > 0 {
+				openers = openers[:len(openers)-1]
+			}
+		}
+		b.WriteString("\n")
+
+		if last, ok := lastCodeToken(line); ok {
+			if last.continuesLine() {
+				if contIndent < 0 || len(openers) != contDepth {
+					contIndent, contDepth = indent+1, len(openers)
+				}
+			} else if len(openers) <= contDepth {
+				contIndent = -1
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,253 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatMapping(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "operator spacing",
+			input:    `root = this.foo+5*  this.bar`,
+			expected: "root = this.foo + 5 * this.bar\n",
+		},
+		{
+			name:     "prefix operators",
+			input:    `root = [ -5, !this.foo, this.bar -1, @, @foo, $bar ]`,
+			expected: "root = [-5, !this.foo, this.bar - 1, @, @foo, $bar]\n",
+		},
+		{
+			name:     "literals",
+			input:    `root = {"a":1,"b":[1,2,  3 ], "c": { } }`,
+			expected: "root = {\"a\": 1, \"b\": [1, 2, 3], \"c\": {}}\n",
+		},
+		{
+			name:     "function and method calls",
+			input:    `root = this.apply( "foo" ).map_each(e -> e.uppercase( ) ).(v -> v.length())`,
+			expected: "root = this.apply(\"foo\").map_each(e -> e.uppercase()).(v -> v.length())\n",
+		},
+		{
+			name: "blocks and comments",
+			input: `
+
+# A map
+map foo {
+root.a = this.a   # inline comment
+
+
+
+    root.b = "b"
+
+}
+
+
+root = this.apply("foo")
+
+`,
+			expected: `# A map
+map foo {
+  root.a = this.a # inline comment
+
+  root.b = "b"
+}
+
+root = this.apply("foo")
+`,
+		},
+		{
+			name: "if else layout",
+			input: `if this.a == "b" {
+root = "a"
+}
+else if this.a == "c" {
+    root = "c"
+}
+
+else {
+  root = deleted() }
+root.c = if this.d>2 {"big"}else{"small"}`,
+			expected: `if this.a == "b" {
+  root = "a"
+} else if this.a == "c" {
+  root = "c"
+} else {
+  root = deleted()
+}
+root.c = if this.d > 2 {
+  "big"
+} else {
+  "small"
+}
+`,
+		},
+		{
+			name: "match layout",
+			input: `root = match this.x {
+"a"=>1,
+    _=>this.b.map_each(e -> e*2)
+}
+root.b = match {this.a == "foo" => "bar", _ => "baz"}`,
+			expected: `root = match this.x {
+  "a" => 1,
+  _ => this.b.map_each(e -> e * 2)
+}
+root.b = match {
+  this.a == "foo" => "bar",
+  _ => "baz"
+}
+`,
+		},
+		{
+			name: "closing braces on their own line",
+			input: `if this.x {
+root.y = 1
+}   else   {
+root.y = 2}
+if this.z { root.q = 1 }
+root.r = match this.s {
+  "a" => {"b": [1, 2]}
+  _ => "y" }`,
+			expected: `if this.x {
+  root.y = 1
+} else {
+  root.y = 2
+}
+if this.z {
+  root.q = 1
+}
+root.r = match this.s {
+  "a" => {"b": [1, 2]}
+  _ => "y"
+}
+`,
+		},
+		{
+			name: "single line blocks with comments",
+			input: `map foo { root.a = this.a } # foo
+if this.z { # check z
+root.q = 1 }`,
+			expected: `map foo {
+  root.a = this.a
+} # foo
+if this.z { # check z
+  root.q = 1
+}
+`,
+		},
+		{
+			name: "nested multiple line literals",
+			input: `root = this.things.map_each(thing -> {
+      "id": thing.id,
+      "tags": [
+  "a",
+        "b"
+      ]})`,
+			expected: `root = this.things.map_each(thing -> {
+  "id": thing.id,
+  "tags": [
+    "a",
+    "b"
+  ]})
+`,
+		},
+		{
+			name: "continued lines",
+			input: `root = this.locations.
+                filter(loc -> loc.state == "WA").
+                map_each(loc -> {
+                    "name": loc.name
+                }).
+                sort_by(loc -> loc.name)
+root.b = this.foo |
+this.bar`,
+			expected: `root = this.locations.
+  filter(loc -> loc.state == "WA").
+  map_each(loc -> {
+    "name": loc.name
+  }).
+  sort_by(loc -> loc.name)
+root.b = this.foo |
+  this.bar
+`,
+		},
+		{
+			name: "triple quoted strings are preserved",
+			input: `root.a = """
+  multi   line
+    string
+"""
+  root.b = "has # hash"`,
+			expected: `root.a = """
+  multi   line
+    string
+"""
+root.b = "has # hash"
+`,
+		},
+		{
+			name:     "quoted paths and numbers",
+			input:    `root."foo bar" = this."baz buz".0.bar + 1.5`,
+			expected: "root.\"foo bar\" = this.\"baz buz\".0.bar + 1.5\n",
+		},
+		{
+			name:     "index and slice expressions",
+			input:    `root = [ this.foo[0], this.bar[1:3], this.baz[ :-1], [1, 2][0] ]`,
+			expected: "root = [this.foo[0], this.bar[1:3], this.baz[:-1], [1, 2][0]]\n",
+		},
+		{
+			name: "function definitions",
+			input: `func join( a,b ){
+root = [a,b].join("-")
+}
+root = join( a:this.a,b:this.b )`,
+			expected: `func join(a, b) {
+  root = [a, b].join("-")
+}
+root = join(a: this.a, b: this.b)
+`,
+		},
+		{
+			name: "namespaced imports",
+			input: `import "./lib.blobl"   as lib
+root = lib::shout( this.a )`,
+			expected: `import "./lib.blobl" as lib
+root = lib::shout(this.a)
+`,
+		},
+		{
+			name:     "root level query",
+			input:    `  this.foo.bar  `,
+			expected: "this.foo.bar\n",
+		},
+	}
+
+	pCtx := GlobalContext().CustomImporter(func(name string) ([]byte, error) {
+		return []byte(`func shout(s) { root = s.uppercase() }`), nil
+	})
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			res, err := FormatMapping(pCtx, test.input)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, res)
+
+			again, err := FormatMapping(pCtx, res)
+			require.NoError(t, err)
+			assert.Equal(t, res, again, "formatting is not idempotent")
+		})
+	}
+}
+
+func TestFormatMappingErrors(t *testing.T) {
+	_, err := FormatMapping(GlobalContext(), `root = this.foo.`)
+	require.Error(t, err)
+	assert.IsType(t, &Error{}, err)
+}
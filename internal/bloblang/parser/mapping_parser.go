@@ -3,6 +3,7 @@ package parser
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/Jeffail/gabs/v2"
@@ -50,6 +51,7 @@ func mappingStatement(pCtx Context, enableMeta bool, maps map[string]query.Funct
 		enabledStatements = []Func[mapping.Statement]{
 			toNilStatement(importParser(pCtx, maps)),
 			toNilStatement(mapParser(pCtx, maps)),
+			toNilStatement(funcParser(pCtx, maps)),
 		}
 	}
 	enabledStatements = append(enabledStatements,
@@ -63,10 +65,20 @@ func mappingStatement(pCtx Context, enableMeta bool, maps map[string]query.Funct
 }
 
 func parseExecutor(pCtx Context) Func[*mapping.Executor] {
+	return func(input []rune) Result[*mapping.Executor] {
+		return parseExecutorWithFunctions(pCtx, map[string]*query.UserFunction{})(input)
+	}
+}
+
+// parseExecutorWithFunctions parses a mapping and populates the provided map
+// with any functions declared within it.
+func parseExecutorWithFunctions(pCtx Context, funcs map[string]*query.UserFunction) Func[*mapping.Executor] {
 	return func(input []rune) Result[*mapping.Executor] {
 		maps := map[string]query.Function{}
 		statements := []mapping.Statement{}
 
+		pCtx.userFunctions = funcs
+
 		statementPattern := mappingStatement(pCtx, true, maps)
 
 		res := statementPattern(DiscardedWhitespaceNewlineComments(input).Remaining)
@@ -183,22 +195,28 @@ var varNameParser = JoinStringPayloads(
 	),
 )
 
-var importParserComb = TakeOnly(2, Sequence(
-	Term("import"),
-	SpacesAndTabs,
-	MustBe(
+var importParserComb = Sequence(
+	FuncAsAny(Term("import")),
+	FuncAsAny(SpacesAndTabs),
+	FuncAsAny(MustBe(
 		Expect(
 			QuotedString,
 			"filepath",
 		),
-	),
-))
+	)),
+	FuncAsAny(Optional(TakeOnly(3, Sequence(
+		SpacesAndTabs,
+		Term("as"),
+		SpacesAndTabs,
+		MustBe(Expect(SnakeCase, "namespace")),
+	)))),
+)
 
 func importParser(pCtx Context, maps map[string]query.Function) Func[string] {
 	return func(input []rune) Result[string] {
 		res := importParserComb(input)
 		if res.Err != nil {
-			return res
+			return Fail[string](res.Err, input)
 		}
 
 		if maps == nil {
@@ -208,7 +226,9 @@ func importParser(pCtx Context, maps map[string]query.Function) Func[string] {
 			)
 		}
 
-		fpath := res.Payload
+		fpath := res.Payload[2].(string)
+		namespace := res.Payload[3].(string)
+
 		contents, err := pCtx.importer.Import(fpath)
 		if err != nil {
 			return Fail[string](NewFatalError(input, fmt.Errorf("failed to read import: %w", err)), input)
@@ -217,34 +237,70 @@ func importParser(pCtx Context, maps map[string]query.Function) Func[string] {
 		nextCtx := pCtx.WithImporterRelativeToFile(fpath)
 
 		importContent := []rune(string(contents))
-		execRes := parseExecutor(nextCtx)(importContent)
+		importFuncs := map[string]*query.UserFunction{}
+		execRes := parseExecutorWithFunctions(nextCtx, importFuncs)(importContent)
 		if execRes.Err != nil {
 			return Fail[string](NewFatalError(input, NewImportError(fpath, importContent, execRes.Err)), input)
 		}
 
 		exec := execRes.Payload
-		if len(exec.Maps()) == 0 {
-			err := fmt.Errorf("no maps to import from '%v'", fpath)
+		if len(exec.Maps()) == 0 && len(importFuncs) == 0 {
+			err := fmt.Errorf("no maps or functions to import from '%v'", fpath)
 			return Fail[string](NewFatalError(input, err), input)
 		}
 
+		prefix := ""
+		if namespace != "" {
+			prefix = namespace + "::"
+		}
+
 		collisions := []string{}
 		for k, v := range exec.Maps() {
-			if _, exists := maps[k]; exists {
-				collisions = append(collisions, k)
+			if namespace != "" {
+				v = namespacedMap(v, exec.Maps())
+			}
+			if _, exists := maps[prefix+k]; exists {
+				collisions = append(collisions, prefix+k)
 			} else {
-				maps[k] = v
+				maps[prefix+k] = v
 			}
 		}
 		if len(collisions) > 0 {
+			sort.Strings(collisions)
 			err := fmt.Errorf("map name collisions from import '%v': %v", fpath, collisions)
 			return Fail[string](NewFatalError(input, err), input)
 		}
 
+		for k, v := range importFuncs {
+			if _, exists := pCtx.userFunctions[prefix+k]; exists {
+				collisions = append(collisions, prefix+k)
+			} else {
+				pCtx.userFunctions[prefix+k] = v
+			}
+		}
+		if len(collisions) > 0 {
+			sort.Strings(collisions)
+			err := fmt.Errorf("function name collisions from import '%v': %v", fpath, collisions)
+			return Fail[string](NewFatalError(input, err), input)
+		}
+
 		return Success(fpath, res.Remaining)
 	}
 }
 
+// namespacedMap wraps a map imported under a namespace so that it executes
+// with the maps of the file it was declared in, allowing it to apply maps that
+// are not directly accessible by the importing mapping.
+func namespacedMap(m query.Function, maps map[string]query.Function) query.Function {
+	return query.ClosureFunction(m.Annotation(), func(ctx query.FunctionContext) (any, error) {
+		ctx.Maps = maps
+		return m.Exec(ctx)
+	}, func(ctx query.TargetsContext) (query.TargetsContext, []query.TargetPath) {
+		ctx.Maps = maps
+		return m.QueryTargets(ctx)
+	})
+}
+
 func mapParser(pCtx Context, maps map[string]query.Function) Func[string] {
 	p := Sequence(
 		FuncAsAny(Term("map")),
@@ -305,6 +361,80 @@ func mapParser(pCtx Context, maps map[string]query.Function) Func[string] {
 	}
 }
 
+var funcHeaderParser = Sequence(
+	FuncAsAny(Term("func")),
+	FuncAsAny(SpacesAndTabs),
+	FuncAsAny(SnakeCase),
+	FuncAsAny(Discard(SpacesAndTabs)),
+	FuncAsAny(MustBe(DelimitedPattern(
+		Expect(Sequence(charBracketOpen, DiscardedWhitespaceNewlineComments), "function parameters"),
+		Expect(SnakeCase, "parameter name"),
+		Expect(Sequence(Discard(SpacesAndTabs), charComma, DiscardedWhitespaceNewlineComments), "comma"),
+		Expect(Sequence(DiscardedWhitespaceNewlineComments, charBracketClose), "closing bracket"),
+	))),
+	FuncAsAny(Discard(SpacesAndTabs)),
+)
+
+func funcParser(pCtx Context, maps map[string]query.Function) Func[string] {
+	return func(input []rune) Result[string] {
+		res := funcHeaderParser(input)
+		if res.Err != nil {
+			return Fail[string](res.Err, input)
+		}
+
+		seqSlice := res.Payload
+		name := seqSlice[2].(string)
+		paramNames := seqSlice[4].([]string)
+
+		if _, err := pCtx.Functions.Params(name); err == nil {
+			return Fail[string](NewFatalError(input, fmt.Errorf("function name collision with a built-in function: %v", name)), input)
+		}
+		if _, exists := pCtx.userFunctions[name]; exists {
+			return Fail[string](NewFatalError(input, fmt.Errorf("function name collision: %v", name)), input)
+		}
+
+		userFunc, err := query.NewUserFunction(name, paramNames)
+		if err != nil {
+			return Fail[string](NewFatalError(input, err), input)
+		}
+
+		// Parameters are accessible within the body as named contexts.
+		bodyCtx := pCtx
+		for _, p := range paramNames {
+			bodyCtx = bodyCtx.WithNamedContext(p)
+		}
+
+		// Register the function before parsing the body so that it can be
+		// called recursively.
+		pCtx.userFunctions[name] = userFunc
+
+		bodyRes := MustBe(DelimitedPattern(
+			Sequence(
+				charSquigOpen,
+				DiscardedWhitespaceNewlineComments,
+			),
+			// Prevent imports, maps, functions and metadata assignments.
+			mappingStatement(bodyCtx, false, nil),
+			Sequence(
+				Discard(SpacesAndTabs),
+				NewlineAllowComment,
+				DiscardedWhitespaceNewlineComments,
+			),
+			Sequence(
+				DiscardedWhitespaceNewlineComments,
+				charSquigClose,
+			),
+		))(res.Remaining)
+		if bodyRes.Err != nil {
+			delete(pCtx.userFunctions, name)
+			return Fail[string](bodyRes.Err, input)
+		}
+
+		userFunc.SetBody(mapping.NewExecutor("func "+name, input, maps, bodyRes.Payload...), maps)
+		return Success(name, bodyRes.Remaining)
+	}
+}
+
 func letStatementParser(pCtx Context) Func[mapping.Statement] {
 	p := Sequence(
 		FuncAsAny(Expect(Term("let"), "assignment")),
@@ -18,10 +18,12 @@ func TestMappingErrors(t *testing.T) {
 	badMapFile := filepath.Join(dir, "bad_map.blobl")
 	noMapsFile := filepath.Join(dir, "no_maps.blobl")
 	goodMapFile := filepath.Join(dir, "good_map.blobl")
+	funcsFile := filepath.Join(dir, "funcs.blobl")
 
 	require.NoError(t, os.WriteFile(badMapFile, []byte(`not a map bruh`), 0o777))
 	require.NoError(t, os.WriteFile(noMapsFile, []byte(`foo = "this is valid but has no maps"`), 0o777))
 	require.NoError(t, os.WriteFile(goodMapFile, []byte(`map foo { foo = "this is valid" }`), 0o777))
+	require.NoError(t, os.WriteFile(funcsFile, []byte(`func double(n) { root = n * 2 }`), 0o777))
 
 	tests := map[string]struct {
 		mapping     string
@@ -37,16 +39,16 @@ func TestMappingErrors(t *testing.T) {
 		},
 		"no mappings": {
 			mapping:     ``,
-			errContains: `line 1 char 1: expected import, map, or assignment`,
+			errContains: `line 1 char 1: expected import, map, func, or assignment`,
 		},
 		"no mappings 2": {
 			mapping: `
    `,
-			errContains: `line 2 char 4: expected import, map, or assignment`,
+			errContains: `line 2 char 4: expected import, map, func, or assignment`,
 		},
 		"comment with no mapping": {
 			mapping:     `# foobar`,
-			errContains: `line 1 char 1: expected import, map, or assignment`,
+			errContains: `line 1 char 1: expected import, map, func, or assignment`,
 		},
 		"double mapping": {
 			mapping:     `foo = bar bar = baz`,
@@ -87,13 +89,13 @@ meta foo = "bar"`,
 		"bad char 2": {
 			mapping: `let foo = bar
 !foo = bar`,
-			errContains: `line 2 char 1: expected import, map, or assignment`,
+			errContains: `line 2 char 1: expected import, map, func, or assignment`,
 		},
 		"bad char 3": {
 			mapping: `let foo = bar
 !foo = bar
 this = that`,
-			errContains: `line 2 char 1: expected import, map, or assignment`,
+			errContains: `line 2 char 1: expected import, map, func, or assignment`,
 		},
 		"bad query": {
 			mapping:     `foo = blah.`,
@@ -156,7 +158,7 @@ root.foo = "not allowed"`, goodMapFile),
 			mapping: fmt.Sprintf(`import "%v"
 
 foo = bar.apply("from_import")`, noMapsFile),
-			errContains: fmt.Sprintf(`line 1 char 1: no maps to import from '%v'`, noMapsFile),
+			errContains: fmt.Sprintf(`line 1 char 1: no maps or functions to import from '%v'`, noMapsFile),
 		},
 		"colliding maps file import": {
 			mapping: fmt.Sprintf(`map "foo" { this = that }
@@ -166,6 +168,61 @@ import "%v"
 foo = bar.apply("foo")`, goodMapFile),
 			errContains: fmt.Sprintf(`line 3 char 1: map name collisions from import '%v': [foo]`, goodMapFile),
 		},
+		"function collides with built-in": {
+			mapping: `func uuid_v4(a) {
+  root = a
+}`,
+			errContains: `line 1 char 1: function name collision with a built-in function: uuid_v4`,
+		},
+		"double function definition": {
+			mapping: `func foo(a) {
+  root = a
+}
+func foo(b) {
+  root = b
+}`,
+			errContains: `line 4 char 1: function name collision: foo`,
+		},
+		"duplicate function parameters": {
+			mapping: `func foo(a, a) {
+  root = a
+}`,
+			errContains: `line 1 char 1: duplicate parameter name: a`,
+		},
+		"function contains meta assignment": {
+			mapping: `func foo(a) {
+  meta foo = a
+}`,
+			errContains: `line 2 char 3: setting meta fields is not allowed within this block`,
+		},
+		"function missing parameters": {
+			mapping: `func foo {
+  root = "bar"
+}`,
+			errContains: `line 1 char 10: required: expected function parameters`,
+		},
+		"function called with too many args": {
+			mapping: `func foo(a) {
+  root = a
+}
+root = foo("a", "b")`,
+			errContains: `line 4 char 21: wrong number of arguments, expected 1, got 2`,
+		},
+		"function called before definition": {
+			mapping: `root = foo("a")
+func foo(a) {
+  root = a
+}`,
+			errContains: `line 1 char 16: unrecognised function 'foo'`,
+		},
+		"colliding functions file import": {
+			mapping: fmt.Sprintf(`func double(n) { root = n * 2 }
+
+import "%v"
+
+root = double(2)`, funcsFile),
+			errContains: fmt.Sprintf(`line 3 char 1: function name collisions from import '%v': [double]`, funcsFile),
+		},
 		"quotes at root": {
 			mapping: `
 "root.something" = 5 + 2`,
@@ -587,6 +644,133 @@ from "%v"`, directMapFile),
 	}
 }
 
+func TestUserFunctions(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "strings.blobl"), []byte(`
+func shout(s) {
+  root = s.uppercase() + "!"
+}
+
+func greet(name, greeting) {
+  root = shout(greeting + " " + name)
+}
+
+map tagged {
+  root.tag = "strings"
+  root.value = this.apply("inner")
+}
+
+map inner {
+  root = this.uppercase()
+}
+`), 0o777))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nested.blobl"), []byte(`
+import "./strings.blobl" as str
+
+func welcome(name) {
+  root = str::greet(name, "welcome")
+}
+`), 0o777))
+
+	tests := map[string]struct {
+		mapping string
+		input   string
+		output  string
+	}{
+		"single parameter": {
+			mapping: `func double(n) {
+  root = n * 2
+}
+root = double(this.value)`,
+			input:  `{"value":5}`,
+			output: `10`,
+		},
+		"multiple parameters": {
+			mapping: `func join(a, b, sep) {
+  root = [ a, b ].join(sep)
+}
+root.nameless = join(this.first, this.last, " ")
+root.named = join(sep: "-", b: this.last, a: this.first)`,
+			input:  `{"first":"foo","last":"bar"}`,
+			output: `{"named":"foo-bar","nameless":"foo bar"}`,
+		},
+		"parameters as this": {
+			mapping: `func pair(a, b) {
+  root = this
+}
+root = pair(1, "two")`,
+			input:  `{}`,
+			output: `{"a":1,"b":"two"}`,
+		},
+		"isolated variables": {
+			mapping: `func get(a) {
+  let foo = a
+  root = $foo
+}
+let foo = "outer"
+root.a = get("inner")
+root.b = $foo`,
+			input:  `{}`,
+			output: `{"a":"inner","b":"outer"}`,
+		},
+		"recursion": {
+			mapping: `func fact(n) {
+  root = if n <= 1 { 1 } else { n * fact(n - 1) }
+}
+root = fact(this.n)`,
+			input:  `{"n":5}`,
+			output: `120`,
+		},
+		"calls a map": {
+			mapping: `map upper {
+  root = this.uppercase()
+}
+func upper_all(values) {
+  root = values.map_each(v -> v.apply("upper"))
+}
+root = upper_all(this.values)`,
+			input:  `{"values":["a","b"]}`,
+			output: `["A","B"]`,
+		},
+		"imported without namespace": {
+			mapping: `import "./strings.blobl"
+root.a = greet(this.name, "hello")
+root.b = this.name.apply("tagged")`,
+			input:  `{"name":"foo"}`,
+			output: `{"a":"HELLO FOO!","b":{"tag":"strings","value":"FOO"}}`,
+		},
+		"imported with namespace": {
+			mapping: `import "./strings.blobl" as str
+root.a = str::greet(this.name, "hello")
+root.b = this.name.apply("str::tagged")`,
+			input:  `{"name":"foo"}`,
+			output: `{"a":"HELLO FOO!","b":{"tag":"strings","value":"FOO"}}`,
+		},
+		"nested namespaces": {
+			mapping: `import "./nested.blobl" as lib
+root.a = lib::welcome(this.name)
+root.b = lib::str::shout(this.name)`,
+			input:  `{"name":"foo"}`,
+			output: `{"a":"WELCOME FOO!","b":"FOO!"}`,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			pCtx := GlobalContext().WithImporterRelativeToFile(filepath.Join(dir, "main.blobl"))
+			exec, perr := ParseMapping(pCtx, test.mapping)
+			require.Nil(t, perr)
+
+			resPart, err := exec.MapPart(0, message.QuickBatch([][]byte{[]byte(test.input)}))
+			require.NoError(t, err)
+			assert.Equal(t, test.output, string(resPart.AsBytes()))
+		})
+	}
+}
+
 func BenchmarkMappingParser(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_, err := ParseMapping(GlobalContext(), `
@@ -466,12 +466,36 @@ func methodParser(fn query.Function, pCtx Context) Func[query.Function] {
 		if err != nil {
 			return Fail[query.Function](NewFatalError(res.Remaining, err), input)
 		}
+		if pCtx.typeChecking {
+			spec, _ := pCtx.Methods.Spec(targetMethod)
+			method = query.NewTypeCheckedMethod(input, spec, fn, parsedParams, method)
+		}
 		return Success(method, res.Remaining)
 	}
 }
 
+// namespacedName parses a snake case name optionally prefixed by any number of
+// namespaces, e.g. `foo::bar::baz`.
+func namespacedName(input []rune) Result[string] {
+	res := SnakeCase(input)
+	if res.Err != nil {
+		return res
+	}
+
+	name, remaining := res.Payload, res.Remaining
+	for {
+		nsRes := TakeOnly(1, Sequence(Term("::"), SnakeCase))(remaining)
+		if nsRes.Err != nil {
+			break
+		}
+		name += "::" + nsRes.Payload
+		remaining = nsRes.Remaining
+	}
+	return Success(name, remaining)
+}
+
 func functionParser(pCtx Context) Func[query.Function] {
-	p := Sequence(FuncAsAny(Expect(SnakeCase, "function")), FuncAsAny(functionArgsParser(pCtx)))
+	p := Sequence(FuncAsAny(Expect(namespacedName, "function")), FuncAsAny(functionArgsParser(pCtx)))
 
 	return func(input []rune) Result[query.Function] {
 		res := p(input)
@@ -482,6 +506,14 @@ func functionParser(pCtx Context) Func[query.Function] {
 		seqSlice := res.Payload
 
 		targetFunc := seqSlice[0].(string)
+		if userFunc, exists := pCtx.userFunctions[targetFunc]; exists {
+			parsedParams, err := extractArgsParserResult(userFunc.Params(), seqSlice[1].([]any))
+			if err != nil {
+				return Fail[query.Function](NewFatalError(res.Remaining, err), input)
+			}
+			return Success(userFunc.Call(parsedParams), res.Remaining)
+		}
+
 		params, err := pCtx.Functions.Params(targetFunc)
 		if err != nil {
 			return Fail[query.Function](NewFatalError(res.Remaining, err), input)
@@ -496,6 +528,10 @@ func functionParser(pCtx Context) Func[query.Function] {
 		if err != nil {
 			return Fail[query.Function](NewFatalError(res.Remaining, err), input)
 		}
+		if pCtx.typeChecking {
+			spec, _ := pCtx.Functions.Spec(targetFunc)
+			fn = query.NewTypeCheckedFunction(input, spec, parsedParams, fn)
+		}
 		return Success(fn, res.Remaining)
 	}
 }
@@ -58,7 +58,7 @@ func (o ArithmeticOperator) String() string {
 
 type arithmeticOpFunc[T any] func(lhs, rhs Function, l, r any) (T, error)
 
-func arithmeticFunc[T any](lhs, rhs Function, op arithmeticOpFunc[T]) (Function, error) {
+func arithmeticFunc[T any](arithOp ArithmeticOperator, lhs, rhs Function, op arithmeticOpFunc[T]) (Function, error) {
 	annotation := rhs.Annotation()
 
 	var litL, litR *Literal
@@ -73,7 +73,7 @@ func arithmeticFunc[T any](lhs, rhs Function, op arithmeticOpFunc[T]) (Function,
 		}
 	}
 
-	return ClosureFunction(annotation, func(ctx FunctionContext) (any, error) {
+	return typedClosureFunction(annotation, func(ctx FunctionContext) (any, error) {
 		var err error
 		var leftV, rightV any
 		if leftV, err = lhs.Exec(ctx); err == nil {
@@ -83,7 +83,7 @@ func arithmeticFunc[T any](lhs, rhs Function, op arithmeticOpFunc[T]) (Function,
 			return nil, err
 		}
 		return op(lhs, rhs, leftV, rightV)
-	}, aggregateTargetPaths(lhs, rhs)), nil
+	}, aggregateTargetPaths(lhs, rhs), arithmeticTypes(arithOp, lhs, rhs)), nil
 }
 
 //------------------------------------------------------------------------------
@@ -392,7 +392,7 @@ func compareOp(op ArithmeticOperator) (arithmeticOpFunc[bool], bool) {
 }
 
 func boolOr(lhs, rhs Function) Function {
-	return ClosureFunction(rhs.Annotation(), func(ctx FunctionContext) (any, error) {
+	return typedClosureFunction(rhs.Annotation(), func(ctx FunctionContext) (any, error) {
 		lhsV, err := lhs.Exec(ctx)
 		if err != nil {
 			return nil, err
@@ -412,11 +412,11 @@ func boolOr(lhs, rhs Function) Function {
 			return nil, err
 		}
 		return b, nil
-	}, aggregateTargetPaths(lhs, rhs))
+	}, aggregateTargetPaths(lhs, rhs), boolOpTypes(lhs, rhs))
 }
 
 func boolAnd(lhs, rhs Function) Function {
-	return ClosureFunction(rhs.Annotation(), func(ctx FunctionContext) (any, error) {
+	return typedClosureFunction(rhs.Annotation(), func(ctx FunctionContext) (any, error) {
 		lhsV, err := lhs.Exec(ctx)
 		if err != nil {
 			return nil, err
@@ -436,17 +436,17 @@ func boolAnd(lhs, rhs Function) Function {
 			return nil, err
 		}
 		return b, nil
-	}, aggregateTargetPaths(lhs, rhs))
+	}, aggregateTargetPaths(lhs, rhs), boolOpTypes(lhs, rhs))
 }
 
 func coalesce(lhs, rhs Function) Function {
-	return ClosureFunction(rhs.Annotation(), func(ctx FunctionContext) (any, error) {
+	return typedClosureFunction(rhs.Annotation(), func(ctx FunctionContext) (any, error) {
 		lhsV, err := lhs.Exec(ctx)
 		if err == nil && !value.IIsNull(lhsV) {
 			return lhsV, nil
 		}
 		return rhs.Exec(ctx)
-	}, aggregateTargetPaths(lhs, rhs))
+	}, aggregateTargetPaths(lhs, rhs), coalesceTypes(lhs, rhs))
 }
 
 // NewArithmeticExpression creates a single query function from a list of child
@@ -467,7 +467,7 @@ func NewArithmeticExpression(fns []Function, ops []ArithmeticOperator) (Function
 	for i, op := range ops {
 		leftFn, rightFn := fnsNew[len(fnsNew)-1], fns[i+1]
 		if opFunc, isProd := prodOp(op); isProd {
-			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(leftFn, rightFn, opFunc); err != nil {
+			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(op, leftFn, rightFn, opFunc); err != nil {
 				return nil, err
 			}
 		} else if op == ArithmeticPipe {
@@ -487,7 +487,7 @@ func NewArithmeticExpression(fns []Function, ops []ArithmeticOperator) (Function
 	for i, op := range ops {
 		leftFn, rightFn := fnsNew[len(fnsNew)-1], fns[i+1]
 		if opFunc, isSum := sumOp(op); isSum {
-			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(leftFn, rightFn, opFunc); err != nil {
+			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(op, leftFn, rightFn, opFunc); err != nil {
 				return nil, err
 			}
 		} else {
@@ -505,7 +505,7 @@ func NewArithmeticExpression(fns []Function, ops []ArithmeticOperator) (Function
 	for i, op := range ops {
 		leftFn, rightFn := fnsNew[len(fnsNew)-1], fns[i+1]
 		if opFunc, isCompare := compareOp(op); isCompare {
-			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(leftFn, rightFn, opFunc); err != nil {
+			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(op, leftFn, rightFn, opFunc); err != nil {
 				return nil, err
 			}
 		} else {
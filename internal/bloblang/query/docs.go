@@ -1,5 +1,7 @@
 package query
 
+import "github.com/warpstreamlabs/bento/internal/value"
+
 // ExampleSpec provides a mapping example and some input/output results to
 // display.
 type ExampleSpec struct {
@@ -92,6 +94,10 @@ type FunctionSpec struct {
 
 	// Version is the Bento version this component was introduced.
 	Version string `json:"version,omitempty"`
+
+	// ReturnTypes optionally lists the types of values that the function may
+	// return, which is used for statically checking the types of mappings.
+	ReturnTypes []value.Type `json:"return_types,omitempty"`
 }
 
 // NewFunctionSpec creates a new function spec.
@@ -137,6 +143,12 @@ func (s FunctionSpec) Param(def ParamDefinition) FunctionSpec {
 	return s
 }
 
+// Returns declares the types of values that the function may return.
+func (s FunctionSpec) Returns(types ...value.Type) FunctionSpec {
+	s.ReturnTypes = types
+	return s
+}
+
 // NewDeprecatedFunctionSpec creates a new function spec that is deprecated.
 func NewDeprecatedFunctionSpec(name, description string, examples ...ExampleSpec) FunctionSpec {
 	description = `:::caution DEPRECATED
@@ -214,6 +226,15 @@ type MethodSpec struct {
 
 	// Version is the Bento version this component was introduced.
 	Version string `json:"version,omitempty"`
+
+	// TargetTypes optionally lists the types of values that the method can be
+	// executed upon, which is used for statically checking the types of
+	// mappings.
+	TargetTypes []value.Type `json:"target_types,omitempty"`
+
+	// ReturnTypes optionally lists the types of values that the method may
+	// return, which is used for statically checking the types of mappings.
+	ReturnTypes []value.Type `json:"return_types,omitempty"`
 }
 
 // NewMethodSpec creates a new method spec.
@@ -279,6 +300,19 @@ func (m MethodSpec) Param(def ParamDefinition) MethodSpec {
 	return m
 }
 
+// OnTargets declares the types of values that the method can be executed upon,
+// executing the method on any other type results in an error.
+func (m MethodSpec) OnTargets(types ...value.Type) MethodSpec {
+	m.TargetTypes = types
+	return m
+}
+
+// Returns declares the types of values that the method may return.
+func (m MethodSpec) Returns(types ...value.Type) MethodSpec {
+	m.ReturnTypes = types
+	return m
+}
+
 // VariadicParams configures the method spec to allow variadic parameters.
 func (m MethodSpec) VariadicParams() MethodSpec {
 	m.Params = VariadicParams()
@@ -26,15 +26,17 @@ func NewMatchCase(caseFn, queryFn Function) MatchCase {
 // the function is executed.
 func NewMatchFunction(contextFn Function, cases ...MatchCase) Function {
 	if contextFn == nil {
-		contextFn = ClosureFunction("this", func(ctx FunctionContext) (any, error) {
+		contextFn = typedClosureFunction("this", func(ctx FunctionContext) (any, error) {
 			var value any
 			if v := ctx.Value(); v != nil {
 				value = *v
 			}
 			return value, nil
-		}, nil)
+		}, nil, func(c typeCheckContext) *TypeSchema {
+			return c.value
+		})
 	}
-	return ClosureFunction("match expression", func(ctx FunctionContext) (any, error) {
+	return typedClosureFunction("match expression", func(ctx FunctionContext) (any, error) {
 		ctxVal, err := contextFn.Exec(ctx)
 		if err != nil {
 			return nil, err
@@ -66,6 +68,23 @@ func NewMatchFunction(contextFn Function, cases ...MatchCase) Function {
 
 		targets = append(targets, contextTargets...)
 		return ctx, targets
+	}, func(c typeCheckContext) *TypeSchema {
+		caseCtx := c.withValue(c.infer(contextFn))
+
+		results := make([]*TypeSchema, 0, len(cases)+1)
+		exhaustive := false
+		for _, mc := range cases {
+			caseCtx.infer(mc.caseFn)
+			results = append(results, caseCtx.infer(mc.queryFn))
+			if lit, isLit := mc.caseFn.(*Literal); isLit && lit.Value == true {
+				exhaustive = true
+				break
+			}
+		}
+		if !exhaustive {
+			results = append(results, NewTypeSchema(value.TNothing))
+		}
+		return unionTypeSchemas(results...)
 	})
 }
 
@@ -86,7 +105,7 @@ func NewIfFunction(queryFn, ifFn Function, elseIfs []ElseIf, elseFn Function) Fu
 		allFns = append(allFns, eIf.QueryFn, eIf.MapFn)
 	}
 
-	return ClosureFunction("if expression", func(ctx FunctionContext) (any, error) {
+	return typedClosureFunction("if expression", func(ctx FunctionContext) (any, error) {
 		queryVal, err := queryFn.Exec(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to check if condition: %w", err)
@@ -132,7 +151,20 @@ func NewIfFunction(queryFn, ifFn Function, elseIfs []ElseIf, elseFn Function) Fu
 			return elseFn.Exec(ctx)
 		}
 		return value.Nothing(nil), nil
-	}, aggregateTargetPaths(allFns...))
+	}, aggregateTargetPaths(allFns...), func(c typeCheckContext) *TypeSchema {
+		checkConditionType(c, queryFn, true)
+		results := []*TypeSchema{c.infer(ifFn)}
+		for _, eFn := range elseIfs {
+			checkConditionType(c, eFn.QueryFn, true)
+			results = append(results, c.infer(eFn.MapFn))
+		}
+		if elseFn != nil {
+			results = append(results, c.infer(elseFn))
+		} else {
+			results = append(results, NewTypeSchema(value.TNothing))
+		}
+		return unionTypeSchemas(results...)
+	})
 }
 
 // NewNamedContextFunction wraps a function and ensures that when the function
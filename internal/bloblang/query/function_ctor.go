@@ -46,10 +46,24 @@ func ClosureFunction(
 	return closureFunction{annotation: annotation, exec: exec, queryTargets: queryTargets}
 }
 
+// typedClosureFunction is a ClosureFunction that is also able to statically
+// infer the type of the value it produces.
+func typedClosureFunction(
+	annotation string,
+	exec func(ctx FunctionContext) (any, error),
+	queryTargets func(ctx TargetsContext) (TargetsContext, []TargetPath),
+	types func(c typeCheckContext) *TypeSchema,
+) Function {
+	f := ClosureFunction(annotation, exec, queryTargets).(closureFunction)
+	f.types = types
+	return f
+}
+
 type closureFunction struct {
 	annotation   string
 	exec         func(ctx FunctionContext) (any, error)
 	queryTargets func(ctx TargetsContext) (TargetsContext, []TargetPath)
+	types        func(c typeCheckContext) *TypeSchema
 }
 
 func (f closureFunction) Annotation() string {
@@ -66,6 +66,15 @@ func (f *FunctionSet) Params(name string) (Params, error) {
 	return details.spec.Params, nil
 }
 
+// Spec attempts to obtain the specification of a given function type.
+func (f *FunctionSet) Spec(name string) (FunctionSpec, error) {
+	details, exists := f.functions[name]
+	if !exists {
+		return FunctionSpec{}, badFunctionErr(name)
+	}
+	return details.spec, nil
+}
+
 // Init attempts to initialize a function of the set by name and zero or more
 // arguments.
 func (f *FunctionSet) Init(name string, args *ParsedParams) (Function, error) {
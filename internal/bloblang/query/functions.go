@@ -934,7 +934,7 @@ var _ = registerFunction(
 
 // NewVarFunction creates a new variable function.
 func NewVarFunction(name string) Function {
-	return ClosureFunction("variable "+name, func(ctx FunctionContext) (any, error) {
+	return typedClosureFunction("variable "+name, func(ctx FunctionContext) (any, error) {
 		if ctx.Vars == nil {
 			return nil, errors.New("variables were undefined")
 		}
@@ -948,5 +948,7 @@ func NewVarFunction(name string) Function {
 		}
 		ctx = ctx.WithValues(paths)
 		return ctx, paths
+	}, func(c typeCheckContext) *TypeSchema {
+		return c.vars[name]
 	})
 }
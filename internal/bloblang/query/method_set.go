@@ -60,6 +60,15 @@ func (m *MethodSet) Params(name string) (Params, error) {
 	return details.spec.Params, nil
 }
 
+// Spec attempts to obtain the specification of a given method type.
+func (m *MethodSet) Spec(name string) (MethodSpec, error) {
+	details, exists := m.methods[name]
+	if !exists {
+		return MethodSpec{}, badMethodErr(name)
+	}
+	return details.spec, nil
+}
+
 // Init attempts to initialize a method of the set by name from a target
 // function and zero or more arguments.
 func (m *MethodSet) Init(name string, target Function, args *ParsedParams) (Function, error) {
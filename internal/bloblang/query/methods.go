@@ -339,7 +339,7 @@ var _ = registerMethod(
 
 // NewMapMethod attempts to create a map method.
 func NewMapMethod(target, mapFn Function) (Function, error) {
-	return ClosureFunction(mapFn.Annotation(), func(ctx FunctionContext) (any, error) {
+	return typedClosureFunction(mapFn.Annotation(), func(ctx FunctionContext) (any, error) {
 		res, err := target.Exec(ctx)
 		if err != nil {
 			return nil, err
@@ -351,6 +351,8 @@ func NewMapMethod(target, mapFn Function) (Function, error) {
 
 		returnCtx, mapTargets := mapFn.QueryTargets(mapCtx)
 		return returnCtx, append(targets, mapTargets...)
+	}, func(c typeCheckContext) *TypeSchema {
+		return c.withValue(c.infer(target)).infer(mapFn)
 	}), nil
 }
 
@@ -766,7 +768,7 @@ root.foo_type = this.foo.type()`,
 			`"2022-06-06"`,
 			`{"type":"timestamp"}`,
 		),
-	),
+	).Returns(value.TString),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v any, ctx FunctionContext) (any, error) {
 			return string(value.ITypeOf(v)), nil
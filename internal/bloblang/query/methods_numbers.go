@@ -18,7 +18,7 @@ var _ = registerSimpleMethod(
 			`{"value":-5.9}`,
 			`{"new_value":-5}`,
 		),
-	),
+	).OnTargets(value.TNumber).Returns(value.TNumber),
 	func(*ParsedParams) (simpleMethod, error) {
 		return numberMethod(func(f *float64, i *int64, ui *uint64) (any, error) {
 			if f != nil {
@@ -47,7 +47,7 @@ var _ = registerSimpleMethod(
 			`{"value":5.7}`,
 			`{"new_value":5}`,
 		),
-	),
+	).OnTargets(value.TNumber).Returns(value.TNumber),
 	func(*ParsedParams) (simpleMethod, error) {
 		return numberMethod(func(f *float64, i *int64, ui *uint64) (any, error) {
 			if f != nil {
@@ -75,7 +75,7 @@ var _ = registerSimpleMethod(
 			`{"value":2.7183}`,
 			`{"new_value":1}`,
 		),
-	),
+	).OnTargets(value.TNumber).Returns(value.TNumber),
 	func(*ParsedParams) (simpleMethod, error) {
 		return numberMethod(func(f *float64, i *int64, ui *uint64) (any, error) {
 			var v float64
@@ -101,7 +101,7 @@ var _ = registerSimpleMethod(
 			`{"value":1000}`,
 			`{"new_value":3}`,
 		),
-	),
+	).OnTargets(value.TNumber).Returns(value.TNumber),
 	func(*ParsedParams) (simpleMethod, error) {
 		return numberMethod(func(f *float64, i *int64, ui *uint64) (any, error) {
 			var v float64
@@ -216,7 +216,7 @@ var _ = registerSimpleMethod(
 			`{"value":5.9}`,
 			`{"new_value":6}`,
 		),
-	),
+	).OnTargets(value.TNumber).Returns(value.TNumber),
 	func(*ParsedParams) (simpleMethod, error) {
 		return numberMethod(func(f *float64, i *int64, ui *uint64) (any, error) {
 			if f != nil {
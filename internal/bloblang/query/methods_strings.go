@@ -69,7 +69,7 @@ var _ = registerSimpleMethod(
 			`{"title":"the foo bar"}`,
 			`{"title":"The Foo Bar"}`,
 		),
-	),
+	).OnTargets(value.TString, value.TBytes).Returns(value.TString, value.TBytes),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v any, ctx FunctionContext) (any, error) {
 			switch t := v.(type) {
@@ -527,7 +527,7 @@ var _ = registerSimpleMethod(
 			`the cat meowed, the dog woofed`,
 			`{"index":8}`,
 		),
-	).Param(ParamString("value", "A string to search for.")),
+	).Param(ParamString("value", "A string to search for.")).OnTargets(value.TString, value.TBytes).Returns(value.TNumber),
 	func(args *ParsedParams) (simpleMethod, error) {
 		substring, err := args.FieldString("value")
 		if err != nil {
@@ -698,7 +698,7 @@ root.t2 = this.v2.has_prefix("foo")`,
 			`{"v1":"foobar","v2":"barfoo"}`,
 			`{"t1":true,"t2":false}`,
 		),
-	).Param(ParamString("value", "The string to test.")),
+	).Param(ParamString("value", "The string to test.")).OnTargets(value.TString, value.TBytes).Returns(value.TBool),
 	func(args *ParsedParams) (simpleMethod, error) {
 		prefix, err := args.FieldString("value")
 		if err != nil {
@@ -731,7 +731,7 @@ root.t2 = this.v2.has_suffix("foo")`,
 			`{"v1":"foobar","v2":"barfoo"}`,
 			`{"t1":false,"t2":true}`,
 		),
-	).Param(ParamString("value", "The string to test.")),
+	).Param(ParamString("value", "The string to test.")).OnTargets(value.TString, value.TBytes).Returns(value.TBool),
 	func(args *ParsedParams) (simpleMethod, error) {
 		suffix, err := args.FieldString("value")
 		if err != nil {
@@ -910,7 +910,7 @@ root.joined_numbers = this.numbers.map_each(this.string()).join(",")`,
 			`{"words":["hello","world"],"numbers":[3,8,11]}`,
 			`{"joined_numbers":"3,8,11","joined_words":"helloworld"}`,
 		),
-	).Param(ParamString("delimiter", "An optional delimiter to add between each string.").Optional()),
+	).Param(ParamString("delimiter", "An optional delimiter to add between each string.").Optional()).OnTargets(value.TArray).Returns(value.TString),
 	func(args *ParsedParams) (simpleMethod, error) {
 		delimArg, err := args.FieldOptionalString("delimiter")
 		if err != nil {
@@ -958,7 +958,7 @@ var _ = registerSimpleMethod(
 			`{"foo":"hello world"}`,
 			`{"foo":"HELLO WORLD"}`,
 		),
-	),
+	).OnTargets(value.TString, value.TBytes).Returns(value.TString, value.TBytes),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v any, ctx FunctionContext) (any, error) {
 			switch t := v.(type) {
@@ -986,7 +986,7 @@ var _ = registerSimpleMethod(
 			`{"foo":"HELLO WORLD"}`,
 			`{"foo":"hello world"}`,
 		),
-	),
+	).OnTargets(value.TString, value.TBytes).Returns(value.TString, value.TBytes),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v any, ctx FunctionContext) (any, error) {
 			switch t := v.(type) {
@@ -1135,7 +1135,7 @@ var _ = registerSimpleMethod(
 			`{"doc":"{\"foo\":\"11380878173205700000000000000000000000000000000\"}"}`,
 			`{"doc":{"foo":"11380878173205700000000000000000000000000000000"}}`,
 		),
-	),
+	).OnTargets(value.TString, value.TBytes),
 	func(args *ParsedParams) (simpleMethod, error) {
 		useNumber, err := args.FieldOptionalBool("use_number")
 		if err != nil {
@@ -1388,7 +1388,7 @@ var _ = registerSimpleMethod(
 			`{"thing":"backwards"}`,
 			`}"sdrawkcab":"gniht"{`,
 		),
-	),
+	).OnTargets(value.TString, value.TBytes).Returns(value.TString, value.TBytes),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v any, ctx FunctionContext) (any, error) {
 			switch t := v.(type) {
@@ -1809,7 +1809,7 @@ var _ = registerSimpleMethod(
 			`{"value":"there are ten puppies"}`,
 			`{"matches":false}`,
 		),
-	).Param(ParamString("pattern", "The pattern to match against.")),
+	).Param(ParamString("pattern", "The pattern to match against.")).OnTargets(value.TString, value.TBytes).Returns(value.TBool),
 	func(args *ParsedParams) (simpleMethod, error) {
 		reStr, err := args.FieldString("pattern")
 		if err != nil {
@@ -1906,7 +1906,7 @@ var _ = registerSimpleMethod(
 			`{"id":228930314431312345}`,
 			`{"id":"228930314431312345"}`,
 		),
-	),
+	).Returns(value.TString),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v any, ctx FunctionContext) (any, error) {
 			return value.IToString(v), nil
@@ -1976,7 +1976,7 @@ root.description = this.description.trim()`,
 			`{"description":"  something happened and its amazing! ","title":"!!!watch out!?"}`,
 			`{"description":"something happened and its amazing!","title":"watch out"}`,
 		),
-	).Param(ParamString("cutset", "An optional string of characters to trim from the target value.").Optional()),
+	).Param(ParamString("cutset", "An optional string of characters to trim from the target value.").Optional()).OnTargets(value.TString, value.TBytes).Returns(value.TString, value.TBytes),
 	func(args *ParsedParams) (simpleMethod, error) {
 		cutset, err := args.FieldOptionalString("cutset")
 		if err != nil {
@@ -2013,7 +2013,7 @@ root.description = this.description.trim_prefix("foobar_")`,
 			`{"description":"unchanged","name":"blobton"}`,
 		),
 	).Param(ParamString("prefix", "The leading prefix substring to trim from the string.")).
-		AtVersion("1.0.0"),
+		AtVersion("1.0.0").OnTargets(value.TString, value.TBytes).Returns(value.TString, value.TBytes),
 	func(args *ParsedParams) (simpleMethod, error) {
 		prefix, err := args.FieldString("prefix")
 		if err != nil {
@@ -2045,7 +2045,7 @@ root.description = this.description.trim_suffix("_foobar")`,
 			`{"description":"unchanged","name":"blobton"}`,
 		),
 	).Param(ParamString("suffix", "The trailing suffix substring to trim from the string.")).
-		AtVersion("1.0.0"),
+		AtVersion("1.0.0").OnTargets(value.TString, value.TBytes).Returns(value.TString, value.TBytes),
 	func(args *ParsedParams) (simpleMethod, error) {
 		suffix, err := args.FieldString("suffix")
 		if err != nil {
@@ -2076,7 +2076,7 @@ var _ = registerSimpleMethod(
 			`{"fruit":"banana"}`,
 		),
 	).Param(ParamInt64("count", "Number of copies. If less than zero, it is treated as zero: resulting in an empty output.")).
-		AtVersion("1.5.0"),
+		AtVersion("1.5.0").OnTargets(value.TString, value.TBytes).Returns(value.TString, value.TBytes),
 	func(args *ParsedParams) (simpleMethod, error) {
 		count, err := args.FieldInt64("count")
 		if err != nil {
@@ -28,7 +28,7 @@ var _ = registerSimpleMethod(
 			`{"patrons":[{"id":"1","age":45},{"id":"2","age":23}]}`,
 			`{"all_over_21":true}`,
 		),
-	).Param(ParamQuery("test", "A test query to apply to each element.", false)),
+	).Param(ParamQuery("test", "A test query to apply to each element.", false)).OnTargets(value.TArray).Returns(value.TBool),
 	func(args *ParsedParams) (simpleMethod, error) {
 		queryFn, err := args.FieldQuery("test")
 		if err != nil {
@@ -74,7 +74,7 @@ var _ = registerSimpleMethod(
 			`{"patrons":[{"id":"1","age":10},{"id":"2","age":12}]}`,
 			`{"any_over_21":false}`,
 		),
-	).Param(ParamQuery("test", "A test query to apply to each element.", false)),
+	).Param(ParamQuery("test", "A test query to apply to each element.", false)).OnTargets(value.TArray).Returns(value.TBool),
 	func(args *ParsedParams) (simpleMethod, error) {
 		queryFn, err := args.FieldQuery("test")
 		if err != nil {
@@ -123,7 +123,7 @@ var _ = registerSimpleMethod(
 			`{"foo":["bar","baz"]}`,
 			`{"foo":["bar","baz","and","this"]}`,
 		),
-	).VariadicParams(),
+	).VariadicParams().OnTargets(value.TArray).Returns(value.TArray),
 	func(args *ParsedParams) (simpleMethod, error) {
 		argsList := args.Raw()
 		return func(res any, ctx FunctionContext) (any, error) {
@@ -205,7 +205,7 @@ var _ = registerSimpleMethod(
 			`{"thing":"this bar that"}`,
 			`{"has_foo":false}`,
 		),
-	).Param(ParamAny("value", "A value to test against elements of the target.")),
+	).Param(ParamAny("value", "A value to test against elements of the target.")).OnTargets(value.TString, value.TBytes, value.TArray, value.TObject).Returns(value.TBool),
 	func(args *ParsedParams) (simpleMethod, error) {
 		compareRight, err := args.Field("value")
 		if err != nil {
@@ -252,7 +252,7 @@ var _ = registerSimpleMethod(
 			`{"foo":["bar","baz"]}`,
 			`{"foo":[{"index":0,"value":"bar"},{"index":1,"value":"baz"}]}`,
 		),
-	),
+	).OnTargets(value.TArray).Returns(value.TArray),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v any, ctx FunctionContext) (any, error) {
 			arr, ok := v.([]any)
@@ -286,7 +286,7 @@ var _ = registerSimpleMethod(
 			`{"foo":{}}`,
 			`{"result":false}`,
 		),
-	).Param(ParamString("path", "A [dot path][field_paths] to a field.")),
+	).Param(ParamString("path", "A [dot path][field_paths] to a field.")).Returns(value.TBool),
 	func(args *ParsedParams) (simpleMethod, error) {
 		pathStr, err := args.FieldString("path")
 		if err != nil {
@@ -381,7 +381,7 @@ When filtering objects the mapping query argument is provided a context with a f
 			`{"dict":{"first":"hello foo","second":"world","third":"this foo is great"}}`,
 			`{"new_dict":{"first":"hello foo","third":"this foo is great"}}`,
 		),
-	).Param(ParamQuery("test", "A query to apply to each element, if this query resolves to any value other than a boolean `true` the element will be removed from the result.", false)),
+	).Param(ParamQuery("test", "A query to apply to each element, if this query resolves to any value other than a boolean `true` the element will be removed from the result.", false)).OnTargets(value.TArray, value.TObject),
 	func(args *ParsedParams) (simpleMethod, error) {
 		mapFn, err := args.FieldQuery("test")
 		if err != nil {
@@ -444,7 +444,7 @@ var _ = registerSimpleMethod(
 			`{"goal":"bar","things":["foo", "bar", "baz"]}`,
 			`{"index":1}`,
 		),
-	).Param(ParamAny("value", "A value to find.")),
+	).Param(ParamAny("value", "A value to find.")).OnTargets(value.TArray).Returns(value.TNumber),
 	func(args *ParsedParams) (simpleMethod, error) {
 		val, err := args.Field("value")
 		if err != nil {
@@ -613,7 +613,7 @@ var _ = registerSimpleMethod(
 			`["foo",["bar","baz"],"buz"]`,
 			`{"result":["foo","bar","baz","buz"]}`,
 		),
-	),
+	).OnTargets(value.TArray).Returns(value.TArray),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v any, ctx FunctionContext) (any, error) {
 			array, isArray := v.([]any)
@@ -709,7 +709,7 @@ var _ = registerSimpleMethod(
 			`{"name":"foobar bazson"}`,
 			`{"last_byte":110}`,
 		),
-	).Param(ParamInt64("index", "The index to obtain from an array.")),
+	).Param(ParamInt64("index", "The index to obtain from an array.")).OnTargets(value.TArray, value.TBytes),
 	func(args *ParsedParams) (simpleMethod, error) {
 		index, err := args.FieldInt64("index")
 		if err != nil {
@@ -816,7 +816,7 @@ var _ = registerSimpleMethod(
 			`{"foo":{"bar":1,"baz":2}}`,
 			`{"foo_keys":["bar","baz"]}`,
 		),
-	),
+	).OnTargets(value.TObject).Returns(value.TArray),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v any, ctx FunctionContext) (any, error) {
 			if m, ok := v.(map[string]any); ok {
@@ -846,7 +846,7 @@ var _ = registerSimpleMethod(
 			`{"foo":{"bar":1,"baz":2}}`,
 			`{"foo_key_values":[{"key":"bar","value":1},{"key":"baz","value":2}]}`,
 		),
-	),
+	).OnTargets(value.TObject).Returns(value.TArray),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v any, ctx FunctionContext) (any, error) {
 			if m, ok := v.(map[string]any); ok {
@@ -885,7 +885,7 @@ var _ = registerSimpleMethod(
 			`{"foo":{"first":"bar","second":"baz"}}`,
 			`{"foo_len":2}`,
 		),
-	),
+	).OnTargets(value.TString, value.TBytes, value.TArray, value.TObject).Returns(value.TNumber),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v any, ctx FunctionContext) (any, error) {
 			var length int64
@@ -931,7 +931,7 @@ Apply a mapping to each value of an object and replace the value with the result
 			`{"dict":{"foo":"hello","bar":"world"}}`,
 			`{"new_dict":{"bar":"WORLD","foo":"HELLO"}}`,
 		),
-	).Param(ParamQuery("query", "A query that will be used to map each element.", false)),
+	).Param(ParamQuery("query", "A query that will be used to map each element.", false)).OnTargets(value.TArray, value.TObject),
 	func(args *ParsedParams) (simpleMethod, error) {
 		mapFn, err := args.FieldQuery("query")
 		if err != nil {
@@ -1005,7 +1005,7 @@ var _ = registerSimpleMethod(
 			`{"amqp_key":"foo","kafka_key":"bar","kafka_topic":"baz"}`,
 			`{"_kafka_key":"bar","_kafka_topic":"baz","amqp_key":"foo"}`,
 		),
-	).Param(ParamQuery("query", "A query that will be used to map each key.", false)),
+	).Param(ParamQuery("query", "A query that will be used to map each key.", false)).OnTargets(value.TObject).Returns(value.TObject),
 	func(args *ParsedParams) (simpleMethod, error) {
 		mapFn, err := args.FieldQuery("query")
 		if err != nil {
@@ -1173,7 +1173,7 @@ var _ = registerSimpleMethod(
 			`{"a":{}}`,
 			`Error("failed assignment (line 1): field `+"`this.a`"+`: object value is empty")`,
 		),
-	),
+	).OnTargets(value.TString, value.TArray, value.TObject),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v any, ctx FunctionContext) (any, error) {
 			switch t := v.(type) {
@@ -1674,7 +1674,7 @@ var _ = registerSimpleMethod(
 			`{"foo":{"bar":1,"baz":2}}`,
 			`{"foo_vals":[1,2]}`,
 		),
-	),
+	).OnTargets(value.TObject).Returns(value.TArray),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v any, ctx FunctionContext) (any, error) {
 			if m, ok := v.(map[string]any); ok {
@@ -1704,7 +1704,7 @@ If a key within a nested path does not exist or is not an object then it is not
 			`{"inner":{"a":"first","b":"second","c":"third"},"d":"fourth","e":"fifth"}`,
 			`{"e":"fifth","inner":{"b":"second"}}`,
 		),
-	).VariadicParams(),
+	).VariadicParams().OnTargets(value.TObject).Returns(value.TObject),
 	func(args *ParsedParams) (simpleMethod, error) {
 		excludeList := make([][]string, 0, len(args.Raw()))
 		for i, argVal := range args.Raw() {
@@ -0,0 +1,468 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/warpstreamlabs/bento/internal/value"
+)
+
+// TypeError is a definite type error found whilst statically checking the
+// types of a query, meaning the query would fail whenever it is executed upon
+// data matching the checked schema.
+type TypeError struct {
+	// Input points to the remaining input of the parsed mapping at the location
+	// of the error, and is nil when the location is unknown.
+	Input []rune
+	Err   error
+}
+
+// Error implements the standard error interface.
+func (t *TypeError) Error() string {
+	return t.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (t *TypeError) Unwrap() error {
+	return t.Err
+}
+
+// TypeContext describes the values available to a query whilst its types are
+// statically checked.
+type TypeContext struct {
+	// Value describes the context of the query, which is the value referenced
+	// with `this`.
+	Value *TypeSchema
+
+	// Vars describes the variables available to the query.
+	Vars map[string]*TypeSchema
+}
+
+// CheckTypes statically infers the type of the value produced by a function
+// and returns it along with any definite type errors found within the
+// function. Method and function calls are only checked when the function was
+// parsed with type checking enabled, as otherwise the information required is
+// not retained.
+func CheckTypes(fn Function, ctx TypeContext) (*TypeSchema, []*TypeError) {
+	var errs []*TypeError
+	c := typeCheckContext{
+		value: ctx.Value,
+		vars:  ctx.Vars,
+		errs:  &errs,
+	}
+	return c.infer(fn), errs
+}
+
+// typeInferer is implemented by functions that are able to statically infer
+// the type of the value they produce.
+type typeInferer interface {
+	inferType(c typeCheckContext) *TypeSchema
+}
+
+type namedTypeSchema struct {
+	name   string
+	schema *TypeSchema
+	next   *namedTypeSchema
+}
+
+type typeCheckContext struct {
+	value *TypeSchema
+	named *namedTypeSchema
+	vars  map[string]*TypeSchema
+	input []rune
+	errs  *[]*TypeError
+}
+
+func (c typeCheckContext) infer(fn Function) *TypeSchema {
+	if t, ok := fn.(typeInferer); ok {
+		return t.inferType(c)
+	}
+	return nil
+}
+
+func (c typeCheckContext) report(err error) {
+	*c.errs = append(*c.errs, &TypeError{Input: c.input, Err: err})
+}
+
+// discarded returns a context where errors are not reported, which is used for
+// queries where errors are recovered from at runtime.
+func (c typeCheckContext) discarded() typeCheckContext {
+	c.errs = &[]*TypeError{}
+	return c
+}
+
+func (c typeCheckContext) withValue(v *TypeSchema) typeCheckContext {
+	c.value = v
+	return c
+}
+
+func (c typeCheckContext) withNamed(name string, v *TypeSchema) typeCheckContext {
+	c.named = &namedTypeSchema{name: name, schema: v, next: c.named}
+	return c
+}
+
+func (c typeCheckContext) namedValue(name string) (*TypeSchema, bool) {
+	for n := c.named; n != nil; n = n.next {
+		if n.name == name {
+			return n.schema, true
+		}
+	}
+	return nil, false
+}
+
+func newTypeError(from Function, actual typeSet, expected ...value.Type) error {
+	return &value.TypeError{
+		From:     from.Annotation(),
+		Expected: expected,
+		Actual:   value.Type(actual.String()),
+	}
+}
+
+func (c typeCheckContext) reportMissingField(annotation string, base *TypeSchema, path []string, missing int) {
+	parent, _ := lookupTypeSchemaPath(base, path[:missing])
+	if parent == nil {
+		return
+	}
+	c.report(fmt.Errorf("%v does not exist, the field %v is not one of the known fields %v", annotation, path[missing], sortedTypeSchemaKeys(parent)))
+}
+
+//------------------------------------------------------------------------------
+
+func (f closureFunction) inferType(c typeCheckContext) *TypeSchema {
+	if f.types == nil {
+		return nil
+	}
+	return f.types(c)
+}
+
+func (l *Literal) inferType(c typeCheckContext) *TypeSchema {
+	return typeSchemaOfValue(l.Value)
+}
+
+func (f *fieldFunction) inferType(c typeCheckContext) *TypeSchema {
+	var base *TypeSchema
+	if f.fromRoot {
+		return nil
+	} else if f.namedContext == "" {
+		base = c.value
+	} else {
+		var exists bool
+		if base, exists = c.namedValue(f.namedContext); !exists {
+			return nil
+		}
+	}
+	res, missing := lookupTypeSchemaPath(base, f.path)
+	if missing >= 0 {
+		c.reportMissingField(f.Annotation(), base, f.path, missing)
+		return nil
+	}
+	return res
+}
+
+func (g *getMethod) inferType(c typeCheckContext) *TypeSchema {
+	base := c.infer(g.fn)
+	res, missing := lookupTypeSchemaPath(base, g.path)
+	if missing >= 0 {
+		c.reportMissingField(g.Annotation(), base, g.path, missing)
+		return nil
+	}
+	return res
+}
+
+func (n *notMethod) inferType(c typeCheckContext) *TypeSchema {
+	if t := c.infer(n.fn).set(); t&typeSetBool == 0 {
+		c.report(newTypeError(n.fn, t, value.TBool))
+		return nil
+	}
+	return NewTypeSchema(value.TBool)
+}
+
+func (n *NamedContextFunction) inferType(c typeCheckContext) *TypeSchema {
+	if n.name != "_" {
+		c = c.withNamed(n.name, c.value)
+	}
+	return c.withValue(nil).infer(n.fn)
+}
+
+func (m *mapLiteral) inferType(c typeCheckContext) *TypeSchema {
+	res := &TypeSchema{
+		Types:      []value.Type{value.TObject},
+		Properties: make(map[string]*TypeSchema, len(m.keyValues)),
+		Closed:     true,
+	}
+	for _, kv := range m.keyValues {
+		var v *TypeSchema
+		if fn, isFn := kv[1].(Function); isFn {
+			v = c.infer(fn)
+		} else {
+			v = typeSchemaOfValue(kv[1])
+		}
+		key, isStr := kv[0].(string)
+		if !isStr {
+			c.infer(kv[0].(Function))
+			res.Closed = false
+			continue
+		}
+		if v.set()&(typeSetDelete|typeSetNothing) != 0 {
+			v = v.withNull()
+		}
+		res.Properties[key] = v
+	}
+	return res
+}
+
+func (a *arrayLiteral) inferType(c typeCheckContext) *TypeSchema {
+	items := make([]*TypeSchema, len(a.values))
+	for i, v := range a.values {
+		if fn, isFn := v.(Function); isFn {
+			items[i] = c.infer(fn)
+		} else {
+			items[i] = typeSchemaOfValue(v)
+		}
+	}
+	return &TypeSchema{
+		Types: []value.Type{value.TArray},
+		Items: unionTypeSchemas(items...),
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// typeCheckedCall wraps a method or function call with the information
+// required in order to statically check the types of its target and
+// arguments.
+type typeCheckedCall struct {
+	input       []rune
+	kind        string
+	name        string
+	params      Params
+	targetTypes []value.Type
+	returnTypes []value.Type
+
+	target Function
+	args   *ParsedParams
+	fn     Function
+}
+
+// NewTypeCheckedMethod wraps a method call with the information required for
+// statically checking its types, where input points to the location of the
+// call within a parsed mapping.
+func NewTypeCheckedMethod(input []rune, spec MethodSpec, target Function, args *ParsedParams, fn Function) Function {
+	return &typeCheckedCall{
+		input:       input,
+		kind:        "method",
+		name:        spec.Name,
+		params:      spec.Params,
+		targetTypes: spec.TargetTypes,
+		returnTypes: spec.ReturnTypes,
+		target:      target,
+		args:        args,
+		fn:          fn,
+	}
+}
+
+// NewTypeCheckedFunction wraps a function call with the information required
+// for statically checking its types, where input points to the location of the
+// call within a parsed mapping.
+func NewTypeCheckedFunction(input []rune, spec FunctionSpec, args *ParsedParams, fn Function) Function {
+	return &typeCheckedCall{
+		input:       input,
+		kind:        "function",
+		name:        spec.Name,
+		params:      spec.Params,
+		returnTypes: spec.ReturnTypes,
+		args:        args,
+		fn:          fn,
+	}
+}
+
+func (t *typeCheckedCall) Annotation() string {
+	return t.fn.Annotation()
+}
+
+func (t *typeCheckedCall) Exec(ctx FunctionContext) (any, error) {
+	return t.fn.Exec(ctx)
+}
+
+func (t *typeCheckedCall) QueryTargets(ctx TargetsContext) (TargetsContext, []TargetPath) {
+	return t.fn.QueryTargets(ctx)
+}
+
+// Methods that recover from errors of their target.
+var errorRecoveringMethods = map[string]struct{}{
+	"catch": {},
+	"or":    {},
+}
+
+func paramTypeSet(t value.Type) typeSet {
+	switch t {
+	case value.TString:
+		return typeSetString | typeSetBytes
+	case value.TTimestamp:
+		return typeSetTimestamp | typeSetString | typeSetBytes | typeSetNumber
+	case value.TBool:
+		return typeSetBool | typeSetNumber
+	}
+	return typeSetOf(t)
+}
+
+func (t *typeCheckedCall) inferType(c typeCheckContext) *TypeSchema {
+	var targetType *TypeSchema
+	if t.target != nil {
+		if _, recovers := errorRecoveringMethods[t.name]; recovers {
+			targetType = c.discarded().infer(t.target)
+		} else {
+			targetType = c.infer(t.target)
+		}
+	}
+
+	callCtx := c
+	callCtx.input = t.input
+
+	failed := false
+	if t.args != nil {
+		for _, dyn := range t.args.dynArgs {
+			argType := c.infer(dyn.fn).set()
+			if dyn.index >= len(t.params.Definitions) {
+				continue
+			}
+			def := t.params.Definitions[dyn.index]
+			if argType&paramTypeSet(def.ValueType) == 0 {
+				callCtx.report(fmt.Errorf("%v %v: argument %v: %w", t.kind, t.name, def.Name, newTypeError(dyn.fn, argType, def.ValueType)))
+				failed = true
+			}
+		}
+		for i, def := range t.params.Definitions {
+			if def.ValueType != value.TQuery || i >= len(t.args.values) {
+				continue
+			}
+			if fn, isFn := t.args.values[i].(Function); isFn {
+				// Queries are executed upon a context that we do not yet infer,
+				// but can still contain errors of their own.
+				c.withValue(nil).infer(fn)
+			}
+		}
+	}
+
+	if t.target != nil && len(t.targetTypes) > 0 {
+		if actual := targetType.set(); actual&typeSetOf(t.targetTypes...) == 0 {
+			callCtx.report(fmt.Errorf("%v %v: %w", t.kind, t.name, newTypeError(t.target, actual, t.targetTypes...)))
+			failed = true
+		}
+	}
+
+	if failed {
+		return nil
+	}
+	return NewTypeSchema(t.returnTypes...)
+}
+
+//------------------------------------------------------------------------------
+
+func arithmeticPairTypes(op ArithmeticOperator, l, r typeSet) (typeSet, bool) {
+	stringy := typeSetString | typeSetBytes
+	switch op {
+	case ArithmeticAdd:
+		if l == typeSetNumber && r == typeSetNumber {
+			return typeSetNumber, true
+		}
+		if l&stringy != 0 && r&(stringy|typeSetTimestamp) != 0 {
+			return typeSetString, true
+		}
+	case ArithmeticSub, ArithmeticMul, ArithmeticDiv, ArithmeticMod:
+		if l == typeSetNumber && r == typeSetNumber {
+			return typeSetNumber, true
+		}
+	case ArithmeticEq, ArithmeticNeq:
+		return typeSetBool, true
+	case ArithmeticGt, ArithmeticGte, ArithmeticLt, ArithmeticLte:
+		comparable := stringy | typeSetTimestamp
+		if (l == typeSetNumber && r == typeSetNumber) || (l&comparable != 0 && r&comparable != 0) {
+			return typeSetBool, true
+		}
+	}
+	return 0, false
+}
+
+func arithmeticTypes(op ArithmeticOperator, lhs, rhs Function) func(c typeCheckContext) *TypeSchema {
+	return func(c typeCheckContext) *TypeSchema {
+		l, r := c.infer(lhs).set(), c.infer(rhs).set()
+		if l == typeSetAny || r == typeSetAny {
+			switch op {
+			case ArithmeticAdd:
+				return nil
+			case ArithmeticSub, ArithmeticMul, ArithmeticDiv, ArithmeticMod:
+				return NewTypeSchema(value.TNumber)
+			}
+			return NewTypeSchema(value.TBool)
+		}
+
+		var res typeSet
+		valid := false
+		for _, ln := range typeSetNames {
+			for _, rn := range typeSetNames {
+				if l&ln.set == 0 || r&rn.set == 0 {
+					continue
+				}
+				if t, ok := arithmeticPairTypes(op, ln.set, rn.set); ok {
+					res |= t
+					valid = true
+				}
+			}
+		}
+		if !valid {
+			c.report(&TypeMismatch{
+				Lfn:       lhs,
+				Rfn:       rhs,
+				Left:      value.Type(l.String()),
+				Right:     value.Type(r.String()),
+				Operation: op.String(),
+			})
+			return nil
+		}
+		return NewTypeSchema(res.types()...)
+	}
+}
+
+func coalesceTypes(lhs, rhs Function) func(c typeCheckContext) *TypeSchema {
+	return func(c typeCheckContext) *TypeSchema {
+		l := c.discarded().infer(lhs)
+		r := c.infer(rhs)
+		if l == nil {
+			return nil
+		}
+		return unionTypeSchemas(l.withoutNull(), r)
+	}
+}
+
+func boolOpTypes(lhs, rhs Function) func(c typeCheckContext) *TypeSchema {
+	return func(c typeCheckContext) *TypeSchema {
+		for _, fn := range []Function{lhs, rhs} {
+			if t := c.infer(fn).set(); t&(typeSetBool|typeSetNumber) == 0 {
+				c.report(newTypeError(fn, t, value.TBool))
+			}
+		}
+		return NewTypeSchema(value.TBool)
+	}
+}
+
+func checkConditionType(c typeCheckContext, fn Function, allowNull bool) {
+	allowed := typeSetBool
+	if allowNull {
+		allowed |= typeSetNull
+	}
+	if t := c.infer(fn).set(); t&allowed == 0 {
+		c.report(newTypeError(fn, t, value.TBool))
+	}
+}
+
+// CheckConditionTypes statically checks that a query used as a condition can
+// resolve to a boolean value.
+func CheckConditionTypes(fn Function, ctx TypeContext) []*TypeError {
+	var errs []*TypeError
+	checkConditionType(typeCheckContext{
+		value: ctx.Value,
+		vars:  ctx.Vars,
+		errs:  &errs,
+	}, fn, false)
+	return errs
+}
@@ -0,0 +1,135 @@
+package query_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang"
+	"github.com/warpstreamlabs/bento/internal/bloblang/query"
+)
+
+func TestCheckTypes(t *testing.T) {
+	schema, err := query.TypeSchemaFromJSONSchema([]byte(`{
+  "type": "object",
+  "properties": {
+    "id": { "type": "integer" },
+    "name": { "type": "string" },
+    "nickname": { "type": "string" },
+    "tags": { "type": "array", "items": { "type": "string" } },
+    "meta": { "type": "object" }
+  },
+  "required": [ "id", "name", "tags" ],
+  "additionalProperties": false
+}`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		mapping string
+		schema  *query.TypeSchema
+		errs    []string
+	}{
+		{
+			name:    "no errors",
+			mapping: `root.a = this.name.uppercase()`,
+			schema:  schema,
+		},
+		{
+			name:    "method on wrong input type",
+			mapping: `root.a = this.id.uppercase()`,
+			schema:  schema,
+			errs:    []string{"method uppercase: expected string or bytes value, got number from field `this.id`"},
+		},
+		{
+			name:    "method on literal without schema",
+			mapping: `root.a = 5.uppercase()`,
+			errs:    []string{"method uppercase: expected string or bytes value, got number from number literal"},
+		},
+		{
+			name:    "method on return type of method",
+			mapping: `root.a = this.name.length().uppercase()`,
+			errs:    []string{"method uppercase: expected string or bytes value, got number from method length"},
+		},
+		{
+			name:    "unknown input type",
+			mapping: `root.a = this.id.uppercase()`,
+		},
+		{
+			name:    "optional field may be null",
+			mapping: `root.a = this.nickname.uppercase()`,
+			schema:  schema,
+		},
+		{
+			name:    "missing field of closed object",
+			mapping: `root.a = this.nope`,
+			schema:  schema,
+			errs:    []string{"field `this.nope` does not exist, the field nope is not one of the known fields [id meta name nickname tags]"},
+		},
+		{
+			name:    "field of open object",
+			mapping: `root.a = this.meta.nope.uppercase()`,
+			schema:  schema,
+		},
+		{
+			name:    "errors are recovered by catch",
+			mapping: `root.a = this.id.uppercase().catch("nope")`,
+			schema:  schema,
+		},
+		{
+			name:    "bad arithmetic",
+			mapping: `root.a = this.id + "foo"`,
+			schema:  schema,
+			errs:    []string{"cannot add types number (from field `this.id`) and string (from string literal)"},
+		},
+		{
+			name: "variable types",
+			mapping: `let x = 10
+root.a = $x.uppercase()`,
+			errs: []string{"method uppercase: expected string or bytes value, got number from variable x"},
+		},
+		{
+			name: "variable conditionally reassigned",
+			mapping: `let x = 10
+if this.foo == "bar" {
+  let x = "foo"
+}
+root.a = $x.uppercase()`,
+		},
+		{
+			name:    "non boolean condition",
+			mapping: `if this.id { root = "foo" }`,
+			schema:  schema,
+			errs:    []string{"expected bool value, got number from field `this.id`"},
+		},
+		{
+			name: "errors within maps",
+			mapping: `map foo {
+  root = 10.uppercase()
+}
+root = this.apply("foo")`,
+			errs: []string{"method uppercase: expected string or bytes value, got number from number literal"},
+		},
+		{
+			name:    "match context",
+			mapping: `root = match this.name { this.length() > 5 => this.uppercase(), _ => this.floor() }`,
+			schema:  schema,
+			errs:    []string{"method floor: expected number value, got string from field `this`"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			tErrs, err := bloblang.GlobalEnvironment().CheckMappingTypes(test.mapping, test.schema)
+			require.NoError(t, err)
+
+			var errStrs []string
+			for _, tErr := range tErrs {
+				errStrs = append(errStrs, tErr.Error())
+			}
+			assert.Equal(t, test.errs, errStrs)
+		})
+	}
+}
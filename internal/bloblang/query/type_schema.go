@@ -0,0 +1,634 @@
+package query
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/warpstreamlabs/bento/internal/value"
+)
+
+// TypeSchema describes the possible types and structure of a value, and is used
+// in order to statically check the types of queries and mappings. A nil
+// *TypeSchema describes a value of an unknown type.
+type TypeSchema struct {
+	// Types lists the types that the value could be, an empty list means the
+	// type is unknown.
+	Types []value.Type
+
+	// Properties describes the known fields of an object value.
+	Properties map[string]*TypeSchema
+
+	// Closed indicates that an object value cannot contain any fields other
+	// than those listed in Properties.
+	Closed bool
+
+	// Items describes the elements of an array value.
+	Items *TypeSchema
+}
+
+// NewTypeSchema creates a schema describing a value that could be any of the
+// provided types.
+func NewTypeSchema(types ...value.Type) *TypeSchema {
+	if len(types) == 0 {
+		return nil
+	}
+	return &TypeSchema{Types: types}
+}
+
+// String returns a human readable description of the types of a schema.
+func (t *TypeSchema) String() string {
+	return t.set().String()
+}
+
+func (t *TypeSchema) set() typeSet {
+	if t == nil {
+		return typeSetAny
+	}
+	return typeSetOf(t.Types...)
+}
+
+// withoutNull returns a version of the schema where null values are excluded.
+func (t *TypeSchema) withoutNull() *TypeSchema {
+	if t == nil {
+		return nil
+	}
+	s := t.set() &^ typeSetNull
+	if s == 0 || s == t.set() {
+		return t
+	}
+	n := *t
+	n.Types = s.types()
+	return &n
+}
+
+// withNull returns a version of the schema where null values are included.
+func (t *TypeSchema) withNull() *TypeSchema {
+	if t == nil || t.set()&typeSetNull != 0 {
+		return t
+	}
+	n := *t
+	n.Types = (t.set() | typeSetNull).types()
+	return &n
+}
+
+// unionTypeSchemas returns a schema describing a value that could match any of
+// the provided schemas.
+func unionTypeSchemas(schemas ...*TypeSchema) *TypeSchema {
+	if len(schemas) == 0 {
+		return nil
+	}
+
+	var set typeSet
+	res := &TypeSchema{}
+	var objects, arrays int
+	for _, s := range schemas {
+		if s == nil || s.set() == typeSetAny {
+			return nil
+		}
+		set |= s.set()
+		if s.Properties != nil {
+			objects++
+		}
+		if s.Items != nil {
+			arrays++
+		}
+	}
+	res.Types = set.types()
+
+	// Structures are only retained when every object or array variant
+	// describes them, otherwise the structure is unknown.
+	for _, s := range schemas {
+		if s.set()&typeSetObject != 0 && s.Properties == nil {
+			objects = -1
+		}
+		if s.set()&typeSetArray != 0 && s.Items == nil {
+			arrays = -1
+		}
+	}
+	if objects > 0 {
+		res.Properties = map[string]*TypeSchema{}
+		res.Closed = true
+		for _, s := range schemas {
+			if s.Properties == nil {
+				continue
+			}
+			res.Closed = res.Closed && s.Closed
+			for k, v := range s.Properties {
+				if existing, exists := res.Properties[k]; exists {
+					res.Properties[k] = unionTypeSchemas(existing, v)
+				} else {
+					res.Properties[k] = v
+				}
+			}
+		}
+		if res.Closed {
+			// Fields that only exist in some variants might be missing.
+			for k, v := range res.Properties {
+				for _, s := range schemas {
+					if _, exists := s.Properties[k]; s.Properties != nil && !exists {
+						res.Properties[k] = v.withNull()
+						break
+					}
+				}
+			}
+		}
+	}
+	if arrays > 0 {
+		var items []*TypeSchema
+		for _, s := range schemas {
+			if s.Items != nil {
+				items = append(items, s.Items)
+			}
+		}
+		res.Items = unionTypeSchemas(items...)
+	}
+	return res
+}
+
+// typeSchemaOfValue returns a schema describing a static value.
+func typeSchemaOfValue(v any) *TypeSchema {
+	switch t := v.(type) {
+	case map[string]any:
+		props := make(map[string]*TypeSchema, len(t))
+		for k, e := range t {
+			props[k] = typeSchemaOfValue(e)
+		}
+		return &TypeSchema{Types: []value.Type{value.TObject}, Properties: props, Closed: true}
+	case []any:
+		s := &TypeSchema{Types: []value.Type{value.TArray}}
+		if len(t) > 0 {
+			items := make([]*TypeSchema, len(t))
+			for i, e := range t {
+				items[i] = typeSchemaOfValue(e)
+			}
+			s.Items = unionTypeSchemas(items...)
+		}
+		return s
+	}
+	return NewTypeSchema(value.ITypeOf(v))
+}
+
+//------------------------------------------------------------------------------
+
+// typeSet is a bit set of the discrete types that a value could be.
+type typeSet uint16
+
+const (
+	typeSetString typeSet = 1 << iota
+	typeSetBytes
+	typeSetNumber
+	typeSetBool
+	typeSetTimestamp
+	typeSetArray
+	typeSetObject
+	typeSetNull
+	typeSetDelete
+	typeSetNothing
+
+	typeSetAny = typeSetString | typeSetBytes | typeSetNumber | typeSetBool |
+		typeSetTimestamp | typeSetArray | typeSetObject | typeSetNull |
+		typeSetDelete | typeSetNothing
+)
+
+var typeSetNames = []struct {
+	set typeSet
+	t   value.Type
+}{
+	{typeSetString, value.TString},
+	{typeSetBytes, value.TBytes},
+	{typeSetNumber, value.TNumber},
+	{typeSetBool, value.TBool},
+	{typeSetTimestamp, value.TTimestamp},
+	{typeSetArray, value.TArray},
+	{typeSetObject, value.TObject},
+	{typeSetNull, value.TNull},
+	{typeSetDelete, value.TDelete},
+	{typeSetNothing, value.TNothing},
+}
+
+func typeSetOf(types ...value.Type) typeSet {
+	if len(types) == 0 {
+		return typeSetAny
+	}
+	var s typeSet
+	for _, t := range types {
+		switch t {
+		case value.TInt, value.TFloat:
+			t = value.TNumber
+		case value.TUnknown, value.TQuery:
+			return typeSetAny
+		}
+		for _, n := range typeSetNames {
+			if n.t == t {
+				s |= n.set
+			}
+		}
+	}
+	return s
+}
+
+func (s typeSet) types() []value.Type {
+	if s == typeSetAny {
+		return nil
+	}
+	var types []value.Type
+	for _, n := range typeSetNames {
+		if s&n.set != 0 {
+			types = append(types, n.t)
+		}
+	}
+	return types
+}
+
+// String returns the types of the set, in the same form as value.TypeError.
+func (s typeSet) String() string {
+	if s == typeSetAny {
+		return string(value.TUnknown)
+	}
+	types := s.types()
+	var b strings.Builder
+	for i, t := range types {
+		if i > 0 {
+			if len(types) > 2 && i < len(types)-1 {
+				b.WriteString(", ")
+			} else {
+				b.WriteString(" or ")
+			}
+		}
+		b.WriteString(string(t))
+	}
+	return b.String()
+}
+
+//------------------------------------------------------------------------------
+
+// TypeSchemaFromJSONSchema creates a type schema from a JSON Schema document.
+// Only the structural aspects of the schema (types, properties, items and
+// compositions of them) are considered, and any keywords that cannot be
+// represented result in parts of the schema being treated as unknown.
+func TypeSchemaFromJSONSchema(schema []byte) (*TypeSchema, error) {
+	var root map[string]any
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON schema: %w", err)
+	}
+	c := jsonSchemaConverter{root: root}
+	return c.convert(root, 0)
+}
+
+type jsonSchemaConverter struct {
+	root map[string]any
+}
+
+const maxSchemaDepth = 32
+
+func (c *jsonSchemaConverter) resolveRef(ref string) (map[string]any, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("only local schema references are supported, got: %v", ref)
+	}
+	var current any = c.root
+	for _, seg := range strings.Split(strings.TrimPrefix(ref, "#"), "/") {
+		if seg == "" {
+			continue
+		}
+		seg = strings.ReplaceAll(strings.ReplaceAll(seg, "~1", "/"), "~0", "~")
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("failed to resolve schema reference: %v", ref)
+		}
+		if current, ok = obj[seg]; !ok {
+			return nil, fmt.Errorf("failed to resolve schema reference: %v", ref)
+		}
+	}
+	obj, ok := current.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("schema reference %v does not point to a schema", ref)
+	}
+	return obj, nil
+}
+
+func (c *jsonSchemaConverter) convertAll(v any, depth int) ([]*TypeSchema, error) {
+	arr, ok := v.([]any)
+	if !ok {
+		return nil, errors.New("expected an array of schemas")
+	}
+	schemas := make([]*TypeSchema, 0, len(arr))
+	for _, e := range arr {
+		obj, ok := e.(map[string]any)
+		if !ok {
+			return nil, nil
+		}
+		s, err := c.convert(obj, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, s)
+	}
+	return schemas, nil
+}
+
+func (c *jsonSchemaConverter) convert(schema map[string]any, depth int) (*TypeSchema, error) {
+	if depth > maxSchemaDepth {
+		// Recursive schemas are only checked up to a fixed depth.
+		return nil, nil
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := c.resolveRef(ref)
+		if err != nil {
+			return nil, err
+		}
+		return c.convert(target, depth+1)
+	}
+
+	for _, k := range []string{"anyOf", "oneOf"} {
+		if v, exists := schema[k]; exists {
+			schemas, err := c.convertAll(v, depth)
+			if err != nil || schemas == nil {
+				return nil, err
+			}
+			return unionTypeSchemas(schemas...), nil
+		}
+	}
+	if v, exists := schema["allOf"]; exists {
+		schemas, err := c.convertAll(v, depth)
+		if err != nil || len(schemas) != 1 {
+			return nil, err
+		}
+		return schemas[0], nil
+	}
+
+	if v, exists := schema["const"]; exists {
+		return typeSchemaOfValue(v), nil
+	}
+	if v, exists := schema["enum"]; exists {
+		arr, ok := v.([]any)
+		if !ok || len(arr) == 0 {
+			return nil, nil
+		}
+		schemas := make([]*TypeSchema, len(arr))
+		for i, e := range arr {
+			schemas[i] = NewTypeSchema(value.ITypeOf(e))
+		}
+		return unionTypeSchemas(schemas...), nil
+	}
+
+	var typeNames []string
+	switch t := schema["type"].(type) {
+	case string:
+		typeNames = []string{t}
+	case []any:
+		for _, e := range t {
+			if str, ok := e.(string); ok {
+				typeNames = append(typeNames, str)
+			}
+		}
+	}
+	if len(typeNames) == 0 {
+		return nil, nil
+	}
+
+	res := &TypeSchema{}
+	for _, name := range typeNames {
+		switch name {
+		case "string":
+			res.Types = append(res.Types, value.TString)
+		case "integer", "number":
+			res.Types = append(res.Types, value.TNumber)
+		case "boolean":
+			res.Types = append(res.Types, value.TBool)
+		case "null":
+			res.Types = append(res.Types, value.TNull)
+		case "array":
+			res.Types = append(res.Types, value.TArray)
+			if items, ok := schema["items"].(map[string]any); ok {
+				var err error
+				if res.Items, err = c.convert(items, depth+1); err != nil {
+					return nil, err
+				}
+			}
+		case "object":
+			res.Types = append(res.Types, value.TObject)
+			if err := c.convertObject(res, schema, depth); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unrecognised schema type: %v", name)
+		}
+	}
+	res.Types = typeSetOf(res.Types...).types()
+	return res, nil
+}
+
+func (c *jsonSchemaConverter) convertObject(res *TypeSchema, schema map[string]any, depth int) error {
+	props, _ := schema["properties"].(map[string]any)
+	if props == nil {
+		return nil
+	}
+
+	required := map[string]struct{}{}
+	if reqArr, ok := schema["required"].([]any); ok {
+		for _, r := range reqArr {
+			if str, ok := r.(string); ok {
+				required[str] = struct{}{}
+			}
+		}
+	}
+
+	res.Properties = make(map[string]*TypeSchema, len(props))
+	for k, v := range props {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			res.Properties[k] = nil
+			continue
+		}
+		p, err := c.convert(obj, depth+1)
+		if err != nil {
+			return fmt.Errorf("property %v: %w", k, err)
+		}
+		if _, isRequired := required[k]; !isRequired {
+			p = p.withNull()
+		}
+		res.Properties[k] = p
+	}
+	if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+		res.Closed = true
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// TypeSchemaFromAvro creates a type schema from an Avro schema document,
+// describing documents as they would be decoded into JSON. Unions of null and
+// a single other type are supported, but all other unions are treated as
+// unknown as their representation differs between decoders.
+func TypeSchemaFromAvro(schema []byte) (*TypeSchema, error) {
+	var root any
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse avro schema: %w", err)
+	}
+	c := avroConverter{named: map[string]any{}}
+	return c.convert(root, "", 0)
+}
+
+type avroConverter struct {
+	named map[string]any
+}
+
+func avroFullName(name, namespace string) string {
+	if namespace == "" || strings.Contains(name, ".") {
+		return name
+	}
+	return namespace + "." + name
+}
+
+func (c *avroConverter) convert(schema any, namespace string, depth int) (*TypeSchema, error) {
+	if depth > maxSchemaDepth {
+		return nil, nil
+	}
+
+	switch t := schema.(type) {
+	case string:
+		switch t {
+		case "null":
+			return NewTypeSchema(value.TNull), nil
+		case "boolean":
+			return NewTypeSchema(value.TBool), nil
+		case "int", "long", "float", "double":
+			return NewTypeSchema(value.TNumber), nil
+		case "string":
+			return NewTypeSchema(value.TString), nil
+		case "bytes":
+			return NewTypeSchema(value.TString, value.TBytes), nil
+		}
+		named, exists := c.named[avroFullName(t, namespace)]
+		if !exists {
+			if named, exists = c.named[t]; !exists {
+				return nil, fmt.Errorf("unknown avro type: %v", t)
+			}
+		}
+		return c.convert(named, namespace, depth+1)
+	case []any:
+		var nonNull []any
+		for _, e := range t {
+			if str, _ := e.(string); str != "null" {
+				nonNull = append(nonNull, e)
+			}
+		}
+		if len(nonNull) != 1 || len(t) != 2 {
+			return nil, nil
+		}
+		s, err := c.convert(nonNull[0], namespace, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		return s.withNull(), nil
+	case map[string]any:
+		typeName, _ := t["type"].(string)
+		if ns, ok := t["namespace"].(string); ok {
+			namespace = ns
+		}
+		if name, ok := t["name"].(string); ok && (typeName == "record" || typeName == "enum" || typeName == "fixed") {
+			c.named[avroFullName(name, namespace)] = t
+		}
+		if _, isLogical := t["logicalType"]; isLogical && typeName != "record" {
+			// Logical types are decoded differently depending on the decoder.
+			return nil, nil
+		}
+		switch typeName {
+		case "record":
+			return c.convertRecord(t, namespace, depth)
+		case "enum":
+			return NewTypeSchema(value.TString), nil
+		case "fixed":
+			return NewTypeSchema(value.TString, value.TBytes), nil
+		case "array":
+			items, err := c.convert(t["items"], namespace, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			return &TypeSchema{Types: []value.Type{value.TArray}, Items: items}, nil
+		case "map":
+			return NewTypeSchema(value.TObject), nil
+		}
+		return c.convert(t["type"], namespace, depth+1)
+	}
+	return nil, fmt.Errorf("unexpected avro schema: %v", schema)
+}
+
+func (c *avroConverter) convertRecord(record map[string]any, namespace string, depth int) (*TypeSchema, error) {
+	fields, _ := record["fields"].([]any)
+	res := &TypeSchema{
+		Types:      []value.Type{value.TObject},
+		Properties: make(map[string]*TypeSchema, len(fields)),
+		Closed:     true,
+	}
+	for i, f := range fields {
+		field, ok := f.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("field %v: expected object", i)
+		}
+		name, _ := field["name"].(string)
+		if name == "" {
+			return nil, fmt.Errorf("field %v: missing name", i)
+		}
+		s, err := c.convert(field["type"], namespace, depth+1)
+		if err != nil {
+			return nil, fmt.Errorf("field %v: %w", name, err)
+		}
+		res.Properties[name] = s
+	}
+	return res, nil
+}
+
+//------------------------------------------------------------------------------
+
+// lookupTypeSchemaPath walks a path of a schema and returns a schema describing
+// the value found at the path, and the index of the path segment that is
+// definitely missing from a closed object, or -1.
+func lookupTypeSchemaPath(s *TypeSchema, path []string) (*TypeSchema, int) {
+	for i, seg := range path {
+		if s == nil {
+			return nil, -1
+		}
+
+		set := s.set()
+		var variants []*TypeSchema
+		if set&typeSetObject != 0 {
+			if p, exists := s.Properties[seg]; exists {
+				variants = append(variants, p)
+			} else if s.Closed {
+				return nil, i
+			} else {
+				return nil, -1
+			}
+		}
+		if set&typeSetArray != 0 {
+			if _, err := strconv.Atoi(seg); err == nil {
+				// An index might be out of bounds.
+				variants = append(variants, s.Items.withNull())
+			} else {
+				variants = append(variants, NewTypeSchema(value.TNull))
+			}
+		}
+		if set&^(typeSetObject|typeSetArray) != 0 {
+			variants = append(variants, NewTypeSchema(value.TNull))
+		}
+		s = unionTypeSchemas(variants...)
+	}
+	return s, -1
+}
+
+// sortedTypeSchemaKeys returns the property keys of a schema in sorted order.
+func sortedTypeSchemaKeys(s *TypeSchema) []string {
+	keys := make([]string, 0, len(s.Properties))
+	for k := range s.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
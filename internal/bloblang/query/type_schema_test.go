@@ -0,0 +1,100 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/value"
+)
+
+func TestTypeSchemaFromJSONSchema(t *testing.T) {
+	s, err := TypeSchemaFromJSONSchema([]byte(`{
+  "$defs": {
+    "name": { "type": "string" }
+  },
+  "type": "object",
+  "properties": {
+    "id": { "type": "integer" },
+    "name": { "$ref": "#/$defs/name" },
+    "tags": { "type": "array", "items": { "type": "string" } },
+    "kind": { "enum": [ "foo", "bar" ] },
+    "extra": { "anyOf": [ { "type": "boolean" }, { "type": "number" } ] }
+  },
+  "required": [ "id", "name", "tags", "kind" ],
+  "additionalProperties": false
+}`))
+	require.NoError(t, err)
+
+	require.NotNil(t, s)
+	assert.Equal(t, []value.Type{value.TObject}, s.Types)
+	assert.True(t, s.Closed)
+	assert.Equal(t, []string{"extra", "id", "kind", "name", "tags"}, sortedTypeSchemaKeys(s))
+
+	assert.Equal(t, "number", s.Properties["id"].String())
+	assert.Equal(t, "string", s.Properties["name"].String())
+	assert.Equal(t, "array", s.Properties["tags"].String())
+	assert.Equal(t, "string", s.Properties["tags"].Items.String())
+	assert.Equal(t, "string", s.Properties["kind"].String())
+	assert.Equal(t, "number, bool or null", s.Properties["extra"].String())
+}
+
+func TestTypeSchemaFromJSONSchemaErrors(t *testing.T) {
+	_, err := TypeSchemaFromJSONSchema([]byte(`not json`))
+	require.Error(t, err)
+
+	_, err = TypeSchemaFromJSONSchema([]byte(`{"$ref":"#/$defs/nope"}`))
+	require.Error(t, err)
+}
+
+func TestTypeSchemaFromAvro(t *testing.T) {
+	s, err := TypeSchemaFromAvro([]byte(`{
+  "type": "record",
+  "name": "Person",
+  "namespace": "com.example",
+  "fields": [
+    { "name": "id", "type": "long" },
+    { "name": "name", "type": [ "null", "string" ] },
+    { "name": "friends", "type": { "type": "array", "items": "Person" } },
+    { "name": "attrs", "type": { "type": "map", "values": "string" } },
+    { "name": "created_at", "type": { "type": "long", "logicalType": "timestamp-millis" } }
+  ]
+}`))
+	require.NoError(t, err)
+
+	require.NotNil(t, s)
+	assert.True(t, s.Closed)
+	assert.Equal(t, "number", s.Properties["id"].String())
+	assert.Equal(t, "string or null", s.Properties["name"].String())
+	assert.Equal(t, "array", s.Properties["friends"].String())
+	assert.Equal(t, "object", s.Properties["friends"].Items.String())
+	assert.Equal(t, "object", s.Properties["attrs"].String())
+	assert.Nil(t, s.Properties["created_at"])
+}
+
+func TestLookupTypeSchemaPath(t *testing.T) {
+	s := &TypeSchema{
+		Types:  []value.Type{value.TObject},
+		Closed: true,
+		Properties: map[string]*TypeSchema{
+			"a": {
+				Types: []value.Type{value.TObject},
+				Properties: map[string]*TypeSchema{
+					"b": NewTypeSchema(value.TString),
+				},
+			},
+		},
+	}
+
+	res, missing := lookupTypeSchemaPath(s, []string{"a", "b"})
+	assert.Equal(t, -1, missing)
+	assert.Equal(t, "string", res.String())
+
+	res, missing = lookupTypeSchemaPath(s, []string{"a", "c"})
+	assert.Equal(t, -1, missing)
+	assert.Nil(t, res)
+
+	_, missing = lookupTypeSchemaPath(s, []string{"c", "d"})
+	assert.Equal(t, 0, missing)
+}
@@ -0,0 +1,82 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+)
+
+// UserFunction is a function declared within a mapping with named parameters.
+// When called, the parameters are available within the body of the function
+// as named contexts, and the context of the body (`this`) is an object
+// containing each argument keyed by its parameter name.
+type UserFunction struct {
+	name   string
+	params Params
+	body   Function
+	maps   map[string]Function
+}
+
+// NewUserFunction creates a user defined function with a name and a list of
+// parameter names. The body of the function must be set with SetBody before
+// it is executed, which allows the body to call the function recursively.
+func NewUserFunction(name string, paramNames []string) (*UserFunction, error) {
+	params := NewParams()
+	for _, p := range paramNames {
+		if _, exists := params.nameToIndex[p]; exists {
+			return nil, fmt.Errorf("duplicate parameter name: %v", p)
+		}
+		def := ParamAny(p, "")
+		if err := def.validate(); err != nil {
+			return nil, err
+		}
+		params = params.Add(def)
+	}
+	return &UserFunction{name: name, params: params}, nil
+}
+
+// SetBody sets the function that is executed when the user function is
+// called, along with the maps that are accessible from within it.
+func (u *UserFunction) SetBody(body Function, maps map[string]Function) {
+	u.body = body
+	u.maps = maps
+}
+
+// Name returns the name of the user function.
+func (u *UserFunction) Name() string {
+	return u.name
+}
+
+// Params returns the parameters expected by the user function.
+func (u *UserFunction) Params() Params {
+	return u.params
+}
+
+// Call returns a function that executes the user function with a set of
+// parsed arguments.
+func (u *UserFunction) Call(args *ParsedParams) Function {
+	return ClosureFunction("function "+u.name, func(ctx FunctionContext) (any, error) {
+		if u.body == nil {
+			return nil, errors.New("function body was not defined")
+		}
+
+		resolved, err := args.ResolveDynamic(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		argsObj := make(map[string]any, len(u.params.Definitions))
+		for i, v := range resolved.Raw() {
+			name := u.params.Definitions[i].Name
+			argsObj[name] = v
+			ctx = ctx.WithNamedValue(name, v)
+		}
+		ctx = ctx.WithValue(argsObj)
+
+		// The body is given its own variables so that it cannot read or modify
+		// the variables of the caller, which keeps the result of a call
+		// dependent only on its arguments.
+		ctx.Vars = map[string]any{}
+		ctx.Maps = u.maps
+		return u.body.Exec(ctx)
+	}, aggregateTargetPaths(args.dynamic()...))
+}
@@ -14,6 +14,7 @@ type Environment struct {
 	outputs    *OutputSet
 	processors *ProcessorSet
 	rateLimits *RateLimitSet
+	locks      *LockSet
 	metrics    *MetricsSet
 	tracers    *TracerSet
 
@@ -31,6 +32,7 @@ func NewEnvironment() *Environment {
 		outputs:      &OutputSet{},
 		processors:   &ProcessorSet{},
 		rateLimits:   &RateLimitSet{},
+		locks:        &LockSet{},
 		metrics:      &MetricsSet{},
 		tracers:      &TracerSet{},
 		scanners:     &ScannerSet{},
@@ -63,6 +65,9 @@ func (e *Environment) Clone() *Environment {
 	for _, v := range e.rateLimits.specs {
 		_ = newEnv.rateLimits.Add(v.constructor, v.spec)
 	}
+	for _, v := range e.locks.specs {
+		_ = newEnv.locks.Add(v.constructor, v.spec)
+	}
 	for _, v := range e.metrics.specs {
 		_ = newEnv.metrics.Add(v.constructor, v.spec)
 	}
@@ -93,6 +98,8 @@ func (e *Environment) GetDocs(name string, ctype docs.Type) (docs.ComponentSpec,
 		spec, ok = e.processors.DocsFor(name)
 	case docs.TypeRateLimit:
 		spec, ok = e.rateLimits.DocsFor(name)
+	case docs.TypeLock:
+		spec, ok = e.locks.DocsFor(name)
 	case docs.TypeMetrics:
 		spec, ok = e.metrics.DocsFor(name)
 	case docs.TypeTracer:
@@ -112,6 +119,7 @@ var GlobalEnvironment = &Environment{
 	outputs:      AllOutputs,
 	processors:   AllProcessors,
 	rateLimits:   AllRateLimits,
+	locks:        AllLocks,
 	metrics:      AllMetrics,
 	tracers:      AllTracers,
 	scanners:     AllScanners,
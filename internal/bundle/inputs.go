@@ -69,6 +69,17 @@ func (s *InputSet) Init(conf input.Config, mgr NewManagement) (input.Streamed, e
 	if !exists {
 		return nil, component.ErrInvalidType("input", conf.Type)
 	}
+	if conf.LeaderOnly != nil {
+		lConf := *conf.LeaderOnly
+		conf.LeaderOnly = nil
+		c, err := input.NewLeaderOnly(lConf, conf.Label, func() (input.Streamed, error) {
+			return s.Init(conf, mgr)
+		}, mgr)
+		if err != nil {
+			return nil, wrapComponentErr(mgr, "input", err)
+		}
+		return c, nil
+	}
 	c, err := spec.constructor(conf, mgr)
 	err = wrapComponentErr(mgr, "input", err)
 	return c, err
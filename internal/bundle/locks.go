@@ -0,0 +1,98 @@
+package bundle
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/lock"
+	"github.com/warpstreamlabs/bento/internal/docs"
+)
+
+// AllLocks is a set containing every single lock that has been imported.
+var AllLocks = &LockSet{
+	specs: map[string]lockSpec{},
+}
+
+//------------------------------------------------------------------------------
+
+// LockAdd adds a new lock to this environment by providing a
+// constructor and documentation.
+func (e *Environment) LockAdd(constructor LockConstructor, spec docs.ComponentSpec) error {
+	return e.locks.Add(constructor, spec)
+}
+
+// LockInit attempts to initialise a lock from a config.
+func (e *Environment) LockInit(conf lock.Config, mgr NewManagement) (lock.V1, error) {
+	return e.locks.Init(conf, mgr)
+}
+
+// LockDocs returns a slice of lock specs, which document each method.
+func (e *Environment) LockDocs() []docs.ComponentSpec {
+	return e.locks.Docs()
+}
+
+//------------------------------------------------------------------------------
+
+// LockConstructor constructs a lock component.
+type LockConstructor func(lock.Config, NewManagement) (lock.V1, error)
+
+type lockSpec struct {
+	constructor LockConstructor
+	spec        docs.ComponentSpec
+}
+
+// LockSet contains an explicit set of locks available to a Bento service.
+type LockSet struct {
+	specs map[string]lockSpec
+}
+
+// Add a new lock to this set by providing a spec (name, documentation, and
+// constructor).
+func (s *LockSet) Add(constructor LockConstructor, spec docs.ComponentSpec) error {
+	if !nameRegexp.MatchString(spec.Name) {
+		return fmt.Errorf("component name '%v' does not match the required regular expression /%v/", spec.Name, nameRegexpRaw)
+	}
+	if s.specs == nil {
+		s.specs = map[string]lockSpec{}
+	}
+	spec.Type = docs.TypeLock
+	s.specs[spec.Name] = lockSpec{
+		constructor: constructor,
+		spec:        spec,
+	}
+	return nil
+}
+
+// Init attempts to initialise a lock from a config.
+func (s *LockSet) Init(conf lock.Config, mgr NewManagement) (lock.V1, error) {
+	spec, exists := s.specs[conf.Type]
+	if !exists {
+		return nil, component.ErrInvalidType("lock", conf.Type)
+	}
+	c, err := spec.constructor(conf, mgr)
+	err = wrapComponentErr(mgr, "lock", err)
+	return c, err
+}
+
+// Docs returns a slice of lock specs, which document each method.
+func (s *LockSet) Docs() []docs.ComponentSpec {
+	var docs []docs.ComponentSpec
+	for _, v := range s.specs {
+		docs = append(docs, v.spec)
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].Name < docs[j].Name
+	})
+	return docs
+}
+
+// DocsFor returns the documentation for a given component name, returns a
+// boolean indicating whether the component name exists.
+func (s *LockSet) DocsFor(name string) (docs.ComponentSpec, bool) {
+	c, ok := s.specs[name]
+	if !ok {
+		return docs.ComponentSpec{}, false
+	}
+	return c.spec, true
+}
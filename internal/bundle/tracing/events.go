@@ -192,9 +192,16 @@ type events struct {
 	mLen int64
 
 	ctrl *control
+	tap  *tapPoint
 }
 
+// IsEnabled returns whether events should be added, either because they're
+// being recorded or because the component is being tapped.
 func (e *events) IsEnabled() bool {
+	return e.isRecording() || e.tap.isActive()
+}
+
+func (e *events) isRecording() bool {
 	if !e.ctrl.IsEnabled() {
 		return false
 	}
@@ -205,6 +212,11 @@ func (e *events) IsEnabled() bool {
 }
 
 func (e *events) Add(event NodeEvent) {
+	e.tap.publish(event)
+	if !e.isRecording() {
+		return
+	}
+
 	e.mut.Lock()
 	defer e.mut.Unlock()
 
@@ -0,0 +1,331 @@
+package tracing
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang/mapping"
+	"github.com/warpstreamlabs/bento/internal/bloblang/query"
+	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/component/output"
+	"github.com/warpstreamlabs/bento/internal/component/output/processors"
+	"github.com/warpstreamlabs/bento/internal/component/processor"
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+// TapEvent is an event observed by a tap on a running stream.
+type TapEvent struct {
+	Stream    string         `json:"stream,omitempty"`
+	Component string         `json:"component"`
+	Kind      string         `json:"kind"`
+	Type      EventType      `json:"type"`
+	Content   string         `json:"content,omitempty"`
+	Meta      map[string]any `json:"metadata,omitempty"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+// TapOptions determines which events are delivered to a tap subscription.
+type TapOptions struct {
+	// The stream to observe, which is empty when running a single stream.
+	Stream string
+
+	// An optional list of components to observe, identified by either their
+	// label or their path, where all components are observed when empty.
+	Components []string
+
+	// An optional Bloblang query executed against the message of each event,
+	// where events are only delivered when it results in true.
+	Filter *mapping.Executor
+
+	// The maximum number of events delivered per second, where events beyond
+	// this rate are dropped. Zero means unlimited.
+	Rate float64
+
+	// The number of events that are buffered for a subscriber before events
+	// are dropped.
+	BufferSize int
+}
+
+// Tap distributes the events of components to subscribers as they occur.
+// Components only produce events whilst there is at least one subscriber, and
+// events are dropped rather than applying back pressure to the stream when
+// subscribers fall behind.
+type Tap struct {
+	mut   sync.RWMutex
+	subs  map[*TapSubscription]struct{}
+	nSubs atomic.Int64
+}
+
+// NewTap creates a new tap without any subscribers.
+func NewTap() *Tap {
+	return &Tap{
+		subs: map[*TapSubscription]struct{}{},
+	}
+}
+
+// Subscribe to the events of the tap, the subscription must be closed once it
+// is no longer needed.
+func (t *Tap) Subscribe(opts TapOptions) *TapSubscription {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 100
+	}
+	s := &TapSubscription{
+		tap:     t,
+		opts:    opts,
+		eventsC: make(chan TapEvent, opts.BufferSize),
+	}
+	if len(opts.Components) > 0 {
+		s.components = make(map[string]struct{}, len(opts.Components))
+		for _, c := range opts.Components {
+			s.components[c] = struct{}{}
+		}
+	}
+
+	t.mut.Lock()
+	t.subs[s] = struct{}{}
+	t.nSubs.Store(int64(len(t.subs)))
+	t.mut.Unlock()
+	return s
+}
+
+func (t *Tap) unsubscribe(s *TapSubscription) {
+	t.mut.Lock()
+	delete(t.subs, s)
+	t.nSubs.Store(int64(len(t.subs)))
+	t.mut.Unlock()
+}
+
+func (t *Tap) isActive() bool {
+	return t != nil && t.nSubs.Load() > 0
+}
+
+func (t *Tap) publish(e TapEvent) {
+	t.mut.RLock()
+	defer t.mut.RUnlock()
+	for s := range t.subs {
+		s.offer(e)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// TapSubscription receives the events of a tap that match its options.
+type TapSubscription struct {
+	tap        *Tap
+	opts       TapOptions
+	components map[string]struct{}
+	eventsC    chan TapEvent
+
+	mut        sync.Mutex
+	tokens     float64
+	lastRefill time.Time
+	dropped    uint64
+}
+
+// Events returns a channel of events that match the options of the
+// subscription.
+func (s *TapSubscription) Events() <-chan TapEvent {
+	return s.eventsC
+}
+
+// Dropped returns the number of events that were dropped due to either the rate
+// limit or a full buffer. Events are checked against the rate limit before the
+// filter, and therefore events dropped by the rate limit are counted even when
+// they would not have matched the filter.
+func (s *TapSubscription) Dropped() uint64 {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.dropped
+}
+
+// Close the subscription, after which no more events are delivered.
+func (s *TapSubscription) Close() {
+	s.tap.unsubscribe(s)
+}
+
+func (s *TapSubscription) matchesSource(e TapEvent) bool {
+	if e.Stream != s.opts.Stream {
+		return false
+	}
+	if s.components != nil {
+		if _, exists := s.components[e.Component]; !exists {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *TapSubscription) matchesFilter(e TapEvent) bool {
+	if s.opts.Filter == nil {
+		return true
+	}
+
+	part := message.NewPart([]byte(e.Content))
+	for k, v := range e.Meta {
+		part.MetaSetMut(k, v)
+	}
+	res, err := s.opts.Filter.QueryPart(0, message.Batch{part})
+	return err == nil && res
+}
+
+// allow consumes a token from the rate limit of the subscription, which is a
+// token bucket holding up to one second worth of events, and at least one event
+// so that rates below one per second are able to deliver. Must be called whilst
+// holding the mutex.
+func (s *TapSubscription) allow(now time.Time) bool {
+	if s.opts.Rate <= 0 {
+		return true
+	}
+	capacity := max(s.opts.Rate, 1)
+	if s.lastRefill.IsZero() {
+		s.tokens = capacity
+	} else {
+		s.tokens = min(capacity, s.tokens+now.Sub(s.lastRefill).Seconds()*s.opts.Rate)
+	}
+	s.lastRefill = now
+	if s.tokens < 1 {
+		return false
+	}
+	s.tokens--
+	return true
+}
+
+// offer delivers an event to the subscription when it matches. The stream and
+// components are checked first, followed by the rate limit, so that the filter
+// is only executed for events that would otherwise be delivered. A token
+// consumed by an event that the filter rejects is returned.
+func (s *TapSubscription) offer(e TapEvent) {
+	if !s.matchesSource(e) {
+		return
+	}
+
+	s.mut.Lock()
+	allowed := s.allow(e.Timestamp)
+	if !allowed {
+		s.dropped++
+	}
+	s.mut.Unlock()
+	if !allowed {
+		return
+	}
+
+	if !s.matchesFilter(e) {
+		if s.opts.Rate > 0 {
+			s.mut.Lock()
+			s.tokens = min(max(s.opts.Rate, 1), s.tokens+1)
+			s.mut.Unlock()
+		}
+		return
+	}
+
+	select {
+	case s.eventsC <- e:
+	default:
+		s.mut.Lock()
+		s.dropped++
+		s.mut.Unlock()
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// tapPoint publishes the events of a single component to a tap.
+type tapPoint struct {
+	tap       *Tap
+	stream    string
+	component string
+	kind      string
+}
+
+func (p *tapPoint) isActive() bool {
+	return p != nil && p.tap.isActive()
+}
+
+func (p *tapPoint) publish(e NodeEvent) {
+	if !p.isActive() {
+		return
+	}
+	p.tap.publish(TapEvent{
+		Stream:    p.stream,
+		Component: p.component,
+		Kind:      p.kind,
+		Type:      e.Type,
+		Content:   e.Content,
+		Meta:      e.Meta,
+		Timestamp: time.Now(),
+	})
+}
+
+//------------------------------------------------------------------------------
+
+// TappedBundle modifies a provided bundle environment so that traceable
+// components are wrapped by components that publish events to the provided
+// tap whilst it has subscribers.
+//
+// Components created by a manager for a stream are identified by the stream
+// they belong to, which is obtained from the manager when it implements a
+// method StreamID.
+func TappedBundle(b *bundle.Environment, tap *Tap) *bundle.Environment {
+	tappedEnv := b.Clone()
+
+	// Tapped components do not record events, and so these counters are unused.
+	var inputCtr, outputCtr, procErrCtr uint64
+
+	newEvents := func(kind string, nm bundle.NewManagement) *events {
+		key := nm.Label()
+		if key == "" {
+			key = "root." + query.SliceToDotPath(nm.Path()...)
+		}
+		var stream string
+		if s, ok := nm.(interface{ StreamID() string }); ok {
+			stream = s.StreamID()
+		}
+		return &events{
+			ctrl: &control{},
+			tap: &tapPoint{
+				tap:       tap,
+				stream:    stream,
+				component: key,
+				kind:      kind,
+			},
+		}
+	}
+
+	for _, spec := range b.InputDocs() {
+		_ = tappedEnv.InputAdd(func(conf input.Config, nm bundle.NewManagement) (input.Streamed, error) {
+			i, err := b.InputInit(conf, nm)
+			if err != nil {
+				return nil, err
+			}
+			return traceInput(newEvents("input", nm), &inputCtr, i), nil
+		}, spec)
+	}
+
+	for _, spec := range b.ProcessorDocs() {
+		_ = tappedEnv.ProcessorAdd(func(conf processor.Config, nm bundle.NewManagement) (processor.V1, error) {
+			p, err := b.ProcessorInit(conf, nm)
+			if err != nil {
+				return nil, err
+			}
+			return traceProcessor(newEvents("processor", nm), &procErrCtr, p), nil
+		}, spec)
+	}
+
+	for _, spec := range b.OutputDocs() {
+		_ = tappedEnv.OutputAdd(func(conf output.Config, nm bundle.NewManagement, pcf ...processor.PipelineConstructorFunc) (output.Streamed, error) {
+			pcf = processors.AppendFromConfig(conf, nm, pcf...)
+			conf.Processors = nil
+
+			o, err := b.OutputInit(conf, nm)
+			if err != nil {
+				return nil, err
+			}
+			o = traceOutput(newEvents("output", nm), &outputCtr, o)
+			return output.WrapWithPipelines(o, pcf...)
+		}, spec)
+	}
+
+	return tappedEnv
+}
@@ -0,0 +1,143 @@
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang"
+	"github.com/warpstreamlabs/bento/internal/log"
+)
+
+// tapMaxRate is the maximum number of events per second that a client of a tap
+// endpoint is able to request.
+const tapMaxRate = 1000
+
+// TapEndpointDescription describes the query parameters supported by
+// ServeTap, for use when registering tap endpoints.
+const TapEndpointDescription = "Streams events of messages passing through the components of %v as newline delimited JSON, or as JSON messages over a websocket." +
+	" Supports the query parameters `component` (a label or path of a component to observe, which can be repeated)," +
+	" `filter` (a Bloblang query that messages must satisfy, which cannot use impure functions), `rate` (the maximum events per second, defaults to 10 and cannot exceed 1000)" +
+	" and `limit` (the maximum number of events after which the response ends)."
+
+// ServeTap streams the events of a tap that belong to a given stream to an HTTP
+// client until either the client disconnects or the limit of the request is
+// reached. Requests that ask for a websocket upgrade receive each event as a
+// JSON message, otherwise events are written as newline delimited JSON.
+func ServeTap(w http.ResponseWriter, r *http.Request, tap *Tap, stream string, blobl *bloblang.Environment, logger log.Modular) {
+	opts := TapOptions{
+		Stream:     stream,
+		Components: r.URL.Query()["component"],
+		Rate:       10,
+	}
+
+	// Filters are executed within the stream and are provided by any client of
+	// the endpoint, and are therefore restricted to pure functions and methods.
+	if filterStr := r.URL.Query().Get("filter"); filterStr != "" {
+		var err error
+		if opts.Filter, err = blobl.OnlyPure().NewMapping(filterStr); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to parse filter: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// The rate limit protects the stream from the cost of delivering events,
+	// and therefore clients are unable to disable it or exceed a maximum.
+	if rateStr := r.URL.Query().Get("rate"); rateStr != "" {
+		var err error
+		if opts.Rate, err = strconv.ParseFloat(rateStr, 64); err != nil || opts.Rate <= 0 || math.IsNaN(opts.Rate) || math.IsInf(opts.Rate, 0) {
+			http.Error(w, fmt.Sprintf("Invalid rate: %v", rateStr), http.StatusBadRequest)
+			return
+		}
+		opts.Rate = min(opts.Rate, tapMaxRate)
+	}
+
+	var limit int
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitStr); err != nil || limit < 0 {
+			http.Error(w, fmt.Sprintf("Invalid limit: %v", limitStr), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if websocket.IsWebSocketUpgrade(r) {
+		serveTapWebsocket(w, r, tap, opts, limit, logger)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported by the connection", http.StatusInternalServerError)
+		return
+	}
+
+	sub := tap.Subscribe(opts)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	enc := json.NewEncoder(w)
+	for sent := 0; limit == 0 || sent < limit; sent++ {
+		select {
+		case e := <-sub.Events():
+			if err := enc.Encode(e); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func serveTapWebsocket(w http.ResponseWriter, r *http.Request, tap *Tap, opts TapOptions, limit int, logger log.Modular) {
+	upgrader := websocket.Upgrader{}
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Warn("Tap websocket upgrade failed: %v", err)
+		return
+	}
+	defer ws.Close()
+
+	sub := tap.Subscribe(opts)
+	defer sub.Close()
+
+	// Messages from the client are discarded, but must be read in order to
+	// process control frames and detect when the client has gone away.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for sent := 0; limit == 0 || sent < limit; sent++ {
+		select {
+		case e := <-sub.Events():
+			if err := ws.WriteJSON(e); err != nil {
+				return
+			}
+		case <-done:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	_ = ws.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, "limit reached"),
+		time.Now().Add(time.Second),
+	)
+}
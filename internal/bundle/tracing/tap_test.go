@@ -0,0 +1,214 @@
+package tracing_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang"
+	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/bundle/tracing"
+	"github.com/warpstreamlabs/bento/internal/component/processor"
+	"github.com/warpstreamlabs/bento/internal/component/testutil"
+	"github.com/warpstreamlabs/bento/internal/log"
+	"github.com/warpstreamlabs/bento/internal/manager"
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+func tapTestProcessor(t testing.TB, tap *tracing.Tap, stream string) processor.V1 {
+	t.Helper()
+
+	mgr, err := manager.New(
+		manager.ResourceConfig{},
+		manager.OptSetEnvironment(tracing.TappedBundle(bundle.GlobalEnvironment, tap)),
+	)
+	require.NoError(t, err)
+
+	procConfig, err := testutil.ProcessorFromYAML(`
+label: foo
+mapping: 'root = if this.n == 0 { deleted() } else { this.n * 10 }'
+`)
+	require.NoError(t, err)
+
+	nm := mgr.IntoPath("pipeline", "processors", "0")
+	if stream != "" {
+		nm = mgr.ForStream(stream).IntoPath("pipeline", "processors", "0")
+	}
+	proc, err := nm.NewProcessor(procConfig)
+	require.NoError(t, err)
+	return proc
+}
+
+func tapProcess(t testing.TB, proc processor.V1, contents ...string) {
+	t.Helper()
+	for _, c := range contents {
+		_, err := proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{[]byte(c)}))
+		require.NoError(t, err)
+	}
+}
+
+func tapReceive(t testing.TB, sub *tracing.TapSubscription, n int) []tracing.TapEvent {
+	t.Helper()
+	var events []tracing.TapEvent
+	for len(events) < n {
+		select {
+		case e := <-sub.Events():
+			events = append(events, e)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out after %v events", len(events))
+		}
+	}
+	select {
+	case e := <-sub.Events():
+		t.Fatalf("unexpected event: %+v", e)
+	default:
+	}
+	return events
+}
+
+func TestTapProcessor(t *testing.T) {
+	tap := tracing.NewTap()
+	proc := tapTestProcessor(t, tap, "")
+
+	// Events before a subscription are not delivered.
+	tapProcess(t, proc, `{"n":1}`)
+
+	sub := tap.Subscribe(tracing.TapOptions{})
+	defer sub.Close()
+
+	tapProcess(t, proc, `{"n":2}`, `{"n":0}`)
+
+	events := tapReceive(t, sub, 4)
+	for _, e := range events {
+		assert.Equal(t, "foo", e.Component)
+		assert.Equal(t, "processor", e.Kind)
+		assert.Empty(t, e.Stream)
+	}
+	assert.Equal(t, tracing.EventConsume, events[0].Type)
+	assert.Equal(t, `{"n":2}`, events[0].Content)
+	assert.Equal(t, tracing.EventProduce, events[1].Type)
+	assert.Equal(t, `20`, events[1].Content)
+	assert.Equal(t, tracing.EventConsume, events[2].Type)
+	assert.Equal(t, `{"n":0}`, events[2].Content)
+	assert.Equal(t, tracing.EventDelete, events[3].Type)
+
+	sub.Close()
+	tapProcess(t, proc, `{"n":3}`)
+	select {
+	case e := <-sub.Events():
+		t.Fatalf("unexpected event: %+v", e)
+	default:
+	}
+}
+
+func TestTapFilters(t *testing.T) {
+	tap := tracing.NewTap()
+	procA := tapTestProcessor(t, tap, "a")
+	procB := tapTestProcessor(t, tap, "b")
+
+	filter, err := bloblang.GlobalEnvironment().NewMapping(`root = this.n.or(0) > 5`)
+	require.NoError(t, err)
+
+	subA := tap.Subscribe(tracing.TapOptions{Stream: "a", Filter: filter})
+	defer subA.Close()
+
+	subB := tap.Subscribe(tracing.TapOptions{Stream: "b", Components: []string{"bar"}})
+	defer subB.Close()
+
+	subLimited := tap.Subscribe(tracing.TapOptions{Stream: "a", Rate: 2})
+	defer subLimited.Close()
+
+	tapProcess(t, procA, `{"n":1}`, `{"n":6}`, `{"n":7}`)
+	tapProcess(t, procB, `{"n":8}`)
+
+	events := tapReceive(t, subA, 2)
+	assert.Equal(t, "a", events[0].Stream)
+	assert.Equal(t, `{"n":6}`, events[0].Content)
+	assert.Equal(t, `{"n":7}`, events[1].Content)
+
+	tapReceive(t, subB, 0)
+
+	tapReceive(t, subLimited, 2)
+	assert.Equal(t, uint64(4), subLimited.Dropped())
+}
+
+func TestTapRateLimit(t *testing.T) {
+	tap := tracing.NewTap()
+	proc := tapTestProcessor(t, tap, "")
+
+	// Rates below one per second still deliver an event.
+	subSlow := tap.Subscribe(tracing.TapOptions{Rate: 0.5})
+	defer subSlow.Close()
+
+	// Events rejected by the filter do not consume the rate limit.
+	filter, err := bloblang.GlobalEnvironment().NewMapping(`root = this.n.or(0) == 3`)
+	require.NoError(t, err)
+	subFiltered := tap.Subscribe(tracing.TapOptions{Rate: 1, Filter: filter})
+	defer subFiltered.Close()
+
+	tapProcess(t, proc, `{"n":1}`, `{"n":2}`, `{"n":3}`)
+
+	events := tapReceive(t, subSlow, 1)
+	assert.Equal(t, `{"n":1}`, events[0].Content)
+	assert.Equal(t, uint64(5), subSlow.Dropped())
+
+	// Only the event produced after the delivered event exceeds the rate.
+	events = tapReceive(t, subFiltered, 1)
+	assert.Equal(t, `{"n":3}`, events[0].Content)
+	assert.Equal(t, uint64(1), subFiltered.Dropped())
+}
+
+func TestTapHTTP(t *testing.T) {
+	tap := tracing.NewTap()
+	proc := tapTestProcessor(t, tap, "")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tracing.ServeTap(w, r, tap, "", bloblang.GlobalEnvironment(), log.Noop())
+	}))
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "?filter=bad%20mapping%20%3D%3D")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res, err = http.Get(server.URL + "?filter=" + url.QueryEscape(`root = count("foo") > 0`))
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	for _, rate := range []string{"0", "-1", "NaN", "Inf", "nope"} {
+		res, err = http.Get(server.URL + "?rate=" + rate)
+		require.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, rate)
+	}
+
+	res, err = http.Get(server.URL + "?limit=2&rate=1000000")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	// The subscription exists before the response headers are written.
+	tapProcess(t, proc, `{"n":1}`)
+
+	var events []tracing.TapEvent
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		var e tracing.TapEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		events = append(events, e)
+	}
+	require.Len(t, events, 2)
+	assert.Equal(t, tracing.EventConsume, events[0].Type)
+	assert.Equal(t, tracing.EventProduce, events[1].Type)
+	assert.Equal(t, "10", events[1].Content)
+}
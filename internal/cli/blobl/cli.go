@@ -106,6 +106,8 @@ Example: bento blobl playground -m mapping.blobl -i input.json`,
 					},
 				},
 			},
+			lspCliCommand(opts),
+			fmtCliCommand(opts),
 		},
 	}
 }
@@ -0,0 +1,467 @@
+package blobl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang"
+	"github.com/warpstreamlabs/bento/internal/bloblang/parser"
+	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/cli/common"
+	"github.com/warpstreamlabs/bento/internal/docs"
+	ifilepath "github.com/warpstreamlabs/bento/internal/filepath"
+	"github.com/warpstreamlabs/bento/internal/filepath/ifs"
+)
+
+func fmtCliCommand(opts *common.CLIOpts) *cli.Command {
+	return &cli.Command{
+		Name:  "fmt",
+		Usage: "Format Bloblang mappings in a canonical style.",
+		Description: opts.ExecTemplate(`
+Formats Bloblang mapping files (.blobl) as well as mappings embedded within
+config files (.yaml, .yml). When no paths are provided a mapping is read from
+stdin and the formatted result is written to stdout:
+
+  {{.BinaryName}} blobl fmt ./mapping.blobl
+  {{.BinaryName}} blobl fmt -w ./mappings/... ./config.yaml
+  {{.BinaryName}} blobl fmt --check ./configs/...
+
+If a path ends with '...' then {{.ProductName}} will walk the target and format
+any files with the .blobl, .yaml or .yml extension.
+
+When the --check flag is set files are not modified, instead the path of each
+file that is not formatted is printed and the command exits with a status code
+1 if any were found.`)[1:],
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "check",
+				Usage: "list files that are not formatted and exit with status code 1 if any are found.",
+			},
+			&cli.BoolFlag{
+				Name:    "write",
+				Aliases: []string{"w"},
+				Usage:   "write the formatted result back to each source file rather than stdout.",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if code := FmtAction(c, opts, os.Stdin, os.Stdout, os.Stderr); code != 0 {
+				os.Exit(code)
+			}
+			return nil
+		},
+	}
+}
+
+// FmtAction performs the bento blobl fmt subcommand and returns the
+// appropriate exit code. This function is exported for testing purposes only.
+func FmtAction(c *cli.Context, opts *common.CLIOpts, stdin io.Reader, stdout, stderr io.Writer) int {
+	check, write := c.Bool("check"), c.Bool("write")
+
+	if c.Args().Len() == 0 {
+		inBytes, err := io.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "%v\n", red(fmt.Sprintf("failed to read stdin: %v", err)))
+			return 1
+		}
+		mapping := string(inBytes)
+		formatted, err := bloblang.GlobalEnvironment().FormatMapping(mapping)
+		if err != nil {
+			fmt.Fprintf(stderr, "%v\n", red(fmtErrorString("<stdin>", mapping, err)))
+			return 1
+		}
+		if check {
+			if formatted != mapping {
+				fmt.Fprintln(stdout, "<stdin>")
+				return 1
+			}
+			return 0
+		}
+		fmt.Fprint(stdout, formatted)
+		return 0
+	}
+
+	targets, err := ifilepath.GlobsAndSuperPaths(ifs.OS(), c.Args().Slice(), "blobl", "yaml", "yml")
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", red(fmt.Sprintf("failed to resolve paths: %v", err)))
+		return 1
+	}
+
+	spec := opts.MainConfigSpecCtor()
+
+	exitCode := 0
+	for _, target := range targets {
+		source, err := ifs.ReadFile(ifs.OS(), target)
+		if err != nil {
+			fmt.Fprintf(stderr, "%v\n", red(fmt.Sprintf("failed to read %v: %v", target, err)))
+			exitCode = 1
+			continue
+		}
+
+		var formatted []byte
+		var errs []error
+		switch filepath.Ext(target) {
+		case ".yaml", ".yml":
+			formatted, errs = formatConfigMappings(target, source, spec, bundle.GlobalEnvironment)
+		default:
+			var fStr string
+			if fStr, err = bloblang.GlobalEnvironment().WithImporterRelativeToFile(target).FormatMapping(string(source)); err != nil {
+				errs = append(errs, errors.New(fmtErrorString(target, string(source), err)))
+			}
+			formatted = []byte(fStr)
+		}
+		if len(errs) > 0 {
+			for _, err := range errs {
+				fmt.Fprintf(stderr, "%v\n", red(err.Error()))
+			}
+			exitCode = 1
+			continue
+		}
+
+		switch {
+		case check:
+			if !bytes.Equal(formatted, source) {
+				fmt.Fprintln(stdout, target)
+				exitCode = 1
+			}
+		case write:
+			if bytes.Equal(formatted, source) {
+				continue
+			}
+			if err := os.WriteFile(target, formatted, 0o644); err != nil {
+				fmt.Fprintf(stderr, "%v\n", red(fmt.Sprintf("failed to write %v: %v", target, err)))
+				exitCode = 1
+			}
+		default:
+			_, _ = stdout.Write(formatted)
+		}
+	}
+	return exitCode
+}
+
+func fmtErrorString(source, mapping string, err error) string {
+	var pErr *parser.Error
+	if errors.As(err, &pErr) {
+		return fmt.Sprintf("%v: %v", source, pErr.ErrorAtPosition([]rune(mapping)))
+	}
+	return fmt.Sprintf("%v: %v", source, err)
+}
+
+//------------------------------------------------------------------------------
+
+// collectMappingNodes walks a config and returns all scalar nodes containing a
+// Bloblang mapping.
+func collectMappingNodes(spec docs.FieldSpecs, prov docs.Provider, root *yaml.Node) ([]*yaml.Node, error) {
+	var nodes []*yaml.Node
+	collectFieldMappingNodes(docs.FieldObject("", "").WithChildren(spec...), root, &nodes)
+
+	if err := spec.WalkYAML(root, prov, func(c docs.WalkedYAMLComponent) error {
+		cSpec, exists := prov.GetDocs(c.Name, c.ComponentType)
+		if !exists {
+			return nil
+		}
+		for i := 0; i < len(c.Conf.Content)-1; i += 2 {
+			if c.Conf.Content[i].Value == c.Name {
+				collectFieldMappingNodes(cSpec.Config, c.Conf.Content[i+1], &nodes)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+func collectFieldMappingNodes(f docs.FieldSpec, node *yaml.Node, nodes *[]*yaml.Node) {
+	if node == nil || node.Kind == yaml.AliasNode {
+		return
+	}
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	// Components are visited separately.
+	if _, isCore := f.Type.IsCoreComponent(); isCore {
+		return
+	}
+
+	switch f.Kind {
+	case docs.Kind2DArray, docs.KindArray:
+		if node.Kind == yaml.SequenceNode {
+			for _, n := range node.Content {
+				if f.Kind == docs.Kind2DArray {
+					collectFieldMappingNodes(f.Array(), n, nodes)
+				} else {
+					collectFieldMappingNodes(f.Scalar(), n, nodes)
+				}
+			}
+		}
+		return
+	case docs.KindMap:
+		if node.Kind == yaml.MappingNode {
+			for i := 1; i < len(node.Content); i += 2 {
+				collectFieldMappingNodes(f.Scalar(), node.Content[i], nodes)
+			}
+		}
+		return
+	}
+
+	if f.Bloblang && node.Kind == yaml.ScalarNode {
+		*nodes = append(*nodes, node)
+		return
+	}
+	if len(f.Children) > 0 && node.Kind == yaml.MappingNode {
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			for _, child := range f.Children {
+				if child.Name == node.Content[i].Value {
+					collectFieldMappingNodes(child, node.Content[i+1], nodes)
+				}
+			}
+		}
+	}
+}
+
+// formatConfigMappings formats each Bloblang mapping within a YAML config and
+// returns the modified config. Only the source of the mappings themselves are
+// modified, the remainder of the config is left untouched.
+func formatConfigMappings(path string, source []byte, spec docs.FieldSpecs, prov docs.Provider) ([]byte, []error) {
+	root, err := docs.UnmarshalYAML(source)
+	if err != nil {
+		return nil, []error{fmt.Errorf("%v: %w", path, err)}
+	}
+
+	nodes, err := collectMappingNodes(spec, prov, root)
+	if err != nil {
+		return nil, []error{fmt.Errorf("%v: %w", path, err)}
+	}
+
+	// Apply edits from the bottom of the file upwards so that the positions of
+	// earlier nodes remain valid.
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Line == nodes[j].Line {
+			return nodes[i].Column > nodes[j].Column
+		}
+		return nodes[i].Line > nodes[j].Line
+	})
+
+	env := bloblang.GlobalEnvironment().Deactivated().WithImporterRelativeToFile(path)
+	lines := strings.Split(string(source), "\n")
+
+	var errs []error
+	expected := map[string]string{}
+	for _, n := range nodes {
+		formatted, err := env.FormatMapping(n.Value)
+		if err != nil {
+			errs = append(errs, errors.New(fmtErrorString(fmt.Sprintf("%v:%v", path, n.Line), n.Value, err)))
+			continue
+		}
+		if strings.TrimRight(formatted, "\n") == strings.TrimRight(n.Value, "\n") {
+			continue
+		}
+		var replaced bool
+		if lines, replaced = replaceYAMLScalar(lines, n, formatted); replaced {
+			expected[strings.TrimRight(n.Value, "\n")] = strings.TrimRight(formatted, "\n")
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	result := []byte(strings.Join(lines, "\n"))
+	if len(expected) == 0 {
+		return result, nil
+	}
+
+	// As a precaution ensure that the resulting config contains the same
+	// mappings, now formatted.
+	if err := verifyFormattedConfig(result, spec, prov, nodes, expected); err != nil {
+		return nil, []error{fmt.Errorf("%v: %w", path, err)}
+	}
+	return result, nil
+}
+
+func verifyFormattedConfig(result []byte, spec docs.FieldSpecs, prov docs.Provider, before []*yaml.Node, expected map[string]string) error {
+	root, err := docs.UnmarshalYAML(result)
+	if err != nil {
+		return fmt.Errorf("formatting resulted in invalid YAML: %w", err)
+	}
+	after, err := collectMappingNodes(spec, prov, root)
+	if err != nil {
+		return err
+	}
+
+	var beforeValues, afterValues []string
+	for _, n := range before {
+		v := strings.TrimRight(n.Value, "\n")
+		if f, exists := expected[v]; exists {
+			v = f
+		}
+		beforeValues = append(beforeValues, v)
+	}
+	for _, n := range after {
+		afterValues = append(afterValues, strings.TrimRight(n.Value, "\n"))
+	}
+	sort.Strings(beforeValues)
+	sort.Strings(afterValues)
+	if strings.Join(beforeValues, "\x00") != strings.Join(afterValues, "\x00") {
+		return errors.New("failed to rewrite mappings within config")
+	}
+	return nil
+}
+
+// replaceYAMLScalar attempts to replace the source of a scalar node with a new
+// value whilst preserving its style, and returns false if the style of the node
+// is not supported.
+func replaceYAMLScalar(lines []string, n *yaml.Node, value string) ([]string, bool) {
+	lineIdx := n.Line - 1
+	if lineIdx < 0 || lineIdx >= len(lines) {
+		return lines, false
+	}
+
+	switch n.Style {
+	case yaml.LiteralStyle:
+		return replaceYAMLLiteral(lines, lineIdx, n.Column-1, value)
+	case yaml.FoldedStyle:
+		return lines, false
+	}
+
+	value = strings.TrimRight(value, "\n")
+	if strings.Contains(n.Value, "\n") || strings.Contains(value, "\n") {
+		return lines, false
+	}
+
+	line := []rune(lines[lineIdx])
+	start := n.Column - 1
+	if start < 0 || start >= len(line) {
+		return lines, false
+	}
+
+	var end int
+	switch n.Style {
+	case yaml.SingleQuotedStyle:
+		if end = yamlSingleQuotedEnd(line, start); end == -1 {
+			return lines, false
+		}
+		value = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	case yaml.DoubleQuotedStyle:
+		if end = yamlDoubleQuotedEnd(line, start); end == -1 {
+			return lines, false
+		}
+		value = strconv.Quote(value)
+	case 0:
+		end = len(line)
+		if i := strings.Index(string(line[start:]), " #"); i != -1 {
+			end = start + len([]rune(string(line[start:])[:i]))
+		}
+		for end > start && (line[end-1] == ' ' || line[end-1] == '\t') {
+			end--
+		}
+		if !yamlPlainSafe(value) {
+			value = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+		}
+	default:
+		return lines, false
+	}
+
+	lines[lineIdx] = string(line[:start]) + value + string(line[end:])
+	return lines, true
+}
+
+func replaceYAMLLiteral(lines []string, headerIdx, headerCol int, value string) ([]string, bool) {
+	header := []rune(lines[headerIdx])
+	if headerCol < 0 || headerCol >= len(header) {
+		return lines, false
+	}
+
+	// Explicit indentation indicators are rare enough that we leave them be.
+	if indicator := strings.TrimSpace(string(header[headerCol:])); strings.ContainsAny(strings.SplitN(indicator, "#", 2)[0], "0123456789") {
+		return lines, false
+	}
+
+	start := headerIdx + 1
+	indent := -1
+	end := start
+	for i := start; i < len(lines); i++ {
+		trimmed := strings.TrimLeft(lines[i], " ")
+		if trimmed == "" {
+			continue
+		}
+		lineIndent := len(lines[i]) - len(trimmed)
+		if indent == -1 {
+			indent = lineIndent
+		}
+		if lineIndent < indent {
+			break
+		}
+		end = i + 1
+	}
+	if indent <= 0 {
+		return lines, false
+	}
+
+	prefix := strings.Repeat(" ", indent)
+	var content []string
+	for _, l := range strings.Split(strings.TrimRight(value, "\n"), "\n") {
+		if l == "" {
+			content = append(content, "")
+		} else {
+			content = append(content, prefix+l)
+		}
+	}
+
+	newLines := make([]string, 0, len(lines)-(end-start)+len(content))
+	newLines = append(newLines, lines[:start]...)
+	newLines = append(newLines, content...)
+	newLines = append(newLines, lines[end:]...)
+	return newLines, true
+}
+
+func yamlSingleQuotedEnd(line []rune, start int) int {
+	if line[start] != '\'' {
+		return -1
+	}
+	for i := start + 1; i < len(line); i++ {
+		if line[i] == '\'' {
+			if i+1 < len(line) && line[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return -1
+}
+
+func yamlDoubleQuotedEnd(line []rune, start int) int {
+	if line[start] != '"' {
+		return -1
+	}
+	for i := start + 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+func yamlPlainSafe(v string) bool {
+	if v == "" || strings.TrimSpace(v) != v {
+		return false
+	}
+	if strings.ContainsRune("-?:,[]{}#&*!|>'\"%@`", rune(v[0])) {
+		return false
+	}
+	return !strings.Contains(v, ": ") && !strings.Contains(v, " #") && !strings.HasSuffix(v, ":")
+}
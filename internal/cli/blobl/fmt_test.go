@@ -0,0 +1,135 @@
+package blobl_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	icli "github.com/warpstreamlabs/bento/internal/cli"
+	"github.com/warpstreamlabs/bento/internal/cli/blobl"
+	"github.com/warpstreamlabs/bento/internal/cli/common"
+
+	_ "github.com/warpstreamlabs/bento/public/components/pure"
+)
+
+func executeFmtSubcmd(t *testing.T, stdin string, args ...string) (exitCode int, stdout, stderr string) {
+	t.Helper()
+
+	opts := common.NewCLIOpts("1.2.3", "now")
+	cliApp := icli.App(opts)
+	for _, c := range cliApp.Commands {
+		if c.Name != "blobl" {
+			continue
+		}
+		for _, sc := range c.Subcommands {
+			if sc.Name == "fmt" {
+				sc.Action = func(ctx *cli.Context) error {
+					var outBuf, errBuf bytes.Buffer
+					exitCode = blobl.FmtAction(ctx, opts, strings.NewReader(stdin), &outBuf, &errBuf)
+					stdout, stderr = outBuf.String(), errBuf.String()
+					return nil
+				}
+			}
+		}
+	}
+	require.NoError(t, cliApp.Run(append([]string{"bento", "blobl", "fmt"}, args...)))
+	return
+}
+
+func TestFmtStdin(t *testing.T) {
+	code, stdout, stderr := executeFmtSubcmd(t, "root = this.foo+1\nroot.bar = this.baz.uppercase( )")
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stderr)
+	assert.Equal(t, "root = this.foo + 1\nroot.bar = this.baz.uppercase()\n", stdout)
+
+	code, stdout, _ = executeFmtSubcmd(t, "root = this.foo+1", "--check")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "<stdin>\n", stdout)
+
+	code, _, stderr = executeFmtSubcmd(t, "root = this.foo.")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "<stdin>: line 1 char 17")
+}
+
+func TestFmtFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	tFile := func(name string) string {
+		return filepath.Join(tmpDir, name)
+	}
+
+	files := map[string]string{
+		"a.blobl": "root = this.foo+1\nroot.bar = this.bar.parse_logfmt( )\n",
+		"b.blobl": "root = this.foo + 1\n",
+		"config.yaml": `# A comment
+input:
+  generate:
+    mapping: root.id = uuid_v4( )   # plain
+    interval: 1s
+
+pipeline:
+  processors:
+    - mapping: |
+        root = this
+          root.doc = this.doc.uppercase( )
+        # trailing comment
+    - branch:
+        request_map: 'root.id = this.id'
+        result_map: "root.bar = this.bar+1"
+    - mutation: >-
+        root.folded = this.foo+1
+
+output:
+  drop: {}
+`,
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(tFile(name), []byte(content), 0o644))
+	}
+
+	code, stdout, stderr := executeFmtSubcmd(t, "", "--check", tFile("a.blobl"), tFile("b.blobl"), tFile("config.yaml"))
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stderr)
+	assert.Equal(t, tFile("a.blobl")+"\n"+tFile("config.yaml")+"\n", stdout)
+
+	code, _, stderr = executeFmtSubcmd(t, "", "-w", tFile("a.blobl"), tFile("b.blobl"), tFile("config.yaml"))
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stderr)
+
+	aBytes, err := os.ReadFile(tFile("a.blobl"))
+	require.NoError(t, err)
+	assert.Equal(t, "root = this.foo + 1\nroot.bar = this.bar.parse_logfmt()\n", string(aBytes))
+
+	confBytes, err := os.ReadFile(tFile("config.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `# A comment
+input:
+  generate:
+    mapping: root.id = uuid_v4()   # plain
+    interval: 1s
+
+pipeline:
+  processors:
+    - mapping: |
+        root = this
+        root.doc = this.doc.uppercase()
+        # trailing comment
+    - branch:
+        request_map: 'root.id = this.id'
+        result_map: "root.bar = this.bar + 1"
+    - mutation: >-
+        root.folded = this.foo+1
+
+output:
+  drop: {}
+`, string(confBytes))
+
+	code, stdout, _ = executeFmtSubcmd(t, "", "--check", tmpDir+"/...")
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stdout)
+}
@@ -0,0 +1,450 @@
+package blobl
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"syscall"
+	"unicode"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang"
+	"github.com/warpstreamlabs/bento/internal/bloblang/parser"
+	"github.com/warpstreamlabs/bento/internal/bloblang/query"
+	"github.com/warpstreamlabs/bento/internal/cli/common"
+	"github.com/warpstreamlabs/bento/internal/filepath/ifs"
+	"github.com/warpstreamlabs/bento/internal/lsp"
+)
+
+func lspCliCommand(opts *common.CLIOpts) *cli.Command {
+	return &cli.Command{
+		Name:  "lsp",
+		Usage: "Run a Bloblang language server over stdio.",
+		Description: opts.ExecTemplate(`
+Runs a language server implementing the Language Server Protocol over stdin and
+stdout, providing diagnostics, completion, hover documentation and go to
+definition of maps and imports for Bloblang mapping files.
+
+Configure your editor to launch the following command for .blobl files:
+
+  {{.BinaryName}} blobl lsp`)[1:],
+		Action: func(c *cli.Context) error {
+			ctx, done := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+			defer done()
+
+			server := lsp.NewServer("bloblang", opts.Version, newBloblangLanguage(bloblang.GlobalEnvironment())).
+				WithCompletionTriggers(".", "$", "\"")
+			return server.Serve(ctx, os.Stdin, os.Stdout)
+		},
+	}
+}
+
+//------------------------------------------------------------------------------
+
+var bloblangKeywords = []string{
+	"root", "this", "meta", "let", "map", "func", "import", "from", "if", "else", "match",
+}
+
+var (
+	mapDeclRegexp    = regexp.MustCompile(`(?m)^[ \t]*map[ \t]+(?:"([^"]+)"|([A-Za-z0-9_]+))[ \t]*\{`)
+	importRegexp     = regexp.MustCompile(`(?m)^[ \t]*(?:import|from)[ \t]+"([^"]+)"`)
+	applyRegexp      = regexp.MustCompile(`apply\(\s*"([^"]*)"\s*\)`)
+	letDeclRegexp    = regexp.MustCompile(`(?m)^[ \t]*let[ \t]+([A-Za-z0-9_]+)`)
+	applyOpenPattern = regexp.MustCompile(`apply\(\s*"[A-Za-z0-9_]*$`)
+)
+
+// NewLanguageHandler returns an lsp.Handler that provides language features
+// for Bloblang mappings parsed with the provided environment.
+func NewLanguageHandler(env *bloblang.Environment) lsp.Handler {
+	return newBloblangLanguage(env)
+}
+
+// bloblangLanguage implements lsp.Handler for Bloblang mapping documents.
+type bloblangLanguage struct {
+	env       *bloblang.Environment
+	functions map[string]query.FunctionSpec
+	methods   map[string]query.MethodSpec
+}
+
+func newBloblangLanguage(env *bloblang.Environment) *bloblangLanguage {
+	l := &bloblangLanguage{
+		env:       env,
+		functions: map[string]query.FunctionSpec{},
+		methods:   map[string]query.MethodSpec{},
+	}
+	env.WalkFunctions(func(name string, spec query.FunctionSpec) {
+		l.functions[name] = spec
+	})
+	env.WalkMethods(func(name string, spec query.MethodSpec) {
+		l.methods[name] = spec
+	})
+	return l
+}
+
+func (l *bloblangLanguage) envFor(doc *lsp.Document) *bloblang.Environment {
+	if strings.HasPrefix(doc.URI, "file:") {
+		return l.env.WithImporterRelativeToFile(doc.Path())
+	}
+	return l.env
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// identAt returns the start and end rune offsets of the identifier that
+// surrounds an offset.
+func identAt(text []rune, offset int) (start, end int) {
+	start, end = offset, offset
+	for start > 0 && isIdentRune(text[start-1]) {
+		start--
+	}
+	for end < len(text) && isIdentRune(text[end]) {
+		end++
+	}
+	return
+}
+
+// Diagnostics parses the mapping and reports the parser error, if any.
+func (l *bloblangLanguage) Diagnostics(doc *lsp.Document) []lsp.Diagnostic {
+	_, err := l.envFor(doc).NewMapping(doc.Text)
+	if err == nil {
+		return nil
+	}
+
+	text := doc.Runes()
+	perr, ok := err.(*parser.Error)
+	if !ok {
+		return []lsp.Diagnostic{{
+			Range:    lsp.Range{Start: lsp.Position{}, End: doc.Position(len(text))},
+			Severity: lsp.SeverityError,
+			Source:   "bloblang",
+			Message:  err.Error(),
+		}}
+	}
+
+	start := len(text) - len(perr.Input)
+	_, end := identAt(text, start)
+	if end == start {
+		// Errors such as unrecognised functions point to the end of the
+		// offending expression, so highlight back to the previous space.
+		for start > 0 && !unicode.IsSpace(text[start-1]) {
+			start--
+		}
+	}
+	if end == start && end < len(text) && text[end] != '\n' {
+		end++
+	}
+	return []lsp.Diagnostic{{
+		Range:    lsp.Range{Start: doc.Position(start), End: doc.Position(end)},
+		Severity: lsp.SeverityError,
+		Source:   "bloblang",
+		Message:  perr.Message(),
+	}}
+}
+
+// Completion suggests methods after a dot, variables after a dollar, map names
+// within an apply call and functions and keywords otherwise.
+func (l *bloblangLanguage) Completion(doc *lsp.Document, pos lsp.Position) []lsp.CompletionItem {
+	text := doc.Runes()
+	offset := doc.Offset(pos)
+	start, _ := identAt(text, offset)
+
+	var items []lsp.CompletionItem
+	if applyOpenPattern.MatchString(string(text[:offset])) {
+		for _, name := range l.mapNames(doc) {
+			items = append(items, lsp.CompletionItem{
+				Label: name,
+				Kind:  lsp.CompletionKindModule,
+			})
+		}
+		return items
+	}
+
+	if start > 0 && text[start-1] == '$' {
+		seen := map[string]struct{}{}
+		for _, m := range letDeclRegexp.FindAllStringSubmatch(doc.Text, -1) {
+			if _, exists := seen[m[1]]; exists {
+				continue
+			}
+			seen[m[1]] = struct{}{}
+			items = append(items, lsp.CompletionItem{
+				Label: m[1],
+				Kind:  lsp.CompletionKindVariable,
+			})
+		}
+		return items
+	}
+
+	if start > 0 && text[start-1] == '.' {
+		for name, spec := range l.methods {
+			if spec.Status == query.StatusHidden {
+				continue
+			}
+			items = append(items, lsp.CompletionItem{
+				Label:         name,
+				Kind:          lsp.CompletionKindMethod,
+				Detail:        signature(name, spec.Params),
+				Documentation: &lsp.MarkupContent{Kind: lsp.MarkupMarkdown, Value: methodDocs(spec)},
+				Deprecated:    spec.Status == query.StatusDeprecated,
+			})
+		}
+		return items
+	}
+
+	for name, spec := range l.functions {
+		if spec.Status == query.StatusHidden {
+			continue
+		}
+		items = append(items, lsp.CompletionItem{
+			Label:         name,
+			Kind:          lsp.CompletionKindFunction,
+			Detail:        signature(name, spec.Params),
+			Documentation: &lsp.MarkupContent{Kind: lsp.MarkupMarkdown, Value: functionDocs(spec)},
+			Deprecated:    spec.Status == query.StatusDeprecated,
+		})
+	}
+	for _, kw := range bloblangKeywords {
+		items = append(items, lsp.CompletionItem{
+			Label: kw,
+			Kind:  lsp.CompletionKindKeyword,
+		})
+	}
+	return items
+}
+
+// Hover shows the documentation of a function or method under the cursor.
+func (l *bloblangLanguage) Hover(doc *lsp.Document, pos lsp.Position) *lsp.Hover {
+	text := doc.Runes()
+	start, end := identAt(text, doc.Offset(pos))
+	if start == end {
+		return nil
+	}
+
+	// Only identifiers followed by an opening bracket are calls.
+	next := end
+	for next < len(text) && (text[next] == ' ' || text[next] == '\t') {
+		next++
+	}
+	if next >= len(text) || text[next] != '(' {
+		return nil
+	}
+
+	name := string(text[start:end])
+	var content string
+	if start > 0 && text[start-1] == '.' {
+		spec, exists := l.methods[name]
+		if !exists {
+			return nil
+		}
+		content = methodDocs(spec)
+	} else {
+		spec, exists := l.functions[name]
+		if !exists {
+			return nil
+		}
+		content = functionDocs(spec)
+	}
+	return &lsp.Hover{
+		Contents: lsp.MarkupContent{Kind: lsp.MarkupMarkdown, Value: content},
+		Range:    &lsp.Range{Start: doc.Position(start), End: doc.Position(end)},
+	}
+}
+
+// Definition resolves import statements to the imported file and map names
+// referenced by apply calls to their declaration, following imports.
+func (l *bloblangLanguage) Definition(doc *lsp.Document, pos lsp.Position) []lsp.Location {
+	lines := strings.Split(doc.Text, "\n")
+	if pos.Line >= len(lines) {
+		return nil
+	}
+	line := lines[pos.Line]
+	lineRunes := []rune(line)
+	col := len(string(lineRunes[:lsp.OffsetOf(lineRunes, lsp.Position{Character: pos.Character})]))
+
+	baseDir := ""
+	if strings.HasPrefix(doc.URI, "file:") {
+		baseDir = filepath.Dir(doc.Path())
+	}
+
+	if m := importRegexp.FindStringSubmatch(line); m != nil {
+		return []lsp.Location{{URI: lsp.PathToURI(resolveImport(baseDir, m[1]))}}
+	}
+
+	var target string
+	for _, m := range applyRegexp.FindAllStringSubmatchIndex(line, -1) {
+		if col >= m[0] && col <= m[1] {
+			target = line[m[2]:m[3]]
+			break
+		}
+	}
+	if target == "" {
+		if m := mapDeclRegexp.FindStringSubmatchIndex(line); m != nil && col >= m[0] && col <= m[1] {
+			if m[2] >= 0 {
+				target = line[m[2]:m[3]]
+			} else {
+				target = line[m[4]:m[5]]
+			}
+		}
+	}
+	if target == "" {
+		return nil
+	}
+
+	if loc, found := findMapDecl(doc.URI, doc.Text, baseDir, target, map[string]struct{}{}); found {
+		return []lsp.Location{loc}
+	}
+	return nil
+}
+
+// mapNames returns the names of maps declared within a document and any
+// documents it imports.
+func (l *bloblangLanguage) mapNames(doc *lsp.Document) []string {
+	baseDir := ""
+	if strings.HasPrefix(doc.URI, "file:") {
+		baseDir = filepath.Dir(doc.Path())
+	}
+
+	seen := map[string]struct{}{}
+	var walk func(text, dir string, visited map[string]struct{})
+	walk = func(text, dir string, visited map[string]struct{}) {
+		for _, m := range mapDeclRegexp.FindAllStringSubmatch(text, -1) {
+			name := m[1]
+			if name == "" {
+				name = m[2]
+			}
+			seen[name] = struct{}{}
+		}
+		for _, m := range importRegexp.FindAllStringSubmatch(text, -1) {
+			path := resolveImport(dir, m[1])
+			if _, exists := visited[path]; exists {
+				continue
+			}
+			visited[path] = struct{}{}
+			if contents, err := ifs.ReadFile(ifs.OS(), path); err == nil {
+				walk(string(contents), filepath.Dir(path), visited)
+			}
+		}
+	}
+	walk(doc.Text, baseDir, map[string]struct{}{})
+
+	names := make([]string, 0, len(seen))
+	for k := range seen {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func resolveImport(baseDir, path string) string {
+	if filepath.IsAbs(path) || baseDir == "" {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+func findMapDecl(uri, text, baseDir, name string, visited map[string]struct{}) (lsp.Location, bool) {
+	for _, m := range mapDeclRegexp.FindAllStringSubmatchIndex(text, -1) {
+		nameStart, nameEnd := m[2], m[3]
+		if nameStart < 0 {
+			nameStart, nameEnd = m[4], m[5]
+		}
+		if text[nameStart:nameEnd] != name {
+			continue
+		}
+		runes := []rune(text)
+		startRune := len([]rune(text[:nameStart]))
+		endRune := startRune + len([]rune(name))
+		return lsp.Location{
+			URI: uri,
+			Range: lsp.Range{
+				Start: lsp.PositionOf(runes, startRune),
+				End:   lsp.PositionOf(runes, endRune),
+			},
+		}, true
+	}
+
+	for _, m := range importRegexp.FindAllStringSubmatch(text, -1) {
+		path := resolveImport(baseDir, m[1])
+		if _, exists := visited[path]; exists {
+			continue
+		}
+		visited[path] = struct{}{}
+
+		contents, err := ifs.ReadFile(ifs.OS(), path)
+		if err != nil {
+			continue
+		}
+		if loc, found := findMapDecl(lsp.PathToURI(path), string(contents), filepath.Dir(path), name, visited); found {
+			return loc, true
+		}
+	}
+	return lsp.Location{}, false
+}
+
+//------------------------------------------------------------------------------
+
+func signature(name string, params query.Params) string {
+	if params.Variadic {
+		return name + "(...)"
+	}
+	args := make([]string, 0, len(params.Definitions))
+	for _, p := range params.Definitions {
+		arg := p.Name + ": " + string(p.ValueType)
+		if p.IsOptional || p.DefaultValue != nil {
+			arg = p.Name + "?: " + string(p.ValueType)
+		}
+		args = append(args, arg)
+	}
+	return name + "(" + strings.Join(args, ", ") + ")"
+}
+
+func paramsDocs(params query.Params) string {
+	if len(params.Definitions) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\n**Parameters**\n")
+	for _, p := range params.Definitions {
+		fmt.Fprintf(&b, "\n- `%v` <%v> %v", p.Name, p.ValueType, strings.TrimSpace(p.Description))
+		if p.DefaultValue != nil {
+			fmt.Fprintf(&b, " (default: `%v`)", *p.DefaultValue)
+		}
+	}
+	return b.String()
+}
+
+func statusDocs(status query.Status) string {
+	switch status {
+	case query.StatusBeta, query.StatusExperimental, query.StatusDeprecated:
+		return fmt.Sprintf("\n\n_Status: %v_", status)
+	}
+	return ""
+}
+
+func functionDocs(spec query.FunctionSpec) string {
+	return "```coffee\n" + signature(spec.Name, spec.Params) + "\n```\n\n" +
+		strings.TrimSpace(spec.Description) +
+		statusDocs(spec.Status) +
+		paramsDocs(spec.Params)
+}
+
+func methodDocs(spec query.MethodSpec) string {
+	description := strings.TrimSpace(spec.Description)
+	if description == "" {
+		for _, cat := range spec.Categories {
+			if description = strings.TrimSpace(cat.Description); description != "" {
+				break
+			}
+		}
+	}
+	return "```coffee\n." + signature(spec.Name, spec.Params) + "\n```\n\n" +
+		description +
+		statusDocs(spec.Status) +
+		paramsDocs(spec.Params)
+}
@@ -0,0 +1,139 @@
+package blobl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang"
+	"github.com/warpstreamlabs/bento/internal/lsp"
+)
+
+func TestBloblangLanguageDiagnostics(t *testing.T) {
+	l := newBloblangLanguage(bloblang.GlobalEnvironment())
+
+	doc := &lsp.Document{URI: "untitled:foo", Text: "root.foo = this.bar\nroot.baz = nope()\nroot.qux = 10"}
+	diags := l.Diagnostics(doc)
+	require.Len(t, diags, 1)
+	assert.Equal(t, lsp.Position{Line: 1, Character: 11}, diags[0].Range.Start)
+	assert.Equal(t, lsp.Position{Line: 1, Character: 17}, diags[0].Range.End)
+	assert.Contains(t, diags[0].Message, "unrecognised function 'nope'")
+
+	doc.Text = "root.foo = this.bar baz"
+	diags = l.Diagnostics(doc)
+	require.Len(t, diags, 1)
+	assert.Equal(t, lsp.Position{Line: 0, Character: 20}, diags[0].Range.Start)
+	assert.Equal(t, lsp.Position{Line: 0, Character: 23}, diags[0].Range.End)
+	assert.Equal(t, "expected line break", diags[0].Message)
+
+	doc.Text = "root.foo = this.bar.uppercase()"
+	assert.Empty(t, l.Diagnostics(doc))
+}
+
+func TestBloblangLanguageCompletion(t *testing.T) {
+	l := newBloblangLanguage(bloblang.GlobalEnvironment())
+
+	labels := func(items []lsp.CompletionItem) map[string]lsp.CompletionItem {
+		m := map[string]lsp.CompletionItem{}
+		for _, i := range items {
+			m[i.Label] = i
+		}
+		return m
+	}
+
+	doc := &lsp.Document{URI: "untitled:foo", Text: "let thing = 10\nroot.foo = this.bar.upp\nroot.bar = no\nroot.baz = $th"}
+
+	methods := labels(l.Completion(doc, lsp.Position{Line: 1, Character: 23}))
+	require.Contains(t, methods, "uppercase")
+	assert.Equal(t, lsp.CompletionKindMethod, methods["uppercase"].Kind)
+	assert.NotContains(t, methods, "now")
+
+	functions := labels(l.Completion(doc, lsp.Position{Line: 2, Character: 13}))
+	require.Contains(t, functions, "now")
+	assert.Equal(t, lsp.CompletionKindFunction, functions["now"].Kind)
+	assert.Contains(t, functions, "root")
+	assert.NotContains(t, functions, "uppercase")
+
+	range_ := labels(l.Completion(doc, lsp.Position{Line: 2, Character: 13}))["range"]
+	assert.Equal(t, "range(start: integer, stop: integer, step?: integer)", range_.Detail)
+	assert.Contains(t, range_.Documentation.Value, "`step` <integer>")
+
+	vars := labels(l.Completion(doc, lsp.Position{Line: 3, Character: 14}))
+	assert.Equal(t, map[string]lsp.CompletionItem{
+		"thing": {Label: "thing", Kind: lsp.CompletionKindVariable},
+	}, vars)
+}
+
+func TestBloblangLanguageHover(t *testing.T) {
+	l := newBloblangLanguage(bloblang.GlobalEnvironment())
+
+	doc := &lsp.Document{URI: "untitled:foo", Text: "root.foo = this.bar.uppercase()\nroot.bar = uuid_v4()"}
+
+	h := l.Hover(doc, lsp.Position{Line: 0, Character: 22})
+	require.NotNil(t, h)
+	assert.Contains(t, h.Contents.Value, ".uppercase()")
+	assert.Equal(t, lsp.Position{Line: 0, Character: 20}, h.Range.Start)
+
+	h = l.Hover(doc, lsp.Position{Line: 1, Character: 12})
+	require.NotNil(t, h)
+	assert.Contains(t, h.Contents.Value, "uuid_v4()")
+
+	assert.Nil(t, l.Hover(doc, lsp.Position{Line: 0, Character: 17}))
+}
+
+func TestBloblangLanguageDefinition(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "shared.blobl"), []byte(`
+map other {
+  root = this
+}
+
+map "quoted_thing" {
+  root = this
+}
+`), 0o644))
+
+	l := newBloblangLanguage(bloblang.GlobalEnvironment())
+
+	mainPath := filepath.Join(tmpDir, "main.blobl")
+	doc := &lsp.Document{
+		URI: lsp.PathToURI(mainPath),
+		Text: `import "./shared.blobl"
+
+map local {
+  root.v = this.v
+}
+
+root.a = this.apply("local")
+root.b = this.apply("other")
+root.c = this.apply("quoted_thing")
+`,
+	}
+
+	assert.Equal(t, []lsp.Location{
+		{URI: lsp.PathToURI(filepath.Join(tmpDir, "shared.blobl"))},
+	}, l.Definition(doc, lsp.Position{Line: 0, Character: 10}))
+
+	assert.Equal(t, []lsp.Location{{
+		URI:   doc.URI,
+		Range: lsp.Range{Start: lsp.Position{Line: 2, Character: 4}, End: lsp.Position{Line: 2, Character: 9}},
+	}}, l.Definition(doc, lsp.Position{Line: 6, Character: 23}))
+
+	assert.Equal(t, []lsp.Location{{
+		URI:   lsp.PathToURI(filepath.Join(tmpDir, "shared.blobl")),
+		Range: lsp.Range{Start: lsp.Position{Line: 1, Character: 4}, End: lsp.Position{Line: 1, Character: 9}},
+	}}, l.Definition(doc, lsp.Position{Line: 7, Character: 23}))
+
+	assert.Equal(t, []lsp.Location{{
+		URI:   lsp.PathToURI(filepath.Join(tmpDir, "shared.blobl")),
+		Range: lsp.Range{Start: lsp.Position{Line: 5, Character: 5}, End: lsp.Position{Line: 5, Character: 17}},
+	}}, l.Definition(doc, lsp.Position{Line: 8, Character: 23}))
+
+	assert.Nil(t, l.Definition(doc, lsp.Position{Line: 3, Character: 4}))
+
+	assert.Equal(t, []string{"local", "other", "quoted_thing"}, l.mapNames(doc))
+	assert.Empty(t, l.Diagnostics(doc))
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/warpstreamlabs/bento/internal/bundle"
 	"github.com/warpstreamlabs/bento/internal/bundle/errorsampling"
 	"github.com/warpstreamlabs/bento/internal/bundle/strict"
+	"github.com/warpstreamlabs/bento/internal/bundle/tracing"
 	"github.com/warpstreamlabs/bento/internal/component/metrics"
 	"github.com/warpstreamlabs/bento/internal/config"
 	"github.com/warpstreamlabs/bento/internal/docs"
@@ -106,9 +107,23 @@ func CreateManager(
 		manager.OptSetStreamsMode(streamsMode),
 	}, mgrOpts...)
 
+	env := bundle.GlobalEnvironment
+
 	// Initialise processors with global error handling strategy
 	if conf.ErrorHandling.Log.Enabled {
-		mgrOpts = append(mgrOpts, manager.OptSetEnvironment(errorsampling.ErrorSamplingBundle(conf.ErrorHandling, bundle.GlobalEnvironment)))
+		env = errorsampling.ErrorSamplingBundle(conf.ErrorHandling, env)
+	}
+
+	// Components are tapped when debug endpoints are enabled, allowing the
+	// messages passing through them to be observed via the API.
+	var tap *tracing.Tap
+	if conf.HTTP.DebugEndpoints {
+		tap = tracing.NewTap()
+		env = tracing.TappedBundle(env, tap)
+	}
+
+	if env != bundle.GlobalEnvironment {
+		mgrOpts = append(mgrOpts, manager.OptSetEnvironment(env))
 	}
 
 	switch conf.ErrorHandling.Strategy {
@@ -126,7 +141,16 @@ func CreateManager(
 		return
 	}
 
-	stoppableMgr = newStoppableManager(httpServer, mgr)
+	if tap != nil && !streamsMode {
+		httpServer.RegisterEndpoint(
+			"/debug/tap", "DEBUG: "+fmt.Sprintf(tracing.TapEndpointDescription, "the stream"),
+			func(w http.ResponseWriter, r *http.Request) {
+				tracing.ServeTap(w, r, tap, "", mgr.BloblEnvironment(), logger)
+			},
+		)
+	}
+
+	stoppableMgr = newStoppableManager(httpServer, mgr, tap)
 	return
 }
 
@@ -238,11 +262,12 @@ func RunManagerUntilStopped(
 	return 0
 }
 
-func newStoppableManager(api *api.Type, mgr *manager.Type) *StoppableManager {
+func newStoppableManager(api *api.Type, mgr *manager.Type, tap *tracing.Tap) *StoppableManager {
 	s := &StoppableManager{
 		api:           api,
 		apiClosedChan: make(chan struct{}),
 		mgr:           mgr,
+		tap:           tap,
 	}
 	// Start HTTP server.
 	go func() {
@@ -262,6 +287,7 @@ type StoppableManager struct {
 	api           *api.Type
 	apiClosedChan chan struct{}
 	mgr           *manager.Type
+	tap           *tracing.Tap
 }
 
 // Manager returns the underlying manager type.
@@ -274,6 +300,12 @@ func (s *StoppableManager) API() *api.Type {
 	return s.api
 }
 
+// Tap returns the tap of the components created by the manager, which is nil
+// unless debug endpoints are enabled.
+func (s *StoppableManager) Tap() *tracing.Tap {
+	return s.tap
+}
+
 // Stop the manager and the API server, gracefully if possible. If the context
 // has a deadline then this will be used as a mechanism for pre-emptively
 // attempting ungraceful stopping when nearing the deadline.
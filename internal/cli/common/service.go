@@ -10,6 +10,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/warpstreamlabs/bento/internal/bundle/tracing"
 	"github.com/warpstreamlabs/bento/internal/config"
 	"github.com/warpstreamlabs/bento/internal/manager"
 	"github.com/warpstreamlabs/bento/internal/stream"
@@ -82,7 +83,7 @@ func RunService(c *cli.Context, cliOpts *CLIOpts, streamsMode bool) int {
 	watching := c.Bool("watcher")
 	if streamsMode {
 		enableStreamsAPI := !c.Bool("no-api")
-		stoppableStream = initStreamsMode(cliOpts, strict, watching, enableStreamsAPI, confReader, stoppableManager.Manager())
+		stoppableStream = initStreamsMode(cliOpts, strict, watching, enableStreamsAPI, confReader, stoppableManager.Manager(), stoppableManager.Tap())
 	} else {
 		stoppableStream, dataStreamClosedChan = initNormalMode(cliOpts, conf, strict, watching, confReader, stoppableManager.Manager())
 	}
@@ -119,9 +120,10 @@ func initStreamsMode(
 	strict, watching, enableAPI bool,
 	confReader *config.Reader,
 	mgr *manager.Type,
+	tap *tracing.Tap,
 ) Stoppable {
 	logger := mgr.Logger()
-	streamMgr := strmmgr.New(mgr, strmmgr.OptAPIEnabled(enableAPI))
+	streamMgr := strmmgr.New(mgr, strmmgr.OptAPIEnabled(enableAPI), strmmgr.OptSetTap(tap))
 
 	streamConfs := map[string]stream.Config{}
 	lints, lintWarns, err := confReader.ReadStreams(streamConfs)
@@ -165,6 +165,16 @@ files with the .yaml or .yml extension.`)[1:],
 				Value: false,
 				Usage: "Do not produce lint errors when environment interpolations exist without defaults within configs but aren't defined.",
 			},
+			&cli.BoolFlag{
+				Name:  "type-check",
+				Value: false,
+				Usage: "Statically check the types of Bloblang mappings and print linting errors for any definite type errors.",
+			},
+			&cli.StringFlag{
+				Name:  "input-schema",
+				Value: "",
+				Usage: "A JSON Schema or Avro schema file, or a schema registry URL (http://localhost:8081/subjects/foo/versions/latest), describing the input documents of Bloblang mappings. Implies --type-check.",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			if code := LintAction(c, cliOpts, os.Stderr); code != 0 {
@@ -193,6 +203,14 @@ func LintAction(c *cli.Context, opts *common.CLIOpts, stderr io.Writer) int {
 	lConf.WarnDeprecated = !lConf.RejectDeprecated
 	lConf.RequireLabels = c.Bool("labels")
 	skipEnvVarCheck := c.Bool("skip-env-var-check")
+	lConf.BloblangTypeCheck = c.Bool("type-check")
+	if schemaTarget := c.String("input-schema"); schemaTarget != "" {
+		if lConf.BloblangInputSchema, err = loadLintInputSchema(schemaTarget); err != nil {
+			fmt.Fprintf(stderr, "Input schema error: %v\n", err)
+			return 1
+		}
+		lConf.BloblangTypeCheck = true
+	}
 
 	spec := opts.MainConfigSpecCtor()
 
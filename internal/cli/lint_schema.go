@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/filepath/ifs"
+	"github.com/warpstreamlabs/bento/public/bloblang"
+)
+
+// loadLintInputSchema reads a schema describing the input documents of
+// Bloblang mappings, either from a file path or from a schema registry URL of
+// the form http://localhost:8081/subjects/foo/versions/latest.
+func loadLintInputSchema(target string) (*bloblang.TypeSchema, error) {
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		return fetchRegistryInputSchema(target)
+	}
+
+	schemaBytes, err := ifs.ReadFile(ifs.OS(), target)
+	if err != nil {
+		return nil, err
+	}
+	if isAvroSchema(schemaBytes) {
+		return bloblang.NewTypeSchemaFromAvro(schemaBytes)
+	}
+	return bloblang.NewTypeSchemaFromJSONSchema(schemaBytes)
+}
+
+func fetchRegistryInputSchema(url string) (*bloblang.TypeSchema, error) {
+	client := http.Client{Timeout: 10 * time.Second}
+	res, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	resBytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("schema registry request failed with status %v: %s", res.StatusCode, resBytes)
+	}
+
+	var resPayload struct {
+		Schema     string `json:"schema"`
+		SchemaType string `json:"schemaType"`
+	}
+	if err := json.Unmarshal(resBytes, &resPayload); err != nil {
+		return nil, fmt.Errorf("failed to parse schema registry response: %w", err)
+	}
+	if resPayload.Schema == "" {
+		return nil, errors.New("schema registry response did not contain a schema")
+	}
+
+	switch resPayload.SchemaType {
+	case "", "AVRO":
+		return bloblang.NewTypeSchemaFromAvro([]byte(resPayload.Schema))
+	case "JSON":
+		return bloblang.NewTypeSchemaFromJSONSchema([]byte(resPayload.Schema))
+	}
+	return nil, fmt.Errorf("schema type %v is not supported", resPayload.SchemaType)
+}
+
+// isAvroSchema guesses whether a schema document is Avro rather than JSON
+// Schema, as both are expressed in JSON.
+func isAvroSchema(schemaBytes []byte) bool {
+	var v any
+	if err := json.Unmarshal(schemaBytes, &v); err != nil {
+		return false
+	}
+	switch t := v.(type) {
+	case []any, string:
+		return true
+	case map[string]any:
+		switch t["type"] {
+		case "record", "enum", "fixed":
+			return true
+		}
+	}
+	return false
+}
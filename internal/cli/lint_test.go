@@ -2,6 +2,8 @@ package cli_test
 
 import (
 	"bytes"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
@@ -150,6 +152,51 @@ output:
 `,
 			},
 		},
+		{
+			name: "type errors ignored without type check",
+			args: []string{"bento", "lint", tFile("foo.yaml")},
+			files: map[string]string{
+				"foo.yaml": `
+pipeline:
+  processors:
+    - mapping: 'root = 10.uppercase()'
+`,
+			},
+		},
+		{
+			name: "type errors with type check",
+			args: []string{"bento", "lint", "--type-check", tFile("foo.yaml")},
+			files: map[string]string{
+				"foo.yaml": `
+pipeline:
+  processors:
+    - mapping: 'root = 10.uppercase()'
+`,
+			},
+			expectedCode: 1,
+			expectedLints: []string{
+				"method uppercase: expected string or bytes value, got number from number literal",
+			},
+		},
+		{
+			name: "type errors with input schema",
+			args: []string{"bento", "lint", "--input-schema", tFile("schema.avsc"), tFile("foo.yaml")},
+			files: map[string]string{
+				"schema.avsc": `{"type":"record","name":"Foo","fields":[{"name":"count","type":"int"}]}`,
+				"foo.yaml": `
+pipeline:
+  processors:
+    - mapping: |
+        root.a = this.count.uppercase()
+        root.b = this.nope
+`,
+			},
+			expectedCode: 1,
+			expectedLints: []string{
+				"method uppercase: expected string or bytes value, got number from field `this.count`",
+				"field `this.nope` does not exist, the field nope is not one of the known fields [count]",
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -172,3 +219,22 @@ output:
 		})
 	}
 }
+
+func TestLintInputSchemaRegistry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/subjects/foo/versions/latest", r.URL.Path)
+		_, _ = w.Write([]byte(`{"subject":"foo","version":1,"schemaType":"JSON","schema":"{\"type\":\"object\",\"properties\":{\"id\":{\"type\":\"integer\"}},\"required\":[\"id\"]}"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	confPath := filepath.Join(t.TempDir(), "foo.yaml")
+	require.NoError(t, os.WriteFile(confPath, []byte(`
+pipeline:
+  processors:
+    - mapping: 'root = this.id.lowercase()'
+`), 0o644))
+
+	code, outStr := executeLintSubcmd(t, []string{"bento", "lint", "--input-schema", srv.URL + "/subjects/foo/versions/latest", confPath})
+	assert.Equal(t, 1, code)
+	assert.Contains(t, outStr, "method lowercase: expected string or bytes value, got number from field `this.id`")
+}
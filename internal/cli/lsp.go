@@ -0,0 +1,644 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang"
+	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/cli/blobl"
+	"github.com/warpstreamlabs/bento/internal/cli/common"
+	"github.com/warpstreamlabs/bento/internal/config"
+	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/internal/lsp"
+)
+
+func lspCliCommand(cliOpts *common.CLIOpts) *cli.Command {
+	return &cli.Command{
+		Name:  "lsp",
+		Usage: cliOpts.ExecTemplate("Run a language server for {{.ProductName}} config files over stdio"),
+		Description: cliOpts.ExecTemplate(`
+Runs a language server implementing the Language Server Protocol over stdin and
+stdout for {{.ProductName}} YAML config files. The server provides completion of
+component names and fields, field documentation on hover and reports the same
+linting errors as the lint subcommand as you type, including errors within
+Bloblang mappings and interpolated strings.
+
+Configure your editor to launch the following command for config files:
+
+  {{.BinaryName}} lsp`)[1:],
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "deprecated",
+				Value: false,
+				Usage: "Report the presence of deprecated fields as errors rather than warnings.",
+			},
+			&cli.BoolFlag{
+				Name:  "labels",
+				Value: false,
+				Usage: "Report linting errors when components do not have labels.",
+			},
+			&cli.BoolFlag{
+				Name:  "skip-env-var-check",
+				Value: false,
+				Usage: "Do not report environment interpolations without defaults that aren't defined.",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			ctx, done := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+			defer done()
+
+			lConf := docs.NewLintConfig(bundle.GlobalEnvironment)
+			lConf.RejectDeprecated = c.Bool("deprecated")
+			lConf.WarnDeprecated = !lConf.RejectDeprecated
+			lConf.RequireLabels = c.Bool("labels")
+
+			handler := newConfigLanguage(bundle.GlobalEnvironment, cliOpts.MainConfigSpecCtor(), lConf)
+			handler.skipEnvVarCheck = c.Bool("skip-env-var-check")
+
+			server := lsp.NewServer(cliOpts.BinaryName, cliOpts.Version, handler).
+				WithCompletionTriggers(".", "$", " ", "\n")
+			return server.Serve(ctx, os.Stdin, os.Stdout)
+		},
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// configLanguage implements lsp.Handler for config files by walking the
+// config spec alongside the YAML structure of a document.
+type configLanguage struct {
+	env             *bundle.Environment
+	spec            docs.FieldSpecs
+	lConf           docs.LintConfig
+	skipEnvVarCheck bool
+
+	blobl lsp.Handler
+}
+
+func newConfigLanguage(env *bundle.Environment, spec docs.FieldSpecs, lConf docs.LintConfig) *configLanguage {
+	return &configLanguage{
+		env:   env,
+		spec:  spec,
+		lConf: lConf,
+		blobl: blobl.NewLanguageHandler(bloblang.GlobalEnvironment()),
+	}
+}
+
+var yamlErrLineRegexp = regexp.MustCompile(`line (\d+)`)
+
+func lintToDiagnostic(lines []string, l docs.Lint) lsp.Diagnostic {
+	line := max(l.Line-1, 0)
+	if line >= len(lines) {
+		line = max(len(lines)-1, 0)
+	}
+	var lineText string
+	if len(lines) > 0 {
+		lineText = lines[line]
+	}
+	lineLen := len([]rune(lineText))
+
+	indent := len([]rune(lineText)) - len([]rune(strings.TrimLeft(lineText, " \t")))
+	col := min(max(l.Column-1, indent), lineLen)
+	if col == lineLen {
+		// Highlight the whole line when the lint points to the end of it.
+		col = indent
+	}
+
+	severity := lsp.SeverityError
+	if l.Level == docs.LintWarning {
+		severity = lsp.SeverityWarning
+	}
+	return lsp.Diagnostic{
+		Range: lsp.Range{
+			Start: lsp.Position{Line: line, Character: col},
+			End:   lsp.Position{Line: line, Character: lineLen},
+		},
+		Severity: severity,
+		Source:   "lint",
+		Message:  l.What,
+	}
+}
+
+// Diagnostics reports the lints that would be returned by the lint
+// subcommand for the document.
+func (c *configLanguage) Diagnostics(doc *lsp.Document) []lsp.Diagnostic {
+	lines := strings.Split(doc.Text, "\n")
+
+	var lints []docs.Lint
+	configBytes, err := config.ReplaceEnvVariables([]byte(doc.Text), os.LookupEnv)
+	if err != nil {
+		var errEnvMissing *config.ErrMissingEnvVars
+		if !errors.As(err, &errEnvMissing) {
+			lints = append(lints, docs.NewLintError(1, docs.LintFailedRead, err))
+			return diagnosticsFromLints(lines, lints)
+		}
+		configBytes = errEnvMissing.BestAttempt
+		if !c.skipEnvVarCheck {
+			lints = append(lints, docs.NewLintError(1, docs.LintMissingEnvVar, err))
+		}
+	}
+
+	cNode, err := docs.UnmarshalYAML(configBytes)
+	if err != nil {
+		line := 1
+		if m := yamlErrLineRegexp.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+		}
+		lints = append(lints, docs.NewLintError(line, docs.LintFailedRead, err))
+		return diagnosticsFromLints(lines, lints)
+	}
+
+	pConf, err := c.spec.ParsedConfigFromAny(cNode)
+	if err == nil {
+		_, err = config.FromParsed(c.env, pConf, nil)
+	}
+	if err != nil {
+		lints = append(lints, docs.NewLintError(1, docs.LintFailedRead, err))
+	}
+
+	if !bytes.HasPrefix(configBytes, []byte("# BENTO LINT DISABLE")) {
+		lints = append(lints, c.spec.LintYAML(docs.NewLintContext(c.lConf), cNode)...)
+	}
+	return diagnosticsFromLints(lines, lints)
+}
+
+func diagnosticsFromLints(lines []string, lints []docs.Lint) []lsp.Diagnostic {
+	diags := make([]lsp.Diagnostic, 0, len(lints))
+	for _, l := range lints {
+		diags = append(diags, lintToDiagnostic(lines, l))
+	}
+	return diags
+}
+
+// Completion suggests fields and component names for keys, options for
+// values, and defers to the Bloblang language for mappings and interpolations.
+func (c *configLanguage) Completion(doc *lsp.Document, pos lsp.Position) []lsp.CompletionItem {
+	ctx, ok := newYAMLCursor(doc, pos)
+	if !ok {
+		return nil
+	}
+
+	if ctx.valueKey != "" {
+		field, ok := c.fieldAt(append(ctx.path, ctx.valueKey))
+		if !ok {
+			return nil
+		}
+		if field.Bloblang || (field.Interpolated && ctx.inInterpolation()) {
+			return c.blobl.Completion(doc, pos)
+		}
+		return valueCompletions(field)
+	}
+
+	parent, ok := c.fieldAt(ctx.path)
+	if !ok {
+		return nil
+	}
+
+	var items []lsp.CompletionItem
+	if coreType, isCore := parent.Type.IsCoreComponent(); isCore && parent.Kind == docs.KindScalar {
+		for _, spec := range c.componentDocs(coreType) {
+			if spec.Status == docs.StatusDeprecated {
+				continue
+			}
+			items = append(items, lsp.CompletionItem{
+				Label:         spec.Name,
+				Kind:          lsp.CompletionKindModule,
+				Detail:        string(coreType),
+				Documentation: &lsp.MarkupContent{Kind: lsp.MarkupMarkdown, Value: componentMarkdown(spec)},
+				InsertText:    spec.Name + ":",
+			})
+		}
+		for name, spec := range docs.ReservedFieldsByType(coreType) {
+			if name == "type" || name == "plugin" {
+				continue
+			}
+			items = append(items, fieldCompletion(spec))
+		}
+		return items
+	}
+
+	for _, child := range parent.Children {
+		if child.IsDeprecated {
+			continue
+		}
+		items = append(items, fieldCompletion(child))
+	}
+	return items
+}
+
+// Hover shows documentation for the field or component under the cursor.
+func (c *configLanguage) Hover(doc *lsp.Document, pos lsp.Position) *lsp.Hover {
+	ctx, ok := newYAMLCursor(doc, pos)
+	if !ok {
+		return nil
+	}
+
+	if ctx.valueKey != "" {
+		if field, ok := c.fieldAt(append(ctx.path, ctx.valueKey)); ok &&
+			(field.Bloblang || (field.Interpolated && ctx.inInterpolation())) {
+			return c.blobl.Hover(doc, pos)
+		}
+		return nil
+	}
+
+	if ctx.key == "" || !ctx.onKey {
+		return nil
+	}
+
+	keyRange := &lsp.Range{
+		Start: lsp.Position{Line: pos.Line, Character: ctx.keyStart},
+		End:   lsp.Position{Line: pos.Line, Character: ctx.keyStart + len([]rune(ctx.key))},
+	}
+
+	parent, ok := c.fieldAt(ctx.path)
+	if !ok {
+		return nil
+	}
+	if coreType, isCore := parent.Type.IsCoreComponent(); isCore && parent.Kind == docs.KindScalar {
+		if _, isReserved := docs.ReservedFieldsByType(coreType)[ctx.key]; !isReserved {
+			spec, exists := c.env.GetDocs(ctx.key, coreType)
+			if !exists {
+				return nil
+			}
+			return &lsp.Hover{
+				Contents: lsp.MarkupContent{Kind: lsp.MarkupMarkdown, Value: componentMarkdown(spec)},
+				Range:    keyRange,
+			}
+		}
+	}
+
+	field, ok := c.fieldAt(append(ctx.path, ctx.key))
+	if !ok {
+		return nil
+	}
+	return &lsp.Hover{
+		Contents: lsp.MarkupContent{Kind: lsp.MarkupMarkdown, Value: fieldMarkdown(field)},
+		Range:    keyRange,
+	}
+}
+
+var labelLineRegexp = regexp.MustCompile(`^[ \t]*(?:-[ \t]+)*label:[ \t]*["']?([^"'#\s]+)`)
+
+// Definition resolves references to resources by their label, and defers to
+// the Bloblang language within mappings.
+func (c *configLanguage) Definition(doc *lsp.Document, pos lsp.Position) []lsp.Location {
+	ctx, ok := newYAMLCursor(doc, pos)
+	if !ok || ctx.valueKey == "" {
+		return nil
+	}
+
+	if field, ok := c.fieldAt(append(ctx.path, ctx.valueKey)); ok && field.Bloblang {
+		return c.blobl.Definition(doc, pos)
+	}
+
+	target := strings.Trim(strings.TrimSpace(ctx.value), `"'`)
+	if target == "" {
+		return nil
+	}
+	for i, line := range strings.Split(doc.Text, "\n") {
+		m := labelLineRegexp.FindStringSubmatchIndex(line)
+		if m == nil || line[m[2]:m[3]] != target {
+			continue
+		}
+		start := len([]rune(line[:m[2]]))
+		return []lsp.Location{{
+			URI: doc.URI,
+			Range: lsp.Range{
+				Start: lsp.Position{Line: i, Character: start},
+				End:   lsp.Position{Line: i, Character: start + len([]rune(target))},
+			},
+		}}
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+func (c *configLanguage) componentDocs(t docs.Type) []docs.ComponentSpec {
+	switch t {
+	case docs.TypeBuffer:
+		return c.env.BufferDocs()
+	case docs.TypeCache:
+		return c.env.CacheDocs()
+	case docs.TypeInput:
+		return c.env.InputDocs()
+	case docs.TypeMetrics:
+		return c.env.MetricsDocs()
+	case docs.TypeOutput:
+		return c.env.OutputDocs()
+	case docs.TypeProcessor:
+		return c.env.ProcessorDocs()
+	case docs.TypeRateLimit:
+		return c.env.RateLimitDocs()
+	case docs.TypeLock:
+		return c.env.LockDocs()
+	case docs.TypeScanner:
+		return c.env.ScannerDocs()
+	case docs.TypeTracer:
+		return c.env.TracersDocs()
+	}
+	return nil
+}
+
+// fieldAt returns the spec of the field found at a path of the config, where
+// an empty path is the root of the config.
+func (c *configLanguage) fieldAt(path []string) (docs.FieldSpec, bool) {
+	if len(path) == 0 {
+		return docs.FieldObject("", "").WithChildren(c.spec...), true
+	}
+	f, err := c.spec.GetDocsForPath(c.env, path...)
+	if err != nil {
+		return docs.FieldSpec{}, false
+	}
+	return f, true
+}
+
+func fieldCompletion(f docs.FieldSpec) lsp.CompletionItem {
+	return lsp.CompletionItem{
+		Label:         f.Name,
+		Kind:          lsp.CompletionKindProperty,
+		Detail:        fieldTypeString(f),
+		Documentation: &lsp.MarkupContent{Kind: lsp.MarkupMarkdown, Value: fieldMarkdown(f)},
+		InsertText:    f.Name + ": ",
+	}
+}
+
+func valueCompletions(f docs.FieldSpec) (items []lsp.CompletionItem) {
+	for _, opt := range f.AnnotatedOptions {
+		items = append(items, lsp.CompletionItem{
+			Label:         opt[0],
+			Kind:          lsp.CompletionKindValue,
+			Documentation: &lsp.MarkupContent{Kind: lsp.MarkupMarkdown, Value: opt[1]},
+		})
+	}
+	for _, opt := range f.Options {
+		items = append(items, lsp.CompletionItem{
+			Label: opt,
+			Kind:  lsp.CompletionKindValue,
+		})
+	}
+	if len(items) == 0 && f.Type == docs.FieldTypeBool && f.Kind == docs.KindScalar {
+		for _, v := range []string{"true", "false"} {
+			items = append(items, lsp.CompletionItem{Label: v, Kind: lsp.CompletionKindValue})
+		}
+	}
+	return
+}
+
+func fieldTypeString(f docs.FieldSpec) string {
+	switch f.Kind {
+	case docs.KindArray:
+		return "array of " + string(f.Type)
+	case docs.Kind2DArray:
+		return "two-dimensional array of " + string(f.Type)
+	case docs.KindMap:
+		return "map of " + string(f.Type)
+	}
+	return string(f.Type)
+}
+
+func fieldMarkdown(f docs.FieldSpec) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**`%v`** _%v_", f.Name, fieldTypeString(f))
+	if f.IsDeprecated {
+		b.WriteString(" (deprecated)")
+	}
+	if desc := strings.TrimSpace(f.Description); desc != "" {
+		b.WriteString("\n\n")
+		b.WriteString(desc)
+	}
+	if f.Interpolated {
+		b.WriteString("\n\nThis field supports interpolation functions.")
+	}
+	if f.Default != nil {
+		fmt.Fprintf(&b, "\n\nDefault: `%v`", *f.Default)
+	}
+	if len(f.AnnotatedOptions) > 0 {
+		b.WriteString("\n\nOptions:\n")
+		for _, opt := range f.AnnotatedOptions {
+			fmt.Fprintf(&b, "\n- `%v`: %v", opt[0], opt[1])
+		}
+	} else if len(f.Options) > 0 {
+		fmt.Fprintf(&b, "\n\nOptions: `%v`", strings.Join(f.Options, "`, `"))
+	}
+	return b.String()
+}
+
+func componentMarkdown(spec docs.ComponentSpec) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**`%v`** _%v_", spec.Name, spec.Type)
+	if spec.Status != docs.StatusStable && spec.Status != "" {
+		fmt.Fprintf(&b, " (%v)", spec.Status)
+	}
+	if summary := strings.TrimSpace(spec.Summary); summary != "" {
+		b.WriteString("\n\n")
+		b.WriteString(summary)
+	}
+	if desc := strings.TrimSpace(spec.Description); desc != "" {
+		b.WriteString("\n\n")
+		b.WriteString(desc)
+	}
+	return b.String()
+}
+
+//------------------------------------------------------------------------------
+
+var yamlKeyRegexp = regexp.MustCompile(`^("[^"]*"|'[^']*'|[A-Za-z0-9_\-./]+)[ \t]*:([ \t]|$)`)
+
+// yamlLine is a shallow parse of a single line of YAML, which is tolerant of
+// documents that are incomplete whilst being edited.
+type yamlLine struct {
+	blank    bool
+	indent   int
+	dashes   []int
+	key      string
+	keyCol   int
+	value    string
+	valueCol int
+}
+
+func parseYAMLLine(line string) yamlLine {
+	l := yamlLine{keyCol: -1, valueCol: -1}
+
+	i := 0
+	for i < len(line) && line[i] == ' ' {
+		i++
+	}
+	l.indent = i
+	for i < len(line) && line[i] == '-' && (i+1 == len(line) || line[i+1] == ' ') {
+		l.dashes = append(l.dashes, i)
+		i++
+		for i < len(line) && line[i] == ' ' {
+			i++
+		}
+	}
+
+	rest := line[i:]
+	if strings.TrimSpace(rest) == "" || strings.HasPrefix(rest, "#") {
+		l.blank = len(l.dashes) == 0
+		return l
+	}
+
+	m := yamlKeyRegexp.FindStringSubmatchIndex(rest)
+	if m == nil {
+		l.value, l.valueCol = rest, i
+		return l
+	}
+	l.key = strings.Trim(rest[m[2]:m[3]], `"'`)
+	l.keyCol = i
+
+	valueStart := m[5]
+	for valueStart < len(rest) && rest[valueStart] == ' ' {
+		valueStart++
+	}
+	l.value, l.valueCol = rest[valueStart:], i+valueStart
+	if idx := strings.Index(l.value, " #"); idx >= 0 {
+		l.value = l.value[:idx]
+	}
+	return l
+}
+
+func (l yamlLine) isBlockScalarKey() bool {
+	v := strings.TrimSpace(l.value)
+	return l.key != "" && (strings.HasPrefix(v, "|") || strings.HasPrefix(v, ">"))
+}
+
+// yamlCursor describes the YAML context of a cursor position.
+type yamlCursor struct {
+	// path of the mapping that contains the cursor.
+	path []string
+
+	// key of the current line and whether the cursor is placed on it.
+	key      string
+	keyStart int
+	onKey    bool
+
+	// valueKey is set when the cursor is within the value of a key, which
+	// might be a block scalar spanning multiple lines.
+	valueKey     string
+	value        string
+	valuePrefix  string
+	linesOfValue []string
+}
+
+// inInterpolation returns true when the cursor is placed within an unclosed
+// interpolation function.
+func (y yamlCursor) inInterpolation() bool {
+	prefix := strings.Join(y.linesOfValue, "\n") + y.valuePrefix
+	open := strings.LastIndex(prefix, "${!")
+	return open >= 0 && !strings.Contains(prefix[open:], "}")
+}
+
+func newYAMLCursor(doc *lsp.Document, pos lsp.Position) (yamlCursor, bool) {
+	lines := strings.Split(doc.Text, "\n")
+	if pos.Line >= len(lines) {
+		return yamlCursor{}, false
+	}
+
+	lineRunes := []rune(lines[pos.Line])
+	col := len(string(lineRunes[:lsp.OffsetOf(lineRunes, lsp.Position{Character: pos.Character})]))
+	line := lines[pos.Line]
+	cur := parseYAMLLine(line)
+
+	// Check whether the cursor is within a block scalar.
+	curIndent := cur.indent
+	if cur.blank {
+		curIndent = col
+	}
+	minIndent := curIndent
+	for j := pos.Line - 1; j >= 0; j-- {
+		pl := parseYAMLLine(lines[j])
+		if pl.blank {
+			continue
+		}
+		if pl.isBlockScalarKey() && pl.keyCol < minIndent {
+			var cursor yamlCursor
+			cursor.path = yamlParentPath(lines, j, pl.keyCol, pl.dashes)
+			cursor.valueKey = pl.key
+			cursor.linesOfValue = lines[j+1 : pos.Line]
+			cursor.valuePrefix = line[:min(col, len(line))]
+			cursor.value = line
+			return cursor, true
+		}
+		minIndent = min(minIndent, pl.indent)
+	}
+
+	var cursor yamlCursor
+	if cur.key != "" && col > cur.keyCol+len(cur.key) {
+		cursor.path = yamlParentPath(lines, pos.Line, cur.keyCol, cur.dashes)
+		cursor.valueKey = cur.key
+		cursor.value = cur.value
+		if col > cur.valueCol && cur.valueCol >= 0 {
+			cursor.valuePrefix = line[cur.valueCol:min(col, len(line))]
+		}
+		return cursor, true
+	}
+
+	target := col
+	if cur.key != "" {
+		target = cur.keyCol
+		cursor.key = cur.key
+		cursor.keyStart = len([]rune(line[:cur.keyCol]))
+		cursor.onKey = col >= cur.keyCol
+	} else if len(cur.dashes) > 0 {
+		target = max(col, cur.dashes[len(cur.dashes)-1]+2)
+	}
+	cursor.path = yamlParentPath(lines, pos.Line, target, cur.dashes)
+	return cursor, true
+}
+
+// yamlParentPath walks upwards from a line in order to determine the path of
+// the mapping that would contain a key placed at a given column.
+func yamlParentPath(lines []string, lineIdx, target int, dashes []int) []string {
+	var rev []string
+
+	// Whether a key at the same column as the target can be a parent, which is
+	// the case for sequences that are not indented beneath their key.
+	inclusive := false
+	consumeDashes := func(dashes []int) {
+		for k := len(dashes) - 1; k >= 0; k-- {
+			if dashes[k] < target {
+				rev = append(rev, "0")
+				target = dashes[k]
+				inclusive = true
+			}
+		}
+	}
+	consumeDashes(dashes)
+
+	for j := lineIdx - 1; j >= 0; j-- {
+		if target == 0 && !inclusive {
+			break
+		}
+		pl := parseYAMLLine(lines[j])
+		if pl.blank {
+			continue
+		}
+		if pl.key != "" && (pl.keyCol < target || (inclusive && pl.keyCol == target && len(pl.dashes) == 0)) {
+			rev = append(rev, pl.key)
+			target = pl.keyCol
+			inclusive = false
+			consumeDashes(pl.dashes)
+			continue
+		}
+		if pl.indent < target || len(pl.dashes) > 0 && pl.dashes[0] < target {
+			consumeDashes(pl.dashes)
+		}
+	}
+
+	path := make([]string, 0, len(rev))
+	for i := len(rev) - 1; i >= 0; i-- {
+		path = append(path, rev[i])
+	}
+	return path
+}
@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/component/buffer"
+	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+	"github.com/warpstreamlabs/bento/internal/component/output"
+	"github.com/warpstreamlabs/bento/internal/component/processor"
+	"github.com/warpstreamlabs/bento/internal/component/tracer"
+	"github.com/warpstreamlabs/bento/internal/config"
+	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/internal/lsp"
+)
+
+func testConfigLanguage(t *testing.T) *configLanguage {
+	t.Helper()
+
+	env := bundle.NewEnvironment()
+	require.NoError(t, env.BufferAdd(func(buffer.Config, bundle.NewManagement) (buffer.Streamed, error) {
+		return nil, nil
+	}, docs.ComponentSpec{Name: "none", Type: docs.TypeBuffer, Config: docs.FieldObject("", "").HasDefault(map[string]any{})}))
+	require.NoError(t, env.MetricsAdd(func(metrics.Config, bundle.NewManagement) (metrics.Type, error) {
+		return nil, nil
+	}, docs.ComponentSpec{Name: "none", Type: docs.TypeMetrics, Config: docs.FieldObject("", "").HasDefault(map[string]any{})}))
+	require.NoError(t, env.TracersAdd(func(tracer.Config, bundle.NewManagement) (trace.TracerProvider, error) {
+		return nil, nil
+	}, docs.ComponentSpec{Name: "none", Type: docs.TypeTracer, Config: docs.FieldObject("", "").HasDefault(map[string]any{})}))
+	require.NoError(t, env.OutputAdd(func(output.Config, bundle.NewManagement, ...processor.PipelineConstructorFunc) (output.Streamed, error) {
+		return nil, nil
+	}, docs.ComponentSpec{Name: "stdout", Type: docs.TypeOutput, Config: docs.FieldObject("", "").HasDefault(map[string]any{})}))
+	require.NoError(t, env.InputAdd(func(input.Config, bundle.NewManagement) (input.Streamed, error) {
+		return nil, nil
+	}, docs.ComponentSpec{Name: "stdin", Type: docs.TypeInput, Config: docs.FieldObject("", "").HasDefault(map[string]any{})}))
+	require.NoError(t, env.InputAdd(func(input.Config, bundle.NewManagement) (input.Streamed, error) {
+		return nil, nil
+	}, docs.ComponentSpec{
+		Name:    "generate",
+		Type:    docs.TypeInput,
+		Status:  docs.StatusStable,
+		Summary: "Generates messages.",
+		Config: docs.FieldComponent().WithChildren(
+			docs.FieldBloblang("mapping", "A mapping to execute."),
+			docs.FieldString("interval", "The time between messages.").HasDefault("1s"),
+			docs.FieldString("mode", "The mode.").HasOptions("fast", "slow").HasDefault("fast"),
+		),
+	}))
+	require.NoError(t, env.ProcessorAdd(func(processor.Config, bundle.NewManagement) (processor.V1, error) {
+		return nil, nil
+	}, docs.ComponentSpec{
+		Name:    "log",
+		Type:    docs.TypeProcessor,
+		Status:  docs.StatusStable,
+		Summary: "Logs messages.",
+		Config: docs.FieldComponent().WithChildren(
+			docs.FieldInterpolatedString("message", "The message to log.").HasDefault(""),
+			docs.FieldBool("verbose", "Be verbose.").HasDefault(false),
+		),
+	}))
+
+	return newConfigLanguage(env, config.Spec(), docs.NewLintConfig(env))
+}
+
+func completionLabels(items []lsp.CompletionItem) []string {
+	labels := make([]string, 0, len(items))
+	for _, i := range items {
+		labels = append(labels, i.Label)
+	}
+	return labels
+}
+
+func TestConfigLanguageDiagnostics(t *testing.T) {
+	l := testConfigLanguage(t)
+
+	doc := &lsp.Document{URI: "file:///config.yaml", Text: `input:
+  generate:
+    mapping: root = nope()
+    nah: 10
+`}
+	diags := l.Diagnostics(doc)
+	require.Len(t, diags, 2)
+
+	assert.Equal(t, 2, diags[0].Range.Start.Line)
+	assert.Contains(t, diags[0].Message, "unrecognised function 'nope'")
+	assert.Equal(t, lsp.SeverityError, diags[0].Severity)
+
+	assert.Equal(t, lsp.Range{
+		Start: lsp.Position{Line: 3, Character: 4},
+		End:   lsp.Position{Line: 3, Character: 11},
+	}, diags[1].Range)
+	assert.Equal(t, "field nah not recognised", diags[1].Message)
+
+	doc.Text = "input:\n\tgenerate: {}\n"
+	diags = l.Diagnostics(doc)
+	require.Len(t, diags, 1)
+	assert.Equal(t, 1, diags[0].Range.Start.Line)
+	assert.Contains(t, diags[0].Message, "found character that cannot start any token")
+
+	doc.Text = `pipeline:
+  processors:
+    - log:
+        message: '${! this.foo.nope() }'
+`
+	diags = l.Diagnostics(doc)
+	require.Len(t, diags, 1)
+	assert.Equal(t, 3, diags[0].Range.Start.Line)
+	assert.Contains(t, diags[0].Message, "unrecognised method 'nope'")
+}
+
+func TestConfigLanguageCompletion(t *testing.T) {
+	l := testConfigLanguage(t)
+
+	doc := &lsp.Document{URI: "file:///config.yaml", Text: `input:
+  generate:
+    mode: 
+    
+  
+pipeline:
+  processors:
+    - log:
+        verbose: 
+      
+    - 
+output:
+  label: foo
+  
+`}
+
+	labels := completionLabels(l.Completion(doc, lsp.Position{Line: 3, Character: 4}))
+	assert.ElementsMatch(t, []string{"mapping", "interval", "mode"}, labels)
+
+	labels = completionLabels(l.Completion(doc, lsp.Position{Line: 2, Character: 10}))
+	assert.ElementsMatch(t, []string{"fast", "slow"}, labels)
+
+	labels = completionLabels(l.Completion(doc, lsp.Position{Line: 4, Character: 2}))
+	assert.ElementsMatch(t, []string{"generate", "stdin", "label", "processors", "leader_only"}, labels)
+
+	labels = completionLabels(l.Completion(doc, lsp.Position{Line: 8, Character: 17}))
+	assert.ElementsMatch(t, []string{"true", "false"}, labels)
+
+	labels = completionLabels(l.Completion(doc, lsp.Position{Line: 9, Character: 6}))
+	assert.ElementsMatch(t, []string{"log", "label"}, labels)
+
+	labels = completionLabels(l.Completion(doc, lsp.Position{Line: 10, Character: 6}))
+	assert.ElementsMatch(t, []string{"log", "label"}, labels)
+
+	labels = completionLabels(l.Completion(doc, lsp.Position{Line: 13, Character: 2}))
+	assert.ElementsMatch(t, []string{"stdout", "label", "processors"}, labels)
+
+	labels = completionLabels(l.Completion(doc, lsp.Position{Line: 0, Character: 0}))
+	assert.Contains(t, labels, "input")
+	assert.Contains(t, labels, "pipeline")
+}
+
+func TestConfigLanguageBloblangCompletion(t *testing.T) {
+	l := testConfigLanguage(t)
+
+	doc := &lsp.Document{URI: "file:///config.yaml", Text: `input:
+  generate:
+    mapping: |
+      root = this.foo.upp
+      root.bar = 
+pipeline:
+  processors:
+    - log:
+        message: 'foo ${! this.bar.low'
+`}
+
+	labels := completionLabels(l.Completion(doc, lsp.Position{Line: 3, Character: 25}))
+	assert.Contains(t, labels, "uppercase")
+	assert.NotContains(t, labels, "now")
+
+	labels = completionLabels(l.Completion(doc, lsp.Position{Line: 4, Character: 17}))
+	assert.Contains(t, labels, "now")
+
+	labels = completionLabels(l.Completion(doc, lsp.Position{Line: 8, Character: 37}))
+	assert.Contains(t, labels, "lowercase")
+
+	labels = completionLabels(l.Completion(doc, lsp.Position{Line: 8, Character: 19}))
+	assert.Empty(t, labels)
+}
+
+func TestConfigLanguageHover(t *testing.T) {
+	l := testConfigLanguage(t)
+
+	doc := &lsp.Document{URI: "file:///config.yaml", Text: `input:
+  generate:
+    interval: 5s
+    mapping: root = this.foo.uppercase()
+`}
+
+	h := l.Hover(doc, lsp.Position{Line: 2, Character: 6})
+	require.NotNil(t, h)
+	assert.Contains(t, h.Contents.Value, "The time between messages.")
+	assert.Contains(t, h.Contents.Value, "Default: `1s`")
+	assert.Equal(t, lsp.Range{
+		Start: lsp.Position{Line: 2, Character: 4},
+		End:   lsp.Position{Line: 2, Character: 12},
+	}, *h.Range)
+
+	h = l.Hover(doc, lsp.Position{Line: 1, Character: 4})
+	require.NotNil(t, h)
+	assert.Contains(t, h.Contents.Value, "Generates messages.")
+
+	h = l.Hover(doc, lsp.Position{Line: 3, Character: 32})
+	require.NotNil(t, h)
+	assert.Contains(t, h.Contents.Value, ".uppercase()")
+
+	assert.Nil(t, l.Hover(doc, lsp.Position{Line: 2, Character: 15}))
+}
+
+func TestConfigLanguageDefinition(t *testing.T) {
+	l := testConfigLanguage(t)
+
+	doc := &lsp.Document{URI: "file:///config.yaml", Text: `pipeline:
+  processors:
+    - resource: foo
+
+processor_resources:
+  - label: foo
+    log:
+      message: hello
+`}
+
+	assert.Equal(t, []lsp.Location{{
+		URI: "file:///config.yaml",
+		Range: lsp.Range{
+			Start: lsp.Position{Line: 5, Character: 11},
+			End:   lsp.Position{Line: 5, Character: 14},
+		},
+	}}, l.Definition(doc, lsp.Position{Line: 2, Character: 17}))
+}
+
+func TestYAMLParentPath(t *testing.T) {
+	lines := []string{
+		"input:",
+		"  broker:",
+		"    inputs:",
+		"    - generate:",
+		"        mapping: root = {}",
+		"      processors:",
+		"        - log: {}",
+		"        - mapping: |",
+		"            root = this",
+	}
+
+	assert.Equal(t, []string{"input", "broker", "inputs", "0", "generate"}, yamlParentPath(lines, 4, 8, nil))
+	assert.Equal(t, []string{"input", "broker", "inputs", "0"}, yamlParentPath(lines, 5, 6, nil))
+	assert.Equal(t, []string{"input", "broker", "inputs", "0", "processors", "0"}, yamlParentPath(lines, 7, 10, []int{8}))
+	assert.Equal(t, []string{"input", "broker"}, yamlParentPath(lines, 3, 4, []int{4}))
+}
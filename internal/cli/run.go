@@ -278,6 +278,7 @@ variables have been resolved:
 				},
 			},
 			lintCliCommand(opts),
+			lspCliCommand(opts),
 			{
 				Name:   "run",
 				Hidden: !opts.ShowRunCommand,
@@ -32,6 +32,24 @@ type metricsCache struct {
 	mDelError   metrics.StatCounter
 	mDelSuccess metrics.StatCounter
 	mDelLatency metrics.StatTimer
+
+	mGetMultiError   metrics.StatCounter
+	mGetMultiSuccess metrics.StatCounter
+	mGetMultiLatency metrics.StatTimer
+
+	mIncrError   metrics.StatCounter
+	mIncrSuccess metrics.StatCounter
+	mIncrLatency metrics.StatTimer
+
+	mCASNotFound metrics.StatCounter
+	mCASMismatch metrics.StatCounter
+	mCASError    metrics.StatCounter
+	mCASSuccess  metrics.StatCounter
+	mCASLatency  metrics.StatTimer
+
+	mScanError   metrics.StatCounter
+	mScanSuccess metrics.StatCounter
+	mScanLatency metrics.StatTimer
 }
 
 // MetricsForCache wraps a cache with a struct that adds standard metrics over
@@ -61,6 +79,24 @@ func MetricsForCache(c V1, stats metrics.Type) V1 {
 		mDelError:   cacheError.With("delete"),
 		mDelSuccess: cacheSuccess.With("delete"),
 		mDelLatency: cacheLatency.With("delete"),
+
+		mGetMultiError:   cacheError.With("get_multi"),
+		mGetMultiSuccess: cacheSuccess.With("get_multi"),
+		mGetMultiLatency: cacheLatency.With("get_multi"),
+
+		mIncrError:   cacheError.With("increment"),
+		mIncrSuccess: cacheSuccess.With("increment"),
+		mIncrLatency: cacheLatency.With("increment"),
+
+		mCASNotFound: stats.GetCounterVec("cache_not_found", "operation").With("compare_and_swap"),
+		mCASMismatch: stats.GetCounterVec("cache_mismatch", "operation").With("compare_and_swap"),
+		mCASError:    cacheError.With("compare_and_swap"),
+		mCASSuccess:  cacheSuccess.With("compare_and_swap"),
+		mCASLatency:  cacheLatency.With("compare_and_swap"),
+
+		mScanError:   cacheError.With("scan"),
+		mScanSuccess: cacheSuccess.With("scan"),
+		mScanLatency: cacheLatency.With("scan"),
 	}
 }
 
@@ -132,6 +168,59 @@ func (a *metricsCache) Delete(ctx context.Context, key string) error {
 	return err
 }
 
+func (a *metricsCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	started := time.Now()
+	values, err := GetMulti(ctx, a.c, keys...)
+	a.mGetMultiLatency.Timing(int64(time.Since(started)))
+	if err != nil {
+		a.mGetMultiError.Incr(1)
+	} else {
+		a.mGetMultiSuccess.Incr(1)
+	}
+	return values, err
+}
+
+func (a *metricsCache) Increment(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	started := time.Now()
+	v, err := Increment(ctx, a.c, key, delta, ttl)
+	a.mIncrLatency.Timing(int64(time.Since(started)))
+	if err != nil {
+		a.mIncrError.Incr(1)
+	} else {
+		a.mIncrSuccess.Incr(1)
+	}
+	return v, err
+}
+
+func (a *metricsCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	started := time.Now()
+	err := CompareAndSwap(ctx, a.c, key, old, value, ttl)
+	a.mCASLatency.Timing(int64(time.Since(started)))
+	switch {
+	case err == nil:
+		a.mCASSuccess.Incr(1)
+	case errors.Is(err, component.ErrKeyNotFound):
+		a.mCASNotFound.Incr(1)
+	case errors.Is(err, component.ErrCompareAndSwapFailed):
+		a.mCASMismatch.Incr(1)
+	default:
+		a.mCASError.Incr(1)
+	}
+	return err
+}
+
+func (a *metricsCache) Scan(ctx context.Context, prefix string) ([]string, error) {
+	started := time.Now()
+	keys, err := Scan(ctx, a.c, prefix)
+	a.mScanLatency.Timing(int64(time.Since(started)))
+	if err != nil {
+		a.mScanError.Incr(1)
+	} else {
+		a.mScanSuccess.Incr(1)
+	}
+	return keys, err
+}
+
 func (a *metricsCache) Close(ctx context.Context) error {
 	return a.c.Close(ctx)
 }
@@ -2,7 +2,12 @@ package cache
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strconv"
 	"time"
+
+	"github.com/warpstreamlabs/bento/internal/component"
 )
 
 // TTLItem contains a value to cache along with an optional TTL.
@@ -38,3 +43,137 @@ type V1 interface {
 	// is cancelled.
 	Close(ctx context.Context) error
 }
+
+// MultiGetter is an interface implemented by caches that are able to retrieve
+// the values of multiple keys in a single operation.
+type MultiGetter interface {
+	// GetMulti attempts to locate and return the cached values of multiple
+	// keys. Keys that do not exist are omitted from the returned map, and an
+	// error is returned only if the command fails.
+	GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error)
+}
+
+// Incrementer is an interface implemented by caches that are able to
+// atomically increment an integer value.
+type Incrementer interface {
+	// Increment atomically adds a delta to the integer value of a key, stored
+	// as a decimal string, and returns the result. A key that does not exist is
+	// treated as zero. If a TTL is provided then it is reset by each increment.
+	Increment(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error)
+}
+
+// CompareAndSwapper is an interface implemented by caches that are able to
+// atomically set the value of a key only if it matches an expected value.
+type CompareAndSwapper interface {
+	// CompareAndSwap attempts to set the value of a key only if its current
+	// value matches old. Returns component.ErrKeyNotFound if the key does not
+	// exist and component.ErrCompareAndSwapFailed if the current value does not
+	// match.
+	CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error
+}
+
+// Scanner is an interface implemented by caches that are able to list the
+// keys they contain.
+type Scanner interface {
+	// Scan returns the keys of the cache that begin with a prefix, an empty
+	// prefix matches all keys. The order of the returned keys is not
+	// guaranteed.
+	Scan(ctx context.Context, prefix string) ([]string, error)
+}
+
+// GetMulti retrieves the values of multiple keys from a cache, using GetMulti
+// if the cache implements MultiGetter and otherwise getting each key in turn.
+func GetMulti(ctx context.Context, c V1, keys ...string) (map[string][]byte, error) {
+	if m, ok := c.(MultiGetter); ok {
+		return m.GetMulti(ctx, keys...)
+	}
+	values := make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		v, err := c.Get(ctx, k)
+		if err != nil {
+			if errors.Is(err, component.ErrKeyNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		values[k] = v
+	}
+	return values, nil
+}
+
+// Increment atomically adds a delta to the integer value of a key, using
+// Increment if the cache implements Incrementer, otherwise falling back to
+// IncrementWithCompareAndSwap if the cache implements CompareAndSwapper.
+// Returns component.ErrCacheOperationNotSupported if neither is implemented.
+func Increment(ctx context.Context, c V1, key string, delta int64, ttl *time.Duration) (int64, error) {
+	if i, ok := c.(Incrementer); ok {
+		return i.Increment(ctx, key, delta, ttl)
+	}
+	if _, ok := c.(CompareAndSwapper); ok {
+		return IncrementWithCompareAndSwap(ctx, c, key, delta, ttl)
+	}
+	return 0, component.ErrCacheOperationNotSupported
+}
+
+// CompareAndSwap sets the value of a key only if it matches an expected value,
+// returning component.ErrCacheOperationNotSupported if the cache does not
+// implement CompareAndSwapper.
+func CompareAndSwap(ctx context.Context, c V1, key string, old, value []byte, ttl *time.Duration) error {
+	if s, ok := c.(CompareAndSwapper); ok {
+		return s.CompareAndSwap(ctx, key, old, value, ttl)
+	}
+	return component.ErrCacheOperationNotSupported
+}
+
+// Scan lists the keys of a cache that begin with a prefix, returning
+// component.ErrCacheOperationNotSupported if the cache does not implement
+// Scanner.
+func Scan(ctx context.Context, c V1, prefix string) ([]string, error) {
+	if s, ok := c.(Scanner); ok {
+		return s.Scan(ctx, prefix)
+	}
+	return nil, component.ErrCacheOperationNotSupported
+}
+
+// IncrementWithCompareAndSwap implements an atomic increment for caches that
+// implement CompareAndSwapper but have no native increment, by retrying a read
+// followed by either an add or a compare and swap until one succeeds.
+func IncrementWithCompareAndSwap(ctx context.Context, c V1, key string, delta int64, ttl *time.Duration) (int64, error) {
+	s, ok := c.(CompareAndSwapper)
+	if !ok {
+		return 0, component.ErrCacheOperationNotSupported
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		old, err := c.Get(ctx, key)
+		if errors.Is(err, component.ErrKeyNotFound) {
+			if err = c.Add(ctx, key, strconv.AppendInt(nil, delta, 10), ttl); err == nil {
+				return delta, nil
+			}
+			if errors.Is(err, component.ErrKeyAlreadyExists) {
+				continue
+			}
+			return 0, err
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		current, err := strconv.ParseInt(string(old), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse value of key '%v' as an integer: %w", key, err)
+		}
+		current += delta
+
+		err = s.CompareAndSwap(ctx, key, old, strconv.AppendInt(nil, current, 10), ttl)
+		if err == nil {
+			return current, nil
+		}
+		if !errors.Is(err, component.ErrCompareAndSwapFailed) && !errors.Is(err, component.ErrKeyNotFound) {
+			return 0, err
+		}
+	}
+}
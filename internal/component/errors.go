@@ -91,6 +91,7 @@ var (
 	ErrCacheNotFound     = errors.New("cache not found")
 	ErrProcessorNotFound = errors.New("processor not found")
 	ErrRateLimitNotFound = errors.New("rate limit not found")
+	ErrLockNotFound      = errors.New("lock not found")
 	ErrOutputNotFound    = errors.New("output not found")
 	ErrKeyAlreadyExists  = errors.New("key already exists")
 	ErrKeyNotFound       = errors.New("key does not exist")
@@ -99,6 +100,14 @@ var (
 
 //------------------------------------------------------------------------------
 
+// Cache errors.
+var (
+	ErrCompareAndSwapFailed       = errors.New("value does not match the expected value")
+	ErrCacheOperationNotSupported = errors.New("operation not supported by this cache")
+)
+
+//------------------------------------------------------------------------------
+
 // Buffer errors.
 var (
 	ErrMessageTooLarge = errors.New("message body larger than buffer space")
@@ -17,6 +17,33 @@ type Config struct {
 	Type       string             `json:"type" yaml:"type"`
 	Plugin     any                `json:"plugin,omitempty" yaml:"plugin,omitempty"`
 	Processors []processor.Config `json:"processors" yaml:"processors"`
+	LeaderOnly *LeaderOnlyConfig  `json:"leader_only,omitempty" yaml:"leader_only,omitempty"`
+}
+
+// LeaderOnlyConfig describes a lock resource lease that an input must hold in
+// order to connect.
+type LeaderOnlyConfig struct {
+	Lock  string `json:"lock" yaml:"lock"`
+	Name  string `json:"name" yaml:"name"`
+	Lease string `json:"lease" yaml:"lease"`
+}
+
+func leaderOnlyFromAny(v any) (*LeaderOnlyConfig, error) {
+	pConf, err := docs.InputLeaderOnlyFieldSpec("leader_only").ParsedConfigFromAny(v)
+	if err != nil {
+		return nil, err
+	}
+	var conf LeaderOnlyConfig
+	if conf.Lock, err = pConf.FieldString("lock"); err != nil {
+		return nil, err
+	}
+	if conf.Name, err = pConf.FieldString("name"); err != nil {
+		return nil, err
+	}
+	if conf.Lease, err = pConf.FieldString("lease"); err != nil {
+		return nil, err
+	}
+	return &conf, nil
 }
 
 // NewConfig returns a configuration struct fully populated with default values.
@@ -68,6 +95,13 @@ func fromMap(prov docs.Provider, value map[string]any) (conf Config, err error)
 		}
 	}
 
+	if lV, exists := value["leader_only"]; exists {
+		if conf.LeaderOnly, err = leaderOnlyFromAny(lV); err != nil {
+			err = fmt.Errorf("leader_only: %w", err)
+			return
+		}
+	}
+
 	if p, exists := value[conf.Type]; exists {
 		conf.Plugin = p
 	} else if p, exists := value["plugin"]; exists {
@@ -95,6 +129,11 @@ func fromYAML(prov docs.Provider, value *yaml.Node) (conf Config, err error) {
 				}
 				conf.Processors = append(conf.Processors, tmpProc)
 			}
+		case "leader_only":
+			if conf.LeaderOnly, err = leaderOnlyFromAny(value.Content[i+1]); err != nil {
+				err = fmt.Errorf("leader_only: %w", err)
+				return
+			}
 		}
 	}
 
@@ -0,0 +1,295 @@
+package input
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/shutdown"
+
+	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/lock"
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+// LeaderOnlyManagement is the subset of a manager required by a leader only
+// input in order to access lock resources.
+type LeaderOnlyManagement interface {
+	component.Observability
+	ProbeLock(name string) bool
+	AccessLock(ctx context.Context, name string, fn func(lock.V1)) error
+}
+
+// LeaderOnly is an input wrapper that only constructs (and therefore connects)
+// its child input whilst a lease on a lock resource is held. When the lease is
+// lost the child input is stopped and drained, and the wrapper returns to
+// waiting for the lease.
+type LeaderOnly struct {
+	lockRes string
+	name    string
+	lease   time.Duration
+
+	ctor func() (Streamed, error)
+	mgr  LeaderOnlyManagement
+
+	childMut   sync.Mutex
+	child      Streamed
+	connStatus *component.ConnectionStatus
+
+	transactions chan message.Transaction
+	shutSig      *shutdown.Signaller
+}
+
+// NewLeaderOnly wraps an input constructor such that the input is only active
+// whilst the running instance holds a lease of a lock resource. The label is
+// used as the lease name when one is not explicitly configured.
+func NewLeaderOnly(conf LeaderOnlyConfig, label string, ctor func() (Streamed, error), mgr LeaderOnlyManagement) (*LeaderOnly, error) {
+	if conf.Lock == "" {
+		return nil, errors.New("leader_only: a lock resource must be specified")
+	}
+	if !mgr.ProbeLock(conf.Lock) {
+		return nil, fmt.Errorf("leader_only: lock resource '%v' was not found", conf.Lock)
+	}
+
+	name := conf.Name
+	if name == "" {
+		name = label
+	}
+	if name == "" {
+		return nil, errors.New("leader_only: a lease name must be specified when the input has no label")
+	}
+
+	lease, err := time.ParseDuration(conf.Lease)
+	if err != nil {
+		return nil, fmt.Errorf("leader_only: failed to parse lease: %w", err)
+	}
+	if lease <= 0 {
+		return nil, errors.New("leader_only: lease must be greater than zero")
+	}
+
+	l := &LeaderOnly{
+		lockRes:      conf.Lock,
+		name:         name,
+		lease:        lease,
+		ctor:         ctor,
+		mgr:          mgr,
+		transactions: make(chan message.Transaction),
+		shutSig:      shutdown.NewSignaller(),
+	}
+
+	// Whilst waiting for the lease the input is considered healthy, otherwise
+	// only one instance of a horizontally scaled deployment would ever be
+	// reported as ready.
+	l.connStatus = component.ConnectionActive(mgr)
+
+	go l.loop()
+	return l, nil
+}
+
+//------------------------------------------------------------------------------
+
+func (l *LeaderOnly) tryAcquire(ctx context.Context) (held bool, err error) {
+	if aerr := l.mgr.AccessLock(ctx, l.lockRes, func(lk lock.V1) {
+		held, err = lk.TryAcquire(ctx, l.name, l.lease)
+	}); aerr != nil {
+		err = aerr
+	}
+	return
+}
+
+func (l *LeaderOnly) release() {
+	ctx, done := context.WithTimeout(context.Background(), l.lease)
+	defer done()
+
+	if aerr := l.mgr.AccessLock(ctx, l.lockRes, func(lk lock.V1) {
+		if err := lk.Release(ctx, l.name); err != nil {
+			l.mgr.Logger().Error("Failed to release lease '%v': %v", l.name, err)
+		}
+	}); aerr != nil {
+		l.mgr.Logger().Error("Failed to release lease '%v': %v", l.name, aerr)
+	}
+}
+
+func (l *LeaderOnly) setStatus(child Streamed, status *component.ConnectionStatus) {
+	l.childMut.Lock()
+	l.child = child
+	l.connStatus = status
+	l.childMut.Unlock()
+}
+
+// waitForLease blocks until the lease is acquired and returns the time at which
+// the acquiring attempt began, returns false if the input was shut down before
+// that happened.
+func (l *LeaderOnly) waitForLease(ctx context.Context) (time.Time, bool) {
+	for {
+		attempted := time.Now()
+		held, err := l.tryAcquire(ctx)
+		if ctx.Err() != nil {
+			return time.Time{}, false
+		}
+		if err != nil {
+			l.mgr.Logger().Error("Failed to acquire lease '%v': %v", l.name, err)
+			l.setStatus(nil, component.ConnectionFailing(l.mgr, err))
+		} else if held {
+			return attempted, true
+		} else {
+			l.setStatus(nil, component.ConnectionActive(l.mgr))
+		}
+		if sleepWithCancellation(ctx, l.lease/3) != nil {
+			return time.Time{}, false
+		}
+	}
+}
+
+// runChild forwards transactions from a child input whilst renewing the lease,
+// returns true if the wrapper should shut down after the child has closed.
+//
+// When renewing fails the child is disconnected once the lease would expire
+// before the following attempt, as after expiring the lease may be acquired
+// by another instance. Half an interval is allowed for the following attempt
+// being delayed.
+func (l *LeaderOnly) runChild(ctx context.Context, child Streamed, lastRenewed time.Time) bool {
+	renewInterval := l.lease / 3
+	renew := time.NewTicker(renewInterval)
+	defer renew.Stop()
+
+	lost := false
+
+	softStopChan := l.shutSig.SoftStopChan()
+	for {
+		select {
+		case t, open := <-child.TransactionChan():
+			if !open {
+				waitCtx, done := l.shutSig.HardStopCtx(context.Background())
+				_ = child.WaitForClose(waitCtx)
+				done()
+				return !lost || l.shutSig.IsSoftStopSignalled()
+			}
+			select {
+			case l.transactions <- t:
+			case <-l.shutSig.HardStopChan():
+				child.TriggerCloseNow()
+				_ = child.WaitForClose(context.Background())
+				return true
+			}
+		case <-renew.C:
+			if lost {
+				continue
+			}
+			attempted := time.Now()
+			held, err := l.tryAcquire(ctx)
+			if err == nil && held {
+				lastRenewed = attempted
+				continue
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					continue
+				}
+				l.mgr.Logger().Error("Failed to renew lease '%v': %v", l.name, err)
+				if time.Since(lastRenewed)+renewInterval+renewInterval/2 < l.lease {
+					continue
+				}
+			}
+			lost = true
+			l.mgr.Logger().Warn("Lost lease '%v', disconnecting input", l.name)
+			child.TriggerStopConsuming()
+		case <-softStopChan:
+			softStopChan = nil
+			child.TriggerStopConsuming()
+		case <-l.shutSig.HardStopChan():
+			child.TriggerCloseNow()
+			_ = child.WaitForClose(context.Background())
+			return true
+		}
+	}
+}
+
+func (l *LeaderOnly) loop() {
+	mLeader := l.mgr.Metrics().GetGauge("input_leader")
+
+	closeAtLeisureCtx, calDone := l.shutSig.SoftStopCtx(context.Background())
+	defer calDone()
+
+	defer func() {
+		mLeader.Set(0)
+		l.setStatus(nil, component.ConnectionClosed(l.mgr))
+		close(l.transactions)
+		l.shutSig.TriggerHasStopped()
+	}()
+
+	for {
+		acquired, ok := l.waitForLease(closeAtLeisureCtx)
+		if !ok {
+			return
+		}
+
+		child, err := l.ctor()
+		if err != nil {
+			l.mgr.Logger().Error("Failed to create input whilst holding lease '%v': %v", l.name, err)
+			l.setStatus(nil, component.ConnectionFailing(l.mgr, err))
+			l.release()
+			if sleepWithCancellation(closeAtLeisureCtx, l.lease/3) != nil {
+				return
+			}
+			continue
+		}
+
+		l.mgr.Logger().Info("Acquired lease '%v', connecting input", l.name)
+		mLeader.Set(1)
+		l.setStatus(child, nil)
+
+		finished := l.runChild(closeAtLeisureCtx, child, acquired)
+
+		mLeader.Set(0)
+		l.setStatus(nil, component.ConnectionActive(l.mgr))
+		l.release()
+
+		if finished {
+			return
+		}
+	}
+}
+
+// TransactionChan returns a transactions channel for consuming messages from
+// this input type.
+func (l *LeaderOnly) TransactionChan() <-chan message.Transaction {
+	return l.transactions
+}
+
+// ConnectionStatus returns the connection status of the child input whilst the
+// lease is held, otherwise the status of the lease itself.
+func (l *LeaderOnly) ConnectionStatus() component.ConnectionStatuses {
+	l.childMut.Lock()
+	defer l.childMut.Unlock()
+	if l.child != nil {
+		return l.child.ConnectionStatus()
+	}
+	return component.ConnectionStatuses{l.connStatus}
+}
+
+// TriggerStopConsuming instructs the input to start shutting down resources
+// once all pending messages are delivered and acknowledged. This call does
+// not block.
+func (l *LeaderOnly) TriggerStopConsuming() {
+	l.shutSig.TriggerSoftStop()
+}
+
+// TriggerCloseNow triggers the shut down of this component but should not block
+// the calling goroutine.
+func (l *LeaderOnly) TriggerCloseNow() {
+	l.shutSig.TriggerHardStop()
+}
+
+// WaitForClose is a blocking call to wait until the component has finished
+// shutting down and cleaning up resources.
+func (l *LeaderOnly) WaitForClose(ctx context.Context) error {
+	select {
+	case <-l.shutSig.HasStoppedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
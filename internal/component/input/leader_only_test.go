@@ -0,0 +1,186 @@
+package input_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/manager/mock"
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+type leaderOnlyChild struct {
+	*mock.Input
+	stopped   atomic.Bool
+	stoppedAt atomic.Int64
+}
+
+func (c *leaderOnlyChild) stop() {
+	if !c.stopped.Swap(true) {
+		c.stoppedAt.Store(time.Now().UnixNano())
+	}
+}
+
+func (c *leaderOnlyChild) TriggerStopConsuming() {
+	c.stop()
+	c.Input.TriggerStopConsuming()
+}
+
+func (c *leaderOnlyChild) TriggerCloseNow() {
+	c.stop()
+	c.Input.TriggerCloseNow()
+}
+
+func TestLeaderOnlyConfigErrors(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.Locks["foo"] = mock.Lock(func(context.Context, string, time.Duration) (bool, error) {
+		return true, nil
+	})
+
+	ctor := func() (input.Streamed, error) {
+		t.Error("input should not be constructed")
+		return nil, nil
+	}
+
+	_, err := input.NewLeaderOnly(input.LeaderOnlyConfig{Lock: "bar", Lease: "1s"}, "meow", ctor, mgr)
+	require.EqualError(t, err, "leader_only: lock resource 'bar' was not found")
+
+	_, err = input.NewLeaderOnly(input.LeaderOnlyConfig{Lock: "foo", Lease: "1s"}, "", ctor, mgr)
+	require.EqualError(t, err, "leader_only: a lease name must be specified when the input has no label")
+
+	_, err = input.NewLeaderOnly(input.LeaderOnlyConfig{Lock: "foo", Lease: "nope"}, "meow", ctor, mgr)
+	require.Error(t, err)
+}
+
+func TestLeaderOnlyLeaseLifecycle(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	var held atomic.Bool
+	var lastName atomic.Value
+
+	mgr := mock.NewManager()
+	mgr.Locks["foo"] = mock.Lock(func(_ context.Context, name string, _ time.Duration) (bool, error) {
+		lastName.Store(name)
+		return held.Load(), nil
+	})
+
+	children := make(chan *leaderOnlyChild, 10)
+	l, err := input.NewLeaderOnly(input.LeaderOnlyConfig{Lock: "foo", Lease: "30ms"}, "meow", func() (input.Streamed, error) {
+		child := &leaderOnlyChild{Input: &mock.Input{TChan: make(chan message.Transaction)}}
+		children <- child
+		return child, nil
+	}, mgr)
+	require.NoError(t, err)
+
+	// Standby instances are reported as connected.
+	time.Sleep(time.Millisecond * 50)
+	assert.True(t, l.ConnectionStatus().AllActive())
+	assert.Empty(t, children)
+	assert.Equal(t, "meow", lastName.Load())
+
+	sendAndReceive := func(child *leaderOnlyChild) {
+		t.Helper()
+		resChan := make(chan error, 1)
+		select {
+		case child.TChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("hello")}), resChan):
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+		select {
+		case tran := <-l.TransactionChan():
+			assert.Equal(t, "hello", string(tran.Payload.Get(0).AsBytes()))
+			require.NoError(t, tran.Ack(ctx, nil))
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+	}
+
+	held.Store(true)
+
+	var child *leaderOnlyChild
+	select {
+	case child = <-children:
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+	sendAndReceive(child)
+
+	// Losing the lease disconnects the child and returns to standby.
+	held.Store(false)
+	assert.Eventually(t, func() bool {
+		return child.stopped.Load()
+	}, time.Second, time.Millisecond*10)
+
+	held.Store(true)
+	select {
+	case child = <-children:
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+	sendAndReceive(child)
+
+	l.TriggerStopConsuming()
+	require.NoError(t, l.WaitForClose(ctx))
+
+	_, open := <-l.TransactionChan()
+	assert.False(t, open)
+	assert.True(t, child.stopped.Load())
+}
+
+func TestLeaderOnlyRenewFailures(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	const lease = time.Millisecond * 300
+
+	var failing atomic.Bool
+	var lastAcquired atomic.Int64
+
+	mgr := mock.NewManager()
+	mgr.Locks["foo"] = mock.Lock(func(context.Context, string, time.Duration) (bool, error) {
+		if failing.Load() {
+			return false, errors.New("lock backend unavailable")
+		}
+		// Record the time of the request, which is when the lease begins.
+		lastAcquired.Store(time.Now().UnixNano())
+		return true, nil
+	})
+
+	children := make(chan *leaderOnlyChild, 10)
+	l, err := input.NewLeaderOnly(input.LeaderOnlyConfig{Lock: "foo", Lease: lease.String()}, "meow", func() (input.Streamed, error) {
+		child := &leaderOnlyChild{Input: &mock.Input{TChan: make(chan message.Transaction)}}
+		children <- child
+		return child, nil
+	}, mgr)
+	require.NoError(t, err)
+
+	var child *leaderOnlyChild
+	select {
+	case child = <-children:
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	// Allow the lease to be renewed at least once before renewals fail.
+	time.Sleep(lease / 2)
+	failing.Store(true)
+
+	assert.Eventually(t, func() bool {
+		return child.stopped.Load()
+	}, time.Second*2, time.Millisecond)
+
+	// The child must stop before the lease that was last acquired expires.
+	deadline := time.Unix(0, lastAcquired.Load()).Add(lease)
+	stoppedAt := time.Unix(0, child.stoppedAt.Load())
+	assert.True(t, stoppedAt.Before(deadline), "stopped %v after the lease expired", stoppedAt.Sub(deadline))
+
+	l.TriggerCloseNow()
+	require.NoError(t, l.WaitForClose(ctx))
+}
@@ -0,0 +1,25 @@
+package lock
+
+import (
+	"context"
+	"time"
+)
+
+// V1 is a common interface implemented by locks.
+type V1 interface {
+	// TryAcquire attempts to acquire a named lock for the period of a lease, or
+	// to extend the lease when the lock is already held by this instance.
+	// Returns false if the lock is currently held elsewhere, or an error if the
+	// attempt fails.
+	TryAcquire(ctx context.Context, name string, lease time.Duration) (bool, error)
+
+	// Release a named lock held by this instance so that it can be acquired
+	// elsewhere without waiting for the lease to expire. Releasing a lock that
+	// is not held by this instance has no effect.
+	Release(ctx context.Context, name string) error
+
+	// Close the component, blocks until either the underlying resources are
+	// cleaned up or the context is cancelled. Returns an error if the context
+	// is cancelled.
+	Close(ctx context.Context) error
+}
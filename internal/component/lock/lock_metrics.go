@@ -0,0 +1,51 @@
+package lock
+
+import (
+	"context"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+)
+
+type metricsLock struct {
+	l V1
+
+	mAcquire       metrics.StatCounter
+	mHeldElsewhere metrics.StatCounter
+	mErr           metrics.StatCounter
+}
+
+// MetricsForLock wraps a lock.V1 with a struct that records metrics for each
+// acquisition attempt.
+func MetricsForLock(l V1, stats metrics.Type) V1 {
+	return &metricsLock{
+		l: l,
+
+		mAcquire:       stats.GetCounter("lock_acquire"),
+		mHeldElsewhere: stats.GetCounter("lock_held_elsewhere"),
+		mErr:           stats.GetCounter("lock_error"),
+	}
+}
+
+func (m *metricsLock) TryAcquire(ctx context.Context, name string, lease time.Duration) (bool, error) {
+	m.mAcquire.Incr(1)
+	held, err := m.l.TryAcquire(ctx, name, lease)
+	if err != nil {
+		m.mErr.Incr(1)
+	} else if !held {
+		m.mHeldElsewhere.Incr(1)
+	}
+	return held, err
+}
+
+func (m *metricsLock) Release(ctx context.Context, name string) error {
+	err := m.l.Release(ctx, name)
+	if err != nil {
+		m.mErr.Incr(1)
+	}
+	return err
+}
+
+func (m *metricsLock) Close(ctx context.Context) error {
+	return m.l.Close(ctx)
+}
@@ -0,0 +1,59 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+)
+
+type fnLock struct {
+	acquire func(name string) (bool, error)
+	closed  bool
+}
+
+func (f *fnLock) TryAcquire(ctx context.Context, name string, lease time.Duration) (bool, error) {
+	return f.acquire(name)
+}
+
+func (f *fnLock) Release(ctx context.Context, name string) error {
+	return nil
+}
+
+func (f *fnLock) Close(ctx context.Context) error {
+	f.closed = true
+	return nil
+}
+
+func TestLockMetrics(t *testing.T) {
+	stats := metrics.NewLocal()
+
+	l := &fnLock{acquire: func(name string) (bool, error) {
+		switch name {
+		case "mine":
+			return true, nil
+		case "theirs":
+			return false, nil
+		}
+		return false, errors.New("nope")
+	}}
+	ml := MetricsForLock(l, stats)
+
+	ctx := context.Background()
+	for _, name := range []string{"mine", "mine", "theirs", "broken"} {
+		_, _ = ml.TryAcquire(ctx, name, time.Second)
+	}
+
+	counters := stats.GetCounters()
+	assert.Equal(t, int64(4), counters["lock_acquire"])
+	assert.Equal(t, int64(1), counters["lock_held_elsewhere"])
+	assert.Equal(t, int64(1), counters["lock_error"])
+
+	require.NoError(t, ml.Close(ctx))
+	assert.True(t, l.closed)
+}
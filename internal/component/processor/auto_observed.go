@@ -100,6 +100,8 @@ func (a *v2ToV1Processor) ProcessBatch(ctx context.Context, msg message.Batch) (
 			a.mgr.Logger().Debug("Processor failed: %v", err)
 			MarkErr(part, span, err)
 			nextParts = append(nextParts, part)
+		} else {
+			tracing.PropagateSpans(a.mgr.Tracer(), a.typeStr, message.Batch{part}, []message.Batch{nextParts})
 		}
 
 		span.Finish()
@@ -235,6 +237,8 @@ func (a *v2BatchedToV1Processor) ProcessBatch(ctx context.Context, msg message.B
 			return nil
 		})
 		outputBatches = append(outputBatches, msg)
+	} else {
+		tracing.PropagateSpans(a.mgr.Tracer(), a.typeStr, msg, outputBatches)
 	}
 
 	for _, s := range spans {
@@ -9,9 +9,13 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/warpstreamlabs/bento/internal/component"
 	"github.com/warpstreamlabs/bento/internal/message"
+	"github.com/warpstreamlabs/bento/internal/tracing"
 )
 
 type fnProcessor struct {
@@ -257,3 +261,42 @@ func TestBatchProcessorAirGapIndividualErrors(t *testing.T) {
 	assert.NoError(t, msgs[0][1].ErrorGet())
 	assert.EqualError(t, msgs[0][2].ErrorGet(), "invalid character 'a' looking for beginning of value")
 }
+
+type tracedObs struct {
+	component.Observability
+	prov trace.TracerProvider
+}
+
+func (o tracedObs) Tracer() trace.TracerProvider {
+	return o.prov
+}
+
+func TestBatchProcessorAirGapMergeLineage(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prov := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+
+	agrp := NewAutoObservedBatchedProcessor("foo", &fnBatchProcessor{
+		fn: func(c *BatchProcContext, msgs message.Batch) ([]message.Batch, error) {
+			return []message.Batch{{message.NewPart([]byte("merged"))}}, nil
+		},
+	}, tracedObs{Observability: component.NoopObservability(), prov: prov})
+
+	msg := message.QuickBatch([][]byte{[]byte("foo"), []byte("bar")})
+	tracing.InitSpans(prov, "input", msg)
+
+	msgs, res := agrp.ProcessBatch(context.Background(), msg)
+	require.NoError(t, res)
+	require.Len(t, msgs, 1)
+	require.Equal(t, 1, msgs[0].Len())
+
+	var merged sdktrace.ReadOnlySpan
+	for _, s := range rec.Ended() {
+		if s.SpanContext().SpanID() == trace.SpanContextFromContext(msgs[0][0].GetContext()).SpanID() {
+			merged = s
+		}
+	}
+	require.NotNil(t, merged)
+	assert.Equal(t, trace.SpanContextFromContext(msg[0].GetContext()).SpanID(), merged.Parent().SpanID())
+	require.Len(t, merged.Links(), 1)
+	assert.Equal(t, trace.SpanContextFromContext(msg[1].GetContext()), merged.Links()[0].SpanContext)
+}
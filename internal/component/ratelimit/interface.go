@@ -18,3 +18,44 @@ type V1 interface {
 	// is cancelled.
 	Close(ctx context.Context) error
 }
+
+// Keyed is an interface implemented by rate limits that are able to limit
+// access independently for each of an arbitrary number of keys.
+type Keyed interface {
+	// AccessKey accesses the rate limited resource identified by a key, where
+	// the limit is applied separately for each key. Returns a duration or an
+	// error if the rate limit check fails. The returned duration is either zero
+	// (meaning the resource may be accessed) or a reasonable length of time to
+	// wait before requesting again.
+	AccessKey(ctx context.Context, key string) (time.Duration, error)
+}
+
+// Adaptive is an interface implemented by rate limits that adjust their limit
+// according to feedback from the components that access them, such as whether
+// a downstream service is throttling requests.
+type Adaptive interface {
+	// ReportSuccess reports that a request made after accessing the rate limit
+	// was accepted.
+	ReportSuccess(ctx context.Context)
+
+	// ReportThrottled reports that a request made after accessing the rate
+	// limit was throttled. The retryAfter duration is the period requested by
+	// the downstream service before trying again, or zero if unknown.
+	ReportThrottled(ctx context.Context, retryAfter time.Duration)
+}
+
+// ReportSuccess reports a successful request to a rate limit if it implements
+// Adaptive, and otherwise does nothing.
+func ReportSuccess(ctx context.Context, r any) {
+	if a, ok := r.(Adaptive); ok {
+		a.ReportSuccess(ctx)
+	}
+}
+
+// ReportThrottled reports a throttled request to a rate limit if it implements
+// Adaptive, and otherwise does nothing.
+func ReportThrottled(ctx context.Context, r any, retryAfter time.Duration) {
+	if a, ok := r.(Adaptive); ok {
+		a.ReportThrottled(ctx, retryAfter)
+	}
+}
@@ -19,13 +19,17 @@ type metricsRateLimit struct {
 // MetricsForRateLimit wraps a ratelimit.V2 with a struct that implements
 // types.RateLimit.
 func MetricsForRateLimit(r V1, stats metrics.Type) V1 {
-	return &metricsRateLimit{
+	m := &metricsRateLimit{
 		r: r,
 
 		mChecked: stats.GetCounter("rate_limit_checked"),
 		mLimited: stats.GetCounter("rate_limit_triggered"),
 		mErr:     stats.GetCounter("rate_limit_error"),
 	}
+	if k, ok := r.(Keyed); ok {
+		return &metricsKeyedRateLimit{metricsRateLimit: m, k: k}
+	}
+	return m
 }
 
 func (r *metricsRateLimit) Access(ctx context.Context) (time.Duration, error) {
@@ -39,6 +43,14 @@ func (r *metricsRateLimit) Access(ctx context.Context) (time.Duration, error) {
 	return tout, err
 }
 
+func (r *metricsRateLimit) ReportSuccess(ctx context.Context) {
+	ReportSuccess(ctx, r.r)
+}
+
+func (r *metricsRateLimit) ReportThrottled(ctx context.Context, retryAfter time.Duration) {
+	ReportThrottled(ctx, r.r, retryAfter)
+}
+
 func (r *metricsRateLimit) Close(ctx context.Context) error {
 	return r.r.Close(ctx)
 }
@@ -56,13 +68,25 @@ type metricsMessageAwareRateLimit struct {
 // MetricsForRateLimit wraps a ratelimit.V2 with a struct that implements
 // types.RateLimit.
 func MetricsForMessageAwareRateLimit(r MessageAwareRateLimit, stats metrics.Type) MessageAwareRateLimit {
-	return &metricsMessageAwareRateLimit{
+	m := &metricsMessageAwareRateLimit{
 		r: r,
 
 		mChecked: stats.GetCounter("rate_limit_checked"),
 		mLimited: stats.GetCounter("rate_limit_triggered"),
 		mErr:     stats.GetCounter("rate_limit_error"),
 	}
+	if k, ok := r.(Keyed); ok {
+		return &metricsKeyedMessageAwareRateLimit{metricsMessageAwareRateLimit: m, k: k}
+	}
+	return m
+}
+
+func (r *metricsMessageAwareRateLimit) ReportSuccess(ctx context.Context) {
+	ReportSuccess(ctx, r.r)
+}
+
+func (r *metricsMessageAwareRateLimit) ReportThrottled(ctx context.Context, retryAfter time.Duration) {
+	ReportThrottled(ctx, r.r, retryAfter)
 }
 
 func (r *metricsMessageAwareRateLimit) Add(ctx context.Context, parts ...*message.Part) bool {
@@ -83,3 +107,38 @@ func (r *metricsMessageAwareRateLimit) Access(ctx context.Context) (time.Duratio
 func (r *metricsMessageAwareRateLimit) Close(ctx context.Context) error {
 	return r.r.Close(ctx)
 }
+
+//------------------------------------------------------------------------------
+
+type metricsKeyedRateLimit struct {
+	*metricsRateLimit
+	k Keyed
+}
+
+func (r *metricsKeyedRateLimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	return observeAccess(r.mChecked, r.mLimited, r.mErr, func() (time.Duration, error) {
+		return r.k.AccessKey(ctx, key)
+	})
+}
+
+type metricsKeyedMessageAwareRateLimit struct {
+	*metricsMessageAwareRateLimit
+	k Keyed
+}
+
+func (r *metricsKeyedMessageAwareRateLimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	return observeAccess(r.mChecked, r.mLimited, r.mErr, func() (time.Duration, error) {
+		return r.k.AccessKey(ctx, key)
+	})
+}
+
+func observeAccess(mChecked, mLimited, mErr metrics.StatCounter, fn func() (time.Duration, error)) (time.Duration, error) {
+	mChecked.Incr(1)
+	tout, err := fn()
+	if err != nil {
+		mErr.Incr(1)
+	} else if tout > 0 {
+		mLimited.Incr(1)
+	}
+	return tout, err
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/warpstreamlabs/bento/internal/bundle"
 	"github.com/warpstreamlabs/bento/internal/component/cache"
 	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/component/lock"
 	"github.com/warpstreamlabs/bento/internal/component/output"
 	"github.com/warpstreamlabs/bento/internal/component/processor"
 	"github.com/warpstreamlabs/bento/internal/component/ratelimit"
@@ -34,6 +35,7 @@ type resourceSourceInfo struct {
 	outputs    map[string]string
 	caches     map[string]string
 	rateLimits map[string]string
+	locks      map[string]string
 }
 
 func newResourceSourceInfo() *resourceSourceInfo {
@@ -43,6 +45,7 @@ func newResourceSourceInfo() *resourceSourceInfo {
 		outputs:    map[string]string{},
 		caches:     map[string]string{},
 		rateLimits: map[string]string{},
+		locks:      map[string]string{},
 	}
 }
 
@@ -62,6 +65,9 @@ func (r *resourceSourceInfo) populateFrom(path string, info *resourceFileInfo) {
 	for k := range info.rateLimits {
 		r.rateLimits[k] = path
 	}
+	for k := range info.locks {
+		r.locks[k] = path
+	}
 }
 
 func (r *resourceSourceInfo) removeOwnedCache(ctx context.Context, label, path string, mgr bundle.NewManagement) {
@@ -114,6 +120,16 @@ func (r *resourceSourceInfo) removeOwnedRateLimit(ctx context.Context, label, pa
 	}
 }
 
+func (r *resourceSourceInfo) removeOwnedLock(ctx context.Context, label, path string, mgr bundle.NewManagement) {
+	if r.locks[label] == path {
+		if err := mgr.RemoveLock(ctx, label); err != nil {
+			mgr.Logger().Error("Failed to remove deleted resource %v: %v", label, err)
+		} else {
+			delete(r.locks, label)
+		}
+	}
+}
+
 // Keeps track of which resources came from a file in its last read, if configs
 // are changed, added or missing we need to reflect that.
 type resourceFileInfo struct {
@@ -122,6 +138,7 @@ type resourceFileInfo struct {
 	outputs    map[string]*output.Config
 	caches     map[string]*cache.Config
 	rateLimits map[string]*ratelimit.Config
+	locks      map[string]*lock.Config
 }
 
 func resInfoEmpty() resourceFileInfo {
@@ -131,6 +148,7 @@ func resInfoEmpty() resourceFileInfo {
 		outputs:    map[string]*output.Config{},
 		caches:     map[string]*cache.Config{},
 		rateLimits: map[string]*ratelimit.Config{},
+		locks:      map[string]*lock.Config{},
 	}
 }
 
@@ -158,6 +176,10 @@ func resInfoFromConfig(conf *manager.ResourceConfig) resourceFileInfo {
 		c := c
 		resInfo.rateLimits[c.Label] = &c
 	}
+	for _, c := range conf.ResourceLocks {
+		c := c
+		resInfo.locks[c.Label] = &c
+	}
 
 	return resInfo
 }
@@ -330,6 +352,22 @@ func (r *Reader) applyResourceChanges(path string, mgr bundle.NewManagement, cur
 		r.resourceSources.removeOwnedRateLimit(ctx, k, path, mgr)
 	}
 
+	unaccounted = map[string]struct{}{}
+	for k := range prevInfo.locks {
+		unaccounted[k] = struct{}{}
+	}
+	for k, v := range currentInfo.locks {
+		delete(unaccounted, k)
+		if err := mgr.StoreLock(ctx, k, *v); err != nil {
+			mgr.Logger().Error("Failed to update resource %v: %v", k, err)
+			return fmt.Errorf("resource %v: %w", k, err)
+		}
+		mgr.Logger().Info("Updated resource %v config from file.", k)
+	}
+	for k := range unaccounted {
+		r.resourceSources.removeOwnedLock(ctx, k, path, mgr)
+	}
+
 	unaccounted = map[string]struct{}{}
 	for k := range prevInfo.caches {
 		unaccounted[k] = struct{}{}
@@ -15,6 +15,7 @@ type componentOptions struct {
 
 	canLabel      bool
 	canPreProcess bool
+	canLeaderOnly bool
 }
 
 func doComponents(specs []docs.ComponentSpec, opts *componentOptions) ([]ast.Decl, error) {
@@ -73,5 +74,16 @@ func doComponents(specs []docs.ComponentSpec, opts *componentOptions) ([]ast.Dec
 		})
 	}
 
+	if opts.canLeaderOnly {
+		field, err := doFieldSpec(docs.InputLeaderOnlyFieldSpec("leader_only"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate cue type for leader_only: %w", err)
+		}
+		decls = append(decls, &ast.Field{
+			Label: opts.disjunctionIdent,
+			Value: ast.NewStruct(field.Label, token.OPTION, field.Value),
+		})
+	}
+
 	return decls, nil
 }
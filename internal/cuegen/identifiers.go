@@ -17,6 +17,9 @@ var (
 	identRateLimitDisjunction = ast.NewIdent("#RateLimit")
 	identRateLimitCollection  = ast.NewIdent("#AllRateLimits")
 
+	identLockDisjunction = ast.NewIdent("#Lock")
+	identLockCollection  = ast.NewIdent("#AllLocks")
+
 	identBufferDisjunction = ast.NewIdent("#Buffer")
 	identBufferCollection  = ast.NewIdent("#AllBuffers")
 
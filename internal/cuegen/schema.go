@@ -32,6 +32,7 @@ func GenerateSchema(sch schema.Full) ([]byte, error) {
 			disjunctionIdent: identInputDisjunction,
 			canLabel:         true,
 			canPreProcess:    true,
+			canLeaderOnly:    true,
 		},
 	)
 	if err != nil {
@@ -92,6 +93,19 @@ func GenerateSchema(sch schema.Full) ([]byte, error) {
 	}
 	root.Decls = append(root.Decls, rateLimitDecls...)
 
+	lockDecls, err := doComponents(
+		sch.Locks,
+		&componentOptions{
+			collectionIdent:  identLockCollection,
+			disjunctionIdent: identLockDisjunction,
+			canLabel:         true,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	root.Decls = append(root.Decls, lockDecls...)
+
 	bufferDecls, err := doComponents(
 		sch.Buffers,
 		&componentOptions{
@@ -1,6 +1,8 @@
 package docs
 
 import (
+	"errors"
+
 	"github.com/warpstreamlabs/bento/public/bloblang"
 )
 
@@ -16,6 +18,9 @@ func LintBloblangMapping(ctx LintContext, line, col int, v any) []Lint {
 	}
 	_, err := ctx.conf.BloblangEnv.Parse(str)
 	if err == nil {
+		if ctx.conf.BloblangTypeCheck {
+			return lintBloblangMappingTypes(ctx, line, col, str)
+		}
 		return nil
 	}
 	if mErr, ok := err.(*bloblang.ParseError); ok {
@@ -26,6 +31,20 @@ func LintBloblangMapping(ctx LintContext, line, col int, v any) []Lint {
 	return []Lint{NewLintError(line, LintBadBloblang, err)}
 }
 
+func lintBloblangMappingTypes(ctx LintContext, line, col int, mapping string) []Lint {
+	tErrs, err := ctx.conf.BloblangEnv.CheckTypes(mapping, ctx.conf.BloblangInputSchema)
+	if err != nil {
+		return nil
+	}
+	var lints []Lint
+	for _, tErr := range tErrs {
+		lint := NewLintError(line+tErr.Line-1, LintBadBloblangType, errors.New(tErr.Message))
+		lint.Column = col + tErr.Column
+		lints = append(lints, lint)
+	}
+	return lints
+}
+
 // LintBloblangField is function for linting a config field expected to be an
 // interpolation string.
 func LintBloblangField(ctx LintContext, line, col int, v any) []Lint {
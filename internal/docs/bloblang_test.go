@@ -7,6 +7,7 @@ import (
 
 	"github.com/warpstreamlabs/bento/internal/bundle"
 	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/public/bloblang"
 )
 
 func TestLintBloblangMapping(t *testing.T) {
@@ -53,6 +54,34 @@ func TestLintBloblangMapping(t *testing.T) {
 	}
 }
 
+func TestLintBloblangMappingTypes(t *testing.T) {
+	schema, err := bloblang.NewTypeSchemaFromJSONSchema([]byte(`{
+  "type": "object",
+  "properties": { "id": { "type": "integer" } },
+  "required": [ "id" ],
+  "additionalProperties": false
+}`))
+	require.NoError(t, err)
+
+	mapping := `root.a = "foo"
+root.b = this.id.uppercase()`
+
+	lConf := docs.NewLintConfig(bundle.GlobalEnvironment)
+	require.Empty(t, docs.LintBloblangMapping(docs.NewLintContext(lConf), 2, 4, mapping))
+
+	lConf.BloblangTypeCheck = true
+	lConf.BloblangInputSchema = schema
+	require.EqualValues(t, []docs.Lint{
+		{
+			Line:   3,
+			Column: 22,
+			Level:  docs.LintError,
+			Type:   docs.LintBadBloblangType,
+			What:   "method uppercase: expected string or bytes value, got number from field `this.id`",
+		},
+	}, docs.LintBloblangMapping(docs.NewLintContext(lConf), 2, 4, mapping))
+}
+
 func TestLintBloblangField(t *testing.T) {
 	type Test struct {
 		mapping   string
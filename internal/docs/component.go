@@ -48,6 +48,7 @@ var (
 	TypeBuffer    Type = "buffer"
 	TypeCache     Type = "cache"
 	TypeInput     Type = "input"
+	TypeLock      Type = "lock"
 	TypeMetrics   Type = "metrics"
 	TypeOutput    Type = "output"
 	TypeProcessor Type = "processor"
@@ -62,6 +63,7 @@ func Types() []Type {
 		TypeBuffer,
 		TypeCache,
 		TypeInput,
+		TypeLock,
 		TypeMetrics,
 		TypeOutput,
 		TypeProcessor,
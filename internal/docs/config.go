@@ -74,6 +74,9 @@ func ReservedFieldsByType(t Type) map[string]FieldSpec {
 			return "", false
 		})
 	}
+	if t == TypeInput {
+		m["leader_only"] = InputLeaderOnlyFieldSpec("leader_only")
+	}
 	if t == TypeMetrics {
 		m["mapping"] = MetricsMappingFieldSpec("mapping")
 	}
@@ -82,6 +85,7 @@ func ReservedFieldsByType(t Type) map[string]FieldSpec {
 		TypeProcessor: {},
 		TypeOutput:    {},
 		TypeCache:     {},
+		TypeLock:      {},
 		TypeRateLimit: {},
 	}[t]; isLabelType {
 		m["label"] = labelField
@@ -26,6 +26,7 @@ var (
 	FieldTypeInput     FieldType = "input"
 	FieldTypeBuffer    FieldType = "buffer"
 	FieldTypeCache     FieldType = "cache"
+	FieldTypeLock      FieldType = "lock"
 	FieldTypeProcessor FieldType = "processor"
 	FieldTypeRateLimit FieldType = "rate_limit"
 	FieldTypeOutput    FieldType = "output"
@@ -43,6 +44,8 @@ func (t FieldType) IsCoreComponent() (Type, bool) {
 		return TypeBuffer, true
 	case FieldTypeCache:
 		return TypeCache, true
+	case FieldTypeLock:
+		return TypeLock, true
 	case FieldTypeProcessor:
 		return TypeProcessor, true
 	case FieldTypeRateLimit:
@@ -545,6 +548,11 @@ func FieldCache(name, description string, examples ...any) FieldSpec {
 	return newField(name, description, examples...).HasType(FieldTypeCache)
 }
 
+// FieldLock returns a field spec for a lock typed field.
+func FieldLock(name, description string, examples ...any) FieldSpec {
+	return newField(name, description, examples...).HasType(FieldTypeLock)
+}
+
 // FieldRateLimit returns a field spec for a rate limit typed field.
 func FieldRateLimit(name, description string, examples ...any) FieldSpec {
 	return newField(name, description, examples...).HasType(FieldTypeRateLimit)
@@ -683,6 +691,13 @@ type LintConfig struct {
 
 	// Require labels for components.
 	RequireLabels bool
+
+	// Statically check the types of Bloblang mappings.
+	BloblangTypeCheck bool
+
+	// An optional schema describing the input documents of Bloblang mappings,
+	// used when type checking is enabled.
+	BloblangInputSchema *bloblang.TypeSchema
 }
 
 // NewLintConfig creates a default linting config.
@@ -783,6 +798,10 @@ const (
 
 	// LintDeprecated means a field is deprecated and should not be used.
 	LintDeprecated LintType = iota
+
+	// LintBadBloblangType means the field contains a Bloblang mapping with a
+	// type error detected by static analysis.
+	LintBadBloblangType LintType = iota
 )
 
 // Lint describes a single linting issue found with a Bento config.
@@ -65,6 +65,8 @@ func (f FieldSpec) JSONSchema() any {
 			spec["$ref"] = "#/definitions/buffer"
 		case FieldTypeCache:
 			spec["$ref"] = "#/definitions/cache"
+		case FieldTypeLock:
+			spec["$ref"] = "#/definitions/lock"
 		case FieldTypeProcessor:
 			spec["$ref"] = "#/definitions/processor"
 		case FieldTypeRateLimit:
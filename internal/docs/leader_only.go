@@ -0,0 +1,14 @@
+package docs
+
+// InputLeaderOnlyFieldSpec is a field spec that describes the leader election
+// settings of an input, where the input only connects whilst a lock resource is
+// held by the running instance.
+func InputLeaderOnlyFieldSpec(name string) FieldSpec {
+	return FieldObject(
+		name, "An optional leader election that restricts the input to a single instance at a time. The input only connects whilst this instance holds a named lease of a [lock resource](/docs/components/locks/about), and is cleanly disconnected when the lease is lost.",
+	).WithChildren(
+		FieldString("lock", "The label of a [lock resource](/docs/components/locks/about) to acquire the lease from."),
+		FieldString("name", "The name of the lease to acquire, instances competing for the same input must share it. When empty the label of the input is used instead.").HasDefault(""),
+		FieldString("lease", "The period for which a lease is held before it must be renewed. Renewals are attempted three times within each period, and a lease that cannot be renewed before it expires is considered lost.", "10s", "1m").HasDefault("10s"),
+	).Optional().Advanced().AtVersion("1.14.0")
+}
@@ -18,6 +18,7 @@ type MappedDocsProvider struct {
 	bufferMap     map[string]ComponentSpec
 	cacheMap      map[string]ComponentSpec
 	inputMap      map[string]ComponentSpec
+	lockMap       map[string]ComponentSpec
 	metricsMap    map[string]ComponentSpec
 	outputMap     map[string]ComponentSpec
 	processorMap  map[string]ComponentSpec
@@ -33,6 +34,7 @@ func NewMappedDocsProvider() *MappedDocsProvider {
 		bufferMap:    map[string]ComponentSpec{},
 		cacheMap:     map[string]ComponentSpec{},
 		inputMap:     map[string]ComponentSpec{},
+		lockMap:      map[string]ComponentSpec{},
 		metricsMap:   map[string]ComponentSpec{},
 		outputMap:    map[string]ComponentSpec{},
 		processorMap: map[string]ComponentSpec{},
@@ -49,6 +51,7 @@ func (m *MappedDocsProvider) Clone() *MappedDocsProvider {
 		bufferMap:    map[string]ComponentSpec{},
 		cacheMap:     map[string]ComponentSpec{},
 		inputMap:     map[string]ComponentSpec{},
+		lockMap:      map[string]ComponentSpec{},
 		metricsMap:   map[string]ComponentSpec{},
 		outputMap:    map[string]ComponentSpec{},
 		processorMap: map[string]ComponentSpec{},
@@ -66,6 +69,9 @@ func (m *MappedDocsProvider) Clone() *MappedDocsProvider {
 	for k, v := range m.inputMap {
 		newM.inputMap[k] = v
 	}
+	for k, v := range m.lockMap {
+		newM.lockMap[k] = v
+	}
 	for k, v := range m.metricsMap {
 		newM.metricsMap[k] = v
 	}
@@ -99,6 +105,8 @@ func (m *MappedDocsProvider) RegisterDocs(spec ComponentSpec) {
 		m.cacheMap[spec.Name] = spec
 	case TypeInput:
 		m.inputMap[spec.Name] = spec
+	case TypeLock:
+		m.lockMap[spec.Name] = spec
 	case TypeMetrics:
 		m.metricsMap[spec.Name] = spec
 	case TypeOutput:
@@ -129,6 +137,8 @@ func (m *MappedDocsProvider) GetDocs(name string, ctype Type) (ComponentSpec, bo
 		spec, ok = m.cacheMap[name]
 	case TypeInput:
 		spec, ok = m.inputMap[name]
+	case TypeLock:
+		spec, ok = m.lockMap[name]
 	case TypeMetrics:
 		spec, ok = m.metricsMap[name]
 	case TypeOutput:
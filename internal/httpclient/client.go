@@ -10,6 +10,7 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -199,6 +200,63 @@ func (h *Client) waitForAccess(ctx context.Context, sendMsg service.MessageBatch
 	}
 }
 
+// reportFeedback informs the rate limit, if any, of the outcome of a request,
+// where responses with a status code that we back off on are considered
+// throttled, as are any unsuccessful responses with a Retry-After header.
+func (h *Client) reportFeedback(ctx context.Context, res *http.Response, resolved bool, retryStrat retryStrategy) {
+	if h.rateLimit == "" {
+		return
+	}
+
+	var retryAfter time.Duration
+	throttled := !resolved && retryStrat == retryBackoff
+	if !resolved {
+		var ok bool
+		if retryAfter, ok = parseRetryAfter(res.Header.Get("Retry-After"), time.Now()); ok {
+			throttled = true
+		}
+	}
+	if !resolved && !throttled {
+		return
+	}
+
+	if rerr := h.mgr.AccessRateLimit(ctx, h.rateLimit, func(rl service.RateLimit) {
+		arl, ok := rl.(service.AdaptiveRateLimit)
+		if !ok {
+			return
+		}
+		if throttled {
+			arl.ReportThrottled(ctx, retryAfter)
+		} else {
+			arl.ReportSuccess(ctx)
+		}
+	}); rerr != nil {
+		h.log.Errorf("Rate limit error: %v\n", rerr)
+	}
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either a
+// number of seconds or a date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	if d := t.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
 // ResponseToBatch attempts to parse an HTTP response into a 2D slice of bytes.
 func (h *Client) ResponseToBatch(res *http.Response) (service.MessageBatch, error) {
 	var resMsg service.MessageBatch
@@ -353,7 +411,9 @@ func (h *Client) SendToResponse(ctx context.Context, sendMsg service.MessageBatc
 	startedAt := time.Now()
 	if res, err = h.client.Do(req.WithContext(ctx)); err == nil {
 		h.incrCode(res.StatusCode)
-		if resolved, retryStrat := h.checkStatus(res.StatusCode); !resolved {
+		resolved, retryStrat := h.checkStatus(res.StatusCode)
+		h.reportFeedback(ctx, res, resolved, retryStrat)
+		if !resolved {
 			rateLimited = retryStrat == retryBackoff
 			if retryStrat == noRetry {
 				numRetries = 0
@@ -398,7 +458,9 @@ func (h *Client) SendToResponse(ctx context.Context, sendMsg service.MessageBatc
 		startedAt = time.Now()
 		if res, err = h.client.Do(req.WithContext(ctx)); err == nil {
 			h.incrCode(res.StatusCode)
-			if resolved, retryStrat := h.checkStatus(res.StatusCode); !resolved {
+			resolved, retryStrat := h.checkStatus(res.StatusCode)
+			h.reportFeedback(ctx, res, resolved, retryStrat)
+			if !resolved {
 				rateLimited = retryStrat == retryBackoff
 				if retryStrat == noRetry {
 					j = 0
@@ -873,3 +873,25 @@ transport:
 	})
 	require.ErrorContains(t, err, "proxyconnect tcp: net/http: TLS handshake timeout")
 }
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, test := range []struct {
+		value string
+		exp   time.Duration
+		ok    bool
+	}{
+		{value: "", ok: false},
+		{value: "nope", ok: false},
+		{value: "-1", ok: false},
+		{value: "0", exp: 0, ok: true},
+		{value: "120", exp: time.Minute * 2, ok: true},
+		{value: "Wed, 01 Jan 2020 00:00:30 GMT", exp: time.Second * 30, ok: true},
+		{value: "Tue, 31 Dec 2019 23:59:00 GMT", exp: 0, ok: true},
+	} {
+		d, ok := parseRetryAfter(test.value, now)
+		assert.Equal(t, test.ok, ok, test.value)
+		assert.Equal(t, test.exp, d, test.value)
+	}
+}
@@ -69,7 +69,7 @@ func ConfigField(defaultVerb string, forOutput bool, extraChildren ...*service.C
 			Description(extractHeadersDesc).
 			Advanced(),
 		service.NewStringField(hcFieldRateLimit).
-			Description("An optional [rate limit](/docs/components/rate_limits/about) to throttle requests by.").
+			Description("An optional [rate limit](/docs/components/rate_limits/about) to throttle requests by. Rate limits that adapt to feedback, such as the [`adaptive` rate limit](/docs/components/rate_limits/adaptive), are informed of requests that succeed and requests that are throttled, which are those with a status code listed in `backoff_on` or any unsuccessful response with a `Retry-After` header.").
 			Optional(),
 		service.NewDurationField(hcFieldTimeout).
 			Description("A static timeout to apply to requests.").
@@ -31,6 +31,8 @@ type RequestCreator struct {
 	explicitBody       *service.InterpolatedString
 	explicitMultiparts []MultipartExpressions
 
+	mutator func(req *http.Request) error
+
 	fs        fs.FS
 	reqSigner func(f fs.FS, req *http.Request) error
 
@@ -90,6 +92,16 @@ func WithExplicitMultipart(m []MultipartExpressions) RequestOpt {
 	}
 }
 
+// WithRequestMutator modifies the request creator to apply a function to each
+// request after it has been created, and before it is signed. This allows
+// components to compute parts of a request, such as the URL, independently of
+// the reference messages.
+func WithRequestMutator(fn func(req *http.Request) error) RequestOpt {
+	return func(r *RequestCreator) {
+		r.mutator = fn
+	}
+}
+
 func (r *RequestCreator) bodyFromExplicit(refBatch service.MessageBatch) (body io.Reader, overrideContentType string, err error) {
 	if _, exists := r.headers["Content-Type"]; !exists {
 		overrideContentType = "application/octet-stream"
@@ -254,6 +266,12 @@ func (r *RequestCreator) Create(refBatch service.MessageBatch) (req *http.Reques
 		req.Header.Add("Content-Type", overrideContentType)
 	}
 
+	if r.mutator != nil {
+		if err = r.mutator(req); err != nil {
+			return
+		}
+	}
+
 	err = r.reqSigner(r.fs, req)
 	return
 }
@@ -1,6 +1,7 @@
 package httpclient
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/warpstreamlabs/bento/public/service"
@@ -39,3 +40,29 @@ metadata:
 	assert.Equal(t, []string{"barvalue"}, req.Header.Values("more_bar"))
 	assert.Equal(t, []string(nil), req.Header.Values("ignore_baz"))
 }
+
+func TestRequestMutator(t *testing.T) {
+	spec := service.NewConfigSpec().Field(ConfigField("GET", false))
+	parsed, err := spec.ParseYAML(`
+url: http://example.com/foo
+headers:
+  "X-Foo": "foo"
+`, nil)
+	require.NoError(t, err)
+
+	oldConf, err := ConfigFromParsed(parsed)
+	require.NoError(t, err)
+
+	reqCreator, err := RequestCreatorFromOldConfig(oldConf, service.MockResources(), WithRequestMutator(func(req *http.Request) error {
+		req.URL.Path = "/bar"
+		req.Header.Set("X-Foo", "bar")
+		return nil
+	}))
+	require.NoError(t, err)
+
+	req, err := reqCreator.Create(nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://example.com/bar", req.URL.String())
+	assert.Equal(t, []string{"bar"}, req.Header.Values("X-Foo"))
+}
@@ -0,0 +1,210 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/linkedin/goavro/v2"
+
+	"github.com/warpstreamlabs/bento/public/bloblang"
+)
+
+// codecCache holds codecs compiled from schemas, which allows methods to be
+// reconstructed with dynamic arguments without compiling the schema again. The
+// number of entries is bounded as schemas may be dynamic.
+type codecCache struct {
+	mut    sync.RWMutex
+	codecs map[string]*goavro.Codec
+}
+
+const codecCacheMaxEntries = 64
+
+var globalCodecCache = &codecCache{
+	codecs: map[string]*goavro.Codec{},
+}
+
+func (c *codecCache) Get(schema string) (*goavro.Codec, error) {
+	c.mut.RLock()
+	codec, exists := c.codecs[schema]
+	c.mut.RUnlock()
+	if exists {
+		return codec, nil
+	}
+
+	codec, err := goavro.NewCodec(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	c.mut.Lock()
+	if _, exists := c.codecs[schema]; !exists && len(c.codecs) >= codecCacheMaxEntries {
+		for k := range c.codecs {
+			delete(c.codecs, k)
+			break
+		}
+	}
+	c.codecs[schema] = codec
+	c.mut.Unlock()
+	return codec, nil
+}
+
+func codecFromArgs(args *bloblang.ParsedParams) (codec *goavro.Codec, encoding string, err error) {
+	schema, err := args.GetString("schema")
+	if err != nil {
+		return nil, "", err
+	}
+	if encoding, err = args.GetString("encoding"); err != nil {
+		return nil, "", err
+	}
+	switch encoding {
+	case "textual", "binary", "single":
+	default:
+		return nil, "", fmt.Errorf("encoding '%v' not recognised", encoding)
+	}
+	if codec, err = globalCodecCache.Get(schema); err != nil {
+		return nil, "", err
+	}
+	return codec, encoding, nil
+}
+
+// nativeFromValue converts a Bloblang value into the native form expected by a
+// codec. Values are converted via their JSON representation as Bloblang values
+// may contain types such as json.Number that are not understood by goavro.
+func nativeFromValue(codec *goavro.Codec, v any) (any, error) {
+	jBytes, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	native, _, err := codec.NativeFromTextual(jBytes)
+	return native, err
+}
+
+// valueFromNative converts a native value produced by a codec into a Bloblang
+// value. Values are converted via their JSON representation as natives contain
+// types such as int32 and float32 that are not supported by Bloblang.
+func valueFromNative(codec *goavro.Codec, native any) (any, error) {
+	jBytes, err := codec.TextualFromNative(nil, native)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(jBytes))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return sanitizeNumbers(v), nil
+}
+
+func sanitizeNumbers(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, e := range t {
+			t[k] = sanitizeNumbers(e)
+		}
+	case []any:
+		for i, e := range t {
+			t[i] = sanitizeNumbers(e)
+		}
+	case json.Number:
+		return bloblang.ValueSanitized(t)
+	}
+	return v
+}
+
+func init() {
+	parseSpec := bloblang.NewPluginSpec().
+		Category("Parsing").
+		Description("Parses an [Avro](https://avro.apache.org/) document into a structured document using a schema. Schemas are compiled once and then cached.").
+		Param(bloblang.NewStringParam("schema").Description("The Avro schema of the document.")).
+		Param(bloblang.NewStringParam("encoding").Description("The encoding of the document, one of `binary`, `textual` or `single`.").Default("binary")).
+		Example("", `root = this.payload.decode("hex").parse_avro("""{"type":"record","name":"foo","fields":[{"name":"name","type":"string"}]}""")`,
+			[2]string{
+				`{"payload":"06666f6f"}`,
+				`{"name":"foo"}`,
+			})
+
+	if err := bloblang.RegisterMethodV2(
+		"parse_avro", parseSpec,
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			codec, encoding, err := codecFromArgs(args)
+			if err != nil {
+				return nil, err
+			}
+
+			var decode func([]byte) (any, []byte, error)
+			switch encoding {
+			case "textual":
+				decode = codec.NativeFromTextual
+			case "binary":
+				decode = codec.NativeFromBinary
+			case "single":
+				decode = codec.NativeFromSingle
+			}
+
+			return func(v any) (any, error) {
+				b, err := bloblang.ValueAsBytes(v)
+				if err != nil {
+					return nil, err
+				}
+				native, _, err := decode(b)
+				if err == nil {
+					native, err = valueFromNative(codec, native)
+				}
+				if err != nil {
+					return nil, fmt.Errorf("failed to convert Avro document to JSON: %v", err)
+				}
+				return native, nil
+			}, nil
+		},
+	); err != nil {
+		panic(err)
+	}
+
+	formatSpec := bloblang.NewPluginSpec().
+		Category("Parsing").
+		Description("Formats a structured document as an [Avro](https://avro.apache.org/) document in bytes format using a schema. Schemas are compiled once and then cached.").
+		Param(bloblang.NewStringParam("schema").Description("The Avro schema of the document.")).
+		Param(bloblang.NewStringParam("encoding").Description("The encoding of the document, one of `binary`, `textual` or `single`.").Default("binary")).
+		Example("", `root.payload = this.format_avro("""{"type":"record","name":"foo","fields":[{"name":"name","type":"string"}]}""").encode("hex")`,
+			[2]string{
+				`{"name":"foo"}`,
+				`{"payload":"06666f6f"}`,
+			})
+
+	if err := bloblang.RegisterMethodV2(
+		"format_avro", formatSpec,
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			codec, encoding, err := codecFromArgs(args)
+			if err != nil {
+				return nil, err
+			}
+
+			var encode func([]byte, any) ([]byte, error)
+			switch encoding {
+			case "textual":
+				encode = codec.TextualFromNative
+			case "binary":
+				encode = codec.BinaryFromNative
+			case "single":
+				encode = codec.SingleFromNative
+			}
+
+			return func(v any) (any, error) {
+				native, err := nativeFromValue(codec, v)
+				if err == nil {
+					v, err = encode(nil, native)
+				}
+				if err != nil {
+					return nil, fmt.Errorf("failed to convert JSON to Avro schema: %v", err)
+				}
+				return v, nil
+			}, nil
+		},
+	); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,130 @@
+package avro
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/public/bloblang"
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+func TestAvroBloblang(t *testing.T) {
+	schema := `{
+  "type": "record",
+  "name": "person",
+  "fields": [
+    { "name": "name", "type": "string" },
+    { "name": "age", "type": "int" }
+  ]
+}`
+
+	tests := []struct {
+		name     string
+		mapping  string
+		input    any
+		expected any
+	}{
+		{
+			name:     "format binary",
+			mapping:  `root = this.format_avro(schema: $schema).encode("hex")`,
+			input:    map[string]any{"name": "foo", "age": 10},
+			expected: "06666f6f14",
+		},
+		{
+			name:     "parse binary",
+			mapping:  `root = this.decode("hex").parse_avro(schema: $schema)`,
+			input:    "06666f6f14",
+			expected: map[string]any{"name": "foo", "age": int64(10)},
+		},
+		{
+			name:     "round trip textual",
+			mapping:  `root = this.format_avro(schema: $schema, encoding: "textual").parse_avro(schema: $schema, encoding: "textual")`,
+			input:    map[string]any{"name": "foo", "age": 10},
+			expected: map[string]any{"name": "foo", "age": int64(10)},
+		},
+		{
+			name:     "embedded field",
+			mapping:  `root = this.without("payload").merge({"person": this.payload.decode("hex").parse_avro(schema: $schema, encoding: "binary")})`,
+			input:    map[string]any{"id": "a", "payload": "06666f6f14"},
+			expected: map[string]any{"id": "a", "person": map[string]any{"name": "foo", "age": int64(10)}},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(`let schema = """` + schema + `"""
+` + test.mapping)
+			require.NoError(t, err)
+
+			res, err := exec.Query(test.input)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+func TestAvroBloblangJSONRoundTrip(t *testing.T) {
+	schema := `{
+  "type": "record",
+  "name": "reading",
+  "fields": [
+    { "name": "id", "type": "long" },
+    { "name": "value", "type": "float" },
+    { "name": "tag", "type": ["null", "string"] }
+  ]
+}`
+
+	for _, encoding := range []string{"binary", "textual", "single"} {
+		encoding := encoding
+		t.Run(encoding, func(t *testing.T) {
+			exec, err := bloblang.Parse(`let schema = """` + schema + `"""
+let parsed = this.format_avro(schema: $schema, encoding: "` + encoding + `").parse_avro(schema: $schema, encoding: "` + encoding + `")
+root.id = $parsed.id + 1
+root.value = $parsed.value * 2
+root.tag = $parsed.tag`)
+			require.NoError(t, err)
+
+			msg := service.NewMessage([]byte(`{"id":10,"value":1.5,"tag":{"string":"foo"}}`))
+			res, err := msg.BloblangQuery(exec)
+			require.NoError(t, err)
+
+			resBytes, err := res.AsBytes()
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"id":11,"value":3,"tag":{"string":"foo"}}`, string(resBytes))
+		})
+	}
+}
+
+func TestAvroBloblangCodecCache(t *testing.T) {
+	cache := &codecCache{codecs: map[string]*goavro.Codec{}}
+	for i := 0; i < codecCacheMaxEntries*2; i++ {
+		_, err := cache.Get(fmt.Sprintf(`{"type":"record","name":"r%v","fields":[]}`, i))
+		require.NoError(t, err)
+	}
+	assert.Len(t, cache.codecs, codecCacheMaxEntries)
+
+	_, err := cache.Get("not a schema")
+	require.Error(t, err)
+	assert.Len(t, cache.codecs, codecCacheMaxEntries)
+}
+
+func TestAvroBloblangErrors(t *testing.T) {
+	_, err := bloblang.Parse(`root = this.parse_avro("not a schema")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse schema")
+
+	_, err = bloblang.Parse(`root = this.parse_avro(schema: "\"string\"", encoding: "nope")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "encoding 'nope' not recognised")
+
+	exec, err := bloblang.Parse(`root = this.format_avro("\"int\"")`)
+	require.NoError(t, err)
+	_, err = exec.Query("not a number")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to convert JSON to Avro schema")
+}
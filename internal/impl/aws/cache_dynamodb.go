@@ -131,7 +131,9 @@ type dynamoDBAPIV2 interface {
 	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
 	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
 	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error)
 	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
 }
 
 type dynamodbCache struct {
@@ -352,6 +354,161 @@ func (d *dynamodbCache) add(ctx context.Context, key string, value []byte, ttl *
 	return nil
 }
 
+// The maximum number of keys that can be requested with a single BatchGetItem
+// request.
+const dynamodbBatchGetMax = 100
+
+func (d *dynamodbCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	boff := d.boffPool.Get().(backoff.BackOff)
+	defer func() {
+		boff.Reset()
+		d.boffPool.Put(boff)
+	}()
+
+	// Requests containing duplicate keys are rejected by DynamoDB, and as the
+	// results are keyed a single lookup serves all duplicates of a key.
+	seen := make(map[string]struct{}, len(keys))
+	uniqueKeys := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if _, exists := seen[k]; !exists {
+			seen[k] = struct{}{}
+			uniqueKeys = append(uniqueKeys, k)
+		}
+	}
+	keys = uniqueKeys
+
+	values := make(map[string][]byte, len(keys))
+	for len(keys) > 0 {
+		n := min(len(keys), dynamodbBatchGetMax)
+		reqKeys := make([]map[string]types.AttributeValue, 0, n)
+		for _, k := range keys[:n] {
+			reqKeys = append(reqKeys, map[string]types.AttributeValue{
+				d.hashKey: &types.AttributeValueMemberS{Value: k},
+			})
+		}
+		keys = keys[n:]
+
+		for len(reqKeys) > 0 {
+			wait := boff.NextBackOff()
+			res, err := d.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+				RequestItems: map[string]types.KeysAndAttributes{
+					d.table: {
+						Keys:           reqKeys,
+						ConsistentRead: aws.Bool(d.consistentRead),
+					},
+				},
+			})
+			if err == nil {
+				for _, item := range res.Responses[d.table] {
+					k, kOk := item[d.hashKey].(*types.AttributeValueMemberS)
+					v, vOk := item[d.dataKey].(*types.AttributeValueMemberB)
+					if kOk && vOk {
+						values[k.Value] = v.Value
+					}
+				}
+				reqKeys = res.UnprocessedKeys[d.table].Keys
+				if len(reqKeys) == 0 {
+					break
+				}
+				err = fmt.Errorf("failed to get %v items", len(reqKeys))
+			}
+			if wait == backoff.Stop {
+				return nil, err
+			}
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return nil, err
+			}
+		}
+	}
+	return values, nil
+}
+
+func (d *dynamodbCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	input := d.putItemInput(key, value, ttl)
+
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.Name(d.dataKey).Equal(expression.Value(old))).
+		Build()
+	if err != nil {
+		return err
+	}
+	input.ExpressionAttributeNames = expr.Names()
+	input.ExpressionAttributeValues = expr.Values()
+	input.ConditionExpression = expr.Condition()
+
+	if _, err = d.client.PutItem(ctx, input); err != nil {
+		var derr *types.ConditionalCheckFailedException
+		if !errors.As(err, &derr) {
+			return err
+		}
+		// The condition also fails when the key does not exist, which is
+		// determined with a read in order to return the appropriate error.
+		if _, err := d.Get(ctx, key); err != nil {
+			return err
+		}
+		return service.ErrCompareAndSwapFailed
+	}
+	return nil
+}
+
+// Scan reads the entire table a page at a time, and so can be slow and consume
+// significant read capacity for large tables.
+func (d *dynamodbCache) Scan(ctx context.Context, prefix string) ([]string, error) {
+	input := &dynamodb.ScanInput{
+		TableName:      &d.table,
+		ConsistentRead: aws.Bool(d.consistentRead),
+	}
+
+	builder := expression.NewBuilder().WithProjection(expression.NamesList(expression.Name(d.hashKey)))
+	if prefix != "" {
+		builder = builder.WithFilter(expression.Name(d.hashKey).BeginsWith(prefix))
+	}
+	expr, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+	input.ExpressionAttributeNames = expr.Names()
+	input.ExpressionAttributeValues = expr.Values()
+	input.ProjectionExpression = expr.Projection()
+	input.FilterExpression = expr.Filter()
+
+	boff := d.boffPool.Get().(backoff.BackOff)
+	defer func() {
+		boff.Reset()
+		d.boffPool.Put(boff)
+	}()
+
+	var keys []string
+	for {
+		res, err := d.client.Scan(ctx, input)
+		if err != nil {
+			wait := boff.NextBackOff()
+			if wait == backoff.Stop {
+				return nil, err
+			}
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return nil, err
+			}
+			continue
+		}
+		boff.Reset()
+
+		for _, item := range res.Items {
+			if k, ok := item[d.hashKey].(*types.AttributeValueMemberS); ok {
+				keys = append(keys, k.Value)
+			}
+		}
+		if len(res.LastEvaluatedKey) == 0 {
+			return keys, nil
+		}
+		input.ExclusiveStartKey = res.LastEvaluatedKey
+	}
+}
+
 func (d *dynamodbCache) Delete(ctx context.Context, key string) error {
 	boff := d.boffPool.Get().(backoff.BackOff)
 	defer func() {
@@ -122,6 +122,10 @@ cache_resources:
 		integration.CacheTestDoubleAdd(),
 		integration.CacheTestDelete(),
 		integration.CacheTestGetAndSet(50),
+		integration.CacheTestGetMulti(),
+		integration.CacheTestIncrement(),
+		integration.CacheTestCompareAndSwap(),
+		integration.CacheTestScan(),
 	)
 	suite.Run(
 		t, template,
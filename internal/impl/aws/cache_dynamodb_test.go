@@ -1,10 +1,16 @@
 package aws
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/cenkalti/backoff/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -88,3 +94,114 @@ ttl_key: buz
 		})
 	}
 }
+
+type mockDynamoDBBatchGet struct {
+	dynamoDBAPIV2
+	items    map[string][]byte
+	requests [][]string
+}
+
+func (m *mockDynamoDBBatchGet) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	var keys []string
+	var items []map[string]types.AttributeValue
+	seen := map[string]struct{}{}
+	for _, k := range params.RequestItems["foo"].Keys {
+		key := k["id"].(*types.AttributeValueMemberS).Value
+		if _, exists := seen[key]; exists {
+			return nil, errors.New("ValidationException: Provided list of item keys contains duplicates")
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+		if v, exists := m.items[key]; exists {
+			items = append(items, map[string]types.AttributeValue{
+				"id":      &types.AttributeValueMemberS{Value: key},
+				"content": &types.AttributeValueMemberB{Value: v},
+			})
+		}
+	}
+	m.requests = append(m.requests, keys)
+	return &dynamodb.BatchGetItemOutput{
+		Responses: map[string][]map[string]types.AttributeValue{"foo": items},
+	}, nil
+}
+
+func TestDynamoDBCacheGetMultiDuplicates(t *testing.T) {
+	client := &mockDynamoDBBatchGet{
+		items: map[string][]byte{"a": []byte("hello"), "b": []byte("world")},
+	}
+	boff := backoff.NewExponentialBackOff()
+	boff.MaxElapsedTime = time.Millisecond
+	c := newDynamodbCache(client, "foo", "id", "content", false, nil, nil, boff)
+
+	values, err := c.GetMulti(context.Background(), "a", "a", "c", "b", "a")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a": []byte("hello"), "b": []byte("world")}, values)
+	assert.Equal(t, [][]string{{"a", "c", "b"}}, client.requests)
+}
+
+type mockDynamoDBScan struct {
+	dynamoDBAPIV2
+	keys   []string
+	inputs []*dynamodb.ScanInput
+}
+
+// Scan returns pages of two keys, filtering by the value of a begins_with
+// expression when one is present.
+func (m *mockDynamoDBScan) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	m.inputs = append(m.inputs, params)
+
+	start := 0
+	if params.ExclusiveStartKey != nil {
+		last := params.ExclusiveStartKey["id"].(*types.AttributeValueMemberS).Value
+		for i, k := range m.keys {
+			if k == last {
+				start = i + 1
+			}
+		}
+	}
+	end := min(start+2, len(m.keys))
+
+	var prefix string
+	for _, v := range params.ExpressionAttributeValues {
+		prefix = v.(*types.AttributeValueMemberS).Value
+	}
+
+	out := &dynamodb.ScanOutput{}
+	for _, k := range m.keys[start:end] {
+		if strings.HasPrefix(k, prefix) {
+			out.Items = append(out.Items, map[string]types.AttributeValue{
+				"id": &types.AttributeValueMemberS{Value: k},
+			})
+		}
+	}
+	if end < len(m.keys) {
+		out.LastEvaluatedKey = map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: m.keys[end-1]},
+		}
+	}
+	return out, nil
+}
+
+func TestDynamoDBCacheScan(t *testing.T) {
+	client := &mockDynamoDBScan{
+		keys: []string{"foo", "bar", "foobar", "baz", "foobaz"},
+	}
+	boff := backoff.NewExponentialBackOff()
+	boff.MaxElapsedTime = time.Millisecond
+	c := newDynamodbCache(client, "foo", "id", "content", false, nil, nil, boff)
+
+	keys, err := c.Scan(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo", "bar", "foobar", "baz", "foobaz"}, keys)
+	require.Len(t, client.inputs, 3)
+	assert.Nil(t, client.inputs[0].FilterExpression)
+	require.NotNil(t, client.inputs[0].ProjectionExpression)
+
+	client.inputs = nil
+	keys, err = c.Scan(context.Background(), "foo")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo", "foobar", "foobaz"}, keys)
+	require.Len(t, client.inputs, 3)
+	require.NotNil(t, client.inputs[0].FilterExpression)
+	assert.Contains(t, *client.inputs[0].FilterExpression, "begins_with")
+}
@@ -45,3 +45,8 @@ const (
 	etcdWatchWithFilterDelete        = "with_delete_filter"
 	etcdWatchWithRangeField          = "with_range"
 )
+
+// etcd lock configuration
+const (
+	etcdLockPrefixField = "prefix"
+)
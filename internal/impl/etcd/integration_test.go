@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -94,6 +95,11 @@ func TestIntegrationEtcd(t *testing.T) {
 		testWatchKeyPrefix(t, address, client)
 	})
 
+	t.Run("leader_only_input", func(t *testing.T) {
+		client, address := setupEtcd(t)
+		testLeaderOnlyInput(t, address, client)
+	})
+
 }
 
 func testWatchSingleKey(t *testing.T, etcdDockerAddress string, etcdClient *clientv3.Client) {
@@ -261,3 +267,60 @@ etcd:
 
 	require.Equal(t, expectedDeleteEvent, string(finalDeleteEvent))
 }
+
+func testLeaderOnlyInput(t *testing.T, etcdDockerAddress string, etcdClient *clientv3.Client) {
+	newStream := func() (*service.Stream, *atomic.Int64) {
+		builder := service.NewStreamBuilder()
+		require.NoError(t, builder.SetLoggerYAML(`level: OFF`))
+		require.NoError(t, builder.AddLockYAML(fmt.Sprintf(`
+label: leader
+etcd:
+  endpoints:
+  - %s`, etcdDockerAddress)))
+		require.NoError(t, builder.AddInputYAML(`
+label: poller
+generate:
+  interval: 10ms
+  mapping: 'root = "hello"'
+leader_only:
+  lock: leader
+  lease: 2s
+`))
+
+		var count atomic.Int64
+		require.NoError(t, builder.AddConsumerFunc(func(ctx context.Context, m *service.Message) error {
+			count.Add(1)
+			return nil
+		}))
+
+		strm, err := builder.Build()
+		require.NoError(t, err)
+		return strm, &count
+	}
+
+	streamA, _ := newStream()
+	go func() {
+		_ = streamA.Run(context.Background())
+	}()
+
+	assert.Eventually(t, func() bool {
+		resp, err := etcdClient.Get(context.Background(), "bento_lock/poller")
+		return err == nil && len(resp.Kvs) == 1
+	}, time.Second*10, time.Millisecond*100)
+
+	streamB, countB := newStream()
+	go func() {
+		_ = streamB.Run(context.Background())
+	}()
+
+	time.Sleep(time.Second)
+	assert.Zero(t, countB.Load(), "standby instance consumed messages")
+
+	require.NoError(t, streamA.StopWithin(time.Second*10))
+
+	assert.Eventually(t, func() bool {
+		return countB.Load() > 0
+	}, time.Second*10, time.Millisecond*100)
+
+	require.NoError(t, streamB.StopWithin(time.Second*10))
+}
@@ -0,0 +1,150 @@
+package etcd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+func etcdLockConfigSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Version("1.14.0").
+		Summary("A lock implementation using etcd, where each lock is a key attached to an etcd lease.").
+		Description(`
+A lock is acquired by creating its key with a transaction that only succeeds when the key does not yet exist, and the key is attached to an etcd lease with a TTL of the requested lease period rounded up to the nearest second. The lease is renewed with a keep alive whilst the lock is held, and revoked when the lock is released, which removes the key.`).
+		Fields(etcdClientFields()...).
+		Field(service.NewStringField(etcdLockPrefixField).
+			Description("A prefix added to the name of each lock in order to determine the key that stores it.").
+			Default("bento_lock/"))
+}
+
+func init() {
+	err := service.RegisterLock("etcd", etcdLockConfigSpec(), func(conf *service.ParsedConfig, mgr *service.Resources) (service.Lock, error) {
+		return newEtcdLockFromConfig(conf)
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type etcdLock struct {
+	client *clientv3.Client
+	prefix string
+	token  string
+
+	leasesMut sync.Mutex
+	leases    map[string]clientv3.LeaseID
+}
+
+func newEtcdLockFromConfig(parsedConf *service.ParsedConfig) (*etcdLock, error) {
+	config, err := newEtcdConfigFromParsed(parsedConf)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := parsedConf.FieldString(etcdLockPrefixField)
+	if err != nil {
+		return nil, err
+	}
+
+	u4, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := newEtcdClientFromConfig(context.Background(), config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &etcdLock{
+		client: client,
+		prefix: prefix,
+		token:  u4.String(),
+		leases: map[string]clientv3.LeaseID{},
+	}, nil
+}
+
+func leaseTTL(lease time.Duration) int64 {
+	return max(1, int64(math.Ceil(lease.Seconds())))
+}
+
+func (e *etcdLock) TryAcquire(ctx context.Context, name string, lease time.Duration) (bool, error) {
+	e.leasesMut.Lock()
+	defer e.leasesMut.Unlock()
+
+	key := e.prefix + name
+
+	// Renew the lease if the lock is still ours.
+	if id, exists := e.leases[name]; exists {
+		resp, err := e.client.Get(ctx, key)
+		if err != nil {
+			return false, err
+		}
+		if len(resp.Kvs) > 0 && string(resp.Kvs[0].Value) == e.token && clientv3.LeaseID(resp.Kvs[0].Lease) == id {
+			_, err := e.client.KeepAliveOnce(ctx, id)
+			if err == nil {
+				return true, nil
+			}
+			if !errors.Is(err, rpctypes.ErrLeaseNotFound) {
+				return false, err
+			}
+		}
+		delete(e.leases, name)
+	}
+
+	granted, err := e.client.Grant(ctx, leaseTTL(lease))
+	if err != nil {
+		return false, fmt.Errorf("failed to grant lease: %w", err)
+	}
+
+	txnResp, err := e.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, e.token, clientv3.WithLease(granted.ID))).
+		Commit()
+	if err != nil || !txnResp.Succeeded {
+		_, _ = e.client.Revoke(ctx, granted.ID)
+		return false, err
+	}
+
+	e.leases[name] = granted.ID
+	return true, nil
+}
+
+func (e *etcdLock) Release(ctx context.Context, name string) error {
+	e.leasesMut.Lock()
+	defer e.leasesMut.Unlock()
+
+	id, exists := e.leases[name]
+	if !exists {
+		return nil
+	}
+	delete(e.leases, name)
+
+	// Revoking the lease deletes the key attached to it.
+	if _, err := e.client.Revoke(ctx, id); err != nil && !errors.Is(err, rpctypes.ErrLeaseNotFound) {
+		return err
+	}
+	return nil
+}
+
+func (e *etcdLock) Close(ctx context.Context) error {
+	e.leasesMut.Lock()
+	for name, id := range e.leases {
+		_, _ = e.client.Revoke(ctx, id)
+		delete(e.leases, name)
+	}
+	e.leasesMut.Unlock()
+
+	return e.client.Close()
+}
@@ -0,0 +1,242 @@
+package html
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+
+	"github.com/warpstreamlabs/bento/public/bloblang"
+)
+
+func init() {
+	if err := bloblang.RegisterMethodV2("parse_html",
+		bloblang.NewPluginSpec().
+			Category("Parsing").
+			Description(`
+Parses an HTML document into a structured result, where each element is an object with the following fields:
+
+- `+"`name`"+`: The tag name of the element.
+- `+"`attributes`"+`: An object of the attributes of the element, omitted when the element has no attributes.
+- `+"`children`"+`: An array of the child elements and text of the element, omitted when the element has no children.
+
+Text is represented as strings, where text consisting only of whitespace is omitted. Comments are ignored. Documents are parsed following the HTML5 specification, and therefore the result is always a `+"`html`"+` element containing `+"`head`"+` and `+"`body`"+` elements.
+`).
+			Example("", `root.title = this.doc.parse_html().children.index(0).children.index(0)`, [2]string{
+				`{"doc":"<html><head><title>Hello</title></head><body><p class=\"intro\">Hello world</p></body></html>"}`,
+				`{"title":{"children":["Hello"],"name":"title"}}`,
+			}),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.BytesMethod(func(b []byte) (any, error) {
+				doc, err := html.Parse(bytes.NewReader(b))
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse value as HTML: %w", err)
+				}
+				for n := doc.FirstChild; n != nil; n = n.NextSibling {
+					if n.Type == html.ElementNode {
+						return nodeToValue(n), nil
+					}
+				}
+				return nil, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("html_select",
+		bloblang.NewPluginSpec().
+			Category("Parsing").
+			Description("Parses an HTML document and returns an array containing a value for each element matching a [CSS selector](https://developer.mozilla.org/en-US/docs/Web/CSS/CSS_Selectors), in document order.").
+			Param(bloblang.NewStringParam("selector").Description("The CSS selector to match elements with.")).
+			Param(bloblang.NewStringParam("output").Description("The value to return for each matched element. One of `text` for the text content, `inner_html` for the HTML of the contents, `outer_html` for the HTML of the element including its tags, `attributes` for an object of the attributes, or an attribute name prefixed with `@` (e.g. `@href`) for the value of that attribute, in which case elements without the attribute are skipped.").Default("text")).
+			Example("", `root.headlines = this.doc.html_select("h2.headline")`, [2]string{
+				`{"doc":"<body><h2 class=\"headline\">First</h2><h2>Ignored</h2><h2 class=\"headline\">Second</h2></body>"}`,
+				`{"headlines":["First","Second"]}`,
+			}).
+			Example("", `root.links = this.doc.html_select("a", "@href")`, [2]string{
+				`{"doc":"<ul><li><a href=\"/foo\">Foo</a></li><li><a>Nope</a></li><li><a href=\"/bar\">Bar</a></li></ul>"}`,
+				`{"links":["/foo","/bar"]}`,
+			}).
+			Example("", `root.items = this.doc.html_select("li", "inner_html")`, [2]string{
+				`{"doc":"<ul><li><b>Foo</b></li><li>Bar</li></ul>"}`,
+				`{"items":["<b>Foo</b>","Bar"]}`,
+			}),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			selStr, err := args.GetString("selector")
+			if err != nil {
+				return nil, err
+			}
+			sel, err := cascadia.Compile(selStr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compile CSS selector: %w", err)
+			}
+			output, err := args.GetString("output")
+			if err != nil {
+				return nil, err
+			}
+			outputFn, err := nodeOutputFn(output)
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.BytesMethod(func(b []byte) (any, error) {
+				doc, err := html.Parse(bytes.NewReader(b))
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse value as HTML: %w", err)
+				}
+				res := []any{}
+				for _, n := range cascadia.QueryAll(doc, sel) {
+					if v, ok := outputFn(n); ok {
+						res = append(res, v)
+					}
+				}
+				return res, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("xpath",
+		bloblang.NewPluginSpec().
+			Category("Parsing").
+			Description("Parses an HTML document and evaluates an [XPath](https://developer.mozilla.org/en-US/docs/Web/XPath) expression against it. When the expression selects nodes an array is returned containing a value for each node in document order, where selected attributes result in their value. Otherwise, the result of the expression is returned as a string, number or boolean. In order to query XML documents use [`xml_xpath`](#xml_xpath) instead.").
+			Param(bloblang.NewStringParam("expression").Description("The XPath expression to evaluate.")).
+			Param(bloblang.NewStringParam("output").Description("The value to return for each selected node. One of `text` for the text content, `inner_html` for the HTML of the contents, `outer_html` for the HTML of the node including its tags, or `attributes` for an object of the attributes.").Default("text")).
+			Example("", `root.prices = this.doc.xpath("//td[@class='price']")
+root.count = this.doc.xpath("count(//tr)")`, [2]string{
+				`{"doc":"<table><tr><td>Foo</td><td class=\"price\">1.50</td></tr><tr><td>Bar</td><td class=\"price\">2.25</td></tr></table>"}`,
+				`{"count":2,"prices":["1.50","2.25"]}`,
+			}).
+			Example("", `root.images = this.doc.xpath("//img/@src")`, [2]string{
+				`{"doc":"<div><img src=\"a.png\"><img src=\"b.png\"></div>"}`,
+				`{"images":["a.png","b.png"]}`,
+			}),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			exprStr, err := args.GetString("expression")
+			if err != nil {
+				return nil, err
+			}
+			expr, err := xpath.Compile(exprStr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compile XPath expression: %w", err)
+			}
+			output, err := args.GetString("output")
+			if err != nil {
+				return nil, err
+			}
+			if strings.HasPrefix(output, "@") {
+				return nil, fmt.Errorf("output '%v' not recognised, select attributes within the expression instead", output)
+			}
+			outputFn, err := nodeOutputFn(output)
+			if err != nil {
+				return nil, err
+			}
+
+			// Evaluating an expression modifies its state.
+			var exprMut sync.Mutex
+			return bloblang.BytesMethod(func(b []byte) (any, error) {
+				doc, err := html.Parse(bytes.NewReader(b))
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse value as HTML: %w", err)
+				}
+
+				exprMut.Lock()
+				v := expr.Evaluate(htmlquery.CreateXPathNavigator(doc))
+				exprMut.Unlock()
+
+				iter, ok := v.(*xpath.NodeIterator)
+				if !ok {
+					return v, nil
+				}
+				res := []any{}
+				for iter.MoveNext() {
+					nav := iter.Current().(*htmlquery.NodeNavigator)
+					if nav.NodeType() == xpath.AttributeNode {
+						res = append(res, nav.Value())
+						continue
+					}
+					if v, ok := outputFn(nav.Current()); ok {
+						res = append(res, v)
+					}
+				}
+				return res, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+func nodeOutputFn(output string) (func(n *html.Node) (any, bool), error) {
+	switch output {
+	case "text":
+		return func(n *html.Node) (any, bool) {
+			return htmlquery.InnerText(n), true
+		}, nil
+	case "inner_html":
+		return func(n *html.Node) (any, bool) {
+			return htmlquery.OutputHTML(n, false), true
+		}, nil
+	case "outer_html":
+		return func(n *html.Node) (any, bool) {
+			return htmlquery.OutputHTML(n, true), true
+		}, nil
+	case "attributes":
+		return func(n *html.Node) (any, bool) {
+			return attributesToValue(n), true
+		}, nil
+	}
+	if attr, ok := strings.CutPrefix(output, "@"); ok && attr != "" {
+		return func(n *html.Node) (any, bool) {
+			for _, a := range n.Attr {
+				if a.Key == attr {
+					return a.Val, true
+				}
+			}
+			return nil, false
+		}, nil
+	}
+	return nil, fmt.Errorf("output '%v' not recognised", output)
+}
+
+func attributesToValue(n *html.Node) map[string]any {
+	attrs := make(map[string]any, len(n.Attr))
+	for _, a := range n.Attr {
+		key := a.Key
+		if a.Namespace != "" {
+			key = a.Namespace + ":" + key
+		}
+		attrs[key] = a.Val
+	}
+	return attrs
+}
+
+func nodeToValue(n *html.Node) map[string]any {
+	obj := map[string]any{
+		"name": n.Data,
+	}
+	if len(n.Attr) > 0 {
+		obj["attributes"] = attributesToValue(n)
+	}
+
+	var children []any
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch c.Type {
+		case html.ElementNode:
+			children = append(children, nodeToValue(c))
+		case html.TextNode:
+			if strings.TrimSpace(c.Data) != "" {
+				children = append(children, c.Data)
+			}
+		}
+	}
+	if len(children) > 0 {
+		obj["children"] = children
+	}
+	return obj
+}
@@ -0,0 +1,151 @@
+package html
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/public/bloblang"
+)
+
+const testHTMLDoc = `<!DOCTYPE html>
+<html>
+  <head><title>Test page</title></head>
+  <body>
+    <div id="main" class="content">
+      <h1>Hello <em>world</em></h1>
+      <!-- a comment -->
+      <a href="/foo" class="link">Foo</a>
+      <a href="/bar" class="link external" data-id="2">Bar</a>
+    </div>
+  </body>
+</html>`
+
+func TestHTMLMethods(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mapping string
+		exp     any
+	}{
+		{
+			name:    "parse html",
+			mapping: `root = this.parse_html().children.index(1).children.index(0).without("children")`,
+			exp: map[string]any{
+				"name":       "div",
+				"attributes": map[string]any{"id": "main", "class": "content"},
+			},
+		},
+		{
+			name:    "parse html text and nested elements",
+			mapping: `root = this.parse_html().children.index(1).children.index(0).children.index(0)`,
+			exp: map[string]any{
+				"name": "h1",
+				"children": []any{
+					"Hello ",
+					map[string]any{"name": "em", "children": []any{"world"}},
+				},
+			},
+		},
+		{
+			name:    "select text",
+			mapping: `root = this.html_select("div#main > h1")`,
+			exp:     []any{"Hello world"},
+		},
+		{
+			name:    "select attribute",
+			mapping: `root = this.html_select("a.link", "@data-id")`,
+			exp:     []any{"2"},
+		},
+		{
+			name:    "select attributes",
+			mapping: `root = this.html_select("a.external", "attributes")`,
+			exp: []any{
+				map[string]any{"href": "/bar", "class": "link external", "data-id": "2"},
+			},
+		},
+		{
+			name:    "select inner and outer html",
+			mapping: `root = [ this.html_select("h1", "inner_html"), this.html_select("em", "outer_html") ]`,
+			exp:     []any{[]any{"Hello <em>world</em>"}, []any{"<em>world</em>"}},
+		},
+		{
+			name:    "select nothing",
+			mapping: `root = this.html_select("table")`,
+			exp:     []any{},
+		},
+		{
+			name:    "xpath nodes",
+			mapping: `root = this.xpath("//a[contains(@class, 'link')]")`,
+			exp:     []any{"Foo", "Bar"},
+		},
+		{
+			name:    "xpath attributes",
+			mapping: `root = this.xpath("//a/@href")`,
+			exp:     []any{"/foo", "/bar"},
+		},
+		{
+			name:    "xpath outer html",
+			mapping: `root = this.xpath("//h1/em", "outer_html")`,
+			exp:     []any{"<em>world</em>"},
+		},
+		{
+			name:    "xpath scalars",
+			mapping: `root = [ this.xpath("count(//a)"), this.xpath("string(//title)"), this.xpath("count(//a) > 1") ]`,
+			exp:     []any{2.0, "Test page", true},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			require.NoError(t, err)
+
+			res, err := exec.Query(testHTMLDoc)
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, res)
+		})
+	}
+}
+
+func TestHTMLMethodErrors(t *testing.T) {
+	for _, mapping := range []string{
+		`root = this.html_select("div[")`,
+		`root = this.html_select("div", "nope")`,
+		`root = this.xpath("//div[")`,
+		`root = this.xpath("//div", "@href")`,
+	} {
+		_, err := bloblang.Parse(mapping)
+		require.Error(t, err, mapping)
+	}
+}
+
+func TestHTMLMethodExamples(t *testing.T) {
+	bloblang.GlobalEnvironment().WalkMethods(func(name string, view *bloblang.MethodView) {
+		switch name {
+		case "parse_html", "html_select", "xpath":
+		default:
+			return
+		}
+		t.Run(name, func(t *testing.T) {
+			for _, e := range view.TemplateData().Examples {
+				exec, err := bloblang.Parse(e.Mapping)
+				require.NoError(t, err)
+
+				for _, io := range e.Results {
+					var input any
+					require.NoError(t, json.Unmarshal([]byte(io[0]), &input))
+
+					res, err := exec.Query(input)
+					require.NoError(t, err)
+
+					resBytes, err := json.Marshal(res)
+					require.NoError(t, err)
+					assert.JSONEq(t, io[1], string(resBytes))
+				}
+			}
+		})
+	})
+}
@@ -0,0 +1,336 @@
+package io
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	gqlsFieldURL              = "url"
+	gqlsFieldProxyURL         = "proxy_url"
+	gqlsFieldHeaders          = "headers"
+	gqlsFieldProtocol         = "protocol"
+	gqlsFieldConnectionParams = "connection_params"
+	gqlsFieldQuery            = "query"
+	gqlsFieldVariables        = "variables"
+	gqlsFieldOperationName    = "operation_name"
+	gqlsFieldAckTimeout       = "connection_ack_timeout"
+	gqlsFieldTLS              = "tls"
+)
+
+const (
+	// gqlsProtocolTransportWS is the protocol implemented by the graphql-ws
+	// library.
+	gqlsProtocolTransportWS = "graphql-transport-ws"
+	// gqlsProtocolLegacyWS is the protocol implemented by the deprecated
+	// subscriptions-transport-ws library.
+	gqlsProtocolLegacyWS = "graphql-ws"
+)
+
+func graphqlSubscriptionInputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Version("1.14.0").
+		Categories("Network").
+		Summary("Connects to a GraphQL server over a websocket and consumes the events of a subscription.").
+		Description(`
+Each event of the subscription results in a message containing the `+"`data`"+` of the event. If an event contains any `+"`errors`"+` then the message is flagged as having failed with the error messages reported by the server, and can be handled with [error handling patterns](/docs/configuration/error_handling).
+
+When the connection is lost, or the server reports an error for the subscription, the input reconnects and subscribes again. Events emitted by the server whilst disconnected are not recovered. When the server completes the subscription the input shuts down.
+
+### Protocols
+
+Two websocket subprotocols are supported, `+"`graphql-transport-ws`"+` as implemented by the [graphql-ws](https://github.com/enisdenjo/graphql-ws) library, and the legacy `+"`graphql-ws`"+` protocol as implemented by the deprecated [subscriptions-transport-ws](https://github.com/apollographql/subscriptions-transport-ws) library.`).
+		Fields(
+			service.NewURLField(gqlsFieldURL).
+				Description("The URL to connect to.").
+				Example("wss://localhost:4000/graphql"),
+			service.NewURLField(gqlsFieldProxyURL).
+				Description("An optional HTTP proxy URL.").
+				Advanced().Optional(),
+			service.NewInterpolatedStringMapField(gqlsFieldHeaders).
+				Description("A map of custom headers to add to the websocket handshake.").
+				Example(map[string]any{
+					"Authorization": `Bearer ${TOKEN}`,
+				}).
+				Advanced().
+				Default(map[string]any{}),
+			service.NewStringAnnotatedEnumField(gqlsFieldProtocol, map[string]string{
+				gqlsProtocolTransportWS: "The protocol of the graphql-ws library.",
+				gqlsProtocolLegacyWS:    "The legacy protocol of the subscriptions-transport-ws library.",
+			}).
+				Description("The websocket subprotocol to communicate with.").
+				Default(gqlsProtocolTransportWS),
+			service.NewAnyField(gqlsFieldConnectionParams).
+				Description("An object sent as the payload of the connection initialisation message, which is commonly used for authentication.").
+				Example(map[string]any{"authToken": "${TOKEN}"}).
+				Default(map[string]any{}),
+			service.NewStringField(gqlsFieldQuery).
+				Description("The GraphQL subscription document to execute.").
+				Example(`subscription { orderCreated { id total } }`),
+			service.NewAnyField(gqlsFieldVariables).
+				Description("An object of variables for the subscription.").
+				Example(map[string]any{"region": "eu-west-1"}).
+				Default(map[string]any{}),
+			service.NewStringField(gqlsFieldOperationName).
+				Description("The name of the operation to execute, which is required when the subscription document contains multiple operations.").
+				Advanced().
+				Optional(),
+			service.NewDurationField(gqlsFieldAckTimeout).
+				Description("The maximum period to wait for the server to acknowledge a new connection.").
+				Advanced().
+				Default("10s"),
+			service.NewAutoRetryNacksToggleField(),
+			service.NewTLSToggledField(gqlsFieldTLS),
+		).
+		Fields(service.NewHTTPRequestAuthSignerFields()...).
+		Example("Order Events", "Consumes newly created orders from a GraphQL server and writes them to stdout.", `
+input:
+  graphql_subscription:
+    url: wss://api.example.com/graphql
+    connection_params:
+      authToken: ${API_TOKEN}
+    query: |
+      subscription($region: String!) {
+        orderCreated(region: $region) { id total }
+      }
+    variables:
+      region: eu-west-1
+
+pipeline:
+  processors:
+    - mapping: 'root = this.orderCreated'
+
+output:
+  stdout: {}
+`)
+}
+
+func init() {
+	err := service.RegisterInput(
+		"graphql_subscription", graphqlSubscriptionInputSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Input, error) {
+			i, err := newGraphQLSubscriptionInputFromParsed(conf, mgr)
+			if err != nil {
+				return nil, err
+			}
+			return service.AutoRetryNacksToggled(conf, i)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// gqlwsMessage is the envelope of all messages of both websocket protocols.
+type gqlwsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+const gqlsSubscriptionID = "1"
+
+type graphqlSubscriptionInput struct {
+	dialer     wsDialer
+	protocol   string
+	connParams json.RawMessage
+	payload    json.RawMessage
+	ackTimeout time.Duration
+
+	log *service.Logger
+
+	connMut  sync.Mutex
+	conn     *websocket.Conn
+	writeMut sync.Mutex
+}
+
+func newGraphQLSubscriptionInputFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (*graphqlSubscriptionInput, error) {
+	g := &graphqlSubscriptionInput{
+		log: mgr.Logger(),
+	}
+
+	var err error
+	if g.dialer, err = wsDialerFromParsed(conf, mgr.FS()); err != nil {
+		return nil, err
+	}
+	if g.protocol, err = conf.FieldString(gqlsFieldProtocol); err != nil {
+		return nil, err
+	}
+	g.dialer.subprotocols = []string{g.protocol}
+
+	connParams, err := conf.FieldAny(gqlsFieldConnectionParams)
+	if err != nil {
+		return nil, err
+	}
+	if g.connParams, err = json.Marshal(connParams); err != nil {
+		return nil, fmt.Errorf("failed to encode %v: %w", gqlsFieldConnectionParams, err)
+	}
+
+	payload := map[string]any{}
+	if payload["query"], err = conf.FieldString(gqlsFieldQuery); err != nil {
+		return nil, err
+	}
+	if payload["variables"], err = conf.FieldAny(gqlsFieldVariables); err != nil {
+		return nil, err
+	}
+	if conf.Contains(gqlsFieldOperationName) {
+		if payload["operationName"], err = conf.FieldString(gqlsFieldOperationName); err != nil {
+			return nil, err
+		}
+	}
+	if g.payload, err = json.Marshal(payload); err != nil {
+		return nil, fmt.Errorf("failed to encode subscription: %w", err)
+	}
+
+	if g.ackTimeout, err = conf.FieldDuration(gqlsFieldAckTimeout); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (g *graphqlSubscriptionInput) write(conn *websocket.Conn, msg gqlwsMessage) error {
+	g.writeMut.Lock()
+	defer g.writeMut.Unlock()
+	return conn.WriteJSON(msg)
+}
+
+func (g *graphqlSubscriptionInput) Connect(ctx context.Context) error {
+	g.connMut.Lock()
+	defer g.connMut.Unlock()
+
+	if g.conn != nil {
+		return nil
+	}
+
+	conn, err := g.dialer.dial(ctx)
+	if err != nil {
+		return err
+	}
+	if err := g.init(conn); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	g.conn = conn
+	return nil
+}
+
+// init performs the handshake of the protocol and starts the subscription.
+func (g *graphqlSubscriptionInput) init(conn *websocket.Conn) error {
+	if err := g.write(conn, gqlwsMessage{Type: "connection_init", Payload: g.connParams}); err != nil {
+		return err
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(g.ackTimeout))
+	for acked := false; !acked; {
+		var msg gqlwsMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return fmt.Errorf("failed to receive connection acknowledgement: %w", err)
+		}
+		switch msg.Type {
+		case "connection_ack":
+			acked = true
+		case "connection_error":
+			return fmt.Errorf("connection rejected: %s", msg.Payload)
+		case "ping":
+			if err := g.write(conn, gqlwsMessage{Type: "pong"}); err != nil {
+				return err
+			}
+		}
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+
+	startType := "subscribe"
+	if g.protocol == gqlsProtocolLegacyWS {
+		startType = "start"
+	}
+	return g.write(conn, gqlwsMessage{ID: gqlsSubscriptionID, Type: startType, Payload: g.payload})
+}
+
+func (g *graphqlSubscriptionInput) resetConn(conn *websocket.Conn) {
+	g.connMut.Lock()
+	if g.conn == conn {
+		g.conn = nil
+	}
+	g.connMut.Unlock()
+	_ = conn.Close()
+}
+
+func (g *graphqlSubscriptionInput) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
+	g.connMut.Lock()
+	conn := g.conn
+	g.connMut.Unlock()
+
+	if conn == nil {
+		return nil, nil, service.ErrNotConnected
+	}
+
+	for {
+		var msg gqlwsMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			g.resetConn(conn)
+			if errors.Is(err, websocket.ErrCloseSent) || websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				g.log.Debugf("Connection closed: %v", err)
+			} else {
+				g.log.Errorf("Failed to read subscription event: %v", err)
+			}
+			return nil, nil, service.ErrNotConnected
+		}
+
+		switch msg.Type {
+		case "next", "data":
+			res, err := parseGraphQLResponse(msg.Payload)
+			if err != nil {
+				g.log.Errorf("Failed to parse subscription event: %v", err)
+				continue
+			}
+			part := service.NewMessage(nil)
+			part.SetStructured(res.Data)
+			if len(res.Errors) > 0 {
+				part.SetError(graphqlErrorsToErr(res.Errors))
+			}
+			return part, func(ctx context.Context, err error) error {
+				return nil
+			}, nil
+		case "error", "connection_error":
+			g.log.Errorf("Subscription failed: %s", msg.Payload)
+			g.resetConn(conn)
+			return nil, nil, service.ErrNotConnected
+		case "complete":
+			g.resetConn(conn)
+			return nil, nil, service.ErrEndOfInput
+		case "ping":
+			if err := g.write(conn, gqlwsMessage{Type: "pong"}); err != nil {
+				g.resetConn(conn)
+				return nil, nil, service.ErrNotConnected
+			}
+		}
+	}
+}
+
+func (g *graphqlSubscriptionInput) Close(ctx context.Context) error {
+	g.connMut.Lock()
+	defer g.connMut.Unlock()
+
+	if g.conn == nil {
+		return nil
+	}
+
+	stopType := "complete"
+	if g.protocol == gqlsProtocolLegacyWS {
+		stopType = "stop"
+	}
+	_ = g.write(g.conn, gqlwsMessage{ID: gqlsSubscriptionID, Type: stopType})
+
+	err := g.conn.Close()
+	g.conn = nil
+	return err
+}
@@ -0,0 +1,140 @@
+package io
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+func TestGraphQLSubscriptionInput(t *testing.T) {
+	for _, test := range []struct {
+		protocol  string
+		startType string
+		dataType  string
+	}{
+		{protocol: gqlsProtocolTransportWS, startType: "subscribe", dataType: "next"},
+		{protocol: gqlsProtocolLegacyWS, startType: "start", dataType: "data"},
+	} {
+		t.Run(test.protocol, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				upgrader := websocket.Upgrader{Subprotocols: []string{test.protocol}}
+				ws, err := upgrader.Upgrade(w, r, nil)
+				if err != nil {
+					return
+				}
+				defer ws.Close()
+
+				assert.Equal(t, test.protocol, ws.Subprotocol())
+				assert.Equal(t, "Bearer foo", r.Header.Get("Authorization"))
+
+				var msg gqlwsMessage
+				require.NoError(t, ws.ReadJSON(&msg))
+				assert.Equal(t, "connection_init", msg.Type)
+				assert.JSONEq(t, `{"token":"bar"}`, string(msg.Payload))
+
+				require.NoError(t, ws.WriteJSON(gqlwsMessage{Type: "ping"}))
+				require.NoError(t, ws.ReadJSON(&msg))
+				assert.Equal(t, "pong", msg.Type)
+
+				require.NoError(t, ws.WriteJSON(gqlwsMessage{Type: "connection_ack"}))
+
+				require.NoError(t, ws.ReadJSON(&msg))
+				assert.Equal(t, test.startType, msg.Type)
+				assert.JSONEq(t, `{"query":"subscription { events(kind: $kind) { id } }","variables":{"kind":"foo"}}`, string(msg.Payload))
+
+				for _, payload := range []string{
+					`{"data":{"events":{"id":"1"}}}`,
+					`{"data":null,"errors":[{"message":"boom"}]}`,
+					`{"data":{"events":{"id":"2"}}}`,
+				} {
+					require.NoError(t, ws.WriteJSON(gqlwsMessage{ID: msg.ID, Type: test.dataType, Payload: json.RawMessage(payload)}))
+				}
+				require.NoError(t, ws.WriteJSON(gqlwsMessage{ID: msg.ID, Type: "complete"}))
+			}))
+			t.Cleanup(server.Close)
+
+			conf, err := graphqlSubscriptionInputSpec().ParseYAML(fmt.Sprintf(`
+url: %v
+protocol: %v
+headers:
+  Authorization: Bearer foo
+connection_params:
+  token: bar
+query: 'subscription { events(kind: $kind) { id } }'
+variables:
+  kind: foo
+`, strings.Replace(server.URL, "http", "ws", 1), test.protocol), nil)
+			require.NoError(t, err)
+
+			in, err := newGraphQLSubscriptionInputFromParsed(conf, service.MockResources())
+			require.NoError(t, err)
+
+			ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+			defer done()
+
+			require.NoError(t, in.Connect(ctx))
+
+			msg, _, err := in.Read(ctx)
+			require.NoError(t, err)
+			b, err := msg.AsBytes()
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"events":{"id":"1"}}`, string(b))
+
+			msg, _, err = in.Read(ctx)
+			require.NoError(t, err)
+			require.Error(t, msg.GetError())
+			assert.Contains(t, msg.GetError().Error(), "boom")
+
+			msg, _, err = in.Read(ctx)
+			require.NoError(t, err)
+			b, err = msg.AsBytes()
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"events":{"id":"2"}}`, string(b))
+
+			_, _, err = in.Read(ctx)
+			require.ErrorIs(t, err, service.ErrEndOfInput)
+
+			require.NoError(t, in.Close(ctx))
+		})
+	}
+}
+
+func TestGraphQLSubscriptionInputRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{Subprotocols: []string{gqlsProtocolTransportWS}}
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+
+		var msg gqlwsMessage
+		_ = ws.ReadJSON(&msg)
+		_ = ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(4403, "Forbidden"))
+	}))
+	t.Cleanup(server.Close)
+
+	conf, err := graphqlSubscriptionInputSpec().ParseYAML(fmt.Sprintf(`
+url: %v
+query: 'subscription { events { id } }'
+`, strings.Replace(server.URL, "http", "ws", 1)), nil)
+	require.NoError(t, err)
+
+	in, err := newGraphQLSubscriptionInputFromParsed(conf, service.MockResources())
+	require.NoError(t, err)
+
+	err = in.Connect(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Forbidden")
+}
@@ -0,0 +1,478 @@
+package io
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/checkpoint"
+
+	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/httpclient"
+	"github.com/warpstreamlabs/bento/internal/value"
+	"github.com/warpstreamlabs/bento/public/bloblang"
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	hpiFieldNextRequest   = "next_request"
+	hpiFieldStopCondition = "stop_condition"
+	hpiFieldInterval      = "interval"
+	hpiFieldCursorCache   = "cursor_cache"
+	hpiFieldCursorKey     = "cursor_key"
+)
+
+func httpPollerInputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Version("1.14.0").
+		Categories("Network").
+		Summary("Polls an HTTP API, computing each request from the response of the previous one in order to follow pagination and incrementally consume new data.").
+		Description(`
+The first request is made to the configured `+"`url`"+`, and each response results in a batch of messages as with the `+"[`http_client` input](/docs/components/inputs/http_client)"+`. The `+"`next_request`"+` mapping is then executed against the response in order to compute the following request, where the metadata of the mapped message contains all headers of the response with lowercased keys, the `+"`http_status_code`"+` of the response, and the `+"`http_poller_url`"+` of the request that was made.
+
+The mapping should result in an object that describes how the following request differs from the previous one, with any of the following fields:
+
+- `+"`url`"+`: A string that replaces the URL of the request.
+- `+"`query`"+`: An object of query parameters to set on the URL, where a `+"`null`"+` value removes the parameter.
+- `+"`headers`"+`: An object of headers to set on the request, where a `+"`null`"+` value removes a header previously set by the mapping.
+
+When the mapping deletes the root there is no further data to consume, and the previous request is repeated after the `+"`interval`"+` in order to poll for new data. This covers link headers, cursors, offsets and timestamps, see the examples for common patterns.
+
+### Stop Condition
+
+When a `+"`stop_condition`"+` is configured it is executed against each response in the same way as the `+"`next_request`"+` mapping, and when it results in `+"`true`"+` the input shuts down once the messages of the response have been delivered.
+
+### Cursor Persistence
+
+When a `+"`cursor_cache`"+` is set the next request computed from each response is stored within the cache under the key `+"`cursor_key`"+` once the messages of that response, and all responses before it, have been acknowledged. When the input starts it resumes from the stored request, if present, rather than the `+"`url`"+`.`).
+		Example(
+			"Cursor Pagination",
+			"Consumes all items of an API that returns a cursor to the next page within the response body, and polls for new items every minute once the last page is reached. The cursor is persisted in a file cache so that a restart resumes from the last page.",
+			`
+input:
+  http_poller:
+    url: https://api.example.com/items?limit=100
+    next_request: |
+      root = if this.next_cursor.or("") != "" {
+        { "query": { "cursor": this.next_cursor } }
+      } else {
+        deleted()
+      }
+    interval: 60s
+    cursor_cache: cursors
+    cursor_key: example_items
+
+cache_resources:
+  - label: cursors
+    file:
+      directory: ./cursors
+`,
+		).
+		Example(
+			"Link Headers",
+			"Follows the `next` relation of [link headers](https://www.rfc-editor.org/rfc/rfc8288) until there are no further pages, and then shuts down.",
+			`
+input:
+  http_poller:
+    url: https://api.github.com/repos/warpstreamlabs/bento/issues?per_page=100
+    next_request: |
+      let next = @link.or("").re_find_all_submatch("<([^>]+)>;\\s*rel=\"next\"").index(0).index(1).catch(null)
+      root = if $next != null { { "url": $next } } else { deleted() }
+    stop_condition: '!@link.or("").contains("rel=\"next\"")'
+`,
+		).
+		Example(
+			"Timestamps",
+			"Incrementally consumes events of an API that can be filtered by a timestamp, using the timestamp of the latest event of each response as the starting point of the following request.",
+			`
+input:
+  http_poller:
+    url: https://api.example.com/events?since=1970-01-01T00:00:00Z
+    next_request: |
+      root = if this.events.length() > 0 {
+        { "query": { "since": this.events.index(-1).created_at } }
+      } else {
+        deleted()
+      }
+    interval: 30s
+`,
+		).
+		Field(httpclient.ConfigField("GET", false,
+			service.NewInterpolatedStringField("payload").Description("An optional payload to deliver for each request.").Optional(),
+			service.NewBloblangField(hpiFieldNextRequest).
+				Description("A [Bloblang mapping](/docs/guides/bloblang/about) executed against each response, which computes the following request."),
+			service.NewBloblangField(hpiFieldStopCondition).
+				Description("An optional [Bloblang query](/docs/guides/bloblang/about) executed against each response, which should return a boolean indicating whether the input should shut down.").
+				Optional(),
+			service.NewDurationField(hpiFieldInterval).
+				Description("The period to wait before repeating the previous request when the `next_request` mapping deletes the root.").
+				Default("60s"),
+			service.NewStringField(hpiFieldCursorCache).
+				Description("An optional [cache resource](/docs/components/caches/about) in which to store the next request once the messages of a response are delivered, allowing the input to resume from it upon restart.").
+				Optional(),
+			service.NewStringField(hpiFieldCursorKey).
+				Description("The key under which the next request is stored within the `cursor_cache`.").
+				Default("http_poller_cursor"),
+		)).
+		Field(service.NewAutoRetryNacksToggleField())
+}
+
+func init() {
+	err := service.RegisterBatchInput(
+		"http_poller", httpPollerInputSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchInput, error) {
+			rdr, err := newHTTPPollerInputFromParsed(conf, mgr)
+			if err != nil {
+				return nil, err
+			}
+			return service.AutoRetryNacksBatchedToggled(conf, rdr)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// httpPollerRequest describes the parts of a request that are computed from
+// the previous response, and is the cursor stored within the cache.
+type httpPollerRequest struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// next returns the request that follows this one according to the result of
+// a next_request mapping.
+func (r httpPollerRequest) next(v any) (httpPollerRequest, error) {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return r, fmt.Errorf("expected an object, got %T", v)
+	}
+
+	next := httpPollerRequest{
+		URL:     r.URL,
+		Headers: maps.Clone(r.Headers),
+	}
+	for k, v := range obj {
+		switch k {
+		case "url":
+			urlStr, ok := v.(string)
+			if !ok {
+				return r, fmt.Errorf("expected url to be a string, got %T", v)
+			}
+			next.URL = urlStr
+		case "query", "headers":
+		default:
+			return r, fmt.Errorf("unexpected field %v", k)
+		}
+	}
+
+	if v, exists := obj["query"]; exists {
+		query, ok := v.(map[string]any)
+		if !ok {
+			return r, fmt.Errorf("expected query to be an object, got %T", v)
+		}
+		u, err := url.Parse(next.URL)
+		if err != nil {
+			return r, fmt.Errorf("failed to parse url: %w", err)
+		}
+		values := u.Query()
+		for k, v := range query {
+			if v == nil {
+				values.Del(k)
+			} else {
+				values.Set(k, value.IToString(v))
+			}
+		}
+		u.RawQuery = values.Encode()
+		next.URL = u.String()
+	}
+
+	if v, exists := obj["headers"]; exists {
+		headers, ok := v.(map[string]any)
+		if !ok {
+			return r, fmt.Errorf("expected headers to be an object, got %T", v)
+		}
+		for k, v := range headers {
+			if v == nil {
+				delete(next.Headers, k)
+				continue
+			}
+			if next.Headers == nil {
+				next.Headers = map[string]string{}
+			}
+			next.Headers[k] = value.IToString(v)
+		}
+	}
+	return next, nil
+}
+
+type httpPollerInput struct {
+	client   *httpclient.Client
+	initURL  *service.InterpolatedString
+	nextReq  *bloblang.Executor
+	stopCond *bloblang.Executor
+	interval time.Duration
+
+	cursorCache  string
+	cursorKey    string
+	checkpointer *checkpoint.Capped[httpPollerRequest]
+	cursorMut    sync.Mutex
+
+	mgr *service.Resources
+	log *service.Logger
+
+	// The following fields are only accessed by the reading goroutine.
+	req       *httpPollerRequest
+	waitUntil time.Time
+	ended     bool
+}
+
+func newHTTPPollerInputFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (*httpPollerInput, error) {
+	oldConf, err := httpclient.ConfigFromParsed(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &httpPollerInput{
+		initURL:      oldConf.URL,
+		checkpointer: checkpoint.NewCapped[httpPollerRequest](1024),
+		mgr:          mgr,
+		log:          mgr.Logger(),
+	}
+
+	if h.nextReq, err = conf.FieldBloblang(hpiFieldNextRequest); err != nil {
+		return nil, err
+	}
+	if conf.Contains(hpiFieldStopCondition) {
+		if h.stopCond, err = conf.FieldBloblang(hpiFieldStopCondition); err != nil {
+			return nil, err
+		}
+	}
+	if h.interval, err = conf.FieldDuration(hpiFieldInterval); err != nil {
+		return nil, err
+	}
+	if conf.Contains(hpiFieldCursorCache) {
+		if h.cursorCache, err = conf.FieldString(hpiFieldCursorCache); err != nil {
+			return nil, err
+		}
+		if !mgr.HasCache(h.cursorCache) {
+			return nil, fmt.Errorf("cache resource '%v' was not found", h.cursorCache)
+		}
+	}
+	if h.cursorKey, err = conf.FieldString(hpiFieldCursorKey); err != nil {
+		return nil, err
+	}
+
+	var payloadExpr *service.InterpolatedString
+	if payloadStr, _ := conf.FieldString("payload"); payloadStr != "" {
+		if payloadExpr, err = conf.FieldInterpolatedString("payload"); err != nil {
+			return nil, err
+		}
+	}
+
+	if h.client, err = httpclient.NewClientFromOldConfig(oldConf, mgr,
+		httpclient.WithExplicitBody(payloadExpr),
+		httpclient.WithRequestMutator(h.mutateRequest),
+	); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *httpPollerInput) mutateRequest(req *http.Request) error {
+	if h.req == nil {
+		return nil
+	}
+
+	u, err := url.Parse(h.req.URL)
+	if err != nil {
+		return fmt.Errorf("failed to parse url: %w", err)
+	}
+	if req.Host == req.URL.Host {
+		req.Host = u.Host
+	}
+	req.URL = u
+
+	for k, v := range h.req.Headers {
+		req.Header.Set(k, v)
+	}
+	return nil
+}
+
+func (h *httpPollerInput) Connect(ctx context.Context) error {
+	if h.req != nil {
+		return nil
+	}
+
+	if h.cursorCache != "" {
+		var cursorBytes []byte
+		var cErr error
+		if err := h.mgr.AccessCache(ctx, h.cursorCache, func(c service.Cache) {
+			cursorBytes, cErr = c.Get(ctx, h.cursorKey)
+		}); err != nil {
+			return fmt.Errorf("failed to access cursor cache: %w", err)
+		}
+		if cErr == nil {
+			var req httpPollerRequest
+			if err := json.Unmarshal(cursorBytes, &req); err != nil {
+				return fmt.Errorf("failed to parse stored cursor: %w", err)
+			}
+			h.req = &req
+			return nil
+		}
+		if !errors.Is(cErr, service.ErrKeyNotFound) {
+			return fmt.Errorf("failed to obtain stored cursor: %w", cErr)
+		}
+	}
+
+	urlStr, err := h.initURL.TryString(service.NewMessage(nil))
+	if err != nil {
+		return fmt.Errorf("url interpolation error: %w", err)
+	}
+	h.req = &httpPollerRequest{URL: urlStr}
+	return nil
+}
+
+func (h *httpPollerInput) ReadBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
+	if h.req == nil {
+		return nil, nil, service.ErrNotConnected
+	}
+	if h.ended {
+		return nil, nil, service.ErrEndOfInput
+	}
+	if wait := time.Until(h.waitUntil); wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+
+	res, err := h.client.SendToResponse(ctx, nil)
+	if err != nil {
+		if strings.Contains(err.Error(), "(Client.Timeout exceeded while awaiting headers)") {
+			err = component.ErrTimeout
+		}
+		return nil, nil, err
+	}
+
+	batch, err := h.client.ResponseToBatch(res)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resMsg := service.NewMessage(nil)
+	if len(batch) > 0 {
+		resMsg = batch[0].Copy()
+	}
+	for k, values := range res.Header {
+		if len(values) > 0 {
+			resMsg.MetaSetMut(strings.ToLower(k), values[0])
+		}
+	}
+	resMsg.MetaSetMut("http_status_code", res.StatusCode)
+	resMsg.MetaSetMut("http_poller_url", h.req.URL)
+
+	next, wait := h.nextRequest(resMsg)
+	if h.stopCond != nil {
+		stop, err := h.stopConditionMet(resMsg)
+		if err != nil {
+			h.log.Errorf("Stop condition failed: %v", err)
+		}
+		h.ended = stop
+	}
+
+	h.req = &next
+	if wait {
+		h.waitUntil = time.Now().Add(h.interval)
+	}
+
+	release, err := h.checkpointer.Track(ctx, next, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	return batch, func(ctx context.Context, err error) error {
+		return h.commitCursor(ctx, release)
+	}, nil
+}
+
+// nextRequest executes the next_request mapping against a response, and returns
+// the following request along with whether it should be delayed by the
+// interval.
+func (h *httpPollerInput) nextRequest(resMsg *service.Message) (httpPollerRequest, bool) {
+	nextMsg, err := resMsg.BloblangQuery(h.nextReq)
+	if err != nil {
+		h.log.Errorf("Next request mapping failed: %v", err)
+		return *h.req, true
+	}
+	if nextMsg == nil {
+		return *h.req, true
+	}
+
+	v, err := nextMsg.AsStructured()
+	if err != nil {
+		h.log.Errorf("Next request mapping failed: %v", err)
+		return *h.req, true
+	}
+	next, err := h.req.next(v)
+	if err != nil {
+		h.log.Errorf("Next request mapping result was invalid: %v", err)
+		return *h.req, true
+	}
+	return next, false
+}
+
+func (h *httpPollerInput) stopConditionMet(resMsg *service.Message) (bool, error) {
+	resultMsg, err := resMsg.BloblangQuery(h.stopCond)
+	if err != nil {
+		return false, err
+	}
+	if resultMsg == nil {
+		return false, nil
+	}
+	v, err := resultMsg.AsStructured()
+	if err != nil {
+		return false, err
+	}
+	stop, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected a boolean, got %T", v)
+	}
+	return stop, nil
+}
+
+func (h *httpPollerInput) commitCursor(ctx context.Context, release func() *httpPollerRequest) error {
+	h.cursorMut.Lock()
+	defer h.cursorMut.Unlock()
+
+	highest := release()
+	if highest == nil || h.cursorCache == "" {
+		return nil
+	}
+
+	cursorBytes, err := json.Marshal(highest)
+	if err != nil {
+		return err
+	}
+
+	var cErr error
+	if err := h.mgr.AccessCache(ctx, h.cursorCache, func(c service.Cache) {
+		cErr = c.Set(ctx, h.cursorKey, cursorBytes, nil)
+	}); err != nil {
+		return err
+	}
+	return cErr
+}
+
+func (h *httpPollerInput) Close(ctx context.Context) error {
+	return h.client.Close(ctx)
+}
@@ -0,0 +1,190 @@
+package io
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+func httpPollerTestInput(t testing.TB, res *service.Resources, confStr string, args ...any) *httpPollerInput {
+	t.Helper()
+
+	conf, err := httpPollerInputSpec().ParseYAML(fmt.Sprintf(confStr, args...), nil)
+	require.NoError(t, err)
+
+	in, err := newHTTPPollerInputFromParsed(conf, res)
+	require.NoError(t, err)
+	require.NoError(t, in.Connect(context.Background()))
+	t.Cleanup(func() {
+		require.NoError(t, in.Close(context.Background()))
+	})
+	return in
+}
+
+func httpPollerTestRead(t testing.TB, in *httpPollerInput) string {
+	t.Helper()
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	batch, ackFn, err := in.ReadBatch(ctx)
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+	require.NoError(t, ackFn(ctx, nil))
+
+	b, err := batch[0].AsBytes()
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestHTTPPollerCursor(t *testing.T) {
+	var reqsMut sync.Mutex
+	var reqs []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqsMut.Lock()
+		reqs = append(reqs, r.URL.RequestURI())
+		reqsMut.Unlock()
+
+		cursor, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+		next := ""
+		if cursor < 2 {
+			next = strconv.Itoa(cursor + 1)
+		}
+		_, _ = fmt.Fprintf(w, `{"page":%v,"next":%q}`, cursor, next)
+	}))
+	defer ts.Close()
+
+	res := service.MockResources(service.MockResourcesOptAddCache("cursors"))
+	conf := `
+url: %v/items?limit=10
+next_request: 'root = if this.next != "" { { "query": { "cursor": this.next } } } else { deleted() }'
+interval: 50ms
+cursor_cache: cursors
+cursor_key: items
+`
+
+	in := httpPollerTestInput(t, res, conf, ts.URL)
+	for i, exp := range []string{
+		`{"page":0,"next":"1"}`,
+		`{"page":1,"next":"2"}`,
+		`{"page":2,"next":""}`,
+		`{"page":2,"next":""}`,
+	} {
+		assert.Equal(t, exp, httpPollerTestRead(t, in), i)
+	}
+
+	reqsMut.Lock()
+	assert.Equal(t, []string{
+		"/items?limit=10",
+		"/items?cursor=1&limit=10",
+		"/items?cursor=2&limit=10",
+		"/items?cursor=2&limit=10",
+	}, reqs)
+	reqsMut.Unlock()
+
+	var cursor []byte
+	require.NoError(t, res.AccessCache(context.Background(), "cursors", func(c service.Cache) {
+		var err error
+		cursor, err = c.Get(context.Background(), "items")
+		require.NoError(t, err)
+	}))
+	assert.JSONEq(t, fmt.Sprintf(`{"url":"%v/items?cursor=2&limit=10"}`, ts.URL), string(cursor))
+
+	// A new input resumes from the stored cursor.
+	in = httpPollerTestInput(t, res, conf, ts.URL)
+	assert.Equal(t, `{"page":2,"next":""}`, httpPollerTestRead(t, in))
+}
+
+func TestHTTPPollerStopCondition(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 2 {
+			w.Header().Set("Link", fmt.Sprintf(`<http://%v/items?page=%v>; rel="next"`, r.Host, page+1))
+			w.Header().Set("X-Next-Token", fmt.Sprintf("t%v", page))
+		}
+		_, _ = fmt.Fprintf(w, "page %v token %v", page, r.Header.Get("X-Token"))
+	}))
+	defer ts.Close()
+
+	in := httpPollerTestInput(t, service.MockResources(), `
+url: %v/items
+next_request: |
+  root.url = @link.re_find_all_submatch("<([^>]+)>").index(0).index(1)
+  root.headers."X-Token" = metadata("x-next-token")
+stop_condition: '!@link.or("").contains("rel=\"next\"")'
+`, ts.URL)
+
+	for _, exp := range []string{"page 0 token ", "page 1 token t0", "page 2 token t1"} {
+		assert.Equal(t, exp, httpPollerTestRead(t, in))
+	}
+
+	_, _, err := in.ReadBatch(context.Background())
+	require.ErrorIs(t, err, service.ErrEndOfInput)
+}
+
+func TestHTTPPollerNextRequest(t *testing.T) {
+	prev := httpPollerRequest{
+		URL:     "http://example.com/foo?a=1&b=2",
+		Headers: map[string]string{"X-Foo": "foo"},
+	}
+
+	for _, test := range []struct {
+		name    string
+		mapping any
+		exp     httpPollerRequest
+		err     string
+	}{
+		{
+			name:    "url",
+			mapping: map[string]any{"url": "http://example.com/bar"},
+			exp:     httpPollerRequest{URL: "http://example.com/bar", Headers: map[string]string{"X-Foo": "foo"}},
+		},
+		{
+			name:    "query",
+			mapping: map[string]any{"query": map[string]any{"a": nil, "b": 3, "c": "foo bar"}},
+			exp:     httpPollerRequest{URL: "http://example.com/foo?b=3&c=foo+bar", Headers: map[string]string{"X-Foo": "foo"}},
+		},
+		{
+			name:    "url and query",
+			mapping: map[string]any{"url": "http://example.com/bar?a=2", "query": map[string]any{"b": "1"}},
+			exp:     httpPollerRequest{URL: "http://example.com/bar?a=2&b=1", Headers: map[string]string{"X-Foo": "foo"}},
+		},
+		{
+			name:    "headers",
+			mapping: map[string]any{"headers": map[string]any{"X-Foo": nil, "X-Bar": "bar"}},
+			exp:     httpPollerRequest{URL: "http://example.com/foo?a=1&b=2", Headers: map[string]string{"X-Bar": "bar"}},
+		},
+		{
+			name:    "not an object",
+			mapping: "nope",
+			err:     "expected an object, got string",
+		},
+		{
+			name:    "unknown field",
+			mapping: map[string]any{"nope": "nope"},
+			err:     "unexpected field nope",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			next, err := prev.next(test.mapping)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, next)
+		})
+	}
+
+	assert.Equal(t, map[string]string{"X-Foo": "foo"}, prev.Headers)
+}
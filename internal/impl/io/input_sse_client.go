@@ -0,0 +1,469 @@
+package io
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/checkpoint"
+
+	"github.com/warpstreamlabs/bento/internal/httpclient"
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	sseFieldReconnectInterval = "reconnect_interval"
+	sseFieldCursorCache       = "cursor_cache"
+	sseFieldCursorKey         = "cursor_key"
+
+	// sseMaxLineSize is the maximum size of a single line of an event stream.
+	sseMaxLineSize = 4 * 1024 * 1024
+)
+
+func sseClientInputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Version("1.14.0").
+		Categories("Network").
+		Summary("Connects to a server and consumes a stream of [Server-Sent Events (SSE)](https://html.spec.whatwg.org/multipage/server-sent-events.html).").
+		Description(`
+Each event results in a message containing the `+"`data`"+` of the event, where multiple data lines are joined with newlines. Comments and events without data are ignored.
+
+When the stream ends or the connection is lost the input reconnects after the `+"`reconnect_interval`"+`, which is replaced by any `+"`retry`"+` interval sent by the server. Reconnection requests include a `+"`Last-Event-ID`"+` header with the ID of the last event received, allowing the server to resume the stream. When the server responds with a status code of 204 the input shuts down.
+
+### Cursor Persistence
+
+When a `+"`cursor_cache`"+` is set the ID of the last event is stored within the cache under the key `+"`cursor_key`"+` once the event, and all events before it, have been acknowledged. When the input starts it resumes from the stored ID, if present, by sending it as the `+"`Last-Event-ID`"+` of the first request.
+
+### Metadata
+
+This input adds the following metadata fields to each message:
+
+`+"```text"+`
+- sse_event
+- sse_id
+- sse_retry
+`+"```"+`
+
+The field `+"`sse_event`"+` is the type of the event, which is `+"`message`"+` unless set by the server. The field `+"`sse_id`"+` is the last event ID, and is only set when the server has sent an ID. The field `+"`sse_retry`"+` is the reconnection interval in milliseconds last sent by the server, and is only set when the server has sent one.
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#bloblang-queries).`).
+		Example(
+			"Resumable Stream",
+			"Consumes a stream of changes where the last event ID is persisted within a Redis cache, so that a restart resumes the stream where it left off.",
+			`
+input:
+  sse_client:
+    url: https://stream.example.com/v1/changes
+    headers:
+      Authorization: "Bearer ${API_TOKEN}"
+    cursor_cache: cursors
+    cursor_key: changes_last_event_id
+
+pipeline:
+  processors:
+    - mapping: |
+        root = this
+        root.event_type = @sse_event
+
+cache_resources:
+  - label: cursors
+    redis:
+      url: redis://localhost:6379
+`,
+		).
+		Field(httpclient.ConfigField("GET", false,
+			service.NewDurationField(sseFieldReconnectInterval).
+				Description("The period to wait before reconnecting once the stream ends, which is replaced by any `retry` interval sent by the server.").
+				Default("3s"),
+			service.NewStringField(sseFieldCursorCache).
+				Description("An optional [cache resource](/docs/components/caches/about) in which to store the ID of the last event once it is delivered, allowing the input to resume from it upon restart.").
+				Optional(),
+			service.NewStringField(sseFieldCursorKey).
+				Description("The key under which the ID of the last event is stored within the `cursor_cache`.").
+				Default("sse_client_last_event_id"),
+		)).
+		Field(service.NewAutoRetryNacksToggleField())
+}
+
+func init() {
+	err := service.RegisterInput(
+		"sse_client", sseClientInputSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Input, error) {
+			rdr, err := newSSEClientInputFromParsed(conf, mgr)
+			if err != nil {
+				return nil, err
+			}
+			return service.AutoRetryNacksToggled(conf, rdr)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// sseEvent is a single event dispatched from an event stream.
+type sseEvent struct {
+	event string
+	data  string
+	id    string
+}
+
+// sseScanner parses events from an event stream according to the
+// interpretation rules of the Server-Sent Events specification.
+type sseScanner struct {
+	scanner *bufio.Scanner
+
+	lastID string
+	retry  time.Duration
+}
+
+func newSSEScanner(r io.Reader, lastID string) *sseScanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), sseMaxLineSize)
+	scanner.Split(sseScanLines)
+	return &sseScanner{
+		scanner: scanner,
+		lastID:  lastID,
+	}
+}
+
+// sseScanLines splits lines ending with either a carriage return, a line feed,
+// or a carriage return followed by a line feed.
+func sseScanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		// Request more data in order to determine whether the carriage return
+		// is followed by a line feed.
+		return 0, nil, nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// next returns the next event of the stream, or io.EOF when the stream ends.
+// Events that are incomplete when the stream ends are discarded.
+func (s *sseScanner) next() (*sseEvent, error) {
+	var eventType string
+	var data strings.Builder
+	var hasData bool
+
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		if line == "" {
+			if !hasData {
+				eventType = ""
+				continue
+			}
+			if eventType == "" {
+				eventType = "message"
+			}
+			return &sseEvent{
+				event: eventType,
+				data:  strings.TrimSuffix(data.String(), "\n"),
+				id:    s.lastID,
+			}, nil
+		}
+		if line[0] == ':' {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				s.lastID = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(value, 10, 64); err == nil {
+				s.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	if err := s.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+//------------------------------------------------------------------------------
+
+type sseClientInput struct {
+	client            *httpclient.Client
+	reconnectInterval time.Duration
+
+	cursorCache  string
+	cursorKey    string
+	checkpointer *checkpoint.Capped[string]
+	cursorMut    sync.Mutex
+
+	mgr *service.Resources
+	log *service.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	stateMut    sync.Mutex
+	loaded      bool
+	lastID      string
+	retry       time.Duration
+	reconnectAt time.Time
+	ended       bool
+	body        io.ReadCloser
+	scanner     *sseScanner
+}
+
+func newSSEClientInputFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (*sseClientInput, error) {
+	oldConf, err := httpclient.ConfigFromParsed(conf)
+	if err != nil {
+		return nil, err
+	}
+	// Timeout should be left at zero as the response is streamed.
+	oldConf.Timeout = 0
+
+	s := &sseClientInput{
+		checkpointer: checkpoint.NewCapped[string](1024),
+		mgr:          mgr,
+		log:          mgr.Logger(),
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	if s.reconnectInterval, err = conf.FieldDuration(sseFieldReconnectInterval); err != nil {
+		return nil, err
+	}
+	if conf.Contains(sseFieldCursorCache) {
+		if s.cursorCache, err = conf.FieldString(sseFieldCursorCache); err != nil {
+			return nil, err
+		}
+		if !mgr.HasCache(s.cursorCache) {
+			return nil, fmt.Errorf("cache resource '%v' was not found", s.cursorCache)
+		}
+	}
+	if s.cursorKey, err = conf.FieldString(sseFieldCursorKey); err != nil {
+		return nil, err
+	}
+
+	if s.client, err = httpclient.NewClientFromOldConfig(oldConf, mgr, httpclient.WithRequestMutator(s.mutateRequest)); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *sseClientInput) mutateRequest(req *http.Request) error {
+	s.stateMut.Lock()
+	lastID := s.lastID
+	s.stateMut.Unlock()
+
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+	return nil
+}
+
+func (s *sseClientInput) loadCursor(ctx context.Context) error {
+	if s.cursorCache == "" {
+		return nil
+	}
+
+	var idBytes []byte
+	var cErr error
+	if err := s.mgr.AccessCache(ctx, s.cursorCache, func(c service.Cache) {
+		idBytes, cErr = c.Get(ctx, s.cursorKey)
+	}); err != nil {
+		return fmt.Errorf("failed to access cursor cache: %w", err)
+	}
+	if cErr != nil {
+		if errors.Is(cErr, service.ErrKeyNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to obtain stored cursor: %w", cErr)
+	}
+
+	s.stateMut.Lock()
+	s.lastID = string(idBytes)
+	s.stateMut.Unlock()
+	return nil
+}
+
+func (s *sseClientInput) Connect(ctx context.Context) error {
+	s.stateMut.Lock()
+	loaded, connected, reconnectAt := s.loaded, s.body != nil || s.ended, s.reconnectAt
+	s.stateMut.Unlock()
+
+	if connected {
+		return nil
+	}
+	if !loaded {
+		if err := s.loadCursor(ctx); err != nil {
+			return err
+		}
+		s.stateMut.Lock()
+		s.loaded = true
+		s.stateMut.Unlock()
+	}
+	if wait := time.Until(reconnectAt); wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	res, err := s.client.SendToResponse(s.ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode == http.StatusNoContent {
+		res.Body.Close()
+		s.log.Info("Server responded with status 204, shutting down")
+
+		s.stateMut.Lock()
+		s.ended = true
+		s.stateMut.Unlock()
+		return nil
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); mediaType != "text/event-stream" {
+		res.Body.Close()
+		return fmt.Errorf("unexpected content type of response: %v", res.Header.Get("Content-Type"))
+	}
+
+	s.stateMut.Lock()
+	s.body = res.Body
+	s.scanner = newSSEScanner(res.Body, s.lastID)
+	s.scanner.retry = s.retry
+	s.stateMut.Unlock()
+	return nil
+}
+
+// disconnect closes the current stream, scheduling the next connection attempt
+// after the reconnection interval.
+func (s *sseClientInput) disconnect() {
+	s.stateMut.Lock()
+	defer s.stateMut.Unlock()
+
+	if s.body != nil {
+		_ = s.body.Close()
+		s.body, s.scanner = nil, nil
+	}
+
+	interval := s.reconnectInterval
+	if s.retry > 0 {
+		interval = s.retry
+	}
+	s.reconnectAt = time.Now().Add(interval)
+}
+
+func (s *sseClientInput) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
+	s.stateMut.Lock()
+	scanner, ended := s.scanner, s.ended
+	s.stateMut.Unlock()
+
+	if ended {
+		return nil, nil, service.ErrEndOfInput
+	}
+	if scanner == nil {
+		return nil, nil, service.ErrNotConnected
+	}
+
+	event, err := scanner.next()
+
+	s.stateMut.Lock()
+	s.lastID, s.retry = scanner.lastID, scanner.retry
+	s.stateMut.Unlock()
+
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			s.log.Debug("Event stream ended, reconnecting")
+		} else if s.ctx.Err() == nil {
+			s.log.Errorf("Failed to read event stream: %v", err)
+		}
+		s.disconnect()
+		return nil, nil, service.ErrNotConnected
+	}
+
+	msg := service.NewMessage([]byte(event.data))
+	msg.MetaSetMut("sse_event", event.event)
+	if event.id != "" {
+		msg.MetaSetMut("sse_id", event.id)
+	}
+	if scanner.retry > 0 {
+		msg.MetaSetMut("sse_retry", scanner.retry.Milliseconds())
+	}
+
+	release, err := s.checkpointer.Track(ctx, event.id, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	return msg, func(ctx context.Context, err error) error {
+		return s.commitCursor(ctx, release)
+	}, nil
+}
+
+func (s *sseClientInput) commitCursor(ctx context.Context, release func() *string) error {
+	s.cursorMut.Lock()
+	defer s.cursorMut.Unlock()
+
+	highest := release()
+	if highest == nil || *highest == "" || s.cursorCache == "" {
+		return nil
+	}
+
+	var cErr error
+	if err := s.mgr.AccessCache(ctx, s.cursorCache, func(c service.Cache) {
+		cErr = c.Set(ctx, s.cursorKey, []byte(*highest), nil)
+	}); err != nil {
+		return err
+	}
+	return cErr
+}
+
+func (s *sseClientInput) Close(ctx context.Context) error {
+	s.cancel()
+
+	s.stateMut.Lock()
+	if s.body != nil {
+		_ = s.body.Close()
+		s.body, s.scanner = nil, nil
+	}
+	s.stateMut.Unlock()
+
+	return s.client.Close(ctx)
+}
@@ -0,0 +1,177 @@
+package io
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+func TestSSEScanner(t *testing.T) {
+	stream := ": a comment\n" +
+		"data: first\n\n" +
+		"event: update\r\n" +
+		"data:second\r\n" +
+		"data:  line\r\n" +
+		"id: 2\r\n\r\n" +
+		"retry: 1500\rid\r\r" +
+		"id: 3\n" +
+		"retry: nope\n" +
+		"data\n\n" +
+		"data: incomplete"
+
+	scanner := newSSEScanner(strings.NewReader(stream), "1")
+
+	var events []sseEvent
+	for {
+		e, err := scanner.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		events = append(events, *e)
+	}
+
+	assert.Equal(t, []sseEvent{
+		{event: "message", data: "first", id: "1"},
+		{event: "update", data: "second\n line", id: "2"},
+		{event: "message", data: "", id: "3"},
+	}, events)
+	assert.Equal(t, 1500*time.Millisecond, scanner.retry)
+}
+
+func TestSSEClientInputResume(t *testing.T) {
+	var reqsMut sync.Mutex
+	var lastIDs []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+
+		reqsMut.Lock()
+		lastIDs = append(lastIDs, r.Header.Get("Last-Event-ID"))
+		reqsMut.Unlock()
+
+		if r.Header.Get("Last-Event-ID") == "5" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		var start int
+		_, _ = fmt.Sscanf(r.Header.Get("Last-Event-ID"), "%d", &start)
+
+		w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+		_, _ = fmt.Fprint(w, "retry: 10\n\n")
+		for i := start + 1; i <= start+2; i++ {
+			_, _ = fmt.Fprintf(w, "event: tick\nid: %v\ndata: {\"n\":%v}\n\n", i, i)
+		}
+	}))
+	defer ts.Close()
+
+	res := service.MockResources(service.MockResourcesOptAddCache("cursors"))
+	conf, err := sseClientInputSpec().ParseYAML(fmt.Sprintf(`
+url: %v/events
+reconnect_interval: 1h
+cursor_cache: cursors
+`, ts.URL), nil)
+	require.NoError(t, err)
+
+	newInput := func() *sseClientInput {
+		in, err := newSSEClientInputFromParsed(conf, res)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, in.Close(context.Background()))
+		})
+		return in
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	readAll := func(in *sseClientInput, n int) (results []string) {
+		for len(results) < n {
+			if err := in.Connect(ctx); err != nil {
+				require.NoError(t, err)
+			}
+			msg, ackFn, err := in.Read(ctx)
+			if errors.Is(err, service.ErrNotConnected) {
+				continue
+			}
+			require.NoError(t, err)
+			require.NoError(t, ackFn(ctx, nil))
+
+			b, err := msg.AsBytes()
+			require.NoError(t, err)
+			event, _ := msg.MetaGetMut("sse_event")
+			id, _ := msg.MetaGetMut("sse_id")
+			retry, _ := msg.MetaGetMut("sse_retry")
+			results = append(results, fmt.Sprintf("%v %v %v %s", event, id, retry, b))
+		}
+		return
+	}
+
+	// The server retry interval replaces the configured one when reconnecting.
+	in := newInput()
+	assert.Equal(t, []string{
+		`tick 1 10 {"n":1}`,
+		`tick 2 10 {"n":2}`,
+		`tick 3 10 {"n":3}`,
+	}, readAll(in, 3))
+	require.NoError(t, in.Close(ctx))
+
+	var stored []byte
+	require.NoError(t, res.AccessCache(ctx, "cursors", func(c service.Cache) {
+		stored, err = c.Get(ctx, "sse_client_last_event_id")
+		require.NoError(t, err)
+	}))
+	assert.Equal(t, "3", string(stored))
+
+	// A new input resumes from the stored ID, and shuts down once the server
+	// responds with no content.
+	in = newInput()
+	assert.Equal(t, []string{
+		`tick 4 10 {"n":4}`,
+		`tick 5 10 {"n":5}`,
+	}, readAll(in, 2))
+
+	require.NoError(t, in.Connect(ctx))
+	_, _, err = in.Read(ctx)
+	for errors.Is(err, service.ErrNotConnected) {
+		require.NoError(t, in.Connect(ctx))
+		_, _, err = in.Read(ctx)
+	}
+	require.ErrorIs(t, err, service.ErrEndOfInput)
+
+	reqsMut.Lock()
+	assert.Equal(t, []string{"", "2", "3", "5"}, lastIDs)
+	reqsMut.Unlock()
+}
+
+func TestSSEClientInputBadContentType(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	conf, err := sseClientInputSpec().ParseYAML(fmt.Sprintf(`url: %v`, ts.URL), nil)
+	require.NoError(t, err)
+
+	in, err := newSSEClientInputFromParsed(conf, service.MockResources())
+	require.NoError(t, err)
+
+	err = in.Connect(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected content type")
+	require.NoError(t, in.Close(context.Background()))
+}
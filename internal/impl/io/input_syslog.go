@@ -0,0 +1,630 @@
+package io
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/shutdown"
+	syslog "github.com/influxdata/go-syslog/v3"
+	"github.com/influxdata/go-syslog/v3/rfc3164"
+	"github.com/influxdata/go-syslog/v3/rfc5424"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	sysiFieldNetwork         = "network"
+	sysiFieldAddress         = "address"
+	sysiFieldAddressCache    = "address_cache"
+	sysiFieldFormat          = "format"
+	sysiFieldFraming         = "framing"
+	sysiFieldParse           = "parse"
+	sysiFieldBestEffort      = "best_effort"
+	sysiFieldAllowRFC3339    = "allow_rfc3339"
+	sysiFieldDefaultYear     = "default_year"
+	sysiFieldDefaultTimezone = "default_timezone"
+	sysiFieldMaxMessageSize  = "max_message_size"
+	sysiFieldTLS             = "tls"
+)
+
+func syslogInputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Version("1.14.0").
+		Summary(`Creates a server that receives syslog messages over UDP, TCP or TLS.`).
+		Categories("Network").
+		Description(`
+Messages following either the [rfc5424](https://tools.ietf.org/html/rfc5424) or [rfc3164](https://tools.ietf.org/html/rfc3164) formats are accepted. Over UDP each datagram is a single message, and over TCP and TLS messages are framed according to [rfc6587](https://tools.ietf.org/html/rfc6587), either with octet counting or newline delimited (non-transparent framing).
+
+When `+"`parse`"+` is enabled (the default) each message is parsed into a structured document following the same structure as the [`+"`parse_log`"+` processor](/docs/components/processors/parse_log), including any structured data elements. Messages that fail to parse are passed through unchanged, flagged with the error, and can be handled with [error handling patterns](/docs/configuration/error_handling).
+
+### Metadata
+
+This input adds the following metadata fields to each message, where fields that aren't present within the syslog message are omitted:
+
+`+"```text"+`
+- syslog_client_address
+- syslog_priority
+- syslog_facility
+- syslog_severity
+- syslog_version
+- syslog_timestamp
+- syslog_hostname
+- syslog_appname
+- syslog_procid
+- syslog_msgid
+`+"```"+`
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#bloblang-queries).
+`).
+		Fields(
+			service.NewStringEnumField(sysiFieldNetwork, "udp", "tcp", "tls").
+				Description("The network type to accept.").
+				Default("udp"),
+			service.NewStringField(sysiFieldAddress).
+				Description("The address to listen from.").
+				Examples("0.0.0.0:514", "0.0.0.0:6514"),
+			service.NewStringField(sysiFieldAddressCache).
+				Description("An optional [`cache`](/docs/components/caches/about) within which this input should write it's bound address once known. The key of the cache item containing the address will be the label of the component suffixed with `_address` (e.g. `foo_address`), or `syslog_address` when a label has not been provided.").
+				Optional().
+				Advanced(),
+			service.NewStringAnnotatedEnumField(sysiFieldFormat, map[string]string{
+				"rfc5424": "Messages follow the rfc5424 format.",
+				"rfc3164": "Messages follow the rfc3164 format.",
+				"auto":    "The format is detected for each message by the presence of a version following the priority.",
+			}).
+				Description("The syslog format of received messages.").
+				Default("rfc5424"),
+			service.NewStringAnnotatedEnumField(sysiFieldFraming, map[string]string{
+				"auto":            "The framing is detected for each message, where messages beginning with a digit are octet counted and all other messages are newline delimited.",
+				"octet_counting":  "Each message is prefixed with its length in bytes followed by a space.",
+				"non_transparent": "Messages are delimited by a newline.",
+			}).
+				Description("The framing of messages received over TCP and TLS connections.").
+				Default("auto"),
+			service.NewBoolField(sysiFieldParse).
+				Description("Whether to parse messages into structured documents. When disabled messages contain the raw syslog line, and metadata is still added.").
+				Default(true),
+			service.NewBoolField(sysiFieldBestEffort).
+				Description("Whether to keep partially parsed messages rather than flag them with an error when they are not fully compliant.").
+				Advanced().
+				Default(true),
+			service.NewBoolField(sysiFieldAllowRFC3339).
+				Description("Also accept timestamps in rfc3339 format while parsing. Applicable to format `rfc3164`.").
+				Advanced().
+				Default(true),
+			service.NewStringField(sysiFieldDefaultYear).
+				Description("Sets the strategy used to set the year for rfc3164 timestamps. When set to `current` the current year will be set, when set to an integer that value will be used. Leave this field empty to not set a default year at all.").
+				Advanced().
+				Default("current"),
+			service.NewStringField(sysiFieldDefaultTimezone).
+				Description("Sets the strategy to decide the timezone for rfc3164 timestamps. This value should follow the [time.LoadLocation](https://golang.org/pkg/time/#LoadLocation) format.").
+				Advanced().
+				Default("UTC"),
+			service.NewIntField(sysiFieldMaxMessageSize).
+				Description("The maximum size in bytes of a received message, messages exceeding this size cause the connection to be closed.").
+				Advanced().
+				Default(65536),
+			service.NewObjectField(sysiFieldTLS,
+				service.NewStringField(issFieldTLSCertFile).
+					Description("PEM encoded certificate for use with TLS.").
+					Optional(),
+				service.NewStringField(issFieldTLSKeyFile).
+					Description("PEM encoded private key for use with TLS.").
+					Optional(),
+				service.NewBoolField(issFieldTLSSelfSigned).
+					Description("Whether to generate self signed certificates.").
+					Default(false),
+			).
+				Description("TLS specific configuration, valid when the `network` is set to `tls`.").
+				Optional(),
+			service.NewAutoRetryNacksToggleField(),
+		).
+		Example("Syslog Aggregation", "Receive rfc5424 syslog messages over TLS and route them by severity.", `
+input:
+  syslog:
+    network: tls
+    address: 0.0.0.0:6514
+    tls:
+      cert_file: ./server.crt
+      key_file: ./server.key
+
+output:
+  switch:
+    cases:
+      - check: '@syslog_severity <= 3'
+        output:
+          file:
+            path: ./errors.jsonl
+      - output:
+          file:
+            path: ./logs.jsonl
+`)
+}
+
+func init() {
+	err := service.RegisterInput("syslog", syslogInputSpec(), func(conf *service.ParsedConfig, mgr *service.Resources) (service.Input, error) {
+		i, err := newSyslogInputFromParsed(conf, mgr)
+		if err != nil {
+			return nil, err
+		}
+		return service.AutoRetryNacksToggled(conf, i)
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type syslogInput struct {
+	log *service.Logger
+	mgr *service.Resources
+
+	network        string
+	address        string
+	addressCache   string
+	format         string
+	framing        string
+	parse          bool
+	bestEffort     bool
+	maxMessageSize int
+	tlsCert        string
+	tlsKey         string
+	tlsSelfSigned  bool
+
+	rfc5424Opts []syslog.MachineOption
+	rfc3164Opts []syslog.MachineOption
+
+	messages chan *service.Message
+	shutSig  *shutdown.Signaller
+}
+
+func newSyslogInputFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (s *syslogInput, err error) {
+	s = &syslogInput{
+		log:      mgr.Logger(),
+		mgr:      mgr,
+		shutSig:  shutdown.NewSignaller(),
+		messages: make(chan *service.Message),
+	}
+
+	if s.network, err = conf.FieldString(sysiFieldNetwork); err != nil {
+		return nil, err
+	}
+	if s.address, err = conf.FieldString(sysiFieldAddress); err != nil {
+		return nil, err
+	}
+	s.addressCache, _ = conf.FieldString(sysiFieldAddressCache)
+	if s.format, err = conf.FieldString(sysiFieldFormat); err != nil {
+		return nil, err
+	}
+	if s.framing, err = conf.FieldString(sysiFieldFraming); err != nil {
+		return nil, err
+	}
+	if s.parse, err = conf.FieldBool(sysiFieldParse); err != nil {
+		return nil, err
+	}
+	if s.bestEffort, err = conf.FieldBool(sysiFieldBestEffort); err != nil {
+		return nil, err
+	}
+	if s.maxMessageSize, err = conf.FieldInt(sysiFieldMaxMessageSize); err != nil {
+		return nil, err
+	}
+	if s.maxMessageSize <= 0 {
+		return nil, fmt.Errorf("%v must be greater than zero", sysiFieldMaxMessageSize)
+	}
+
+	var rfc3164Opts []syslog.MachineOption
+	if s.bestEffort {
+		s.rfc5424Opts = append(s.rfc5424Opts, rfc5424.WithBestEffort())
+		rfc3164Opts = append(rfc3164Opts, rfc3164.WithBestEffort())
+	}
+
+	allowRFC3339, err := conf.FieldBool(sysiFieldAllowRFC3339)
+	if err != nil {
+		return nil, err
+	}
+	if allowRFC3339 {
+		rfc3164Opts = append(rfc3164Opts, rfc3164.WithRFC3339())
+	}
+
+	defaultYear, err := conf.FieldString(sysiFieldDefaultYear)
+	if err != nil {
+		return nil, err
+	}
+	switch defaultYear {
+	case "current":
+		rfc3164Opts = append(rfc3164Opts, rfc3164.WithYear(rfc3164.CurrentYear{}))
+	case "":
+	default:
+		iYear, err := strconv.Atoi(defaultYear)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert year %s into integer: %v", defaultYear, err)
+		}
+		rfc3164Opts = append(rfc3164Opts, rfc3164.WithYear(rfc3164.Year{YYYY: iYear}))
+	}
+
+	defaultTZ, err := conf.FieldString(sysiFieldDefaultTimezone)
+	if err != nil {
+		return nil, err
+	}
+	if defaultTZ != "" {
+		loc, err := time.LoadLocation(defaultTZ)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lookup timezone %s: %v", defaultTZ, err)
+		}
+		rfc3164Opts = append(rfc3164Opts, rfc3164.WithTimezone(loc))
+	}
+	s.rfc3164Opts = rfc3164Opts
+
+	tlsConf := conf.Namespace(sysiFieldTLS)
+	s.tlsCert, _ = tlsConf.FieldString(issFieldTLSCertFile)
+	s.tlsKey, _ = tlsConf.FieldString(issFieldTLSKeyFile)
+	s.tlsSelfSigned, _ = tlsConf.FieldBool(issFieldTLSSelfSigned)
+	return s, nil
+}
+
+func (s *syslogInput) Connect(ctx context.Context) error {
+	var ln net.Listener
+	var cn net.PacketConn
+
+	var err error
+	switch s.network {
+	case "tcp":
+		ln, err = net.Listen("tcp", s.address)
+	case "tls":
+		var cert tls.Certificate
+		if cert, err = loadOrCreateCertificate(s.tlsCert, s.tlsKey, s.tlsSelfSigned); err != nil {
+			return err
+		}
+		ln, err = tls.Listen("tcp", s.address, &tls.Config{
+			Certificates: []tls.Certificate{cert},
+		})
+	case "udp":
+		cn, err = net.ListenPacket("udp", s.address)
+	default:
+		return fmt.Errorf("network '%v' is not supported by this input", s.network)
+	}
+	if err != nil {
+		return err
+	}
+
+	var addr net.Addr
+	if ln != nil {
+		addr = ln.Addr()
+		go s.loop(ln)
+	} else {
+		addr = cn.LocalAddr()
+		go s.udpLoop(cn)
+	}
+	s.log.Infof("Receiving %v syslog messages from address: %v", s.network, addr.String())
+
+	if s.addressCache != "" {
+		key := "syslog_address"
+		if l := s.mgr.Label(); l != "" {
+			key = l + "_address"
+		}
+		_ = s.mgr.AccessCache(ctx, s.addressCache, func(c service.Cache) {
+			if err := c.Set(ctx, key, []byte(addr.String()), nil); err != nil {
+				s.log.Errorf("Failed to set address in cache: %v", err)
+			}
+		})
+	}
+	return nil
+}
+
+func (s *syslogInput) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
+	select {
+	case m, open := <-s.messages:
+		if open {
+			return m, func(ctx context.Context, err error) error {
+				return nil
+			}, nil
+		}
+		return nil, nil, service.ErrEndOfInput
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+func (s *syslogInput) loop(listener net.Listener) {
+	var wg sync.WaitGroup
+
+	defer func() {
+		wg.Wait()
+		_ = listener.Close()
+		close(s.messages)
+		s.shutSig.TriggerHasStopped()
+	}()
+
+	go func() {
+		<-s.shutSig.SoftStopChan()
+		_ = listener.Close()
+	}()
+
+acceptLoop:
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.log.Errorf("Failed to accept syslog connection: %v", err)
+			}
+			select {
+			case <-time.After(time.Second):
+				continue acceptLoop
+			case <-s.shutSig.SoftStopChan():
+				return
+			}
+		}
+
+		go func() {
+			<-s.shutSig.SoftStopChan()
+			_ = conn.Close()
+		}()
+
+		wg.Add(1)
+		go func(c net.Conn) {
+			defer func() {
+				_ = c.Close()
+				wg.Done()
+			}()
+
+			parsers := s.newParsers()
+			r := bufio.NewReader(c)
+			for {
+				frame, err := s.readFrame(r)
+				if err != nil {
+					if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+						s.log.Errorf("Connection dropped due to: %v", err)
+					}
+					return
+				}
+				if len(frame) == 0 {
+					continue
+				}
+
+				select {
+				case s.messages <- s.newMessage(parsers, frame, c.RemoteAddr()):
+				case <-s.shutSig.SoftStopChan():
+					return
+				}
+			}
+		}(conn)
+	}
+}
+
+// readFrame reads a single message from a stream according to rfc6587, where
+// an empty frame is returned for empty lines.
+func (s *syslogInput) readFrame(r *bufio.Reader) ([]byte, error) {
+	first, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	octetCounted := s.framing == "octet_counting" ||
+		(s.framing == "auto" && first[0] >= '0' && first[0] <= '9')
+	if octetCounted {
+		lenStr, err := r.ReadString(' ')
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(lenStr, " "))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid message length: %q", lenStr)
+		}
+		if n > s.maxMessageSize {
+			return nil, fmt.Errorf("message length %v exceeds the maximum of %v", n, s.maxMessageSize)
+		}
+		frame := make([]byte, n)
+		if _, err := io.ReadFull(r, frame); err != nil {
+			return nil, err
+		}
+		return frame, nil
+	}
+
+	var frame []byte
+	for {
+		line, err := r.ReadSlice('\n')
+		frame = append(frame, line...)
+		if len(frame) > s.maxMessageSize+2 {
+			return nil, fmt.Errorf("message exceeds the maximum length of %v", s.maxMessageSize)
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) && len(frame) > 0 {
+				break
+			}
+			return nil, err
+		}
+		break
+	}
+	return bytes.TrimRight(frame, "\r\n"), nil
+}
+
+func (s *syslogInput) udpLoop(conn net.PacketConn) {
+	defer func() {
+		_ = conn.Close()
+		close(s.messages)
+		s.shutSig.TriggerHasStopped()
+	}()
+
+	go func() {
+		<-s.shutSig.SoftStopChan()
+		_ = conn.Close()
+	}()
+
+	parsers := s.newParsers()
+	buf := make([]byte, s.maxMessageSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.log.Errorf("Connection dropped due to: %v", err)
+			}
+			return
+		}
+
+		frame := bytes.TrimRight(buf[:n], "\r\n\x00")
+		if len(frame) == 0 {
+			continue
+		}
+
+		select {
+		case s.messages <- s.newMessage(parsers, bytes.Clone(frame), addr):
+		case <-s.shutSig.SoftStopChan():
+			return
+		}
+	}
+}
+
+func (s *syslogInput) Close(ctx context.Context) error {
+	s.shutSig.TriggerSoftStop()
+	select {
+	case <-s.shutSig.HasStoppedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// isRFC5424 returns true if a message has a version following the priority,
+// which is the distinguishing feature of the rfc5424 format.
+func isRFC5424(frame []byte) bool {
+	end := bytes.IndexByte(frame, '>')
+	if end < 0 || end+2 >= len(frame) {
+		return false
+	}
+	i := end + 1
+	for i < len(frame) && frame[i] >= '0' && frame[i] <= '9' {
+		i++
+	}
+	return i > end+1 && i < len(frame) && frame[i] == ' '
+}
+
+// syslogParsers holds parsers for each format, which hold state whilst parsing
+// and therefore must not be shared between connections.
+type syslogParsers struct {
+	rfc5424 syslog.Machine
+	rfc3164 syslog.Machine
+}
+
+func (s *syslogInput) newParsers() *syslogParsers {
+	return &syslogParsers{
+		rfc5424: rfc5424.NewParser(s.rfc5424Opts...),
+		rfc3164: rfc3164.NewParser(s.rfc3164Opts...),
+	}
+}
+
+func (s *syslogInput) newMessage(parsers *syslogParsers, frame []byte, addr net.Addr) *service.Message {
+	var msg *service.Message
+	if s.parse {
+		msg = service.NewMessage(nil)
+	} else {
+		msg = service.NewMessage(frame)
+	}
+	if addr != nil {
+		msg.MetaSetMut("syslog_client_address", addr.String())
+	}
+
+	machine := parsers.rfc5424
+	if s.format == "rfc3164" || (s.format == "auto" && !isRFC5424(frame)) {
+		machine = parsers.rfc3164
+	}
+
+	res, err := machine.Parse(frame)
+	if res == nil || (err != nil && !s.bestEffort) {
+		if err == nil {
+			err = errors.New("failed to parse syslog message")
+		}
+		if s.parse {
+			msg.SetBytes(frame)
+		}
+		msg.SetError(err)
+		return msg
+	}
+
+	var base *syslog.Base
+	var structured map[string]any
+	switch t := res.(type) {
+	case *rfc5424.SyslogMessage:
+		base = &t.Base
+		structured = syslogBaseToMap(base)
+		if t.Version != 0 {
+			structured["version"] = int64(t.Version)
+			msg.MetaSetMut("syslog_version", int64(t.Version))
+		}
+		if t.StructuredData != nil {
+			sd := make(map[string]any, len(*t.StructuredData))
+			for id, params := range *t.StructuredData {
+				elements := make(map[string]any, len(params))
+				for k, v := range params {
+					elements[k] = v
+				}
+				sd[id] = elements
+			}
+			structured["structureddata"] = sd
+		}
+	case *rfc3164.SyslogMessage:
+		base = &t.Base
+		structured = syslogBaseToMap(base)
+	default:
+		msg.SetError(fmt.Errorf("unexpected syslog message type: %T", res))
+		return msg
+	}
+
+	for k, v := range structured {
+		switch k {
+		case "message", "structureddata", "version":
+		default:
+			msg.MetaSetMut("syslog_"+k, v)
+		}
+	}
+	if s.parse {
+		msg.SetStructuredMut(structured)
+	}
+	return msg
+}
+
+func syslogBaseToMap(b *syslog.Base) map[string]any {
+	res := map[string]any{}
+	if b.Message != nil {
+		res["message"] = *b.Message
+	}
+	if b.Timestamp != nil {
+		res["timestamp"] = b.Timestamp.Format(time.RFC3339Nano)
+	}
+	if b.Facility != nil {
+		res["facility"] = int64(*b.Facility)
+	}
+	if b.Severity != nil {
+		res["severity"] = int64(*b.Severity)
+	}
+	if b.Priority != nil {
+		res["priority"] = int64(*b.Priority)
+	}
+	if b.Hostname != nil {
+		res["hostname"] = *b.Hostname
+	}
+	if b.ProcID != nil {
+		res["procid"] = *b.ProcID
+	}
+	if b.Appname != nil {
+		res["appname"] = *b.Appname
+	}
+	if b.MsgID != nil {
+		res["msgid"] = *b.MsgID
+	}
+	return res
+}
@@ -0,0 +1,237 @@
+package io_test
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/component/cache"
+	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/component/testutil"
+	"github.com/warpstreamlabs/bento/internal/manager/mock"
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+func syslogInputFromConf(t testing.TB, confStr string) (input.Streamed, string) {
+	t.Helper()
+
+	mgr := mock.NewManager()
+	mgr.Caches["testcache"] = map[string]mock.CacheItem{}
+
+	conf, err := testutil.InputFromYAML(confStr + "\n  address_cache: testcache")
+	require.NoError(t, err)
+
+	s, err := mgr.NewInput(conf)
+	require.NoError(t, err)
+
+	addr := ""
+	require.Eventually(t, func() bool {
+		_ = mgr.AccessCache(context.Background(), "testcache", func(v cache.V1) {
+			res, _ := v.Get(context.Background(), "syslog_address")
+			addr = string(res)
+		})
+		return addr != ""
+	}, time.Second, time.Millisecond*10)
+
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+		defer done()
+		s.TriggerStopConsuming()
+		assert.NoError(t, s.WaitForClose(ctx))
+	})
+	return s, addr
+}
+
+func readSyslogMsg(t testing.TB, rdr input.Streamed) *message.Part {
+	t.Helper()
+
+	select {
+	case tran := <-rdr.TransactionChan():
+		require.NoError(t, tran.Ack(context.Background(), nil))
+		require.Equal(t, 1, tran.Payload.Len())
+		return tran.Payload.Get(0)
+	case <-time.After(time.Second * 5):
+		t.Fatal(errors.New("timed out"))
+	}
+	return nil
+}
+
+func TestSyslogUDP(t *testing.T) {
+	rdr, addr := syslogInputFromConf(t, `
+syslog:
+  network: udp
+  address: 127.0.0.1:0
+`)
+
+	conn, err := net.Dial("udp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(`<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="Application"] An application event` + "\n"))
+	require.NoError(t, err)
+
+	p := readSyslogMsg(t, rdr)
+	require.NoError(t, p.ErrorGet())
+
+	v, err := p.AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"appname":   "evntslog",
+		"facility":  int64(20),
+		"hostname":  "mymachine.example.com",
+		"message":   "An application event",
+		"msgid":     "ID47",
+		"priority":  int64(165),
+		"severity":  int64(5),
+		"timestamp": "2003-10-11T22:14:15.003Z",
+		"version":   int64(1),
+		"structureddata": map[string]any{
+			"exampleSDID@32473": map[string]any{
+				"iut":         "3",
+				"eventSource": "Application",
+			},
+		},
+	}, v)
+
+	assert.Equal(t, conn.LocalAddr().String(), p.MetaGetStr("syslog_client_address"))
+	assert.Equal(t, int64(20), syslogMeta(p, "syslog_facility"))
+	assert.Equal(t, int64(5), syslogMeta(p, "syslog_severity"))
+	assert.Equal(t, int64(165), syslogMeta(p, "syslog_priority"))
+	assert.Equal(t, int64(1), syslogMeta(p, "syslog_version"))
+	assert.Equal(t, "mymachine.example.com", p.MetaGetStr("syslog_hostname"))
+	assert.Equal(t, "evntslog", p.MetaGetStr("syslog_appname"))
+	assert.Equal(t, "ID47", p.MetaGetStr("syslog_msgid"))
+	assert.Equal(t, "2003-10-11T22:14:15.003Z", p.MetaGetStr("syslog_timestamp"))
+	assert.Nil(t, syslogMeta(p, "syslog_procid"))
+}
+
+func TestSyslogTCPFraming(t *testing.T) {
+	rdr, addr := syslogInputFromConf(t, `
+syslog:
+  network: tcp
+  address: 127.0.0.1:0
+  format: auto
+  parse: false
+`)
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	msgs := []string{
+		"<34>1 2003-10-11T22:14:15.003Z host su - - - first\nwith a newline",
+		"<13>Oct 11 22:14:15 host app[42]: second",
+		"<14>1 - host app - - - third",
+	}
+
+	_ = conn.SetWriteDeadline(time.Now().Add(time.Second * 5))
+	_, err = fmt.Fprintf(conn, "%d %s", len(msgs[0]), msgs[0])
+	require.NoError(t, err)
+	_, err = fmt.Fprintf(conn, "%s\r\n\n", msgs[1])
+	require.NoError(t, err)
+	_, err = fmt.Fprintf(conn, "%d %s", len(msgs[2]), msgs[2])
+	require.NoError(t, err)
+
+	p := readSyslogMsg(t, rdr)
+	assert.Equal(t, msgs[0], string(p.AsBytes()))
+	assert.Equal(t, int64(4), syslogMeta(p, "syslog_facility"))
+	assert.Equal(t, int64(2), syslogMeta(p, "syslog_severity"))
+	assert.Equal(t, "su", p.MetaGetStr("syslog_appname"))
+
+	p = readSyslogMsg(t, rdr)
+	assert.Equal(t, msgs[1], string(p.AsBytes()))
+	assert.Equal(t, int64(1), syslogMeta(p, "syslog_facility"))
+	assert.Equal(t, int64(5), syslogMeta(p, "syslog_severity"))
+	assert.Equal(t, "app", p.MetaGetStr("syslog_appname"))
+	assert.Equal(t, "42", p.MetaGetStr("syslog_procid"))
+	assert.Nil(t, syslogMeta(p, "syslog_version"))
+
+	p = readSyslogMsg(t, rdr)
+	assert.Equal(t, msgs[2], string(p.AsBytes()))
+	assert.Equal(t, int64(14), syslogMeta(p, "syslog_priority"))
+	assert.Equal(t, "host", p.MetaGetStr("syslog_hostname"))
+}
+
+func TestSyslogTLS(t *testing.T) {
+	rdr, addr := syslogInputFromConf(t, `
+syslog:
+  network: tls
+  address: 127.0.0.1:0
+  framing: non_transparent
+  tls:
+    self_signed: true
+`)
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("<165>1 2003-10-11T22:14:15.003Z host app - - - hello world\n"))
+	require.NoError(t, err)
+
+	p := readSyslogMsg(t, rdr)
+	v, err := p.AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", v.(map[string]any)["message"])
+	assert.Equal(t, "app", p.MetaGetStr("syslog_appname"))
+}
+
+func TestSyslogParseError(t *testing.T) {
+	rdr, addr := syslogInputFromConf(t, `
+syslog:
+  network: udp
+  address: 127.0.0.1:0
+  best_effort: false
+`)
+
+	conn, err := net.Dial("udp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("not a syslog message"))
+	require.NoError(t, err)
+
+	p := readSyslogMsg(t, rdr)
+	require.Error(t, p.ErrorGet())
+	assert.Equal(t, "not a syslog message", string(p.AsBytes()))
+	assert.Equal(t, conn.LocalAddr().String(), p.MetaGetStr("syslog_client_address"))
+}
+
+func TestSyslogMessageTooLarge(t *testing.T) {
+	rdr, addr := syslogInputFromConf(t, `
+syslog:
+  network: tcp
+  address: 127.0.0.1:0
+  max_message_size: 10
+`)
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("100 <13>1 - - - - - -"))
+	require.NoError(t, err)
+
+	// The connection is closed by the server.
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	_, err = conn.Read(make([]byte, 1))
+	require.Error(t, err)
+
+	select {
+	case <-rdr.TransactionChan():
+		t.Fatal("unexpected message")
+	case <-time.After(time.Millisecond * 100):
+	}
+}
+
+func syslogMeta(p *message.Part, key string) any {
+	v, _ := p.MetaGetMut(key)
+	return v
+}
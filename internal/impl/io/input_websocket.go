@@ -102,50 +102,102 @@ func init() {
 	}
 }
 
-type websocketReader struct {
-	log log.Modular
-	mgr bundle.NewManagement
+// wsDialer establishes websocket connections using the common connection
+// fields of websocket based inputs.
+type wsDialer struct {
+	fs fs.FS
 
-	lock *sync.Mutex
-
-	client         *websocket.Conn
 	urlParsed      *url.URL
 	urlStr         string
 	proxyURLParsed *url.URL
 	tlsEnabled     bool
 	tlsConf        *tls.Config
 	reqSigner      func(f fs.FS, req *http.Request) error
-
-	openMsgType wsOpenMsgType
-	openMsg     [][]byte
-	headers     map[string]*service.InterpolatedString
+	headers        map[string]*service.InterpolatedString
+	subprotocols   []string
 }
 
-func newWebsocketReaderFromParsed(conf *service.ParsedConfig, mgr bundle.NewManagement) (*websocketReader, error) {
-	ws := &websocketReader{
-		log:  mgr.Logger(),
-		mgr:  mgr,
-		lock: &sync.Mutex{},
+func wsDialerFromParsed(conf *service.ParsedConfig, f fs.FS) (d wsDialer, err error) {
+	d.fs = f
+	if d.urlParsed, err = conf.FieldURL("url"); err != nil {
+		return
 	}
-	var err error
-	if ws.urlParsed, err = conf.FieldURL("url"); err != nil {
-		return nil, err
-	}
-	if ws.urlStr, err = conf.FieldString("url"); err != nil {
-		return nil, err
+	if d.urlStr, err = conf.FieldString("url"); err != nil {
+		return
 	}
 	if conf.Contains("proxy_url") {
-		if ws.proxyURLParsed, err = conf.FieldURL("proxy_url"); err != nil {
-			return nil, err
+		if d.proxyURLParsed, err = conf.FieldURL("proxy_url"); err != nil {
+			return
+		}
+	}
+	if d.tlsConf, d.tlsEnabled, err = conf.FieldTLSToggled("tls"); err != nil {
+		return
+	}
+	if d.reqSigner, err = conf.HTTPRequestAuthSignerFromParsed(); err != nil {
+		return
+	}
+	d.headers, err = conf.FieldInterpolatedStringMap("headers")
+	return
+}
+
+// dial opens a new websocket connection, adding any configured headers and
+// signing the handshake request.
+func (d *wsDialer) dial(ctx context.Context) (*websocket.Conn, error) {
+	headers := http.Header{}
+	for k, v := range d.headers {
+		value, err := v.TryString(service.NewMessage(nil))
+		if err != nil {
+			return nil, fmt.Errorf(`failed string interpolation on header %q: %w`, k, err)
 		}
+		headers.Add(k, value)
 	}
-	if ws.tlsConf, ws.tlsEnabled, err = conf.FieldTLSToggled("tls"); err != nil {
+
+	err := d.reqSigner(d.fs, &http.Request{
+		URL:    d.urlParsed,
+		Header: headers,
+	})
+	if err != nil {
 		return nil, err
 	}
-	if ws.reqSigner, err = conf.HTTPRequestAuthSignerFromParsed(); err != nil {
+
+	dialer := *websocket.DefaultDialer
+	dialer.Subprotocols = d.subprotocols
+	if d.proxyURLParsed != nil {
+		dialer.Proxy = http.ProxyURL(d.proxyURLParsed)
+	}
+	if d.tlsEnabled {
+		dialer.TLSClientConfig = d.tlsConf
+	}
+
+	client, res, err := dialer.DialContext(ctx, d.urlStr, headers)
+	if res != nil {
+		res.Body.Close()
+	}
+	if err != nil {
 		return nil, err
 	}
-	if ws.headers, err = conf.FieldInterpolatedStringMap("headers"); err != nil {
+	return client, nil
+}
+
+type websocketReader struct {
+	log log.Modular
+
+	lock *sync.Mutex
+
+	client *websocket.Conn
+	dialer wsDialer
+
+	openMsgType wsOpenMsgType
+	openMsg     [][]byte
+}
+
+func newWebsocketReaderFromParsed(conf *service.ParsedConfig, mgr bundle.NewManagement) (*websocketReader, error) {
+	ws := &websocketReader{
+		log:  mgr.Logger(),
+		lock: &sync.Mutex{},
+	}
+	var err error
+	if ws.dialer, err = wsDialerFromParsed(conf, mgr.FS()); err != nil {
 		return nil, err
 	}
 	var openMsgStr, openMsgTypeStr string
@@ -181,48 +233,11 @@ func (w *websocketReader) Connect(ctx context.Context) error {
 		return nil
 	}
 
-	headers := http.Header{}
-	for k, v := range w.headers {
-		value, err := v.TryString(service.NewMessage(nil))
-		if err != nil {
-			return fmt.Errorf(`failed string interpolation on header %q: %w`, k, err)
-		}
-		headers.Add(k, value)
-	}
-
-	err := w.reqSigner(w.mgr.FS(), &http.Request{
-		URL:    w.urlParsed,
-		Header: headers,
-	})
+	client, err := w.dialer.dial(ctx)
 	if err != nil {
 		return err
 	}
 
-	var (
-		client *websocket.Conn
-		res    *http.Response
-	)
-
-	defer func() {
-		if res != nil {
-			res.Body.Close()
-		}
-	}()
-
-	dialer := *websocket.DefaultDialer
-	if w.proxyURLParsed != nil {
-		dialer.Proxy = http.ProxyURL(w.proxyURLParsed)
-	}
-
-	if w.tlsEnabled {
-		dialer.TLSClientConfig = w.tlsConf
-		if client, res, err = dialer.Dial(w.urlStr, headers); err != nil {
-			return err
-		}
-	} else if client, res, err = dialer.Dial(w.urlStr, headers); err != nil {
-		return err
-	}
-
 	var openMsgType int
 	switch w.openMsgType {
 	case wsOpenMsgTypeBinary:
@@ -230,11 +245,13 @@ func (w *websocketReader) Connect(ctx context.Context) error {
 	case wsOpenMsgTypeText:
 		openMsgType = websocket.TextMessage
 	default:
+		_ = client.Close()
 		return fmt.Errorf("unrecognised open_message_type: %s", w.openMsgType)
 	}
 
 	for _, msg := range w.openMsg {
 		if err := client.WriteMessage(openMsgType, msg); err != nil {
+			_ = client.Close()
 			return err
 		}
 	}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -19,9 +20,11 @@ import (
 
 	"github.com/warpstreamlabs/bento/internal/component/output"
 	"github.com/warpstreamlabs/bento/internal/component/testutil"
+	"github.com/warpstreamlabs/bento/internal/manager"
 	"github.com/warpstreamlabs/bento/internal/manager/mock"
 	"github.com/warpstreamlabs/bento/internal/message"
 	"github.com/warpstreamlabs/bento/internal/transaction"
+	"github.com/warpstreamlabs/bento/public/service"
 )
 
 func parseYAMLOutputConf(t testing.TB, formatStr string, args ...any) (conf output.Config) {
@@ -221,6 +224,92 @@ http_client:
 	require.NoError(t, h.WaitForClose(ctx))
 }
 
+type feedbackRecorderRateLimit struct {
+	mut     sync.Mutex
+	reports []string
+}
+
+func (f *feedbackRecorderRateLimit) Access(ctx context.Context) (time.Duration, error) {
+	return 0, nil
+}
+
+func (f *feedbackRecorderRateLimit) ReportSuccess(ctx context.Context) {
+	f.mut.Lock()
+	f.reports = append(f.reports, "success")
+	f.mut.Unlock()
+}
+
+func (f *feedbackRecorderRateLimit) ReportThrottled(ctx context.Context, retryAfter time.Duration) {
+	f.mut.Lock()
+	f.reports = append(f.reports, fmt.Sprintf("throttled %v", retryAfter))
+	f.mut.Unlock()
+}
+
+func (f *feedbackRecorderRateLimit) Close(ctx context.Context) error {
+	return nil
+}
+
+func TestHTTPClientRateLimitFeedback(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	rl := &feedbackRecorderRateLimit{}
+	require.NoError(t, service.RegisterRateLimit("test_http_client_feedback_recorder", service.NewConfigSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.RateLimit, error) {
+			return rl, nil
+		}))
+
+	var reqCount uint32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddUint32(&reqCount, 1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		case 3:
+			http.Error(w, "nope", http.StatusBadGateway)
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	defer ts.Close()
+
+	rlConf, err := testutil.RateLimitFromYAML(`
+label: foo
+test_http_client_feedback_recorder: {}
+`)
+	require.NoError(t, err)
+
+	resConf := manager.NewResourceConfig()
+	resConf.ResourceRateLimits = append(resConf.ResourceRateLimits, rlConf)
+
+	mgr, err := manager.New(resConf)
+	require.NoError(t, err)
+
+	conf := parseYAMLOutputConf(t, `
+http_client:
+  url: %v/testpost
+  rate_limit: foo
+  retry_period: 1ms
+  max_retry_backoff: 1ms
+  retries: 3
+`, ts.URL)
+
+	h, err := mgr.NewOutput(conf)
+	require.NoError(t, err)
+
+	require.NoError(t, writeBatchToStreamed(ctx, t, message.QuickBatch([][]byte{[]byte("test")}), h))
+
+	rl.mut.Lock()
+	assert.Equal(t, []string{"throttled 0s", "throttled 0s", "success"}, rl.reports)
+	rl.mut.Unlock()
+
+	h.TriggerCloseNow()
+	require.NoError(t, h.WaitForClose(ctx))
+}
+
 func TestHTTPClientBasic(t *testing.T) {
 	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
 	defer done()
@@ -0,0 +1,342 @@
+package io
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/warpstreamlabs/bento/internal/httpclient"
+	"github.com/warpstreamlabs/bento/public/bloblang"
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	gqlpFieldQuery                    = "query"
+	gqlpFieldVariables                = "variables"
+	gqlpFieldOperationName            = "operation_name"
+	gqlpFieldPagination               = "pagination"
+	gqlpFieldPaginationPageInfoPath   = "page_info_path"
+	gqlpFieldPaginationCursorVariable = "cursor_variable"
+	gqlpFieldPaginationMaxPages       = "max_pages"
+)
+
+func graphqlProcSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Version("1.14.0").
+		Categories("Integration").
+		Summary("Executes a GraphQL query against a server over HTTP for each message, and replaces the message with the `data` of the response.").
+		Description(`
+The variables of the query are obtained by executing the `+"`variables`"+` mapping against each message, which must result in an object. Requests are made with the same options as the `+"[`http` processor](/docs/components/processors/http)"+`, and therefore support authentication, retries and `+"[rate limits](/docs/components/rate_limits/about)"+`.
+
+If the response contains any `+"`errors`"+` then the message is flagged as having failed with the error messages reported by the server, and can be handled with [error handling patterns](/docs/configuration/error_handling).
+
+## Pagination
+
+When the field `+"`pagination`"+` is set the query is expected to follow the [cursor connections specification](https://relay.dev/graphql/connections.htm), where the `+"`page_info_path`"+` is the dot separated path within the `+"`data`"+` of each response to a `+"`pageInfo`"+` object containing the fields `+"`hasNextPage`"+` and `+"`endCursor`"+`.
+
+Whilst `+"`hasNextPage`"+` is true the query is repeated with the variable `+"`cursor_variable`"+` set to the `+"`endCursor`"+` of the previous response, up to a maximum of `+"`max_pages`"+` requests. Each page results in a separate message with the metadata field `+"`graphql_page`"+` set to the index of the page, starting at zero. If any page fails then the entire message is flagged as having failed.
+
+## Metadata
+
+If the request returns an error response code this processor sets a metadata field `+"`http_status_code`"+` on the resulting message. Use the field `+"`extract_headers`"+` to specify rules for which other headers should be copied into the resulting message from the response.`).
+		Example(
+			"Paginated Issues",
+			`This example fetches all issues of a GitHub repository named by each message, resulting in a message for each page of up to 100 issues:`,
+			`
+pipeline:
+  processors:
+    - graphql:
+        url: https://api.github.com/graphql
+        headers:
+          Authorization: "Bearer ${GITHUB_TOKEN}"
+        query: |
+          query($owner: String!, $name: String!, $after: String) {
+            repository(owner: $owner, name: $name) {
+              issues(first: 100, after: $after) {
+                nodes { number title }
+                pageInfo { hasNextPage endCursor }
+              }
+            }
+          }
+        variables: |
+          root.owner = this.owner
+          root.name = this.repo
+        pagination:
+          page_info_path: repository.issues.pageInfo
+          cursor_variable: after
+    - mapping: 'root = this.repository.issues.nodes'
+    - unarchive:
+        format: json_array
+`,
+		).
+		Field(httpclient.ConfigField("POST", false,
+			service.NewStringField(gqlpFieldQuery).
+				Description("The GraphQL query document to execute.").
+				Example(`query($id: ID!) { user(id: $id) { name email } }`),
+			service.NewBloblangField(gqlpFieldVariables).
+				Description("An optional [Bloblang mapping](/docs/guides/bloblang/about) which should evaluate to an object of variables for the query.").
+				Example(`root.id = this.user_id`).
+				Optional(),
+			service.NewStringField(gqlpFieldOperationName).
+				Description("The name of the operation to execute, which is required when the query document contains multiple operations.").
+				Advanced().
+				Optional(),
+			service.NewObjectField(gqlpFieldPagination,
+				service.NewStringField(gqlpFieldPaginationPageInfoPath).
+					Description("The dot separated path within the `data` of each response to the `pageInfo` object of the paginated connection.").
+					Example("repository.issues.pageInfo"),
+				service.NewStringField(gqlpFieldPaginationCursorVariable).
+					Description("The name of the query variable to set to the `endCursor` of the previous page.").
+					Default("after"),
+				service.NewIntField(gqlpFieldPaginationMaxPages).
+					Description("The maximum number of pages to request for each message.").
+					Default(100),
+			).
+				Description("Follow cursor based pagination of the query.").
+				Optional(),
+		))
+}
+
+func init() {
+	err := service.RegisterBatchProcessor(
+		"graphql", graphqlProcSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchProcessor, error) {
+			return newGraphQLProcFromParsed(conf, mgr)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type graphqlProc struct {
+	client    *httpclient.Client
+	rawURL    string
+	query     string
+	opName    string
+	variables *bloblang.Executor
+
+	pageInfoPath []string
+	cursorVar    string
+	maxPages     int
+
+	log *service.Logger
+}
+
+func newGraphQLProcFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (*graphqlProc, error) {
+	oldConf, err := httpclient.ConfigFromParsed(conf)
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := oldConf.Headers["Content-Type"]; !exists {
+		oldConf.Headers["Content-Type"], _ = service.NewInterpolatedString("application/json")
+	}
+
+	g := &graphqlProc{
+		log: mgr.Logger(),
+	}
+	g.rawURL, _ = conf.FieldString("url")
+	if g.query, err = conf.FieldString(gqlpFieldQuery); err != nil {
+		return nil, err
+	}
+	if conf.Contains(gqlpFieldVariables) {
+		if g.variables, err = conf.FieldBloblang(gqlpFieldVariables); err != nil {
+			return nil, err
+		}
+	}
+	if conf.Contains(gqlpFieldOperationName) {
+		if g.opName, err = conf.FieldString(gqlpFieldOperationName); err != nil {
+			return nil, err
+		}
+	}
+
+	if conf.Contains(gqlpFieldPagination) {
+		pConf := conf.Namespace(gqlpFieldPagination)
+
+		pageInfoPath, err := pConf.FieldString(gqlpFieldPaginationPageInfoPath)
+		if err != nil {
+			return nil, err
+		}
+		if pageInfoPath == "" {
+			return nil, errors.New("pagination page_info_path must not be empty")
+		}
+		g.pageInfoPath = strings.Split(pageInfoPath, ".")
+
+		if g.cursorVar, err = pConf.FieldString(gqlpFieldPaginationCursorVariable); err != nil {
+			return nil, err
+		}
+		if g.maxPages, err = pConf.FieldInt(gqlpFieldPaginationMaxPages); err != nil {
+			return nil, err
+		}
+		if g.maxPages < 1 {
+			return nil, fmt.Errorf("pagination max_pages must be greater than zero, got %v", g.maxPages)
+		}
+	}
+
+	if g.client, err = httpclient.NewClientFromOldConfig(oldConf, mgr); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+type graphqlResponse struct {
+	Data   any            `json:"data"`
+	Errors []graphqlError `json:"errors"`
+}
+
+type graphqlError struct {
+	Message string `json:"message"`
+}
+
+// parseGraphQLResponse decodes the result of an operation, where numbers are
+// preserved as json.Number in order to avoid losing the precision of integers.
+func parseGraphQLResponse(b []byte) (res graphqlResponse, err error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	err = dec.Decode(&res)
+	return
+}
+
+func graphqlErrorsToErr(errs []graphqlError) error {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Message
+	}
+	return fmt.Errorf("graphql errors: %v", strings.Join(msgs, "; "))
+}
+
+func (g *graphqlProc) queryVariables(batch service.MessageBatch, index int) (map[string]any, error) {
+	if g.variables == nil {
+		return map[string]any{}, nil
+	}
+
+	varsMsg, err := batch.BloblangQuery(index, g.variables)
+	if err != nil {
+		return nil, fmt.Errorf("variables mapping failed: %w", err)
+	}
+	if varsMsg == nil {
+		return map[string]any{}, nil
+	}
+
+	v, err := varsMsg.AsStructured()
+	if err != nil {
+		return nil, fmt.Errorf("variables mapping failed: %w", err)
+	}
+	vars, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("variables mapping must result in an object, got %T", v)
+	}
+	return vars, nil
+}
+
+// pageInfo extracts whether there is a next page of a response, and the cursor
+// with which it can be requested.
+func (g *graphqlProc) pageInfo(data any) (hasNext bool, cursor any, err error) {
+	v := data
+	for _, k := range g.pageInfoPath {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return false, nil, fmt.Errorf("page info path %v not found in response", strings.Join(g.pageInfoPath, "."))
+		}
+		v = obj[k]
+	}
+
+	info, ok := v.(map[string]any)
+	if !ok {
+		return false, nil, fmt.Errorf("page info path %v not found in response", strings.Join(g.pageInfoPath, "."))
+	}
+	hasNext, _ = info["hasNextPage"].(bool)
+	return hasNext, info["endCursor"], nil
+}
+
+// execute performs the query for a message, following pagination when enabled,
+// and returns a message for each response.
+func (g *graphqlProc) execute(ctx context.Context, batch service.MessageBatch, index int) (service.MessageBatch, error) {
+	vars, err := g.queryVariables(batch, index)
+	if err != nil {
+		return nil, err
+	}
+
+	var pages service.MessageBatch
+	for page := 0; ; page++ {
+		reqBody := map[string]any{
+			"query":     g.query,
+			"variables": vars,
+		}
+		if g.opName != "" {
+			reqBody["operationName"] = g.opName
+		}
+
+		reqMsg := batch[index].Copy()
+		reqMsg.SetStructured(reqBody)
+
+		resBatch, err := g.client.Send(ctx, service.MessageBatch{reqMsg})
+		if err != nil {
+			return nil, err
+		}
+		if len(resBatch) != 1 {
+			return nil, fmt.Errorf("unexpected response size: %v", len(resBatch))
+		}
+
+		resBytes, err := resBatch[0].AsBytes()
+		if err != nil {
+			return nil, err
+		}
+		res, err := parseGraphQLResponse(resBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+		if len(res.Errors) > 0 {
+			return nil, graphqlErrorsToErr(res.Errors)
+		}
+
+		pageMsg := batch[index].Copy()
+		pageMsg.SetStructured(res.Data)
+		_ = resBatch[0].MetaWalkMut(func(k string, v any) error {
+			pageMsg.MetaSetMut(k, v)
+			return nil
+		})
+		if g.pageInfoPath == nil {
+			return service.MessageBatch{pageMsg}, nil
+		}
+		pageMsg.MetaSetMut("graphql_page", page)
+		pages = append(pages, pageMsg)
+
+		hasNext, cursor, err := g.pageInfo(res.Data)
+		if err != nil {
+			return nil, err
+		}
+		if !hasNext || page+1 >= g.maxPages {
+			return pages, nil
+		}
+		vars[g.cursorVar] = cursor
+	}
+}
+
+func (g *graphqlProc) ProcessBatch(ctx context.Context, batch service.MessageBatch) ([]service.MessageBatch, error) {
+	var resBatch service.MessageBatch
+	for i, msg := range batch {
+		pages, err := g.execute(ctx, batch, i)
+		if err != nil {
+			g.log.Errorf("GraphQL request to '%v' failed: %v", g.rawURL, err)
+
+			errMsg := msg.Copy()
+			var hErr httpclient.ErrUnexpectedHTTPRes
+			if errors.As(err, &hErr) {
+				errMsg.MetaSetMut("http_status_code", hErr.Code)
+			}
+			errMsg.SetError(err)
+			resBatch = append(resBatch, errMsg)
+			continue
+		}
+		resBatch = append(resBatch, pages...)
+	}
+	return []service.MessageBatch{resBatch}, nil
+}
+
+func (g *graphqlProc) Close(ctx context.Context) error {
+	return g.client.Close(ctx)
+}
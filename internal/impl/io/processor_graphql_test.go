@@ -0,0 +1,196 @@
+package io
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+type gqlTestRequest struct {
+	Query         string         `json:"query"`
+	Variables     map[string]any `json:"variables"`
+	OperationName string         `json:"operationName"`
+}
+
+func gqlTestServer(t testing.TB, handler func(req gqlTestRequest) any) (string, *[]gqlTestRequest) {
+	t.Helper()
+
+	var reqs []gqlTestRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var req gqlTestRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		reqs = append(reqs, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(handler(req))
+	}))
+	t.Cleanup(ts.Close)
+	return ts.URL, &reqs
+}
+
+func gqlTestProc(t testing.TB, confStr string, args ...any) *graphqlProc {
+	t.Helper()
+
+	conf, err := graphqlProcSpec().ParseYAML(fmt.Sprintf(confStr, args...), nil)
+	require.NoError(t, err)
+
+	proc, err := newGraphQLProcFromParsed(conf, service.MockResources())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, proc.Close(context.Background()))
+	})
+	return proc
+}
+
+func TestGraphQLProcessorBasic(t *testing.T) {
+	url, reqs := gqlTestServer(t, func(req gqlTestRequest) any {
+		return map[string]any{
+			"data": map[string]any{
+				"user": map[string]any{"id": req.Variables["id"], "name": "foo"},
+			},
+		}
+	})
+
+	proc := gqlTestProc(t, `
+url: %v
+query: 'query($id: ID!) { user(id: $id) { id name } }'
+operation_name: GetUser
+variables: 'root.id = this.user_id'
+`, url)
+
+	inMsg := service.NewMessage([]byte(`{"user_id":"u1"}`))
+	inMsg.MetaSetMut("foo", "bar")
+
+	batches, err := proc.ProcessBatch(context.Background(), service.MessageBatch{inMsg})
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0], 1)
+
+	require.NoError(t, batches[0][0].GetError())
+	resBytes, err := batches[0][0].AsBytes()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"user":{"id":"u1","name":"foo"}}`, string(resBytes))
+
+	v, _ := batches[0][0].MetaGetMut("foo")
+	assert.Equal(t, "bar", v)
+	v, _ = batches[0][0].MetaGetMut("http_status_code")
+	assert.Equal(t, 200, v)
+
+	require.Len(t, *reqs, 1)
+	assert.Equal(t, gqlTestRequest{
+		Query:         `query($id: ID!) { user(id: $id) { id name } }`,
+		Variables:     map[string]any{"id": "u1"},
+		OperationName: "GetUser",
+	}, (*reqs)[0])
+}
+
+func TestGraphQLProcessorPagination(t *testing.T) {
+	url, reqs := gqlTestServer(t, func(req gqlTestRequest) any {
+		page := 0
+		if c, ok := req.Variables["cursor"].(string); ok {
+			_, _ = fmt.Sscanf(c, "c%d", &page)
+		}
+		return map[string]any{
+			"data": map[string]any{
+				"repo": map[string]any{
+					"issues": map[string]any{
+						"nodes": []any{page},
+						"pageInfo": map[string]any{
+							"hasNextPage": page < 4,
+							"endCursor":   fmt.Sprintf("c%d", page+1),
+						},
+					},
+				},
+			},
+		}
+	})
+
+	proc := gqlTestProc(t, `
+url: %v
+query: 'query($cursor: String) { repo { issues(after: $cursor) { nodes pageInfo { hasNextPage endCursor } } } }'
+pagination:
+  page_info_path: repo.issues.pageInfo
+  cursor_variable: cursor
+  max_pages: 3
+`, url)
+
+	batches, err := proc.ProcessBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{}`)),
+	})
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0], 3)
+
+	for i, msg := range batches[0] {
+		require.NoError(t, msg.GetError())
+		v, err := msg.AsStructured()
+		require.NoError(t, err)
+		nodes := v.(map[string]any)["repo"].(map[string]any)["issues"].(map[string]any)["nodes"]
+		assert.Equal(t, []any{json.Number(fmt.Sprint(i))}, nodes)
+
+		page, _ := msg.MetaGetMut("graphql_page")
+		assert.Equal(t, i, page)
+	}
+
+	require.Len(t, *reqs, 3)
+	assert.Equal(t, map[string]any{}, (*reqs)[0].Variables)
+	assert.Equal(t, map[string]any{"cursor": "c1"}, (*reqs)[1].Variables)
+	assert.Equal(t, map[string]any{"cursor": "c2"}, (*reqs)[2].Variables)
+}
+
+func TestGraphQLProcessorErrors(t *testing.T) {
+	url, _ := gqlTestServer(t, func(req gqlTestRequest) any {
+		if req.Variables["fail"] == true {
+			return map[string]any{
+				"data":   nil,
+				"errors": []any{map[string]any{"message": "foo"}, map[string]any{"message": "bar"}},
+			}
+		}
+		return map[string]any{"data": map[string]any{"nope": true}}
+	})
+
+	proc := gqlTestProc(t, `
+url: %v
+query: 'query { foo }'
+variables: 'root.fail = this.fail'
+pagination:
+  page_info_path: foo.pageInfo
+`, url)
+
+	batches, err := proc.ProcessBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"fail":true}`)),
+		service.NewMessage([]byte(`{"fail":false}`)),
+		service.NewMessage([]byte(`not json`)),
+	})
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0], 3)
+
+	require.Error(t, batches[0][0].GetError())
+	assert.Contains(t, batches[0][0].GetError().Error(), "graphql errors: foo; bar")
+
+	require.Error(t, batches[0][1].GetError())
+	assert.Contains(t, batches[0][1].GetError().Error(), "page info path foo.pageInfo not found")
+
+	require.Error(t, batches[0][2].GetError())
+	assert.Contains(t, batches[0][2].GetError().Error(), "variables mapping failed")
+
+	for i, exp := range []string{`{"fail":true}`, `{"fail":false}`, `not json`} {
+		b, err := batches[0][i].AsBytes()
+		require.NoError(t, err)
+		assert.Equal(t, exp, string(b))
+	}
+}
@@ -1,8 +1,10 @@
 package nats
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"strings"
 	"sync"
 	"time"
 
@@ -141,6 +143,72 @@ func (p *kvCache) Add(ctx context.Context, key string, value []byte, _ *time.Dur
 	return err
 }
 
+func (p *kvCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	p.connMut.RLock()
+	defer p.connMut.RUnlock()
+
+	values := make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		entry, err := p.kv.Get(k)
+		if err != nil {
+			if errors.Is(err, nats.ErrKeyNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		values[k] = entry.Value()
+	}
+	return values, nil
+}
+
+func (p *kvCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, _ *time.Duration) error {
+	p.connMut.RLock()
+	defer p.connMut.RUnlock()
+
+	entry, err := p.kv.Get(key)
+	if err != nil {
+		if errors.Is(err, nats.ErrKeyNotFound) {
+			err = service.ErrKeyNotFound
+		}
+		return err
+	}
+	if !bytes.Equal(entry.Value(), old) {
+		return service.ErrCompareAndSwapFailed
+	}
+
+	// The update only succeeds if the key has not been modified since it was
+	// read, which makes the comparison atomic.
+	if _, err = p.kv.Update(key, value, entry.Revision()); err != nil {
+		var apiErr *nats.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode == nats.JSErrCodeStreamWrongLastSequence {
+			return service.ErrCompareAndSwapFailed
+		}
+		return err
+	}
+	return nil
+}
+
+func (p *kvCache) Scan(ctx context.Context, prefix string) ([]string, error) {
+	p.connMut.RLock()
+	defer p.connMut.RUnlock()
+
+	allKeys, err := p.kv.Keys(nats.Context(ctx))
+	if err != nil {
+		if errors.Is(err, nats.ErrNoKeysFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var keys []string
+	for _, k := range allKeys {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
 func (p *kvCache) Delete(ctx context.Context, key string) error {
 	p.connMut.RLock()
 	defer p.connMut.RUnlock()
@@ -104,6 +104,10 @@ cache_resources:
 			integration.CacheTestDoubleAdd(),
 			integration.CacheTestDelete(),
 			integration.CacheTestGetAndSet(50),
+			integration.CacheTestGetMulti(),
+			integration.CacheTestIncrement(),
+			integration.CacheTestCompareAndSwap(),
+			integration.CacheTestScan(),
 		)
 		suite.Run(
 			t, template,
@@ -122,6 +126,62 @@ cache_resources:
 		)
 	})
 
+	t.Run("lock", func(t *testing.T) {
+		js, err := jetstream.New(natsConn)
+		require.NoError(t, err)
+
+		_, err = js.CreateKeyValue(context.Background(), jetstream.KeyValueConfig{
+			Bucket: "bucket-locks",
+		})
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		newLock := func() *kvLock {
+			conf, err := natsKVLockConfig().ParseYAML(fmt.Sprintf(`
+bucket: bucket-locks
+urls: [ tcp://localhost:%v ]
+`, resource.GetPort("4222/tcp")), nil)
+			require.NoError(t, err)
+
+			l, err := newKVLock(conf, service.MockResources())
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				_ = l.Close(ctx)
+			})
+			return l
+		}
+
+		lockA, lockB := newLock(), newLock()
+
+		held, err := lockA.TryAcquire(ctx, "foo", time.Minute)
+		require.NoError(t, err)
+		assert.True(t, held)
+
+		held, err = lockB.TryAcquire(ctx, "foo", time.Minute)
+		require.NoError(t, err)
+		assert.False(t, held)
+
+		held, err = lockA.TryAcquire(ctx, "foo", time.Minute)
+		require.NoError(t, err)
+		assert.True(t, held, "renewal")
+
+		require.NoError(t, lockB.Release(ctx, "foo"))
+		held, err = lockB.TryAcquire(ctx, "foo", time.Minute)
+		require.NoError(t, err)
+		assert.False(t, held, "release by a different instance")
+
+		require.NoError(t, lockA.Release(ctx, "foo"))
+		held, err = lockB.TryAcquire(ctx, "foo", time.Millisecond*50)
+		require.NoError(t, err)
+		assert.True(t, held)
+
+		time.Sleep(time.Millisecond * 100)
+
+		held, err = lockA.TryAcquire(ctx, "foo", time.Minute)
+		require.NoError(t, err)
+		assert.True(t, held, "lease expiry")
+	})
+
 	t.Run("processor", func(t *testing.T) {
 		createBucket := func(t *testing.T) (jetstream.KeyValue, string) {
 			u4, err := uuid.NewV4()
@@ -0,0 +1,214 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/shutdown"
+	"github.com/gofrs/uuid"
+	"github.com/nats-io/nats.go"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+func natsKVLockConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Services").
+		Version("1.14.0").
+		Summary("Store locks in a NATS key-value bucket.").
+		Description(`
+Each lock is a key within the bucket holding a token unique to the Bento instance holding it and the time at which the lease expires. A lock is acquired either by creating its key, or by updating the key when the lease is held by this instance or has expired, where updates are conditional on the revision of the key in order to prevent races between instances. Lease expiry is calculated with the clock of each Bento instance, and therefore the clocks of instances sharing locks must be reasonably synchronised.` + connectionNameDescription() + authDescription()).
+		Fields(Docs("KV")...)
+}
+
+func init() {
+	err := service.RegisterLock(
+		"nats_kv", natsKVLockConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Lock, error) {
+			return newKVLock(conf, mgr)
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+type kvLock struct {
+	connDetails connectionDetails
+	bucket      string
+	token       string
+
+	shutSig *shutdown.Signaller
+
+	connMut  sync.RWMutex
+	natsConn *nats.Conn
+	kv       nats.KeyValue
+}
+
+func newKVLock(conf *service.ParsedConfig, mgr *service.Resources) (*kvLock, error) {
+	p := &kvLock{
+		shutSig: shutdown.NewSignaller(),
+	}
+
+	var err error
+	if p.connDetails, err = connectionDetailsFromParsed(conf, mgr); err != nil {
+		return nil, err
+	}
+
+	if p.bucket, err = conf.FieldString(kvFieldBucket); err != nil {
+		return nil, err
+	}
+
+	u4, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+	p.token = u4.String()
+
+	err = p.connect(context.Background())
+	return p, err
+}
+
+func (p *kvLock) disconnect() {
+	p.connMut.Lock()
+	defer p.connMut.Unlock()
+
+	if p.natsConn != nil {
+		p.natsConn.Close()
+		p.natsConn = nil
+	}
+	p.kv = nil
+}
+
+func (p *kvLock) connect(ctx context.Context) error {
+	p.connMut.Lock()
+	defer p.connMut.Unlock()
+
+	if p.natsConn != nil {
+		return nil
+	}
+
+	var err error
+	if p.natsConn, err = p.connDetails.get(ctx); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			p.natsConn.Close()
+			p.natsConn = nil
+		}
+	}()
+
+	var js nats.JetStreamContext
+	if js, err = p.natsConn.JetStream(); err != nil {
+		return err
+	}
+
+	if p.kv, err = js.KeyValue(p.bucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Values are stored as the token of the holder followed by a colon and the
+// expiry of the lease as a Unix timestamp in milliseconds.
+func (p *kvLock) encodeLease(expires time.Time) []byte {
+	return []byte(p.token + ":" + strconv.FormatInt(expires.UnixMilli(), 10))
+}
+
+func decodeLease(value []byte) (owner string, expires time.Time, err error) {
+	owner, expiresStr, found := strings.Cut(string(value), ":")
+	if !found {
+		return "", time.Time{}, fmt.Errorf("unexpected lock value: %s", value)
+	}
+	expiresMillis, err := strconv.ParseInt(expiresStr, 10, 64)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("unexpected lock expiry: %w", err)
+	}
+	return owner, time.UnixMilli(expiresMillis), nil
+}
+
+func isWrongLastSequence(err error) bool {
+	var apiErr *nats.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode == nats.JSErrCodeStreamWrongLastSequence
+}
+
+func (p *kvLock) TryAcquire(ctx context.Context, name string, lease time.Duration) (bool, error) {
+	p.connMut.RLock()
+	defer p.connMut.RUnlock()
+
+	now := time.Now()
+	value := p.encodeLease(now.Add(lease))
+
+	entry, err := p.kv.Get(name)
+	if err != nil {
+		if !errors.Is(err, nats.ErrKeyNotFound) {
+			return false, err
+		}
+		if _, err = p.kv.Create(name, value); err != nil {
+			if errors.Is(err, nats.ErrKeyExists) || isWrongLastSequence(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+
+	owner, expires, err := decodeLease(entry.Value())
+	if err != nil {
+		return false, err
+	}
+	if owner != p.token && now.Before(expires) {
+		return false, nil
+	}
+
+	if _, err = p.kv.Update(name, value, entry.Revision()); err != nil {
+		if isWrongLastSequence(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (p *kvLock) Release(ctx context.Context, name string) error {
+	p.connMut.RLock()
+	defer p.connMut.RUnlock()
+
+	entry, err := p.kv.Get(name)
+	if err != nil {
+		if errors.Is(err, nats.ErrKeyNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if owner, _, err := decodeLease(entry.Value()); err != nil || owner != p.token {
+		return err
+	}
+
+	if err = p.kv.Delete(name, nats.LastRevision(entry.Revision())); err != nil && !isWrongLastSequence(err) {
+		return err
+	}
+	return nil
+}
+
+func (p *kvLock) Close(ctx context.Context) error {
+	go func() {
+		p.disconnect()
+		p.shutSig.TriggerHasStopped()
+	}()
+	select {
+	case <-p.shutSig.HasStoppedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
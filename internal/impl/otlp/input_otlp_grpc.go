@@ -0,0 +1,214 @@
+package otlp
+
+import (
+	"context"
+	"net"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	oiFieldAddress  = "address"
+	oiFieldTLS      = "tls"
+	oiFieldCertFile = "cert_file"
+	oiFieldKeyFile  = "key_file"
+)
+
+func otlpInputTLSField() *service.ConfigField {
+	return service.NewObjectField(oiFieldTLS,
+		service.NewStringField(oiFieldCertFile).
+			Description("A PEM encoded certificate file, when set along with `key_file` the server is served with TLS.").
+			Default(""),
+		service.NewStringField(oiFieldKeyFile).
+			Description("A PEM encoded private key file, when set along with `cert_file` the server is served with TLS.").
+			Default(""),
+	).
+		Description("TLS configuration of the server.").
+		Advanced()
+}
+
+func otlpGRPCInputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Version("1.14.0").
+		Categories("Services").
+		Summary("Receives traces, metrics and logs from OpenTelemetry applications and collectors via the OTLP gRPC protocol.").
+		Description(`
+Creates a gRPC server implementing the OTLP trace, metrics and logs services. Each export request is delivered to the pipeline as a batch, and a response is only returned to the client once the batch has been acknowledged, where rejected batches result in an `+"`UNAVAILABLE`"+` status so that the client retries the export.
+`+signalsDescription).
+		Fields(
+			service.NewStringField(oiFieldAddress).
+				Description("The address to listen from.").
+				Default("0.0.0.0:4317"),
+			otlpInputTLSField(),
+		).
+		Example("Telemetry Pipeline", "Receive telemetry from applications, drop debug logs and forward everything else to a collector.", `
+input:
+  otlp_grpc:
+    address: 0.0.0.0:4317
+
+pipeline:
+  processors:
+    - mapping: |
+        root = if @otel_signal == "logs" && this.severityNumber.or(0) < 9 { deleted() }
+
+output:
+  otlp_grpc:
+    address: collector:4317
+`)
+}
+
+func init() {
+	err := service.RegisterBatchInput("otlp_grpc", otlpGRPCInputSpec(), func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchInput, error) {
+		return newOTLPGRPCInputFromParsed(conf, mgr)
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type otlpGRPCInput struct {
+	log *service.Logger
+
+	address  string
+	certFile string
+	keyFile  string
+
+	recv   *otlpReceiver
+	server *grpc.Server
+}
+
+func newOTLPGRPCInputFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (i *otlpGRPCInput, err error) {
+	i = &otlpGRPCInput{
+		log:  mgr.Logger(),
+		recv: newOTLPReceiver(),
+	}
+	if i.address, err = conf.FieldString(oiFieldAddress); err != nil {
+		return nil, err
+	}
+	if i.certFile, err = conf.FieldString(oiFieldTLS, oiFieldCertFile); err != nil {
+		return nil, err
+	}
+	if i.keyFile, err = conf.FieldString(oiFieldTLS, oiFieldKeyFile); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+func (i *otlpGRPCInput) Connect(ctx context.Context) error {
+	if i.server != nil {
+		return nil
+	}
+
+	var opts []grpc.ServerOption
+	if i.certFile != "" && i.keyFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(i.certFile, i.keyFile)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	ln, err := net.Listen("tcp", i.address)
+	if err != nil {
+		return err
+	}
+
+	i.server = grpc.NewServer(opts...)
+	coltracepb.RegisterTraceServiceServer(i.server, &otlpTraceService{recv: i.recv})
+	colmetricspb.RegisterMetricsServiceServer(i.server, &otlpMetricsService{recv: i.recv})
+	collogspb.RegisterLogsServiceServer(i.server, &otlpLogsService{recv: i.recv})
+
+	go func() {
+		if err := i.server.Serve(ln); err != nil {
+			i.log.Errorf("OTLP gRPC server stopped: %v", err)
+		}
+	}()
+	i.log.Infof("Receiving OTLP gRPC requests at: %v", ln.Addr().String())
+	return nil
+}
+
+func (i *otlpGRPCInput) ReadBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
+	if i.server == nil {
+		return nil, nil, service.ErrNotConnected
+	}
+	return i.recv.read(ctx)
+}
+
+func (i *otlpGRPCInput) Close(ctx context.Context) error {
+	i.recv.close()
+	if i.server == nil {
+		return nil
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		i.server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		i.server.Stop()
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+func deliverStatus(ctx context.Context, recv *otlpReceiver, batch service.MessageBatch, err error) error {
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := recv.deliver(ctx, batch); err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return nil
+}
+
+type otlpTraceService struct {
+	coltracepb.UnimplementedTraceServiceServer
+	recv *otlpReceiver
+}
+
+func (s *otlpTraceService) Export(ctx context.Context, req *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	batch, err := tracesToBatch(req)
+	if err := deliverStatus(ctx, s.recv, batch, err); err != nil {
+		return nil, err
+	}
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+type otlpMetricsService struct {
+	colmetricspb.UnimplementedMetricsServiceServer
+	recv *otlpReceiver
+}
+
+func (s *otlpMetricsService) Export(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	batch, err := metricsToBatch(req)
+	if err := deliverStatus(ctx, s.recv, batch, err); err != nil {
+		return nil, err
+	}
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+type otlpLogsService struct {
+	collogspb.UnimplementedLogsServiceServer
+	recv *otlpReceiver
+}
+
+func (s *otlpLogsService) Export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	batch, err := logsToBatch(req)
+	if err := deliverStatus(ctx, s.recv, batch, err); err != nil {
+		return nil, err
+	}
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
@@ -0,0 +1,222 @@
+package otlp
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+func otlpHTTPInputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Version("1.14.0").
+		Categories("Services").
+		Summary("Receives traces, metrics and logs from OpenTelemetry applications and collectors via the OTLP HTTP protocol.").
+		Description(`
+Creates an HTTP server that accepts OTLP export requests as `+"`POST`"+` requests to the paths `+"`/v1/traces`, `/v1/metrics` and `/v1/logs`"+`, with either a binary protobuf (`+"`application/x-protobuf`"+`) or JSON (`+"`application/json`"+`) body that can optionally be gzip compressed. Each export request is delivered to the pipeline as a batch, and a response is only returned to the client once the batch has been acknowledged, where rejected batches result in a `+"`503`"+` status code so that the client retries the export.
+`+signalsDescription).
+		Fields(
+			service.NewStringField(oiFieldAddress).
+				Description("The address to listen from.").
+				Default("0.0.0.0:4318"),
+			otlpInputTLSField(),
+		).
+		Example("Logs to Kafka", "Receive logs from applications and write each log record to Kafka, keyed by the name of the service.", `
+input:
+  otlp_http:
+    address: 0.0.0.0:4318
+
+pipeline:
+  processors:
+    - mapping: |
+        root = if @otel_signal != "logs" { deleted() }
+
+output:
+  kafka:
+    addresses: [ localhost:9092 ]
+    topic: logs
+    key: ${! metadata("service.name") }
+`)
+}
+
+func init() {
+	err := service.RegisterBatchInput("otlp_http", otlpHTTPInputSpec(), func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchInput, error) {
+		return newOTLPHTTPInputFromParsed(conf, mgr)
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type otlpHTTPInput struct {
+	log *service.Logger
+
+	address  string
+	certFile string
+	keyFile  string
+
+	recv   *otlpReceiver
+	server *http.Server
+}
+
+func newOTLPHTTPInputFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (i *otlpHTTPInput, err error) {
+	i = &otlpHTTPInput{
+		log:  mgr.Logger(),
+		recv: newOTLPReceiver(),
+	}
+	if i.address, err = conf.FieldString(oiFieldAddress); err != nil {
+		return nil, err
+	}
+	if i.certFile, err = conf.FieldString(oiFieldTLS, oiFieldCertFile); err != nil {
+		return nil, err
+	}
+	if i.keyFile, err = conf.FieldString(oiFieldTLS, oiFieldKeyFile); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+func (i *otlpHTTPInput) Connect(ctx context.Context) error {
+	if i.server != nil {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/traces", i.handler(signalTraces))
+	mux.HandleFunc("/v1/metrics", i.handler(signalMetrics))
+	mux.HandleFunc("/v1/logs", i.handler(signalLogs))
+
+	ln, err := net.Listen("tcp", i.address)
+	if err != nil {
+		return err
+	}
+
+	i.server = &http.Server{Handler: mux}
+	go func() {
+		var err error
+		if i.certFile != "" && i.keyFile != "" {
+			err = i.server.ServeTLS(ln, i.certFile, i.keyFile)
+		} else {
+			err = i.server.Serve(ln)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			i.log.Errorf("OTLP HTTP server stopped: %v", err)
+		}
+	}()
+	i.log.Infof("Receiving OTLP HTTP requests at: %v", ln.Addr().String())
+	return nil
+}
+
+func (i *otlpHTTPInput) handler(signal string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if contentType != contentTypeProtobuf && contentType != contentTypeJSON {
+			http.Error(w, fmt.Sprintf("Unsupported content type: %v", contentType), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		body := r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			defer gr.Close()
+			body = gr
+		}
+
+		b, err := io.ReadAll(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		unmarshal := proto.Unmarshal
+		marshal := proto.Marshal
+		if contentType == contentTypeJSON {
+			unmarshal = unmarshalOTLPJSON
+			marshal = marshalOTLPJSON
+		}
+
+		var batch service.MessageBatch
+		var res proto.Message
+		switch signal {
+		case signalTraces:
+			req := &coltracepb.ExportTraceServiceRequest{}
+			if err = unmarshal(b, req); err == nil {
+				batch, err = tracesToBatch(req)
+			}
+			res = &coltracepb.ExportTraceServiceResponse{}
+		case signalMetrics:
+			req := &colmetricspb.ExportMetricsServiceRequest{}
+			if err = unmarshal(b, req); err == nil {
+				batch, err = metricsToBatch(req)
+			}
+			res = &colmetricspb.ExportMetricsServiceResponse{}
+		case signalLogs:
+			req := &collogspb.ExportLogsServiceRequest{}
+			if err = unmarshal(b, req); err == nil {
+				batch, err = logsToBatch(req)
+			}
+			res = &collogspb.ExportLogsServiceResponse{}
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode request: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		if err := i.recv.deliver(r.Context(), batch); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		resBytes, err := marshal(res)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write(resBytes)
+	}
+}
+
+func (i *otlpHTTPInput) ReadBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
+	if i.server == nil {
+		return nil, nil, service.ErrNotConnected
+	}
+	return i.recv.read(ctx)
+}
+
+func (i *otlpHTTPInput) Close(ctx context.Context) error {
+	i.recv.close()
+	if i.server == nil {
+		return nil
+	}
+	if err := i.server.Shutdown(ctx); err != nil {
+		return i.server.Close()
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package otlp
+
+import (
+	"context"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	ooFieldAddress            = "address"
+	ooFieldURL                = "url"
+	ooFieldEncoding           = "encoding"
+	ooFieldHeaders            = "headers"
+	ooFieldTimeout            = "timeout"
+	ooFieldTLS                = "tls"
+	ooFieldResourceAttributes = "resource_attributes"
+	ooFieldBatching           = "batching"
+)
+
+func otlpOutputCommonFields() []*service.ConfigField {
+	return []*service.ConfigField{
+		service.NewStringMapField(ooFieldHeaders).
+			Description("A map of headers to add to each export request, which can be used for authentication.").
+			Example(map[string]any{"authorization": "Bearer ${TOKEN}"}).
+			Default(map[string]any{}),
+		service.NewDurationField(ooFieldTimeout).
+			Description("The maximum period to wait for an export request to complete.").
+			Default("10s").
+			Advanced(),
+		service.NewTLSToggledField(ooFieldTLS),
+		service.NewMetadataExcludeFilterField(ooFieldResourceAttributes).
+			Description("Specify criteria for which metadata values are exported as resource attributes, all are exported by default except for fields prefixed with `otel_`."),
+		service.NewOutputMaxInFlightField(),
+		service.NewBatchPolicyField(ooFieldBatching),
+	}
+}
+
+func otlpGRPCOutputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Version("1.14.0").
+		Categories("Services").
+		Summary("Exports traces, metrics and logs to an OpenTelemetry collector via the OTLP gRPC protocol.").
+		Description(outputSignalsDescription+service.OutputPerformanceDocs(true, true)).
+		Fields(
+			service.NewStringField(ooFieldAddress).
+				Description("The address of the collector to export to.").
+				Example("localhost:4317"),
+		).
+		Fields(otlpOutputCommonFields()...).
+		Example("Enrich Resources", "Add an attribute to the resource of all telemetry received from applications before forwarding it to a collector.", `
+input:
+  otlp_grpc: {}
+
+pipeline:
+  processors:
+    - mapping: |
+        meta "deployment.environment" = "production"
+
+output:
+  otlp_grpc:
+    address: collector:4317
+`)
+}
+
+func init() {
+	err := service.RegisterBatchOutput("otlp_grpc", otlpGRPCOutputSpec(), func(conf *service.ParsedConfig, mgr *service.Resources) (out service.BatchOutput, batchPolicy service.BatchPolicy, maxInFlight int, err error) {
+		if maxInFlight, err = conf.FieldMaxInFlight(); err != nil {
+			return
+		}
+		if batchPolicy, err = conf.FieldBatchPolicy(ooFieldBatching); err != nil {
+			return
+		}
+		out, err = newOTLPGRPCOutputFromParsed(conf)
+		return
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type otlpGRPCOutput struct {
+	address     string
+	headers     map[string]string
+	timeout     time.Duration
+	creds       credentials.TransportCredentials
+	resourceMFn *service.MetadataExcludeFilter
+
+	conn    *grpc.ClientConn
+	traces  coltracepb.TraceServiceClient
+	metrics colmetricspb.MetricsServiceClient
+	logs    collogspb.LogsServiceClient
+}
+
+func newOTLPGRPCOutputFromParsed(conf *service.ParsedConfig) (o *otlpGRPCOutput, err error) {
+	o = &otlpGRPCOutput{}
+	if o.address, err = conf.FieldString(ooFieldAddress); err != nil {
+		return nil, err
+	}
+	if o.headers, err = conf.FieldStringMap(ooFieldHeaders); err != nil {
+		return nil, err
+	}
+	if o.timeout, err = conf.FieldDuration(ooFieldTimeout); err != nil {
+		return nil, err
+	}
+	if o.resourceMFn, err = conf.FieldMetadataExcludeFilter(ooFieldResourceAttributes); err != nil {
+		return nil, err
+	}
+
+	tlsConf, tlsEnabled, err := conf.FieldTLSToggled(ooFieldTLS)
+	if err != nil {
+		return nil, err
+	}
+	if tlsEnabled {
+		o.creds = credentials.NewTLS(tlsConf)
+	} else {
+		o.creds = insecure.NewCredentials()
+	}
+	return o, nil
+}
+
+func (o *otlpGRPCOutput) Connect(ctx context.Context) error {
+	if o.conn != nil {
+		return nil
+	}
+
+	conn, err := grpc.NewClient(o.address, grpc.WithTransportCredentials(o.creds))
+	if err != nil {
+		return err
+	}
+	o.conn = conn
+	o.traces = coltracepb.NewTraceServiceClient(conn)
+	o.metrics = colmetricspb.NewMetricsServiceClient(conn)
+	o.logs = collogspb.NewLogsServiceClient(conn)
+	return nil
+}
+
+func (o *otlpGRPCOutput) WriteBatch(ctx context.Context, batch service.MessageBatch) error {
+	if o.conn == nil {
+		return service.ErrNotConnected
+	}
+
+	reqs, err := batchToRequests(batch, o.resourceMFn)
+	if err != nil {
+		return err
+	}
+
+	ctx, done := context.WithTimeout(ctx, o.timeout)
+	defer done()
+	if len(o.headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(o.headers))
+	}
+
+	if reqs.traces != nil {
+		if _, err := o.traces.Export(ctx, reqs.traces); err != nil {
+			return err
+		}
+	}
+	if reqs.metrics != nil {
+		if _, err := o.metrics.Export(ctx, reqs.metrics); err != nil {
+			return err
+		}
+	}
+	if reqs.logs != nil {
+		if _, err := o.logs.Export(ctx, reqs.logs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *otlpGRPCOutput) Close(ctx context.Context) error {
+	if o.conn == nil {
+		return nil
+	}
+	return o.conn.Close()
+}
@@ -0,0 +1,183 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+func otlpHTTPOutputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Version("1.14.0").
+		Categories("Services").
+		Summary("Exports traces, metrics and logs to an OpenTelemetry collector via the OTLP HTTP protocol.").
+		Description(`
+Export requests are sent as `+"`POST`"+` requests to the paths `+"`/v1/traces`, `/v1/metrics` and `/v1/logs`"+` of the configured URL.
+`+outputSignalsDescription+service.OutputPerformanceDocs(true, true)).
+		Fields(
+			service.NewStringField(ooFieldURL).
+				Description("The base URL of the collector to export to.").
+				Example("http://localhost:4318"),
+			service.NewStringEnumField(ooFieldEncoding, "protobuf", "json").
+				Description("The encoding of export requests.").
+				Default("protobuf").
+				Advanced(),
+		).
+		Fields(otlpOutputCommonFields()...).
+		Example("Filter Spans", "Drop the spans of health checks received from applications and forward everything else to a collector.", `
+input:
+  otlp_http: {}
+
+pipeline:
+  processors:
+    - mapping: |
+        root = if @otel_signal == "traces" && this.name.has_prefix("GET /health") { deleted() }
+
+output:
+  otlp_http:
+    url: http://collector:4318
+`)
+}
+
+func init() {
+	err := service.RegisterBatchOutput("otlp_http", otlpHTTPOutputSpec(), func(conf *service.ParsedConfig, mgr *service.Resources) (out service.BatchOutput, batchPolicy service.BatchPolicy, maxInFlight int, err error) {
+		if maxInFlight, err = conf.FieldMaxInFlight(); err != nil {
+			return
+		}
+		if batchPolicy, err = conf.FieldBatchPolicy(ooFieldBatching); err != nil {
+			return
+		}
+		out, err = newOTLPHTTPOutputFromParsed(conf)
+		return
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type otlpHTTPOutput struct {
+	url         string
+	contentType string
+	marshal     func(proto.Message) ([]byte, error)
+	headers     map[string]string
+	resourceMFn *service.MetadataExcludeFilter
+
+	client *http.Client
+}
+
+func newOTLPHTTPOutputFromParsed(conf *service.ParsedConfig) (o *otlpHTTPOutput, err error) {
+	o = &otlpHTTPOutput{}
+
+	if o.url, err = conf.FieldString(ooFieldURL); err != nil {
+		return nil, err
+	}
+	o.url = strings.TrimSuffix(o.url, "/")
+
+	encoding, err := conf.FieldString(ooFieldEncoding)
+	if err != nil {
+		return nil, err
+	}
+	switch encoding {
+	case "protobuf":
+		o.contentType, o.marshal = contentTypeProtobuf, proto.Marshal
+	case "json":
+		o.contentType, o.marshal = contentTypeJSON, marshalOTLPJSON
+	default:
+		return nil, fmt.Errorf("encoding '%v' is not supported", encoding)
+	}
+
+	if o.headers, err = conf.FieldStringMap(ooFieldHeaders); err != nil {
+		return nil, err
+	}
+	if o.resourceMFn, err = conf.FieldMetadataExcludeFilter(ooFieldResourceAttributes); err != nil {
+		return nil, err
+	}
+
+	var timeout time.Duration
+	if timeout, err = conf.FieldDuration(ooFieldTimeout); err != nil {
+		return nil, err
+	}
+	o.client = &http.Client{Timeout: timeout}
+
+	tlsConf, tlsEnabled, err := conf.FieldTLSToggled(ooFieldTLS)
+	if err != nil {
+		return nil, err
+	}
+	if tlsEnabled {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConf
+		o.client.Transport = transport
+	}
+	return o, nil
+}
+
+func (o *otlpHTTPOutput) Connect(ctx context.Context) error {
+	return nil
+}
+
+func (o *otlpHTTPOutput) WriteBatch(ctx context.Context, batch service.MessageBatch) error {
+	reqs, err := batchToRequests(batch, o.resourceMFn)
+	if err != nil {
+		return err
+	}
+
+	if reqs.traces != nil {
+		if err := o.export(ctx, "/v1/traces", reqs.traces); err != nil {
+			return err
+		}
+	}
+	if reqs.metrics != nil {
+		if err := o.export(ctx, "/v1/metrics", reqs.metrics); err != nil {
+			return err
+		}
+	}
+	if reqs.logs != nil {
+		if err := o.export(ctx, "/v1/logs", reqs.logs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *otlpHTTPOutput) export(ctx context.Context, path string, req proto.Message) error {
+	body, err := o.marshal(req)
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, o.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", o.contentType)
+	for k, v := range o.headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	res, err := o.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		resBody, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("export request to %v returned status %v: %s", path, res.StatusCode, bytes.TrimSpace(resBody))
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	return nil
+}
+
+func (o *otlpHTTPOutput) Close(ctx context.Context) error {
+	o.client.CloseIdleConnections()
+	return nil
+}
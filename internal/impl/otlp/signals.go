@@ -0,0 +1,553 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Jeffail/shutdown"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	metaSignal       = "otel_signal"
+	metaScopeName    = "otel_scope_name"
+	metaScopeVersion = "otel_scope_version"
+
+	// Metadata keys with this prefix are reserved and are therefore never
+	// exported as resource attributes.
+	metaReservedPrefix = "otel_"
+
+	signalTraces  = "traces"
+	signalMetrics = "metrics"
+	signalLogs    = "logs"
+)
+
+const signalsDescription = `
+Each span, metric and log record is emitted as an individual message, where the payload is the [OTLP JSON encoding](https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding) of the item, with trace and span IDs as hex encoded strings.
+
+### Metadata
+
+This input adds the following metadata fields to each message:
+
+` + "```text" + `
+- otel_signal (one of traces, metrics or logs)
+- otel_scope_name
+- otel_scope_version
+- All resource attributes
+` + "```" + `
+
+Resource attributes are added with their original type, where arrays and key/value lists become arrays and objects respectively. You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#bloblang-queries).
+`
+
+const outputSignalsDescription = `
+Each message is expected to be a span, metric or log record in the [OTLP JSON encoding](https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding), with the type of signal determined by the metadata field ` + "`otel_signal`" + ` (one of ` + "`traces`, `metrics` or `logs`" + `). Messages of a batch are grouped by their resource and instrumentation scope before being exported, where the resource attributes are taken from the metadata of each message, excluding fields prefixed with ` + "`otel_`" + `, and the scope from the metadata fields ` + "`otel_scope_name` and `otel_scope_version`" + `. These are the same metadata fields added by the ` + "`otlp_grpc` and `otlp_http`" + ` inputs, and therefore messages consumed from those inputs can be exported without any further changes.
+`
+
+//------------------------------------------------------------------------------
+
+var errReceiverClosed = errors.New("receiver is closed")
+
+type otlpTransaction struct {
+	batch   service.MessageBatch
+	resChan chan error
+}
+
+// otlpReceiver passes batches from request handlers to the pipeline, allowing
+// handlers to wait for the acknowledgement of their batch.
+type otlpReceiver struct {
+	transactions chan otlpTransaction
+	shutSig      *shutdown.Signaller
+}
+
+func newOTLPReceiver() *otlpReceiver {
+	return &otlpReceiver{
+		transactions: make(chan otlpTransaction),
+		shutSig:      shutdown.NewSignaller(),
+	}
+}
+
+// deliver sends a batch to the pipeline and blocks until it has been
+// acknowledged, returning the error of a rejected batch.
+func (r *otlpReceiver) deliver(ctx context.Context, batch service.MessageBatch) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	resChan := make(chan error, 1)
+	select {
+	case r.transactions <- otlpTransaction{batch: batch, resChan: resChan}:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-r.shutSig.SoftStopChan():
+		return errReceiverClosed
+	}
+
+	select {
+	case err := <-resChan:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-r.shutSig.SoftStopChan():
+		return errReceiverClosed
+	}
+}
+
+func (r *otlpReceiver) read(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
+	select {
+	case t := <-r.transactions:
+		return t.batch, func(ctx context.Context, err error) error {
+			t.resChan <- err
+			return nil
+		}, nil
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	case <-r.shutSig.SoftStopChan():
+		return nil, nil, service.ErrEndOfInput
+	}
+}
+
+func (r *otlpReceiver) close() {
+	r.shutSig.TriggerSoftStop()
+}
+
+//------------------------------------------------------------------------------
+
+func tracesToBatch(req *coltracepb.ExportTraceServiceRequest) (service.MessageBatch, error) {
+	var batch service.MessageBatch
+	for _, rs := range req.GetResourceSpans() {
+		for _, ss := range rs.GetScopeSpans() {
+			for _, s := range ss.GetSpans() {
+				msg, err := newSignalMessage(signalTraces, rs.GetResource(), ss.GetScope(), s)
+				if err != nil {
+					return nil, err
+				}
+				batch = append(batch, msg)
+			}
+		}
+	}
+	return batch, nil
+}
+
+func metricsToBatch(req *colmetricspb.ExportMetricsServiceRequest) (service.MessageBatch, error) {
+	var batch service.MessageBatch
+	for _, rm := range req.GetResourceMetrics() {
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, m := range sm.GetMetrics() {
+				msg, err := newSignalMessage(signalMetrics, rm.GetResource(), sm.GetScope(), m)
+				if err != nil {
+					return nil, err
+				}
+				batch = append(batch, msg)
+			}
+		}
+	}
+	return batch, nil
+}
+
+func logsToBatch(req *collogspb.ExportLogsServiceRequest) (service.MessageBatch, error) {
+	var batch service.MessageBatch
+	for _, rl := range req.GetResourceLogs() {
+		for _, sl := range rl.GetScopeLogs() {
+			for _, l := range sl.GetLogRecords() {
+				msg, err := newSignalMessage(signalLogs, rl.GetResource(), sl.GetScope(), l)
+				if err != nil {
+					return nil, err
+				}
+				batch = append(batch, msg)
+			}
+		}
+	}
+	return batch, nil
+}
+
+func newSignalMessage(signal string, res *resourcepb.Resource, scope *commonpb.InstrumentationScope, item proto.Message) (*service.Message, error) {
+	v, err := protoToJSONValue(item)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := service.NewMessage(nil)
+	msg.SetStructuredMut(v)
+	msg.MetaSetMut(metaSignal, signal)
+	if name := scope.GetName(); name != "" {
+		msg.MetaSetMut(metaScopeName, name)
+	}
+	if version := scope.GetVersion(); version != "" {
+		msg.MetaSetMut(metaScopeVersion, version)
+	}
+	for _, kv := range res.GetAttributes() {
+		msg.MetaSetMut(kv.GetKey(), anyValueToNative(kv.GetValue()))
+	}
+	return msg, nil
+}
+
+//------------------------------------------------------------------------------
+
+// otlpRequests contains the export requests of each signal built from a batch,
+// where signals without any items are nil.
+type otlpRequests struct {
+	traces  *coltracepb.ExportTraceServiceRequest
+	metrics *colmetricspb.ExportMetricsServiceRequest
+	logs    *collogspb.ExportLogsServiceRequest
+}
+
+type requestsBuilder struct {
+	filter *service.MetadataExcludeFilter
+	reqs   otlpRequests
+
+	resourceSpans   map[string]*tracepb.ResourceSpans
+	scopeSpans      map[string]*tracepb.ScopeSpans
+	resourceMetrics map[string]*metricspb.ResourceMetrics
+	scopeMetrics    map[string]*metricspb.ScopeMetrics
+	resourceLogs    map[string]*logspb.ResourceLogs
+	scopeLogs       map[string]*logspb.ScopeLogs
+}
+
+func batchToRequests(batch service.MessageBatch, filter *service.MetadataExcludeFilter) (*otlpRequests, error) {
+	b := &requestsBuilder{
+		filter:          filter,
+		resourceSpans:   map[string]*tracepb.ResourceSpans{},
+		scopeSpans:      map[string]*tracepb.ScopeSpans{},
+		resourceMetrics: map[string]*metricspb.ResourceMetrics{},
+		scopeMetrics:    map[string]*metricspb.ScopeMetrics{},
+		resourceLogs:    map[string]*logspb.ResourceLogs{},
+		scopeLogs:       map[string]*logspb.ScopeLogs{},
+	}
+	for i, msg := range batch {
+		if err := b.add(msg); err != nil {
+			return nil, fmt.Errorf("message %v: %w", i, err)
+		}
+	}
+	return &b.reqs, nil
+}
+
+func (b *requestsBuilder) add(msg *service.Message) error {
+	signal, _ := msg.MetaGet(metaSignal)
+	if signal != signalTraces && signal != signalMetrics && signal != signalLogs {
+		return fmt.Errorf("metadata field %v must be one of %v, %v or %v, got '%v'", metaSignal, signalTraces, signalMetrics, signalLogs, signal)
+	}
+
+	res, err := b.resourceFromMeta(msg)
+	if err != nil {
+		return err
+	}
+	resBytes, err := proto.MarshalOptions{Deterministic: true}.Marshal(res)
+	if err != nil {
+		return err
+	}
+	resKey := string(resBytes)
+
+	scope := &commonpb.InstrumentationScope{}
+	scope.Name, _ = msg.MetaGet(metaScopeName)
+	scope.Version, _ = msg.MetaGet(metaScopeVersion)
+	scopeKey := resKey + "\x00" + scope.Name + "\x00" + scope.Version
+
+	v, err := msg.AsStructured()
+	if err != nil {
+		return err
+	}
+
+	switch signal {
+	case signalTraces:
+		span := &tracepb.Span{}
+		if err := jsonValueToProto(v, span); err != nil {
+			return err
+		}
+		ss, exists := b.scopeSpans[scopeKey]
+		if !exists {
+			rs, exists := b.resourceSpans[resKey]
+			if !exists {
+				if b.reqs.traces == nil {
+					b.reqs.traces = &coltracepb.ExportTraceServiceRequest{}
+				}
+				rs = &tracepb.ResourceSpans{Resource: res}
+				b.reqs.traces.ResourceSpans = append(b.reqs.traces.ResourceSpans, rs)
+				b.resourceSpans[resKey] = rs
+			}
+			ss = &tracepb.ScopeSpans{Scope: scope}
+			rs.ScopeSpans = append(rs.ScopeSpans, ss)
+			b.scopeSpans[scopeKey] = ss
+		}
+		ss.Spans = append(ss.Spans, span)
+	case signalMetrics:
+		metric := &metricspb.Metric{}
+		if err := jsonValueToProto(v, metric); err != nil {
+			return err
+		}
+		sm, exists := b.scopeMetrics[scopeKey]
+		if !exists {
+			rm, exists := b.resourceMetrics[resKey]
+			if !exists {
+				if b.reqs.metrics == nil {
+					b.reqs.metrics = &colmetricspb.ExportMetricsServiceRequest{}
+				}
+				rm = &metricspb.ResourceMetrics{Resource: res}
+				b.reqs.metrics.ResourceMetrics = append(b.reqs.metrics.ResourceMetrics, rm)
+				b.resourceMetrics[resKey] = rm
+			}
+			sm = &metricspb.ScopeMetrics{Scope: scope}
+			rm.ScopeMetrics = append(rm.ScopeMetrics, sm)
+			b.scopeMetrics[scopeKey] = sm
+		}
+		sm.Metrics = append(sm.Metrics, metric)
+	case signalLogs:
+		record := &logspb.LogRecord{}
+		if err := jsonValueToProto(v, record); err != nil {
+			return err
+		}
+		sl, exists := b.scopeLogs[scopeKey]
+		if !exists {
+			rl, exists := b.resourceLogs[resKey]
+			if !exists {
+				if b.reqs.logs == nil {
+					b.reqs.logs = &collogspb.ExportLogsServiceRequest{}
+				}
+				rl = &logspb.ResourceLogs{Resource: res}
+				b.reqs.logs.ResourceLogs = append(b.reqs.logs.ResourceLogs, rl)
+				b.resourceLogs[resKey] = rl
+			}
+			sl = &logspb.ScopeLogs{Scope: scope}
+			rl.ScopeLogs = append(rl.ScopeLogs, sl)
+			b.scopeLogs[scopeKey] = sl
+		}
+		sl.LogRecords = append(sl.LogRecords, record)
+	}
+	return nil
+}
+
+func (b *requestsBuilder) resourceFromMeta(msg *service.Message) (*resourcepb.Resource, error) {
+	res := &resourcepb.Resource{}
+	if err := b.filter.WalkMut(msg, func(key string, value any) error {
+		if strings.HasPrefix(key, metaReservedPrefix) {
+			return nil
+		}
+		res.Attributes = append(res.Attributes, &commonpb.KeyValue{
+			Key:   key,
+			Value: nativeToAnyValue(value),
+		})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.Slice(res.Attributes, func(i, j int) bool {
+		return res.Attributes[i].Key < res.Attributes[j].Key
+	})
+	return res, nil
+}
+
+//------------------------------------------------------------------------------
+
+func anyValueToNative(v *commonpb.AnyValue) any {
+	switch t := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return t.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return t.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		return t.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		return t.DoubleValue
+	case *commonpb.AnyValue_BytesValue:
+		return t.BytesValue
+	case *commonpb.AnyValue_ArrayValue:
+		arr := make([]any, 0, len(t.ArrayValue.GetValues()))
+		for _, e := range t.ArrayValue.GetValues() {
+			arr = append(arr, anyValueToNative(e))
+		}
+		return arr
+	case *commonpb.AnyValue_KvlistValue:
+		obj := make(map[string]any, len(t.KvlistValue.GetValues()))
+		for _, kv := range t.KvlistValue.GetValues() {
+			obj[kv.GetKey()] = anyValueToNative(kv.GetValue())
+		}
+		return obj
+	}
+	return nil
+}
+
+func nativeToAnyValue(v any) *commonpb.AnyValue {
+	switch t := v.(type) {
+	case nil:
+		return &commonpb.AnyValue{}
+	case string:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: t}}
+	case bool:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: t}}
+	case int:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(t)}}
+	case int32:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(t)}}
+	case int64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: t}}
+	case uint32:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(t)}}
+	case uint64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(t)}}
+	case float32:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: float64(t)}}
+	case float64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: t}}
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: i}}
+		}
+		if f, err := t.Float64(); err == nil {
+			return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: f}}
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: t.String()}}
+	case []byte:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BytesValue{BytesValue: t}}
+	case []any:
+		arr := &commonpb.ArrayValue{}
+		for _, e := range t {
+			arr.Values = append(arr.Values, nativeToAnyValue(e))
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: arr}}
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		kvs := &commonpb.KeyValueList{}
+		for _, k := range keys {
+			kvs.Values = append(kvs.Values, &commonpb.KeyValue{Key: k, Value: nativeToAnyValue(t[k])})
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: kvs}}
+	}
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: fmt.Sprintf("%v", v)}}
+}
+
+//------------------------------------------------------------------------------
+
+// The OTLP JSON encoding differs from the canonical protobuf JSON mapping in
+// that trace and span IDs are hex encoded rather than base64, and enums are
+// encoded as integers.
+var idFields = map[string]struct{}{
+	"traceId":      {},
+	"spanId":       {},
+	"parentSpanId": {},
+}
+
+func base64ToHex(s string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hexToBase64(s string) (string, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// convertIDs returns a copy of a JSON value where the values of ID fields have
+// been converted with a given function.
+func convertIDs(v any, fn func(string) (string, error)) (any, error) {
+	switch t := v.(type) {
+	case map[string]any:
+		res := make(map[string]any, len(t))
+		for k, e := range t {
+			if s, ok := e.(string); ok {
+				if _, isID := idFields[k]; isID {
+					c, err := fn(s)
+					if err != nil {
+						return nil, fmt.Errorf("field %v: %w", k, err)
+					}
+					res[k] = c
+					continue
+				}
+			}
+			c, err := convertIDs(e, fn)
+			if err != nil {
+				return nil, err
+			}
+			res[k] = c
+		}
+		return res, nil
+	case []any:
+		res := make([]any, len(t))
+		for i, e := range t {
+			c, err := convertIDs(e, fn)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = c
+		}
+		return res, nil
+	}
+	return v, nil
+}
+
+// protoToJSONValue converts a protobuf message into a structured value
+// following the OTLP JSON encoding.
+func protoToJSONValue(m proto.Message) (any, error) {
+	b, err := protojson.MarshalOptions{UseEnumNumbers: true}.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return convertIDs(v, base64ToHex)
+}
+
+// jsonValueToProto populates a protobuf message from a structured value
+// following the OTLP JSON encoding.
+func jsonValueToProto(v any, m proto.Message) error {
+	v, err := convertIDs(v, hexToBase64)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(b, m)
+}
+
+// unmarshalOTLPJSON populates a protobuf message from a document following the
+// OTLP JSON encoding.
+func unmarshalOTLPJSON(b []byte, m proto.Message) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	return jsonValueToProto(v, m)
+}
+
+// marshalOTLPJSON encodes a protobuf message following the OTLP JSON encoding.
+func marshalOTLPJSON(m proto.Message) ([]byte, error) {
+	v, err := protoToJSONValue(m)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
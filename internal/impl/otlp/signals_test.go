@@ -0,0 +1,450 @@
+package otlp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+func freeAddress(t testing.TB) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+	return addr
+}
+
+func signalMessage(t testing.TB, signal, payload string, meta map[string]any) *service.Message {
+	t.Helper()
+
+	msg := service.NewMessage([]byte(payload))
+	msg.MetaSetMut(metaSignal, signal)
+	for k, v := range meta {
+		msg.MetaSetMut(k, v)
+	}
+	return msg
+}
+
+func readOTLPBatch(t testing.TB, ctx context.Context, in service.BatchInput, ackErr error) service.MessageBatch {
+	t.Helper()
+
+	batch, ackFn, err := in.ReadBatch(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ackFn(ctx, ackErr))
+	return batch
+}
+
+func metaMap(t testing.TB, msg *service.Message) map[string]any {
+	t.Helper()
+
+	m := map[string]any{}
+	require.NoError(t, msg.MetaWalkMut(func(k string, v any) error {
+		m[k] = v
+		return nil
+	}))
+	return m
+}
+
+func testExcludeFilter(t testing.TB, prefixes ...string) *service.MetadataExcludeFilter {
+	t.Helper()
+
+	spec := service.NewConfigSpec().Field(service.NewMetadataExcludeFilterField("meta"))
+	conf := "meta: {}"
+	if len(prefixes) > 0 {
+		conf = "meta:\n  exclude_prefixes: [ " + strings.Join(prefixes, ", ") + " ]"
+	}
+	pConf, err := spec.ParseYAML(conf, nil)
+	require.NoError(t, err)
+
+	f, err := pConf.FieldMetadataExcludeFilter("meta")
+	require.NoError(t, err)
+	return f
+}
+
+func TestSignalsToBatch(t *testing.T) {
+	req := &coltracepb.ExportTraceServiceRequest{
+		ResourceSpans: []*tracepb.ResourceSpans{{
+			Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+				{Key: "service.name", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "foo"}}},
+				{Key: "count", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: 5}}},
+				{Key: "tags", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{
+					Values: []*commonpb.AnyValue{{Value: &commonpb.AnyValue_BoolValue{BoolValue: true}}},
+				}}}},
+			}},
+			ScopeSpans: []*tracepb.ScopeSpans{{
+				Scope: &commonpb.InstrumentationScope{Name: "lib", Version: "1.0.0"},
+				Spans: []*tracepb.Span{
+					{
+						TraceId:           []byte{0x5b, 0x8e, 0xff, 0xf7, 0x98, 0x03, 0x81, 0x03, 0xd2, 0x69, 0xb6, 0x33, 0x81, 0x3f, 0xc6, 0x0c},
+						SpanId:            []byte{0xee, 0xe1, 0x9b, 0x7e, 0xc3, 0xc1, 0xb1, 0x74},
+						Name:              "first",
+						Kind:              tracepb.Span_SPAN_KIND_SERVER,
+						StartTimeUnixNano: 1544712660000000000,
+					},
+					{Name: "second"},
+				},
+			}},
+		}},
+	}
+
+	batch, err := tracesToBatch(req)
+	require.NoError(t, err)
+	require.Len(t, batch, 2)
+
+	v, err := batch[0].AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"traceId":           "5b8efff798038103d269b633813fc60c",
+		"spanId":            "eee19b7ec3c1b174",
+		"name":              "first",
+		"kind":              2.0,
+		"startTimeUnixNano": "1544712660000000000",
+	}, v)
+
+	assert.Equal(t, map[string]any{
+		"otel_signal":        "traces",
+		"otel_scope_name":    "lib",
+		"otel_scope_version": "1.0.0",
+		"service.name":       "foo",
+		"count":              int64(5),
+		"tags":               []any{true},
+	}, metaMap(t, batch[0]))
+
+	reqs, err := batchToRequests(batch, testExcludeFilter(t))
+	require.NoError(t, err)
+	assert.Nil(t, reqs.metrics)
+	assert.Nil(t, reqs.logs)
+
+	// Attributes are sorted by key when exported.
+	req.ResourceSpans[0].Resource.Attributes = []*commonpb.KeyValue{
+		req.ResourceSpans[0].Resource.Attributes[1],
+		req.ResourceSpans[0].Resource.Attributes[0],
+		req.ResourceSpans[0].Resource.Attributes[2],
+	}
+	assert.True(t, proto.Equal(req, reqs.traces), "expected: %v\nactual: %v", req, reqs.traces)
+}
+
+func TestBatchToRequestsGrouping(t *testing.T) {
+	batch := service.MessageBatch{
+		signalMessage(t, signalLogs, `{"body":{"stringValue":"a"}}`, map[string]any{"service.name": "foo", "otel_scope_name": "x"}),
+		signalMessage(t, signalLogs, `{"body":{"stringValue":"b"}}`, map[string]any{"service.name": "bar", "kafka_key": "ignored"}),
+		signalMessage(t, signalLogs, `{"body":{"stringValue":"c"}}`, map[string]any{"service.name": "foo", "otel_scope_name": "y"}),
+		signalMessage(t, signalLogs, `{"body":{"stringValue":"d"},"severityNumber":9}`, map[string]any{"service.name": "foo", "otel_scope_name": "x"}),
+	}
+
+	reqs, err := batchToRequests(batch, testExcludeFilter(t, "kafka_"))
+	require.NoError(t, err)
+	assert.Nil(t, reqs.traces)
+	assert.Nil(t, reqs.metrics)
+
+	strAttr := func(k, v string) *commonpb.KeyValue {
+		return &commonpb.KeyValue{Key: k, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}}
+	}
+	body := func(v string) *commonpb.AnyValue {
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}
+	}
+
+	exp := &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{
+			{
+				Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{strAttr("service.name", "foo")}},
+				ScopeLogs: []*logspb.ScopeLogs{
+					{
+						Scope: &commonpb.InstrumentationScope{Name: "x"},
+						LogRecords: []*logspb.LogRecord{
+							{Body: body("a")},
+							{Body: body("d"), SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_INFO},
+						},
+					},
+					{
+						Scope:      &commonpb.InstrumentationScope{Name: "y"},
+						LogRecords: []*logspb.LogRecord{{Body: body("c")}},
+					},
+				},
+			},
+			{
+				Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{strAttr("service.name", "bar")}},
+				ScopeLogs: []*logspb.ScopeLogs{{
+					Scope:      &commonpb.InstrumentationScope{},
+					LogRecords: []*logspb.LogRecord{{Body: body("b")}},
+				}},
+			},
+		},
+	}
+	assert.True(t, proto.Equal(exp, reqs.logs), "expected: %v\nactual: %v", exp, reqs.logs)
+}
+
+func TestBatchToRequestsErrors(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		msg  *service.Message
+		err  string
+	}{
+		{
+			name: "missing signal",
+			msg:  service.NewMessage([]byte(`{}`)),
+			err:  "message 0: metadata field otel_signal must be one of traces, metrics or logs",
+		},
+		{
+			name: "invalid trace id",
+			msg:  signalMessage(t, signalTraces, `{"traceId":"nope"}`, nil),
+			err:  "field traceId",
+		},
+		{
+			name: "invalid payload",
+			msg:  signalMessage(t, signalMetrics, `not json`, nil),
+			err:  "message 0",
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			_, err := batchToRequests(service.MessageBatch{test.msg}, testExcludeFilter(t))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestOTLPGRPCRoundTrip(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	addr := freeAddress(t)
+
+	inConf, err := otlpGRPCInputSpec().ParseYAML(`address: `+addr, nil)
+	require.NoError(t, err)
+	in, err := newOTLPGRPCInputFromParsed(inConf, service.MockResources())
+	require.NoError(t, err)
+	require.NoError(t, in.Connect(ctx))
+	t.Cleanup(func() {
+		_ = in.Close(context.Background())
+	})
+
+	outConf, err := otlpGRPCOutputSpec().ParseYAML(`
+address: `+addr+`
+headers:
+  authorization: foo
+`, nil)
+	require.NoError(t, err)
+	out, err := newOTLPGRPCOutputFromParsed(outConf)
+	require.NoError(t, err)
+	require.NoError(t, out.Connect(ctx))
+	t.Cleanup(func() {
+		_ = out.Close(context.Background())
+	})
+
+	writeErr := make(chan error, 1)
+	go func() {
+		writeErr <- out.WriteBatch(ctx, service.MessageBatch{
+			signalMessage(t, signalTraces, `{"traceId":"5b8efff798038103d269b633813fc60c","spanId":"eee19b7ec3c1b174","name":"foo"}`, map[string]any{"service.name": "a"}),
+			signalMessage(t, signalMetrics, `{"name":"bar","gauge":{"dataPoints":[{"asInt":"5"}]}}`, map[string]any{"service.name": "a"}),
+			signalMessage(t, signalLogs, `{"body":{"stringValue":"baz"}}`, map[string]any{"service.name": "b", "otel_scope_name": "lib"}),
+		})
+	}()
+
+	batch := readOTLPBatch(t, ctx, in, nil)
+	require.Len(t, batch, 1)
+	v, err := batch[0].AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"traceId": "5b8efff798038103d269b633813fc60c", "spanId": "eee19b7ec3c1b174", "name": "foo"}, v)
+	assert.Equal(t, map[string]any{"otel_signal": "traces", "service.name": "a"}, metaMap(t, batch[0]))
+
+	batch = readOTLPBatch(t, ctx, in, nil)
+	require.Len(t, batch, 1)
+	v, err = batch[0].AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "bar", "gauge": map[string]any{"dataPoints": []any{map[string]any{"asInt": "5"}}}}, v)
+	assert.Equal(t, map[string]any{"otel_signal": "metrics", "service.name": "a"}, metaMap(t, batch[0]))
+
+	batch = readOTLPBatch(t, ctx, in, nil)
+	require.Len(t, batch, 1)
+	v, err = batch[0].AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"body": map[string]any{"stringValue": "baz"}}, v)
+	assert.Equal(t, map[string]any{"otel_signal": "logs", "otel_scope_name": "lib", "service.name": "b"}, metaMap(t, batch[0]))
+
+	require.NoError(t, <-writeErr)
+
+	// Rejected batches are returned to the client as errors.
+	go func() {
+		writeErr <- out.WriteBatch(ctx, service.MessageBatch{
+			signalMessage(t, signalLogs, `{"body":{"stringValue":"baz"}}`, nil),
+		})
+	}()
+	_ = readOTLPBatch(t, ctx, in, errors.New("nope"))
+	err = <-writeErr
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nope")
+}
+
+func TestOTLPHTTPInput(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	addr := freeAddress(t)
+
+	inConf, err := otlpHTTPInputSpec().ParseYAML(`address: `+addr, nil)
+	require.NoError(t, err)
+	in, err := newOTLPHTTPInputFromParsed(inConf, service.MockResources())
+	require.NoError(t, err)
+	require.NoError(t, in.Connect(ctx))
+	t.Cleanup(func() {
+		_ = in.Close(context.Background())
+	})
+
+	post := func(path, contentType, body string) (*http.Response, string) {
+		res, err := http.Post("http://"+addr+path, contentType, strings.NewReader(body))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		resBody, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res, string(resBody)
+	}
+
+	type response struct {
+		res  *http.Response
+		body string
+	}
+	resChan := make(chan response, 1)
+	go func() {
+		res, body := post("/v1/traces", "application/json", `{
+  "resourceSpans": [{
+    "resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "foo"}}]},
+    "scopeSpans": [{
+      "scope": {"name": "lib"},
+      "spans": [{
+        "traceId": "5b8efff798038103d269b633813fc60c",
+        "spanId": "eee19b7ec3c1b174",
+        "name": "a",
+        "kind": 2,
+        "startTimeUnixNano": 1544712660000000000
+      }]
+    }]
+  }]
+}`)
+		resChan <- response{res, body}
+	}()
+
+	batch := readOTLPBatch(t, ctx, in, nil)
+	require.Len(t, batch, 1)
+	v, err := batch[0].AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"traceId":           "5b8efff798038103d269b633813fc60c",
+		"spanId":            "eee19b7ec3c1b174",
+		"name":              "a",
+		"kind":              2.0,
+		"startTimeUnixNano": "1544712660000000000",
+	}, v)
+	assert.Equal(t, map[string]any{"otel_signal": "traces", "otel_scope_name": "lib", "service.name": "foo"}, metaMap(t, batch[0]))
+
+	res := <-resChan
+	assert.Equal(t, http.StatusOK, res.res.StatusCode)
+	assert.Equal(t, "application/json", res.res.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{}`, res.body)
+
+	// Protobuf requests, rejected by the pipeline.
+	reqBytes, err := proto.Marshal(&collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			ScopeLogs: []*logspb.ScopeLogs{{
+				LogRecords: []*logspb.LogRecord{{SeverityText: "INFO"}},
+			}},
+		}},
+	})
+	require.NoError(t, err)
+	go func() {
+		res, body := post("/v1/logs", "application/x-protobuf", string(reqBytes))
+		resChan <- response{res, body}
+	}()
+
+	batch = readOTLPBatch(t, ctx, in, errors.New("nope"))
+	require.Len(t, batch, 1)
+	v, err = batch[0].AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"severityText": "INFO"}, v)
+
+	res = <-resChan
+	assert.Equal(t, http.StatusServiceUnavailable, res.res.StatusCode)
+	assert.Contains(t, res.body, "nope")
+
+	res.res, res.body = post("/v1/metrics", "application/json", `{"resourceMetrics":[{"scopeMetrics":[{"metrics":[{"name":"foo","nope":`)
+	assert.Equal(t, http.StatusBadRequest, res.res.StatusCode)
+
+	res.res, res.body = post("/v1/metrics", "text/plain", `foo`)
+	assert.Equal(t, http.StatusUnsupportedMediaType, res.res.StatusCode)
+}
+
+func TestOTLPHTTPOutput(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	reqChan := make(chan *http.Request, 10)
+	bodyChan := make(chan []byte, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		reqChan <- r
+		bodyChan <- b
+		if r.URL.Path == "/v1/metrics" {
+			http.Error(w, "nope", http.StatusTooManyRequests)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	outConf, err := otlpHTTPOutputSpec().ParseYAML(`
+url: `+server.URL+`/
+encoding: json
+headers:
+  authorization: foo
+`, nil)
+	require.NoError(t, err)
+	out, err := newOTLPHTTPOutputFromParsed(outConf)
+	require.NoError(t, err)
+	require.NoError(t, out.Connect(ctx))
+	t.Cleanup(func() {
+		_ = out.Close(context.Background())
+	})
+
+	require.NoError(t, out.WriteBatch(ctx, service.MessageBatch{
+		signalMessage(t, signalTraces, `{"traceId":"5b8efff798038103d269b633813fc60c","name":"foo","kind":2}`, map[string]any{"service.name": "a"}),
+	}))
+
+	req := <-reqChan
+	assert.Equal(t, "/v1/traces", req.URL.Path)
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, "foo", req.Header.Get("authorization"))
+	assert.JSONEq(t, `{
+  "resourceSpans": [{
+    "resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "a"}}]},
+    "scopeSpans": [{
+      "scope": {},
+      "spans": [{"traceId": "5b8efff798038103d269b633813fc60c", "name": "foo", "kind": 2}]
+    }]
+  }]
+}`, string(<-bodyChan))
+
+	err = out.WriteBatch(ctx, service.MessageBatch{
+		signalMessage(t, signalMetrics, `{"name":"foo"}`, nil),
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "returned status 429: nope")
+}
@@ -0,0 +1,219 @@
+package protobuf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/warpstreamlabs/bento/internal/filepath/ifs"
+	"github.com/warpstreamlabs/bento/public/bloblang"
+)
+
+// registryCache holds the registries parsed from sets of import paths, which
+// allows methods to be reconstructed with dynamic arguments without parsing
+// the .proto files again. Failed attempts are not cached so that they can be
+// retried, and the number of entries is bounded as import paths may be
+// dynamic.
+type registryCache struct {
+	mut     sync.Mutex
+	entries map[string]*registryCacheEntry
+}
+
+type registryCacheEntry struct {
+	once  sync.Once
+	files *protoregistry.Files
+	types *protoregistry.Types
+	err   error
+}
+
+const registryCacheMaxEntries = 64
+
+var globalRegistryCache = &registryCache{
+	entries: map[string]*registryCacheEntry{},
+}
+
+func (r *registryCache) Get(importPaths []string) (*protoregistry.Files, *protoregistry.Types, error) {
+	key := strings.Join(importPaths, "\x00")
+
+	r.mut.Lock()
+	entry, exists := r.entries[key]
+	if !exists {
+		if len(r.entries) >= registryCacheMaxEntries {
+			for k := range r.entries {
+				delete(r.entries, k)
+				break
+			}
+		}
+		entry = &registryCacheEntry{}
+		r.entries[key] = entry
+	}
+	r.mut.Unlock()
+
+	entry.once.Do(func() {
+		entry.files, entry.types, entry.err = loadDescriptors(ifs.OS(), importPaths)
+		if entry.err != nil {
+			r.mut.Lock()
+			if r.entries[key] == entry {
+				delete(r.entries, key)
+			}
+			r.mut.Unlock()
+		}
+	})
+	return entry.files, entry.types, entry.err
+}
+
+func importPathsFromArgs(args *bloblang.ParsedParams) ([]string, error) {
+	v, err := args.Get("import_paths")
+	if err != nil {
+		return nil, err
+	}
+	switch t := v.(type) {
+	case string:
+		return []string{t}, nil
+	case []any:
+		paths := make([]string, 0, len(t))
+		for i, e := range t {
+			s, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("import path %v: expected string value, got %T", i, e)
+			}
+			paths = append(paths, s)
+		}
+		if len(paths) == 0 {
+			return nil, errors.New("at least one import path must be provided")
+		}
+		return paths, nil
+	}
+	return nil, fmt.Errorf("expected string or array of strings for import_paths, got %T", v)
+}
+
+func messageTypeFromArgs(args *bloblang.ParsedParams) (protoreflect.MessageType, *protoregistry.Types, error) {
+	msg, err := args.GetString("message")
+	if err != nil {
+		return nil, nil, err
+	}
+	importPaths, err := importPathsFromArgs(args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, types, err := globalRegistryCache.Get(importPaths)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mt, err := types.FindMessageByName(protoreflect.FullName(msg))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to find message '%v' definition within '%v'", msg, importPaths)
+	}
+	return mt, types, nil
+}
+
+func init() {
+	parseSpec := bloblang.NewPluginSpec().
+		Impure().
+		Category("Parsing").
+		Description("Parses a protobuf message into a structured document using reflection of the message definition found within a set of .proto files. The .proto files are read from the filesystem, parsed once and then cached.").
+		Param(bloblang.NewStringParam("message").Description("The fully qualified name of the protobuf message to parse.")).
+		Param(bloblang.NewAnyParam("import_paths").Description("A directory, or an array of directories, containing .proto files that include all definitions required for parsing the target message. Each directory is walked with all found .proto files imported.")).
+		Param(bloblang.NewBoolParam("use_proto_names").Description("Whether fields of the resulting document should be named exactly as they are within the .proto files rather than as lower camel case.").Default(false)).
+		ExampleNotTested("", `root = this.payload.decode("base64").parse_protobuf("testing.Person", ["./schemas"])`)
+
+	if err := bloblang.RegisterMethodV2(
+		"parse_protobuf", parseSpec,
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			mt, types, err := messageTypeFromArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			useProtoNames, err := args.GetBool("use_proto_names")
+			if err != nil {
+				return nil, err
+			}
+
+			opts := protojson.MarshalOptions{
+				Resolver:      types,
+				UseProtoNames: useProtoNames,
+			}
+			return func(v any) (any, error) {
+				b, err := bloblang.ValueAsBytes(v)
+				if err != nil {
+					return nil, err
+				}
+
+				dynMsg := dynamicpb.NewMessage(mt.Descriptor())
+				if err := proto.Unmarshal(b, dynMsg); err != nil {
+					return nil, fmt.Errorf("failed to unmarshal protobuf message '%v': %w", mt.Descriptor().FullName(), err)
+				}
+
+				jBytes, err := opts.Marshal(dynMsg)
+				if err != nil {
+					return nil, fmt.Errorf("failed to marshal JSON protobuf message '%v': %w", mt.Descriptor().FullName(), err)
+				}
+
+				var jObj any
+				if err := json.Unmarshal(jBytes, &jObj); err != nil {
+					return nil, err
+				}
+				return jObj, nil
+			}, nil
+		},
+	); err != nil {
+		panic(err)
+	}
+
+	formatSpec := bloblang.NewPluginSpec().
+		Impure().
+		Category("Parsing").
+		Description("Formats a structured document as a protobuf message in bytes format using reflection of the message definition found within a set of .proto files. The .proto files are read from the filesystem, parsed once and then cached.").
+		Param(bloblang.NewStringParam("message").Description("The fully qualified name of the protobuf message to format.")).
+		Param(bloblang.NewAnyParam("import_paths").Description("A directory, or an array of directories, containing .proto files that include all definitions required for formatting the target message. Each directory is walked with all found .proto files imported.")).
+		Param(bloblang.NewBoolParam("discard_unknown").Description("Whether fields that are unknown to the message definition should be discarded rather than result in an error.").Default(false)).
+		ExampleNotTested("", `root.payload = this.person.format_protobuf("testing.Person", ["./schemas"]).encode("base64")`)
+
+	if err := bloblang.RegisterMethodV2(
+		"format_protobuf", formatSpec,
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			mt, types, err := messageTypeFromArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			discardUnknown, err := args.GetBool("discard_unknown")
+			if err != nil {
+				return nil, err
+			}
+
+			opts := protojson.UnmarshalOptions{
+				Resolver:       types,
+				DiscardUnknown: discardUnknown,
+			}
+			return func(v any) (any, error) {
+				jBytes, err := json.Marshal(v)
+				if err != nil {
+					return nil, err
+				}
+
+				dynMsg := dynamicpb.NewMessage(mt.Descriptor())
+				if err := opts.Unmarshal(jBytes, dynMsg); err != nil {
+					return nil, fmt.Errorf("failed to unmarshal JSON message '%v': %w", mt.Descriptor().FullName(), err)
+				}
+
+				data, err := proto.Marshal(dynMsg)
+				if err != nil {
+					return nil, fmt.Errorf("failed to marshal protobuf message '%v': %w", mt.Descriptor().FullName(), err)
+				}
+				return data, nil
+			}, nil
+		},
+	); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,120 @@
+package protobuf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/public/bloblang"
+)
+
+func TestProtobufBloblangRoundTrip(t *testing.T) {
+	exec, err := bloblang.Parse(fmt.Sprintf(`
+let encoded = this.person.format_protobuf("testing.Person", ["%v"])
+root.camel = $encoded.parse_protobuf("testing.Person", "%v")
+root.proto_names = $encoded.parse_protobuf(message: "testing.Person", import_paths: ["%v"], use_proto_names: true)
+`, protosPath, protosPath, protosPath))
+	require.NoError(t, err)
+
+	res, err := exec.Query(map[string]any{
+		"person": map[string]any{
+			"firstName": "caleb",
+			"lastName":  "quaye",
+			"age":       10,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"camel": map[string]any{
+			"firstName": "caleb",
+			"lastName":  "quaye",
+			"age":       10.0,
+		},
+		"proto_names": map[string]any{
+			"first_name": "caleb",
+			"last_name":  "quaye",
+			"age":        10.0,
+		},
+	}, res)
+}
+
+func TestProtobufBloblangImpure(t *testing.T) {
+	env := bloblang.GlobalEnvironment().OnlyPure()
+	for _, mapping := range []string{
+		fmt.Sprintf(`root = this.parse_protobuf("testing.Person", "%v")`, protosPath),
+		fmt.Sprintf(`root = this.format_protobuf("testing.Person", "%v")`, protosPath),
+	} {
+		_, err := env.Parse(mapping)
+		require.Error(t, err, mapping)
+	}
+}
+
+func TestProtobufBloblangErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		mapping     string
+		input       any
+		errContains string
+	}{
+		{
+			name:        "unknown message",
+			mapping:     fmt.Sprintf(`root = this.format_protobuf("testing.Nope", "%v")`, protosPath),
+			input:       map[string]any{},
+			errContains: "unable to find message 'testing.Nope'",
+		},
+		{
+			name:        "unknown field",
+			mapping:     fmt.Sprintf(`root = this.format_protobuf("testing.Person", "%v")`, protosPath),
+			input:       map[string]any{"nope": "foo"},
+			errContains: "failed to unmarshal JSON message 'testing.Person'",
+		},
+		{
+			name:        "invalid protobuf",
+			mapping:     fmt.Sprintf(`root = this.parse_protobuf("testing.Person", "%v")`, protosPath),
+			input:       []byte("\xff\xff\xff"),
+			errContains: "failed to unmarshal protobuf message 'testing.Person'",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			if err == nil {
+				_, err = exec.Query(test.input)
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.errContains)
+		})
+	}
+}
+
+func TestProtobufBloblangRegistryCache(t *testing.T) {
+	cache := &registryCache{entries: map[string]*registryCacheEntry{}}
+
+	dir := t.TempDir()
+	_, _, err := cache.Get([]string{filepath.Join(dir, "schemas")})
+	require.Error(t, err)
+	assert.Empty(t, cache.entries)
+
+	protoBytes, err := os.ReadFile(filepath.Join(protosPath, "person.proto"))
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "schemas"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schemas", "person.proto"), protoBytes, 0o644))
+
+	_, types, err := cache.Get([]string{filepath.Join(dir, "schemas")})
+	require.NoError(t, err)
+	_, err = types.FindMessageByName("testing.Person")
+	require.NoError(t, err)
+
+	for i := 0; len(cache.entries) < registryCacheMaxEntries; i++ {
+		cache.entries[fmt.Sprintf("filler%v", i)] = &registryCacheEntry{}
+	}
+	_, _, err = cache.Get([]string{dir + "/nope"})
+	require.Error(t, err)
+	assert.Len(t, cache.entries, registryCacheMaxEntries-1)
+}
@@ -4,8 +4,11 @@ import (
 	"fmt"
 
 	"github.com/jhump/protoreflect/desc/protoparse"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
 	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/warpstreamlabs/bento/public/service"
 )
 
 // RegistriesFromMap attempts to parse a map of filenames (relative to import
@@ -44,3 +47,83 @@ func RegistriesFromMap(filesMap map[string]string) (*protoregistry.Files, *proto
 	}
 	return files, types, nil
 }
+
+//------------------------------------------------------------------------------
+
+func schemaImportPathsField() *service.ConfigField {
+	return service.NewStringListField(fieldImportPaths).
+		Description("A list of directories containing .proto files or list of file paths, including all definitions required for parsing the target message. If left empty the current directory is used. Each directory listed will be walked with all found .proto files imported. Either this field or `bsr` must be populated.").
+		Default([]string{})
+}
+
+func schemaBSRField() *service.ConfigField {
+	return service.NewObjectListField(fieldBsrConfig,
+		service.NewStringField(fieldBsrModule).
+			Description("Module to fetch from a Buf Schema Registry e.g. 'buf.build/exampleco/mymodule'."),
+		service.NewStringField(fieldBSRUrl).
+			Description("Buf Schema Registry URL, leave blank to extract from module.").
+			Default("").Advanced(),
+		service.NewStringField(fieldBsrAPIKey).
+			Description("Buf Schema Registry server API key, can be left blank for a public registry.").
+			Secret().
+			Default(""),
+		service.NewStringField(fieldBsrVersion).
+			Description("Version to retrieve from the Buf Schema Registry, leave blank for latest.").
+			Default("").Advanced(),
+	).Description("Buf Schema Registry configuration. Either this field or `import_paths` must be populated. Note that this field is an array, and multiple BSR configurations can be provided.").
+		Default([]any{})
+}
+
+// schemaResolver provides access to the descriptors and types of a schema,
+// loaded either from .proto files or a Buf Schema Registry.
+type schemaResolver interface {
+	protoregistry.ExtensionTypeResolver
+	protoregistry.MessageTypeResolver
+
+	FindFileByPath(path string) (protoreflect.FileDescriptor, error)
+	FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error)
+}
+
+type fileSchema struct {
+	*protoregistry.Files
+	*protoregistry.Types
+}
+
+// schemaFromParsed loads a schema from the import_paths and bsr fields of a
+// parsed config.
+func schemaFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (schemaResolver, error) {
+	bsrModules, err := conf.FieldObjectList(fieldBsrConfig)
+	if err != nil {
+		return nil, err
+	}
+	if len(bsrModules) > 0 {
+		w, err := newMultiModuleWatcher(bsrModules)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create MultiModuleWatcher: %w", err)
+		}
+		return w, nil
+	}
+
+	importPaths, err := conf.FieldStringList(fieldImportPaths)
+	if err != nil {
+		return nil, err
+	}
+	files, types, err := loadDescriptors(mgr.FS(), importPaths)
+	if err != nil {
+		return nil, err
+	}
+	return fileSchema{Files: files, Types: types}, nil
+}
+
+// findService attempts to find a service descriptor by its fully qualified name.
+func findService(schema schemaResolver, name string) (protoreflect.ServiceDescriptor, error) {
+	d, err := schema.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("unable to find service '%v' definition: %w", name, err)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("descriptor '%v' is not a service, got %T", name, d)
+	}
+	return sd, nil
+}
@@ -0,0 +1,349 @@
+package protobuf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jhump/protoreflect/grpcreflect"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	gcFieldAddress    = "address"
+	gcFieldMethod     = "method"
+	gcFieldReflection = "reflection"
+	gcFieldMetadata   = "metadata"
+	gcFieldTimeout    = "timeout"
+	gcFieldTLS        = "tls"
+)
+
+func grpcClientFields() []*service.ConfigField {
+	return []*service.ConfigField{
+		service.NewStringField(gcFieldAddress).
+			Description("The address of the server to connect to.").
+			Example("localhost:50051"),
+		service.NewStringField(gcFieldMethod).
+			Description("The fully qualified name of the method to call, consisting of the service name and method name separated by a slash.").
+			Example("helloworld.Greeter/SayHello"),
+		service.NewBoolField(gcFieldReflection).
+			Description("Whether to obtain the definition of the method from the server using [gRPC server reflection](https://github.com/grpc/grpc/blob/master/doc/server-reflection.md), in which case `import_paths` and `bsr` are ignored.").
+			Default(false),
+		schemaImportPathsField(),
+		schemaBSRField(),
+		service.NewInterpolatedStringMapField(gcFieldMetadata).
+			Description("A map of metadata to add to each call.").
+			Example(map[string]any{"authorization": "Bearer ${TOKEN}"}).
+			Default(map[string]any{}),
+		service.NewDurationField(gcFieldTimeout).
+			Description("The maximum period to wait for a call to complete.").
+			Default("5s"),
+		service.NewTLSToggledField(gcFieldTLS),
+	}
+}
+
+const grpcClientLintRule = `
+root = match {
+this.reflection.or(false) => [],
+this.import_paths.type() == "unknown" && this.bsr.length() == 0 => [ "at least one of ` + "`import_paths`" + `, ` + "`bsr`" + ` or ` + "`reflection`" + ` must be set" ],
+this.import_paths.type() == "array" && this.import_paths.length() > 0 && this.bsr.length() > 0 => [ "both ` + "`import_paths`" + ` and ` + "`bsr`" + ` can't be set simultaneously" ],
+}`
+
+// grpcClient dynamically invokes a method of a gRPC server, converting
+// messages to and from the JSON mapping of the method types.
+type grpcClient struct {
+	address    string
+	service    string
+	methodName string
+	fullMethod string
+	metadata   map[string]*service.InterpolatedString
+	timeout    time.Duration
+	creds      credentials.TransportCredentials
+
+	useRefl  bool
+	connMut  sync.Mutex
+	conn     *grpc.ClientConn
+	method   protoreflect.MethodDescriptor
+	resolver typeResolver
+}
+
+type typeResolver interface {
+	protoregistry.MessageTypeResolver
+	protoregistry.ExtensionTypeResolver
+}
+
+func newGRPCClientFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (c *grpcClient, err error) {
+	c = &grpcClient{}
+	if c.address, err = conf.FieldString(gcFieldAddress); err != nil {
+		return nil, err
+	}
+
+	method, err := conf.FieldString(gcFieldMethod)
+	if err != nil {
+		return nil, err
+	}
+	method = strings.TrimPrefix(method, "/")
+	if i := strings.LastIndex(method, "/"); i > 0 {
+		c.service, c.methodName = method[:i], method[i+1:]
+	}
+	if c.service == "" || c.methodName == "" {
+		return nil, fmt.Errorf("method '%v' must consist of a service and method name separated by a slash", method)
+	}
+	c.fullMethod = "/" + c.service + "/" + c.methodName
+
+	if c.metadata, err = conf.FieldInterpolatedStringMap(gcFieldMetadata); err != nil {
+		return nil, err
+	}
+	if c.timeout, err = conf.FieldDuration(gcFieldTimeout); err != nil {
+		return nil, err
+	}
+
+	tlsConf, tlsEnabled, err := conf.FieldTLSToggled(gcFieldTLS)
+	if err != nil {
+		return nil, err
+	}
+	if tlsEnabled {
+		c.creds = credentials.NewTLS(tlsConf)
+	} else {
+		c.creds = insecure.NewCredentials()
+	}
+
+	if c.useRefl, err = conf.FieldBool(gcFieldReflection); err != nil {
+		return nil, err
+	}
+	if !c.useRefl {
+		schema, err := schemaFromParsed(conf, mgr)
+		if err != nil {
+			return nil, err
+		}
+		sd, err := findService(schema, c.service)
+		if err != nil {
+			return nil, err
+		}
+		if c.method = sd.Methods().ByName(protoreflect.Name(c.methodName)); c.method == nil {
+			return nil, fmt.Errorf("service '%v' does not have a method '%v'", c.service, c.methodName)
+		}
+		c.resolver = schema
+	}
+	return c, nil
+}
+
+// connect establishes a connection to the server, and resolves the method using
+// reflection when enabled.
+func (c *grpcClient) connect(ctx context.Context) (*grpc.ClientConn, error) {
+	c.connMut.Lock()
+	defer c.connMut.Unlock()
+
+	if c.conn != nil {
+		return c.conn, nil
+	}
+
+	conn, err := grpc.NewClient(c.address, grpc.WithTransportCredentials(c.creds))
+	if err != nil {
+		return nil, err
+	}
+
+	if c.useRefl {
+		method, resolver, err := c.reflectMethod(ctx, conn)
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		c.method, c.resolver = method, resolver
+	}
+	if c.method.IsStreamingClient() && c.method.IsStreamingServer() {
+		_ = conn.Close()
+		return nil, fmt.Errorf("method %v uses bidirectional streaming, which is not supported", c.fullMethod)
+	}
+
+	c.conn = conn
+	return conn, nil
+}
+
+func (c *grpcClient) reflectMethod(ctx context.Context, conn *grpc.ClientConn) (protoreflect.MethodDescriptor, typeResolver, error) {
+	ctx, done := context.WithTimeout(ctx, c.timeout)
+	defer done()
+
+	reflClient := grpcreflect.NewClientAuto(ctx, conn)
+	defer reflClient.Reset()
+
+	sd, err := reflClient.ResolveService(c.service)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve service '%v' via reflection: %w", c.service, err)
+	}
+
+	method := sd.UnwrapService().Methods().ByName(protoreflect.Name(c.methodName))
+	if method == nil {
+		return nil, nil, fmt.Errorf("service '%v' does not have a method '%v'", c.service, c.methodName)
+	}
+
+	files := &protoregistry.Files{}
+	if err := registerFileWithImports(files, sd.GetFile().UnwrapFile()); err != nil {
+		return nil, nil, err
+	}
+	return method, dynamicpb.NewTypes(files), nil
+}
+
+func registerFileWithImports(files *protoregistry.Files, fd protoreflect.FileDescriptor) error {
+	if _, err := files.FindFileByPath(fd.Path()); err == nil {
+		return nil
+	}
+	imports := fd.Imports()
+	for i := 0; i < imports.Len(); i++ {
+		if err := registerFileWithImports(files, imports.Get(i).FileDescriptor); err != nil {
+			return err
+		}
+	}
+	return files.RegisterFile(fd)
+}
+
+func (c *grpcClient) callContext(ctx context.Context, batch service.MessageBatch, index int) (context.Context, context.CancelFunc, error) {
+	ctx, done := context.WithTimeout(ctx, c.timeout)
+	if len(c.metadata) == 0 {
+		return ctx, done, nil
+	}
+
+	md := metadata.MD{}
+	for k, v := range c.metadata {
+		str, err := batch.TryInterpolatedString(index, v)
+		if err != nil {
+			done()
+			return nil, nil, fmt.Errorf("metadata %v interpolation: %w", k, err)
+		}
+		md.Set(k, str)
+	}
+	return metadata.NewOutgoingContext(ctx, md), done, nil
+}
+
+func (c *grpcClient) toRequest(msg *service.Message) (*dynamicpb.Message, error) {
+	msgBytes, err := msg.AsBytes()
+	if err != nil {
+		return nil, err
+	}
+	req := dynamicpb.NewMessage(c.method.Input())
+	if err := (protojson.UnmarshalOptions{Resolver: c.resolver}).Unmarshal(msgBytes, req); err != nil {
+		return nil, fmt.Errorf("failed to convert message to '%v': %w", c.method.Input().FullName(), err)
+	}
+	return req, nil
+}
+
+func (c *grpcClient) fromResponse(res *dynamicpb.Message) ([]byte, error) {
+	return protojson.MarshalOptions{Resolver: c.resolver}.Marshal(res)
+}
+
+// invoke calls a unary or server streaming method with a message, returning
+// the JSON of each response.
+func (c *grpcClient) invoke(ctx context.Context, batch service.MessageBatch, index int) ([][]byte, error) {
+	conn, err := c.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := c.toRequest(batch[index])
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, done, err := c.callContext(ctx, batch, index)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	if !c.method.IsStreamingServer() {
+		res := dynamicpb.NewMessage(c.method.Output())
+		if err := conn.Invoke(ctx, c.fullMethod, req, res); err != nil {
+			return nil, err
+		}
+		resBytes, err := c.fromResponse(res)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{resBytes}, nil
+	}
+
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, c.fullMethod)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	var results [][]byte
+	for {
+		res := dynamicpb.NewMessage(c.method.Output())
+		if err := stream.RecvMsg(res); err != nil {
+			if errors.Is(err, io.EOF) {
+				return results, nil
+			}
+			return nil, err
+		}
+		resBytes, err := c.fromResponse(res)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, resBytes)
+	}
+}
+
+// invokeClientStream calls a client streaming method with a request for each
+// message of a batch.
+func (c *grpcClient) invokeClientStream(ctx context.Context, batch service.MessageBatch) error {
+	conn, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	ctx, done, err := c.callContext(ctx, batch, 0)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ClientStreams: true}, c.fullMethod)
+	if err != nil {
+		return err
+	}
+	for _, msg := range batch {
+		req, err := c.toRequest(msg)
+		if err != nil {
+			return err
+		}
+		if err := stream.SendMsg(req); err != nil {
+			return err
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+	return stream.RecvMsg(dynamicpb.NewMessage(c.method.Output()))
+}
+
+func (c *grpcClient) close() error {
+	c.connMut.Lock()
+	defer c.connMut.Unlock()
+
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
@@ -0,0 +1,291 @@
+package protobuf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const greeterProto = `
+syntax = "proto3";
+package testing.greeter;
+
+service Greeter {
+  rpc SayHello (HelloRequest) returns (HelloReply) {}
+  rpc SayHellos (HelloRequest) returns (stream HelloReply) {}
+  rpc CountHellos (stream HelloRequest) returns (HelloReply) {}
+}
+
+message HelloRequest {
+  string name = 1;
+}
+
+message HelloReply {
+  string message = 1;
+}
+`
+
+func greeterServer(t testing.TB, extraConf string, handler func(batch service.MessageBatch) error) string {
+	t.Helper()
+
+	protoDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(protoDir, "greeter.proto"), []byte(greeterProto), 0o644))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	conf, err := grpcServerInputSpec().ParseYAML(fmt.Sprintf(`
+address: %v
+services: [ testing.greeter.Greeter ]
+import_paths: [ %v ]
+reflection: true
+%v
+`, addr, protoDir, extraConf), nil)
+	require.NoError(t, err)
+
+	in, err := newGRPCServerInputFromParsed(conf, service.MockResources())
+	require.NoError(t, err)
+	require.NoError(t, in.Connect(context.Background()))
+
+	ctx, done := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		done()
+		require.NoError(t, in.Close(context.Background()))
+	})
+
+	go func() {
+		for {
+			batch, ackFn, err := in.ReadBatch(ctx)
+			if err != nil {
+				return
+			}
+			_ = ackFn(ctx, handler(batch))
+		}
+	}()
+	return addr
+}
+
+func helloHandler(batch service.MessageBatch) error {
+	var names []string
+	for _, msg := range batch {
+		v, err := msg.AsStructured()
+		if err != nil {
+			return err
+		}
+		name, _ := v.(map[string]any)["name"].(string)
+		names = append(names, name)
+	}
+
+	res := batch[0].Copy()
+	res.SetStructured(map[string]any{
+		"message": "hello " + strings.Join(names, " and "),
+	})
+	if m, ok := batch[0].MetaGet("grpc_method"); ok {
+		res.MetaSetMut("x-method", m)
+	}
+	return service.MessageBatch{res}.AddSyncResponse()
+}
+
+func TestGRPCServerAndClientProcessor(t *testing.T) {
+	addr := greeterServer(t, "", helloHandler)
+
+	conf, err := grpcClientProcessorSpec().ParseYAML(fmt.Sprintf(`
+address: %v
+method: testing.greeter.Greeter/SayHello
+reflection: true
+metadata:
+  authorization: ${! @token }
+`, addr), nil)
+	require.NoError(t, err)
+
+	proc, err := newGRPCClientProcessorFromParsed(conf, service.MockResources())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, proc.Close(context.Background()))
+	})
+
+	inMsg := service.NewMessage([]byte(`{"name":"foo"}`))
+	inMsg.MetaSetMut("token", "bar")
+
+	batches, err := proc.ProcessBatch(context.Background(), service.MessageBatch{
+		inMsg,
+		service.NewMessage([]byte(`{"nope":"foo"}`)),
+	})
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0], 2)
+
+	require.NoError(t, batches[0][0].GetError())
+	resBytes, err := batches[0][0].AsBytes()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"message":"hello foo"}`, string(resBytes))
+	token, _ := batches[0][0].MetaGet("token")
+	assert.Equal(t, "bar", token)
+
+	require.Error(t, batches[0][1].GetError())
+	assert.Contains(t, batches[0][1].GetError().Error(), "failed to convert message")
+}
+
+func TestGRPCServerMetadata(t *testing.T) {
+	var received *service.Message
+	addr := greeterServer(t, `
+sync_response:
+  metadata_headers:
+    include_prefixes: [ x- ]
+`, func(batch service.MessageBatch) error {
+		received = batch[0]
+		return helloHandler(batch)
+	})
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "foo")
+
+	// Empty messages are compatible with any message type on the wire, and are
+	// therefore used in place of generated types.
+	var header metadata.MD
+	require.NoError(t, conn.Invoke(ctx, "/testing.greeter.Greeter/SayHello", &emptypb.Empty{}, &emptypb.Empty{}, grpc.Header(&header)))
+
+	assert.Equal(t, []string{"/testing.greeter.Greeter/SayHello"}, header.Get("x-method"))
+
+	method, _ := received.MetaGet("grpc_method")
+	assert.Equal(t, "/testing.greeter.Greeter/SayHello", method)
+	auth, _ := received.MetaGet("authorization")
+	assert.Equal(t, "foo", auth)
+}
+
+func TestGRPCServerErrors(t *testing.T) {
+	addr := greeterServer(t, "", func(batch service.MessageBatch) error {
+		name, _ := batch[0].AsStructured()
+		if name.(map[string]any)["name"] == "nack" {
+			return errors.New("nope")
+		}
+		res := batch[0].Copy()
+		res.SetError(errors.New("processing failed"))
+		return res.AddSyncResponse()
+	})
+
+	for _, test := range []struct {
+		method string
+		input  string
+		code   codes.Code
+		err    string
+	}{
+		{method: "SayHello", input: `{"name":"nack"}`, code: codes.Unavailable, err: "nope"},
+		{method: "SayHello", input: `{"name":"foo"}`, code: codes.Internal, err: "processing failed"},
+		{method: "SayHellos", input: `{"name":"foo"}`, code: codes.Unimplemented, err: "server streaming"},
+	} {
+		conf, err := grpcClientProcessorSpec().ParseYAML(fmt.Sprintf(`
+address: %v
+method: /testing.greeter.Greeter/%v
+reflection: true
+`, addr, test.method), nil)
+		require.NoError(t, err)
+
+		proc, err := newGRPCClientProcessorFromParsed(conf, service.MockResources())
+		require.NoError(t, err)
+
+		batches, err := proc.ProcessBatch(context.Background(), service.MessageBatch{
+			service.NewMessage([]byte(test.input)),
+		})
+		require.NoError(t, err)
+		require.Len(t, batches, 1)
+		require.Len(t, batches[0], 1)
+
+		msgErr := batches[0][0].GetError()
+		require.Error(t, msgErr, test.method)
+		assert.Equal(t, test.code, status.Code(msgErr), msgErr.Error())
+		assert.Contains(t, msgErr.Error(), test.err)
+
+		require.NoError(t, proc.Close(context.Background()))
+	}
+}
+
+func TestGRPCClientOutputStreaming(t *testing.T) {
+	batchChan := make(chan service.MessageBatch, 1)
+	addr := greeterServer(t, "", func(batch service.MessageBatch) error {
+		batchChan <- batch
+		return helloHandler(batch)
+	})
+
+	protoDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(protoDir, "greeter.proto"), []byte(greeterProto), 0o644))
+
+	conf, err := grpcClientOutputSpec().ParseYAML(fmt.Sprintf(`
+address: %v
+method: testing.greeter.Greeter/CountHellos
+import_paths: [ %v ]
+`, addr, protoDir), nil)
+	require.NoError(t, err)
+
+	out, err := newGRPCClientOutputFromParsed(conf, service.MockResources())
+	require.NoError(t, err)
+	require.NoError(t, out.Connect(context.Background()))
+	t.Cleanup(func() {
+		require.NoError(t, out.Close(context.Background()))
+	})
+
+	require.NoError(t, out.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte(`{"name":"a"}`)),
+		service.NewMessage([]byte(`{"name":"b"}`)),
+		service.NewMessage([]byte(`{"name":"c"}`)),
+	}))
+
+	batch := <-batchChan
+	require.Len(t, batch, 3)
+	for i, exp := range []string{"a", "b", "c"} {
+		b, err := batch[i].AsBytes()
+		require.NoError(t, err)
+		assert.JSONEq(t, fmt.Sprintf(`{"name":%q}`, exp), string(b))
+		method, _ := batch[i].MetaGet("grpc_method")
+		assert.Equal(t, "/testing.greeter.Greeter/CountHellos", method)
+	}
+}
+
+func TestGRPCClientConfigErrors(t *testing.T) {
+	for _, test := range []struct {
+		conf string
+		err  string
+	}{
+		{
+			conf: `method: SayHello`,
+			err:  "must consist of a service and method name",
+		},
+		{
+			conf: fmt.Sprintf("method: testing.Nope/Foo\nimport_paths: [ %v ]", protosPath),
+			err:  "unable to find service 'testing.Nope'",
+		},
+	} {
+		conf, err := grpcClientProcessorSpec().ParseYAML("address: localhost:50051\n"+test.conf, nil)
+		require.NoError(t, err)
+
+		_, err = newGRPCClientProcessorFromParsed(conf, service.MockResources())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), test.err)
+	}
+}
@@ -0,0 +1,452 @@
+package protobuf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/Jeffail/shutdown"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	gsiFieldAddress                 = "address"
+	gsiFieldServices                = "services"
+	gsiFieldReflection              = "reflection"
+	gsiFieldTLS                     = "tls"
+	gsiFieldTLSCertFile             = "cert_file"
+	gsiFieldTLSKeyFile              = "key_file"
+	gsiFieldResponse                = "sync_response"
+	gsiFieldResponseExtractMetadata = "metadata_headers"
+)
+
+func grpcServerInputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Version("1.14.0").
+		Categories("Network").
+		Summary("Creates a gRPC server that serves services defined by .proto files, where each call is consumed as messages.").
+		Description(`
+The definitions of the services, and all of the messages they use, are loaded either from .proto files found within `+"`import_paths`"+` or from a Buf Schema Registry with `+"`bsr`"+`. Requests are converted into JSON documents following the [JSON mapping of protobuf messages](https://developers.google.com/protocol-buffers/docs/proto3#json).
+
+Unary calls result in a single message, and client streaming calls result in a batch containing a message for each request received on the stream, which is consumed once the client closes the stream. A response is only returned once the message or batch has been acknowledged, where rejected messages result in an `+"`UNAVAILABLE`"+` status so that the client can retry the call. Server and bidirectional streaming calls are not supported and are rejected with an `+"`UNIMPLEMENTED`"+` status.
+
+### Responses
+
+It's possible to return a response for each call received using [synchronous responses](/docs/guides/sync_responses), where the first message of the response is converted from JSON into the response type of the method. When a synchronous response isn't set an empty response is returned. If the response message has failed processing the call is returned an `+"`INTERNAL`"+` status containing the error.
+
+### Metadata
+
+This input adds the following metadata fields to each message:
+
+`+"```text"+`
+- grpc_method (the full method name, e.g. /helloworld.Greeter/SayHello)
+- All metadata of the call, where only the first value of each key is used
+`+"```"+`
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#bloblang-queries).
+`).
+		Fields(
+			service.NewStringField(gsiFieldAddress).
+				Description("The address to listen from.").
+				Default("0.0.0.0:50051"),
+			service.NewStringListField(gsiFieldServices).
+				Description("A list of fully qualified names of the services to serve.").
+				Example([]string{"helloworld.Greeter"}),
+			schemaImportPathsField(),
+			schemaBSRField(),
+			service.NewBoolField(gsiFieldReflection).
+				Description("Whether to serve the [gRPC server reflection](https://github.com/grpc/grpc/blob/master/doc/server-reflection.md) service, allowing clients to discover the served services.").
+				Default(false),
+			service.NewObjectField(gsiFieldTLS,
+				service.NewStringField(gsiFieldTLSCertFile).
+					Description("A PEM encoded certificate file, when set along with `key_file` the server is served with TLS.").
+					Default(""),
+				service.NewStringField(gsiFieldTLSKeyFile).
+					Description("A PEM encoded private key file, when set along with `cert_file` the server is served with TLS.").
+					Default(""),
+			).
+				Description("TLS configuration of the server.").
+				Advanced(),
+			service.NewObjectField(gsiFieldResponse,
+				service.NewMetadataFilterField(gsiFieldResponseExtractMetadata).
+					Description("Specify criteria for which metadata values are sent as headers of the response."),
+			).
+				Description("Customise responses returned via [synchronous responses](/docs/guides/sync_responses).").
+				Advanced(),
+		).
+		LintRule(`
+root = match {
+this.import_paths.type() == "unknown" && this.bsr.length() == 0 => [ "at least one of `+"`import_paths`"+` and `+"`bsr`"+` must be set" ],
+this.import_paths.type() == "array" && this.import_paths.length() > 0 && this.bsr.length() > 0 => [ "both `+"`import_paths`"+` and `+"`bsr`"+` can't be set simultaneously" ],
+}`).
+		Example("Greeter Service", `
+Given the following definition within a directory called `+"`protos`"+`:
+
+`+"```protobuf"+`
+syntax = "proto3";
+package helloworld;
+
+service Greeter {
+  rpc SayHello (HelloRequest) returns (HelloReply) {}
+}
+
+message HelloRequest {
+  string name = 1;
+}
+
+message HelloReply {
+  string message = 1;
+}
+`+"```"+`
+
+We can implement the service with the following config:`, `
+input:
+  grpc_server:
+    address: 0.0.0.0:50051
+    services: [ helloworld.Greeter ]
+    import_paths: [ protos ]
+    reflection: true
+
+pipeline:
+  processors:
+    - mapping: |
+        root.message = "Hello " + this.name
+
+output:
+  sync_response: {}
+`)
+}
+
+func init() {
+	err := service.RegisterBatchInput("grpc_server", grpcServerInputSpec(), func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchInput, error) {
+		return newGRPCServerInputFromParsed(conf, mgr)
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type grpcTransaction struct {
+	batch   service.MessageBatch
+	resChan chan error
+}
+
+type grpcServerInput struct {
+	log *service.Logger
+
+	address    string
+	reflection bool
+	certFile   string
+	keyFile    string
+	metaFilter *service.MetadataFilter
+
+	schema   schemaResolver
+	services []protoreflect.ServiceDescriptor
+
+	server       *grpc.Server
+	transactions chan grpcTransaction
+	shutSig      *shutdown.Signaller
+}
+
+func newGRPCServerInputFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (g *grpcServerInput, err error) {
+	g = &grpcServerInput{
+		log:          mgr.Logger(),
+		transactions: make(chan grpcTransaction),
+		shutSig:      shutdown.NewSignaller(),
+	}
+	if g.address, err = conf.FieldString(gsiFieldAddress); err != nil {
+		return nil, err
+	}
+	if g.reflection, err = conf.FieldBool(gsiFieldReflection); err != nil {
+		return nil, err
+	}
+	if g.certFile, err = conf.FieldString(gsiFieldTLS, gsiFieldTLSCertFile); err != nil {
+		return nil, err
+	}
+	if g.keyFile, err = conf.FieldString(gsiFieldTLS, gsiFieldTLSKeyFile); err != nil {
+		return nil, err
+	}
+	if g.metaFilter, err = conf.FieldMetadataFilter(gsiFieldResponse, gsiFieldResponseExtractMetadata); err != nil {
+		return nil, err
+	}
+
+	serviceNames, err := conf.FieldStringList(gsiFieldServices)
+	if err != nil {
+		return nil, err
+	}
+	if len(serviceNames) == 0 {
+		return nil, errors.New("at least one service must be specified")
+	}
+
+	if g.schema, err = schemaFromParsed(conf, mgr); err != nil {
+		return nil, err
+	}
+	for _, name := range serviceNames {
+		sd, err := findService(g.schema, name)
+		if err != nil {
+			return nil, err
+		}
+		g.services = append(g.services, sd)
+	}
+	return g, nil
+}
+
+func (g *grpcServerInput) Connect(ctx context.Context) error {
+	if g.server != nil {
+		return nil
+	}
+
+	var opts []grpc.ServerOption
+	if g.certFile != "" && g.keyFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(g.certFile, g.keyFile)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	ln, err := net.Listen("tcp", g.address)
+	if err != nil {
+		return err
+	}
+
+	g.server = grpc.NewServer(opts...)
+	for _, sd := range g.services {
+		g.server.RegisterService(g.serviceDesc(sd), g)
+	}
+	if g.reflection {
+		reflOpts := reflection.ServerOptions{
+			Services:           g.server,
+			DescriptorResolver: reflectionResolver{schema: g.schema},
+		}
+		reflectionv1.RegisterServerReflectionServer(g.server, reflection.NewServerV1(reflOpts))
+		reflectionv1alpha.RegisterServerReflectionServer(g.server, reflection.NewServer(reflOpts))
+	}
+
+	go func() {
+		if err := g.server.Serve(ln); err != nil {
+			g.log.Errorf("gRPC server stopped: %v", err)
+		}
+	}()
+	g.log.Infof("Receiving gRPC calls at: %v", ln.Addr().String())
+	return nil
+}
+
+// serviceDesc creates a service description that dynamically handles each
+// method of a service.
+func (g *grpcServerInput) serviceDesc(sd protoreflect.ServiceDescriptor) *grpc.ServiceDesc {
+	desc := &grpc.ServiceDesc{
+		ServiceName: string(sd.FullName()),
+		HandlerType: (*any)(nil),
+		Metadata:    sd.ParentFile().Path(),
+	}
+
+	methods := sd.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		md := methods.Get(i)
+		fullMethod := fmt.Sprintf("/%v/%v", sd.FullName(), md.Name())
+
+		if !md.IsStreamingClient() && !md.IsStreamingServer() {
+			desc.Methods = append(desc.Methods, grpc.MethodDesc{
+				MethodName: string(md.Name()),
+				Handler: func(_ any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+					req := dynamicpb.NewMessage(md.Input())
+					if err := dec(req); err != nil {
+						return nil, err
+					}
+					msg, err := g.newMessage(ctx, fullMethod, req)
+					if err != nil {
+						return nil, err
+					}
+					return g.call(ctx, md, service.MessageBatch{msg})
+				},
+			})
+			continue
+		}
+
+		desc.Streams = append(desc.Streams, grpc.StreamDesc{
+			StreamName:    string(md.Name()),
+			ClientStreams: md.IsStreamingClient(),
+			ServerStreams: md.IsStreamingServer(),
+			Handler: func(_ any, stream grpc.ServerStream) error {
+				if md.IsStreamingServer() {
+					return status.Errorf(codes.Unimplemented, "method %v uses server streaming, which is not supported", fullMethod)
+				}
+
+				var batch service.MessageBatch
+				for {
+					req := dynamicpb.NewMessage(md.Input())
+					if err := stream.RecvMsg(req); err != nil {
+						if errors.Is(err, io.EOF) {
+							break
+						}
+						return err
+					}
+					msg, err := g.newMessage(stream.Context(), fullMethod, req)
+					if err != nil {
+						return err
+					}
+					batch = append(batch, msg)
+				}
+
+				res, err := g.call(stream.Context(), md, batch)
+				if err != nil {
+					return err
+				}
+				return stream.SendMsg(res)
+			},
+		})
+	}
+	return desc
+}
+
+func (g *grpcServerInput) newMessage(ctx context.Context, fullMethod string, req *dynamicpb.Message) (*service.Message, error) {
+	reqBytes, err := protojson.MarshalOptions{Resolver: g.schema}.Marshal(req)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to convert request to JSON: %v", err)
+	}
+
+	msg := service.NewMessage(reqBytes)
+	msg.MetaSetMut("grpc_method", fullMethod)
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for k, v := range md {
+			if len(v) > 0 {
+				msg.MetaSetMut(k, v[0])
+			}
+		}
+	}
+	return msg, nil
+}
+
+// call delivers a batch to the pipeline, waits for it to be acknowledged and
+// returns the response of the method.
+func (g *grpcServerInput) call(ctx context.Context, md protoreflect.MethodDescriptor, batch service.MessageBatch) (*dynamicpb.Message, error) {
+	res := dynamicpb.NewMessage(md.Output())
+	if len(batch) == 0 {
+		return res, nil
+	}
+
+	var store *service.SyncResponseStore
+	batch[0], store = batch[0].WithSyncResponseStore()
+
+	resChan := make(chan error, 1)
+	select {
+	case g.transactions <- grpcTransaction{batch: batch, resChan: resChan}:
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	case <-g.shutSig.SoftStopChan():
+		return nil, status.Error(codes.Unavailable, "server is shutting down")
+	}
+
+	select {
+	case err := <-resChan:
+		if err != nil {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	case <-g.shutSig.SoftStopChan():
+		return nil, status.Error(codes.Unavailable, "server is shutting down")
+	}
+
+	responses := store.Read()
+	if len(responses) == 0 || len(responses[0]) == 0 {
+		return res, nil
+	}
+
+	resMsg := responses[0][0]
+	if err := resMsg.GetError(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	header := metadata.MD{}
+	_ = g.metaFilter.Walk(resMsg, func(k, v string) error {
+		header.Append(k, v)
+		return nil
+	})
+	if len(header) > 0 {
+		if err := grpc.SetHeader(ctx, header); err != nil {
+			g.log.Warnf("Failed to set response headers: %v", err)
+		}
+	}
+
+	resBytes, err := resMsg.AsBytes()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if err := (protojson.UnmarshalOptions{Resolver: g.schema}).Unmarshal(resBytes, res); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to convert response from JSON: %v", err)
+	}
+	return res, nil
+}
+
+func (g *grpcServerInput) ReadBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
+	select {
+	case t := <-g.transactions:
+		return t.batch, func(ctx context.Context, err error) error {
+			t.resChan <- err
+			return nil
+		}, nil
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	case <-g.shutSig.SoftStopChan():
+		return nil, nil, service.ErrEndOfInput
+	}
+}
+
+func (g *grpcServerInput) Close(ctx context.Context) error {
+	g.shutSig.TriggerSoftStop()
+	if g.server == nil {
+		return nil
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		g.server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		g.server.Stop()
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// reflectionResolver resolves descriptors from a schema, falling back to the
+// global registry for the descriptors of the reflection service itself.
+type reflectionResolver struct {
+	schema schemaResolver
+}
+
+func (r reflectionResolver) FindFileByPath(path string) (protoreflect.FileDescriptor, error) {
+	if fd, err := r.schema.FindFileByPath(path); err == nil {
+		return fd, nil
+	}
+	return protoregistry.GlobalFiles.FindFileByPath(path)
+}
+
+func (r reflectionResolver) FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error) {
+	if d, err := r.schema.FindDescriptorByName(name); err == nil {
+		return d, nil
+	}
+	return protoregistry.GlobalFiles.FindDescriptorByName(name)
+}
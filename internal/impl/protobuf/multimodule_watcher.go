@@ -173,3 +173,31 @@ func (w *MultiModuleWatcher) FindEnumByName(enum protoreflect.FullName) (protore
 	}
 	return nil, fmt.Errorf("could not find %s in any loaded modules", enum)
 }
+
+func (w *MultiModuleWatcher) FindFileByPath(path string) (protoreflect.FileDescriptor, error) {
+	for _, schemaWatcher := range w.bsrClients {
+		fd, err := schemaWatcher.FindFileByPath(path)
+		if err != nil {
+			if errors.Is(err, protoregistry.NotFound) {
+				continue
+			}
+			return nil, err
+		}
+		return fd, nil
+	}
+	return nil, fmt.Errorf("could not find %s in any loaded modules", path)
+}
+
+func (w *MultiModuleWatcher) FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error) {
+	for _, schemaWatcher := range w.bsrClients {
+		d, err := schemaWatcher.FindDescriptorByName(name)
+		if err != nil {
+			if errors.Is(err, protoregistry.NotFound) {
+				continue
+			}
+			return nil, err
+		}
+		return d, nil
+	}
+	return nil, fmt.Errorf("could not find %s in any loaded modules", name)
+}
@@ -0,0 +1,98 @@
+package protobuf
+
+import (
+	"context"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const gcoFieldBatching = "batching"
+
+func grpcClientOutputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Version("1.14.0").
+		Categories("Network").
+		Summary("Calls a method of a gRPC server with messages.").
+		Description(`
+Messages are converted from JSON documents into the request type of the method following the [JSON mapping of protobuf messages](https://developers.google.com/protocol-buffers/docs/proto3#json). The definition of the method is loaded either from .proto files found within `+"`import_paths`"+`, from a Buf Schema Registry with `+"`bsr`"+`, or from the server itself with `+"`reflection`"+`.
+
+Unary and server streaming methods are called once for each message, where responses are discarded. Client streaming methods are called once for each batch, with a request for each message of the batch, and therefore the size of client streams can be controlled with a [batching policy](/docs/configuration/batching). Bidirectional streaming methods are not supported.
+`+service.OutputPerformanceDocs(true, true)).
+		Fields(grpcClientFields()...).
+		Fields(
+			service.NewOutputMaxInFlightField(),
+			service.NewBatchPolicyField(gcoFieldBatching),
+		).
+		LintRule(grpcClientLintRule).
+		Example("Record Events", "Send documents to a client streaming method in batches of 100 messages.", `
+output:
+  grpc_client:
+    address: events:50051
+    method: events.EventService/RecordEvents
+    import_paths: [ protos ]
+    batching:
+      count: 100
+      period: 1s
+`)
+}
+
+func init() {
+	err := service.RegisterBatchOutput("grpc_client", grpcClientOutputSpec(), func(conf *service.ParsedConfig, mgr *service.Resources) (out service.BatchOutput, batchPolicy service.BatchPolicy, maxInFlight int, err error) {
+		if maxInFlight, err = conf.FieldMaxInFlight(); err != nil {
+			return
+		}
+		if batchPolicy, err = conf.FieldBatchPolicy(gcoFieldBatching); err != nil {
+			return
+		}
+		out, err = newGRPCClientOutputFromParsed(conf, mgr)
+		return
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type grpcClientOutput struct {
+	client *grpcClient
+}
+
+func newGRPCClientOutputFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (*grpcClientOutput, error) {
+	client, err := newGRPCClientFromParsed(conf, mgr)
+	if err != nil {
+		return nil, err
+	}
+	return &grpcClientOutput{client: client}, nil
+}
+
+func (g *grpcClientOutput) Connect(ctx context.Context) error {
+	_, err := g.client.connect(ctx)
+	return err
+}
+
+func (g *grpcClientOutput) WriteBatch(ctx context.Context, batch service.MessageBatch) error {
+	if _, err := g.client.connect(ctx); err != nil {
+		return err
+	}
+	if g.client.method.IsStreamingClient() {
+		return g.client.invokeClientStream(ctx, batch)
+	}
+
+	var batchErr *service.BatchError
+	for i := range batch {
+		if _, err := g.client.invoke(ctx, batch, i); err != nil {
+			if batchErr == nil {
+				batchErr = service.NewBatchError(batch, err)
+			}
+			batchErr.Failed(i, err)
+		}
+	}
+	if batchErr != nil {
+		return batchErr
+	}
+	return nil
+}
+
+func (g *grpcClientOutput) Close(ctx context.Context) error {
+	return g.client.close()
+}
@@ -0,0 +1,90 @@
+package protobuf
+
+import (
+	"context"
+	"errors"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+func grpcClientProcessorSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Version("1.14.0").
+		Categories("Integration").
+		Summary("Calls a method of a gRPC server for each message, replacing the message with the response.").
+		Description(`
+Messages are converted from JSON documents into the request type of the method following the [JSON mapping of protobuf messages](https://developers.google.com/protocol-buffers/docs/proto3#json), and responses are converted back into JSON. The definition of the method is loaded either from .proto files found within `+"`import_paths`"+`, from a Buf Schema Registry with `+"`bsr`"+`, or from the server itself with `+"`reflection`"+`.
+
+Unary methods result in a single message, and server streaming methods result in a message for each response received on the stream, where a stream without responses results in the message being removed. Client and bidirectional streaming methods are not supported by this processor, use the [`+"`grpc_client`"+` output](/docs/components/outputs/grpc_client) in order to call client streaming methods.
+
+When a call fails the message is flagged with the error and left unchanged, and can be handled with [error handling patterns](/docs/configuration/error_handling).
+`).
+		Fields(grpcClientFields()...).
+		LintRule(grpcClientLintRule).
+		Example("Enrich Users", "Replace the document of each message with the result of calling a method, where the method is resolved via server reflection.", `
+pipeline:
+  processors:
+    - branch:
+        request_map: 'root.id = this.user_id'
+        processors:
+          - grpc_client:
+              address: users:50051
+              method: users.UserService/GetUser
+              reflection: true
+        result_map: 'root.user = this'
+`)
+}
+
+func init() {
+	err := service.RegisterBatchProcessor("grpc_client", grpcClientProcessorSpec(), func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchProcessor, error) {
+		return newGRPCClientProcessorFromParsed(conf, mgr)
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type grpcClientProcessor struct {
+	client *grpcClient
+}
+
+func newGRPCClientProcessorFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (*grpcClientProcessor, error) {
+	client, err := newGRPCClientFromParsed(conf, mgr)
+	if err != nil {
+		return nil, err
+	}
+	if client.method != nil && client.method.IsStreamingClient() {
+		return nil, errors.New("client streaming methods are not supported by this processor")
+	}
+	return &grpcClientProcessor{client: client}, nil
+}
+
+func (p *grpcClientProcessor) ProcessBatch(ctx context.Context, batch service.MessageBatch) ([]service.MessageBatch, error) {
+	if _, err := p.client.connect(ctx); err != nil {
+		return nil, err
+	}
+	if p.client.method.IsStreamingClient() {
+		return nil, errors.New("client streaming methods are not supported by this processor")
+	}
+
+	resBatch := make(service.MessageBatch, 0, len(batch))
+	for i, msg := range batch {
+		results, err := p.client.invoke(ctx, batch, i)
+		if err != nil {
+			msg.SetError(err)
+			resBatch = append(resBatch, msg)
+			continue
+		}
+		for _, res := range results {
+			resMsg := msg.Copy()
+			resMsg.SetBytes(res)
+			resBatch = append(resBatch, resMsg)
+		}
+	}
+	return []service.MessageBatch{resBatch}, nil
+}
+
+func (p *grpcClientProcessor) Close(ctx context.Context) error {
+	return p.client.close()
+}
@@ -63,24 +63,8 @@ Attempts to create a target protobuf message from a generic JSON structure.
 		service.NewBoolField(fieldUseProtoNames).
 			Description("If `true`, the `to_json` operator deserializes fields exactly as named in schema file.").
 			Default(false),
-		service.NewStringListField(fieldImportPaths).
-			Description("A list of directories containing .proto files or list of file paths, including all definitions required for parsing the target message. If left empty the current directory is used. Each directory listed will be walked with all found .proto files imported. Either this field or `bsr` must be populated.").
-			Default([]string{}),
-		service.NewObjectListField(fieldBsrConfig,
-			service.NewStringField(fieldBsrModule).
-				Description("Module to fetch from a Buf Schema Registry e.g. 'buf.build/exampleco/mymodule'."),
-			service.NewStringField(fieldBSRUrl).
-				Description("Buf Schema Registry URL, leave blank to extract from module.").
-				Default("").Advanced(),
-			service.NewStringField(fieldBsrAPIKey).
-				Description("Buf Schema Registry server API key, can be left blank for a public registry.").
-				Secret().
-				Default(""),
-			service.NewStringField(fieldBsrVersion).
-				Description("Version to retrieve from the Buf Schema Registry, leave blank for latest.").
-				Default("").Advanced(),
-		).Description("Buf Schema Registry configuration. Either this field or `import_paths` must be populated. Note that this field is an array, and multiple BSR configurations can be provided.").
-			Default([]any{}),
+		schemaImportPathsField(),
+		schemaBSRField(),
 	).LintRule(`
 root = match {
 this.import_paths.type() == "unknown" && this.bsr.length() == 0 => [ "at least one of `+"`import_paths`"+`and `+"`bsr`"+` must be set" ],
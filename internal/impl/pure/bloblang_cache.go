@@ -2,6 +2,9 @@ package pure
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
 	"sync/atomic"
 
 	"github.com/warpstreamlabs/bento/internal/bloblang/query"
@@ -24,6 +27,10 @@ func init() {
 		panic(err)
 	}
 
+	if err := registerBloblangSketchFunctions(getManager); err != nil {
+		panic(err)
+	}
+
 	if err := service.RegisterManagedConstructor(func(mgr service.LimitedResources) error {
 		container.Store(mgr)
 		return nil
@@ -178,5 +185,213 @@ func registerBloblangFunctions(getMgr func() service.LimitedResources) error {
 		return err
 	}
 
+	if err := bloblang.RegisterFunctionV2("cache_get_multi",
+		bloblang.NewPluginSpec().
+			Experimental().
+			Impure().
+			Category(query.FunctionCategoryEnvironment).
+			Description("Used to retrieve the cached values of multiple keys from a cache resource, returning an object of keys to values. Keys that do not exist are omitted from the result. Caches that support it retrieve all keys in a single request.").
+			Param(bloblang.NewStringParam("resource").Description("The name of the `cache` resource to target.")).
+			Param(bloblang.NewAnyParam("keys").Description("An array of keys of the values to retrieve from the `cache` resource.")),
+		func(args *bloblang.ParsedParams) (bloblang.Function, error) {
+			resource, err := args.GetString("resource")
+			if err != nil {
+				return nil, err
+			}
+			keysV, err := args.Get("keys")
+			if err != nil {
+				return nil, err
+			}
+			keysArr, ok := keysV.([]any)
+			if !ok {
+				return nil, fmt.Errorf("expected keys to be an array, got %T", keysV)
+			}
+			keys := make([]string, 0, len(keysArr))
+			for i, k := range keysArr {
+				ks, ok := k.(string)
+				if !ok {
+					return nil, fmt.Errorf("expected keys to be an array of strings, got %T at index %v", k, i)
+				}
+				keys = append(keys, ks)
+			}
+
+			return func() (any, error) {
+				var (
+					values map[string][]byte
+					cerr   error
+				)
+				ctx := context.Background()
+				if err := getMgr().AccessCache(ctx, resource, func(c service.Cache) {
+					mc, ok := c.(service.MultiGetCache)
+					if !ok {
+						cerr = service.ErrCacheOperationNotSupported
+						return
+					}
+					values, cerr = mc.GetMulti(ctx, keys...)
+				}); err != nil {
+					return nil, err
+				}
+				if cerr != nil {
+					return nil, cerr
+				}
+				output := make(map[string]any, len(values))
+				for k, v := range values {
+					output[k] = v
+				}
+				return output, nil
+			}, nil
+		}); err != nil {
+		return err
+	}
+
+	if err := bloblang.RegisterFunctionV2("cache_increment",
+		bloblang.NewPluginSpec().
+			Experimental().
+			Impure().
+			Category(query.FunctionCategoryEnvironment).
+			Description("Atomically add a delta to the integer value of a key in a cache resource and return the result. If the key does not exist it is treated as zero. This is only supported by some caches, including `memory`, `redis`, `sql`, `aws_dynamodb` and `nats_kv`.").
+			Param(bloblang.NewStringParam("resource").Description("The name of the `cache` resource to target.")).
+			Param(bloblang.NewStringParam("key").Description("A key to use with the `cache`.")).
+			Param(bloblang.NewInt64Param("delta").Description("The amount to add to the value, which can be negative.").Default(1)),
+		func(args *bloblang.ParsedParams) (bloblang.Function, error) {
+			resource, err := args.GetString("resource")
+			if err != nil {
+				return nil, err
+			}
+			key, err := args.GetString("key")
+			if err != nil {
+				return nil, err
+			}
+			delta, err := args.GetInt64("delta")
+			if err != nil {
+				return nil, err
+			}
+
+			return func() (any, error) {
+				var (
+					output int64
+					cerr   error
+				)
+				ctx := context.Background()
+				if err := getMgr().AccessCache(ctx, resource, func(c service.Cache) {
+					ic, ok := c.(service.IncrementCache)
+					if !ok {
+						cerr = service.ErrCacheOperationNotSupported
+						return
+					}
+					output, cerr = ic.Increment(ctx, key, delta, nil)
+				}); err != nil {
+					return nil, err
+				}
+				if cerr != nil {
+					return nil, cerr
+				}
+				return output, nil
+			}, nil
+		}); err != nil {
+		return err
+	}
+
+	if err := bloblang.RegisterFunctionV2("cache_compare_and_swap",
+		bloblang.NewPluginSpec().
+			Experimental().
+			Impure().
+			Category(query.FunctionCategoryEnvironment).
+			Description("Atomically set a key in the cache resource to a value only if its current contents match an expected value. Returns `true` if the value was set and `false` if the contents did not match. If the key does not exist the action fails with a 'key does not exist' error. This is only supported by some caches, including `memory`, `redis`, `sql`, `aws_dynamodb` and `nats_kv`.").
+			Param(bloblang.NewStringParam("resource").Description("The name of the `cache` resource to target.")).
+			Param(bloblang.NewStringParam("key").Description("A key to use with the `cache`.")).
+			Param(bloblang.NewStringParam("expected").Description("The value that the key must currently be set to.")).
+			Param(bloblang.NewStringParam("value").Description("A value to use with the `cache`.")),
+		func(args *bloblang.ParsedParams) (bloblang.Function, error) {
+			resource, err := args.GetString("resource")
+			if err != nil {
+				return nil, err
+			}
+			key, err := args.GetString("key")
+			if err != nil {
+				return nil, err
+			}
+			expected, err := args.GetString("expected")
+			if err != nil {
+				return nil, err
+			}
+			value, err := args.GetString("value")
+			if err != nil {
+				return nil, err
+			}
+
+			return func() (any, error) {
+				var cerr error
+				ctx := context.Background()
+				if err := getMgr().AccessCache(ctx, resource, func(c service.Cache) {
+					cc, ok := c.(service.CompareAndSwapCache)
+					if !ok {
+						cerr = service.ErrCacheOperationNotSupported
+						return
+					}
+					cerr = cc.CompareAndSwap(ctx, key, []byte(expected), []byte(value), nil)
+				}); err != nil {
+					return nil, err
+				}
+				if errors.Is(cerr, service.ErrCompareAndSwapFailed) {
+					return false, nil
+				}
+				if cerr != nil {
+					return nil, cerr
+				}
+				return true, nil
+			}, nil
+		}); err != nil {
+		return err
+	}
+
+	if err := bloblang.RegisterFunctionV2("cache_scan",
+		bloblang.NewPluginSpec().
+			Experimental().
+			Impure().
+			Category(query.FunctionCategoryEnvironment).
+			Description("List the keys of a cache resource that begin with a prefix, returning an array of keys in lexicographical order. An empty prefix lists all keys of the cache. This is only supported by some caches, including `memory`, `redis`, `sql`, `aws_dynamodb` and `nats_kv`.").
+			Param(bloblang.NewStringParam("resource").Description("The name of the `cache` resource to target.")).
+			Param(bloblang.NewStringParam("prefix").Description("The prefix that listed keys must begin with.").Default("")),
+		func(args *bloblang.ParsedParams) (bloblang.Function, error) {
+			resource, err := args.GetString("resource")
+			if err != nil {
+				return nil, err
+			}
+			prefix, err := args.GetString("prefix")
+			if err != nil {
+				return nil, err
+			}
+
+			return func() (any, error) {
+				var (
+					keys []string
+					cerr error
+				)
+				ctx := context.Background()
+				if err := getMgr().AccessCache(ctx, resource, func(c service.Cache) {
+					sc, ok := c.(service.ScanCache)
+					if !ok {
+						cerr = service.ErrCacheOperationNotSupported
+						return
+					}
+					keys, cerr = sc.Scan(ctx, prefix)
+				}); err != nil {
+					return nil, err
+				}
+				if cerr != nil {
+					return nil, cerr
+				}
+				sort.Strings(keys)
+				output := make([]any, len(keys))
+				for i, k := range keys {
+					output[i] = k
+				}
+				return output, nil
+			}, nil
+		}); err != nil {
+		return err
+	}
+
 	return nil
 }
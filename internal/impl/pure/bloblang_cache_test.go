@@ -109,6 +109,106 @@ mapping: |
 			},
 			expected: []string{`{"add_result":null,"delete_result":null,"get_after_delete":"deleted","get_after_set":"crud_val_updated","get_result":"crud_val","set_result":null}`},
 		},
+		{
+			name: "cache_increment",
+			pConf: `
+mapping: |
+   _ = cache_increment(resource: "local", key: "counter", delta: 5)
+   root = cache_increment(resource: "local", key: "counter")`,
+			input: [][]byte{
+				[]byte(`{}`),
+			},
+			expected: []string{"6"},
+		},
+		{
+			name: "cache_compare_and_swap",
+			pConf: `
+mapping: |
+   root.set = cache_set(resource: "local", key: "cas_key", value: "first")
+   root.mismatch = cache_compare_and_swap(resource: "local", key: "cas_key", expected: "nope", value: "second")
+   root.match = cache_compare_and_swap(resource: "local", key: "cas_key", expected: "first", value: "second")
+   root.value = cache_get(resource: "local", key: "cas_key").string()`,
+			input: [][]byte{
+				[]byte(`{}`),
+			},
+			expected: []string{`{"match":true,"mismatch":false,"set":null,"value":"second"}`},
+		},
+		{
+			name: "err when compare and swap key does not exist",
+			pConf: `
+mapping: |
+   root = cache_compare_and_swap(resource: "local", key: "dne", expected: "a", value: "b")`,
+			input: [][]byte{
+				[]byte(`{}`),
+			},
+			expectError: true,
+		},
+		{
+			name: "cache_get_multi",
+			pConf: `
+mapping: |
+   _ = cache_set(resource: "local", key: "multi_1", value: "foo")
+   _ = cache_set(resource: "local", key: "multi_2", value: "bar")
+   root = cache_get_multi(resource: "local", keys: [ "multi_1", "multi_2", "dne" ]).map_each(kv -> kv.value.string())`,
+			input: [][]byte{
+				[]byte(`{}`),
+			},
+			expected: []string{`{"multi_1":"foo","multi_2":"bar"}`},
+		},
+		{
+			name: "cache_scan",
+			pConf: `
+mapping: |
+   let b = cache_set(resource: "local", key: "scan_b", value: "foo")
+   let a = cache_set(resource: "local", key: "scan_a", value: "bar")
+   let other = cache_set(resource: "local", key: "other", value: "baz")
+   root.prefixed = cache_scan(resource: "local", prefix: "scan_")
+   root.all = cache_scan(resource: "local")`,
+			input: [][]byte{
+				[]byte(`{}`),
+			},
+			expected: []string{`{"all":["other","scan_a","scan_b"],"prefixed":["scan_a","scan_b"]}`},
+		},
+		{
+			name: "hll_add and hll_count",
+			pConf: `
+mapping: |
+   root.missing = hll_count(resource: "local", key: "users")
+   root.first = hll_add(resource: "local", key: "users", value: "foo")
+   root.repeated = hll_add(resource: "local", key: "users", value: "foo")
+   let bar = hll_add(resource: "local", key: "users", value: "bar")
+   let ten = hll_add(resource: "local", key: "users", value: 10)
+   root.count = hll_count(resource: "local", key: "users")`,
+			input: [][]byte{
+				[]byte(`{}`),
+			},
+			expected: []string{`{"count":3,"first":1,"missing":0,"repeated":1}`},
+		},
+		{
+			name: "err when hll key holds another value",
+			pConf: `
+mapping: |
+   _ = cache_set(resource: "local", key: "users", value: "nope")
+   root = hll_count(resource: "local", key: "users")`,
+			input: [][]byte{
+				[]byte(`{}`),
+			},
+			expectError: true,
+		},
+		{
+			name: "count_min_add and count_min_estimate",
+			pConf: `
+mapping: |
+   root.missing = count_min_estimate(resource: "local", key: "paths", value: "/foo")
+   root.first = count_min_add(resource: "local", key: "paths", value: "/foo")
+   root.second = count_min_add(resource: "local", key: "paths", value: "/foo", count: 4)
+   let bar = count_min_add(resource: "local", key: "paths", value: "/bar")
+   root.estimate = count_min_estimate(resource: "local", key: "paths", value: "/foo")`,
+			input: [][]byte{
+				[]byte(`{}`),
+			},
+			expected: []string{`{"estimate":5,"first":1,"missing":0,"second":5}`},
+		},
 	}
 
 	for _, tt := range tests {
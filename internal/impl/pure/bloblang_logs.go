@@ -0,0 +1,831 @@
+package pure
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang/query"
+	"github.com/warpstreamlabs/bento/internal/value"
+	"github.com/warpstreamlabs/bento/public/bloblang"
+)
+
+// syslogParseMethod creates a method that parses syslog messages. Parsers hold
+// state whilst parsing and therefore cannot be used concurrently, so a pool of
+// parsers is kept for each method rather than serialising calls.
+func syslogParseMethod(newParser func() (parserFormat, error)) (bloblang.Method, error) {
+	first, err := newParser()
+	if err != nil {
+		return nil, err
+	}
+
+	pool := &sync.Pool{
+		New: func() any {
+			// Parser options are validated by the first parser, and so
+			// subsequent parsers cannot fail.
+			p, _ := newParser()
+			return p
+		},
+	}
+	pool.Put(first)
+
+	return bloblang.BytesMethod(func(b []byte) (any, error) {
+		parse := pool.Get().(parserFormat)
+		res, err := parse(b)
+		pool.Put(parse)
+		if err != nil {
+			return nil, err
+		}
+		return sanitiseSyslogFields(res), nil
+	}), nil
+}
+
+func init() {
+	if err := bloblang.RegisterMethodV2("parse_logfmt",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description("Parses a [logfmt](https://brandur.org/logfmt) formatted log line into an object. Values are always parsed as strings, and keys without a value are set to `true`.").
+			Example("", `root = this.log.parse_logfmt()`,
+				[2]string{
+					`{"log":"level=info msg=\"user logged in\" user_id=42 admin"}`,
+					`{"admin":true,"level":"info","msg":"user logged in","user_id":"42"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return parseLogfmt(s)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("format_logfmt",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description("Formats an object as a [logfmt](https://brandur.org/logfmt) log line with keys sorted alphabetically. Values containing spaces, quotes or equals signs are quoted, and objects and arrays are formatted as JSON.").
+			Example("", `root = this.format_logfmt()`,
+				[2]string{
+					`{"level":"info","msg":"user logged in","user_id":42}`,
+					`level=info msg="user logged in" user_id=42`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.ObjectMethod(func(obj map[string]any) (any, error) {
+				return formatLogfmt(obj)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("parse_cef",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description("Parses a log line in the ArcSight Common Event Format (CEF) into an object containing the fields `version`, `device_vendor`, `device_product`, `device_version`, `device_event_class_id`, `name`, `severity` and `extensions`. Any prefix before the `CEF:` header, such as a syslog header, is ignored.").
+			Example("", `root = this.log.parse_cef()`,
+				[2]string{
+					`{"log":"CEF:0|Security|threatmanager|1.0|100|worm successfully stopped|10|src=10.0.0.1 dst=2.1.2.2 msg=Detected a threat\\=worm"}`,
+					`{"device_event_class_id":"100","device_product":"threatmanager","device_vendor":"Security","device_version":"1.0","extensions":{"dst":"2.1.2.2","msg":"Detected a threat=worm","src":"10.0.0.1"},"name":"worm successfully stopped","severity":"10","version":0}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return parseCEF(s)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("format_cef",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description("Formats an object as an ArcSight Common Event Format (CEF) log line. The object is expected to follow the structure produced by [`parse_cef`](#parse_cef), where missing header fields are left empty and extensions are sorted by key.").
+			Example("", `root = this.format_cef()`,
+				[2]string{
+					`{"device_vendor":"Security","device_product":"threatmanager","device_version":"1.0","device_event_class_id":"100","name":"worm stopped","severity":10,"extensions":{"src":"10.0.0.1","msg":"threat=worm"}}`,
+					`CEF:0|Security|threatmanager|1.0|100|worm stopped|10|msg=threat\=worm src=10.0.0.1`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.ObjectMethod(func(obj map[string]any) (any, error) {
+				return formatCEF(obj)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("parse_leef",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description("Parses a log line in the IBM Log Event Extended Format (LEEF) versions 1.0 and 2.0 into an object containing the fields `version`, `vendor`, `product`, `product_version`, `event_id` and `attributes`. For LEEF 2.0 the attribute delimiter is also included as the field `delimiter`. Any prefix before the `LEEF:` header, such as a syslog header, is ignored.").
+			Example("", `root = this.log.parse_leef()`,
+				[2]string{
+					`{"log":"LEEF:2.0|Lancope|StealthWatch|1.0|41|^|src=10.0.1.8^dst=10.0.0.5^sev=5"}`,
+					`{"attributes":{"dst":"10.0.0.5","sev":"5","src":"10.0.1.8"},"delimiter":"^","event_id":"41","product":"StealthWatch","product_version":"1.0","vendor":"Lancope","version":"2.0"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return parseLEEF(s)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("format_leef",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description("Formats an object as an IBM Log Event Extended Format (LEEF) log line. The object is expected to follow the structure produced by [`parse_leef`](#parse_leef), where the version defaults to `1.0`, the delimiter of LEEF 2.0 defaults to a tab, and attributes are sorted by key.").
+			Example("", `root = this.format_leef()`,
+				[2]string{
+					`{"version":"2.0","vendor":"Lancope","product":"StealthWatch","product_version":"1.0","event_id":"41","delimiter":"^","attributes":{"src":"10.0.1.8","dst":"10.0.0.5"}}`,
+					`LEEF:2.0|Lancope|StealthWatch|1.0|41|^|dst=10.0.0.5^src=10.0.1.8`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.ObjectMethod(func(obj map[string]any) (any, error) {
+				return formatLEEF(obj)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("parse_syslog_rfc5424",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description("Parses a log line following the [Syslog rfc5424](https://tools.ietf.org/html/rfc5424) spec into an object, following the same structure as the `syslog_rfc5424` format of the [`parse_log` processor](/docs/components/processors/parse_log).").
+			Param(bloblang.NewBoolParam("best_effort").Description("Whether to return partially parsed messages rather than an error when the log line is not fully compliant.").Default(true)).
+			Example("", `root = this.log.parse_syslog_rfc5424()`,
+				[2]string{
+					`{"log":"<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut=\"3\"] An application event"}`,
+					`{"appname":"evntslog","facility":20,"hostname":"mymachine.example.com","message":"An application event","msgid":"ID47","priority":165,"severity":5,"structureddata":{"exampleSDID@32473":{"iut":"3"}},"timestamp":"2003-10-11T22:14:15.003Z","version":1}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			bestEffort, err := args.GetBool("best_effort")
+			if err != nil {
+				return nil, err
+			}
+			return syslogParseMethod(func() (parserFormat, error) {
+				return parserRFC5424(bestEffort), nil
+			})
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("parse_syslog_rfc3164",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description("Parses a log line following the [Syslog rfc3164](https://tools.ietf.org/html/rfc3164) spec into an object, following the same structure as the `syslog_rfc3164` format of the [`parse_log` processor](/docs/components/processors/parse_log).").
+			Param(bloblang.NewBoolParam("best_effort").Description("Whether to return partially parsed messages rather than an error when the log line is not fully compliant.").Default(true)).
+			Param(bloblang.NewBoolParam("allow_rfc3339").Description("Whether to also accept timestamps in rfc3339 format.").Default(true)).
+			Param(bloblang.NewStringParam("default_year").Description("The year to set for timestamps, which do not include one. When set to `current` the current year is used, and when empty no year is set.").Default("current")).
+			Param(bloblang.NewStringParam("default_timezone").Description("The timezone to set for timestamps, which do not include one. This value should follow the [time.LoadLocation](https://golang.org/pkg/time/#LoadLocation) format.").Default("UTC")).
+			Example("", `root = this.log.parse_syslog_rfc3164()`,
+				[2]string{
+					`{"log":"<34>2003-10-11T22:14:15Z mymachine su[123]: 'su root' failed for lonvick on /dev/pts/8"}`,
+					`{"appname":"su","facility":4,"hostname":"mymachine","message":"'su root' failed for lonvick on /dev/pts/8","priority":34,"procid":"123","severity":2,"timestamp":"2003-10-11T22:14:15Z"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			bestEffort, err := args.GetBool("best_effort")
+			if err != nil {
+				return nil, err
+			}
+			allowRFC3339, err := args.GetBool("allow_rfc3339")
+			if err != nil {
+				return nil, err
+			}
+			defaultYear, err := args.GetString("default_year")
+			if err != nil {
+				return nil, err
+			}
+			defaultTZ, err := args.GetString("default_timezone")
+			if err != nil {
+				return nil, err
+			}
+			return syslogParseMethod(func() (parserFormat, error) {
+				return parserRFC3164(bestEffort, allowRFC3339, defaultYear, defaultTZ)
+			})
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("format_syslog_rfc5424",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description("Formats an object as a [Syslog rfc5424](https://tools.ietf.org/html/rfc5424) log line. The object is expected to follow the structure produced by [`parse_syslog_rfc5424`](#parse_syslog_rfc5424). The priority is taken from the field `priority`, or otherwise calculated from the fields `facility` and `severity`, which default to `1` (user) and `5` (notice) respectively. Missing header fields are written as `-`.").
+			Example("", `root = this.format_syslog_rfc5424()`,
+				[2]string{
+					`{"facility":20,"severity":5,"timestamp":"2003-10-11T22:14:15.003Z","hostname":"mymachine.example.com","appname":"evntslog","msgid":"ID47","structureddata":{"exampleSDID@32473":{"iut":"3"}},"message":"An application event"}`,
+					`<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3"] An application event`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.ObjectMethod(func(obj map[string]any) (any, error) {
+				return formatSyslogRFC5424(obj)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("format_syslog_rfc3164",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description("Formats an object as a [Syslog rfc3164](https://tools.ietf.org/html/rfc3164) log line. The object is expected to follow the structure produced by [`parse_syslog_rfc3164`](#parse_syslog_rfc3164). The priority is taken from the field `priority`, or otherwise calculated from the fields `facility` and `severity`, which default to `1` (user) and `5` (notice) respectively. When the field `timestamp` is missing the current time is used.").
+			Example("", `root = this.format_syslog_rfc3164()`,
+				[2]string{
+					`{"facility":4,"severity":2,"timestamp":"2003-10-11T22:14:15Z","hostname":"mymachine","appname":"su","procid":"123","message":"'su root' failed for lonvick on /dev/pts/8"}`,
+					`<34>Oct 11 22:14:15 mymachine su[123]: 'su root' failed for lonvick on /dev/pts/8`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.ObjectMethod(func(obj map[string]any) (any, error) {
+				return formatSyslogRFC3164(obj)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+func isLogfmtSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+func parseLogfmt(s string) (map[string]any, error) {
+	res := map[string]any{}
+	for i := 0; i < len(s); {
+		if isLogfmtSpace(s[i]) {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(s) && !isLogfmtSpace(s[i]) && s[i] != '=' && s[i] != '"' {
+			i++
+		}
+		if i == start {
+			return nil, fmt.Errorf("unexpected character '%c' at position %v", s[i], i)
+		}
+		key := s[start:i]
+
+		if i >= len(s) || s[i] != '=' {
+			if i < len(s) && s[i] == '"' {
+				return nil, fmt.Errorf("unexpected character '%c' at position %v", s[i], i)
+			}
+			res[key] = true
+			continue
+		}
+		i++
+
+		if i < len(s) && s[i] == '"' {
+			end := i + 1
+			for ; end < len(s) && s[end] != '"'; end++ {
+				if s[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("unterminated quoted value of key '%v'", key)
+			}
+			v, err := strconv.Unquote(s[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted value of key '%v': %w", key, err)
+			}
+			res[key] = v
+			i = end + 1
+			continue
+		}
+
+		start = i
+		for i < len(s) && !isLogfmtSpace(s[i]) {
+			i++
+		}
+		res[key] = s[start:i]
+	}
+	return res, nil
+}
+
+func sortedKeys(obj map[string]any) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatLogfmt(obj map[string]any) (string, error) {
+	var b strings.Builder
+	for _, k := range sortedKeys(obj) {
+		if k == "" || strings.IndexFunc(k, func(r rune) bool {
+			return r <= ' ' || r == '=' || r == '"'
+		}) >= 0 {
+			return "", fmt.Errorf("key '%v' cannot be represented in logfmt", k)
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(k)
+		b.WriteByte('=')
+
+		v := obj[k]
+		if v == nil {
+			continue
+		}
+		s := value.IToString(v)
+		if strings.IndexFunc(s, func(r rune) bool {
+			return r <= ' ' || r == '=' || r == '"' || r == '\\' || r == 0x7f
+		}) >= 0 {
+			s = strconv.Quote(s)
+		}
+		b.WriteString(s)
+	}
+	return b.String(), nil
+}
+
+//------------------------------------------------------------------------------
+
+// splitLogHeader splits the first n fields of a pipe delimited header, where
+// pipes and backslashes within fields are escaped with a backslash, and returns
+// the remainder of the line following the final field.
+func splitLogHeader(s string, n int) ([]string, string, error) {
+	fields := make([]string, 0, n)
+
+	var b strings.Builder
+	i := 0
+	for ; i < len(s) && len(fields) < n; i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s) && (s[i+1] == '|' || s[i+1] == '\\'):
+			i++
+			b.WriteByte(s[i])
+		case c == '|':
+			fields = append(fields, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	if len(fields) < n {
+		return nil, "", fmt.Errorf("expected %v header fields, found %v", n, len(fields))
+	}
+	return fields, s[i:], nil
+}
+
+var logHeaderEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`)
+
+func logHeaderField(obj map[string]any, key string) string {
+	v, exists := obj[key]
+	if !exists || v == nil {
+		return ""
+	}
+	return logHeaderEscaper.Replace(value.IToString(v))
+}
+
+func parseCEF(s string) (map[string]any, error) {
+	idx := strings.Index(s, "CEF:")
+	if idx < 0 {
+		return nil, errors.New("missing CEF header")
+	}
+
+	header, extStr, err := splitLogHeader(s[idx+4:], 7)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := strconv.ParseInt(strings.TrimSpace(header[0]), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CEF version: %w", err)
+	}
+
+	extensions, err := parseCEFExtensions(extStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"version":               version,
+		"device_vendor":         header[1],
+		"device_product":        header[2],
+		"device_version":        header[3],
+		"device_event_class_id": header[4],
+		"name":                  header[5],
+		"severity":              header[6],
+		"extensions":            extensions,
+	}, nil
+}
+
+var cefExtensionUnescaper = strings.NewReplacer(`\\`, `\`, `\=`, `=`, `\n`, "\n", `\r`, "\r", `\|`, `|`)
+
+// parseCEFExtensions parses space separated key value pairs, where values can
+// contain spaces and therefore end where the key of the following pair begins.
+func parseCEFExtensions(s string) (map[string]any, error) {
+	type extKey struct {
+		name            string
+		start, valStart int
+	}
+
+	var keys []extKey
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '=':
+			start := i
+			for start > 0 && s[start-1] != ' ' {
+				start--
+			}
+			if start == i {
+				return nil, fmt.Errorf("missing extension key at position %v", i)
+			}
+			if len(keys) > 0 && start < keys[len(keys)-1].valStart {
+				// An unescaped equals sign within a value.
+				continue
+			}
+			keys = append(keys, extKey{name: s[start:i], start: start, valStart: i + 1})
+		}
+	}
+	if len(keys) == 0 && strings.TrimSpace(s) != "" {
+		return nil, errors.New("expected extensions of the form key=value")
+	}
+
+	extensions := make(map[string]any, len(keys))
+	for i, k := range keys {
+		end := len(s)
+		if i+1 < len(keys) {
+			end = keys[i+1].start
+		}
+		extensions[k.name] = cefExtensionUnescaper.Replace(strings.TrimRight(s[k.valStart:end], " \r\n"))
+	}
+	return extensions, nil
+}
+
+var cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+
+func formatCEF(obj map[string]any) (string, error) {
+	version := int64(0)
+	if v, exists := obj["version"]; exists {
+		var err error
+		if version, err = value.IGetInt(v); err != nil {
+			return "", fmt.Errorf("field version: %w", err)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("CEF:")
+	b.WriteString(strconv.FormatInt(version, 10))
+	for _, k := range []string{"device_vendor", "device_product", "device_version", "device_event_class_id", "name", "severity"} {
+		b.WriteByte('|')
+		b.WriteString(logHeaderField(obj, k))
+	}
+	b.WriteByte('|')
+
+	if v, exists := obj["extensions"]; exists && v != nil {
+		extensions, ok := v.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("field extensions: %w", value.NewTypeError(v, value.TObject))
+		}
+		written := 0
+		for _, k := range sortedKeys(extensions) {
+			if k == "" || strings.ContainsAny(k, " =\\") {
+				return "", fmt.Errorf("extension key '%v' cannot be represented in CEF", k)
+			}
+			if extensions[k] == nil {
+				continue
+			}
+			if written > 0 {
+				b.WriteByte(' ')
+			}
+			written++
+			b.WriteString(k)
+			b.WriteByte('=')
+			b.WriteString(cefExtensionEscaper.Replace(value.IToString(extensions[k])))
+		}
+	}
+	return b.String(), nil
+}
+
+//------------------------------------------------------------------------------
+
+func parseLEEFDelimiter(s string) (string, error) {
+	switch {
+	case s == "":
+		return "\t", nil
+	case len(s) == 1:
+		return s, nil
+	}
+	hex := strings.TrimPrefix(strings.TrimPrefix(s, "0"), "x")
+	if len(hex) == len(s) {
+		return "", fmt.Errorf("invalid LEEF delimiter: %v", s)
+	}
+	c, err := strconv.ParseUint(hex, 16, 8)
+	if err != nil {
+		return "", fmt.Errorf("invalid LEEF delimiter: %v", s)
+	}
+	return string(rune(c)), nil
+}
+
+func parseLEEF(s string) (map[string]any, error) {
+	idx := strings.Index(s, "LEEF:")
+	if idx < 0 {
+		return nil, errors.New("missing LEEF header")
+	}
+
+	header, attrStr, err := splitLogHeader(s[idx+5:], 5)
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]any{
+		"version":         strings.TrimSpace(header[0]),
+		"vendor":          header[1],
+		"product":         header[2],
+		"product_version": header[3],
+		"event_id":        header[4],
+	}
+
+	delim := "\t"
+	if strings.HasPrefix(header[0], "2") {
+		delimField, rest, err := splitLogHeader(attrStr, 1)
+		if err != nil {
+			return nil, err
+		}
+		if delim, err = parseLEEFDelimiter(delimField[0]); err != nil {
+			return nil, err
+		}
+		res["delimiter"] = delim
+		attrStr = rest
+	}
+
+	attributes := map[string]any{}
+	var lastKey string
+	for _, attr := range strings.Split(strings.TrimRight(attrStr, "\r\n"), delim) {
+		if attr == "" {
+			continue
+		}
+		k, v, found := strings.Cut(attr, "=")
+		if !found {
+			if lastKey == "" {
+				return nil, fmt.Errorf("expected attribute of the form key=value, got: %v", attr)
+			}
+			// The delimiter was part of the previous value.
+			attributes[lastKey] = attributes[lastKey].(string) + delim + attr
+			continue
+		}
+		attributes[k] = v
+		lastKey = k
+	}
+	res["attributes"] = attributes
+	return res, nil
+}
+
+func formatLEEF(obj map[string]any) (string, error) {
+	version := "1.0"
+	if v, exists := obj["version"]; exists && v != nil {
+		version = value.IToString(v)
+	}
+
+	var b strings.Builder
+	b.WriteString("LEEF:")
+	b.WriteString(logHeaderEscaper.Replace(version))
+	for _, k := range []string{"vendor", "product", "product_version", "event_id"} {
+		b.WriteByte('|')
+		b.WriteString(logHeaderField(obj, k))
+	}
+	b.WriteByte('|')
+
+	delim := "\t"
+	if strings.HasPrefix(version, "2") {
+		if v, exists := obj["delimiter"]; exists && v != nil {
+			if delim = value.IToString(v); len(delim) != 1 {
+				return "", fmt.Errorf("field delimiter: expected a single character, got: %v", delim)
+			}
+		}
+		if c := delim[0]; c > ' ' && c < 0x7f && c != '|' {
+			b.WriteString(delim)
+		} else {
+			fmt.Fprintf(&b, "x%02X", c)
+		}
+		b.WriteByte('|')
+	}
+
+	if v, exists := obj["attributes"]; exists && v != nil {
+		attributes, ok := v.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("field attributes: %w", value.NewTypeError(v, value.TObject))
+		}
+		written := 0
+		for _, k := range sortedKeys(attributes) {
+			if k == "" || strings.Contains(k, "=") || strings.Contains(k, delim) {
+				return "", fmt.Errorf("attribute key '%v' cannot be represented in LEEF", k)
+			}
+			if attributes[k] == nil {
+				continue
+			}
+			if written > 0 {
+				b.WriteString(delim)
+			}
+			b.WriteString(k)
+			b.WriteByte('=')
+			b.WriteString(value.IToString(attributes[k]))
+			written++
+		}
+	}
+	return b.String(), nil
+}
+
+//------------------------------------------------------------------------------
+
+// sanitiseSyslogFields converts the numeric fields of a parsed syslog message
+// into types supported by Bloblang.
+func sanitiseSyslogFields(res map[string]any) map[string]any {
+	for k, v := range res {
+		switch t := v.(type) {
+		case uint8:
+			res[k] = int64(t)
+		case uint16:
+			res[k] = int64(t)
+		}
+	}
+	return res
+}
+
+func syslogPriority(obj map[string]any) (int64, error) {
+	getInRange := func(key string, def, maxV int64) (int64, error) {
+		v, exists := obj[key]
+		if !exists || v == nil {
+			return def, nil
+		}
+		i, err := value.IGetInt(v)
+		if err != nil {
+			return 0, fmt.Errorf("field %v: %w", key, err)
+		}
+		if i < 0 || i > maxV {
+			return 0, fmt.Errorf("field %v: value %v is outside of the range 0 to %v", key, i, maxV)
+		}
+		return i, nil
+	}
+
+	if _, exists := obj["priority"]; exists {
+		return getInRange("priority", 0, 191)
+	}
+	facility, err := getInRange("facility", 1, 23)
+	if err != nil {
+		return 0, err
+	}
+	severity, err := getInRange("severity", 5, 7)
+	if err != nil {
+		return 0, err
+	}
+	return facility*8 + severity, nil
+}
+
+func syslogTimestamp(obj map[string]any) (time.Time, bool, error) {
+	v, exists := obj["timestamp"]
+	if !exists || v == nil {
+		return time.Time{}, false, nil
+	}
+	ts, err := value.IGetTimestamp(v)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("field timestamp: %w", err)
+	}
+	return ts, true, nil
+}
+
+// syslogHeaderField returns a header field that cannot contain spaces, where
+// missing fields are replaced with a nil value.
+func syslogHeaderField(obj map[string]any, key, nilValue string) (string, error) {
+	v, exists := obj[key]
+	if !exists || v == nil {
+		return nilValue, nil
+	}
+	s := value.IToString(v)
+	if s == "" {
+		return nilValue, nil
+	}
+	if strings.IndexFunc(s, func(r rune) bool { return r <= ' ' }) >= 0 {
+		return "", fmt.Errorf("field %v: value must not contain whitespace", key)
+	}
+	return s, nil
+}
+
+var syslogParamEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+func formatSyslogRFC5424(obj map[string]any) (string, error) {
+	priority, err := syslogPriority(obj)
+	if err != nil {
+		return "", err
+	}
+
+	version := int64(1)
+	if v, exists := obj["version"]; exists && v != nil {
+		if version, err = value.IGetInt(v); err != nil {
+			return "", fmt.Errorf("field version: %w", err)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>%d ", priority, version)
+
+	ts, exists, err := syslogTimestamp(obj)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		b.WriteString(ts.Format("2006-01-02T15:04:05.999999Z07:00"))
+	} else {
+		b.WriteByte('-')
+	}
+
+	for _, k := range []string{"hostname", "appname", "procid", "msgid"} {
+		s, err := syslogHeaderField(obj, k, "-")
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(' ')
+		b.WriteString(s)
+	}
+	b.WriteByte(' ')
+
+	sdV, exists := obj["structureddata"]
+	if !exists || sdV == nil {
+		b.WriteByte('-')
+	} else {
+		sd, ok := sdV.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("field structureddata: %w", value.NewTypeError(sdV, value.TObject))
+		}
+		if len(sd) == 0 {
+			b.WriteByte('-')
+		}
+		for _, id := range sortedKeys(sd) {
+			params, ok := sd[id].(map[string]any)
+			if !ok {
+				return "", fmt.Errorf("field structureddata.%v: %w", id, value.NewTypeError(sd[id], value.TObject))
+			}
+			b.WriteByte('[')
+			b.WriteString(id)
+			for _, name := range sortedKeys(params) {
+				fmt.Fprintf(&b, ` %v="%v"`, name, syslogParamEscaper.Replace(value.IToString(params[name])))
+			}
+			b.WriteByte(']')
+		}
+	}
+
+	if v, exists := obj["message"]; exists && v != nil {
+		b.WriteByte(' ')
+		b.WriteString(value.IToString(v))
+	}
+	return b.String(), nil
+}
+
+func formatSyslogRFC3164(obj map[string]any) (string, error) {
+	priority, err := syslogPriority(obj)
+	if err != nil {
+		return "", err
+	}
+
+	ts, exists, err := syslogTimestamp(obj)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		ts = time.Now()
+	}
+
+	hostname, err := syslogHeaderField(obj, "hostname", "-")
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>%v %v", priority, ts.Format(time.Stamp), hostname)
+
+	appname, err := syslogHeaderField(obj, "appname", "")
+	if err != nil {
+		return "", err
+	}
+	procid, err := syslogHeaderField(obj, "procid", "")
+	if err != nil {
+		return "", err
+	}
+	if appname != "" {
+		b.WriteByte(' ')
+		b.WriteString(appname)
+		if procid != "" {
+			fmt.Fprintf(&b, "[%v]", procid)
+		}
+		b.WriteByte(':')
+	}
+
+	if v, exists := obj["message"]; exists && v != nil {
+		b.WriteByte(' ')
+		b.WriteString(value.IToString(v))
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,297 @@
+package pure
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang/query"
+	"github.com/warpstreamlabs/bento/internal/value"
+)
+
+func TestLogMethods(t *testing.T) {
+	testCases := []struct {
+		name   string
+		method string
+		target any
+		args   []any
+		exp    any
+		err    string
+	}{
+		{
+			name:   "logfmt escaped quotes",
+			method: "parse_logfmt",
+			target: `a="foo \"bar\"" b=c=d  c=`,
+			exp:    map[string]any{"a": `foo "bar"`, "b": "c=d", "c": ""},
+		},
+		{
+			name:   "logfmt unterminated quote",
+			method: "parse_logfmt",
+			target: `a="foo`,
+			err:    "unterminated quoted value of key 'a'",
+		},
+		{
+			name:   "logfmt format nested values",
+			method: "format_logfmt",
+			target: map[string]any{"a": []any{"b", 1.5}, "c": nil, "d": true, "e": ""},
+			exp:    `a="[\"b\",1.5]" c= d=true e=`,
+		},
+		{
+			name:   "logfmt format invalid key",
+			method: "format_logfmt",
+			target: map[string]any{"a b": "c"},
+			err:    "key 'a b' cannot be represented in logfmt",
+		},
+		{
+			name:   "cef with syslog prefix and escapes",
+			method: "parse_cef",
+			target: `Sep 19 08:26:10 host CEF:0|Vend\|or|Prod\\uct|1.0|100|name|Low|cs1=line one\nline two cs1Label=Some Label act=blocked a \= b`,
+			exp: map[string]any{
+				"version":               int64(0),
+				"device_vendor":         "Vend|or",
+				"device_product":        `Prod\uct`,
+				"device_version":        "1.0",
+				"device_event_class_id": "100",
+				"name":                  "name",
+				"severity":              "Low",
+				"extensions": map[string]any{
+					"cs1":      "line one\nline two",
+					"cs1Label": "Some Label",
+					"act":      "blocked a = b",
+				},
+			},
+		},
+		{
+			name:   "cef no extensions",
+			method: "parse_cef",
+			target: `CEF:1|a|b|c|d|e|f|`,
+			exp: map[string]any{
+				"version":               int64(1),
+				"device_vendor":         "a",
+				"device_product":        "b",
+				"device_version":        "c",
+				"device_event_class_id": "d",
+				"name":                  "e",
+				"severity":              "f",
+				"extensions":            map[string]any{},
+			},
+		},
+		{
+			name:   "cef missing header",
+			method: "parse_cef",
+			target: `foo bar`,
+			err:    "missing CEF header",
+		},
+		{
+			name:   "cef truncated header",
+			method: "parse_cef",
+			target: `CEF:0|a|b|c`,
+			err:    "expected 7 header fields, found 3",
+		},
+		{
+			name:   "cef format escapes",
+			method: "format_cef",
+			target: map[string]any{
+				"device_vendor": "Vend|or",
+				"extensions":    map[string]any{"a": "b\\c\nd", "e": nil},
+			},
+			exp: `CEF:0|Vend\|or||||||a=b\\c\nd`,
+		},
+		{
+			name:   "leef 1.0 tab delimited",
+			method: "parse_leef",
+			target: "LEEF:1.0|Microsoft|MSExchange|4.0 SP1|15345|src=10.50.1.1\tdst=2.10.20.20\tmsg=a\tb",
+			exp: map[string]any{
+				"version":         "1.0",
+				"vendor":          "Microsoft",
+				"product":         "MSExchange",
+				"product_version": "4.0 SP1",
+				"event_id":        "15345",
+				"attributes": map[string]any{
+					"src": "10.50.1.1",
+					"dst": "2.10.20.20",
+					"msg": "a\tb",
+				},
+			},
+		},
+		{
+			name:   "leef 2.0 hex delimiter",
+			method: "parse_leef",
+			target: "LEEF:2.0|Vendor|Product|1.0|1|x7C|a=b|c=d",
+			exp: map[string]any{
+				"version":         "2.0",
+				"vendor":          "Vendor",
+				"product":         "Product",
+				"product_version": "1.0",
+				"event_id":        "1",
+				"delimiter":       "|",
+				"attributes":      map[string]any{"a": "b", "c": "d"},
+			},
+		},
+		{
+			name:   "leef format 2.0 default delimiter",
+			method: "format_leef",
+			target: map[string]any{
+				"version":    "2.0",
+				"vendor":     "Vendor",
+				"attributes": map[string]any{"b": int64(2), "a": "1"},
+			},
+			exp: "LEEF:2.0|Vendor||||x09|a=1\tb=2",
+		},
+		{
+			name:   "leef format 1.0",
+			method: "format_leef",
+			target: map[string]any{
+				"vendor":     "Vendor",
+				"event_id":   "1",
+				"attributes": map[string]any{"a": "1"},
+			},
+			exp: "LEEF:1.0|Vendor|||1|a=1",
+		},
+		{
+			name:   "syslog rfc5424 format defaults",
+			method: "format_syslog_rfc5424",
+			target: map[string]any{
+				"structureddata": map[string]any{"id": map[string]any{"a": `b"]\`}},
+			},
+			exp: `<13>1 - - - - - [id a="b\"\]\\"]`,
+		},
+		{
+			name:   "syslog rfc5424 invalid severity",
+			method: "format_syslog_rfc5424",
+			target: map[string]any{"severity": int64(8)},
+			err:    "field severity: value 8 is outside of the range 0 to 7",
+		},
+		{
+			name:   "syslog rfc5424 hostname with spaces",
+			method: "format_syslog_rfc5424",
+			target: map[string]any{"hostname": "foo bar"},
+			err:    "field hostname: value must not contain whitespace",
+		},
+		{
+			name:   "syslog rfc3164 format priority",
+			method: "format_syslog_rfc3164",
+			target: map[string]any{
+				"priority":  int64(14),
+				"timestamp": "2003-01-02T03:04:05Z",
+				"hostname":  "host",
+				"message":   "hello world",
+			},
+			exp: "<14>Jan  2 03:04:05 host hello world",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			targetClone := value.IClone(test.target)
+
+			fn, err := query.InitMethodHelper(test.method, query.NewLiteralFunction("", targetClone), test.args...)
+			require.NoError(t, err)
+
+			res, err := fn.Exec(query.FunctionContext{
+				Maps:     map[string]query.Function{},
+				Index:    0,
+				MsgBatch: nil,
+			})
+			if test.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, res)
+			assert.Equal(t, test.target, targetClone)
+		})
+	}
+}
+
+func TestLogMethodsRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name   string
+		parse  string
+		format string
+		input  string
+	}{
+		{
+			name:   "logfmt",
+			parse:  "parse_logfmt",
+			format: "format_logfmt",
+			input:  `a=b c="d e" f="g\"h" i=`,
+		},
+		{
+			name:   "cef",
+			parse:  "parse_cef",
+			format: "format_cef",
+			input:  `CEF:0|Vend\|or|Product|1.0|100|Name|10|act=a \= b msg=line one\nline two`,
+		},
+		{
+			name:   "leef",
+			parse:  "parse_leef",
+			format: "format_leef",
+			input:  "LEEF:2.0|Vendor|Product|1.0|1|^|a=b c^d=e",
+		},
+		{
+			name:   "syslog rfc5424",
+			parse:  "parse_syslog_rfc5424",
+			format: "format_syslog_rfc5424",
+			input:  `<165>1 2003-10-11T22:14:15.003Z host app 123 ID47 [a@1 b="c"][d@2 e="f\]"] hello world`,
+		},
+		{
+			name:   "syslog rfc3164",
+			parse:  "parse_syslog_rfc3164",
+			format: "format_syslog_rfc3164",
+			input:  `<34>Oct 11 22:14:15 mymachine su[123]: hello world`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			parseFn, err := query.InitMethodHelper(test.parse, query.NewLiteralFunction("", test.input))
+			require.NoError(t, err)
+
+			fn, err := query.InitMethodHelper(test.format, parseFn)
+			require.NoError(t, err)
+
+			res, err := fn.Exec(query.FunctionContext{
+				Maps: map[string]query.Function{},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, test.input, res)
+		})
+	}
+}
+
+func TestLogMethodsSyslogConcurrent(t *testing.T) {
+	for _, method := range []string{"parse_syslog_rfc5424", "parse_syslog_rfc3164"} {
+		method := method
+		t.Run(method, func(t *testing.T) {
+			input := `<165>1 2003-10-11T22:14:15.003Z host app 123 ID47 - hello world`
+			if method == "parse_syslog_rfc3164" {
+				input = `<34>Oct 11 22:14:15 mymachine su[123]: hello world`
+			}
+
+			fn, err := query.InitMethodHelper(method, query.NewLiteralFunction("", input))
+			require.NoError(t, err)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						res, err := fn.Exec(query.FunctionContext{
+							Maps: map[string]query.Function{},
+						})
+						require.NoError(t, err)
+						assert.Equal(t, "hello world", res.(map[string]any)["message"])
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
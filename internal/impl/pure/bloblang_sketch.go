@@ -0,0 +1,328 @@
+package pure
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang/query"
+	"github.com/warpstreamlabs/bento/internal/value"
+	"github.com/warpstreamlabs/bento/public/bloblang"
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+// updateCachedSketch applies a modification to a sketch stored within a cache,
+// where fn receives the current serialised sketch (nil if the key does not yet
+// exist) and returns the new one, or nil if the sketch is unchanged. Caches
+// that support compare and swap are updated atomically by retrying on
+// conflicts, otherwise concurrent updates to the same key may be lost.
+func updateCachedSketch(ctx context.Context, c service.Cache, key string, fn func(current []byte) ([]byte, error)) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		current, err := c.Get(ctx, key)
+		if errors.Is(err, service.ErrKeyNotFound) {
+			next, err := fn(nil)
+			if err != nil {
+				return err
+			}
+			if err = c.Add(ctx, key, next, nil); errors.Is(err, service.ErrKeyAlreadyExists) {
+				continue
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		next, err := fn(current)
+		if err != nil || next == nil {
+			return err
+		}
+
+		if cs, ok := c.(service.CompareAndSwapCache); ok {
+			err = cs.CompareAndSwap(ctx, key, current, next, nil)
+			if errors.Is(err, service.ErrCompareAndSwapFailed) || errors.Is(err, service.ErrKeyNotFound) {
+				continue
+			}
+			if !errors.Is(err, service.ErrCacheOperationNotSupported) {
+				return err
+			}
+		}
+		return c.Set(ctx, key, next, nil)
+	}
+}
+
+func readCachedSketch(ctx context.Context, c service.Cache, key string, sketch encoding.BinaryUnmarshaler) (bool, error) {
+	data, err := c.Get(ctx, key)
+	if errors.Is(err, service.ErrKeyNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := sketch.UnmarshalBinary(data); err != nil {
+		return false, fmt.Errorf("failed to parse sketch stored at key '%v': %w", key, err)
+	}
+	return true, nil
+}
+
+func registerBloblangSketchFunctions(getMgr func() service.LimitedResources) error {
+	if err := bloblang.RegisterFunctionV2("hll_add",
+		bloblang.NewPluginSpec().
+			Experimental().
+			Impure().
+			Version("1.14.0").
+			Category(query.FunctionCategoryEnvironment).
+			Description("Adds a value to a HyperLogLog sketch stored within a cache resource and returns the estimated number of distinct values that have been added to it. The sketch is created if the key does not exist. HyperLogLog estimates cardinality using a fixed amount of memory, with a standard error of roughly `1.04 / sqrt(2^precision)`. Caches that support compare and swap, including `memory`, `redis`, `sql`, `aws_dynamodb` and `nats_kv`, are updated atomically, otherwise concurrent additions to the same key may be lost.").
+			Param(bloblang.NewStringParam("resource").Description("The name of the `cache` resource to target.")).
+			Param(bloblang.NewStringParam("key").Description("The key of the sketch within the `cache`.")).
+			Param(bloblang.NewAnyParam("value").Description("The value to add to the sketch.")).
+			Param(bloblang.NewInt64Param("precision").Description("The precision of a newly created sketch, between 4 and 18, where a sketch occupies `2^precision` bytes. This has no effect on existing sketches.").Default(14)).
+			ExampleNotTested("", `root.unique_users = hll_add(resource: "sketches", key: "users", value: this.user_id)`),
+		func(args *bloblang.ParsedParams) (bloblang.Function, error) {
+			resource, err := args.GetString("resource")
+			if err != nil {
+				return nil, err
+			}
+			key, err := args.GetString("key")
+			if err != nil {
+				return nil, err
+			}
+			v, err := args.Get("value")
+			if err != nil {
+				return nil, err
+			}
+			precision, err := args.GetInt64("precision")
+			if err != nil {
+				return nil, err
+			}
+			if _, err := newHyperLogLog(precision); err != nil {
+				return nil, err
+			}
+			item := value.IToBytes(v)
+
+			return func() (any, error) {
+				var (
+					count uint64
+					cerr  error
+				)
+				ctx := context.Background()
+				if err := getMgr().AccessCache(ctx, resource, func(c service.Cache) {
+					cerr = updateCachedSketch(ctx, c, key, func(current []byte) ([]byte, error) {
+						var hll *hyperLogLog
+						if current == nil {
+							hll, _ = newHyperLogLog(precision)
+						} else {
+							hll = &hyperLogLog{}
+							if err := hll.UnmarshalBinary(current); err != nil {
+								return nil, fmt.Errorf("failed to parse sketch stored at key '%v': %w", key, err)
+							}
+						}
+						changed := hll.add(item)
+						count = hll.count()
+						if !changed && current != nil {
+							return nil, nil
+						}
+						return hll.MarshalBinary()
+					})
+				}); err != nil {
+					return nil, err
+				}
+				if cerr != nil {
+					return nil, cerr
+				}
+				return int64(count), nil
+			}, nil
+		}); err != nil {
+		return err
+	}
+
+	if err := bloblang.RegisterFunctionV2("hll_count",
+		bloblang.NewPluginSpec().
+			Experimental().
+			Impure().
+			Version("1.14.0").
+			Category(query.FunctionCategoryEnvironment).
+			Description("Returns the estimated number of distinct values added to a HyperLogLog sketch stored within a cache resource with `hll_add`. If the key does not exist the result is zero.").
+			Param(bloblang.NewStringParam("resource").Description("The name of the `cache` resource to target.")).
+			Param(bloblang.NewStringParam("key").Description("The key of the sketch within the `cache`.")).
+			ExampleNotTested("", `root.unique_users = hll_count(resource: "sketches", key: "users")`),
+		func(args *bloblang.ParsedParams) (bloblang.Function, error) {
+			resource, err := args.GetString("resource")
+			if err != nil {
+				return nil, err
+			}
+			key, err := args.GetString("key")
+			if err != nil {
+				return nil, err
+			}
+
+			return func() (any, error) {
+				var (
+					hll    hyperLogLog
+					exists bool
+					cerr   error
+				)
+				ctx := context.Background()
+				if err := getMgr().AccessCache(ctx, resource, func(c service.Cache) {
+					exists, cerr = readCachedSketch(ctx, c, key, &hll)
+				}); err != nil {
+					return nil, err
+				}
+				if cerr != nil {
+					return nil, cerr
+				}
+				if !exists {
+					return int64(0), nil
+				}
+				return int64(hll.count()), nil
+			}, nil
+		}); err != nil {
+		return err
+	}
+
+	if err := bloblang.RegisterFunctionV2("count_min_add",
+		bloblang.NewPluginSpec().
+			Experimental().
+			Impure().
+			Version("1.14.0").
+			Category(query.FunctionCategoryEnvironment).
+			Description("Adds a count of a value to a count-min sketch stored within a cache resource and returns the estimated frequency of the value. The sketch is created if the key does not exist. Estimates are never lower than the true frequency, and exceed it by at most `2.72 / width` multiplied by the total of all counts with a probability of `1 - 1/2.72^depth`. Caches that support compare and swap, including `memory`, `redis`, `sql`, `aws_dynamodb` and `nats_kv`, are updated atomically, otherwise concurrent additions to the same key may be lost.").
+			Param(bloblang.NewStringParam("resource").Description("The name of the `cache` resource to target.")).
+			Param(bloblang.NewStringParam("key").Description("The key of the sketch within the `cache`.")).
+			Param(bloblang.NewAnyParam("value").Description("The value to count.")).
+			Param(bloblang.NewInt64Param("count").Description("The amount to add to the frequency of the value, which must not be negative.").Default(1)).
+			Param(bloblang.NewInt64Param("width").Description("The number of counters per row of a newly created sketch. This has no effect on existing sketches.").Default(1024)).
+			Param(bloblang.NewInt64Param("depth").Description("The number of rows of a newly created sketch, where a sketch occupies `8 * width * depth` bytes. This has no effect on existing sketches.").Default(4)).
+			ExampleNotTested("", `root.path_hits = count_min_add(resource: "sketches", key: "paths", value: this.path)`),
+		func(args *bloblang.ParsedParams) (bloblang.Function, error) {
+			resource, err := args.GetString("resource")
+			if err != nil {
+				return nil, err
+			}
+			key, err := args.GetString("key")
+			if err != nil {
+				return nil, err
+			}
+			v, err := args.Get("value")
+			if err != nil {
+				return nil, err
+			}
+			count, err := args.GetInt64("count")
+			if err != nil {
+				return nil, err
+			}
+			if count < 0 {
+				return nil, fmt.Errorf("count must not be negative, got %v", count)
+			}
+			width, err := args.GetInt64("width")
+			if err != nil {
+				return nil, err
+			}
+			depth, err := args.GetInt64("depth")
+			if err != nil {
+				return nil, err
+			}
+			if _, err := newCountMinSketch(width, depth); err != nil {
+				return nil, err
+			}
+			item := value.IToBytes(v)
+
+			return func() (any, error) {
+				var (
+					estimate uint64
+					cerr     error
+				)
+				ctx := context.Background()
+				if err := getMgr().AccessCache(ctx, resource, func(c service.Cache) {
+					cerr = updateCachedSketch(ctx, c, key, func(current []byte) ([]byte, error) {
+						var cms *countMinSketch
+						if current == nil {
+							cms, _ = newCountMinSketch(width, depth)
+						} else {
+							cms = &countMinSketch{}
+							if err := cms.UnmarshalBinary(current); err != nil {
+								return nil, fmt.Errorf("failed to parse sketch stored at key '%v': %w", key, err)
+							}
+						}
+						estimate = cms.add(item, uint64(count))
+						if count == 0 && current != nil {
+							return nil, nil
+						}
+						return cms.MarshalBinary()
+					})
+				}); err != nil {
+					return nil, err
+				}
+				if cerr != nil {
+					return nil, cerr
+				}
+				return sketchCountToInt(estimate), nil
+			}, nil
+		}); err != nil {
+		return err
+	}
+
+	if err := bloblang.RegisterFunctionV2("count_min_estimate",
+		bloblang.NewPluginSpec().
+			Experimental().
+			Impure().
+			Version("1.14.0").
+			Category(query.FunctionCategoryEnvironment).
+			Description("Returns the estimated frequency of a value within a count-min sketch stored within a cache resource with `count_min_add`. If the key does not exist the result is zero.").
+			Param(bloblang.NewStringParam("resource").Description("The name of the `cache` resource to target.")).
+			Param(bloblang.NewStringParam("key").Description("The key of the sketch within the `cache`.")).
+			Param(bloblang.NewAnyParam("value").Description("The value to estimate the frequency of.")).
+			ExampleNotTested("", `root.is_hot = count_min_estimate(resource: "sketches", key: "paths", value: this.path) > 1000`),
+		func(args *bloblang.ParsedParams) (bloblang.Function, error) {
+			resource, err := args.GetString("resource")
+			if err != nil {
+				return nil, err
+			}
+			key, err := args.GetString("key")
+			if err != nil {
+				return nil, err
+			}
+			v, err := args.Get("value")
+			if err != nil {
+				return nil, err
+			}
+			item := value.IToBytes(v)
+
+			return func() (any, error) {
+				var (
+					cms    countMinSketch
+					exists bool
+					cerr   error
+				)
+				ctx := context.Background()
+				if err := getMgr().AccessCache(ctx, resource, func(c service.Cache) {
+					exists, cerr = readCachedSketch(ctx, c, key, &cms)
+				}); err != nil {
+					return nil, err
+				}
+				if cerr != nil {
+					return nil, cerr
+				}
+				if !exists {
+					return int64(0), nil
+				}
+				return sketchCountToInt(cms.estimate(item)), nil
+			}, nil
+		}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func sketchCountToInt(v uint64) int64 {
+	if v > uint64(1<<63-1) {
+		return 1<<63 - 1
+	}
+	return int64(v)
+}
@@ -0,0 +1,346 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/shutdown"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	bcFieldCapacity          = "capacity"
+	bcFieldFalsePositiveRate = "false_positive_rate"
+	bcFieldSnapshot          = "snapshot"
+	bcFieldSnapshotPath      = "path"
+	bcFieldSnapshotCache     = "cache"
+	bcFieldSnapshotKey       = "key"
+	bcFieldSnapshotInterval  = "interval"
+)
+
+func bloomCacheConfig() *service.ConfigSpec {
+	spec := service.NewConfigSpec().
+		Beta().
+		Version("1.14.0").
+		Summary(`Stores keys within a bloom filter held in memory, which uses a fixed amount of memory regardless of the number of keys added at the cost of occasional false positives.`).
+		Description(`
+A bloom filter is able to tell with certainty when a key has never been added, but there is a small chance that it reports a key as existing when it has not. The memory used by the filter is determined by the `+"`capacity`"+` and `+"`false_positive_rate`"+` fields, where the false positive rate is met once the number of keys added reaches the capacity, and grows beyond that point as more keys are added.
+
+The filter only records the existence of keys and therefore values are discarded, a `+"`get`"+` of an existing key yields an empty value. Keys cannot be removed from a bloom filter and therefore a `+"`delete`"+` results in an error, and TTLs are ignored.
+
+This makes the cache ideal for use with the `+"[`dedupe` processor](/docs/components/processors/dedupe)"+` when the number of keys is too high to be stored exactly, where a false positive results in a message being dropped.
+
+### Snapshots
+
+The filter can be periodically snapshotted to either a file or to another cache resource by setting either `+"`snapshot.path`"+` or `+"`snapshot.cache`"+`, in which case the snapshot is loaded when the cache is first used and written again when the cache is closed. If the capacity or false positive rate of the cache has changed since the snapshot was written then it is ignored and the filter starts empty.`).
+		Field(service.NewIntField(bcFieldCapacity).
+			Description("The number of keys expected to be added to the filter, after which the false positive rate begins to exceed the configured rate.").
+			Default(1000000)).
+		Field(service.NewFloatField(bcFieldFalsePositiveRate).
+			Description("The target probability of a key being reported as existing when it has not been added, once the filter has reached capacity.").
+			Default(0.01)).
+		Field(service.NewObjectField(bcFieldSnapshot,
+			service.NewStringField(bcFieldSnapshotPath).
+				Description("A file path to write snapshots to, which is read from when the cache is first used. This field is mutually exclusive with `cache`.").
+				Example("/var/lib/bento/seen.bloom").
+				Default(""),
+			service.NewStringField(bcFieldSnapshotCache).
+				Description("A [cache resource](/docs/components/caches/about) to write snapshots to, which is read from when the cache is first used. This field is mutually exclusive with `path`.").
+				Default(""),
+			service.NewStringField(bcFieldSnapshotKey).
+				Description("The key under which snapshots are stored within the `cache`.").
+				Default("bloom"),
+			service.NewDurationField(bcFieldSnapshotInterval).
+				Description("The period of time between each snapshot, snapshots are only written when keys have been added since the previous one.").
+				Default("1m"),
+		).
+			Description("Configures periodic snapshots of the filter, allowing it to be restored after a restart. Snapshots are enabled by setting either a `path` or a `cache`.")).
+		Example(
+			"High cardinality deduplication",
+			"The following deduplicates messages by their ID, using a bloom filter that's snapshotted to disk in order to survive restarts.",
+			`
+pipeline:
+  processors:
+    - dedupe:
+        cache: seen
+        key: ${! json("id") }
+
+cache_resources:
+  - label: seen
+    bloom:
+      capacity: 100000000
+      false_positive_rate: 0.001
+      snapshot:
+        path: /var/lib/bento/seen.bloom
+        interval: 5m
+`)
+	return spec
+}
+
+func init() {
+	err := service.RegisterCache(
+		"bloom", bloomCacheConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Cache, error) {
+			return newBloomCacheFromConfig(conf, mgr)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func newBloomCacheFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*bloomCache, error) {
+	capacity, err := conf.FieldInt(bcFieldCapacity)
+	if err != nil {
+		return nil, err
+	}
+	if capacity <= 0 {
+		return nil, fmt.Errorf("capacity must be greater than zero, got %v", capacity)
+	}
+
+	fpRate, err := conf.FieldFloat(bcFieldFalsePositiveRate)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := newBloomFilter(uint64(capacity), fpRate)
+	if err != nil {
+		return nil, err
+	}
+
+	var snap bloomSnapshotConfig
+	sConf := conf.Namespace(bcFieldSnapshot)
+	if snap.path, err = sConf.FieldString(bcFieldSnapshotPath); err != nil {
+		return nil, err
+	}
+	if snap.cache, err = sConf.FieldString(bcFieldSnapshotCache); err != nil {
+		return nil, err
+	}
+	if snap.key, err = sConf.FieldString(bcFieldSnapshotKey); err != nil {
+		return nil, err
+	}
+	if snap.interval, err = sConf.FieldDuration(bcFieldSnapshotInterval); err != nil {
+		return nil, err
+	}
+	if snap.path != "" && snap.cache != "" {
+		return nil, errors.New("snapshot cannot have both a path and a cache")
+	}
+	if snap.enabled() && snap.interval <= 0 {
+		return nil, errors.New("snapshot interval must be greater than zero")
+	}
+	return newBloomCache(filter, snap, mgr, mgr.Logger()), nil
+}
+
+//------------------------------------------------------------------------------
+
+type bloomSnapshotConfig struct {
+	path     string
+	cache    string
+	key      string
+	interval time.Duration
+}
+
+func (s bloomSnapshotConfig) enabled() bool {
+	return s.path != "" || s.cache != ""
+}
+
+type bloomCache struct {
+	mgr  cacheProvider
+	log  *service.Logger
+	snap bloomSnapshotConfig
+
+	mut    sync.Mutex
+	filter *bloomFilter
+	loaded bool
+	dirty  bool
+
+	shutSig *shutdown.Signaller
+}
+
+func newBloomCache(filter *bloomFilter, snap bloomSnapshotConfig, mgr cacheProvider, log *service.Logger) *bloomCache {
+	b := &bloomCache{
+		mgr:     mgr,
+		log:     log,
+		snap:    snap,
+		filter:  filter,
+		loaded:  !snap.enabled(),
+		shutSig: shutdown.NewSignaller(),
+	}
+	if snap.enabled() {
+		go b.snapshotLoop()
+	} else {
+		b.shutSig.TriggerHasStopped()
+	}
+	return b
+}
+
+// load restores the filter from a snapshot if one has not yet been loaded. The
+// load is deferred until the cache is first used as snapshots might be stored
+// within a cache resource that is yet to be initialised. Must be called whilst
+// holding the mutex.
+func (b *bloomCache) load(ctx context.Context) error {
+	if b.loaded {
+		return nil
+	}
+
+	var data []byte
+	var err error
+	if b.snap.path != "" {
+		if data, err = os.ReadFile(b.snap.path); errors.Is(err, os.ErrNotExist) {
+			data, err = nil, nil
+		}
+	} else {
+		if cerr := b.mgr.AccessCache(ctx, b.snap.cache, func(c service.Cache) {
+			data, err = c.Get(ctx, b.snap.key)
+		}); cerr != nil {
+			err = cerr
+		}
+		if errors.Is(err, service.ErrKeyNotFound) {
+			data, err = nil, nil
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read bloom filter snapshot: %w", err)
+	}
+
+	if data != nil {
+		var restored bloomFilter
+		if err := restored.UnmarshalBinary(data); err != nil {
+			return fmt.Errorf("failed to parse bloom filter snapshot: %w", err)
+		}
+		if restored.sameShape(b.filter) {
+			b.filter = &restored
+		} else {
+			b.log.Warn("Ignoring bloom filter snapshot as its dimensions do not match the configured capacity and false positive rate")
+		}
+	}
+	b.loaded = true
+	return nil
+}
+
+func (b *bloomCache) writeSnapshot(ctx context.Context, data []byte) error {
+	if b.snap.path != "" {
+		// Write to a temporary file first so that a crash mid-write never
+		// leaves a corrupted snapshot behind.
+		tmpPath := filepath.Join(filepath.Dir(b.snap.path), "."+filepath.Base(b.snap.path)+".tmp")
+		if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+			return err
+		}
+		return os.Rename(tmpPath, b.snap.path)
+	}
+
+	var setErr error
+	if err := b.mgr.AccessCache(ctx, b.snap.cache, func(c service.Cache) {
+		setErr = c.Set(ctx, b.snap.key, data, nil)
+	}); err != nil {
+		return err
+	}
+	return setErr
+}
+
+func (b *bloomCache) save(ctx context.Context) error {
+	b.mut.Lock()
+	if !b.dirty {
+		b.mut.Unlock()
+		return nil
+	}
+	data, err := b.filter.MarshalBinary()
+	if err != nil {
+		b.mut.Unlock()
+		return err
+	}
+	b.dirty = false
+	b.mut.Unlock()
+
+	if err := b.writeSnapshot(ctx, data); err != nil {
+		b.mut.Lock()
+		b.dirty = true
+		b.mut.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (b *bloomCache) snapshotLoop() {
+	defer b.shutSig.TriggerHasStopped()
+
+	ticker := time.NewTicker(b.snap.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, done := b.shutSig.HardStopCtx(context.Background())
+			if err := b.save(ctx); err != nil {
+				b.log.Errorf("Failed to write bloom filter snapshot: %v", err)
+			}
+			done()
+		case <-b.shutSig.SoftStopChan():
+			ctx, done := b.shutSig.HardStopCtx(context.Background())
+			if err := b.save(ctx); err != nil {
+				b.log.Errorf("Failed to write bloom filter snapshot: %v", err)
+			}
+			done()
+			return
+		}
+	}
+}
+
+//------------------------------------------------------------------------------
+
+func (b *bloomCache) Get(ctx context.Context, key string) ([]byte, error) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	if err := b.load(ctx); err != nil {
+		return nil, err
+	}
+	if !b.filter.test([]byte(key)) {
+		return nil, service.ErrKeyNotFound
+	}
+	return []byte{}, nil
+}
+
+func (b *bloomCache) Set(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	if err := b.load(ctx); err != nil {
+		return err
+	}
+	if !b.filter.add([]byte(key)) {
+		b.dirty = true
+	}
+	return nil
+}
+
+func (b *bloomCache) Add(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	if err := b.load(ctx); err != nil {
+		return err
+	}
+	if b.filter.add([]byte(key)) {
+		return service.ErrKeyAlreadyExists
+	}
+	b.dirty = true
+	return nil
+}
+
+func (b *bloomCache) Delete(ctx context.Context, key string) error {
+	return service.ErrCacheOperationNotSupported
+}
+
+func (b *bloomCache) Close(ctx context.Context) error {
+	b.shutSig.TriggerSoftStop()
+	select {
+	case <-b.shutSig.HasStoppedChan():
+	case <-ctx.Done():
+		b.shutSig.TriggerHardStop()
+		return ctx.Err()
+	}
+	return nil
+}
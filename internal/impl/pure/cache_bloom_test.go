@@ -0,0 +1,129 @@
+package pure
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+func TestBloomCache(t *testing.T) {
+	conf, err := bloomCacheConfig().ParseYAML(`
+capacity: 1000
+false_positive_rate: 0.001
+`, nil)
+	require.NoError(t, err)
+
+	c, err := newBloomCacheFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	_, err = c.Get(ctx, "foo")
+	require.ErrorIs(t, err, service.ErrKeyNotFound)
+
+	require.NoError(t, c.Add(ctx, "foo", []byte("t"), nil))
+	require.ErrorIs(t, c.Add(ctx, "foo", []byte("t"), nil), service.ErrKeyAlreadyExists)
+
+	v, err := c.Get(ctx, "foo")
+	require.NoError(t, err)
+	assert.Empty(t, v)
+
+	require.NoError(t, c.Set(ctx, "bar", []byte("t"), nil))
+	require.ErrorIs(t, c.Add(ctx, "bar", []byte("t"), nil), service.ErrKeyAlreadyExists)
+
+	require.ErrorIs(t, c.Delete(ctx, "foo"), service.ErrCacheOperationNotSupported)
+	require.NoError(t, c.Close(ctx))
+}
+
+func TestBloomCacheBadSnapshotConfig(t *testing.T) {
+	for _, conf := range []string{
+		`snapshot: { path: ./foo, cache: bar }`,
+		`snapshot: { path: ./foo, interval: 0s }`,
+	} {
+		pConf, err := bloomCacheConfig().ParseYAML(conf, nil)
+		require.NoError(t, err)
+
+		_, err = newBloomCacheFromConfig(pConf, service.MockResources())
+		require.Error(t, err, conf)
+	}
+}
+
+func TestBloomCacheSnapshotFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seen.bloom")
+	conf, err := bloomCacheConfig().ParseYAML(`
+capacity: 1000
+snapshot:
+  path: `+path+`
+  interval: 1h
+`, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	c, err := newBloomCacheFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+	require.NoError(t, c.Add(ctx, "foo", nil, nil))
+	require.NoError(t, c.Close(ctx))
+	require.FileExists(t, path)
+
+	c, err = newBloomCacheFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+	require.ErrorIs(t, c.Add(ctx, "foo", nil, nil), service.ErrKeyAlreadyExists)
+	require.NoError(t, c.Add(ctx, "bar", nil, nil))
+	require.NoError(t, c.Close(ctx))
+
+	// A snapshot of a filter with different dimensions is ignored.
+	conf, err = bloomCacheConfig().ParseYAML(`
+capacity: 2000
+snapshot:
+  path: `+path+`
+`, nil)
+	require.NoError(t, err)
+
+	c, err = newBloomCacheFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+	require.NoError(t, c.Add(ctx, "foo", nil, nil))
+	require.NoError(t, c.Close(ctx))
+}
+
+func TestBloomCacheSnapshotCache(t *testing.T) {
+	conf, err := bloomCacheConfig().ParseYAML(`
+capacity: 1000
+snapshot:
+  cache: snapshots
+  key: seen
+  interval: 1h
+`, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	res := service.MockResources(service.MockResourcesOptAddCache("snapshots"))
+
+	c, err := newBloomCacheFromConfig(conf, res)
+	require.NoError(t, err)
+	require.NoError(t, c.Add(ctx, "foo", nil, nil))
+	require.NoError(t, c.Close(ctx))
+
+	var snapshot []byte
+	require.NoError(t, res.AccessCache(ctx, "snapshots", func(c service.Cache) {
+		snapshot, err = c.Get(ctx, "seen")
+	}))
+	require.NoError(t, err)
+	require.NotEmpty(t, snapshot)
+
+	c, err = newBloomCacheFromConfig(conf, res)
+	require.NoError(t, err)
+	require.ErrorIs(t, c.Add(ctx, "foo", nil, nil), service.ErrKeyAlreadyExists)
+	require.NoError(t, c.Close(ctx))
+
+	// Without a snapshot cache the filter cannot be used.
+	c, err = newBloomCacheFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+	require.Error(t, c.Add(ctx, "foo", nil, nil))
+	require.NoError(t, c.Close(ctx))
+}
@@ -0,0 +1,321 @@
+package pure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Jeffail/shutdown"
+
+	"github.com/warpstreamlabs/bento/public/bloblang"
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	mcFieldWarmUp                = "warm_up"
+	mcFieldWarmUpRefreshInterval = "refresh_interval"
+	mcFieldWarmUpTimeout         = "timeout"
+	mcFieldChangeFeed            = "change_feed"
+	mcFieldLoaderInput           = "input"
+	mcFieldLoaderMapping         = "mapping"
+)
+
+func memCacheLoaderMappingField() *service.ConfigField {
+	return service.NewBloblangField(mcFieldLoaderMapping).
+		Description("A [Bloblang mapping](/docs/guides/bloblang/about) executed on each message consumed from the input, which should result in an object of keys to values to be set within the cache. String and byte values are stored as they are, a `null` value deletes the key, and any other value is stored as a JSON document. Deleting the root of the mapping skips the message.").
+		Examples(
+			`root = { this.id: this.name }`,
+			`root = this.rows.fold({}, item -> item.tally.merge({ item.value.id: item.value }))`,
+		)
+}
+
+func memCacheWarmUpField() *service.ConfigField {
+	return service.NewObjectField(mcFieldWarmUp,
+		service.NewInputField(mcFieldLoaderInput).
+			Description("An input that is consumed until it is exhausted in order to load the contents of the cache, such as a `file` input, a `sql_select` input or a `generate` input with an `http` processor."),
+		memCacheLoaderMappingField(),
+		service.NewDurationField(mcFieldWarmUpRefreshInterval).
+			Description("An optional period after which the input is consumed again in order to refresh the contents of the cache, where keys that were loaded previously but are not loaded again are deleted.").
+			Example("1h").
+			Optional(),
+		service.NewDurationField(mcFieldWarmUpTimeout).
+			Description("The maximum period of time to wait for the input to be exhausted, after which the attempt is abandoned. When the cache is created the initial attempt must succeed within this time.").
+			Default("1m"),
+	).
+		Description("Loads the contents of the cache from an input when it is created, and optionally at a regular interval thereafter. Keys that are loaded are exempt from TTLs.").
+		Version("1.14.0").
+		Optional()
+}
+
+func memCacheChangeFeedField() *service.ConfigField {
+	return service.NewObjectField(mcFieldChangeFeed,
+		service.NewInputField(mcFieldLoaderInput).
+			Description("An input that is consumed for as long as the cache exists, where each message describes a change to the contents of the cache."),
+		memCacheLoaderMappingField(),
+	).
+		Description("Subscribes the cache to a feed of changes consumed from an input, keeping its contents current without a restart. Keys that are set from the change feed are exempt from TTLs.").
+		Version("1.14.0").
+		Optional()
+}
+
+//------------------------------------------------------------------------------
+
+// memCacheChanges is a set of keys and their new values, where a nil value
+// indicates that the key is deleted.
+type memCacheChanges map[string][]byte
+
+func memCacheChangesFromMessage(msg *service.Message, mapping *bloblang.Executor) (memCacheChanges, error) {
+	resMsg, err := msg.BloblangQuery(mapping)
+	if err != nil {
+		return nil, err
+	}
+	if resMsg == nil {
+		return nil, nil
+	}
+
+	res, err := resMsg.AsStructured()
+	if err != nil {
+		return nil, err
+	}
+
+	obj, ok := res.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected mapping to result in an object, got %T", res)
+	}
+
+	changes := make(memCacheChanges, len(obj))
+	for k, v := range obj {
+		switch t := v.(type) {
+		case nil:
+			changes[k] = nil
+		case string:
+			changes[k] = []byte(t)
+		case []byte:
+			changes[k] = t
+		default:
+			if changes[k], err = json.Marshal(t); err != nil {
+				return nil, fmt.Errorf("failed to serialise value of key '%v': %w", k, err)
+			}
+		}
+	}
+	return changes, nil
+}
+
+type memCacheLoader struct {
+	cache *memoryCache
+	log   *service.Logger
+
+	warmUpConf      *service.ParsedConfig
+	warmUpMapping   *bloblang.Executor
+	refreshInterval time.Duration
+	timeout         time.Duration
+	warmedKeys      map[string]struct{}
+
+	changeFeedConf    *service.ParsedConfig
+	changeFeedMapping *bloblang.Executor
+
+	shutSig *shutdown.Signaller
+}
+
+// newMemCacheLoader parses the warm up and change feed configs of a memory
+// cache, returns nil if neither are configured.
+func newMemCacheLoader(conf *service.ParsedConfig, cache *memoryCache, log *service.Logger) (*memCacheLoader, error) {
+	l := &memCacheLoader{
+		cache:   cache,
+		log:     log,
+		shutSig: shutdown.NewSignaller(),
+	}
+
+	var err error
+	if conf.Contains(mcFieldWarmUp) {
+		l.warmUpConf = conf.Namespace(mcFieldWarmUp)
+		if l.warmUpMapping, err = l.warmUpConf.FieldBloblang(mcFieldLoaderMapping); err != nil {
+			return nil, err
+		}
+		if l.warmUpConf.Contains(mcFieldWarmUpRefreshInterval) {
+			if l.refreshInterval, err = l.warmUpConf.FieldDuration(mcFieldWarmUpRefreshInterval); err != nil {
+				return nil, err
+			}
+		}
+		if l.timeout, err = l.warmUpConf.FieldDuration(mcFieldWarmUpTimeout); err != nil {
+			return nil, err
+		}
+	}
+
+	if conf.Contains(mcFieldChangeFeed) {
+		l.changeFeedConf = conf.Namespace(mcFieldChangeFeed)
+		if l.changeFeedMapping, err = l.changeFeedConf.FieldBloblang(mcFieldLoaderMapping); err != nil {
+			return nil, err
+		}
+	}
+
+	if l.warmUpConf == nil && l.changeFeedConf == nil {
+		return nil, nil
+	}
+	return l, nil
+}
+
+// start performs the initial warm up, blocking until it has completed, and
+// then launches the refresh and change feed loops in the background.
+func (l *memCacheLoader) start() error {
+	if l.warmUpConf != nil {
+		if err := l.warmUp(context.Background()); err != nil {
+			return fmt.Errorf("failed to warm up cache: %w", err)
+		}
+	}
+
+	var loops []func()
+	if l.warmUpConf != nil && l.refreshInterval > 0 {
+		loops = append(loops, l.refreshLoop)
+	}
+	if l.changeFeedConf != nil {
+		loops = append(loops, l.changeFeedLoop)
+	}
+	if len(loops) == 0 {
+		l.shutSig.TriggerHasStopped()
+		return nil
+	}
+
+	remaining := make(chan struct{}, len(loops))
+	for _, loop := range loops {
+		go func(loop func()) {
+			loop()
+			remaining <- struct{}{}
+		}(loop)
+	}
+	go func() {
+		for range loops {
+			<-remaining
+		}
+		l.shutSig.TriggerHasStopped()
+	}()
+	return nil
+}
+
+// consumeInputField creates an input from a field of a parsed config and reads
+// messages from it until it is exhausted, calling fn with each message. Batches
+// containing a message that fn fails to process are nacked so that the input
+// can deliver them again rather than the changes being lost.
+func consumeInputField(ctx context.Context, conf *service.ParsedConfig, field string, fn func(*service.Message) error) error {
+	in, err := conf.FieldInput(field)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+		_ = in.Close(closeCtx)
+		done()
+	}()
+
+	for {
+		batch, ackFn, err := in.ReadBatch(ctx)
+		if err != nil {
+			if errors.Is(err, service.ErrEndOfInput) {
+				return nil
+			}
+			return err
+		}
+		var batchErr error
+		for _, msg := range batch {
+			if err := fn(msg); err != nil && batchErr == nil {
+				batchErr = err
+			}
+		}
+		_ = ackFn(ctx, batchErr)
+	}
+}
+
+// consume reads messages from an input until it is exhausted, calling fn with
+// the changes of each message.
+func (l *memCacheLoader) consume(ctx context.Context, conf *service.ParsedConfig, mapping *bloblang.Executor, fn func(memCacheChanges)) error {
+	return consumeInputField(ctx, conf, mcFieldLoaderInput, func(msg *service.Message) error {
+		changes, err := memCacheChangesFromMessage(msg, mapping)
+		if err != nil {
+			l.log.Errorf("Failed to map message into cache changes: %v", err)
+			return err
+		}
+		fn(changes)
+		return nil
+	})
+}
+
+func (l *memCacheLoader) warmUp(ctx context.Context) error {
+	ctx, done := context.WithTimeout(ctx, l.timeout)
+	defer done()
+
+	loaded := memCacheChanges{}
+	if err := l.consume(ctx, l.warmUpConf, l.warmUpMapping, func(changes memCacheChanges) {
+		for k, v := range changes {
+			if v == nil {
+				delete(loaded, k)
+			} else {
+				loaded[k] = v
+			}
+		}
+	}); err != nil {
+		return err
+	}
+
+	// Keys that were loaded previously but are now absent are deleted.
+	for k := range l.warmedKeys {
+		if _, exists := loaded[k]; !exists {
+			loaded[k] = nil
+		}
+	}
+	l.cache.apply(loaded)
+
+	l.warmedKeys = make(map[string]struct{}, len(loaded))
+	for k, v := range loaded {
+		if v != nil {
+			l.warmedKeys[k] = struct{}{}
+		}
+	}
+	return nil
+}
+
+func (l *memCacheLoader) refreshLoop() {
+	ctx, done := l.shutSig.SoftStopCtx(context.Background())
+	defer done()
+
+	ticker := time.NewTicker(l.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := l.warmUp(ctx); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				l.log.Errorf("Failed to refresh cache: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (l *memCacheLoader) changeFeedLoop() {
+	ctx, done := l.shutSig.SoftStopCtx(context.Background())
+	defer done()
+
+	if err := l.consume(ctx, l.changeFeedConf, l.changeFeedMapping, l.cache.apply); err != nil {
+		if ctx.Err() == nil {
+			l.log.Errorf("Failed to consume cache change feed: %v", err)
+		}
+		return
+	}
+	l.log.Warn("Cache change feed input has finished, the contents of the cache will no longer be updated from it")
+}
+
+func (l *memCacheLoader) close(ctx context.Context) error {
+	l.shutSig.TriggerSoftStop()
+	select {
+	case <-l.shutSig.HasStoppedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
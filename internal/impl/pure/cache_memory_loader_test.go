@@ -0,0 +1,181 @@
+package pure
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/component/cache"
+	"github.com/warpstreamlabs/bento/internal/component/testutil"
+	"github.com/warpstreamlabs/bento/internal/manager"
+)
+
+func memCacheGet(t testing.TB, mgr *manager.Type, key string) (string, error) {
+	t.Helper()
+
+	var value []byte
+	var err error
+	require.NoError(t, mgr.AccessCache(context.Background(), "foo", func(c cache.V1) {
+		value, err = c.Get(context.Background(), key)
+	}))
+	return string(value), err
+}
+
+func TestMemoryCacheWarmUp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"id":"a","name":"Ash"}
+{"id":"b","name":"Brock"}
+{"id":"c","name":"Misty","deleted":true}
+{"id":"d","profile":{"age":10}}
+`), 0o644))
+
+	mgrConf, err := testutil.ManagerFromYAML(`
+cache_resources:
+  - label: foo
+    memory:
+      default_ttl: 1ms
+      compaction_interval: 1ms
+      warm_up:
+        input:
+          file:
+            paths: [ ` + path + ` ]
+        mapping: |
+          root = { this.id: if this.deleted.or(false) { null } else { this.name | this.profile } }
+        refresh_interval: 10ms
+`)
+	require.NoError(t, err)
+
+	mgr, err := manager.New(mgrConf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		mgr.TriggerStopConsuming()
+		require.NoError(t, mgr.WaitForClose(context.Background()))
+	})
+
+	// Loaded keys are exempt from the TTL.
+	time.Sleep(time.Millisecond * 5)
+
+	v, err := memCacheGet(t, mgr, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "Ash", v)
+
+	v, err = memCacheGet(t, mgr, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "Brock", v)
+
+	_, err = memCacheGet(t, mgr, "c")
+	require.Error(t, err)
+
+	v, err = memCacheGet(t, mgr, "d")
+	require.NoError(t, err)
+	assert.Equal(t, `{"age":10}`, v)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"id":"a","name":"Ash Ketchum"}
+`), 0o644))
+
+	assert.Eventually(t, func() bool {
+		v, err := memCacheGet(t, mgr, "a")
+		return err == nil && v == "Ash Ketchum"
+	}, time.Second*5, time.Millisecond*10)
+
+	_, err = memCacheGet(t, mgr, "b")
+	require.Error(t, err)
+}
+
+func TestMemoryCacheWarmUpTimeout(t *testing.T) {
+	mgrConf, err := testutil.ManagerFromYAML(`
+cache_resources:
+  - label: foo
+    memory:
+      warm_up:
+        input:
+          generate:
+            interval: 1s
+            mapping: 'root = {}'
+        mapping: 'root = this'
+        timeout: 10ms
+`)
+	require.NoError(t, err)
+
+	_, err = manager.New(mgrConf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to warm up cache")
+}
+
+func TestMemoryCacheChangeFeed(t *testing.T) {
+	mgrConf, err := testutil.ManagerFromYAML(`
+cache_resources:
+  - label: foo
+    memory:
+      init_values:
+        b: Brock
+      change_feed:
+        input:
+          generate:
+            count: 3
+            interval: ""
+            mapping: |
+              root = match counter() {
+                1 => { "id": "a", "name": "Ash" }
+                2 => { "id": "b", "deleted": true }
+                _ => { "id": "c", "name": "Misty" }
+              }
+        mapping: |
+          root = { this.id: if this.deleted.or(false) { null } else { this.name } }
+`)
+	require.NoError(t, err)
+
+	mgr, err := manager.New(mgrConf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		mgr.TriggerStopConsuming()
+		require.NoError(t, mgr.WaitForClose(context.Background()))
+	})
+
+	assert.Eventually(t, func() bool {
+		_, err := memCacheGet(t, mgr, "c")
+		return err == nil
+	}, time.Second*5, time.Millisecond*10)
+
+	v, err := memCacheGet(t, mgr, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "Ash", v)
+
+	_, err = memCacheGet(t, mgr, "b")
+	require.Error(t, err)
+}
+
+func TestMemoryCacheChangeFeedRetry(t *testing.T) {
+	mgrConf, err := testutil.ManagerFromYAML(`
+cache_resources:
+  - label: foo
+    memory:
+      change_feed:
+        input:
+          generate:
+            count: 1
+            interval: ""
+            mapping: 'root = { "id": "a", "name": "Ash" }'
+        mapping: |
+          root = if counter() == 1 { throw("not yet") } else { { this.id: this.name } }
+`)
+	require.NoError(t, err)
+
+	mgr, err := manager.New(mgrConf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		mgr.TriggerStopConsuming()
+		require.NoError(t, mgr.WaitForClose(context.Background()))
+	})
+
+	// The change that failed to map is nacked and delivered again.
+	assert.Eventually(t, func() bool {
+		v, err := memCacheGet(t, mgr, "a")
+		return err == nil && v == "Ash"
+	}, time.Second*5, time.Millisecond*10)
+}
@@ -253,3 +253,68 @@ shards: 10
 		assert.Equal(b, value, res)
 	}
 }
+
+func TestMemoryCacheAtomicOperations(t *testing.T) {
+	defConf, err := memCacheConfig().ParseYAML(``, nil)
+	require.NoError(t, err)
+
+	c, err := newMemCacheFromConfig(defConf)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	v, err := c.Increment(ctx, "counter", 5, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), v)
+
+	v, err = c.Increment(ctx, "counter", -2, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), v)
+
+	require.NoError(t, c.Set(ctx, "notanumber", []byte("foo"), nil))
+	_, err = c.Increment(ctx, "notanumber", 1, nil)
+	require.Error(t, err)
+
+	assert.ErrorIs(t, c.CompareAndSwap(ctx, "nope", []byte("a"), []byte("b"), nil), service.ErrKeyNotFound)
+	assert.ErrorIs(t, c.CompareAndSwap(ctx, "counter", []byte("4"), []byte("10"), nil), service.ErrCompareAndSwapFailed)
+	require.NoError(t, c.CompareAndSwap(ctx, "counter", []byte("3"), []byte("10"), nil))
+
+	values, err := c.GetMulti(ctx, "counter", "notanumber", "nope")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"counter":    []byte("10"),
+		"notanumber": []byte("foo"),
+	}, values)
+}
+
+func TestMemoryCacheScan(t *testing.T) {
+	conf, err := memCacheConfig().ParseYAML(`
+shards: 4
+`, nil)
+	require.NoError(t, err)
+
+	c, err := newMemCacheFromConfig(conf)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	ttl := time.Millisecond
+	require.NoError(t, c.Set(ctx, "foo", []byte("a"), nil))
+	require.NoError(t, c.Set(ctx, "foobar", []byte("b"), nil))
+	require.NoError(t, c.Set(ctx, "foobaz", []byte("c"), &ttl))
+	require.NoError(t, c.Set(ctx, "bar", []byte("d"), nil))
+
+	<-time.After(time.Millisecond * 5)
+
+	keys, err := c.Scan(ctx, "foo")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"foo", "foobar"}, keys)
+
+	keys, err = c.Scan(ctx, "")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"foo", "foobar", "bar"}, keys)
+
+	keys, err = c.Scan(ctx, "nope")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
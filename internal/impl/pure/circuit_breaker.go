@@ -0,0 +1,273 @@
+package pure
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+	"github.com/warpstreamlabs/bento/internal/log"
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	cbFieldFailureThreshold = "failure_threshold"
+	cbFieldMinRequests      = "min_requests"
+	cbFieldWindow           = "window"
+	cbFieldCooldown         = "cooldown"
+	cbFieldHalfOpenProbes   = "half_open_probes"
+)
+
+// errCircuitOpen is returned for requests that are rejected by an open circuit
+// breaker without being attempted.
+var errCircuitOpen = errors.New("circuit breaker is open")
+
+const circuitBreakerStatesDescription = `
+## States
+
+A circuit breaker begins in the closed state, where all requests are attempted and the outcome of each is recorded within a rolling ` + "`window`" + `. Once at least ` + "`min_requests`" + ` were recorded within the window, and the proportion of those that failed reaches the ` + "`failure_threshold`" + `, the circuit breaker opens.
+
+Whilst open all requests fail immediately without being attempted, until the ` + "`cooldown`" + ` period has passed and the circuit breaker becomes half-open.
+
+Whilst half-open up to ` + "`half_open_probes`" + ` requests are attempted as probes whilst any others fail immediately. Once all probes succeed the circuit breaker closes, but if any probe fails then it opens again for another cooldown period.
+
+## Metrics
+
+The current state is exported as the gauge ` + "`circuit_breaker_state`" + `, where ` + "`0`" + ` is closed, ` + "`1`" + ` is open and ` + "`2`" + ` is half-open. The counter ` + "`circuit_breaker_rejected`" + ` is incremented for each request that fails immediately, and ` + "`circuit_breaker_opened`" + ` each time the circuit breaker opens. Each change of state is also logged.`
+
+func circuitBreakerFields() []*service.ConfigField {
+	return []*service.ConfigField{
+		service.NewFloatField(cbFieldFailureThreshold).
+			Description("The proportion of requests within the window that must fail in order to open the circuit breaker, between zero and one.").
+			Default(0.5).
+			LintRule(`root = if this <= 0 || this > 1 { [ "failure_threshold must be larger than zero and no larger than one" ] }`),
+		service.NewIntField(cbFieldMinRequests).
+			Description("The minimum number of requests that must be recorded within the window before the failure threshold is evaluated.").
+			Default(10).
+			LintRule(`root = if this < 1 { [ "min_requests must be larger than zero" ] }`),
+		service.NewDurationField(cbFieldWindow).
+			Description("The rolling period of time within which the outcome of requests are recorded whilst closed.").
+			Default("10s"),
+		service.NewDurationField(cbFieldCooldown).
+			Description("The period of time to fail requests immediately once opened, before allowing probe requests.").
+			Default("30s"),
+		service.NewIntField(cbFieldHalfOpenProbes).
+			Description("The number of probe requests attempted whilst half-open, all of which must succeed in order to close the circuit breaker.").
+			Default(1).
+			LintRule(`root = if this < 1 { [ "half_open_probes must be larger than zero" ] }`),
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type cbState int
+
+const (
+	cbStateClosed cbState = iota
+	cbStateOpen
+	cbStateHalfOpen
+)
+
+func (s cbState) String() string {
+	switch s {
+	case cbStateClosed:
+		return "closed"
+	case cbStateOpen:
+		return "open"
+	case cbStateHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// The window of a closed circuit breaker is divided into this many buckets,
+// where the oldest bucket is discarded as time passes.
+const cbWindowBuckets = 10
+
+type cbBucket struct {
+	successes int
+	failures  int
+}
+
+type circuitBreaker struct {
+	mut sync.Mutex
+
+	state cbState
+	gen   int
+
+	buckets     [cbWindowBuckets]cbBucket
+	current     int
+	bucketStart time.Time
+
+	openedAt       time.Time
+	probesInFlight int
+	probeSuccesses int
+
+	failureThreshold float64
+	minRequests      int
+	bucketPeriod     time.Duration
+	cooldown         time.Duration
+	halfOpenProbes   int
+
+	log       log.Modular
+	mState    metrics.StatGauge
+	mRejected metrics.StatCounter
+	mOpened   metrics.StatCounter
+	nowFn     func() time.Time
+}
+
+func circuitBreakerFromParsed(conf *service.ParsedConfig, log log.Modular, stats metrics.Type) (*circuitBreaker, error) {
+	threshold, err := conf.FieldFloat(cbFieldFailureThreshold)
+	if err != nil {
+		return nil, err
+	}
+	minRequests, err := conf.FieldInt(cbFieldMinRequests)
+	if err != nil {
+		return nil, err
+	}
+	window, err := conf.FieldDuration(cbFieldWindow)
+	if err != nil {
+		return nil, err
+	}
+	cooldown, err := conf.FieldDuration(cbFieldCooldown)
+	if err != nil {
+		return nil, err
+	}
+	probes, err := conf.FieldInt(cbFieldHalfOpenProbes)
+	if err != nil {
+		return nil, err
+	}
+
+	if threshold <= 0 || threshold > 1 {
+		return nil, errors.New("failure_threshold must be larger than zero and no larger than one")
+	}
+	if minRequests < 1 {
+		return nil, errors.New("min_requests must be larger than zero")
+	}
+	if window <= 0 {
+		return nil, errors.New("window must be larger than zero")
+	}
+	if probes < 1 {
+		return nil, errors.New("half_open_probes must be larger than zero")
+	}
+
+	c := &circuitBreaker{
+		failureThreshold: threshold,
+		minRequests:      minRequests,
+		bucketPeriod:     window / cbWindowBuckets,
+		cooldown:         cooldown,
+		halfOpenProbes:   probes,
+
+		log:       log,
+		mState:    stats.GetGauge("circuit_breaker_state"),
+		mRejected: stats.GetCounter("circuit_breaker_rejected"),
+		mOpened:   stats.GetCounter("circuit_breaker_opened"),
+		nowFn:     time.Now,
+	}
+	c.bucketStart = c.nowFn()
+	c.mState.Set(int64(cbStateClosed))
+	return c, nil
+}
+
+// allow returns whether a request may be attempted and, if so, a func that
+// must be called with the outcome of the request.
+func (c *circuitBreaker) allow() (done func(success bool), allowed bool) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	now := c.nowFn()
+	if c.state == cbStateOpen {
+		if now.Sub(c.openedAt) < c.cooldown {
+			c.mRejected.Incr(1)
+			return nil, false
+		}
+		c.transition(cbStateHalfOpen, now)
+	}
+
+	if c.state == cbStateHalfOpen {
+		if c.probesInFlight+c.probeSuccesses >= c.halfOpenProbes {
+			c.mRejected.Incr(1)
+			return nil, false
+		}
+		c.probesInFlight++
+	}
+
+	gen := c.gen
+	return func(success bool) {
+		c.record(gen, success)
+	}, true
+}
+
+func (c *circuitBreaker) record(gen int, success bool) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	// Outcomes of requests attempted before the last change of state are
+	// discarded.
+	if gen != c.gen {
+		return
+	}
+
+	now := c.nowFn()
+	switch c.state {
+	case cbStateClosed:
+		c.rotate(now)
+		if success {
+			c.buckets[c.current].successes++
+			return
+		}
+		c.buckets[c.current].failures++
+
+		var total, failures int
+		for _, b := range c.buckets {
+			total += b.successes + b.failures
+			failures += b.failures
+		}
+		if total >= c.minRequests && float64(failures)/float64(total) >= c.failureThreshold {
+			c.log.Warn("Circuit breaker opened after %v of %v requests failed", failures, total)
+			c.transition(cbStateOpen, now)
+		}
+	case cbStateHalfOpen:
+		c.probesInFlight--
+		if !success {
+			c.log.Warn("Circuit breaker opened after a probe request failed")
+			c.transition(cbStateOpen, now)
+			return
+		}
+		if c.probeSuccesses++; c.probeSuccesses >= c.halfOpenProbes {
+			c.log.Info("Circuit breaker closed after %v probe requests succeeded", c.probeSuccesses)
+			c.transition(cbStateClosed, now)
+		}
+	}
+}
+
+// rotate discards the buckets of the window that have expired.
+func (c *circuitBreaker) rotate(now time.Time) {
+	for i := 0; i < cbWindowBuckets && now.Sub(c.bucketStart) >= c.bucketPeriod; i++ {
+		c.current = (c.current + 1) % cbWindowBuckets
+		c.buckets[c.current] = cbBucket{}
+		c.bucketStart = c.bucketStart.Add(c.bucketPeriod)
+	}
+	if now.Sub(c.bucketStart) >= c.bucketPeriod {
+		c.bucketStart = now
+	}
+}
+
+func (c *circuitBreaker) transition(state cbState, now time.Time) {
+	c.state = state
+	c.gen++
+	c.probesInFlight = 0
+	c.probeSuccesses = 0
+
+	switch state {
+	case cbStateClosed:
+		c.buckets = [cbWindowBuckets]cbBucket{}
+		c.bucketStart = now
+	case cbStateOpen:
+		c.openedAt = now
+		c.mOpened.Incr(1)
+	case cbStateHalfOpen:
+		c.log.Info("Circuit breaker half-open, attempting %v probe requests", c.halfOpenProbes)
+	}
+	c.mState.Set(int64(state))
+}
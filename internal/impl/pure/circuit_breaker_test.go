@@ -0,0 +1,187 @@
+package pure
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+	"github.com/warpstreamlabs/bento/internal/log"
+	"github.com/warpstreamlabs/bento/internal/manager/mock"
+	"github.com/warpstreamlabs/bento/internal/message"
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+func testCircuitBreaker(t testing.TB, confStr string) (*circuitBreaker, func(time.Duration)) {
+	t.Helper()
+
+	spec := service.NewConfigSpec().Fields(circuitBreakerFields()...)
+	conf, err := spec.ParseYAML(confStr, nil)
+	require.NoError(t, err)
+
+	c, err := circuitBreakerFromParsed(conf, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	now := time.Unix(1000, 0)
+	c.nowFn = func() time.Time {
+		return now
+	}
+	c.bucketStart = now
+	return c, func(d time.Duration) {
+		now = now.Add(d)
+	}
+}
+
+func cbAttempt(t testing.TB, c *circuitBreaker, success bool) bool {
+	t.Helper()
+
+	done, allowed := c.allow()
+	if allowed {
+		done(success)
+	}
+	return allowed
+}
+
+func TestCircuitBreakerStates(t *testing.T) {
+	c, advance := testCircuitBreaker(t, `
+failure_threshold: 0.5
+min_requests: 4
+window: 10s
+cooldown: 30s
+half_open_probes: 2
+`)
+
+	// The threshold is not evaluated until the minimum requests are recorded.
+	assert.True(t, cbAttempt(t, c, false))
+	assert.True(t, cbAttempt(t, c, false))
+	assert.True(t, cbAttempt(t, c, true))
+	assert.Equal(t, cbStateClosed, c.state)
+
+	assert.True(t, cbAttempt(t, c, false))
+	assert.Equal(t, cbStateOpen, c.state)
+
+	// Requests fail immediately until the cooldown has passed.
+	assert.False(t, cbAttempt(t, c, true))
+	advance(time.Second * 29)
+	assert.False(t, cbAttempt(t, c, true))
+
+	// Only the configured number of probes are attempted whilst half-open,
+	// and a failed probe opens the circuit breaker again.
+	advance(time.Second)
+	doneA, allowed := c.allow()
+	require.True(t, allowed)
+	assert.Equal(t, cbStateHalfOpen, c.state)
+	doneB, allowed := c.allow()
+	require.True(t, allowed)
+	_, allowed = c.allow()
+	assert.False(t, allowed)
+
+	doneA(true)
+	doneB(false)
+	assert.Equal(t, cbStateOpen, c.state)
+
+	// Once all probes succeed the circuit breaker closes.
+	advance(time.Second * 30)
+	assert.True(t, cbAttempt(t, c, true))
+	assert.Equal(t, cbStateHalfOpen, c.state)
+	assert.True(t, cbAttempt(t, c, true))
+	assert.Equal(t, cbStateClosed, c.state)
+
+	// With a fresh window.
+	assert.True(t, cbAttempt(t, c, false))
+	assert.True(t, cbAttempt(t, c, false))
+	assert.True(t, cbAttempt(t, c, false))
+	assert.Equal(t, cbStateClosed, c.state)
+}
+
+func TestCircuitBreakerWindow(t *testing.T) {
+	c, advance := testCircuitBreaker(t, `
+failure_threshold: 0.5
+min_requests: 4
+window: 10s
+`)
+
+	assert.True(t, cbAttempt(t, c, false))
+	assert.True(t, cbAttempt(t, c, false))
+	assert.True(t, cbAttempt(t, c, false))
+
+	// Failures that have left the window are discarded.
+	advance(time.Second * 11)
+	assert.True(t, cbAttempt(t, c, false))
+	assert.True(t, cbAttempt(t, c, true))
+	assert.True(t, cbAttempt(t, c, true))
+	assert.True(t, cbAttempt(t, c, true))
+	assert.Equal(t, cbStateClosed, c.state)
+
+	advance(time.Second * 5)
+	assert.True(t, cbAttempt(t, c, false))
+	assert.Equal(t, cbStateClosed, c.state)
+	assert.True(t, cbAttempt(t, c, false))
+	assert.Equal(t, cbStateOpen, c.state)
+}
+
+func TestCircuitBreakerStaleOutcomes(t *testing.T) {
+	c, _ := testCircuitBreaker(t, `
+min_requests: 1
+cooldown: 0s
+`)
+
+	doneA, allowed := c.allow()
+	require.True(t, allowed)
+
+	assert.True(t, cbAttempt(t, c, false))
+	assert.Equal(t, cbStateOpen, c.state)
+
+	// The outcome of a request attempted before opening is ignored.
+	doneA(true)
+	assert.Equal(t, cbStateOpen, c.state)
+}
+
+func TestCircuitBreakerOutput(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	conf := parseYAMLOutputConf(t, `
+circuit_breaker:
+  min_requests: 2
+  cooldown: 1h
+  output:
+    reject: nope
+`)
+
+	mgr := mock.NewManager()
+	s, err := mgr.NewOutput(conf)
+	require.NoError(t, err)
+
+	sendChan := make(chan message.Transaction)
+	require.NoError(t, s.Consume(sendChan))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		s.TriggerCloseNow()
+		require.NoError(t, s.WaitForClose(ctx))
+		done()
+	})
+
+	send := func() error {
+		resChan := make(chan error)
+		select {
+		case sendChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("hello")}), resChan):
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+		select {
+		case err := <-resChan:
+			return err
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+		return nil
+	}
+
+	require.EqualError(t, send(), "nope")
+	require.EqualError(t, send(), "nope")
+	require.ErrorIs(t, send(), errCircuitOpen)
+}
@@ -0,0 +1,195 @@
+package pure
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Jeffail/shutdown"
+
+	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/interop"
+	"github.com/warpstreamlabs/bento/internal/component/output"
+	"github.com/warpstreamlabs/bento/internal/message"
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const cboFieldOutput = "output"
+
+func circuitBreakerOutputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Categories("Utility").
+		Beta().
+		Version("1.14.0").
+		Summary("Writes messages to a child output, and stops attempting writes for a cooldown period once the proportion of writes that fail reaches a threshold.").
+		Description(`
+When a downstream service is unavailable continuing to attempt writes can prolong the outage, and delays the rerouting of messages to alternative destinations. This output tracks the outcome of writes to a child output and, once too many fail, fails all writes immediately without attempting them until the child output has had a chance to recover.
+
+This is most useful when combined with a `+"[`fallback`](/docs/components/outputs/fallback)"+` output, which then reroutes messages to the next output instantly whilst the circuit breaker is open.
+`+circuitBreakerStatesDescription).
+		Example(
+			"Fail Over Instantly",
+			"Here we write messages to an HTTP service, and whilst that service is failing we immediately write messages to a queue instead rather than waiting on each request to fail.",
+			`
+output:
+  fallback:
+    - circuit_breaker:
+        failure_threshold: 0.5
+        min_requests: 20
+        window: 30s
+        cooldown: 1m
+        output:
+          http_client:
+            url: http://example.com/events
+            verb: POST
+            retries: 0
+    - kafka:
+        addresses: [ localhost:9092 ]
+        topic: events_backlog
+`,
+		).
+		Fields(circuitBreakerFields()...).
+		Fields(
+			service.NewOutputField(cboFieldOutput).
+				Description("A child output."),
+		)
+}
+
+func init() {
+	err := service.RegisterBatchOutput(
+		"circuit_breaker", circuitBreakerOutputSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (out service.BatchOutput, batchPolicy service.BatchPolicy, maxInFlight int, err error) {
+			maxInFlight = 1
+
+			var s output.Streamed
+			if s, err = circuitBreakerOutputFromConfig(conf, interop.UnwrapManagement(mgr)); err != nil {
+				return
+			}
+			out = interop.NewUnwrapInternalOutput(s)
+			return
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func circuitBreakerOutputFromConfig(conf *service.ParsedConfig, mgr bundle.NewManagement) (*circuitBreakerOutput, error) {
+	breaker, err := circuitBreakerFromParsed(conf, mgr.Logger(), mgr.Metrics())
+	if err != nil {
+		return nil, err
+	}
+
+	pOut, err := conf.FieldOutput(cboFieldOutput)
+	if err != nil {
+		return nil, err
+	}
+
+	return &circuitBreakerOutput{
+		wrapped:         interop.UnwrapOwnedOutput(pOut),
+		breaker:         breaker,
+		transactionsOut: make(chan message.Transaction),
+		shutSig:         shutdown.NewSignaller(),
+	}, nil
+}
+
+//------------------------------------------------------------------------------
+
+// circuitBreakerOutput is an output type that writes messages to a child output
+// whilst a circuit breaker permits it, and otherwise fails them immediately.
+type circuitBreakerOutput struct {
+	wrapped output.Streamed
+	breaker *circuitBreaker
+
+	transactionsIn  <-chan message.Transaction
+	transactionsOut chan message.Transaction
+
+	shutSig *shutdown.Signaller
+}
+
+func (c *circuitBreakerOutput) loop() {
+	wg := sync.WaitGroup{}
+
+	defer func() {
+		wg.Wait()
+		close(c.transactionsOut)
+		c.wrapped.TriggerCloseNow()
+		_ = c.wrapped.WaitForClose(context.Background())
+		c.shutSig.TriggerHasStopped()
+	}()
+
+	cnCtx, cnDone := c.shutSig.HardStopCtx(context.Background())
+	defer cnDone()
+
+	for {
+		var tran message.Transaction
+		var open bool
+		select {
+		case tran, open = <-c.transactionsIn:
+			if !open {
+				return
+			}
+		case <-c.shutSig.HardStopChan():
+			return
+		}
+
+		done, allowed := c.breaker.allow()
+		if !allowed {
+			if err := tran.Ack(cnCtx, errCircuitOpen); err != nil && cnCtx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		rChan := make(chan error)
+		select {
+		case c.transactionsOut <- message.NewTransaction(tran.Payload, rChan):
+		case <-c.shutSig.HardStopChan():
+			return
+		}
+
+		wg.Add(1)
+		go func(ts message.Transaction) {
+			defer wg.Done()
+
+			var res error
+			select {
+			case res = <-rChan:
+			case <-c.shutSig.HardStopChan():
+				return
+			}
+
+			done(res == nil)
+			_ = ts.Ack(cnCtx, res)
+		}(tran)
+	}
+}
+
+// Consume assigns a messages channel for the output to read.
+func (c *circuitBreakerOutput) Consume(ts <-chan message.Transaction) error {
+	if c.transactionsIn != nil {
+		return component.ErrAlreadyStarted
+	}
+	if err := c.wrapped.Consume(c.transactionsOut); err != nil {
+		return err
+	}
+	c.transactionsIn = ts
+	go c.loop()
+	return nil
+}
+
+func (c *circuitBreakerOutput) ConnectionStatus() component.ConnectionStatuses {
+	return c.wrapped.ConnectionStatus()
+}
+
+func (c *circuitBreakerOutput) TriggerCloseNow() {
+	c.shutSig.TriggerHardStop()
+}
+
+func (c *circuitBreakerOutput) WaitForClose(ctx context.Context) error {
+	select {
+	case <-c.shutSig.HasStoppedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
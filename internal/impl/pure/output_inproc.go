@@ -11,6 +11,7 @@ import (
 	"github.com/warpstreamlabs/bento/internal/component/output"
 	"github.com/warpstreamlabs/bento/internal/log"
 	"github.com/warpstreamlabs/bento/internal/message"
+	"github.com/warpstreamlabs/bento/internal/tracing"
 	"github.com/warpstreamlabs/bento/public/service"
 )
 
@@ -88,8 +89,18 @@ func (i *inprocOutput) loop() {
 			return
 		}
 
+		// Each message is given a span for the hop between streams, which the
+		// components of the consuming stream then nest their spans under.
+		payload, spans := tracing.WithChildSpans(i.mgr.Tracer(), "output_inproc", ts.Payload)
+		tsOut := message.NewTransactionFunc(payload, func(ctx context.Context, err error) error {
+			for _, s := range spans {
+				s.Finish()
+			}
+			return ts.Ack(ctx, err)
+		})
+
 		select {
-		case i.transactionsOut <- ts:
+		case i.transactionsOut <- tsOut:
 		case <-i.shutSig.HardStopChan():
 			return
 		}
@@ -7,11 +7,15 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/warpstreamlabs/bento/internal/component"
 	"github.com/warpstreamlabs/bento/internal/component/output"
 	"github.com/warpstreamlabs/bento/internal/manager"
 	"github.com/warpstreamlabs/bento/internal/message"
+	"github.com/warpstreamlabs/bento/internal/tracing"
 
 	_ "github.com/warpstreamlabs/bento/public/components/pure"
 )
@@ -65,3 +69,64 @@ func TestInproc(t *testing.T) {
 	_, err = mgr.GetPipe("foo")
 	assert.Equal(t, err, component.ErrPipeNotFound)
 }
+
+func TestInprocTracing(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	rec := tracetest.NewSpanRecorder()
+	prov := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+
+	mgr, err := manager.New(manager.NewResourceConfig(), manager.OptSetTracer(prov))
+	require.NoError(t, err)
+
+	conf := output.NewConfig()
+	conf.Type = "inproc"
+	conf.Plugin = "foo"
+
+	ip, err := mgr.NewOutput(conf)
+	require.NoError(t, err)
+
+	tinchan := make(chan message.Transaction)
+	require.NoError(t, ip.Consume(tinchan))
+
+	part := tracing.InitSpan(prov, "input_foo", message.NewPart([]byte("hello")))
+	parentSC := trace.SpanContextFromContext(part.GetContext())
+
+	resChan := make(chan error, 1)
+	select {
+	case tinchan <- message.NewTransaction(message.Batch{part}, resChan):
+	case <-tCtx.Done():
+		t.Fatal("Timed out")
+	}
+
+	toutchan, err := mgr.GetPipe("foo")
+	require.NoError(t, err)
+
+	var tran message.Transaction
+	select {
+	case tran = <-toutchan:
+	case <-tCtx.Done():
+		t.Fatal("Timed out")
+	}
+
+	require.Len(t, tran.Payload, 1)
+	assert.Equal(t, "hello", string(tran.Payload[0].AsBytes()))
+
+	sc := trace.SpanContextFromContext(tran.Payload[0].GetContext())
+	assert.Equal(t, parentSC.TraceID(), sc.TraceID())
+	assert.NotEqual(t, parentSC.SpanID(), sc.SpanID())
+	assert.Empty(t, rec.Ended())
+
+	require.NoError(t, tran.Ack(tCtx, nil))
+	require.NoError(t, <-resChan)
+
+	ended := rec.Ended()
+	require.Len(t, ended, 1)
+	assert.Equal(t, "output_inproc", ended[0].Name())
+	assert.Equal(t, parentSC.SpanID(), ended[0].Parent().SpanID())
+	assert.Equal(t, sc.SpanID(), ended[0].SpanContext().SpanID())
+
+	ip.TriggerCloseNow()
+	require.NoError(t, ip.WaitForClose(tCtx))
+}
@@ -136,7 +136,7 @@ func newBloblang(conf string, mgr bundle.NewManagement) (processor.AutoObservedB
 		return nil, err
 	}
 	return &bloblangProc{
-		exec: exec,
+		exec: exec.Compile(),
 		log:  mgr.Logger(),
 	}, nil
 }
@@ -2,8 +2,11 @@ package pure
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/warpstreamlabs/bento/internal/bloblang/field"
@@ -21,6 +24,7 @@ const (
 	cachePFieldOperator = "operator"
 	cachePFieldKey      = "key"
 	cachePFieldValue    = "value"
+	cachePFieldExpected = "expected_value"
 	cachePFieldTTL      = "ttl"
 )
 
@@ -56,7 +60,33 @@ can be detected with [processor error handling](/docs/configuration/error_handli
 ### `+"`delete`"+`
 
 Delete a key and its contents from the cache.  If the key does not exist the
-action is a no-op and will not fail with an error.`).
+action is a no-op and will not fail with an error.
+
+### `+"`increment`"+`
+
+Atomically add the integer value of the `+"`value`"+` field to the integer
+value of a key, or one if the field is not set, and replace the original message
+payload with the result. If the key does not exist it is treated as zero.
+Negative values decrement the key. If the stored value is not an integer the
+action fails with an error.
+
+### `+"`compare_and_swap`"+`
+
+Atomically set a key in the cache to a value only if its current contents match
+`+"`expected_value`"+`. If the contents do not match, or the key does not exist,
+the action fails with an error, which can be detected with
+[processor error handling](/docs/configuration/error_handling).
+
+### `+"`scan`"+`
+
+List the keys of the cache that begin with the value of the `+"`key`"+` field,
+and replace the original message payload with a JSON array of the keys in
+lexicographical order. An empty key lists all keys of the cache. Some caches
+list keys by reading their entire contents, which can be slow for large caches.
+
+The `+"`increment`, `compare_and_swap` and `scan`"+` operators are only supported by
+some caches, including `+"`memory`, `redis`, `sql`, `aws_dynamodb` and `nats_kv`"+`,
+and fail with an error for any others.`).
 		Example("Deduplication", `
 Deduplication can be done using the add operator with a key extracted from the message payload, since it fails when a key already exists we can remove the duplicates using a [`+"`mapping` processor"+`](/docs/components/processors/mapping):`,
 			`
@@ -96,6 +126,25 @@ pipeline:
         root = if errored().from(0) {
           deleted()
         }
+`).
+		Example("Counting", `
+Counters shared across instances of Bento can be maintained with the increment operator, here we count the events of each user over the past hour and add the result to the message metadata:`,
+			`
+pipeline:
+  processors:
+    - branch:
+        processors:
+          - cache:
+              resource: counters
+              operator: increment
+              key: '${! json("user.id") }-${! now().ts_format("2006-01-02T15") }'
+              ttl: 1h
+        result_map: 'meta user_events = content().string()'
+
+cache_resources:
+  - label: counters
+    redis:
+      url: tcp://TODO:6379
 `).
 		Example("Hydration", `
 It's possible to enrich payloads with content previously stored in a cache by using the [`+"`branch`"+`](/docs/components/processors/branch) processor:`,
@@ -122,13 +171,17 @@ cache_resources:
 		Fields(
 			service.NewStringField(cachePFieldResource).
 				Description("The [`cache` resource](/docs/components/caches/about) to target with this processor."),
-			service.NewStringEnumField(cachePFieldOperator, "set", "add", "get", "delete").
+			service.NewStringEnumField(cachePFieldOperator, "set", "add", "get", "delete", "increment", "compare_and_swap", "scan").
 				Description("The [operation](#operators) to perform with the cache."),
 			service.NewInterpolatedStringField(cachePFieldKey).
 				Description("A key to use with the cache."),
 			service.NewInterpolatedStringField(cachePFieldValue).
 				Description("A value to use with the cache (when applicable).").
 				Optional(),
+			service.NewInterpolatedStringField(cachePFieldExpected).
+				Description("The value that a key must currently be set to in order for the `compare_and_swap` operator to succeed.").
+				Version("1.14.0").
+				Optional(),
 			service.NewInterpolatedStringField(cachePFieldTTL).
 				Description("The TTL of each individual item as a duration string. After this period an item will be eligible for removal during the next compaction. Not all caches support per-key TTLs, those that do will have a configuration field `default_ttl`, and those that do not will fall back to their generally configured TTL setting.").
 				Examples("60s", "5m", "36h").
@@ -143,6 +196,7 @@ type cacheProcConfig struct {
 	Operator string
 	Key      string
 	Value    string
+	Expected string
 	TTL      string
 }
 
@@ -163,6 +217,7 @@ func init() {
 				return nil, err
 			}
 			cConf.Value, _ = conf.FieldString(cachePFieldValue)
+			cConf.Expected, _ = conf.FieldString(cachePFieldExpected)
 			cConf.TTL, _ = conf.FieldString(cachePFieldTTL)
 
 			mgr := interop.UnwrapManagement(res)
@@ -180,9 +235,10 @@ func init() {
 //------------------------------------------------------------------------------
 
 type cacheProc struct {
-	key   *field.Expression
-	value *field.Expression
-	ttl   *field.Expression
+	key      *field.Expression
+	value    *field.Expression
+	expected *field.Expression
+	ttl      *field.Expression
 
 	mgr       bundle.NewManagement
 	cacheName string
@@ -210,6 +266,11 @@ func newCache(conf cacheProcConfig, mgr bundle.NewManagement) (*cacheProc, error
 		return nil, fmt.Errorf("failed to parse value expression: %v", err)
 	}
 
+	expected, err := mgr.BloblEnvironment().NewField(conf.Expected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse expected_value expression: %v", err)
+	}
+
 	ttl, err := mgr.BloblEnvironment().NewField(conf.TTL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse ttl expression: %v", err)
@@ -220,9 +281,10 @@ func newCache(conf cacheProcConfig, mgr bundle.NewManagement) (*cacheProc, error
 	}
 
 	return &cacheProc{
-		key:   key,
-		value: value,
-		ttl:   ttl,
+		key:      key,
+		value:    value,
+		expected: expected,
+		ttl:      ttl,
 
 		mgr:       mgr,
 		cacheName: cacheName,
@@ -232,36 +294,78 @@ func newCache(conf cacheProcConfig, mgr bundle.NewManagement) (*cacheProc, error
 
 //------------------------------------------------------------------------------
 
-type cacheOperator func(ctx context.Context, cache cache.V1, key string, value []byte, ttl *time.Duration) ([]byte, bool, error)
+type cacheOperator func(ctx context.Context, cache cache.V1, key string, value, expected []byte, ttl *time.Duration) ([]byte, bool, error)
 
 func newCacheSetOperator() cacheOperator {
-	return func(ctx context.Context, cache cache.V1, key string, value []byte, ttl *time.Duration) ([]byte, bool, error) {
+	return func(ctx context.Context, cache cache.V1, key string, value, _ []byte, ttl *time.Duration) ([]byte, bool, error) {
 		err := cache.Set(ctx, key, value, ttl)
 		return nil, false, err
 	}
 }
 
 func newCacheAddOperator() cacheOperator {
-	return func(ctx context.Context, cache cache.V1, key string, value []byte, ttl *time.Duration) ([]byte, bool, error) {
+	return func(ctx context.Context, cache cache.V1, key string, value, _ []byte, ttl *time.Duration) ([]byte, bool, error) {
 		err := cache.Add(ctx, key, value, ttl)
 		return nil, false, err
 	}
 }
 
 func newCacheGetOperator() cacheOperator {
-	return func(ctx context.Context, cache cache.V1, key string, _ []byte, _ *time.Duration) ([]byte, bool, error) {
+	return func(ctx context.Context, cache cache.V1, key string, _, _ []byte, _ *time.Duration) ([]byte, bool, error) {
 		result, err := cache.Get(ctx, key)
 		return result, true, err
 	}
 }
 
 func newCacheDeleteOperator() cacheOperator {
-	return func(ctx context.Context, cache cache.V1, key string, _ []byte, ttl *time.Duration) ([]byte, bool, error) {
+	return func(ctx context.Context, cache cache.V1, key string, _, _ []byte, ttl *time.Duration) ([]byte, bool, error) {
 		err := cache.Delete(ctx, key)
 		return nil, false, err
 	}
 }
 
+func newCacheIncrementOperator() cacheOperator {
+	return func(ctx context.Context, c cache.V1, key string, value, _ []byte, ttl *time.Duration) ([]byte, bool, error) {
+		delta := int64(1)
+		if len(value) > 0 {
+			var err error
+			if delta, err = strconv.ParseInt(string(value), 10, 64); err != nil {
+				return nil, false, fmt.Errorf("value must be an integer: %w", err)
+			}
+		}
+		result, err := cache.Increment(ctx, c, key, delta, ttl)
+		if err != nil {
+			return nil, false, err
+		}
+		return strconv.AppendInt(nil, result, 10), true, nil
+	}
+}
+
+func newCacheCompareAndSwapOperator() cacheOperator {
+	return func(ctx context.Context, c cache.V1, key string, value, expected []byte, ttl *time.Duration) ([]byte, bool, error) {
+		err := cache.CompareAndSwap(ctx, c, key, expected, value, ttl)
+		return nil, false, err
+	}
+}
+
+func newCacheScanOperator() cacheOperator {
+	return func(ctx context.Context, c cache.V1, key string, value, expected []byte, ttl *time.Duration) ([]byte, bool, error) {
+		keys, err := cache.Scan(ctx, c, key)
+		if err != nil {
+			return nil, false, err
+		}
+		if keys == nil {
+			keys = []string{}
+		}
+		sort.Strings(keys)
+		result, err := json.Marshal(keys)
+		if err != nil {
+			return nil, false, err
+		}
+		return result, true, nil
+	}
+}
+
 func cacheOperatorFromString(operator string) (cacheOperator, error) {
 	switch operator {
 	case "set":
@@ -272,6 +376,12 @@ func cacheOperatorFromString(operator string) (cacheOperator, error) {
 		return newCacheGetOperator(), nil
 	case "delete":
 		return newCacheDeleteOperator(), nil
+	case "increment":
+		return newCacheIncrementOperator(), nil
+	case "compare_and_swap":
+		return newCacheCompareAndSwapOperator(), nil
+	case "scan":
+		return newCacheScanOperator(), nil
 	}
 	return nil, fmt.Errorf("operator not recognised: %v", operator)
 }
@@ -294,6 +404,13 @@ func (c *cacheProc) ProcessBatch(ctx *processor.BatchProcContext, msg message.Ba
 			return nil
 		}
 
+		expected, err := c.expected.Bytes(index, msg)
+		if err != nil {
+			err = fmt.Errorf("expected_value interpolation error: %w", err)
+			ctx.OnError(err, index, nil)
+			return nil
+		}
+
 		var ttl *time.Duration
 		ttls, err := c.ttl.String(index, msg)
 		if err != nil {
@@ -315,7 +432,7 @@ func (c *cacheProc) ProcessBatch(ctx *processor.BatchProcContext, msg message.Ba
 		var result []byte
 		var useResult bool
 		if cerr := c.mgr.AccessCache(context.Background(), c.cacheName, func(cache cache.V1) {
-			result, useResult, err = c.operator(context.Background(), cache, key, value, ttl)
+			result, useResult, err = c.operator(context.Background(), cache, key, value, expected, ttl)
 		}); cerr != nil {
 			err = cerr
 		}
@@ -214,3 +214,115 @@ cache:
 	_, ok = mgr.Caches["foocache"]["3"]
 	require.False(t, ok)
 }
+
+func TestCacheIncrement(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.Caches["foocache"] = map[string]mock.CacheItem{
+		"1": {Value: "10"},
+		"2": {Value: "nope"},
+	}
+
+	conf, err := testutil.ProcessorFromYAML(`
+cache:
+  operator: increment
+  key: ${!json("key")}
+  value: ${!json("delta")}
+  resource: foocache
+`)
+	require.NoError(t, err)
+
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	output, err := proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte(`{"key":"1","delta":5}`),
+		[]byte(`{"key":"3","delta":-2}`),
+		[]byte(`{"key":"2","delta":1}`),
+	}))
+	require.NoError(t, err)
+	require.Len(t, output, 1)
+
+	assert.Equal(t, "15", string(output[0].Get(0).AsBytes()))
+	assert.NoError(t, output[0].Get(0).ErrorGet())
+	assert.Equal(t, "-2", string(output[0].Get(1).AsBytes()))
+	assert.NoError(t, output[0].Get(1).ErrorGet())
+	assert.Error(t, output[0].Get(2).ErrorGet())
+
+	assert.Equal(t, "15", mgr.Caches["foocache"]["1"].Value)
+	assert.Equal(t, "-2", mgr.Caches["foocache"]["3"].Value)
+}
+
+func TestCacheCompareAndSwap(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.Caches["foocache"] = map[string]mock.CacheItem{
+		"1": {Value: "foo"},
+		"2": {Value: "bar"},
+	}
+
+	conf, err := testutil.ProcessorFromYAML(`
+cache:
+  operator: compare_and_swap
+  key: ${!json("key")}
+  expected_value: ${!json("expected")}
+  value: ${!json("value")}
+  resource: foocache
+`)
+	require.NoError(t, err)
+
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	input := message.QuickBatch([][]byte{
+		[]byte(`{"key":"1","expected":"foo","value":"baz"}`),
+		[]byte(`{"key":"2","expected":"foo","value":"baz"}`),
+		[]byte(`{"key":"3","expected":"foo","value":"baz"}`),
+	})
+	output, err := proc.ProcessBatch(context.Background(), input)
+	require.NoError(t, err)
+	require.Len(t, output, 1)
+
+	assert.Equal(t, message.GetAllBytes(input), message.GetAllBytes(output[0]))
+	assert.NoError(t, output[0].Get(0).ErrorGet())
+	assert.Error(t, output[0].Get(1).ErrorGet())
+	assert.Error(t, output[0].Get(2).ErrorGet())
+
+	assert.Equal(t, "baz", mgr.Caches["foocache"]["1"].Value)
+	assert.Equal(t, "bar", mgr.Caches["foocache"]["2"].Value)
+}
+
+func TestCacheScan(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.Caches["foocache"] = map[string]mock.CacheItem{
+		"foo":    {Value: "1"},
+		"foobar": {Value: "2"},
+		"bar":    {Value: "3"},
+	}
+
+	conf, err := testutil.ProcessorFromYAML(`
+cache:
+  operator: scan
+  key: ${!content()}
+  resource: foocache
+`)
+	require.NoError(t, err)
+
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	output, err := proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte(`foo`),
+		[]byte(``),
+		[]byte(`nope`),
+	}))
+	require.NoError(t, err)
+	require.Len(t, output, 1)
+
+	assert.Equal(t, [][]byte{
+		[]byte(`["foo","foobar"]`),
+		[]byte(`["bar","foo","foobar"]`),
+		[]byte(`[]`),
+	}, message.GetAllBytes(output[0]))
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, output[0].Get(i).ErrorGet())
+	}
+}
@@ -0,0 +1,146 @@
+package pure
+
+import (
+	"context"
+	"errors"
+
+	"github.com/warpstreamlabs/bento/internal/component/interop"
+	"github.com/warpstreamlabs/bento/internal/component/processor"
+	"github.com/warpstreamlabs/bento/internal/message"
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const cbpFieldProcessors = "processors"
+
+func circuitBreakerProcSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Categories("Composition").
+		Beta().
+		Version("1.14.0").
+		Summary("Executes a list of child processors on messages, and stops executing them for a cooldown period once the proportion of executions that fail reaches a threshold.").
+		Description(`
+When a downstream service used by processors is unavailable continuing to make requests can prolong the outage, and delays the handling of the failed messages. This processor tracks the outcome of executing the child processors and, once too many fail, flags all messages as failed immediately without executing them until the service has had a chance to recover. Messages that fail immediately have the error `+"`circuit breaker is open`"+`, and can be handled with [error handling patterns](/docs/configuration/error_handling).
+
+The child processors are executed for each message individually, where each message counts as a single request that fails when the child processors result in any errored messages. Messages that are already flagged as errored when they reach this processor are skipped, similar to the `+"[`try` processor](/docs/components/processors/try)"+`, and do not count as requests.
+`+circuitBreakerStatesDescription).
+		Example(
+			"Skip Enrichment",
+			"Here we enrich messages with the response of an HTTP service, and whilst that service is failing we skip the enrichment and route the messages to a separate topic for later reprocessing.",
+			`
+pipeline:
+  processors:
+    - circuit_breaker:
+        min_requests: 20
+        cooldown: 1m
+        processors:
+          - branch:
+              request_map: 'root.id = this.user_id'
+              processors:
+                - http:
+                    url: http://example.com/users
+                    verb: POST
+              result_map: 'root.user = this'
+
+output:
+  switch:
+    cases:
+      - check: errored()
+        output:
+          kafka:
+            addresses: [ localhost:9092 ]
+            topic: enrich_backlog
+      - output:
+          kafka:
+            addresses: [ localhost:9092 ]
+            topic: enriched
+`,
+		).
+		Fields(circuitBreakerFields()...).
+		Fields(
+			service.NewProcessorListField(cbpFieldProcessors).
+				Description("A list of [processors](/docs/components/processors/about/) to execute on each message."),
+		)
+}
+
+func init() {
+	err := service.RegisterBatchProcessor(
+		"circuit_breaker", circuitBreakerProcSpec(),
+		func(conf *service.ParsedConfig, res *service.Resources) (service.BatchProcessor, error) {
+			mgr := interop.UnwrapManagement(res)
+
+			breaker, err := circuitBreakerFromParsed(conf, mgr.Logger(), mgr.Metrics())
+			if err != nil {
+				return nil, err
+			}
+
+			procList, err := conf.FieldProcessorList(cbpFieldProcessors)
+			if err != nil {
+				return nil, err
+			}
+			if len(procList) == 0 {
+				return nil, errors.New("at least one child processor must be specified")
+			}
+
+			p := &circuitBreakerProc{breaker: breaker}
+			for _, tmp := range procList {
+				p.children = append(p.children, interop.UnwrapOwnedProcessor(tmp))
+			}
+			return interop.NewUnwrapInternalBatchProcessor(processor.NewAutoObservedBatchedProcessor("circuit_breaker", p, mgr)), nil
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type circuitBreakerProc struct {
+	children []processor.V1
+	breaker  *circuitBreaker
+}
+
+func (c *circuitBreakerProc) ProcessBatch(ctx *processor.BatchProcContext, msg message.Batch) ([]message.Batch, error) {
+	var resMsg message.Batch
+	for i, p := range msg {
+		if p.ErrorGet() != nil {
+			resMsg = append(resMsg, p)
+			continue
+		}
+
+		done, allowed := c.breaker.allow()
+		if !allowed {
+			ctx.OnError(errCircuitOpen, i, p)
+			resMsg = append(resMsg, p)
+			continue
+		}
+
+		resultMsgs, err := processor.ExecuteTryAll(ctx.Context(), c.children, message.Batch{p})
+		if err != nil {
+			// Execution was interrupted rather than failing, and therefore the
+			// outcome is not recorded.
+			return nil, err
+		}
+
+		success := true
+		for _, b := range resultMsgs {
+			for _, rp := range b {
+				if rp.ErrorGet() != nil {
+					success = false
+				}
+			}
+			resMsg = append(resMsg, b...)
+		}
+		done(success)
+	}
+	if len(resMsg) == 0 {
+		return nil, nil
+	}
+	return []message.Batch{resMsg}, nil
+}
+
+func (c *circuitBreakerProc) Close(ctx context.Context) error {
+	for _, child := range c.children {
+		if err := child.Close(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package pure_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/component/testutil"
+	"github.com/warpstreamlabs/bento/internal/manager/mock"
+	"github.com/warpstreamlabs/bento/internal/message"
+
+	_ "github.com/warpstreamlabs/bento/internal/impl/pure"
+)
+
+func TestCircuitBreakerProcessor(t *testing.T) {
+	conf, err := testutil.ProcessorFromYAML(`
+circuit_breaker:
+  min_requests: 2
+  cooldown: 1h
+  processors:
+    - mapping: 'root = if content() == "bad" { throw("nope") } else { content().uppercase() }'
+`)
+	require.NoError(t, err)
+
+	proc, err := mock.NewManager().NewProcessor(conf)
+	require.NoError(t, err)
+
+	batch := message.QuickBatch([][]byte{
+		[]byte("good"),
+		[]byte("bad"),
+		[]byte("bad"),
+		[]byte("good"),
+	})
+
+	// A message that was already errored is skipped.
+	batch[0].ErrorSet(assert.AnError)
+
+	msgs, res := proc.ProcessBatch(context.Background(), batch)
+	require.NoError(t, res)
+	require.Len(t, msgs, 1)
+	require.Len(t, msgs[0], 4)
+
+	assert.Equal(t, "good", string(msgs[0][0].AsBytes()))
+	assert.ErrorIs(t, msgs[0][0].ErrorGet(), assert.AnError)
+
+	for i := 1; i < 3; i++ {
+		assert.Equal(t, "bad", string(msgs[0][i].AsBytes()))
+		require.Error(t, msgs[0][i].ErrorGet())
+		assert.Contains(t, msgs[0][i].ErrorGet().Error(), "nope")
+	}
+
+	assert.Equal(t, "good", string(msgs[0][3].AsBytes()))
+	require.EqualError(t, msgs[0][3].ErrorGet(), "circuit breaker is open")
+}
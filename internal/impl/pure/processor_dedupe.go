@@ -29,7 +29,7 @@ func dedupeProcSpec() *service.ConfigSpec {
 		Stable().
 		Summary(`Deduplicates messages by storing a key value in a cache using the `+"`add`"+` operator. If the key already exists within the cache it is dropped.`).
 		Description(`
-Caches must be configured as resources, for more information check out the [cache documentation here](/docs/components/caches/about).
+Caches must be configured as resources, for more information check out the [cache documentation here](/docs/components/caches/about). When the number of keys is too large to be stored exactly the `+"[`bloom` cache](/docs/components/caches/bloom)"+` can be used instead, which uses a fixed amount of memory at the cost of occasionally dropping messages that were not duplicates.
 
 When using this processor with an output target that might fail you should always wrap the output within an indefinite `+"[`retry`](/docs/components/outputs/retry)"+` block. This ensures that during outages your messages aren't reprocessed after failures, which would result in messages being dropped.
 
@@ -0,0 +1,199 @@
+package pure_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/component/processor"
+	"github.com/warpstreamlabs/bento/internal/component/testutil"
+	"github.com/warpstreamlabs/bento/internal/manager/mock"
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+func enrichProcFromYAML(t testing.TB, confStr string) processor.V1 {
+	t.Helper()
+
+	conf, err := testutil.ProcessorFromYAML(confStr)
+	require.NoError(t, err)
+
+	proc, err := mock.NewManager().NewProcessor(conf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+		defer done()
+		require.NoError(t, proc.Close(ctx))
+	})
+	return proc
+}
+
+func enrichProcess(t testing.TB, proc processor.V1, inputs ...string) []string {
+	t.Helper()
+
+	var outputs []string
+	for _, in := range inputs {
+		msgs, err := proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{[]byte(in)}))
+		require.NoError(t, err)
+		require.Len(t, msgs, 1)
+		for _, p := range msgs[0] {
+			outputs = append(outputs, string(p.AsBytes()))
+		}
+	}
+	return outputs
+}
+
+func TestEnrichExact(t *testing.T) {
+	proc := enrichProcFromYAML(t, `
+enrich:
+  key: ${! this.product_id }
+  result_map: root.product = this.name
+  table:
+    input:
+      generate:
+        count: 1
+        interval: ""
+        mapping: |
+          root.products = [
+            { "id": "a", "name": "apple" },
+            { "id": "b", "name": "banana" },
+            { "id": "b", "name": "blueberry" },
+          ]
+    mapping: root = this.products
+    key: ${! this.id }
+`)
+
+	assert.Equal(t, []string{
+		`{"product":"apple","product_id":"a"}`,
+		`{"product":"blueberry","product_id":"b"}`,
+		`{"product_id":"c"}`,
+	}, enrichProcess(t, proc,
+		`{"product_id":"a"}`,
+		`{"product_id":"b"}`,
+		`{"product_id":"c"}`,
+	))
+}
+
+func TestEnrichPrefix(t *testing.T) {
+	proc := enrichProcFromYAML(t, `
+enrich:
+  key: ${! this.phone }
+  key_type: prefix
+  result_map: root.country = this.country
+  table:
+    input:
+      generate:
+        count: 1
+        interval: ""
+        mapping: |
+          root = [
+            { "prefix": "+44", "country": "uk" },
+            { "prefix": "+1", "country": "nanp" },
+            { "prefix": "+1242", "country": "bahamas" },
+          ]
+    mapping: root = this
+    key: ${! this.prefix }
+`)
+
+	assert.Equal(t, []string{
+		`{"country":"uk","phone":"+447700900000"}`,
+		`{"country":"nanp","phone":"+12025550100"}`,
+		`{"country":"bahamas","phone":"+12425550100"}`,
+		`{"phone":"+33100000000"}`,
+	}, enrichProcess(t, proc,
+		`{"phone":"+447700900000"}`,
+		`{"phone":"+12025550100"}`,
+		`{"phone":"+12425550100"}`,
+		`{"phone":"+33100000000"}`,
+	))
+}
+
+func TestEnrichCIDR(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "networks.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"network":"10.0.0.0/8","site":"corp"}
+{"network":"10.1.0.0/16","site":"london"}
+{"network":"10.1.2.3","site":"printer"}
+{"network":"2001:db8::/32","site":"v6"}
+`), 0o644))
+
+	proc := enrichProcFromYAML(t, `
+enrich:
+  key: ${! this.ip }
+  key_type: cidr
+  result_map: root.site = this.site
+  table:
+    input:
+      file:
+        paths: [ `+path+` ]
+    key: ${! this.network }
+`)
+
+	assert.Equal(t, []string{
+		`{"ip":"10.200.0.1","site":"corp"}`,
+		`{"ip":"10.1.9.9","site":"london"}`,
+		`{"ip":"10.1.2.3","site":"printer"}`,
+		`{"ip":"::ffff:10.1.2.3","site":"printer"}`,
+		`{"ip":"2001:db8:1::1","site":"v6"}`,
+		`{"ip":"192.168.0.1"}`,
+		`{"ip":"nope"}`,
+	}, enrichProcess(t, proc,
+		`{"ip":"10.200.0.1"}`,
+		`{"ip":"10.1.9.9"}`,
+		`{"ip":"10.1.2.3"}`,
+		`{"ip":"::ffff:10.1.2.3"}`,
+		`{"ip":"2001:db8:1::1"}`,
+		`{"ip":"192.168.0.1"}`,
+		`{"ip":"nope"}`,
+	))
+}
+
+func TestEnrichRefresh(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rows.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"id":"a","v":"first"}
+`), 0o644))
+
+	proc := enrichProcFromYAML(t, `
+enrich:
+  key: ${! content() }
+  result_map: root = this.v
+  table:
+    input:
+      file:
+        paths: [ `+path+` ]
+    key: ${! this.id }
+    refresh_interval: 10ms
+`)
+
+	assert.Equal(t, []string{"first", "b"}, enrichProcess(t, proc, "a", "b"))
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"id":"b","v":"second"}
+`), 0o644))
+
+	assert.Eventually(t, func() bool {
+		out := enrichProcess(t, proc, "a", "b")
+		return out[0] == "a" && out[1] == "second"
+	}, time.Second*5, time.Millisecond*10)
+}
+
+func TestEnrichBadTable(t *testing.T) {
+	conf, err := testutil.ProcessorFromYAML(`
+enrich:
+  key: ${! content() }
+  result_map: root = this
+  table:
+    input:
+      file:
+        paths: [ ./does_not_exist.jsonl ]
+    key: ${! this.id }
+    timeout: 100ms
+`)
+	require.NoError(t, err)
+
+	_, err = mock.NewManager().NewProcessor(conf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load table")
+}
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/warpstreamlabs/bento/internal/bloblang/field"
 	"github.com/warpstreamlabs/bento/internal/bundle"
 	"github.com/warpstreamlabs/bento/internal/component"
 	"github.com/warpstreamlabs/bento/internal/component/interop"
@@ -17,6 +18,7 @@ import (
 
 const (
 	rlimitFieldResource = "resource"
+	rlimitFieldKey      = "key"
 )
 
 func rlimitProcSpec() *service.ConfigSpec {
@@ -24,8 +26,18 @@ func rlimitProcSpec() *service.ConfigSpec {
 		Categories("Utility").
 		Stable().
 		Summary(`Throttles the throughput of a pipeline according to a specified ` + "[`rate_limit`](/docs/components/rate_limits/about)" + ` resource. Rate limits are shared across components and therefore apply globally to all processing pipelines.`).
+		Description(`
+### Keyed Limits
+
+When a ` + "`key`" + ` is specified the limit is applied separately for each distinct value of the key, which is evaluated for each message. For example, a key of ` + "`${! @tenant }`" + ` throttles each tenant independently rather than throttling all messages collectively. Only rate limits that support keyed access can be used with a key, which currently includes the ` + "[`local`](/docs/components/rate_limits/local)" + ` and ` + "[`redis`](/docs/components/rate_limits/redis)" + ` rate limits.`).
 		Field(service.NewStringField(rlimitFieldResource).
-			Description("The target [`rate_limit` resource](/docs/components/rate_limits/about)."))
+			Description("The target [`rate_limit` resource](/docs/components/rate_limits/about).")).
+		Field(service.NewInterpolatedStringField(rlimitFieldKey).
+			Description("An optional key to limit messages by, where the limit is applied separately for each key.").
+			Example(`${! @tenant }`).
+			Example(`${! json("user.id") }`).
+			Version("1.14.0").
+			Optional())
 }
 
 func init() {
@@ -37,11 +49,23 @@ func init() {
 				return nil, err
 			}
 
+			var keyStr string
+			if conf.Contains(rlimitFieldKey) {
+				if keyStr, err = conf.FieldString(rlimitFieldKey); err != nil {
+					return nil, err
+				}
+			}
+
 			mgr := interop.UnwrapManagement(res)
 			r, err := newRateLimitProc(resStr, mgr)
 			if err != nil {
 				return nil, err
 			}
+			if keyStr != "" {
+				if r.key, err = mgr.BloblEnvironment().NewField(keyStr); err != nil {
+					return nil, fmt.Errorf("failed to parse key expression: %v", err)
+				}
+			}
 			return interop.NewUnwrapInternalBatchProcessor(processor.NewAutoObservedProcessor("rate_limit", r, mgr)), nil
 		})
 	if err != nil {
@@ -51,6 +75,7 @@ func init() {
 
 type rateLimitProc struct {
 	rlName string
+	key    *field.Expression
 	mgr    bundle.NewManagement
 
 	closeChan chan struct{}
@@ -70,20 +95,39 @@ func newRateLimitProc(resStr string, mgr bundle.NewManagement) (*rateLimitProc,
 }
 
 func (r *rateLimitProc) Process(ctx context.Context, msg *message.Part) ([]*message.Part, error) {
+	var key string
+	if r.key != nil {
+		var err error
+		if key, err = r.key.String(0, message.Batch{msg}); err != nil {
+			return nil, fmt.Errorf("key evaluation error: %w", err)
+		}
+	}
+
 	for {
 		var waitFor time.Duration
-		var err error
+		var err, keyErr error
 		if rerr := r.mgr.AccessRateLimit(ctx, r.rlName, func(rl ratelimit.V1) {
-			v2, ok := rl.(ratelimit.MessageAwareRateLimit)
-			if ok {
-				v2.Add(ctx, msg)
+			// Messages are only added to the shared state of message aware
+			// rate limits when unkeyed, as keyed access is tracked per key.
+			if r.key == nil {
+				if v2, ok := rl.(ratelimit.MessageAwareRateLimit); ok {
+					v2.Add(ctx, msg)
+				}
+				waitFor, err = rl.Access(ctx)
+				return
 			}
-
-			waitFor, err = rl.Access(ctx)
-
+			keyed, ok := rl.(ratelimit.Keyed)
+			if !ok {
+				keyErr = fmt.Errorf("rate limit resource '%v' does not support keyed access", r.rlName)
+				return
+			}
+			waitFor, err = keyed.AccessKey(ctx, key)
 		}); rerr != nil {
 			err = rerr
 		}
+		if keyErr != nil {
+			return nil, keyErr
+		}
 		if ctx.Err() != nil {
 			return nil, err
 		}
@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/warpstreamlabs/bento/internal/component/testutil"
+	"github.com/warpstreamlabs/bento/internal/manager"
 	"github.com/warpstreamlabs/bento/internal/manager/mock"
 	"github.com/warpstreamlabs/bento/internal/message"
 
@@ -168,3 +169,146 @@ rate_limit:
 		t.Error("Timed out")
 	}
 }
+
+func TestRateLimitKeyed(t *testing.T) {
+	rlConf, err := testutil.RateLimitFromYAML(`
+label: foo
+local:
+  algorithm: token_bucket
+  count: 1
+  interval: 1h
+`)
+	require.NoError(t, err)
+
+	resConf := manager.NewResourceConfig()
+	resConf.ResourceRateLimits = append(resConf.ResourceRateLimits, rlConf)
+
+	mgr, err := manager.New(resConf)
+	require.NoError(t, err)
+
+	conf, err := testutil.ProcessorFromYAML(`
+rate_limit:
+  resource: foo
+  key: ${! json("key") }
+`)
+	require.NoError(t, err)
+
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	// Each key is limited independently, and therefore the first message of
+	// each passes without waiting.
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	output, res := proc.ProcessBatch(ctx, message.QuickBatch([][]byte{
+		[]byte(`{"key":"1","value":"foo 1"}`),
+		[]byte(`{"key":"2","value":"foo 2"}`),
+	}))
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+	require.Len(t, output[0], 2)
+	for _, p := range output[0] {
+		require.NoError(t, p.ErrorGet())
+	}
+
+	// Whereas a second message of the same key is throttled.
+	shortCtx, shortDone := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer shortDone()
+
+	output, res = proc.ProcessBatch(shortCtx, message.QuickBatch([][]byte{
+		[]byte(`{"key":"1","value":"foo 3"}`),
+	}))
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+	require.Len(t, output[0], 1)
+	require.Error(t, output[0][0].ErrorGet())
+
+	require.NoError(t, proc.Close(ctx))
+}
+
+func TestRateLimitKeyedIgnoresByteSize(t *testing.T) {
+	rlConf, err := testutil.RateLimitFromYAML(`
+label: foo
+local:
+  count: 100
+  byte_size: 10
+  interval: 1h
+`)
+	require.NoError(t, err)
+
+	resConf := manager.NewResourceConfig()
+	resConf.ResourceRateLimits = append(resConf.ResourceRateLimits, rlConf)
+
+	mgr, err := manager.New(resConf)
+	require.NoError(t, err)
+
+	keyedConf, err := testutil.ProcessorFromYAML(`
+rate_limit:
+  resource: foo
+  key: ${! json("key") }
+`)
+	require.NoError(t, err)
+
+	keyedProc, err := mgr.NewProcessor(keyedConf)
+	require.NoError(t, err)
+
+	unkeyedConf, err := testutil.ProcessorFromYAML(`
+rate_limit:
+  resource: foo
+`)
+	require.NoError(t, err)
+
+	unkeyedProc, err := mgr.NewProcessor(unkeyedConf)
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer done()
+
+	// Keyed access exceeds the byte size limit without consuming it.
+	output, res := keyedProc.ProcessBatch(ctx, message.QuickBatch([][]byte{
+		[]byte(`{"key":"1","value":"foo 1"}`),
+		[]byte(`{"key":"2","value":"foo 2"}`),
+	}))
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+	for _, p := range output[0] {
+		require.NoError(t, p.ErrorGet())
+	}
+
+	output, res = unkeyedProc.ProcessBatch(ctx, message.QuickBatch([][]byte{
+		[]byte(`foo`),
+	}))
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+	require.Len(t, output[0], 1)
+	require.NoError(t, output[0][0].ErrorGet())
+
+	require.NoError(t, keyedProc.Close(ctx))
+	require.NoError(t, unkeyedProc.Close(ctx))
+}
+
+func TestRateLimitKeyedNotSupported(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.RateLimits["foo"] = func(context.Context) (time.Duration, error) {
+		return 0, nil
+	}
+
+	conf, err := testutil.ProcessorFromYAML(`
+rate_limit:
+  resource: foo
+  key: ${! json("key") }
+`)
+	require.NoError(t, err)
+
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	output, res := proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte(`{"key":"1","value":"foo 1"}`),
+	}))
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+	require.Len(t, output[0], 1)
+	require.EqualError(t, output[0][0].ErrorGet(), "rate limit resource 'foo' does not support keyed access")
+}
@@ -0,0 +1,225 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	arlFieldCount          = "count"
+	arlFieldMinCount       = "min_count"
+	arlFieldMaxCount       = "max_count"
+	arlFieldInterval       = "interval"
+	arlFieldIncrease       = "increase"
+	arlFieldDecreaseFactor = "decrease_factor"
+)
+
+func adaptiveRatelimitConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Version("1.14.0").
+		Summary(`A rate limit that adjusts its limit according to feedback from the components that use it, raising throughput additively whilst requests succeed and cutting it multiplicatively when requests are throttled.`).
+		Description(`
+This rate limit implements an additive increase/multiplicative decrease (AIMD) algorithm. Requests are spaced evenly so that at most `+"`count`"+` requests are made within each `+"`interval`"+`, where the count starts at the configured value and is then adjusted according to feedback:
+
+- Each successful request raises the count such that it grows by `+"`increase`"+` for each interval worth of successful requests, up to `+"`max_count`"+`.
+- A throttled request multiplies the count by `+"`decrease_factor`"+`, down to `+"`min_count`"+`. Only one decrease is applied within each interval, as requests already in flight are likely to be throttled as well.
+- When a throttled request specifies how long to wait before trying again, such as with a `+"`Retry-After`"+` header, all access is blocked until that period has passed.
+
+Feedback is reported by components that support it, which currently includes any component based on the `+"[`http_client` output](/docs/components/outputs/http_client)"+`, where responses with a status code listed in `+"`backoff_on`"+` are reported as throttled, and the `+"`Retry-After`"+` header of any unsuccessful response is honoured. Components that do not report feedback are limited at the current count, which therefore only changes in response to those that do.
+
+Like the `+"[`local` rate limit](/docs/components/rate_limits/local)"+` this rate limit is shared only across components within a single instance of Bento.`).
+		Example(
+			"Partner API",
+			"This example sends messages to an API that throttles clients with 429 responses, starting at 50 requests per second and adapting to between 5 and 200 requests per second.",
+			`
+output:
+  http_client:
+    url: https://api.example.com/v1/events
+    verb: POST
+    rate_limit: partner_api
+    backoff_on: [ 429 ]
+
+rate_limit_resources:
+  - label: partner_api
+    adaptive:
+      count: 50
+      min_count: 5
+      max_count: 200
+      interval: 1s
+`,
+		).
+		Field(service.NewIntField(arlFieldCount).
+			Description("The initial maximum number of requests to allow for a given period of time.").
+			Default(100)).
+		Field(service.NewIntField(arlFieldMinCount).
+			Description("The lowest that the count can be reduced to when requests are throttled.").
+			Default(1)).
+		Field(service.NewIntField(arlFieldMaxCount).
+			Description("The highest that the count can be raised to whilst requests succeed.").
+			Default(1000)).
+		Field(service.NewDurationField(arlFieldInterval).
+			Description("The time window to limit requests by.").
+			Default("1s")).
+		Field(service.NewFloatField(arlFieldIncrease).
+			Description("The amount to raise the count by for each interval worth of successful requests.").
+			Default(1).
+			Advanced()).
+		Field(service.NewFloatField(arlFieldDecreaseFactor).
+			Description("The factor to multiply the count by when a request is throttled, which must be between zero and one.").
+			Default(0.5).
+			Advanced().
+			LintRule(`root = if this <= 0 || this >= 1 { [ "decrease_factor must be between zero and one" ] }`)).
+		LintRule(`root = if this.min_count.or(1) > this.max_count.or(1000) { [ "min_count must not be larger than max_count" ] } else if this.count.or(100) < this.min_count.or(1) || this.count.or(100) > this.max_count.or(1000) { [ "count must be between min_count and max_count" ] }`)
+}
+
+func init() {
+	err := service.RegisterRateLimit(
+		"adaptive", adaptiveRatelimitConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.RateLimit, error) {
+			return newAdaptiveRatelimitFromConfig(conf, mgr)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func newAdaptiveRatelimitFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*adaptiveRatelimit, error) {
+	count, err := conf.FieldInt(arlFieldCount)
+	if err != nil {
+		return nil, err
+	}
+	minCount, err := conf.FieldInt(arlFieldMinCount)
+	if err != nil {
+		return nil, err
+	}
+	maxCount, err := conf.FieldInt(arlFieldMaxCount)
+	if err != nil {
+		return nil, err
+	}
+	interval, err := conf.FieldDuration(arlFieldInterval)
+	if err != nil {
+		return nil, err
+	}
+	increase, err := conf.FieldFloat(arlFieldIncrease)
+	if err != nil {
+		return nil, err
+	}
+	decreaseFactor, err := conf.FieldFloat(arlFieldDecreaseFactor)
+	if err != nil {
+		return nil, err
+	}
+
+	if minCount <= 0 {
+		return nil, errors.New("min_count must be larger than zero")
+	}
+	if minCount > maxCount {
+		return nil, errors.New("min_count must not be larger than max_count")
+	}
+	if count < minCount || count > maxCount {
+		return nil, errors.New("count must be between min_count and max_count")
+	}
+	if interval <= 0 {
+		return nil, errors.New("interval must be larger than zero")
+	}
+	if increase < 0 {
+		return nil, errors.New("increase cannot be negative")
+	}
+	if decreaseFactor <= 0 || decreaseFactor >= 1 {
+		return nil, errors.New("decrease_factor must be between zero and one")
+	}
+
+	r := &adaptiveRatelimit{
+		count:          float64(count),
+		minCount:       float64(minCount),
+		maxCount:       float64(maxCount),
+		period:         interval,
+		increase:       increase,
+		decreaseFactor: decreaseFactor,
+		mCount:         mgr.Metrics().NewGauge("rate_limit_adaptive_count"),
+		nowFn:          time.Now,
+	}
+	r.mCount.Set(int64(count))
+	return r, nil
+}
+
+//------------------------------------------------------------------------------
+
+type adaptiveRatelimit struct {
+	mut sync.Mutex
+
+	count        float64
+	next         time.Time
+	blockedUntil time.Time
+	lastDecrease time.Time
+
+	minCount       float64
+	maxCount       float64
+	period         time.Duration
+	increase       float64
+	decreaseFactor float64
+
+	mCount *service.MetricGauge
+	nowFn  func() time.Time
+}
+
+func (r *adaptiveRatelimit) Access(ctx context.Context) (time.Duration, error) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	now := r.nowFn()
+	if now.Before(r.blockedUntil) {
+		return r.blockedUntil.Sub(now), nil
+	}
+	if now.Before(r.next) {
+		return r.next.Sub(now), nil
+	}
+
+	// Requests are spaced evenly at the current rate, and therefore a period
+	// without any requests does not result in a burst afterwards.
+	r.next = now.Add(time.Duration(float64(r.period) / r.count))
+	return 0, nil
+}
+
+// ReportSuccess raises the count by a fraction of the increase such that it
+// grows by the full increase for each interval worth of successful requests.
+func (r *adaptiveRatelimit) ReportSuccess(ctx context.Context) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	if r.count += r.increase / r.count; r.count > r.maxCount {
+		r.count = r.maxCount
+	}
+	r.mCount.Set(int64(r.count))
+}
+
+// ReportThrottled cuts the count at most once per interval, and blocks access
+// until the requested retry period has passed.
+func (r *adaptiveRatelimit) ReportThrottled(ctx context.Context, retryAfter time.Duration) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	now := r.nowFn()
+	if retryAfter > 0 {
+		if until := now.Add(retryAfter); until.After(r.blockedUntil) {
+			r.blockedUntil = until
+		}
+	}
+	if now.Sub(r.lastDecrease) < r.period {
+		return
+	}
+	r.lastDecrease = now
+
+	if r.count *= r.decreaseFactor; r.count < r.minCount {
+		r.count = r.minCount
+	}
+	r.mCount.Set(int64(r.count))
+}
+
+func (r *adaptiveRatelimit) Close(ctx context.Context) error {
+	return nil
+}
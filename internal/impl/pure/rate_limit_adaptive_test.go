@@ -0,0 +1,124 @@
+package pure
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+func testAdaptiveRatelimit(t testing.TB, confStr string) (*adaptiveRatelimit, func(time.Duration)) {
+	t.Helper()
+
+	conf, err := adaptiveRatelimitConfig().ParseYAML(confStr, nil)
+	require.NoError(t, err)
+
+	rl, err := newAdaptiveRatelimitFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+
+	now := time.Unix(1000, 0)
+	rl.nowFn = func() time.Time {
+		return now
+	}
+	return rl, func(d time.Duration) {
+		now = now.Add(d)
+	}
+}
+
+func TestAdaptiveRateLimitConfErrors(t *testing.T) {
+	for _, confStr := range []string{
+		`min_count: 0`,
+		`count: 10
+min_count: 20`,
+		`count: 10
+max_count: 5`,
+		`decrease_factor: 1`,
+		`increase: -1`,
+		`interval: 0s`,
+	} {
+		conf, err := adaptiveRatelimitConfig().ParseYAML(confStr, nil)
+		require.NoError(t, err)
+
+		_, err = newAdaptiveRatelimitFromConfig(conf, service.MockResources())
+		require.Error(t, err, confStr)
+	}
+}
+
+func TestAdaptiveRateLimitPacing(t *testing.T) {
+	rl, advance := testAdaptiveRatelimit(t, `
+count: 4
+interval: 1s
+`)
+	ctx := context.Background()
+
+	tout, err := rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), tout)
+
+	tout, err = rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Millisecond*250, tout)
+
+	advance(time.Millisecond * 250)
+	tout, err = rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), tout)
+}
+
+func TestAdaptiveRateLimitFeedback(t *testing.T) {
+	rl, advance := testAdaptiveRatelimit(t, `
+count: 10
+min_count: 2
+max_count: 12
+interval: 1s
+increase: 1
+decrease_factor: 0.5
+`)
+	ctx := context.Background()
+
+	// An interval worth of successful requests raises the count by roughly
+	// the increase.
+	for i := 0; i < 10; i++ {
+		rl.ReportSuccess(ctx)
+	}
+	assert.InDelta(t, 11, rl.count, 0.1)
+
+	for i := 0; i < 100; i++ {
+		rl.ReportSuccess(ctx)
+	}
+	assert.Equal(t, float64(12), rl.count)
+
+	// Only one decrease is applied within an interval.
+	rl.ReportThrottled(ctx, 0)
+	rl.ReportThrottled(ctx, 0)
+	assert.Equal(t, float64(6), rl.count)
+
+	advance(time.Second)
+	rl.ReportThrottled(ctx, 0)
+	assert.Equal(t, float64(3), rl.count)
+
+	advance(time.Second)
+	rl.ReportThrottled(ctx, 0)
+	assert.Equal(t, float64(2), rl.count)
+
+	// A retry period blocks all access until it has passed.
+	advance(time.Second)
+	rl.ReportThrottled(ctx, time.Second*5)
+
+	tout, err := rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Second*5, tout)
+
+	advance(time.Second * 5)
+	tout, err = rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), tout)
+
+	tout, err = rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Millisecond*500, tout)
+}
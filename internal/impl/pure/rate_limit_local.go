@@ -3,6 +3,7 @@ package pure
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -10,10 +11,34 @@ import (
 	"github.com/warpstreamlabs/bento/public/service"
 )
 
+const (
+	localRLAlgorithmFixedWindow   = "fixed_window"
+	localRLAlgorithmTokenBucket   = "token_bucket"
+	localRLAlgorithmSlidingWindow = "sliding_window"
+)
+
 func localRatelimitConfig() *service.ConfigSpec {
 	spec := service.NewConfigSpec().
 		Stable().
 		Summary(`The local rate limit is a simple X every Y type rate limit that can be shared across any number of components within the pipeline but does not support distributed rate limits across multiple running instances of Bento.`).
+		Description(`
+### Algorithms
+
+By default the ` + "`fixed_window`" + ` algorithm is used, which allows up to ` + "`count`" + ` requests within each ` + "`interval`" + `, starting from the first request after the previous interval ended. This is cheap but allows bursts of up to twice the ` + "`count`" + ` across the boundary of two intervals.
+
+The ` + "`token_bucket`" + ` algorithm refills a bucket of ` + "`burst`" + ` tokens at a rate of ` + "`count`" + ` tokens per ` + "`interval`" + `, where each request consumes a token. This smooths the rate of requests whilst still allowing short bursts.
+
+The ` + "`sliding_window`" + ` algorithm keeps a log of the time of each request, and allows a request only when fewer than ` + "`count`" + ` requests were made within the last ` + "`interval`" + `. This is the most accurate algorithm, but stores a timestamp for each request within the interval.
+
+The ` + "`byte_size`" + ` limit is only supported by the ` + "`fixed_window`" + ` algorithm.
+
+### Keyed Access
+
+When used by components that support keyed access, such as the ` + "[`rate_limit` processor](/docs/components/processors/rate_limit)" + ` with a ` + "`key`" + `, the limit is applied separately to each key. Keys that are no longer limited are discarded periodically. The ` + "`byte_size`" + ` limit does not apply to keyed access.`).
+		Field(service.NewStringEnumField("algorithm", localRLAlgorithmFixedWindow, localRLAlgorithmTokenBucket, localRLAlgorithmSlidingWindow).
+			Description("The algorithm used to enforce the limit.").
+			Default(localRLAlgorithmFixedWindow).
+			Version("1.14.0")).
 		Field(service.NewIntField("count").
 			Description("The maximum number of requests to allow for a given period of time. If `0` disables count based rate-limiting.").
 			Default(1000).LintRule(`root = if this < 0 { [ "count cannot be less than zero" ] }`)).
@@ -22,7 +47,17 @@ func localRatelimitConfig() *service.ConfigSpec {
 			Default(0).LintRule(`root = if this < 0 { [ "byte_size cannot be less than zero" ] }`)).
 		Field(service.NewDurationField("interval").
 			Description("The time window to limit requests by.").
-			Default("1s"))
+			Default("1s")).
+		Field(service.NewIntField("burst").
+			Description("The capacity of the bucket when using the `token_bucket` algorithm, which is the maximum number of requests allowed in a single burst. If `0` the capacity is equal to `count`.").
+			Default(0).
+			Advanced().
+			Version("1.14.0").
+			LintRule(`root = if this < 0 { [ "burst cannot be less than zero" ] }`)).
+		LintRule(`root = match {
+  this.algorithm.or("fixed_window") != "fixed_window" && this.byte_size.or(0) > 0 => [ "byte_size is only supported by the fixed_window algorithm" ],
+  this.algorithm.or("fixed_window") != "fixed_window" && this.count.or(1000) <= 0 => [ "count must be larger than zero for the %v algorithm".format(this.algorithm) ],
+}`)
 
 	return spec
 }
@@ -51,7 +86,23 @@ func newLocalRatelimitFromConfig(conf *service.ParsedConfig) (*localRatelimit, e
 	if err != nil {
 		return nil, err
 	}
-	return newLocalRatelimit(count, byteSize, interval)
+	rl, err := newLocalRatelimit(count, byteSize, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm, err := conf.FieldString("algorithm")
+	if err != nil {
+		return nil, err
+	}
+	burst, err := conf.FieldInt("burst")
+	if err != nil {
+		return nil, err
+	}
+	if err := rl.setAlgorithm(algorithm, burst); err != nil {
+		return nil, err
+	}
+	return rl, nil
 }
 
 //------------------------------------------------------------------------------
@@ -66,6 +117,12 @@ type localRatelimit struct {
 	size     int
 	byteSize int
 	period   time.Duration
+
+	newLimiter func(now time.Time) localLimiter
+	global     localLimiter
+	keys       map[string]localLimiter
+	lastPrune  time.Time
+	nowFn      func() time.Time
 }
 
 func newLocalRatelimit(count, byteSize int, interval time.Duration) (*localRatelimit, error) {
@@ -86,13 +143,63 @@ func newLocalRatelimit(count, byteSize int, interval time.Duration) (*localRatel
 		size:     count,
 		byteSize: byteSize,
 		period:   interval,
+
+		newLimiter: func(now time.Time) localLimiter {
+			return &localFixedWindow{remaining: count, start: now, count: count, period: interval}
+		},
+		keys:      map[string]localLimiter{},
+		lastPrune: time.Now(),
+		nowFn:     time.Now,
 	}, nil
 }
 
+func (r *localRatelimit) setAlgorithm(algorithm string, burst int) error {
+	if burst < 0 {
+		return errors.New("burst cannot be negative")
+	}
+	if algorithm == localRLAlgorithmFixedWindow {
+		return nil
+	}
+	if r.size <= 0 {
+		return fmt.Errorf("count must be larger than zero for the %v algorithm", algorithm)
+	}
+	if r.byteSize > 0 {
+		return fmt.Errorf("byte_size is not supported by the %v algorithm", algorithm)
+	}
+
+	count, period := r.size, r.period
+	switch algorithm {
+	case localRLAlgorithmTokenBucket:
+		if burst == 0 {
+			burst = count
+		}
+		r.newLimiter = func(now time.Time) localLimiter {
+			return &localTokenBucket{
+				tokens:   float64(burst),
+				last:     now,
+				capacity: float64(burst),
+				perToken: period / time.Duration(count),
+			}
+		}
+	case localRLAlgorithmSlidingWindow:
+		r.newLimiter = func(now time.Time) localLimiter {
+			return &localSlidingWindow{count: count, period: period}
+		}
+	default:
+		return fmt.Errorf("unrecognised algorithm: %v", algorithm)
+	}
+	r.global = r.newLimiter(r.nowFn())
+	return nil
+}
+
 func (r *localRatelimit) Access(ctx context.Context) (time.Duration, error) {
 	r.mut.Lock()
 	defer r.mut.Unlock()
 
+	if r.global != nil {
+		return r.global.access(r.nowFn()), nil
+	}
+
 	// Rate limiting count is enabled
 	if r.size > 0 {
 		r.bucket--
@@ -147,6 +254,30 @@ func (r *localRatelimit) Add(ctx context.Context, parts ...*message.Part) bool {
 	return r.exceededLimit
 }
 
+// AccessKey applies the limit separately for each key, where the state of keys
+// that are no longer limited is pruned once per interval.
+func (r *localRatelimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	now := r.nowFn()
+	if now.Sub(r.lastPrune) >= r.period {
+		for k, l := range r.keys {
+			if l.idle(now) {
+				delete(r.keys, k)
+			}
+		}
+		r.lastPrune = now
+	}
+
+	l, exists := r.keys[key]
+	if !exists {
+		l = r.newLimiter(now)
+		r.keys[key] = l
+	}
+	return l.access(now), nil
+}
+
 func (r *localRatelimit) refresh() {
 	r.byteBucket = r.byteSize
 	r.bucket = r.size
@@ -157,3 +288,108 @@ func (r *localRatelimit) refresh() {
 func (r *localRatelimit) Close(ctx context.Context) error {
 	return nil
 }
+
+//------------------------------------------------------------------------------
+
+// localLimiter is the state of a single limit, which is not safe for concurrent
+// use.
+type localLimiter interface {
+	// access consumes a request, or returns the time to wait until a request
+	// is allowed.
+	access(now time.Time) time.Duration
+
+	// idle returns true when the limit is in the same state as when it was
+	// created, and can therefore be discarded.
+	idle(now time.Time) bool
+}
+
+// localFixedWindow allows count requests for each period, starting from the
+// first request after the previous period ended. A count of zero disables the
+// limit.
+type localFixedWindow struct {
+	remaining int
+	start     time.Time
+
+	count  int
+	period time.Duration
+}
+
+func (f *localFixedWindow) access(now time.Time) time.Duration {
+	if f.count <= 0 {
+		return 0
+	}
+	if now.Sub(f.start) >= f.period {
+		f.remaining = f.count
+		f.start = now
+	}
+	if f.remaining > 0 {
+		f.remaining--
+		return 0
+	}
+	return f.period - now.Sub(f.start)
+}
+
+func (f *localFixedWindow) idle(now time.Time) bool {
+	return now.Sub(f.start) >= f.period
+}
+
+// localTokenBucket holds up to capacity tokens, where a token is added every
+// perToken and each request consumes one.
+type localTokenBucket struct {
+	tokens float64
+	last   time.Time
+
+	capacity float64
+	perToken time.Duration
+}
+
+func (b *localTokenBucket) refill(now time.Time) float64 {
+	tokens := b.tokens + float64(now.Sub(b.last))/float64(b.perToken)
+	if tokens > b.capacity {
+		tokens = b.capacity
+	}
+	return tokens
+}
+
+func (b *localTokenBucket) access(now time.Time) time.Duration {
+	b.tokens = b.refill(now)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	if wait := time.Duration((1 - b.tokens) * float64(b.perToken)); wait > 0 {
+		return wait
+	}
+	return 1
+}
+
+func (b *localTokenBucket) idle(now time.Time) bool {
+	return b.refill(now) >= b.capacity
+}
+
+// localSlidingWindow keeps a log of the requests made within the last period,
+// and allows a request when fewer than count are logged.
+type localSlidingWindow struct {
+	log []time.Time
+
+	count  int
+	period time.Duration
+}
+
+func (w *localSlidingWindow) access(now time.Time) time.Duration {
+	i := 0
+	for i < len(w.log) && now.Sub(w.log[i]) >= w.period {
+		i++
+	}
+	w.log = w.log[i:]
+	if len(w.log) < w.count {
+		w.log = append(w.log, now)
+		return 0
+	}
+	return w.period - now.Sub(w.log[0])
+}
+
+func (w *localSlidingWindow) idle(now time.Time) bool {
+	return len(w.log) == 0 || now.Sub(w.log[len(w.log)-1]) >= w.period
+}
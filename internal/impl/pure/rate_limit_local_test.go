@@ -2,6 +2,7 @@ package pure
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -296,3 +297,137 @@ interval: 1ns
 	close(startChan)
 	wg.Wait()
 }
+
+func testLocalRatelimitClock(t testing.TB, confStr string) (*localRatelimit, func(time.Duration)) {
+	t.Helper()
+
+	conf, err := localRatelimitConfig().ParseYAML(confStr, nil)
+	require.NoError(t, err)
+
+	rl, err := newLocalRatelimitFromConfig(conf)
+	require.NoError(t, err)
+
+	now := time.Unix(1000, 0)
+	rl.nowFn = func() time.Time {
+		return now
+	}
+	rl.lastPrune = now
+	if rl.global != nil {
+		rl.global = rl.newLimiter(now)
+	}
+	return rl, func(d time.Duration) {
+		now = now.Add(d)
+	}
+}
+
+func TestLocalRateLimitAlgorithmConfErrors(t *testing.T) {
+	for _, confStr := range []string{
+		"algorithm: token_bucket\ncount: 0\nbyte_size: 10",
+		"algorithm: sliding_window\nbyte_size: 10",
+		"algorithm: token_bucket\nburst: -1",
+	} {
+		conf, err := localRatelimitConfig().ParseYAML(confStr, nil)
+		require.NoError(t, err)
+
+		_, err = newLocalRatelimitFromConfig(conf)
+		require.Error(t, err, confStr)
+	}
+}
+
+func TestLocalRateLimitTokenBucket(t *testing.T) {
+	rl, advance := testLocalRatelimitClock(t, `
+algorithm: token_bucket
+count: 2
+interval: 1s
+burst: 3
+`)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		tout, err := rl.Access(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), tout, i)
+	}
+
+	tout, err := rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Millisecond*500, tout)
+
+	advance(time.Millisecond * 250)
+	tout, err = rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Millisecond*250, tout)
+
+	advance(time.Millisecond * 250)
+	tout, err = rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), tout)
+
+	tout, err = rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Millisecond*500, tout)
+}
+
+func TestLocalRateLimitSlidingWindow(t *testing.T) {
+	rl, advance := testLocalRatelimitClock(t, `
+algorithm: sliding_window
+count: 2
+interval: 1s
+`)
+	ctx := context.Background()
+
+	tout, _ := rl.Access(ctx)
+	assert.Equal(t, time.Duration(0), tout)
+
+	advance(time.Millisecond * 600)
+	tout, _ = rl.Access(ctx)
+	assert.Equal(t, time.Duration(0), tout)
+
+	tout, _ = rl.Access(ctx)
+	assert.Equal(t, time.Millisecond*400, tout)
+
+	// Unlike a fixed window only the oldest request has expired.
+	advance(time.Millisecond * 400)
+	tout, _ = rl.Access(ctx)
+	assert.Equal(t, time.Duration(0), tout)
+
+	tout, _ = rl.Access(ctx)
+	assert.Equal(t, time.Millisecond*600, tout)
+}
+
+func TestLocalRateLimitKeyed(t *testing.T) {
+	for _, algorithm := range []string{"fixed_window", "token_bucket", "sliding_window"} {
+		t.Run(algorithm, func(t *testing.T) {
+			rl, advance := testLocalRatelimitClock(t, fmt.Sprintf(`
+algorithm: %v
+count: 2
+interval: 1s
+`, algorithm))
+			ctx := context.Background()
+
+			for _, key := range []string{"a", "b", "a", "b"} {
+				tout, err := rl.AccessKey(ctx, key)
+				require.NoError(t, err)
+				assert.Equal(t, time.Duration(0), tout, key)
+			}
+			for _, key := range []string{"a", "b"} {
+				tout, err := rl.AccessKey(ctx, key)
+				require.NoError(t, err)
+				assert.Positive(t, tout, key)
+			}
+
+			// Keyed access does not affect the unkeyed limit.
+			tout, err := rl.Access(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, time.Duration(0), tout)
+
+			// Keys are pruned once they are no longer limited.
+			assert.Len(t, rl.keys, 2)
+			advance(time.Second)
+			tout, err = rl.AccessKey(ctx, "c")
+			require.NoError(t, err)
+			assert.Equal(t, time.Duration(0), tout)
+			assert.Len(t, rl.keys, 1)
+		})
+	}
+}
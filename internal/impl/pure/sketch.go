@@ -0,0 +1,321 @@
+package pure
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/OneOfOne/xxhash"
+)
+
+// Probabilistic data structures used by the bloom cache and the sketch
+// functions of bloblang. Each one is serialised into a compact binary form
+// prefixed with a byte identifying the structure, which allows them to be
+// stored within (and snapshotted to) arbitrary caches.
+
+const (
+	sketchTypeBloom    byte = 'b'
+	sketchTypeHLL      byte = 'h'
+	sketchTypeCountMin byte = 'c'
+)
+
+var errSketchTruncated = errors.New("sketch data is truncated")
+
+// sketchHashes returns two independent hashes of a key, which are combined in
+// order to derive any number of hash functions as described in "Less Hashing,
+// Same Performance: Building a Better Bloom Filter" by Kirsch and Mitzenmacher.
+func sketchHashes(key []byte) (h1, h2 uint64) {
+	h1 = xxhash.Checksum64S(key, 0)
+	h2 = xxhash.Checksum64S(key, h1)
+	if h2 == 0 {
+		h2 = 1
+	}
+	return
+}
+
+//------------------------------------------------------------------------------
+
+type bloomFilter struct {
+	nBits   uint64
+	nHashes uint64
+	words   []uint64
+}
+
+// newBloomFilter creates a filter sized for an expected number of items and a
+// target false positive rate once that number of items has been added.
+func newBloomFilter(capacity uint64, fpRate float64) (*bloomFilter, error) {
+	if capacity == 0 {
+		return nil, errors.New("capacity must be greater than zero")
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		return nil, fmt.Errorf("false positive rate must be between 0 and 1, got %v", fpRate)
+	}
+
+	nBits := uint64(math.Ceil(-float64(capacity) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	nHashes := uint64(math.Round(float64(nBits) / float64(capacity) * math.Ln2))
+	if nHashes < 1 {
+		nHashes = 1
+	}
+	return &bloomFilter{
+		nBits:   nBits,
+		nHashes: nHashes,
+		words:   make([]uint64, (nBits+63)/64),
+	}, nil
+}
+
+// add inserts a key into the filter and returns whether it was (probably)
+// already present.
+func (b *bloomFilter) add(key []byte) (existed bool) {
+	h1, h2 := sketchHashes(key)
+	existed = true
+	for i := uint64(0); i < b.nHashes; i++ {
+		bit := (h1 + i*h2) % b.nBits
+		word, mask := bit/64, uint64(1)<<(bit%64)
+		if b.words[word]&mask == 0 {
+			existed = false
+			b.words[word] |= mask
+		}
+	}
+	return
+}
+
+func (b *bloomFilter) test(key []byte) bool {
+	h1, h2 := sketchHashes(key)
+	for i := uint64(0); i < b.nHashes; i++ {
+		bit := (h1 + i*h2) % b.nBits
+		if b.words[bit/64]&(uint64(1)<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *bloomFilter) sameShape(other *bloomFilter) bool {
+	return b.nBits == other.nBits && b.nHashes == other.nHashes
+}
+
+func (b *bloomFilter) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, 17+len(b.words)*8)
+	data = append(data, sketchTypeBloom)
+	data = binary.LittleEndian.AppendUint64(data, b.nBits)
+	data = binary.LittleEndian.AppendUint64(data, b.nHashes)
+	for _, w := range b.words {
+		data = binary.LittleEndian.AppendUint64(data, w)
+	}
+	return data, nil
+}
+
+func (b *bloomFilter) UnmarshalBinary(data []byte) error {
+	if len(data) < 17 {
+		return errSketchTruncated
+	}
+	if data[0] != sketchTypeBloom {
+		return errors.New("data is not a bloom filter")
+	}
+	nBits := binary.LittleEndian.Uint64(data[1:])
+	nHashes := binary.LittleEndian.Uint64(data[9:])
+	if nBits == 0 || nHashes == 0 {
+		return errors.New("bloom filter has invalid dimensions")
+	}
+
+	data = data[17:]
+	nWords := (nBits + 63) / 64
+	if uint64(len(data)) != nWords*8 {
+		return errSketchTruncated
+	}
+
+	b.nBits, b.nHashes = nBits, nHashes
+	b.words = make([]uint64, nWords)
+	for i := range b.words {
+		b.words[i] = binary.LittleEndian.Uint64(data[i*8:])
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+const (
+	hllMinPrecision = 4
+	hllMaxPrecision = 18
+)
+
+type hyperLogLog struct {
+	precision uint8
+	registers []uint8
+}
+
+func newHyperLogLog(precision int64) (*hyperLogLog, error) {
+	if precision < hllMinPrecision || precision > hllMaxPrecision {
+		return nil, fmt.Errorf("precision must be between %v and %v, got %v", hllMinPrecision, hllMaxPrecision, precision)
+	}
+	return &hyperLogLog{
+		precision: uint8(precision),
+		registers: make([]uint8, 1<<precision),
+	}, nil
+}
+
+// add inserts a value into the sketch and returns whether the sketch changed.
+func (h *hyperLogLog) add(value []byte) bool {
+	hash := xxhash.Checksum64(value)
+	idx := hash >> (64 - h.precision)
+	rank := uint8(bits.LeadingZeros64(hash<<h.precision|1<<(h.precision-1))) + 1
+	if rank > h.registers[idx] {
+		h.registers[idx] = rank
+		return true
+	}
+	return false
+}
+
+func (h *hyperLogLog) count() uint64 {
+	m := float64(len(h.registers))
+
+	var alpha float64
+	switch len(h.registers) {
+	case 16:
+		alpha = 0.673
+	case 32:
+		alpha = 0.697
+	case 64:
+		alpha = 0.709
+	default:
+		alpha = 0.7213 / (1 + 1.079/m)
+	}
+
+	sum, zeros := 0.0, 0
+	for _, r := range h.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// Linear counting gives a better estimate for small cardinalities.
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+func (h *hyperLogLog) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, 2+len(h.registers))
+	data = append(data, sketchTypeHLL, h.precision)
+	return append(data, h.registers...), nil
+}
+
+func (h *hyperLogLog) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return errSketchTruncated
+	}
+	if data[0] != sketchTypeHLL {
+		return errors.New("data is not a hyperloglog sketch")
+	}
+	precision := data[1]
+	if precision < hllMinPrecision || precision > hllMaxPrecision {
+		return fmt.Errorf("hyperloglog sketch has invalid precision %v", precision)
+	}
+	if len(data)-2 != 1<<precision {
+		return errSketchTruncated
+	}
+	h.precision = precision
+	h.registers = append([]uint8(nil), data[2:]...)
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// The maximum number of counters of a count-min sketch, which keeps the size of
+// a serialised sketch within 128MiB.
+const countMinMaxCounters = 1 << 24
+
+type countMinSketch struct {
+	width    uint32
+	depth    uint32
+	counters []uint64
+}
+
+func newCountMinSketch(width, depth int64) (*countMinSketch, error) {
+	if width < 1 {
+		return nil, fmt.Errorf("width must be greater than zero, got %v", width)
+	}
+	if depth < 1 {
+		return nil, fmt.Errorf("depth must be greater than zero, got %v", depth)
+	}
+	if width > countMinMaxCounters/depth {
+		return nil, fmt.Errorf("width multiplied by depth must not exceed %v", countMinMaxCounters)
+	}
+	return &countMinSketch{
+		width:    uint32(width),
+		depth:    uint32(depth),
+		counters: make([]uint64, width*depth),
+	}, nil
+}
+
+// add increments the count of a value and returns its new estimate.
+func (c *countMinSketch) add(value []byte, count uint64) uint64 {
+	h1, h2 := sketchHashes(value)
+	estimate := uint64(math.MaxUint64)
+	for row := uint64(0); row < uint64(c.depth); row++ {
+		idx := row*uint64(c.width) + (h1+row*h2)%uint64(c.width)
+		if c.counters[idx] > math.MaxUint64-count {
+			c.counters[idx] = math.MaxUint64
+		} else {
+			c.counters[idx] += count
+		}
+		estimate = min(estimate, c.counters[idx])
+	}
+	return estimate
+}
+
+func (c *countMinSketch) estimate(value []byte) uint64 {
+	h1, h2 := sketchHashes(value)
+	estimate := uint64(math.MaxUint64)
+	for row := uint64(0); row < uint64(c.depth); row++ {
+		idx := row*uint64(c.width) + (h1+row*h2)%uint64(c.width)
+		estimate = min(estimate, c.counters[idx])
+	}
+	return estimate
+}
+
+func (c *countMinSketch) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, 9+len(c.counters)*8)
+	data = append(data, sketchTypeCountMin)
+	data = binary.LittleEndian.AppendUint32(data, c.width)
+	data = binary.LittleEndian.AppendUint32(data, c.depth)
+	for _, v := range c.counters {
+		data = binary.LittleEndian.AppendUint64(data, v)
+	}
+	return data, nil
+}
+
+func (c *countMinSketch) UnmarshalBinary(data []byte) error {
+	if len(data) < 9 {
+		return errSketchTruncated
+	}
+	if data[0] != sketchTypeCountMin {
+		return errors.New("data is not a count-min sketch")
+	}
+	width := binary.LittleEndian.Uint32(data[1:])
+	depth := binary.LittleEndian.Uint32(data[5:])
+	if width == 0 || depth == 0 {
+		return errors.New("count-min sketch has invalid dimensions")
+	}
+
+	data = data[9:]
+	nCounters := uint64(width) * uint64(depth)
+	if nCounters > countMinMaxCounters {
+		return errors.New("count-min sketch has invalid dimensions")
+	}
+	if uint64(len(data)) != nCounters*8 {
+		return errSketchTruncated
+	}
+
+	c.width, c.depth = width, depth
+	c.counters = make([]uint64, nCounters)
+	for i := range c.counters {
+		c.counters[i] = binary.LittleEndian.Uint64(data[i*8:])
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package pure
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBloomFilterFalsePositives(t *testing.T) {
+	const capacity = 10000
+
+	b, err := newBloomFilter(capacity, 0.01)
+	require.NoError(t, err)
+
+	for i := 0; i < capacity; i++ {
+		b.add([]byte(fmt.Sprintf("key-%v", i)))
+	}
+	for i := 0; i < capacity; i++ {
+		require.True(t, b.test([]byte(fmt.Sprintf("key-%v", i))), i)
+	}
+
+	falsePositives := 0
+	for i := 0; i < capacity; i++ {
+		if b.test([]byte(fmt.Sprintf("other-%v", i))) {
+			falsePositives++
+		}
+	}
+	assert.Less(t, float64(falsePositives)/capacity, 0.02)
+}
+
+func TestBloomFilterSerialisation(t *testing.T) {
+	b, err := newBloomFilter(100, 0.01)
+	require.NoError(t, err)
+
+	b.add([]byte("foo"))
+	b.add([]byte("bar"))
+
+	data, err := b.MarshalBinary()
+	require.NoError(t, err)
+
+	var restored bloomFilter
+	require.NoError(t, restored.UnmarshalBinary(data))
+	assert.True(t, restored.sameShape(b))
+	assert.True(t, restored.test([]byte("foo")))
+	assert.True(t, restored.test([]byte("bar")))
+	assert.False(t, restored.test([]byte("baz")))
+
+	require.Error(t, restored.UnmarshalBinary(data[:len(data)-1]))
+	require.Error(t, restored.UnmarshalBinary([]byte("nope")))
+
+	other, err := newBloomFilter(1000, 0.01)
+	require.NoError(t, err)
+	assert.False(t, restored.sameShape(other))
+}
+
+func TestBloomFilterBadConfig(t *testing.T) {
+	_, err := newBloomFilter(0, 0.01)
+	require.Error(t, err)
+
+	_, err = newBloomFilter(100, 0)
+	require.Error(t, err)
+
+	_, err = newBloomFilter(100, 1)
+	require.Error(t, err)
+}
+
+func TestHyperLogLogCount(t *testing.T) {
+	for _, n := range []int{0, 10, 1000, 100000} {
+		t.Run(fmt.Sprintf("%v", n), func(t *testing.T) {
+			h, err := newHyperLogLog(14)
+			require.NoError(t, err)
+
+			for i := 0; i < n; i++ {
+				h.add([]byte(fmt.Sprintf("value-%v", i)))
+				h.add([]byte(fmt.Sprintf("value-%v", i)))
+			}
+
+			// The standard error at precision 14 is ~0.8%, allow for 3%.
+			assert.InDelta(t, float64(n), float64(h.count()), math.Max(1, float64(n)*0.03))
+		})
+	}
+}
+
+func TestHyperLogLogSerialisation(t *testing.T) {
+	h, err := newHyperLogLog(4)
+	require.NoError(t, err)
+
+	assert.True(t, h.add([]byte("foo")))
+	assert.False(t, h.add([]byte("foo")))
+
+	data, err := h.MarshalBinary()
+	require.NoError(t, err)
+	assert.Len(t, data, 18)
+
+	var restored hyperLogLog
+	require.NoError(t, restored.UnmarshalBinary(data))
+	assert.Equal(t, h.count(), restored.count())
+
+	require.Error(t, restored.UnmarshalBinary(data[:10]))
+
+	_, err = newHyperLogLog(3)
+	require.Error(t, err)
+
+	_, err = newHyperLogLog(19)
+	require.Error(t, err)
+}
+
+func TestCountMinEstimate(t *testing.T) {
+	c, err := newCountMinSketch(1024, 4)
+	require.NoError(t, err)
+
+	for i := 0; i < 1000; i++ {
+		c.add([]byte(fmt.Sprintf("value-%v", i%100)), 1)
+	}
+	assert.Equal(t, uint64(50), c.add([]byte("heavy"), 50))
+
+	for i := 0; i < 100; i++ {
+		est := c.estimate([]byte(fmt.Sprintf("value-%v", i)))
+		assert.GreaterOrEqual(t, est, uint64(10))
+		assert.LessOrEqual(t, est, uint64(20))
+	}
+	assert.GreaterOrEqual(t, c.estimate([]byte("heavy")), uint64(50))
+
+	data, err := c.MarshalBinary()
+	require.NoError(t, err)
+
+	var restored countMinSketch
+	require.NoError(t, restored.UnmarshalBinary(data))
+	assert.Equal(t, c.estimate([]byte("heavy")), restored.estimate([]byte("heavy")))
+
+	require.Error(t, restored.UnmarshalBinary(data[:100]))
+
+	_, err = newCountMinSketch(0, 4)
+	require.Error(t, err)
+
+	_, err = newCountMinSketch(1<<24, 2)
+	require.Error(t, err)
+}
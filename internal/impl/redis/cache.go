@@ -3,6 +3,7 @@ package redis
 import (
 	"context"
 	"errors"
+	"strings"
 	"sync"
 	"time"
 
@@ -248,6 +249,151 @@ func (r *redisCache) Delete(ctx context.Context, key string) error {
 	}
 }
 
+// GetMulti pipelines a GET for each key rather than using MGET, which would
+// fail in cluster mode for keys within different hash slots.
+func (r *redisCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	boff := r.boffPool.Get().(backoff.BackOff)
+	defer func() {
+		boff.Reset()
+		r.boffPool.Put(boff)
+	}()
+
+	for {
+		pipe := r.client.Pipeline()
+		cmds := make([]*redis.StringCmd, len(keys))
+		for i, k := range keys {
+			cmds[i] = pipe.Get(ctx, r.prefix+k)
+		}
+		_, err := pipe.Exec(ctx)
+		if err == nil || errors.Is(err, redis.Nil) {
+			values := make(map[string][]byte, len(keys))
+			for i, cmd := range cmds {
+				res, cerr := cmd.Bytes()
+				if cerr != nil {
+					if errors.Is(cerr, redis.Nil) {
+						continue
+					}
+					return nil, cerr
+				}
+				values[keys[i]] = res
+			}
+			return values, nil
+		}
+
+		wait := boff.NextBackOff()
+		if wait == backoff.Stop {
+			return nil, err
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, err
+		}
+	}
+}
+
+var redisIncrementScript = redis.NewScript(`
+local existed = redis.call("EXISTS", KEYS[1])
+local value = redis.call("INCRBY", KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if ARGV[3] == "1" or existed == 0 then
+  if ttl > 0 then
+    redis.call("PEXPIRE", KEYS[1], ttl)
+  else
+    redis.call("PERSIST", KEYS[1])
+  end
+end
+return value
+`)
+
+// Increment is not retried as a failed attempt may still have been applied.
+func (r *redisCache) Increment(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	t, explicitTTL := r.defaultTTL, "0"
+	if ttl != nil {
+		t, explicitTTL = *ttl, "1"
+	}
+	return redisIncrementScript.Run(ctx, r.client, []string{r.prefix + key}, delta, t.Milliseconds(), explicitTTL).Int64()
+}
+
+var redisCompareAndSwapScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if not current then
+  return -1
+end
+if current ~= ARGV[1] then
+  return 0
+end
+local ttl = tonumber(ARGV[3])
+if ttl > 0 then
+  redis.call("SET", KEYS[1], ARGV[2], "PX", ttl)
+else
+  redis.call("SET", KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// CompareAndSwap is not retried as a failed attempt may still have been
+// applied, in which case a retry would report a mismatch for a swap that
+// succeeded.
+func (r *redisCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	t := r.defaultTTL
+	if ttl != nil {
+		t = *ttl
+	}
+
+	res, err := redisCompareAndSwapScript.Run(ctx, r.client, []string{r.prefix + key}, old, value, t.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	switch res {
+	case -1:
+		return service.ErrKeyNotFound
+	case 0:
+		return service.ErrCompareAndSwapFailed
+	}
+	return nil
+}
+
+var redisGlobEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+// Scan iterates the keyspace with SCAN, which in cluster mode must be run
+// against each master node in turn. SCAN can return a key more than once, and
+// so the results are deduplicated.
+func (r *redisCache) Scan(ctx context.Context, prefix string) ([]string, error) {
+	match := redisGlobEscaper.Replace(r.prefix+prefix) + "*"
+
+	var mut sync.Mutex
+	seen := map[string]struct{}{}
+	scanNode := func(ctx context.Context, c redis.Cmdable) error {
+		iter := c.Scan(ctx, 0, match, 1000).Iterator()
+		for iter.Next(ctx) {
+			key := strings.TrimPrefix(iter.Val(), r.prefix)
+			mut.Lock()
+			seen[key] = struct{}{}
+			mut.Unlock()
+		}
+		return iter.Err()
+	}
+
+	var err error
+	if cc, ok := r.client.(*redis.ClusterClient); ok {
+		err = cc.ForEachMaster(ctx, func(ctx context.Context, c *redis.Client) error {
+			return scanNode(ctx, c)
+		})
+	} else {
+		err = scanNode(ctx, r.client)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
 func (r *redisCache) Close(ctx context.Context) error {
 	return r.client.Close()
 }
@@ -0,0 +1,114 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+func redisLockConfig() *service.ConfigSpec {
+	spec := service.NewConfigSpec().
+		Beta().
+		Summary(`A lock implementation using Redis, where each lock is a key holding a token unique to the Bento instance that acquired it.`).
+		Description(`
+Locks are acquired with ` + "`SET NX PX`" + ` and renewed by extending the expiry of a key only whilst it still holds the token of this instance, which is checked atomically within a script. For the same reason a lock is only deleted when released by the instance holding it.
+
+Since a lock is only as durable as the key that stores it, a Redis deployment that can lose writes during a failover can briefly allow a lock to be held by two instances.`).
+		Version("1.14.0")
+
+	for _, f := range clientFields() {
+		spec = spec.Field(f)
+	}
+
+	spec.Field(service.NewStringField("prefix").
+		Description("A prefix added to the name of each lock in order to determine the key that stores it.").
+		Default("bento_lock:"))
+
+	return spec
+}
+
+func init() {
+	err := service.RegisterLock(
+		"redis", redisLockConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Lock, error) {
+			return newRedisLockFromConfig(conf)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// Extends the expiry of the key when it already holds our token, otherwise
+// attempts to set it. Returns 1 if the lock is held by us after the call.
+var redisLockAcquireScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return 1
+end
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return 1
+end
+return 0
+`)
+
+var redisLockReleaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+type redisLock struct {
+	client redis.UniversalClient
+	prefix string
+	token  string
+}
+
+func newRedisLockFromConfig(conf *service.ParsedConfig) (*redisLock, error) {
+	client, err := getClient(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := conf.FieldString("prefix")
+	if err != nil {
+		return nil, err
+	}
+
+	u4, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+
+	return &redisLock{
+		client: client,
+		prefix: prefix,
+		token:  u4.String(),
+	}, nil
+}
+
+func (r *redisLock) TryAcquire(ctx context.Context, name string, lease time.Duration) (bool, error) {
+	res, err := redisLockAcquireScript.Run(ctx, r.client, []string{r.prefix + name}, r.token, lease.Milliseconds()).Int64()
+	if err != nil {
+		return false, fmt.Errorf("acquiring redis lock: %w", err)
+	}
+	return res == 1, nil
+}
+
+func (r *redisLock) Release(ctx context.Context, name string) error {
+	if err := redisLockReleaseScript.Run(ctx, r.client, []string{r.prefix + name}, r.token).Err(); err != nil {
+		return fmt.Errorf("releasing redis lock: %w", err)
+	}
+	return nil
+}
+
+func (r *redisLock) Close(ctx context.Context) error {
+	return r.client.Close()
+}
@@ -0,0 +1,90 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/public/service/integration"
+)
+
+func TestIntegrationRedisLock(t *testing.T) {
+	integration.CheckSkip(t)
+
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		t.Skipf("Could not connect to docker: %s", err)
+	}
+	pool.MaxWait = time.Second * 30
+
+	resource, err := pool.Run("redis", "latest", nil)
+	if err != nil {
+		t.Fatalf("Could not start resource: %s", err)
+	}
+	t.Cleanup(func() {
+		if err = pool.Purge(resource); err != nil {
+			t.Logf("Failed to clean up docker resource: %v", err)
+		}
+	})
+
+	urlStr := fmt.Sprintf("tcp://localhost:%v", resource.GetPort("6379/tcp"))
+	client := redis.NewClient(&redis.Options{
+		Addr:    "localhost:" + resource.GetPort("6379/tcp"),
+		Network: "tcp",
+	})
+	defer client.Close()
+
+	ctx := context.Background()
+	if err = pool.Retry(func() error {
+		return client.Ping(ctx).Err()
+	}); err != nil {
+		t.Fatalf("Could not connect to docker resource: %s", err)
+	}
+
+	newLock := func() *redisLock {
+		conf, err := redisLockConfig().ParseYAML(`url: `+urlStr, nil)
+		require.NoError(t, err)
+
+		l, err := newRedisLockFromConfig(conf)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = l.Close(ctx)
+		})
+		return l
+	}
+
+	lockA, lockB := newLock(), newLock()
+
+	held, err := lockA.TryAcquire(ctx, "foo", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	held, err = lockB.TryAcquire(ctx, "foo", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	held, err = lockA.TryAcquire(ctx, "foo", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, held, "renewal")
+
+	require.NoError(t, lockB.Release(ctx, "foo"))
+	held, err = lockB.TryAcquire(ctx, "foo", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, held, "release by a different instance")
+
+	require.NoError(t, lockA.Release(ctx, "foo"))
+	held, err = lockB.TryAcquire(ctx, "foo", time.Millisecond*500)
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	assert.Eventually(t, func() bool {
+		held, err := lockA.TryAcquire(ctx, "foo", time.Minute)
+		return err == nil && held
+	}, time.Second*5, time.Millisecond*100, "lease expiry")
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -11,9 +13,29 @@ import (
 	"github.com/warpstreamlabs/bento/public/service"
 )
 
+const (
+	redisRLAlgorithmFixedWindow   = "fixed_window"
+	redisRLAlgorithmTokenBucket   = "token_bucket"
+	redisRLAlgorithmSlidingWindow = "sliding_window"
+)
+
 func redisRatelimitConfig() *service.ConfigSpec {
 	spec := service.NewConfigSpec().
-		Summary(`A rate limit implementation using Redis. It works by using a simple token bucket algorithm to limit the number of requests to a given count within a given time period. The rate limit is shared across all instances of Bento that use the same Redis instance, which must all have a consistent count and interval.`).
+		Summary(`A rate limit implementation using Redis. It limits the number of requests to a given count within a given time period. The rate limit is shared across all instances of Bento that use the same Redis instance, which must all have a consistent algorithm, count and interval.`).
+		Description(`
+### Algorithms
+
+By default the ` + "`fixed_window`" + ` algorithm is used, which counts requests with a key that expires after each ` + "`interval`" + `. This is cheap but allows bursts of up to twice the ` + "`count`" + ` across the boundary of two intervals.
+
+The ` + "`token_bucket`" + ` algorithm stores a bucket of ` + "`burst`" + ` tokens as a hash, which is refilled at a rate of ` + "`count`" + ` tokens per ` + "`interval`" + ` and where each request consumes a token. This smooths the rate of requests whilst still allowing short bursts.
+
+The ` + "`sliding_window`" + ` algorithm stores the time of each request within a sorted set, and allows a request only when fewer than ` + "`count`" + ` requests were made within the last ` + "`interval`" + `. This is the most accurate algorithm, but stores an entry for each request within the interval.
+
+The ` + "`token_bucket`" + ` and ` + "`sliding_window`" + ` algorithms use the clock of the Redis server, and therefore require Redis 5.0 or later.
+
+### Keyed Access
+
+When used by components that support keyed access, such as the ` + "[`rate_limit` processor](/docs/components/processors/rate_limit)" + ` with a ` + "`key`" + `, the limit is applied separately to each key by storing the state of each under the ` + "`key`" + ` of the rate limit suffixed with a colon and the key of the request, e.g. ` + "`ratelimit:tenant_a`" + `.`).
 		Version("1.0.0")
 
 	for _, f := range clientFields() {
@@ -27,7 +49,17 @@ func redisRatelimitConfig() *service.ConfigSpec {
 			Description("The time window to limit requests by.").
 			Default("1s")).
 		Field(service.NewStringField("key").
-			Description("The key to use for the rate limit."))
+			Description("The key to use for the rate limit.")).
+		Field(service.NewStringEnumField("algorithm", redisRLAlgorithmFixedWindow, redisRLAlgorithmTokenBucket, redisRLAlgorithmSlidingWindow).
+			Description("The algorithm used to enforce the limit.").
+			Default(redisRLAlgorithmFixedWindow).
+			Version("1.14.0")).
+		Field(service.NewIntField("burst").
+			Description("The capacity of the bucket when using the `token_bucket` algorithm, which is the maximum number of requests allowed in a single burst. If `0` the capacity is equal to `count`.").
+			Default(0).
+			Advanced().
+			Version("1.14.0").
+			LintRule(`root = if this < 0 { [ "burst cannot be less than zero" ] }`))
 
 	return spec
 }
@@ -47,14 +79,85 @@ func init() {
 
 type redisRatelimit struct {
 	size   int
+	burst  int
 	key    string
 	period time.Duration
 
 	client redis.UniversalClient
 
+	algorithm    string
 	accessScript *redis.Script
 }
 
+var redisFixedWindowScript = redis.NewScript(`
+local current = redis.call("INCR",KEYS[1])
+
+if current == 1 then
+    redis.call("PEXPIRE", KEYS[1], tonumber(ARGV[2]))
+end
+
+if current > tonumber(ARGV[1]) then
+	return redis.call("PTTL", KEYS[1])
+end
+
+return 0
+`)
+
+// Stores the number of tokens and the time they were last refilled (in
+// microseconds) as a hash, which expires once the bucket would be full again.
+var redisTokenBucketScript = redis.NewScript(`
+local count = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2]) * 1000
+local capacity = tonumber(ARGV[3])
+local per_token = interval / count
+
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now - ts) / per_token)
+
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+else
+	wait = math.max(1, math.ceil((1 - tokens) * per_token / 1000))
+end
+
+redis.call("HSET", KEYS[1], "tokens", string.format("%.17g", tokens), "ts", string.format("%.0f", now))
+redis.call("PEXPIRE", KEYS[1], math.ceil((capacity - tokens) * per_token / 1000) + 1)
+return wait
+`)
+
+// Stores the time of each request (in microseconds) within the window as a
+// sorted set, where members are made unique with a random suffix.
+var redisSlidingWindowScript = redis.NewScript(`
+local count = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local window = interval * 1000
+
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", string.format("%.0f", now - window))
+if redis.call("ZCARD", KEYS[1]) < count then
+	local score = string.format("%.0f", now)
+	redis.call("ZADD", KEYS[1], score, score .. "-" .. ARGV[3])
+	redis.call("PEXPIRE", KEYS[1], interval)
+	return 0
+end
+
+local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+return math.max(1, math.ceil((tonumber(oldest[2]) + window - now) / 1000))
+`)
+
 func newRedisRatelimitFromConfig(conf *service.ParsedConfig) (*redisRatelimit, error) {
 	client, err := getClient(conf)
 	if err != nil {
@@ -76,35 +179,69 @@ func newRedisRatelimitFromConfig(conf *service.ParsedConfig) (*redisRatelimit, e
 		return nil, err
 	}
 
+	algorithm, err := conf.FieldString("algorithm")
+	if err != nil {
+		return nil, err
+	}
+
+	burst, err := conf.FieldInt("burst")
+	if err != nil {
+		return nil, err
+	}
+
 	if count <= 0 {
 		return nil, errors.New("count must be larger than zero")
 	}
+	if burst < 0 {
+		return nil, errors.New("burst cannot be negative")
+	}
+	if burst == 0 {
+		burst = count
+	}
 
-	return &redisRatelimit{
-		size:   count,
-		period: interval,
-		client: client,
-		key:    key,
-		accessScript: redis.NewScript(`
-local current = redis.call("INCR",KEYS[1])
+	r := &redisRatelimit{
+		size:      count,
+		burst:     burst,
+		period:    interval,
+		client:    client,
+		key:       key,
+		algorithm: algorithm,
+	}
+	switch algorithm {
+	case redisRLAlgorithmFixedWindow:
+		r.accessScript = redisFixedWindowScript
+	case redisRLAlgorithmTokenBucket:
+		r.accessScript = redisTokenBucketScript
+	case redisRLAlgorithmSlidingWindow:
+		r.accessScript = redisSlidingWindowScript
+	default:
+		return nil, fmt.Errorf("unrecognised algorithm: %v", algorithm)
+	}
+	return r, nil
+}
 
-if current == 1 then
-    redis.call("PEXPIRE", KEYS[1], tonumber(ARGV[2]))
-end
+//------------------------------------------------------------------------------
 
-if current > tonumber(ARGV[1]) then
-	return redis.call("PTTL", KEYS[1])
-end
+func (r *redisRatelimit) Access(ctx context.Context) (time.Duration, error) {
+	return r.access(ctx, r.key)
+}
 
-return 0
-`),
-	}, nil
+// AccessKey applies the limit separately for each key, where the state of each
+// is stored under the key of the rate limit suffixed with the provided key.
+func (r *redisRatelimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	return r.access(ctx, r.key+":"+key)
 }
 
-//------------------------------------------------------------------------------
+func (r *redisRatelimit) access(ctx context.Context, key string) (time.Duration, error) {
+	args := []any{r.size, int(r.period.Milliseconds())}
+	switch r.algorithm {
+	case redisRLAlgorithmTokenBucket:
+		args = append(args, r.burst)
+	case redisRLAlgorithmSlidingWindow:
+		args = append(args, strconv.FormatUint(rand.Uint64(), 36))
+	}
 
-func (r *redisRatelimit) Access(ctx context.Context) (time.Duration, error) {
-	result := r.accessScript.Run(ctx, r.client, []string{r.key}, r.size, int(r.period.Milliseconds()))
+	result := r.accessScript.Run(ctx, r.client, []string{key}, args...)
 
 	if result.Err() != nil {
 		return 0, fmt.Errorf("accessing redis rate limit: %w", result.Err())
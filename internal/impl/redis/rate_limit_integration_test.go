@@ -63,6 +63,20 @@ func TestIntegrationRedisRateLimit(t *testing.T) {
 	t.Run("testRedisRateLimitRefresh", func(t *testing.T) {
 		testRedisRateLimitRefresh(t, urlStr)
 	})
+
+	for _, algorithm := range []string{"fixed_window", "token_bucket", "sliding_window"} {
+		t.Run("testRedisRateLimitKeyed/"+algorithm, func(t *testing.T) {
+			testRedisRateLimitKeyed(t, urlStr, algorithm)
+		})
+	}
+
+	t.Run("testRedisRateLimitTokenBucket", func(t *testing.T) {
+		testRedisRateLimitTokenBucket(t, urlStr)
+	})
+
+	t.Run("testRedisRateLimitSlidingWindow", func(t *testing.T) {
+		testRedisRateLimitSlidingWindow(t, urlStr)
+	})
 }
 
 func testRedisRateLimitBasic(t *testing.T, url string) {
@@ -152,3 +166,110 @@ url: `+url, nil)
 		t.Errorf("Period beyond interval: %v", period)
 	}
 }
+
+func testRedisRateLimitKeyed(t *testing.T, url, algorithm string) {
+	conf, err := redisRatelimitConfig().ParseYAML(fmt.Sprintf(`
+key: rate_limit_keyed_%v
+algorithm: %v
+count: 2
+interval: 10s
+url: %v`, algorithm, algorithm, url), nil)
+	require.NoError(t, err)
+
+	rl, err := newRedisRatelimitFromConfig(conf)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	for _, key := range []string{"a", "b", "a", "b"} {
+		period, err := rl.AccessKey(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), period, key)
+	}
+
+	for _, key := range []string{"a", "b"} {
+		period, err := rl.AccessKey(ctx, key)
+		require.NoError(t, err)
+		assert.Positive(t, period, key)
+		assert.LessOrEqual(t, period, time.Second*10, key)
+	}
+
+	// The unkeyed limit is unaffected by keyed access.
+	period, err := rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), period)
+}
+
+func testRedisRateLimitTokenBucket(t *testing.T, url string) {
+	conf, err := redisRatelimitConfig().ParseYAML(`
+key: rate_limit_token_bucket
+algorithm: token_bucket
+count: 10
+burst: 5
+interval: 1s
+url: `+url, nil)
+	require.NoError(t, err)
+
+	rl, err := newRedisRatelimitFromConfig(conf)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		period, err := rl.Access(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), period, i)
+	}
+
+	period, err := rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Positive(t, period)
+	assert.LessOrEqual(t, period, time.Millisecond*100)
+
+	<-time.After(period + 10*time.Millisecond)
+
+	period, err = rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), period)
+}
+
+func testRedisRateLimitSlidingWindow(t *testing.T, url string) {
+	conf, err := redisRatelimitConfig().ParseYAML(`
+key: rate_limit_sliding_window
+algorithm: sliding_window
+count: 2
+interval: 200ms
+url: `+url, nil)
+	require.NoError(t, err)
+
+	rl, err := newRedisRatelimitFromConfig(conf)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	period, err := rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), period)
+
+	<-time.After(100 * time.Millisecond)
+
+	period, err = rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), period)
+
+	period, err = rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Positive(t, period)
+	assert.LessOrEqual(t, period, time.Millisecond*100)
+
+	// Only the oldest request has left the window.
+	<-time.After(period + 10*time.Millisecond)
+
+	period, err = rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), period)
+
+	period, err = rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Positive(t, period)
+}
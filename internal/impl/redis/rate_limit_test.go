@@ -25,6 +25,16 @@ key: asdf`, nil)
 	_, err = newRedisRatelimitFromConfig(conf)
 	require.Error(t, err)
 
+	conf, err = redisRatelimitConfig().ParseYAML(`
+url: redis://localhost:6379
+algorithm: token_bucket
+burst: -1
+key: asdf`, nil)
+	require.NoError(t, err)
+
+	_, err = newRedisRatelimitFromConfig(conf)
+	require.Error(t, err)
+
 	_, err = redisRatelimitConfig().ParseYAML(`key: asdf`, nil)
 	require.Error(t, err)
 
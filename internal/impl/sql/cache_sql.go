@@ -1,6 +1,7 @@
 package sql
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"errors"
@@ -96,12 +97,16 @@ type sqlCache struct {
 	dsn    string
 	db     *sql.DB
 
-	keyColumn string
+	keyColumn   string
+	valueColumn string
 
-	selectBuilder squirrel.SelectBuilder
-	insertBuilder squirrel.InsertBuilder
-	upsertBuilder squirrel.InsertBuilder
-	deleteBuilder squirrel.DeleteBuilder
+	selectBuilder      squirrel.SelectBuilder
+	multiSelectBuilder squirrel.SelectBuilder
+	keysSelectBuilder  squirrel.SelectBuilder
+	updateBuilder      squirrel.UpdateBuilder
+	insertBuilder      squirrel.InsertBuilder
+	upsertBuilder      squirrel.InsertBuilder
+	deleteBuilder      squirrel.DeleteBuilder
 
 	awsConf aws.Config
 
@@ -140,6 +145,10 @@ func newSQLCacheFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (
 	}
 
 	s.selectBuilder = squirrel.Select(valueColumn).From(tableStr)
+	s.multiSelectBuilder = squirrel.Select(s.keyColumn, valueColumn).From(tableStr)
+	s.keysSelectBuilder = squirrel.Select(s.keyColumn).From(tableStr)
+	s.updateBuilder = squirrel.Update(tableStr)
+	s.valueColumn = valueColumn
 	s.insertBuilder = squirrel.Insert(tableStr).Columns(s.keyColumn, valueColumn)
 	s.upsertBuilder = squirrel.Insert(tableStr).Columns(s.keyColumn, valueColumn)
 	s.deleteBuilder = squirrel.Delete(tableStr)
@@ -147,11 +156,17 @@ func newSQLCacheFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (
 	switch s.driver {
 	case "postgres", "clickhouse":
 		s.selectBuilder = s.selectBuilder.PlaceholderFormat(squirrel.Dollar)
+		s.multiSelectBuilder = s.multiSelectBuilder.PlaceholderFormat(squirrel.Dollar)
+		s.keysSelectBuilder = s.keysSelectBuilder.PlaceholderFormat(squirrel.Dollar)
+		s.updateBuilder = s.updateBuilder.PlaceholderFormat(squirrel.Dollar)
 		s.insertBuilder = s.insertBuilder.PlaceholderFormat(squirrel.Dollar)
 		s.upsertBuilder = s.upsertBuilder.PlaceholderFormat(squirrel.Dollar)
 		s.deleteBuilder = s.deleteBuilder.PlaceholderFormat(squirrel.Dollar)
 	case "oracle", "gocosmos":
 		s.selectBuilder = s.selectBuilder.PlaceholderFormat(squirrel.Colon)
+		s.multiSelectBuilder = s.multiSelectBuilder.PlaceholderFormat(squirrel.Colon)
+		s.keysSelectBuilder = s.keysSelectBuilder.PlaceholderFormat(squirrel.Colon)
+		s.updateBuilder = s.updateBuilder.PlaceholderFormat(squirrel.Colon)
 		s.insertBuilder = s.insertBuilder.PlaceholderFormat(squirrel.Colon)
 		s.upsertBuilder = s.upsertBuilder.PlaceholderFormat(squirrel.Colon)
 		s.deleteBuilder = s.deleteBuilder.PlaceholderFormat(squirrel.Colon)
@@ -224,6 +239,84 @@ func (s *sqlCache) Add(ctx context.Context, key string, value []byte, ttl *time.
 	return err
 }
 
+func (s *sqlCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	rows, err := s.multiSelectBuilder.
+		Where(squirrel.Eq{s.keyColumn: keys}).
+		RunWith(s.db).QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make(map[string][]byte, len(keys))
+	for rows.Next() {
+		var key string
+		var value []byte
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+	return values, rows.Err()
+}
+
+// Scan narrows the query with LIKE where the prefix contains no characters
+// that would need escaping, as escape syntax differs between drivers. LIKE can
+// also match case insensitively and so the prefix is always checked here.
+func (s *sqlCache) Scan(ctx context.Context, prefix string) ([]string, error) {
+	builder := s.keysSelectBuilder
+	if prefix != "" && !strings.ContainsAny(prefix, `%_\[`) {
+		builder = builder.Where(squirrel.Like{s.keyColumn: prefix + "%"})
+	}
+
+	rows, err := builder.RunWith(s.db).QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, rows.Err()
+}
+
+func (s *sqlCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	res, err := s.updateBuilder.
+		Set(s.valueColumn, value).
+		Where(squirrel.Eq{s.keyColumn: key, s.valueColumn: old}).
+		RunWith(s.db).ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+
+	// Nothing was updated, and so we determine whether the key exists in order
+	// to return the appropriate error. Some drivers only count rows that were
+	// changed, and therefore swapping a value for itself is also checked here.
+	current, err := s.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(current, old) && bytes.Equal(old, value) {
+		return nil
+	}
+	return service.ErrCompareAndSwapFailed
+}
+
 func (s *sqlCache) Delete(ctx context.Context, key string) error {
 	_, err := s.deleteBuilder.Where(squirrel.Eq{s.keyColumn: key}).RunWith(s.db).ExecContext(ctx)
 	if err != nil && errors.Is(err, sql.ErrNoRows) {
@@ -0,0 +1,219 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Jeffail/shutdown"
+	"github.com/Masterminds/squirrel"
+	"github.com/gofrs/uuid"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	lockNameColumnField    = "name_column"
+	lockOwnerColumnField   = "owner_column"
+	lockExpiresColumnField = "expires_column"
+)
+
+func sqlLockConfig() *service.ConfigSpec {
+	spec := service.NewConfigSpec().
+		Beta().
+		Categories("Services").
+		Summary("Uses an SQL database table for storing locks, where each lock held is a row.").
+		Version("1.14.0").
+		Description(`
+Each lock is a row within the specified table holding the name of the lock, a token unique to the Bento instance holding it and the time at which the lease expires as a Unix timestamp in milliseconds. The table must exist beforehand with a unique constraint on the name column, for example:
+
+` + "```sql" + `
+CREATE TABLE bento_locks (
+  name VARCHAR(255) PRIMARY KEY,
+  owner VARCHAR(255) NOT NULL,
+  expires_at BIGINT NOT NULL
+);
+` + "```" + `
+
+A lock is acquired by updating its row when either the lease is held by this instance or has expired, and by inserting the row when it does not yet exist. Lease expiry is calculated with the clock of each Bento instance, and therefore the clocks of instances sharing locks must be reasonably synchronised.`).
+		Field(driverField).
+		Field(dsnField).
+		Field(service.NewStringField("table").
+			Description("The table to store locks within.").
+			Example("bento_locks")).
+		Field(service.NewStringField(lockNameColumnField).
+			Description("The name of a column to be used for storing lock names, which must have a unique constraint.").
+			Default("name")).
+		Field(service.NewStringField(lockOwnerColumnField).
+			Description("The name of a column to be used for storing the token of the instance holding a lock.").
+			Default("owner")).
+		Field(service.NewStringField(lockExpiresColumnField).
+			Description("The name of a column to be used for storing the expiry of a lease as a Unix timestamp in milliseconds. This column should support 64-bit integers.").
+			Default("expires_at")).
+		LintRule(SQLConnLintRule)
+
+	for _, f := range connFields() {
+		spec = spec.Field(f)
+	}
+	return spec
+}
+
+func init() {
+	err := service.RegisterLock("sql", sqlLockConfig(), func(conf *service.ParsedConfig, mgr *service.Resources) (service.Lock, error) {
+		return newSQLLockFromConfig(conf, mgr)
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type sqlLock struct {
+	db    *sql.DB
+	token string
+
+	nameColumn    string
+	ownerColumn   string
+	expiresColumn string
+
+	selectBuilder squirrel.SelectBuilder
+	updateBuilder squirrel.UpdateBuilder
+	insertBuilder squirrel.InsertBuilder
+	deleteBuilder squirrel.DeleteBuilder
+
+	shutSig *shutdown.Signaller
+}
+
+func newSQLLockFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*sqlLock, error) {
+	s := &sqlLock{
+		shutSig: shutdown.NewSignaller(),
+	}
+
+	driver, err := conf.FieldString("driver")
+	if err != nil {
+		return nil, err
+	}
+
+	dsn, err := conf.FieldString("dsn")
+	if err != nil {
+		return nil, err
+	}
+
+	tableStr, err := conf.FieldString("table")
+	if err != nil {
+		return nil, err
+	}
+
+	if s.nameColumn, err = conf.FieldString(lockNameColumnField); err != nil {
+		return nil, err
+	}
+	if s.ownerColumn, err = conf.FieldString(lockOwnerColumnField); err != nil {
+		return nil, err
+	}
+	if s.expiresColumn, err = conf.FieldString(lockExpiresColumnField); err != nil {
+		return nil, err
+	}
+
+	u4, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+	s.token = u4.String()
+
+	s.selectBuilder = squirrel.Select(s.ownerColumn).From(tableStr)
+	s.updateBuilder = squirrel.Update(tableStr)
+	s.insertBuilder = squirrel.Insert(tableStr).Columns(s.nameColumn, s.ownerColumn, s.expiresColumn)
+	s.deleteBuilder = squirrel.Delete(tableStr)
+
+	switch driver {
+	case "postgres", "clickhouse":
+		s.selectBuilder = s.selectBuilder.PlaceholderFormat(squirrel.Dollar)
+		s.updateBuilder = s.updateBuilder.PlaceholderFormat(squirrel.Dollar)
+		s.insertBuilder = s.insertBuilder.PlaceholderFormat(squirrel.Dollar)
+		s.deleteBuilder = s.deleteBuilder.PlaceholderFormat(squirrel.Dollar)
+	case "oracle", "gocosmos":
+		s.selectBuilder = s.selectBuilder.PlaceholderFormat(squirrel.Colon)
+		s.updateBuilder = s.updateBuilder.PlaceholderFormat(squirrel.Colon)
+		s.insertBuilder = s.insertBuilder.PlaceholderFormat(squirrel.Colon)
+		s.deleteBuilder = s.deleteBuilder.PlaceholderFormat(squirrel.Colon)
+	}
+
+	connSettings, err := connSettingsFromParsed(conf, mgr)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.db, err = sqlOpenWithReworks(context.Background(), mgr.Logger(), driver, dsn, connSettings); err != nil {
+		return nil, err
+	}
+	connSettings.apply(context.Background(), s.db, mgr.Logger())
+
+	go func() {
+		<-s.shutSig.HardStopChan()
+		_ = s.db.Close()
+		s.shutSig.TriggerHasStopped()
+	}()
+	return s, nil
+}
+
+func (s *sqlLock) TryAcquire(ctx context.Context, name string, lease time.Duration) (bool, error) {
+	now := time.Now()
+	expires := now.Add(lease).UnixMilli()
+
+	res, err := s.updateBuilder.
+		Set(s.ownerColumn, s.token).
+		Set(s.expiresColumn, expires).
+		Where(squirrel.Eq{s.nameColumn: name}).
+		Where(squirrel.Or{
+			squirrel.Eq{s.ownerColumn: s.token},
+			squirrel.Lt{s.expiresColumn: now.UnixMilli()},
+		}).
+		RunWith(s.db).ExecContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return false, err
+	} else if n > 0 {
+		return true, nil
+	}
+
+	_, insertErr := s.insertBuilder.Values(name, s.token, expires).RunWith(s.db).ExecContext(ctx)
+	if insertErr == nil {
+		return true, nil
+	}
+
+	// Rather than attempting to interpret the insert error of each SQL engine
+	// we determine whether it was a collision by checking for an existing row.
+	var owner string
+	err = s.selectBuilder.
+		Where(squirrel.Eq{s.nameColumn: name}).
+		RunWith(s.db).QueryRowContext(ctx).
+		Scan(&owner)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, insertErr
+		}
+		return false, err
+	}
+	return false, nil
+}
+
+func (s *sqlLock) Release(ctx context.Context, name string) error {
+	_, err := s.deleteBuilder.
+		Where(squirrel.Eq{s.nameColumn: name, s.ownerColumn: s.token}).
+		RunWith(s.db).ExecContext(ctx)
+	return err
+}
+
+func (s *sqlLock) Close(ctx context.Context) error {
+	s.shutSig.TriggerHardStop()
+	select {
+	case <-s.shutSig.HasStoppedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+func TestSQLLockSQLite(t *testing.T) {
+	ctx := context.Background()
+	dsn := fmt.Sprintf("file:%v/locks.db", t.TempDir())
+
+	newLock := func() *sqlLock {
+		conf, err := sqlLockConfig().ParseYAML(fmt.Sprintf(`
+driver: sqlite
+dsn: %v
+table: bento_locks
+init_statement: |
+  CREATE TABLE IF NOT EXISTS bento_locks (
+    name VARCHAR(255) PRIMARY KEY,
+    owner VARCHAR(255) NOT NULL,
+    expires_at BIGINT NOT NULL
+  );
+`, dsn), nil)
+		require.NoError(t, err)
+
+		l, err := newSQLLockFromConfig(conf, service.MockResources())
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = l.Close(ctx)
+		})
+		return l
+	}
+
+	lockA, lockB := newLock(), newLock()
+
+	held, err := lockA.TryAcquire(ctx, "foo", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	held, err = lockB.TryAcquire(ctx, "foo", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	held, err = lockA.TryAcquire(ctx, "foo", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, held, "renewal")
+
+	held, err = lockB.TryAcquire(ctx, "bar", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, held, "different name")
+
+	require.NoError(t, lockB.Release(ctx, "foo"))
+	held, err = lockB.TryAcquire(ctx, "foo", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, held, "release by a different instance")
+
+	require.NoError(t, lockA.Release(ctx, "foo"))
+	held, err = lockB.TryAcquire(ctx, "foo", time.Millisecond*50)
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	time.Sleep(time.Millisecond * 100)
+
+	held, err = lockA.TryAcquire(ctx, "foo", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, held, "lease expiry")
+}
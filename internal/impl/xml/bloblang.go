@@ -1,9 +1,13 @@
 package xml
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
+	"sync"
 
+	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
 	"github.com/clbanning/mxj/v2"
 
 	"github.com/warpstreamlabs/bento/public/bloblang"
@@ -121,4 +125,99 @@ Serializes a target value into an XML byte array.
 		}); err != nil {
 		panic(err)
 	}
+
+	if err := bloblang.RegisterMethodV2("xml_xpath",
+		bloblang.NewPluginSpec().
+			Category("Parsing").
+			Description("Parses an XML document and evaluates an [XPath](https://developer.mozilla.org/en-US/docs/Web/XPath) expression against it. When the expression selects nodes an array is returned containing a value for each node in document order, where selected attributes result in their value. Otherwise, the result of the expression is returned as a string, number or boolean.").
+			Param(bloblang.NewStringParam("expression").Description("The XPath expression to evaluate.")).
+			Param(bloblang.NewStringParam("output").Description("The value to return for each selected node. One of `text` for the text content, `inner_xml` for the XML of the contents, `outer_xml` for the XML of the node including its tags, or `attributes` for an object of the attributes.").Default("text")).
+			Example("", `root.titles = this.doc.xml_xpath("//book[@lang='en']/title")
+root.ids = this.doc.xml_xpath("//book/@id")
+root.total = this.doc.xml_xpath("sum(//book/price)")`, [2]string{
+				`{"doc":"<library><book id=\"1\" lang=\"en\"><title>Foo</title><price>10</price></book><book id=\"2\" lang=\"de\"><title>Bar</title><price>5.5</price></book></library>"}`,
+				`{"ids":["1","2"],"titles":["Foo"],"total":15.5}`,
+			}).
+			Example("", `root.books = this.doc.xml_xpath("//book", "outer_xml")`, [2]string{
+				`{"doc":"<library><book><title>Foo</title></book></library>"}`,
+				`{"books":["<book><title>Foo</title></book>"]}`,
+			}),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			exprStr, err := args.GetString("expression")
+			if err != nil {
+				return nil, err
+			}
+			expr, err := xpath.Compile(exprStr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compile XPath expression: %w", err)
+			}
+			output, err := args.GetString("output")
+			if err != nil {
+				return nil, err
+			}
+			outputFn, err := xmlNodeOutputFn(output)
+			if err != nil {
+				return nil, err
+			}
+
+			// Evaluating an expression modifies its state.
+			var exprMut sync.Mutex
+			return bloblang.BytesMethod(func(b []byte) (any, error) {
+				doc, err := xmlquery.Parse(bytes.NewReader(b))
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse value as XML: %w", err)
+				}
+
+				exprMut.Lock()
+				v := expr.Evaluate(xmlquery.CreateXPathNavigator(doc))
+				exprMut.Unlock()
+
+				iter, ok := v.(*xpath.NodeIterator)
+				if !ok {
+					return v, nil
+				}
+				res := []any{}
+				for iter.MoveNext() {
+					nav := iter.Current().(*xmlquery.NodeNavigator)
+					if nav.NodeType() == xpath.AttributeNode {
+						res = append(res, nav.Value())
+						continue
+					}
+					res = append(res, outputFn(nav.Current()))
+				}
+				return res, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+}
+
+func xmlNodeOutputFn(output string) (func(n *xmlquery.Node) any, error) {
+	switch output {
+	case "text":
+		return func(n *xmlquery.Node) any {
+			return n.InnerText()
+		}, nil
+	case "inner_xml":
+		return func(n *xmlquery.Node) any {
+			return n.OutputXML(false)
+		}, nil
+	case "outer_xml":
+		return func(n *xmlquery.Node) any {
+			return n.OutputXML(true)
+		}, nil
+	case "attributes":
+		return func(n *xmlquery.Node) any {
+			attrs := make(map[string]any, len(n.Attr))
+			for _, a := range n.Attr {
+				key := a.Name.Local
+				if a.Name.Space != "" {
+					key = a.Name.Space + ":" + key
+				}
+				attrs[key] = a.Value
+			}
+			return attrs
+		}, nil
+	}
+	return nil, fmt.Errorf("output '%v' not recognised", output)
 }
@@ -53,3 +53,74 @@ func TestParseXML(t *testing.T) {
 		})
 	}
 }
+
+func TestXMLXPath(t *testing.T) {
+	doc := `<?xml version="1.0"?>
+<library xmlns:x="http://example.com/x">
+  <book id="1" lang="en"><title>Foo</title><price>10</price></book>
+  <book id="2" lang="de" x:extra="yes"><title>Bar</title><price>5.5</price></book>
+</library>`
+
+	testCases := []struct {
+		name    string
+		mapping string
+		exp     any
+	}{
+		{
+			name:    "text of nodes",
+			mapping: `root = this.xml_xpath("//book/title")`,
+			exp:     []any{"Foo", "Bar"},
+		},
+		{
+			name:    "attribute values",
+			mapping: `root = this.xml_xpath("//book[price > 6]/@id")`,
+			exp:     []any{"1"},
+		},
+		{
+			name:    "attributes object",
+			mapping: `root = this.xml_xpath("//book[@id='2']", "attributes")`,
+			exp:     []any{map[string]any{"id": "2", "lang": "de", "x:extra": "yes"}},
+		},
+		{
+			name:    "inner xml",
+			mapping: `root = this.xml_xpath("//book[@id='1']", "inner_xml")`,
+			exp:     []any{"<title>Foo</title><price>10</price>"},
+		},
+		{
+			name:    "scalar results",
+			mapping: `root = [ this.xml_xpath("count(//book)"), this.xml_xpath("string(//book[2]/title)"), this.xml_xpath("boolean(//magazine)") ]`,
+			exp:     []any{2.0, "Bar", false},
+		},
+		{
+			name:    "no matches",
+			mapping: `root = this.xml_xpath("//magazine")`,
+			exp:     []any{},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			require.NoError(t, err)
+
+			res, err := exec.Query(doc)
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, res)
+		})
+	}
+}
+
+func TestXMLXPathErrors(t *testing.T) {
+	_, err := bloblang.Parse(`root = this.xml_xpath("//book[")`)
+	require.Error(t, err)
+
+	_, err = bloblang.Parse(`root = this.xml_xpath("//book", "inner_html")`)
+	require.Error(t, err)
+
+	exec, err := bloblang.Parse(`root = this.xml_xpath("//book")`)
+	require.NoError(t, err)
+
+	_, err = exec.Query(`<library><book></library>`)
+	require.Error(t, err)
+}
@@ -0,0 +1,132 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// JSON-RPC error codes used by the server.
+const (
+	codeParseError           = -32700
+	codeMethodNotFound       = -32601
+	codeInvalidParams        = -32602
+	codeInternalError        = -32603
+	codeServerNotInitialized = -32002
+)
+
+// ResponseError is a JSON-RPC error returned to the client.
+type ResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (r *ResponseError) Error() string {
+	return fmt.Sprintf("%v (code %v)", r.Message, r.Code)
+}
+
+type inMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+func (m *inMessage) isNotification() bool {
+	return len(m.ID) == 0
+}
+
+type outResult struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result"`
+}
+
+type outError struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   *ResponseError  `json:"error"`
+}
+
+type outNotification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+// conn reads and writes JSON-RPC messages framed with the Content-Length
+// headers of the base protocol.
+type conn struct {
+	r *textproto.Reader
+
+	writeMut sync.Mutex
+	w        io.Writer
+}
+
+func newConn(r io.Reader, w io.Writer) *conn {
+	return &conn{
+		r: textproto.NewReader(bufio.NewReader(r)),
+		w: w,
+	}
+}
+
+func (c *conn) read() ([]byte, error) {
+	header, err := c.r.ReadMIMEHeader()
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(header) == 0 {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to read message header: %w", err)
+	}
+
+	lengthStr := strings.TrimSpace(header.Get("Content-Length"))
+	if lengthStr == "" {
+		return nil, errors.New("message header is missing Content-Length")
+	}
+	length, err := strconv.Atoi(lengthStr)
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length: %v", lengthStr)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.r.R, body); err != nil {
+		return nil, fmt.Errorf("failed to read message body: %w", err)
+	}
+	return body, nil
+}
+
+func (c *conn) write(v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	c.writeMut.Lock()
+	defer c.writeMut.Unlock()
+
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = c.w.Write(body)
+	return err
+}
+
+func (c *conn) reply(id json.RawMessage, result any) error {
+	return c.write(outResult{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (c *conn) replyErr(id json.RawMessage, rErr *ResponseError) error {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return c.write(outError{JSONRPC: "2.0", ID: id, Error: rErr})
+}
+
+func (c *conn) notify(method string, params any) error {
+	return c.write(outNotification{JSONRPC: "2.0", Method: method, Params: params})
+}
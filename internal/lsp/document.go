@@ -0,0 +1,79 @@
+package lsp
+
+import (
+	"unicode/utf16"
+)
+
+// Document is a text document that is open within the client editor. The
+// server keeps documents in sync with full content updates.
+type Document struct {
+	URI        string
+	LanguageID string
+	Version    int
+	Text       string
+}
+
+// Path returns the local file path of the document, or the raw URI when it
+// isn't a file.
+func (d *Document) Path() string {
+	return URIToPath(d.URI)
+}
+
+// Runes returns the document text as a slice of runes, which is the form
+// consumed by the Bloblang parser.
+func (d *Document) Runes() []rune {
+	return []rune(d.Text)
+}
+
+// Offset converts a protocol position into a rune offset of the document
+// text. Positions beyond the end of a line are clamped to the line end.
+func (d *Document) Offset(pos Position) int {
+	return OffsetOf([]rune(d.Text), pos)
+}
+
+// Position converts a rune offset of the document text into a protocol
+// position.
+func (d *Document) Position(offset int) Position {
+	return PositionOf([]rune(d.Text), offset)
+}
+
+// OffsetOf converts a protocol position into a rune offset of the provided
+// text. Positions beyond the end of a line are clamped to the line end.
+func OffsetOf(text []rune, pos Position) int {
+	line, col := 0, 0
+	for i, r := range text {
+		if line == pos.Line && col >= pos.Character {
+			return i
+		}
+		if r == '\n' {
+			if line == pos.Line {
+				return i
+			}
+			line++
+			col = 0
+			continue
+		}
+		if line == pos.Line {
+			col += utf16.RuneLen(r)
+		}
+	}
+	return len(text)
+}
+
+// PositionOf converts a rune offset of the provided text into a protocol
+// position.
+func PositionOf(text []rune, offset int) Position {
+	if offset > len(text) {
+		offset = len(text)
+	}
+	var pos Position
+	for _, r := range text[:offset] {
+		if r == '\n' {
+			pos.Line++
+			pos.Character = 0
+			continue
+		}
+		pos.Character += utf16.RuneLen(r)
+	}
+	return pos
+}
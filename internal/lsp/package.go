@@ -0,0 +1,5 @@
+// Package lsp implements the transport and lifecycle of the Language Server
+// Protocol, allowing language specific handlers (Bloblang mappings, Bento
+// configs) to provide diagnostics, completion, hover docs and go-to-definition
+// to editors.
+package lsp
@@ -0,0 +1,124 @@
+package lsp
+
+import (
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Position in a text document expressed as a zero-based line and a zero-based
+// character offset counted in UTF-16 code units, as required by the protocol.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range within a text document, the end position is exclusive.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location represents a range inside a resource, such as a file.
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// DiagnosticSeverity describes the severity of a diagnostic.
+type DiagnosticSeverity int
+
+// Diagnostic severities.
+const (
+	SeverityError       DiagnosticSeverity = 1
+	SeverityWarning     DiagnosticSeverity = 2
+	SeverityInformation DiagnosticSeverity = 3
+	SeverityHint        DiagnosticSeverity = 4
+)
+
+// Diagnostic represents a compiler error, linting error or warning within a
+// document.
+type Diagnostic struct {
+	Range    Range              `json:"range"`
+	Severity DiagnosticSeverity `json:"severity,omitempty"`
+	Source   string             `json:"source,omitempty"`
+	Message  string             `json:"message"`
+}
+
+// MarkupKind describes the content type of a MarkupContent.
+type MarkupKind string
+
+// Supported markup kinds.
+const (
+	MarkupPlainText MarkupKind = "plaintext"
+	MarkupMarkdown  MarkupKind = "markdown"
+)
+
+// MarkupContent is a string of text formatted with the given kind.
+type MarkupContent struct {
+	Kind  MarkupKind `json:"kind"`
+	Value string     `json:"value"`
+}
+
+// CompletionItemKind describes the kind of a completion entry, which editors
+// use in order to select an icon.
+type CompletionItemKind int
+
+// Completion item kinds used by Bento language servers.
+const (
+	CompletionKindText     CompletionItemKind = 1
+	CompletionKindMethod   CompletionItemKind = 2
+	CompletionKindFunction CompletionItemKind = 3
+	CompletionKindField    CompletionItemKind = 5
+	CompletionKindVariable CompletionItemKind = 6
+	CompletionKindModule   CompletionItemKind = 9
+	CompletionKindProperty CompletionItemKind = 10
+	CompletionKindValue    CompletionItemKind = 12
+	CompletionKindKeyword  CompletionItemKind = 14
+	CompletionKindSnippet  CompletionItemKind = 15
+)
+
+// CompletionItem is a single completion suggestion.
+type CompletionItem struct {
+	Label         string             `json:"label"`
+	Kind          CompletionItemKind `json:"kind,omitempty"`
+	Detail        string             `json:"detail,omitempty"`
+	Documentation *MarkupContent     `json:"documentation,omitempty"`
+	InsertText    string             `json:"insertText,omitempty"`
+	Deprecated    bool               `json:"deprecated,omitempty"`
+}
+
+// Hover is the result of a hover request.
+type Hover struct {
+	Contents MarkupContent `json:"contents"`
+	Range    *Range        `json:"range,omitempty"`
+}
+
+//------------------------------------------------------------------------------
+
+// URIToPath converts a file URI into a local file path. Non-file URIs are
+// returned unchanged.
+func URIToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	p := u.Path
+	if runtime.GOOS == "windows" {
+		p = strings.TrimPrefix(p, "/")
+	}
+	return filepath.FromSlash(p)
+}
+
+// PathToURI converts a local file path into a file URI.
+func PathToURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
@@ -0,0 +1,364 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Handler implements the language specific features of a language server. The
+// server takes care of the protocol lifecycle and document synchronisation and
+// calls into the handler with the current state of a document.
+type Handler interface {
+	// Diagnostics returns the problems found within a document, it is called
+	// each time a document is opened or changed.
+	Diagnostics(doc *Document) []Diagnostic
+
+	// Completion returns completion candidates at a position of a document.
+	Completion(doc *Document, pos Position) []CompletionItem
+
+	// Hover returns documentation for the symbol at a position of a document,
+	// or nil if there is nothing to show.
+	Hover(doc *Document, pos Position) *Hover
+
+	// Definition returns the locations where the symbol at a position of a
+	// document is defined.
+	Definition(doc *Document, pos Position) []Location
+}
+
+// Server is a language server that communicates with a client over a pair of
+// streams using the JSON-RPC base protocol, usually stdin and stdout.
+type Server struct {
+	name     string
+	version  string
+	handler  Handler
+	triggers []string
+
+	docsMut sync.Mutex
+	docs    map[string]*Document
+
+	initialized bool
+	shutdown    bool
+}
+
+// NewServer creates a language server with a name and version that are
+// reported to clients, and a handler that implements language features.
+func NewServer(name, version string, handler Handler) *Server {
+	return &Server{
+		name:    name,
+		version: version,
+		handler: handler,
+		docs:    map[string]*Document{},
+	}
+}
+
+// WithCompletionTriggers sets characters that, when typed, cause the client to
+// request completions automatically.
+func (s *Server) WithCompletionTriggers(chars ...string) *Server {
+	s.triggers = chars
+	return s
+}
+
+// Document returns the current state of an open document by its URI.
+func (s *Server) Document(uri string) (*Document, bool) {
+	s.docsMut.Lock()
+	defer s.docsMut.Unlock()
+	d, exists := s.docs[uri]
+	if !exists {
+		return nil, false
+	}
+	dCopy := *d
+	return &dCopy, true
+}
+
+// Serve reads requests from r and writes responses to w until the client
+// sends an exit notification, the input is closed or the context is cancelled.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	c := newConn(r, w)
+
+	type readResult struct {
+		body []byte
+		err  error
+	}
+	reads := make(chan readResult)
+	go func() {
+		for {
+			body, err := c.read()
+			select {
+			case reads <- readResult{body, err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		var res readResult
+		select {
+		case res = <-reads:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if res.err != nil {
+			if errors.Is(res.err, io.EOF) {
+				return nil
+			}
+			return res.err
+		}
+
+		var msg inMessage
+		if err := json.Unmarshal(res.body, &msg); err != nil {
+			if err := c.replyErr(nil, &ResponseError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("exit received before shutdown")
+			}
+			return nil
+		}
+
+		result, rErr := s.dispatch(c, &msg)
+		if msg.isNotification() {
+			continue
+		}
+		var err error
+		if rErr != nil {
+			err = c.replyErr(msg.ID, rErr)
+		} else {
+			err = c.reply(msg.ID, result)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+	}
+}
+
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type textDocumentPositionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Version     int          `json:"version,omitempty"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+func decodeParams(raw json.RawMessage, v any) *ResponseError {
+	if err := json.Unmarshal(raw, v); err != nil {
+		return &ResponseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) dispatch(c *conn, msg *inMessage) (any, *ResponseError) {
+	if !s.initialized && msg.Method != "initialize" {
+		if msg.isNotification() {
+			return nil, nil
+		}
+		return nil, &ResponseError{Code: codeServerNotInitialized, Message: "server not initialized"}
+	}
+
+	switch msg.Method {
+	case "initialize":
+		s.initialized = true
+		return s.capabilities(), nil
+
+	case "initialized", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+		return nil, nil
+
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params struct {
+			TextDocument struct {
+				URI        string `json:"uri"`
+				LanguageID string `json:"languageId"`
+				Version    int    `json:"version"`
+				Text       string `json:"text"`
+			} `json:"textDocument"`
+		}
+		if rErr := decodeParams(msg.Params, &params); rErr != nil {
+			return nil, rErr
+		}
+		doc := &Document{
+			URI:        params.TextDocument.URI,
+			LanguageID: params.TextDocument.LanguageID,
+			Version:    params.TextDocument.Version,
+			Text:       params.TextDocument.Text,
+		}
+		s.docsMut.Lock()
+		s.docs[doc.URI] = doc
+		s.docsMut.Unlock()
+		return nil, s.publishDiagnostics(c, doc)
+
+	case "textDocument/didChange":
+		var params struct {
+			TextDocument struct {
+				URI     string `json:"uri"`
+				Version int    `json:"version"`
+			} `json:"textDocument"`
+			ContentChanges []struct {
+				Range *Range `json:"range"`
+				Text  string `json:"text"`
+			} `json:"contentChanges"`
+		}
+		if rErr := decodeParams(msg.Params, &params); rErr != nil {
+			return nil, rErr
+		}
+		s.docsMut.Lock()
+		doc, exists := s.docs[params.TextDocument.URI]
+		if !exists {
+			doc = &Document{URI: params.TextDocument.URI}
+			s.docs[doc.URI] = doc
+		}
+		for _, change := range params.ContentChanges {
+			if change.Range == nil {
+				doc.Text = change.Text
+				continue
+			}
+			text := doc.Runes()
+			start, end := OffsetOf(text, change.Range.Start), OffsetOf(text, change.Range.End)
+			doc.Text = string(text[:start]) + change.Text + string(text[end:])
+		}
+		doc.Version = params.TextDocument.Version
+		dCopy := *doc
+		s.docsMut.Unlock()
+		return nil, s.publishDiagnostics(c, &dCopy)
+
+	case "textDocument/didClose":
+		var params struct {
+			TextDocument textDocumentIdentifier `json:"textDocument"`
+		}
+		if rErr := decodeParams(msg.Params, &params); rErr != nil {
+			return nil, rErr
+		}
+		s.docsMut.Lock()
+		delete(s.docs, params.TextDocument.URI)
+		s.docsMut.Unlock()
+		if err := c.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		}); err != nil {
+			return nil, &ResponseError{Code: codeInternalError, Message: err.Error()}
+		}
+		return nil, nil
+
+	case "textDocument/didSave":
+		return nil, nil
+
+	case "textDocument/completion":
+		doc, pos, rErr := s.positionParams(msg.Params)
+		if rErr != nil || doc == nil {
+			return nil, rErr
+		}
+		items := s.handler.Completion(doc, pos)
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].Label < items[j].Label
+		})
+		if items == nil {
+			items = []CompletionItem{}
+		}
+		return items, nil
+
+	case "textDocument/hover":
+		doc, pos, rErr := s.positionParams(msg.Params)
+		if rErr != nil || doc == nil {
+			return nil, rErr
+		}
+		if h := s.handler.Hover(doc, pos); h != nil {
+			return h, nil
+		}
+		return nil, nil
+
+	case "textDocument/definition":
+		doc, pos, rErr := s.positionParams(msg.Params)
+		if rErr != nil || doc == nil {
+			return nil, rErr
+		}
+		if locs := s.handler.Definition(doc, pos); len(locs) > 0 {
+			return locs, nil
+		}
+		return nil, nil
+	}
+
+	if msg.isNotification() {
+		return nil, nil
+	}
+	return nil, &ResponseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not supported: %v", msg.Method)}
+}
+
+type completionOptions struct {
+	TriggerCharacters []string `json:"triggerCharacters,omitempty"`
+}
+
+type serverCapabilities struct {
+	TextDocumentSync   int               `json:"textDocumentSync"`
+	CompletionProvider completionOptions `json:"completionProvider"`
+	HoverProvider      bool              `json:"hoverProvider"`
+	DefinitionProvider bool              `json:"definitionProvider"`
+}
+
+type serverInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type initializeResult struct {
+	Capabilities serverCapabilities `json:"capabilities"`
+	ServerInfo   serverInfo         `json:"serverInfo"`
+}
+
+func (s *Server) capabilities() initializeResult {
+	return initializeResult{
+		Capabilities: serverCapabilities{
+			// Incremental sync, changes are applied to documents by the server.
+			TextDocumentSync:   2,
+			CompletionProvider: completionOptions{TriggerCharacters: s.triggers},
+			HoverProvider:      true,
+			DefinitionProvider: true,
+		},
+		ServerInfo: serverInfo{Name: s.name, Version: s.version},
+	}
+}
+
+func (s *Server) positionParams(raw json.RawMessage) (*Document, Position, *ResponseError) {
+	var params textDocumentPositionParams
+	if rErr := decodeParams(raw, &params); rErr != nil {
+		return nil, Position{}, rErr
+	}
+	doc, _ := s.Document(params.TextDocument.URI)
+	return doc, params.Position, nil
+}
+
+func (s *Server) publishDiagnostics(c *conn, doc *Document) *ResponseError {
+	diags := s.handler.Diagnostics(doc)
+	if diags == nil {
+		diags = []Diagnostic{}
+	}
+	if err := c.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         doc.URI,
+		Version:     doc.Version,
+		Diagnostics: diags,
+	}); err != nil {
+		return &ResponseError{Code: codeInternalError, Message: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockHandler struct{}
+
+func (mockHandler) Diagnostics(doc *Document) []Diagnostic {
+	idx := strings.Index(doc.Text, "bad")
+	if idx == -1 {
+		return nil
+	}
+	return []Diagnostic{{
+		Range:    Range{Start: doc.Position(idx), End: doc.Position(idx + 3)},
+		Severity: SeverityError,
+		Message:  "found bad",
+	}}
+}
+
+func (mockHandler) Completion(doc *Document, pos Position) []CompletionItem {
+	return []CompletionItem{{Label: "foo"}, {Label: "bar"}}
+}
+
+func (mockHandler) Hover(doc *Document, pos Position) *Hover {
+	if pos.Line > 0 {
+		return nil
+	}
+	return &Hover{Contents: MarkupContent{Kind: MarkupPlainText, Value: doc.Text}}
+}
+
+func (mockHandler) Definition(doc *Document, pos Position) []Location {
+	return []Location{{URI: doc.URI, Range: Range{Start: pos, End: pos}}}
+}
+
+type testClient struct {
+	t     *testing.T
+	w     io.Writer
+	r     *textproto.Reader
+	reqID int
+}
+
+func (c *testClient) send(method string, id *int, params any) {
+	msg := map[string]any{"jsonrpc": "2.0", "method": method, "params": params}
+	if id != nil {
+		msg["id"] = *id
+	}
+	body, err := json.Marshal(msg)
+	require.NoError(c.t, err)
+	_, err = fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	require.NoError(c.t, err)
+}
+
+func (c *testClient) notify(method string, params any) {
+	c.send(method, nil, params)
+}
+
+func (c *testClient) request(method string, params any) {
+	c.reqID++
+	id := c.reqID
+	c.send(method, &id, params)
+}
+
+func (c *testClient) read() map[string]any {
+	header, err := c.r.ReadMIMEHeader()
+	require.NoError(c.t, err)
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	require.NoError(c.t, err)
+	body := make([]byte, length)
+	_, err = io.ReadFull(c.r.R, body)
+	require.NoError(c.t, err)
+
+	var v map[string]any
+	require.NoError(c.t, json.Unmarshal(body, &v))
+	return v
+}
+
+func TestServerLifecycle(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+
+	s := NewServer("test", "1.0.0", mockHandler{}).WithCompletionTriggers(".")
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.Serve(context.Background(), inR, outW)
+	}()
+
+	c := &testClient{t: t, w: inW, r: textproto.NewReader(bufio.NewReader(outR))}
+
+	c.request("textDocument/hover", map[string]any{})
+	res := c.read()
+	assert.Equal(t, float64(codeServerNotInitialized), res["error"].(map[string]any)["code"])
+
+	c.request("initialize", map[string]any{})
+	res = c.read()
+	caps := res["result"].(map[string]any)["capabilities"].(map[string]any)
+	assert.Equal(t, true, caps["hoverProvider"])
+	assert.Equal(t, []any{"."}, caps["completionProvider"].(map[string]any)["triggerCharacters"])
+	assert.Equal(t, "test", res["result"].(map[string]any)["serverInfo"].(map[string]any)["name"])
+
+	c.notify("initialized", map[string]any{})
+
+	c.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{
+			"uri": "file:///foo.blobl", "languageId": "bloblang", "version": 1,
+			"text": "root = this\nroot.foo = bad",
+		},
+	})
+	res = c.read()
+	assert.Equal(t, "textDocument/publishDiagnostics", res["method"])
+	diags := res["params"].(map[string]any)["diagnostics"].([]any)
+	require.Len(t, diags, 1)
+	assert.Equal(t, map[string]any{
+		"start": map[string]any{"line": float64(1), "character": float64(11)},
+		"end":   map[string]any{"line": float64(1), "character": float64(14)},
+	}, diags[0].(map[string]any)["range"])
+
+	c.notify("textDocument/didChange", map[string]any{
+		"textDocument": map[string]any{"uri": "file:///foo.blobl", "version": 2},
+		"contentChanges": []any{
+			map[string]any{
+				"range": map[string]any{
+					"start": map[string]any{"line": 1, "character": 11},
+					"end":   map[string]any{"line": 1, "character": 14},
+				},
+				"text": "good",
+			},
+		},
+	})
+	res = c.read()
+	assert.Empty(t, res["params"].(map[string]any)["diagnostics"])
+
+	doc, exists := s.Document("file:///foo.blobl")
+	require.True(t, exists)
+	assert.Equal(t, "root = this\nroot.foo = good", doc.Text)
+	assert.Equal(t, 2, doc.Version)
+
+	pos := map[string]any{
+		"textDocument": map[string]any{"uri": "file:///foo.blobl"},
+		"position":     map[string]any{"line": 0, "character": 2},
+	}
+
+	c.request("textDocument/completion", pos)
+	res = c.read()
+	items := res["result"].([]any)
+	require.Len(t, items, 2)
+	assert.Equal(t, "bar", items[0].(map[string]any)["label"])
+	assert.Equal(t, "foo", items[1].(map[string]any)["label"])
+
+	c.request("textDocument/hover", pos)
+	res = c.read()
+	assert.Equal(t, "root = this\nroot.foo = good", res["result"].(map[string]any)["contents"].(map[string]any)["value"])
+
+	c.request("textDocument/definition", pos)
+	res = c.read()
+	assert.Len(t, res["result"], 1)
+
+	c.request("textDocument/formatting", pos)
+	res = c.read()
+	assert.Equal(t, float64(codeMethodNotFound), res["error"].(map[string]any)["code"])
+
+	c.request("shutdown", nil)
+	res = c.read()
+	assert.Contains(t, res, "result")
+	assert.Nil(t, res["result"])
+
+	c.notify("exit", nil)
+
+	select {
+	case err := <-serveErr:
+		require.NoError(t, err)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for server to exit")
+	}
+}
+
+func TestDocumentPositions(t *testing.T) {
+	text := []rune("foo\nb😀r baz\n\nqux")
+
+	tests := []struct {
+		offset int
+		pos    Position
+	}{
+		{offset: 0, pos: Position{Line: 0, Character: 0}},
+		{offset: 3, pos: Position{Line: 0, Character: 3}},
+		{offset: 4, pos: Position{Line: 1, Character: 0}},
+		{offset: 6, pos: Position{Line: 1, Character: 3}},
+		{offset: 12, pos: Position{Line: 2, Character: 0}},
+		{offset: 13, pos: Position{Line: 3, Character: 0}},
+		{offset: 16, pos: Position{Line: 3, Character: 3}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.pos, PositionOf(text, test.offset), "offset %v", test.offset)
+		assert.Equal(t, test.offset, OffsetOf(text, test.pos), "offset %v", test.offset)
+	}
+
+	// Characters beyond the line end are clamped.
+	assert.Equal(t, 3, OffsetOf(text, Position{Line: 0, Character: 10}))
+}
+
+func TestURIPaths(t *testing.T) {
+	assert.Equal(t, "/foo/bar baz.blobl", URIToPath("file:///foo/bar%20baz.blobl"))
+	assert.Equal(t, "file:///foo/bar%20baz.blobl", PathToURI("/foo/bar baz.blobl"))
+	assert.Equal(t, "untitled:Untitled-1", URIToPath("untitled:Untitled-1"))
+}
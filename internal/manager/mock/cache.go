@@ -2,6 +2,7 @@ package mock
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/warpstreamlabs/bento/internal/component"
@@ -60,6 +61,33 @@ func (c *Cache) Add(ctx context.Context, key string, value []byte, ttl *time.Dur
 	return nil
 }
 
+// CompareAndSwap sets a mock cache item only if it matches an expected value.
+func (c *Cache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	i, ok := c.Values[key]
+	if !ok {
+		return component.ErrKeyNotFound
+	}
+	if i.Value != string(old) {
+		return component.ErrCompareAndSwapFailed
+	}
+	c.Values[key] = CacheItem{
+		Value: string(value),
+		TTL:   ttl,
+	}
+	return nil
+}
+
+// Scan returns the keys of mock cache items that begin with a prefix.
+func (c *Cache) Scan(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for k := range c.Values {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
 // Delete a mock cache item.
 func (c *Cache) Delete(ctx context.Context, key string) error {
 	delete(c.Values, key)
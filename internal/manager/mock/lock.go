@@ -0,0 +1,25 @@
+package mock
+
+import (
+	"context"
+	"time"
+)
+
+// Lock provides a mock lock implementation around a closure that determines
+// whether an acquisition attempt succeeds.
+type Lock func(ctx context.Context, name string, lease time.Duration) (bool, error)
+
+// TryAcquire attempts to acquire the lock.
+func (l Lock) TryAcquire(ctx context.Context, name string, lease time.Duration) (bool, error) {
+	return l(ctx, name, lease)
+}
+
+// Release does nothing.
+func (l Lock) Release(ctx context.Context, name string) error {
+	return nil
+}
+
+// Close does nothing.
+func (l Lock) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,8 @@
+package mock_test
+
+import (
+	"github.com/warpstreamlabs/bento/internal/component/lock"
+	"github.com/warpstreamlabs/bento/internal/manager/mock"
+)
+
+var _ lock.V1 = mock.Lock(nil)
@@ -14,6 +14,7 @@ import (
 	"github.com/warpstreamlabs/bento/internal/component/buffer"
 	"github.com/warpstreamlabs/bento/internal/component/cache"
 	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/component/lock"
 	"github.com/warpstreamlabs/bento/internal/component/metrics"
 	"github.com/warpstreamlabs/bento/internal/component/output"
 	"github.com/warpstreamlabs/bento/internal/component/processor"
@@ -34,6 +35,7 @@ type Manager struct {
 	Inputs     map[string]*Input
 	Caches     map[string]map[string]CacheItem
 	RateLimits map[string]RateLimit
+	Locks      map[string]Lock
 	Outputs    map[string]OutputWriter
 	Processors map[string]Processor
 	Pipes      map[string]<-chan message.Transaction
@@ -58,6 +60,7 @@ func NewManager() *Manager {
 		Inputs:        map[string]*Input{},
 		Caches:        map[string]map[string]CacheItem{},
 		RateLimits:    map[string]RateLimit{},
+		Locks:         map[string]Lock{},
 		Outputs:       map[string]OutputWriter{},
 		Processors:    map[string]Processor{},
 		Pipes:         map[string]<-chan message.Transaction{},
@@ -144,6 +147,16 @@ func (m *Manager) StoreRateLimit(ctx context.Context, name string, conf ratelimi
 	return component.ErrInvalidType("rate_limit", conf.Type)
 }
 
+// NewLock always errors on invalid type.
+func (m *Manager) NewLock(conf lock.Config) (lock.V1, error) {
+	return bundle.AllLocks.Init(conf, m)
+}
+
+// StoreLock always errors on invalid type.
+func (m *Manager) StoreLock(ctx context.Context, name string, conf lock.Config) error {
+	return component.ErrInvalidType("lock", conf.Type)
+}
+
 // NewScanner attempts to create a new scanner component from a config.
 func (m *Manager) NewScanner(conf scanner.Config) (scanner.Creator, error) {
 	return bundle.AllScanners.Init(conf, m)
@@ -257,6 +270,41 @@ func (m *Manager) RemoveRateLimit(ctx context.Context, name string) error {
 	return nil
 }
 
+// ProbeLock returns true if a lock resource exists under the provided name.
+func (m *Manager) ProbeLock(name string) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	_, exists := m.Locks[name]
+	return exists
+}
+
+// AccessLock executes a closure on a lock resource.
+func (m *Manager) AccessLock(ctx context.Context, name string, fn func(lock.V1)) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	l, ok := m.Locks[name]
+	if !ok {
+		return component.ErrLockNotFound
+	}
+	fn(l)
+	return nil
+}
+
+// RemoveLock removes a resource.
+func (m *Manager) RemoveLock(ctx context.Context, name string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	_, exists := m.Locks[name]
+	if !exists {
+		return component.ErrLockNotFound
+	}
+	delete(m.Locks, name)
+	return nil
+}
+
 // ProbeInput returns true if an input resource exists under the provided name.
 func (m *Manager) ProbeInput(name string) bool {
 	m.lock.Lock()
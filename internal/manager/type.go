@@ -17,6 +17,7 @@ import (
 	"github.com/warpstreamlabs/bento/internal/component/buffer"
 	"github.com/warpstreamlabs/bento/internal/component/cache"
 	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/component/lock"
 	"github.com/warpstreamlabs/bento/internal/component/metrics"
 	"github.com/warpstreamlabs/bento/internal/component/output"
 	"github.com/warpstreamlabs/bento/internal/component/processor"
@@ -83,6 +84,7 @@ type Type struct {
 	processors *liveResources[processor.V1]
 	outputs    *liveResources[*outputWrapper]
 	rateLimits *liveResources[ratelimit.V1]
+	locks      *liveResources[lock.V1]
 
 	// Collections of component constructors
 	env      *bundle.Environment
@@ -203,6 +205,7 @@ func New(conf ResourceConfig, opts ...OptFunc) (*Type, error) {
 		processors: newLiveResources[processor.V1](),
 		outputs:    newLiveResources[*outputWrapper](),
 		rateLimits: newLiveResources[ratelimit.V1](),
+		locks:      newLiveResources[lock.V1](),
 
 		// Environment defaults to global (everything that was imported).
 		env:      bundle.GlobalEnvironment,
@@ -273,6 +276,12 @@ func New(conf ResourceConfig, opts ...OptFunc) (*Type, error) {
 		}
 		t.rateLimits.Add(c.Label, nil)
 	}
+	for _, c := range conf.ResourceLocks {
+		if err := checkLabel("lock", c.Label); err != nil {
+			return nil, err
+		}
+		t.locks.Add(c.Label, nil)
+	}
 
 	// Labels validated, begin construction
 	for _, conf := range conf.ResourceRateLimits {
@@ -281,6 +290,12 @@ func New(conf ResourceConfig, opts ...OptFunc) (*Type, error) {
 		}
 	}
 
+	for _, conf := range conf.ResourceLocks {
+		if err := t.StoreLock(context.Background(), conf.Label, conf); err != nil {
+			return nil, err
+		}
+	}
+
 	for _, conf := range conf.ResourceCaches {
 		if err := t.StoreCache(context.Background(), conf.Label, conf); err != nil {
 			return nil, err
@@ -379,6 +394,12 @@ func (t *Type) Label() string {
 	return t.label
 }
 
+// StreamID returns the identifier of the stream that a manager was created for
+// with ForStream, which is empty otherwise.
+func (t *Type) StreamID() string {
+	return t.stream
+}
+
 // WithAddedMetrics returns a modified version of the manager where metrics are
 // registered to both the current metrics target as well as the provided one.
 func (t *Type) WithAddedMetrics(m metrics.Type) bundle.NewManagement {
@@ -915,6 +936,83 @@ func (t *Type) RemoveRateLimit(ctx context.Context, name string) error {
 
 //------------------------------------------------------------------------------
 
+// ProbeLock returns true if a lock resource exists under the
+// provided name.
+func (t *Type) ProbeLock(name string) bool {
+	return t.locks.Probe(name)
+}
+
+// AccessLock attempts to access a lock resource by a unique
+// identifier and executes a closure function with the lock as an
+// argument. Returns an error if the lock does not exist (or is otherwise
+// inaccessible).
+//
+// During the execution of the provided closure it is guaranteed that the
+// resource will not be closed or removed. However, it is possible for the
+// resource to be accessed by any number of components in parallel.
+func (t *Type) AccessLock(ctx context.Context, name string, fn func(lock.V1)) (err error) {
+	if rerr := t.locks.RAccess(name, func(t lock.V1) {
+		if t == nil {
+			err = ErrResourceNotFound(name)
+			return
+		}
+		fn(t)
+	}); rerr != nil {
+		err = rerr
+	}
+	return
+}
+
+// NewLock attempts to create a new lock component from a config.
+func (t *Type) NewLock(conf lock.Config) (lock.V1, error) {
+	return t.env.LockInit(conf, t.forLabel(conf.Label))
+}
+
+// StoreLock attempts to store a new lock resource. If an existing
+// resource has the same name it is closed and removed _before_ the new one is
+// initialized in order to avoid duplicate connections.
+func (t *Type) StoreLock(ctx context.Context, name string, conf lock.Config) error {
+	var initErr error
+	if err := t.locks.Access(name, true, func(r *lock.V1, set func(*lock.V1)) {
+		if r != nil {
+			// If a previous resource exists with the same name then we do NOT allow
+			// it to be replaced unless it can be successfully closed. This ensures
+			// that we do not leak connections.
+			if initErr = (*r).Close(ctx); initErr != nil {
+				return
+			}
+		}
+
+		var newL lock.V1
+		if newL, initErr = t.intoPath("lock_resources").NewLock(conf); initErr != nil {
+			return
+		}
+		set(&newL)
+	}); err != nil {
+		return err
+	}
+	return initErr
+}
+
+// RemoveLock attempts to close and remove an existing lock resource.
+func (t *Type) RemoveLock(ctx context.Context, name string) error {
+	var closeErr error
+	if err := t.locks.Access(name, false, func(r *lock.V1, set func(r *lock.V1)) {
+		if r == nil {
+			return
+		}
+		if closeErr = (*r).Close(ctx); closeErr != nil {
+			return
+		}
+		set(nil)
+	}); err != nil {
+		return err
+	}
+	return closeErr
+}
+
+//------------------------------------------------------------------------------
+
 // NewScanner attempts to create a new scanner component from a config.
 func (t *Type) NewScanner(conf scanner.Config) (scanner.Creator, error) {
 	return t.env.ScannerInit(conf, t)
@@ -1023,6 +1121,19 @@ func (t *Type) WaitForClose(ctx context.Context) error {
 		return err
 	}
 
+	if err := t.locks.Walk(func(name string, l *lock.V1, set func(l *lock.V1)) error {
+		if l == nil {
+			return nil
+		}
+		if err := (*l).Close(ctx); err != nil {
+			return fmt.Errorf("resource '%s' failed to cleanly shutdown: %v", name, err)
+		}
+		set(nil)
+		return nil
+	}); err != nil {
+		return err
+	}
+
 	if err := t.outputs.Walk(func(name string, o **outputWrapper, set func(o **outputWrapper)) error {
 		if o == nil {
 			return nil
@@ -14,6 +14,7 @@ import (
 	"github.com/gorilla/mux"
 	"gopkg.in/yaml.v3"
 
+	"github.com/warpstreamlabs/bento/internal/bundle/tracing"
 	"github.com/warpstreamlabs/bento/internal/component/cache"
 	"github.com/warpstreamlabs/bento/internal/component/input"
 	"github.com/warpstreamlabs/bento/internal/component/output"
@@ -45,6 +46,13 @@ func (m *Type) registerEndpoints(enableCrud bool) {
 		"GET a structured JSON object containing metrics for the stream.",
 		m.HandleStreamStats,
 	)
+	if m.tap != nil {
+		m.manager.RegisterEndpoint(
+			"/streams/{id}/tap",
+			fmt.Sprintf(tracing.TapEndpointDescription, "the stream"),
+			m.HandleStreamTap,
+		)
+	}
 	m.manager.RegisterEndpoint(
 		"/streams/{id}",
 		"Perform CRUD operations on streams, supporting POST (Create),"+
@@ -653,6 +661,32 @@ func (m *Type) HandleStreamStats(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// HandleStreamTap is an http.HandleFunc for streaming the events of messages
+// passing through the components of a stream.
+func (m *Type) HandleStreamTap(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method != "GET" {
+		http.Error(w, fmt.Sprintf("Error: verb not supported: %v", r.Method), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := m.Read(id); err != nil {
+		if err == ErrStreamDoesNotExist {
+			http.Error(w, "Stream not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	tracing.ServeTap(w, r, m.tap, id, m.manager.BloblEnvironment(), m.manager.Logger())
+}
+
 // HandleStreamReady is an http.HandleFunc for providing a ready check across
 // all streams.
 func (m *Type) HandleStreamReady(w http.ResponseWriter, r *http.Request) {
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/bundle/tracing"
 	"github.com/warpstreamlabs/bento/internal/component"
 	"github.com/warpstreamlabs/bento/internal/component/metrics"
 	"github.com/warpstreamlabs/bento/internal/component/processor"
@@ -87,6 +88,7 @@ type Type struct {
 
 	manager    bundle.NewManagement
 	apiEnabled bool
+	tap        *tracing.Tap
 
 	lock sync.Mutex
 }
@@ -115,6 +117,16 @@ func OptAPIEnabled(b bool) func(*Type) {
 	}
 }
 
+// OptSetTap sets a tap of the components of streams, which is exposed by the
+// API in order to observe the messages passing through a stream. The
+// components of streams are only tapped when the environment of the manager
+// was created with tracing.TappedBundle using the same tap.
+func OptSetTap(tap *tracing.Tap) func(*Type) {
+	return func(t *Type) {
+		t.tap = tap
+	}
+}
+
 //------------------------------------------------------------------------------
 
 // Errors specifically returned by a stream manager.
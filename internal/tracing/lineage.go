@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/warpstreamlabs/bento/internal/batch"
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+type lineageSource struct {
+	ctx       context.Context
+	sc        trace.SpanContext
+	collapsed int
+}
+
+// PropagateSpans carries the spans of messages consumed by a component over to
+// the messages that it produced, allowing the lineage of a message to be
+// followed through components that split or merge messages.
+//
+// Produced messages that have no span inherit the span of the consumed message
+// when there was only one (a split), otherwise they are given a new span that
+// is linked to the spans of all consumed messages (a merge). Produced messages
+// that have collapsed other messages into them, such as archives, are given a
+// new child span that is linked to the spans of the other consumed messages.
+//
+// Produced messages are replaced within their batches where necessary.
+func PropagateSpans(prov trace.TracerProvider, operationName string, consumed message.Batch, produced []message.Batch) {
+	// Messages rarely carry spans when tracing is disabled, and so we check for
+	// any before allocating in order to keep this free for untraced messages.
+	traced := false
+	for _, p := range consumed {
+		if p != nil && trace.SpanContextFromContext(p.GetContext()).IsValid() {
+			traced = true
+			break
+		}
+	}
+	if !traced {
+		return
+	}
+
+	sources := make([]lineageSource, 0, len(consumed))
+	sourceIndex := make(map[trace.SpanID]int, len(consumed))
+	for _, p := range consumed {
+		if p == nil {
+			continue
+		}
+		ctx := p.GetContext()
+		sc := trace.SpanContextFromContext(ctx)
+		if !sc.IsValid() {
+			continue
+		}
+		if i, exists := sourceIndex[sc.SpanID()]; exists {
+			sources[i].collapsed = max(sources[i].collapsed, batch.CtxCollapsedCount(ctx))
+			continue
+		}
+		sourceIndex[sc.SpanID()] = len(sources)
+		sources = append(sources, lineageSource{ctx: ctx, sc: sc, collapsed: batch.CtxCollapsedCount(ctx)})
+	}
+	if len(sources) == 0 {
+		return
+	}
+
+	// Produced messages without a span share a single merged span, which is
+	// only created when needed.
+	var mergedSpan trace.Span
+	for _, b := range produced {
+		for i, p := range b {
+			if p == nil {
+				continue
+			}
+			ctx := p.GetContext()
+			sc := trace.SpanContextFromContext(ctx)
+
+			if !sc.IsValid() {
+				if mergedSpan == nil {
+					mergedSpan = trace.SpanFromContext(sources[0].ctx)
+					if len(sources) > 1 {
+						mergedSpan = trace.SpanFromContext(mergedSpanCtx(prov, operationName, sources[0].ctx, sources))
+					}
+				}
+				b[i] = p.WithContext(trace.ContextWithSpan(ctx, mergedSpan))
+				continue
+			}
+
+			collapsed := 1
+			if j, exists := sourceIndex[sc.SpanID()]; exists {
+				collapsed = sources[j].collapsed
+			}
+			if batch.CtxCollapsedCount(ctx) > collapsed && len(sources) > 1 {
+				b[i] = p.WithContext(mergedSpanCtx(prov, operationName, ctx, sources))
+			}
+		}
+	}
+}
+
+// mergedSpanCtx starts a span as a child of any span within the provided
+// context, linked to the spans of all sources other than the parent. The span
+// marks the point at which the messages were merged and is ended immediately.
+func mergedSpanCtx(prov trace.TracerProvider, operationName string, ctx context.Context, sources []lineageSource) context.Context {
+	parentID := trace.SpanContextFromContext(ctx).SpanID()
+
+	links := make([]trace.Link, 0, len(sources))
+	for _, s := range sources {
+		if s.sc.SpanID() == parentID {
+			continue
+		}
+		links = append(links, trace.Link{SpanContext: s.sc})
+	}
+
+	ctx, span := prov.Tracer(name).Start(ctx, operationName,
+		trace.WithLinks(links...),
+		trace.WithAttributes(attribute.Int("merged_count", len(sources))),
+	)
+	span.End()
+	return ctx
+}
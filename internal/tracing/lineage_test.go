@@ -0,0 +1,128 @@
+package tracing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/warpstreamlabs/bento/internal/batch"
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+func lineageTestProvider() (*sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	rec := tracetest.NewSpanRecorder()
+	return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)), rec
+}
+
+func TestPropagateSpansSplit(t *testing.T) {
+	prov, rec := lineageTestProvider()
+
+	in := InitSpan(prov, "input", message.NewPart([]byte("a,b")))
+	inSC := trace.SpanContextFromContext(in.GetContext())
+
+	produced := []message.Batch{{
+		message.NewPart([]byte("a")),
+		in.ShallowCopy(),
+	}}
+	PropagateSpans(prov, "split", message.Batch{in}, produced)
+
+	for _, p := range produced[0] {
+		assert.Equal(t, inSC, trace.SpanContextFromContext(p.GetContext()))
+	}
+	assert.Empty(t, rec.Ended())
+}
+
+func TestPropagateSpansMerge(t *testing.T) {
+	prov, rec := lineageTestProvider()
+
+	consumed := message.Batch{
+		InitSpan(prov, "input", message.NewPart([]byte("a"))),
+		InitSpan(prov, "input", message.NewPart([]byte("b"))),
+		InitSpan(prov, "input", message.NewPart([]byte("c"))),
+	}
+	var scs []trace.SpanContext
+	for _, p := range consumed {
+		scs = append(scs, trace.SpanContextFromContext(p.GetContext()))
+	}
+
+	produced := []message.Batch{{message.NewPart([]byte("abc"))}}
+	PropagateSpans(prov, "merge", consumed, produced)
+
+	ended := rec.Ended()
+	require.Len(t, ended, 1)
+	assert.Equal(t, "merge", ended[0].Name())
+	assert.Equal(t, scs[0].SpanID(), ended[0].Parent().SpanID())
+	assert.Equal(t, scs[0].TraceID(), ended[0].SpanContext().TraceID())
+
+	require.Len(t, ended[0].Links(), 2)
+	assert.Equal(t, scs[1], ended[0].Links()[0].SpanContext)
+	assert.Equal(t, scs[2], ended[0].Links()[1].SpanContext)
+
+	assert.Equal(t, ended[0].SpanContext(), trace.SpanContextFromContext(produced[0][0].GetContext()))
+}
+
+func TestPropagateSpansCollapsed(t *testing.T) {
+	prov, rec := lineageTestProvider()
+
+	consumed := message.Batch{
+		InitSpan(prov, "input", message.NewPart([]byte("a"))),
+		InitSpan(prov, "input", message.NewPart([]byte("b"))),
+	}
+	scA := trace.SpanContextFromContext(consumed[0].GetContext())
+	scB := trace.SpanContextFromContext(consumed[1].GetContext())
+
+	// Archives reuse the first message of a batch, carrying its span.
+	archived := batch.WithCollapsedCount(consumed[0].ShallowCopy(), 2)
+	produced := []message.Batch{{archived}}
+	PropagateSpans(prov, "archive", consumed, produced)
+
+	ended := rec.Ended()
+	require.Len(t, ended, 1)
+	assert.Equal(t, scA.SpanID(), ended[0].Parent().SpanID())
+	require.Len(t, ended[0].Links(), 1)
+	assert.Equal(t, scB, ended[0].Links()[0].SpanContext)
+	assert.Equal(t, 2, batch.CollapsedCount(produced[0][0]))
+
+	// Messages that pass through unchanged are left alone.
+	rec = tracetest.NewSpanRecorder()
+	prov.RegisterSpanProcessor(rec)
+
+	produced = []message.Batch{{consumed[1].ShallowCopy()}}
+	PropagateSpans(prov, "filter", consumed, produced)
+	assert.Empty(t, rec.Ended())
+	assert.Equal(t, scB, trace.SpanContextFromContext(produced[0][0].GetContext()))
+}
+
+func TestPropagateSpansUntracedAllocs(t *testing.T) {
+	var prov trace.TracerProvider = noop.NewTracerProvider()
+
+	consumed := message.QuickBatch([][]byte{[]byte("a"), []byte("b")})
+	produced := []message.Batch{message.QuickBatch([][]byte{[]byte("c")})}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		PropagateSpans(prov, "merge", consumed, produced)
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkPropagateSpansNoop(b *testing.B) {
+	var prov trace.TracerProvider = noop.NewTracerProvider()
+
+	consumed := make(message.Batch, 10)
+	for i := range consumed {
+		consumed[i] = InitSpan(prov, "input", message.NewPart([]byte("hello world")))
+	}
+	produced := []message.Batch{consumed.ShallowCopy()}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		PropagateSpans(prov, "merge", consumed, produced)
+	}
+}
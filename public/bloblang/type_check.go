@@ -0,0 +1,100 @@
+package bloblang
+
+import (
+	"fmt"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang/parser"
+	"github.com/warpstreamlabs/bento/internal/bloblang/query"
+)
+
+// TypeSchema describes the structure of documents that a mapping is expected
+// to be executed against, and is used for statically checking the types within
+// a mapping.
+type TypeSchema struct {
+	s *query.TypeSchema
+}
+
+// NewTypeSchemaFromJSONSchema creates a type schema from a JSON Schema
+// document. Features of JSON Schema that cannot be expressed as a type schema
+// are treated as unknown types.
+func NewTypeSchemaFromJSONSchema(schema []byte) (*TypeSchema, error) {
+	s, err := query.TypeSchemaFromJSONSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	return &TypeSchema{s: s}, nil
+}
+
+// NewTypeSchemaFromAvro creates a type schema from an Avro schema document.
+// Features of Avro that cannot be expressed as a type schema are treated as
+// unknown types.
+func NewTypeSchemaFromAvro(schema []byte) (*TypeSchema, error) {
+	s, err := query.TypeSchemaFromAvro(schema)
+	if err != nil {
+		return nil, err
+	}
+	return &TypeSchema{s: s}, nil
+}
+
+// String returns a human readable representation of the schema.
+func (t *TypeSchema) String() string {
+	if t == nil {
+		return "unknown"
+	}
+	return t.s.String()
+}
+
+// TypeError describes a type mismatch found within a mapping by static
+// analysis, along with the line and column where it was found.
+type TypeError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+// Error returns a single line error string.
+func (t *TypeError) Error() string {
+	return fmt.Sprintf("line %v char %v: %v", t.Line, t.Column, t.Message)
+}
+
+// CheckTypes parses a Bloblang mapping and statically checks the types within
+// it, returning a slice of type errors found. An input schema can optionally be
+// provided in order to check the mapping against the structure of the
+// documents it will be executed upon, otherwise only errors that are
+// independent of the input are found.
+//
+// Type errors describe operations that will fail when the affected parts of the
+// mapping are executed, such as arithmetic on a string, as well as references
+// to fields that are not defined by the input schema. A reference to an
+// undefined field does not fail at runtime, as it results in null, but is
+// reported as it is likely to be a mistake.
+//
+// Type checking is conservative, and therefore the absence of errors does not
+// guarantee that a mapping is free of type errors.
+//
+// When a parsing error occurs the error will be the type *ParseError, which
+// gives access to the line and column where the error occurred, as well as a
+// method for creating a well formatted error message.
+func (e *Environment) CheckTypes(blobl string, input *TypeSchema) ([]*TypeError, error) {
+	var iSchema *query.TypeSchema
+	if input != nil {
+		iSchema = input.s
+	}
+
+	iErrs, err := e.env.CheckMappingTypes(blobl, iSchema)
+	if err != nil {
+		if pErr, ok := err.(*parser.Error); ok {
+			return nil, internalToPublicParserError([]rune(blobl), pErr)
+		}
+		return nil, err
+	}
+
+	mappingRunes := []rune(blobl)
+	tErrs := make([]*TypeError, 0, len(iErrs))
+	for _, iErr := range iErrs {
+		tErr := &TypeError{Message: iErr.Err.Error()}
+		tErr.Line, tErr.Column = parser.LineAndColOf(mappingRunes, iErr.Input)
+		tErrs = append(tErrs, tErr)
+	}
+	return tErrs, nil
+}
@@ -0,0 +1,52 @@
+package bloblang
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvironmentCheckTypes(t *testing.T) {
+	schema, err := NewTypeSchemaFromJSONSchema([]byte(`{
+  "type": "object",
+  "properties": {
+    "id": { "type": "integer" },
+    "name": { "type": "string" }
+  },
+  "required": [ "id", "name" ],
+  "additionalProperties": false
+}`))
+	require.NoError(t, err)
+
+	tErrs, err := GlobalEnvironment().CheckTypes(`root.a = this.name.uppercase()
+root.b = this.id.uppercase()
+root.c = this.nope`, schema)
+	require.NoError(t, err)
+
+	require.Len(t, tErrs, 2)
+	assert.Equal(t, "line 2 char 18: method uppercase: expected string or bytes value, got number from field `this.id`", tErrs[0].Error())
+	assert.Equal(t, "line 3 char 1: field `this.nope` does not exist, the field nope is not one of the known fields [id name]", tErrs[1].Error())
+
+	tErrs, err = GlobalEnvironment().CheckTypes(`root.b = this.id.uppercase()`, nil)
+	require.NoError(t, err)
+	assert.Empty(t, tErrs)
+
+	_, err = GlobalEnvironment().CheckTypes(`root.b = this.id.uppercase(`, nil)
+	require.Error(t, err)
+	assert.IsType(t, &ParseError{}, err)
+}
+
+func TestTypeSchemaFromAvro(t *testing.T) {
+	schema, err := NewTypeSchemaFromAvro([]byte(`{
+  "type": "record",
+  "name": "Foo",
+  "fields": [ { "name": "count", "type": "int" } ]
+}`))
+	require.NoError(t, err)
+
+	tErrs, err := GlobalEnvironment().CheckTypes(`root = this.count.trim()`, schema)
+	require.NoError(t, err)
+	require.Len(t, tErrs, 1)
+	assert.Equal(t, "line 1 char 19: method trim: expected string or bytes value, got number from field `this.count`", tErrs[0].Error())
+}
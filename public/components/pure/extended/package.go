@@ -11,6 +11,7 @@ package extended
 import (
 	// Import pure but larger packages.
 	_ "github.com/warpstreamlabs/bento/internal/impl/awk"
+	_ "github.com/warpstreamlabs/bento/internal/impl/html"
 	_ "github.com/warpstreamlabs/bento/internal/impl/jsonpath"
 	_ "github.com/warpstreamlabs/bento/internal/impl/lang"
 	_ "github.com/warpstreamlabs/bento/internal/impl/msgpack"
@@ -12,8 +12,10 @@ import (
 
 // Errors returned by cache types.
 var (
-	ErrKeyAlreadyExists = errors.New("key already exists")
-	ErrKeyNotFound      = errors.New("key does not exist")
+	ErrKeyAlreadyExists           = errors.New("key already exists")
+	ErrKeyNotFound                = errors.New("key does not exist")
+	ErrCompareAndSwapFailed       = errors.New("value does not match the expected value")
+	ErrCacheOperationNotSupported = errors.New("operation not supported by this cache")
 )
 
 // Cache is an interface implemented by Bento caches.
@@ -55,6 +57,64 @@ type batchedCache interface {
 	SetMulti(ctx context.Context, keyValues ...CacheItem) error
 }
 
+// MultiGetCache is an interface implemented by caches that are able to
+// retrieve the values of multiple keys in a single request. This interface is
+// optional for caches and when implemented will automatically be utilised
+// where possible.
+type MultiGetCache interface {
+	Cache
+
+	// GetMulti attempts to retrieve the values of multiple keys. Keys that do
+	// not exist are omitted from the returned map, and an error is returned
+	// only if the request fails.
+	GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error)
+}
+
+// IncrementCache is an interface implemented by caches that are able to
+// atomically increment integer values stored as decimal strings. This interface
+// is optional for caches, and caches that implement CompareAndSwapCache
+// without it are incremented with a loop of compare and swap operations.
+//
+// Caches obtained with AccessCache always implement this interface, and return
+// ErrCacheOperationNotSupported when the underlying cache does not support it.
+type IncrementCache interface {
+	Cache
+
+	// Increment atomically adds a delta to the integer value of a key and
+	// returns the result, where a key that does not exist is treated as zero.
+	// If a TTL is provided then it is reset by each increment.
+	Increment(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error)
+}
+
+// CompareAndSwapCache is an interface implemented by caches that are able to
+// atomically set the value of a key only if it matches an expected value. This
+// interface is optional for caches.
+//
+// Caches obtained with AccessCache always implement this interface, and return
+// ErrCacheOperationNotSupported when the underlying cache does not support it.
+type CompareAndSwapCache interface {
+	Cache
+
+	// CompareAndSwap attempts to set the value of a key only if its current
+	// value matches old. Returns ErrKeyNotFound if the key does not exist and
+	// ErrCompareAndSwapFailed if the current value does not match.
+	CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error
+}
+
+// ScanCache is an interface implemented by caches that are able to list the
+// keys they contain. This interface is optional for caches.
+//
+// Caches obtained with AccessCache always implement this interface, and return
+// ErrCacheOperationNotSupported when the underlying cache does not support it.
+type ScanCache interface {
+	Cache
+
+	// Scan returns the keys of the cache that begin with a prefix, where an
+	// empty prefix matches all keys. The order of the returned keys is not
+	// guaranteed.
+	Scan(ctx context.Context, prefix string) ([]string, error)
+}
+
 //------------------------------------------------------------------------------
 
 // Implements types.Cache.
@@ -113,10 +173,82 @@ func (a *airGapCache) Delete(ctx context.Context, key string) error {
 	return a.c.Delete(ctx, key)
 }
 
+func (a *airGapCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	if mg, ok := a.c.(MultiGetCache); ok {
+		return mg.GetMulti(ctx, keys...)
+	}
+	values := make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		v, err := a.Get(ctx, k)
+		if err != nil {
+			if errors.Is(err, component.ErrKeyNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		values[k] = v
+	}
+	return values, nil
+}
+
+func (a *airGapCache) Increment(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	if ic, ok := a.c.(IncrementCache); ok {
+		v, err := ic.Increment(ctx, key, delta, ttl)
+		return v, toInternalCacheErr(err)
+	}
+	if _, ok := a.c.(CompareAndSwapCache); ok {
+		return cache.IncrementWithCompareAndSwap(ctx, a, key, delta, ttl)
+	}
+	return 0, component.ErrCacheOperationNotSupported
+}
+
+func (a *airGapCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	if cs, ok := a.c.(CompareAndSwapCache); ok {
+		return toInternalCacheErr(cs.CompareAndSwap(ctx, key, old, value, ttl))
+	}
+	return component.ErrCacheOperationNotSupported
+}
+
+func (a *airGapCache) Scan(ctx context.Context, prefix string) ([]string, error) {
+	if sc, ok := a.c.(ScanCache); ok {
+		keys, err := sc.Scan(ctx, prefix)
+		return keys, toInternalCacheErr(err)
+	}
+	return nil, component.ErrCacheOperationNotSupported
+}
+
 func (a *airGapCache) Close(ctx context.Context) error {
 	return a.c.Close(ctx)
 }
 
+func toInternalCacheErr(err error) error {
+	switch {
+	case errors.Is(err, ErrKeyNotFound):
+		return component.ErrKeyNotFound
+	case errors.Is(err, ErrKeyAlreadyExists):
+		return component.ErrKeyAlreadyExists
+	case errors.Is(err, ErrCompareAndSwapFailed):
+		return component.ErrCompareAndSwapFailed
+	case errors.Is(err, ErrCacheOperationNotSupported):
+		return component.ErrCacheOperationNotSupported
+	}
+	return err
+}
+
+func fromInternalCacheErr(err error) error {
+	switch {
+	case errors.Is(err, component.ErrKeyNotFound):
+		return ErrKeyNotFound
+	case errors.Is(err, component.ErrKeyAlreadyExists):
+		return ErrKeyAlreadyExists
+	case errors.Is(err, component.ErrCompareAndSwapFailed):
+		return ErrCompareAndSwapFailed
+	case errors.Is(err, component.ErrCacheOperationNotSupported):
+		return ErrCacheOperationNotSupported
+	}
+	return err
+}
+
 //------------------------------------------------------------------------------
 
 // Implements Cache around a types.Cache.
@@ -151,6 +283,24 @@ func (r *reverseAirGapCache) Delete(ctx context.Context, key string) error {
 	return r.c.Delete(ctx, key)
 }
 
+func (r *reverseAirGapCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	return cache.GetMulti(ctx, r.c, keys...)
+}
+
+func (r *reverseAirGapCache) Increment(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	v, err := cache.Increment(ctx, r.c, key, delta, ttl)
+	return v, fromInternalCacheErr(err)
+}
+
+func (r *reverseAirGapCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	return fromInternalCacheErr(cache.CompareAndSwap(ctx, r.c, key, old, value, ttl))
+}
+
+func (r *reverseAirGapCache) Scan(ctx context.Context, prefix string) ([]string, error) {
+	keys, err := cache.Scan(ctx, r.c, prefix)
+	return keys, fromInternalCacheErr(err)
+}
+
 func (r *reverseAirGapCache) Close(ctx context.Context) error {
 	return r.c.Close(ctx)
 }
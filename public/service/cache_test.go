@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -90,6 +91,41 @@ func (c *closableCacheMulti) SetMulti(ctx context.Context, keyValues ...CacheIte
 	return nil
 }
 
+type closableCacheCAS struct {
+	*closableCache
+}
+
+func (c *closableCacheCAS) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	i, ok := c.m[key]
+	if !ok {
+		return ErrKeyNotFound
+	}
+	if string(i.b) != string(old) {
+		return ErrCompareAndSwapFailed
+	}
+	c.m[key] = testCacheItem{
+		b: value, ttl: ttl,
+	}
+	return nil
+}
+
+type closableCacheScan struct {
+	*closableCache
+}
+
+func (c *closableCacheScan) Scan(ctx context.Context, prefix string) ([]string, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	var keys []string
+	for k := range c.m {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
 func TestCacheAirGapShutdown(t *testing.T) {
 	rl := &closableCache{}
 	agrl := newAirGapCache(rl, metrics.Noop())
@@ -490,3 +526,107 @@ func TestCacheReverseAirGapDelete(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, map[string]testCacheItem{}, rl.m)
 }
+
+func TestCacheAirGapCompareAndSwap(t *testing.T) {
+	ctx := context.Background()
+	rl := &closableCacheCAS{
+		closableCache: &closableCache{
+			m: map[string]testCacheItem{
+				"foo": {b: []byte("bar")},
+			},
+		},
+	}
+	agrl := newAirGapCache(rl, metrics.Noop())
+
+	err := cache.CompareAndSwap(ctx, agrl, "foo", []byte("nope"), []byte("baz"), nil)
+	assert.True(t, errors.Is(err, component.ErrCompareAndSwapFailed), err)
+
+	err = cache.CompareAndSwap(ctx, agrl, "nope", []byte("bar"), []byte("baz"), nil)
+	assert.True(t, errors.Is(err, component.ErrKeyNotFound), err)
+
+	assert.NoError(t, cache.CompareAndSwap(ctx, agrl, "foo", []byte("bar"), []byte("baz"), nil))
+	assert.Equal(t, "baz", string(rl.m["foo"].b))
+
+	v, err := cache.Increment(ctx, agrl, "counter", 3, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), v)
+
+	v, err = cache.Increment(ctx, agrl, "counter", 2, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), v)
+}
+
+func TestCacheAirGapOperationNotSupported(t *testing.T) {
+	ctx := context.Background()
+	rl := &closableCache{
+		m: map[string]testCacheItem{
+			"foo": {b: []byte("bar")},
+		},
+	}
+	agrl := newAirGapCache(rl, metrics.Noop())
+
+	_, err := cache.Increment(ctx, agrl, "foo", 1, nil)
+	assert.True(t, errors.Is(err, component.ErrCacheOperationNotSupported), err)
+
+	err = cache.CompareAndSwap(ctx, agrl, "foo", []byte("bar"), []byte("baz"), nil)
+	assert.True(t, errors.Is(err, component.ErrCacheOperationNotSupported), err)
+
+	values, err := cache.GetMulti(ctx, agrl, "foo", "bar")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"foo": []byte("bar")}, values)
+
+	_, err = cache.Scan(ctx, agrl, "")
+	assert.True(t, errors.Is(err, component.ErrCacheOperationNotSupported), err)
+}
+
+func TestCacheAirGapScan(t *testing.T) {
+	ctx := context.Background()
+	rl := &closableCacheScan{
+		closableCache: &closableCache{
+			m: map[string]testCacheItem{
+				"foo":    {b: []byte("a")},
+				"foobar": {b: []byte("b")},
+				"bar":    {b: []byte("c")},
+			},
+		},
+	}
+	agrl := newAirGapCache(rl, metrics.Noop())
+
+	keys, err := cache.Scan(ctx, agrl, "foo")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"foo", "foobar"}, keys)
+
+	keys, err = cache.Scan(ctx, agrl, "")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"foo", "foobar", "bar"}, keys)
+
+	rl.err = errors.New("nope")
+	_, err = cache.Scan(ctx, agrl, "")
+	assert.EqualError(t, err, "nope")
+}
+
+func TestCacheReverseAirGapCompareAndSwap(t *testing.T) {
+	ctx := context.Background()
+	rl := &closableCacheType{
+		m: map[string]testCacheItem{
+			"foo": {b: []byte("bar")},
+		},
+	}
+	var agrl Cache = newReverseAirGapCache(rl)
+
+	cas, ok := agrl.(CompareAndSwapCache)
+	assert.True(t, ok)
+	err := cas.CompareAndSwap(ctx, "foo", []byte("bar"), []byte("baz"), nil)
+	assert.True(t, errors.Is(err, ErrCacheOperationNotSupported), err)
+
+	mg, ok := agrl.(MultiGetCache)
+	assert.True(t, ok)
+	values, err := mg.GetMulti(ctx, "foo", "bar")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"foo": []byte("bar")}, values)
+
+	sc, ok := agrl.(ScanCache)
+	assert.True(t, ok)
+	_, err = sc.Scan(ctx, "")
+	assert.True(t, errors.Is(err, ErrCacheOperationNotSupported), err)
+}
@@ -523,6 +523,7 @@ func (c *ConfigView) FormatJSON() ([]byte, error) {
 func (c *ConfigView) RenderDocs() ([]byte, error) {
 	_, rootOnly := map[string]struct{}{
 		"cache":      {},
+		"lock":       {},
 		"rate_limit": {},
 		"processor":  {},
 		"scanner":    {},
@@ -12,6 +12,7 @@ import (
 	"github.com/warpstreamlabs/bento/internal/component/cache"
 	"github.com/warpstreamlabs/bento/internal/component/input"
 	iprocessors "github.com/warpstreamlabs/bento/internal/component/input/processors"
+	"github.com/warpstreamlabs/bento/internal/component/lock"
 	"github.com/warpstreamlabs/bento/internal/component/metrics"
 	"github.com/warpstreamlabs/bento/internal/component/output"
 	"github.com/warpstreamlabs/bento/internal/component/output/batcher"
@@ -551,6 +552,51 @@ func (e *Environment) GetRateLimitConfig(name string) (*ConfigView, bool) {
 	}, true
 }
 
+// RegisterLock attempts to register a new lock plugin by providing a
+// description of the configuration for the plugin as well as a constructor for
+// the lock itself. The constructor will be called for each instantiation of the
+// component within a config.
+func (e *Environment) RegisterLock(name string, spec *ConfigSpec, ctor LockConstructor) error {
+	componentSpec := spec.component
+	componentSpec.Name = name
+	componentSpec.Type = docs.TypeLock
+	return e.internal.LockAdd(func(conf lock.Config, nm bundle.NewManagement) (lock.V1, error) {
+		pluginConf, err := extractConfig(nm, spec, name, conf.Plugin)
+		if err != nil {
+			return nil, err
+		}
+		l, err := ctor(pluginConf, newResourcesFromManager(nm))
+		if err != nil {
+			return nil, err
+		}
+		return newAirGapLock(l, nm.Metrics()), nil
+	}, componentSpec)
+}
+
+// WalkLocks executes a provided function argument for every lock component
+// that has been registered to the environment.
+func (e *Environment) WalkLocks(fn func(name string, config *ConfigView)) {
+	for _, v := range e.internal.LockDocs() {
+		fn(v.Name, &ConfigView{
+			prov:      e.internal,
+			component: v,
+		})
+	}
+}
+
+// GetLockConfig attempts to obtain a lock configuration spec by the component
+// name. Returns a nil ConfigView and false if the component is unknown.
+func (e *Environment) GetLockConfig(name string) (*ConfigView, bool) {
+	c, exists := bundle.AllLocks.DocsFor(name)
+	if !exists {
+		return nil, false
+	}
+	return &ConfigView{
+		prov:      e.internal,
+		component: c,
+	}, true
+}
+
 // RegisterMetricsExporter attempts to register a new metrics exporter plugin by
 // providing a description of the configuration for the plugin as well as a
 // constructor for the metrics exporter itself.
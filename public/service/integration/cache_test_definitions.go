@@ -3,6 +3,7 @@ package integration
 import (
 	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/cache"
 )
 
 // CacheTestOpenClose checks that the cache can be started, an item added, and
@@ -122,3 +124,112 @@ func CacheTestGetAndSet(n int) CacheTestDefinition {
 		},
 	)
 }
+
+// CacheTestGetMulti checks that we can get multiple items at once, where
+// missing keys are omitted.
+func CacheTestGetMulti() CacheTestDefinition {
+	return namedCacheTest(
+		"can get multiple keys",
+		func(t *testing.T, env *cacheTestEnvironment) {
+			c := initCache(t, env)
+			t.Cleanup(func() {
+				closeCache(t, c)
+			})
+
+			require.NoError(t, c.Set(env.ctx, "multikey1", []byte("value1"), nil))
+			require.NoError(t, c.Set(env.ctx, "multikey2", []byte("value2"), nil))
+
+			res, err := cache.GetMulti(env.ctx, c, "multikey1", "multikey2", "multikey3")
+			require.NoError(t, err)
+			assert.Equal(t, map[string][]byte{
+				"multikey1": []byte("value1"),
+				"multikey2": []byte("value2"),
+			}, res)
+		},
+	)
+}
+
+// CacheTestIncrement checks that concurrent increments of a key are atomic.
+func CacheTestIncrement() CacheTestDefinition {
+	return namedCacheTest(
+		"can increment keys concurrently",
+		func(t *testing.T, env *cacheTestEnvironment) {
+			c := initCache(t, env)
+			t.Cleanup(func() {
+				closeCache(t, c)
+			})
+
+			v, err := cache.Increment(env.ctx, c, "incrkey", 5, nil)
+			require.NoError(t, err)
+			assert.Equal(t, int64(5), v)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := cache.Increment(env.ctx, c, "incrkey", 1, nil)
+					assert.NoError(t, err)
+				}()
+			}
+			wg.Wait()
+
+			res, err := c.Get(env.ctx, "incrkey")
+			require.NoError(t, err)
+			assert.Equal(t, "15", string(res))
+		},
+	)
+}
+
+// CacheTestCompareAndSwap checks that a value is only swapped when it matches
+// the expected value.
+func CacheTestCompareAndSwap() CacheTestDefinition {
+	return namedCacheTest(
+		"can compare and swap",
+		func(t *testing.T, env *cacheTestEnvironment) {
+			c := initCache(t, env)
+			t.Cleanup(func() {
+				closeCache(t, c)
+			})
+
+			err := cache.CompareAndSwap(env.ctx, c, "caskey", []byte("first"), []byte("second"), nil)
+			require.ErrorIs(t, err, component.ErrKeyNotFound)
+
+			require.NoError(t, c.Set(env.ctx, "caskey", []byte("first"), nil))
+
+			err = cache.CompareAndSwap(env.ctx, c, "caskey", []byte("nope"), []byte("second"), nil)
+			require.ErrorIs(t, err, component.ErrCompareAndSwapFailed)
+
+			require.NoError(t, cache.CompareAndSwap(env.ctx, c, "caskey", []byte("first"), []byte("second"), nil))
+
+			res, err := c.Get(env.ctx, "caskey")
+			require.NoError(t, err)
+			assert.Equal(t, "second", string(res))
+		},
+	)
+}
+
+// CacheTestScan checks that the keys of a cache can be listed by a prefix.
+func CacheTestScan() CacheTestDefinition {
+	return namedCacheTest(
+		"can scan keys",
+		func(t *testing.T, env *cacheTestEnvironment) {
+			c := initCache(t, env)
+			t.Cleanup(func() {
+				closeCache(t, c)
+			})
+
+			require.NoError(t, c.Set(env.ctx, "scankey1", []byte("value1"), nil))
+			require.NoError(t, c.Set(env.ctx, "scankey2", []byte("value2"), nil))
+			require.NoError(t, c.Set(env.ctx, "otherscankey", []byte("value3"), nil))
+
+			keys, err := cache.Scan(env.ctx, c, "scankey")
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []string{"scankey1", "scankey2"}, keys)
+
+			keys, err = cache.Scan(env.ctx, c, "")
+			require.NoError(t, err)
+			assert.Subset(t, keys, []string{"scankey1", "scankey2", "otherscankey"})
+		},
+	)
+}
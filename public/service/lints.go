@@ -71,6 +71,10 @@ const (
 
 	// LintDeprecated means a field is deprecated and should not be used.
 	LintDeprecated LintType = iota
+
+	// LintBadBloblangType means the field contains a Bloblang mapping with a
+	// type error detected by static analysis.
+	LintBadBloblangType LintType = iota
 )
 
 func convertDocsLintType(d docs.LintType) LintType {
@@ -109,6 +113,8 @@ func convertDocsLintType(d docs.LintType) LintType {
 		return LintExpectedScalar
 	case docs.LintDeprecated:
 		return LintDeprecated
+	case docs.LintBadBloblangType:
+		return LintBadBloblangType
 	}
 	return LintCustom
 }
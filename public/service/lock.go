@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/component/lock"
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+)
+
+// Lock is an interface implemented by Bento locks, which provide named leases
+// that can be held by a single instance at a time.
+type Lock interface {
+	// TryAcquire attempts to acquire a named lock for the period of a lease,
+	// or to extend the lease when the lock is already held by this instance.
+	// Returns false if the lock is currently held elsewhere, or an error if
+	// the attempt fails.
+	TryAcquire(ctx context.Context, name string, lease time.Duration) (bool, error)
+
+	// Release a named lock held by this instance so that it can be acquired
+	// elsewhere without waiting for the lease to expire. Releasing a lock that
+	// is not held by this instance has no effect.
+	Release(ctx context.Context, name string) error
+
+	Closer
+}
+
+//------------------------------------------------------------------------------
+
+func newAirGapLock(l Lock, stats metrics.Type) lock.V1 {
+	return lock.MetricsForLock(l, stats)
+}
+
+//------------------------------------------------------------------------------
+
+// Implements Lock around a lock.V1.
+type reverseAirGapLock struct {
+	l lock.V1
+}
+
+func newReverseAirGapLock(l lock.V1) Lock {
+	return &reverseAirGapLock{l}
+}
+
+func (a *reverseAirGapLock) TryAcquire(ctx context.Context, name string, lease time.Duration) (bool, error) {
+	return a.l.TryAcquire(ctx, name, lease)
+}
+
+func (a *reverseAirGapLock) Release(ctx context.Context, name string) error {
+	return a.l.Release(ctx, name)
+}
+
+func (a *reverseAirGapLock) Close(ctx context.Context) error {
+	return a.l.Close(ctx)
+}
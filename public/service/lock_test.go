@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+)
+
+type closableLock struct {
+	held     map[string]bool
+	err      error
+	released []string
+	closed   bool
+}
+
+func (c *closableLock) TryAcquire(ctx context.Context, name string, lease time.Duration) (bool, error) {
+	return c.held[name], c.err
+}
+
+func (c *closableLock) Release(ctx context.Context, name string) error {
+	c.released = append(c.released, name)
+	return nil
+}
+
+func (c *closableLock) Close(ctx context.Context) error {
+	c.closed = true
+	return nil
+}
+
+func TestLockAirGap(t *testing.T) {
+	ctx := context.Background()
+	l := &closableLock{
+		held: map[string]bool{"foo": true},
+	}
+	agl := newAirGapLock(l, metrics.Noop())
+
+	held, err := agl.TryAcquire(ctx, "foo", time.Second)
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	held, err = agl.TryAcquire(ctx, "bar", time.Second)
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	l.err = errors.New("test error")
+	_, err = agl.TryAcquire(ctx, "foo", time.Second)
+	assert.EqualError(t, err, "test error")
+
+	require.NoError(t, agl.Release(ctx, "foo"))
+	assert.Equal(t, []string{"foo"}, l.released)
+
+	require.NoError(t, agl.Close(ctx))
+	assert.True(t, l.closed)
+}
+
+func TestLockMockResources(t *testing.T) {
+	res := MockResources(MockResourcesOptAddLock("foo", func(ctx context.Context, name string, lease time.Duration) (bool, error) {
+		return name == "mine", nil
+	}))
+	assert.True(t, res.HasLock("foo"))
+	assert.False(t, res.HasLock("bar"))
+
+	var mine, theirs bool
+	require.NoError(t, res.AccessLock(context.Background(), "foo", func(l Lock) {
+		mine, _ = l.TryAcquire(context.Background(), "mine", time.Second)
+		theirs, _ = l.TryAcquire(context.Background(), "theirs", time.Second)
+	}))
+	assert.True(t, mine)
+	assert.False(t, theirs)
+}
@@ -36,6 +36,38 @@ type MessageAwareRateLimit interface {
 	Closer
 }
 
+// KeyedRateLimit is an interface implemented by Bento rate limits that are able
+// to limit access independently for each of an arbitrary number of keys.
+type KeyedRateLimit interface {
+	// AccessKey accesses the rate limited resource identified by a key, where
+	// the limit is applied separately for each key. Returns a duration or an
+	// error if the rate limit check fails. The returned duration is either zero
+	// (meaning the resource may be accessed) or a reasonable length of time to
+	// wait before requesting again.
+	AccessKey(ctx context.Context, key string) (time.Duration, error)
+
+	RateLimit
+}
+
+// AdaptiveRateLimit is an interface implemented by Bento rate limits that adjust
+// their limit according to feedback from the components that access them, such
+// as whether a downstream service is throttling requests.
+//
+// The rate limits obtained via Resources.AccessRateLimit always implement this
+// interface, where feedback is ignored by rate limits that are not adaptive.
+type AdaptiveRateLimit interface {
+	// ReportSuccess reports that a request made after accessing the rate limit
+	// was accepted.
+	ReportSuccess(ctx context.Context)
+
+	// ReportThrottled reports that a request made after accessing the rate
+	// limit was throttled. The retryAfter duration is the period requested by
+	// the downstream service before trying again, or zero if unknown.
+	ReportThrottled(ctx context.Context, retryAfter time.Duration)
+
+	RateLimit
+}
+
 //------------------------------------------------------------------------------
 
 func newAirGapRateLimit(c RateLimit, stats metrics.Type) ratelimit.V1 {
@@ -44,6 +76,9 @@ func newAirGapRateLimit(c RateLimit, stats metrics.Type) ratelimit.V1 {
 
 func newAirGapMessageAwareRateLimit(rl MessageAwareRateLimit, stats metrics.Type) ratelimit.MessageAwareRateLimit {
 	agrl := &airGapMessageAwareRateLimit{r: rl}
+	if k, ok := rl.(KeyedRateLimit); ok {
+		return ratelimit.MetricsForMessageAwareRateLimit(&airGapKeyedMessageAwareRateLimit{airGapMessageAwareRateLimit: agrl, k: k}, stats)
+	}
 	return ratelimit.MetricsForMessageAwareRateLimit(agrl, stats)
 }
 
@@ -66,10 +101,27 @@ func (a *airGapMessageAwareRateLimit) Access(ctx context.Context) (time.Duration
 	return a.r.Access(ctx)
 }
 
+func (a *airGapMessageAwareRateLimit) ReportSuccess(ctx context.Context) {
+	ratelimit.ReportSuccess(ctx, a.r)
+}
+
+func (a *airGapMessageAwareRateLimit) ReportThrottled(ctx context.Context, retryAfter time.Duration) {
+	ratelimit.ReportThrottled(ctx, a.r, retryAfter)
+}
+
 func (a *airGapMessageAwareRateLimit) Close(ctx context.Context) error {
 	return a.r.Close(ctx)
 }
 
+type airGapKeyedMessageAwareRateLimit struct {
+	*airGapMessageAwareRateLimit
+	k KeyedRateLimit
+}
+
+func (a *airGapKeyedMessageAwareRateLimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	return a.k.AccessKey(ctx, key)
+}
+
 //------------------------------------------------------------------------------
 
 // Implements RateLimit around a types.RateLimit.
@@ -77,7 +129,10 @@ type reverseAirGapRateLimit struct {
 	r ratelimit.V1
 }
 
-func newReverseAirGapRateLimit(r ratelimit.V1) *reverseAirGapRateLimit {
+func newReverseAirGapRateLimit(r ratelimit.V1) RateLimit {
+	if k, ok := r.(ratelimit.Keyed); ok {
+		return &reverseAirGapKeyedRateLimit{reverseAirGapRateLimit: &reverseAirGapRateLimit{r}, k: k}
+	}
 	return &reverseAirGapRateLimit{r}
 }
 
@@ -85,10 +140,28 @@ func (a *reverseAirGapRateLimit) Access(ctx context.Context) (time.Duration, err
 	return a.r.Access(ctx)
 }
 
+func (a *reverseAirGapRateLimit) ReportSuccess(ctx context.Context) {
+	ratelimit.ReportSuccess(ctx, a.r)
+}
+
+func (a *reverseAirGapRateLimit) ReportThrottled(ctx context.Context, retryAfter time.Duration) {
+	ratelimit.ReportThrottled(ctx, a.r, retryAfter)
+}
+
 func (a *reverseAirGapRateLimit) Close(ctx context.Context) error {
 	return a.r.Close(ctx)
 }
 
+// Implements KeyedRateLimit around a ratelimit.Keyed.
+type reverseAirGapKeyedRateLimit struct {
+	*reverseAirGapRateLimit
+	k ratelimit.Keyed
+}
+
+func (a *reverseAirGapKeyedRateLimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	return a.k.AccessKey(ctx, key)
+}
+
 //------------------------------------------------------------------------------
 
 // Implements MessageAwareRateLimit around a types.MessageAwareRateLimiter.
@@ -96,7 +169,10 @@ type reverseAirGapMessageAwareRateLimit struct {
 	r ratelimit.MessageAwareRateLimit
 }
 
-func newReverseAirGapMessageAwareRateLimit(r ratelimit.MessageAwareRateLimit) *reverseAirGapMessageAwareRateLimit {
+func newReverseAirGapMessageAwareRateLimit(r ratelimit.MessageAwareRateLimit) MessageAwareRateLimit {
+	if k, ok := r.(ratelimit.Keyed); ok {
+		return &reverseAirGapKeyedMessageAwareRateLimit{reverseAirGapMessageAwareRateLimit: &reverseAirGapMessageAwareRateLimit{r}, k: k}
+	}
 	return &reverseAirGapMessageAwareRateLimit{r}
 }
 func (a *reverseAirGapMessageAwareRateLimit) Add(ctx context.Context, msgs ...*Message) bool {
@@ -111,6 +187,25 @@ func (a *reverseAirGapMessageAwareRateLimit) Access(ctx context.Context) (time.D
 	return a.r.Access(ctx)
 }
 
+func (a *reverseAirGapMessageAwareRateLimit) ReportSuccess(ctx context.Context) {
+	ratelimit.ReportSuccess(ctx, a.r)
+}
+
+func (a *reverseAirGapMessageAwareRateLimit) ReportThrottled(ctx context.Context, retryAfter time.Duration) {
+	ratelimit.ReportThrottled(ctx, a.r, retryAfter)
+}
+
 func (a *reverseAirGapMessageAwareRateLimit) Close(ctx context.Context) error {
 	return a.r.Close(ctx)
 }
+
+// Implements KeyedRateLimit and MessageAwareRateLimit around a
+// ratelimit.Keyed.
+type reverseAirGapKeyedMessageAwareRateLimit struct {
+	*reverseAirGapMessageAwareRateLimit
+	k ratelimit.Keyed
+}
+
+func (a *reverseAirGapKeyedMessageAwareRateLimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	return a.k.AccessKey(ctx, key)
+}
@@ -7,8 +7,10 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/warpstreamlabs/bento/internal/component/metrics"
+	"github.com/warpstreamlabs/bento/internal/component/ratelimit"
 )
 
 type closableRateLimit struct {
@@ -86,3 +88,40 @@ func TestRateLimitReverseAirGapShutdown(t *testing.T) {
 	assert.NoError(t, agrl.Close(context.Background()))
 	assert.True(t, rl.closed)
 }
+
+//------------------------------------------------------------------------------
+
+type keyedRateLimit struct {
+	closableRateLimit
+	keys []string
+}
+
+func (k *keyedRateLimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	k.keys = append(k.keys, key)
+	return time.Second, nil
+}
+
+func TestRateLimitAirGapKeyed(t *testing.T) {
+	ctx := context.Background()
+
+	rl := &keyedRateLimit{}
+	agrl := newAirGapRateLimit(rl, metrics.Noop())
+
+	keyed, ok := agrl.(ratelimit.Keyed)
+	require.True(t, ok)
+
+	tout, err := keyed.AccessKey(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, tout)
+
+	rgrl, ok := newReverseAirGapRateLimit(agrl).(KeyedRateLimit)
+	require.True(t, ok)
+
+	tout, err = rgrl.AccessKey(ctx, "bar")
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, tout)
+	assert.Equal(t, []string{"foo", "bar"}, rl.keys)
+
+	_, ok = newReverseAirGapRateLimit(newAirGapRateLimit(&closableRateLimit{}, metrics.Noop())).(KeyedRateLimit)
+	assert.False(t, ok)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/warpstreamlabs/bento/internal/bundle"
 	"github.com/warpstreamlabs/bento/internal/component/cache"
 	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/component/lock"
 	"github.com/warpstreamlabs/bento/internal/component/output"
 	"github.com/warpstreamlabs/bento/internal/component/ratelimit"
 	"github.com/warpstreamlabs/bento/internal/filepath/ifs"
@@ -101,6 +102,15 @@ func MockResourcesOptAddRateLimit(name string, fn func(context.Context) (time.Du
 	}
 }
 
+// MockResourcesOptAddLock instantiates the resources type with a mock lock with
+// a given name, the provided closure will be called for each attempt to acquire
+// the lock.
+func MockResourcesOptAddLock(name string, fn func(ctx context.Context, name string, lease time.Duration) (bool, error)) MockResourcesOptFn {
+	return func(m *mock.Manager) {
+		m.Locks[name] = mock.Lock(fn)
+	}
+}
+
 // EngineVersion returns the version stamp associated with the underlying
 // bento engine. The version string is not guaranteed to match any particular
 // scheme.
@@ -290,6 +300,21 @@ func (r *Resources) HasRateLimit(name string) bool {
 	return r.mgr.ProbeRateLimit(name)
 }
 
+// AccessLock attempts to access a lock resource by name. This action can block
+// if CRUD operations are being actively performed on the resource.
+func (r *Resources) AccessLock(ctx context.Context, name string, fn func(l Lock)) error {
+	return r.mgr.AccessLock(ctx, name, func(l lock.V1) {
+		fn(newReverseAirGapLock(l))
+	})
+}
+
+// HasLock confirms whether a lock with a given name has been registered as a
+// resource. This method is useful during component initialisation as it is
+// defensive against ordering.
+func (r *Resources) HasLock(name string) bool {
+	return r.mgr.ProbeLock(name)
+}
+
 // GetGeneric queries the resources for a generic key value, potentially set by
 // another plugin or instantiation of this plugin.
 func (r *Resources) GetGeneric(key any) (any, bool) {
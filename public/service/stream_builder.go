@@ -20,6 +20,7 @@ import (
 	"github.com/warpstreamlabs/bento/internal/component/buffer"
 	"github.com/warpstreamlabs/bento/internal/component/cache"
 	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/component/lock"
 	"github.com/warpstreamlabs/bento/internal/component/metrics"
 	"github.com/warpstreamlabs/bento/internal/component/output"
 	"github.com/warpstreamlabs/bento/internal/component/processor"
@@ -514,6 +515,35 @@ func (s *StreamBuilder) AddRateLimitYAML(conf string) error {
 	return nil
 }
 
+// AddLockYAML parses a lock YAML configuration and adds it to the builder as a
+// resource.
+func (s *StreamBuilder) AddLockYAML(conf string) error {
+	nconf, err := s.getYAMLNode([]byte(conf))
+	if err != nil {
+		return err
+	}
+
+	if err := s.lintYAMLComponent(nconf, docs.TypeLock); err != nil {
+		return err
+	}
+
+	lconf, err := lock.FromAny(s.env.internal, nconf)
+	if err != nil {
+		return convertDocsLintErr(err)
+	}
+	if lconf.Label == "" {
+		return errors.New("a label must be specified for lock resources")
+	}
+	for _, l := range s.resources.ResourceLocks {
+		if l.Label == lconf.Label {
+			return fmt.Errorf("label %v collides with a previously defined resource", l.Label)
+		}
+	}
+
+	s.resources.ResourceLocks = append(s.resources.ResourceLocks, lconf)
+	return nil
+}
+
 // AddResourcesYAML parses resource configurations and adds them to the config.
 func (s *StreamBuilder) AddResourcesYAML(conf string) error {
 	node, err := s.getYAMLNode([]byte(conf))
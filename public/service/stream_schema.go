@@ -114,6 +114,7 @@ func (s *ConfigSchema) MarshalJSONV0() ([]byte, error) {
 		Outputs:           s.env.internal.OutputDocs(),
 		Processors:        s.env.internal.ProcessorDocs(),
 		RateLimits:        s.env.internal.RateLimitDocs(),
+		Locks:             s.env.internal.LockDocs(),
 		Metrics:           s.env.internal.MetricsDocs(),
 		Tracers:           s.env.internal.TracersDocs(),
 		Scanners:          s.env.internal.ScannerDocs(),
@@ -164,6 +165,7 @@ func (s *ConfigSchema) MarshalJSONSchema() ([]byte, error) {
 		"cache":      compSpecsToDefinition(s.env.internal.CacheDocs(), docs.ReservedFieldsByType(docs.TypeCache)),
 		"processor":  compSpecsToDefinition(s.env.internal.ProcessorDocs(), docs.ReservedFieldsByType(docs.TypeProcessor)),
 		"rate_limit": compSpecsToDefinition(s.env.internal.RateLimitDocs(), docs.ReservedFieldsByType(docs.TypeRateLimit)),
+		"lock":       compSpecsToDefinition(s.env.internal.LockDocs(), docs.ReservedFieldsByType(docs.TypeLock)),
 		"output":     compSpecsToDefinition(s.env.internal.OutputDocs(), docs.ReservedFieldsByType(docs.TypeOutput)),
 		"metrics":    compSpecsToDefinition(s.env.internal.MetricsDocs(), docs.ReservedFieldsByType(docs.TypeMetrics)),
 		"tracer":     compSpecsToDefinition(s.env.internal.TracersDocs(), docs.ReservedFieldsByType(docs.TypeTracer)),
@@ -221,6 +223,7 @@ type rawMessageSchema struct {
 	Outputs           []json.RawMessage `json:"outputs,omitempty"`
 	Processors        []json.RawMessage `json:"processors,omitempty"`
 	RateLimits        []json.RawMessage `json:"rate-limits,omitempty"`
+	Locks             []json.RawMessage `json:"locks,omitempty"`
 	Metrics           []json.RawMessage `json:"metrics,omitempty"`
 	Tracers           []json.RawMessage `json:"tracers,omitempty"`
 	Scanners          []json.RawMessage `json:"scanners,omitempty"`
@@ -380,6 +383,21 @@ func expandEnvWithSchema(schema *rawMessageSchema, env *Environment) error {
 			})
 	}
 
+	for _, spec := range schema.Locks {
+		pluginSpec := NewConfigSpec()
+		if err := pluginSpec.EncodeJSON(spec); err != nil {
+			return err
+		}
+		if _, exists := env.internal.GetDocs(pluginSpec.component.Name, docs.TypeLock); exists {
+			continue
+		}
+		_ = env.RegisterLock(
+			pluginSpec.component.Name, pluginSpec,
+			func(conf *ParsedConfig, mgr *Resources) (Lock, error) {
+				return nil, errComponentDisabled
+			})
+	}
+
 	for _, spec := range schema.Metrics {
 		pluginSpec := NewConfigSpec()
 		if err := pluginSpec.EncodeJSON(spec); err != nil {
@@ -55,6 +55,11 @@ func testEnvWithPlugins(t testing.TB) *service.Environment {
 			return nil, errors.New("nope")
 		}))
 
+	require.NoError(t, env.RegisterLock("testlock", service.NewConfigSpec().Field(service.NewStringField("lockfield")),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Lock, error) {
+			return nil, errors.New("nope")
+		}))
+
 	require.NoError(t, env.RegisterMetricsExporter("testmetrics", service.NewConfigSpec().Field(service.NewStringField("metricsfield")),
 		func(conf *service.ParsedConfig, log *service.Logger) (service.MetricsExporter, error) {
 			return nil, errors.New("nope")
@@ -16,6 +16,7 @@ module.exports = {
       outputs: components("outputs"),
       caches: components("caches"),
       rate_limits: components("rate_limits"),
+      locks: components("locks"),
       buffers: components("buffers"),
       metrics: components("metrics"),
       tracers: components("tracers"),